@@ -5,7 +5,9 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func GetBundleCmd(config *config.Config) *cobra.Command {
+// GetBundleCmd returns the bundle command, profile is the name of the
+// instance selected with the global --profile flag once it is parsed
+func GetBundleCmd(config *config.Config, profile *string) *cobra.Command {
 	bundleCmd := &cobra.Command{
 		Use:   "bundle SUBCOMMAND [flags]",
 		Short: "Manage CRC bundles",
@@ -14,6 +16,10 @@ func GetBundleCmd(config *config.Config) *cobra.Command {
 			_ = cmd.Help()
 		},
 	}
-	bundleCmd.AddCommand(getGenerateCmd(config))
+	bundleCmd.AddCommand(getGenerateCmd(config, profile))
+	bundleCmd.AddCommand(getInspectCmd(config))
+	bundleCmd.AddCommand(getDeleteCmd())
+	bundleCmd.AddCommand(getPruneCmd())
+	bundleCmd.AddCommand(getVerifyCmd(config))
 	return bundleCmd
 }
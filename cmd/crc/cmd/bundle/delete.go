@@ -0,0 +1,43 @@
+package bundle
+
+import (
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/spf13/cobra"
+)
+
+func getDeleteCmd() *cobra.Command {
+	var force bool
+	deleteCmd := &cobra.Command{
+		Use:   "delete BUNDLE",
+		Short: "Delete a bundle from the cache",
+		Long:  "Delete an extracted bundle and its archive from the cache, bundles used by instances are only deleted with --force",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := bundle.Remove(args[0], force); err != nil {
+				return err
+			}
+			fmt.Printf("Deleted bundle %s\n", bundle.GetBundleNameWithoutExtension(args[0]))
+			return nil
+		},
+	}
+	deleteCmd.Flags().BoolVarP(&force, "force", "f", false, "Delete the bundle even when instances use it, they will not start anymore")
+	return deleteCmd
+}
+
+func getPruneCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "prune",
+		Short: "Delete the bundles no instance uses",
+		Long:  "Delete the extracted bundles and their archive from the cache when no instance uses them",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pruned, err := bundle.Prune()
+			for _, bundleName := range pruned {
+				fmt.Printf("Deleted bundle %s\n", bundleName)
+			}
+			return err
+		},
+	}
+}
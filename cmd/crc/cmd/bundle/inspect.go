@@ -0,0 +1,37 @@
+package bundle
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/spf13/cobra"
+)
+
+func getInspectCmd(config *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "inspect [bundle name]",
+		Short: "Print the content and provenance of a bundle",
+		Long:  "Print the OpenShift and RHCOS versions, image content sources, operators and files of a bundle as JSON, the configured bundle is used by default",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runInspect(config, args)
+		},
+	}
+}
+
+func runInspect(cfg *config.Config, args []string) error {
+	bundleName := filepath.Base(cfg.Get(config.Bundle).AsString())
+	if len(args) == 1 {
+		bundleName = args[0]
+	}
+	inspection, err := bundle.Inspect(bundleName)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(inspection)
+}
@@ -0,0 +1,34 @@
+package bundle
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/spf13/cobra"
+)
+
+func getVerifyCmd(config *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify [bundle name]",
+		Short: "Verify the checksums of the files of a bundle",
+		Long:  "Hash all the files of an extracted bundle and compare them with their checksums recorded at extraction, the configured bundle is used by default",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runVerify(config, args)
+		},
+	}
+}
+
+func runVerify(cfg *config.Config, args []string) error {
+	bundleName := filepath.Base(cfg.Get(config.Bundle).AsString())
+	if len(args) == 1 {
+		bundleName = args[0]
+	}
+	if err := bundle.Verify(bundleName); err != nil {
+		return fmt.Errorf("%v, run 'crc bundle delete %s' so that the next start extracts it again", err, bundle.GetBundleNameWithoutExtension(bundleName))
+	}
+	fmt.Printf("Bundle %s is valid\n", bundle.GetBundleNameWithoutExtension(bundleName))
+	return nil
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addOutputFormatFlag(cloneCmd)
+	rootCmd.AddCommand(cloneCmd)
+}
+
+var cloneCmd = &cobra.Command{
+	Use:   "clone NAME",
+	Short: "Create an instance from the stopped OpenShift cluster",
+	Long: "Create the instance NAME from the disk of the stopped OpenShift cluster of the profile, so that a cluster " +
+		"prepared once can be started several times. The clone is used with '--profile NAME'.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runClone(os.Stdout, func(newName string) error {
+			return machine.Clone(config, globalProfile, newName)
+		}, globalProfile, args[0], outputFormat)
+	},
+}
+
+func runClone(writer io.Writer, clone func(newName string) error, source, newName string, outputFormat string) error {
+	err := clone(newName)
+	return render(&operationResult{
+		Success: err == nil,
+		Error:   crcErrors.ToSerializableError(err),
+		message: fmt.Sprintf("Instance %s is cloned from %s, use 'crc start --profile %s' to start it", newName, source, newName),
+	}, writer, outputFormat)
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClonePlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runClone(out, func(newName string) error {
+		assert.Equal(t, "team1", newName)
+		return nil
+	}, "golden", "team1", ""))
+	assert.Equal(t, "Instance team1 is cloned from golden, use 'crc start --profile team1' to start it\n", out.String())
+}
+
+func TestCloneJSONError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runClone(out, func(string) error {
+		return errors.New("Instance golden must be stopped to be cloned")
+	}, "golden", "team1", jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": false, "error": "Instance golden must be stopped to be cloned"}`, out.String())
+}
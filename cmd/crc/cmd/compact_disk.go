@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/docker/go-units"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addOutputFormatFlag(compactDiskCmd)
+	addForceFlag(compactDiskCmd)
+	rootCmd.AddCommand(compactDiskCmd)
+}
+
+var compactDiskCmd = &cobra.Command{
+	Use:   "compact-disk",
+	Short: "Reclaim the unused space of the disk image of the instance",
+	Long: "Trim the filesystems of the instance and compact its disk image, " +
+		"the running instance is stopped when --force is set",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCompactDisk(os.Stdout, newMachine(), globalForce, outputFormat)
+	},
+}
+
+func runCompactDisk(writer io.Writer, client machine.Client, force bool, outputFormat string) error {
+	var result *types.CompactDiskResult
+	err := checkIfMachineMissing(client)
+	if err == nil {
+		result, err = client.CompactDisk(force)
+	}
+	compactResult := &compactDiskResult{
+		Success: err == nil,
+		Error:   crcErrors.ToSerializableError(err),
+	}
+	if result != nil {
+		compactResult.SizeBefore = result.SizeBefore
+		compactResult.SizeAfter = result.SizeAfter
+		compactResult.Reclaimed = result.SizeBefore - result.SizeAfter
+		compactResult.Stopped = result.Stopped
+	}
+	return render(compactResult, writer, outputFormat)
+}
+
+type compactDiskResult struct {
+	Success    bool                         `json:"success"`
+	Error      *crcErrors.SerializableError `json:"error,omitempty"`
+	SizeBefore int64                        `json:"sizeBefore,omitempty"`
+	SizeAfter  int64                        `json:"sizeAfter,omitempty"`
+	Reclaimed  int64                        `json:"reclaimed,omitempty"`
+	Stopped    bool                         `json:"stopped,omitempty"`
+}
+
+func (s *compactDiskResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if _, err := fmt.Fprintf(writer, "Reclaimed %s, the disk image now uses %s\n",
+		units.HumanSize(float64(s.Reclaimed)), units.HumanSize(float64(s.SizeAfter))); err != nil {
+		return err
+	}
+	if s.Stopped {
+		_, err := fmt.Fprintln(writer, "The instance was stopped, use 'crc start' to start it again")
+		return err
+	}
+	return nil
+}
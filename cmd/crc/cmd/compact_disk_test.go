@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompactDiskPlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runCompactDisk(out, fakemachine.NewClient(), true, ""))
+	assert.Equal(t, "Reclaimed 13GB, the disk image now uses 18GB\nThe instance was stopped, use 'crc start' to start it again\n", out.String())
+}
+
+func TestCompactDiskPlainError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runCompactDisk(out, fakemachine.NewFailingClient(), false, ""), "disk compaction failed")
+}
+
+func TestCompactDiskJSONSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runCompactDisk(out, fakemachine.NewClient(), false, jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": true, "sizeBefore": 31000000000, "sizeAfter": 18000000000, "reclaimed": 13000000000}`, out.String())
+}
@@ -8,6 +8,9 @@ import (
 	"text/tabwriter"
 
 	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/spf13/cobra"
 )
 
@@ -52,7 +55,33 @@ func configurableFields(config *config.Config) string {
 	return buf.String()
 }
 
-func GetConfigCmd(config *config.Config) *cobra.Command {
+// configChangeMessage tells the user when the change of key is applied to the
+// existing instance called name, defaultMessage is used when there is nothing
+// special to do
+func configChangeMessage(cfg config.Storage, name string, key string, oldValue interface{}, defaultMessage string) string {
+	result, err := machine.NewClient(name, logging.IsDebug(), cfg).ConfigChanged(key, oldValue)
+	if err != nil {
+		logging.Debugf("Cannot check how the change of %s applies to the instance: %v", key, err)
+		return defaultMessage
+	}
+	switch result.Impact {
+	case types.ConfigRequiresDelete:
+		return fmt.Sprintf("Changes to configuration property '%s' are only applied when the CRC instance is created.\n"+
+			"Delete the CRC instance with 'crc delete' and start it again with 'crc start' for this change to take effect.", key)
+	case types.ConfigAppliedAtStart:
+		if result.InstanceRunning {
+			return fmt.Sprintf("Changes to configuration property '%s' are only applied when the CRC instance is started.\n"+
+				"Stop the running CRC instance with 'crc stop' and start it again with 'crc start' for this change to take effect.", key)
+		}
+		return fmt.Sprintf("Changes to configuration property '%s' will be applied at the next 'crc start'.", key)
+	default:
+		return defaultMessage
+	}
+}
+
+// GetConfigCmd returns the config command, profile is the name of the
+// instance selected with the global --profile flag once it is parsed
+func GetConfigCmd(config *config.Config, profile *string) *cobra.Command {
 	configCmd := &cobra.Command{
 		Use:   "config SUBCOMMAND [flags]",
 		Short: "Modify crc configuration",
@@ -63,8 +92,8 @@ Properties: ` + "\n\n" + configurableFields(config),
 		},
 	}
 	configCmd.AddCommand(configGetCmd(config))
-	configCmd.AddCommand(configSetCmd(config))
-	configCmd.AddCommand(configUnsetCmd(config))
+	configCmd.AddCommand(configSetCmd(config, profile))
+	configCmd.AddCommand(configUnsetCmd(config, profile))
 	configCmd.AddCommand(configViewCmd(config))
 	return configCmd
 }
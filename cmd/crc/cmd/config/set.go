@@ -9,7 +9,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func configSetCmd(config *config.Config) *cobra.Command {
+func configSetCmd(config *config.Config, profile *string) *cobra.Command {
 	return &cobra.Command{
 		Use:   "set CONFIG-KEY VALUE",
 		Short: "Set a crc configuration property",
@@ -19,6 +19,7 @@ CONFIG-KEYS: ` + "\n\n" + configurableFields(config),
 			if len(args) < 2 {
 				return errors.New("Please provide a configuration property and its value as in 'crc config set KEY VALUE'")
 			}
+			oldValue := config.Get(args[0]).Value
 			setMessage, err := config.Set(args[0], args[1])
 			if err != nil {
 				return err
@@ -26,8 +27,8 @@ CONFIG-KEYS: ` + "\n\n" + configurableFields(config),
 
 			telemetry.SetConfigurationKey(cmd.Context(), args[0])
 
-			if setMessage != "" {
-				fmt.Println(setMessage)
+			if message := configChangeMessage(config, *profile, args[0], oldValue, setMessage); message != "" {
+				fmt.Println(message)
 			}
 			return nil
 		},
@@ -9,7 +9,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func configUnsetCmd(config config.Storage) *cobra.Command {
+func configUnsetCmd(config config.Storage, profile *string) *cobra.Command {
 	return &cobra.Command{
 		Use:   "unset CONFIG-KEY",
 		Short: "Unset a crc configuration property",
@@ -18,6 +18,7 @@ func configUnsetCmd(config config.Storage) *cobra.Command {
 			if len(args) != 1 {
 				return errors.New("Please provide a configuration property to unset")
 			}
+			oldValue := config.Get(args[0]).Value
 			unsetMessage, err := config.Unset(args[0])
 			if err != nil {
 				return err
@@ -25,8 +26,8 @@ func configUnsetCmd(config config.Storage) *cobra.Command {
 
 			telemetry.SetConfigurationKey(cmd.Context(), args[0])
 
-			if unsetMessage != "" {
-				fmt.Println(unsetMessage)
+			if message := configChangeMessage(config, *profile, args[0], oldValue, unsetMessage); message != "" {
+				fmt.Println(message)
 			}
 			return nil
 		},
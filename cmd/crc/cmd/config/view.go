@@ -9,6 +9,7 @@ import (
 	"text/template"
 
 	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/spf13/cast"
 	"github.com/spf13/cobra"
 )
 
@@ -55,7 +56,7 @@ func runConfigView(cfg map[string]config.SettingValue, tmpl *template.Template,
 		if v.IsDefault {
 			continue
 		}
-		viewTmplt := configViewTemplate{k, v.AsString()}
+		viewTmplt := configViewTemplate{k, cast.ToString(v.DisplayValue())}
 		var buffer bytes.Buffer
 		if err := tmpl.Execute(&buffer, viewTmplt); err != nil {
 			return err
@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 
+	crcCredentials "github.com/code-ready/crc/pkg/crc/credentials"
 	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
 	"github.com/code-ready/crc/pkg/crc/machine"
 	"github.com/code-ready/crc/pkg/crc/machine/state"
@@ -15,14 +16,16 @@ import (
 )
 
 var (
-	consolePrintURL         bool
-	consolePrintCredentials bool
+	consolePrintURL          bool
+	consolePrintCredentials  bool
+	consoleCredentialsFormat string
 )
 
 func init() {
 	addOutputFormatFlag(consoleCmd)
 	consoleCmd.Flags().BoolVar(&consolePrintURL, "url", false, "Print the URL for the OpenShift Web Console")
 	consoleCmd.Flags().BoolVar(&consolePrintCredentials, "credentials", false, "Print the credentials for the OpenShift Web Console")
+	consoleCmd.Flags().StringVar(&consoleCredentialsFormat, "credentials-format", "", "Format of the credentials printed with --credentials. One of: json, env, netrc, kubeconfig")
 	rootCmd.AddCommand(consoleCmd)
 }
 
@@ -33,7 +36,10 @@ var consoleCmd = &cobra.Command{
 	Short:   "Open the OpenShift Web Console in the default browser",
 	Long:    `Open the OpenShift Web Console in the default browser or print its URL or credentials`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runConsole(os.Stdout, newMachine(), consolePrintURL, consolePrintCredentials, outputFormat)
+		if consoleCredentialsFormat != "" && !consolePrintCredentials {
+			return errors.New("--credentials-format can only be used with --credentials")
+		}
+		return runConsole(os.Stdout, newMachine(), consolePrintURL, consolePrintCredentials, consoleCredentialsFormat, outputFormat)
 	},
 }
 
@@ -47,7 +53,7 @@ func showConsole(client machine.Client) (*types.ConsoleResult, error) {
 	return client.GetConsoleURL()
 }
 
-func runConsole(writer io.Writer, client machine.Client, consolePrintURL, consolePrintCredentials bool, outputFormat string) error {
+func runConsole(writer io.Writer, client machine.Client, consolePrintURL, consolePrintCredentials bool, credentialsFormat, outputFormat string) error {
 	result, err := showConsole(client)
 	return render(&consoleResult{
 		Success:                 err == nil,
@@ -56,6 +62,7 @@ func runConsole(writer io.Writer, client machine.Client, consolePrintURL, consol
 		Error:                   crcErrors.ToSerializableError(err),
 		consolePrintURL:         consolePrintURL,
 		consolePrintCredentials: consolePrintCredentials,
+		credentialsFormat:       crcCredentials.Format(credentialsFormat),
 	}, writer, outputFormat)
 }
 
@@ -66,6 +73,7 @@ type consoleResult struct {
 	ClusterConfig           *clusterConfig               `json:"clusterConfig,omitempty"`
 	consolePrintURL         bool
 	consolePrintCredentials bool
+	credentialsFormat       crcCredentials.Format
 }
 
 func (s *consoleResult) prettyPrintTo(writer io.Writer) error {
@@ -78,7 +86,15 @@ func (s *consoleResult) prettyPrintTo(writer io.Writer) error {
 		}
 	}
 
-	if s.consolePrintCredentials {
+	if s.consolePrintCredentials && s.credentialsFormat != "" {
+		out, err := s.toCredentials().Render(s.credentialsFormat)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprint(writer, out); err != nil {
+			return err
+		}
+	} else if s.consolePrintCredentials {
 		if _, err := fmt.Fprintf(writer, "To login as a regular user, run 'oc login -u %s -p %s %s'.\n",
 			s.ClusterConfig.DeveloperCredentials.Username, s.ClusterConfig.DeveloperCredentials.Password, s.ClusterConfig.URL); err != nil {
 			return err
@@ -106,6 +122,22 @@ func (s *consoleResult) prettyPrintTo(writer io.Writer) error {
 	return nil
 }
 
+func (s *consoleResult) toCredentials() crcCredentials.Credentials {
+	return crcCredentials.Credentials{
+		ClusterCACert: s.ClusterConfig.ClusterCACert,
+		WebConsoleURL: s.ClusterConfig.WebConsoleURL,
+		URL:           s.ClusterConfig.URL,
+		AdminCredentials: crcCredentials.UserCredentials{
+			Username: s.ClusterConfig.AdminCredentials.Username,
+			Password: s.ClusterConfig.AdminCredentials.Password,
+		},
+		DeveloperCredentials: crcCredentials.UserCredentials{
+			Username: s.ClusterConfig.DeveloperCredentials.Username,
+			Password: s.ClusterConfig.DeveloperCredentials.Password,
+		},
+	}
+}
+
 func toState(result *types.ConsoleResult) state.State {
 	if result == nil {
 		return state.Error
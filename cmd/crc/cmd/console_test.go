@@ -11,13 +11,13 @@ import (
 
 func TestConsolePlainSuccess(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, runConsole(out, fakemachine.NewClient(), true, false, ""))
+	assert.NoError(t, runConsole(out, fakemachine.NewClient(), true, false, "", ""))
 	assert.Equal(t, fmt.Sprintf("%s\n", fakemachine.DummyClusterConfig.WebConsoleURL), out.String())
 }
 
 func TestConsolePlainError(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.EqualError(t, runConsole(out, fakemachine.NewFailingClient(), true, false, ""), "console failed")
+	assert.EqualError(t, runConsole(out, fakemachine.NewFailingClient(), true, false, "", ""), "console failed")
 }
 
 func TestConsoleWithPrintCredentialsPlainSuccess(t *testing.T) {
@@ -25,7 +25,7 @@ func TestConsoleWithPrintCredentialsPlainSuccess(t *testing.T) {
 To login as an admin, run 'oc login -u kubeadmin -p %s %s'
 `, fakemachine.DummyClusterConfig.ClusterAPI, fakemachine.DummyClusterConfig.KubeAdminPass, fakemachine.DummyClusterConfig.ClusterAPI)
 	out := new(bytes.Buffer)
-	assert.NoError(t, runConsole(out, fakemachine.NewClient(), false, true, ""))
+	assert.NoError(t, runConsole(out, fakemachine.NewClient(), false, true, "", ""))
 	assert.Equal(t, expectedOut, out.String())
 }
 
@@ -35,12 +35,13 @@ To login as a regular user, run 'oc login -u developer -p developer %s'.
 To login as an admin, run 'oc login -u kubeadmin -p %s %s'
 `, fakemachine.DummyClusterConfig.WebConsoleURL, fakemachine.DummyClusterConfig.ClusterAPI, fakemachine.DummyClusterConfig.KubeAdminPass, fakemachine.DummyClusterConfig.ClusterAPI)
 	out := new(bytes.Buffer)
-	assert.NoError(t, runConsole(out, fakemachine.NewClient(), true, true, ""))
+	assert.NoError(t, runConsole(out, fakemachine.NewClient(), true, true, "", ""))
 	assert.Equal(t, expectedOut, out.String())
 }
 
 func TestConsoleJSONSuccess(t *testing.T) {
 	expectedJSONOut := fmt.Sprintf(`{
+  "schemaVersion": 1,
   "success": true,
   "clusterConfig": {
 	"cacert": "%s",
@@ -57,12 +58,25 @@ func TestConsoleJSONSuccess(t *testing.T) {
   }
 }`, fakemachine.DummyClusterConfig.ClusterCACert, fakemachine.DummyClusterConfig.WebConsoleURL, fakemachine.DummyClusterConfig.ClusterAPI, fakemachine.DummyClusterConfig.KubeAdminPass)
 	out := new(bytes.Buffer)
-	assert.NoError(t, runConsole(out, fakemachine.NewClient(), false, false, jsonFormat))
+	assert.NoError(t, runConsole(out, fakemachine.NewClient(), false, false, "", jsonFormat))
 	assert.JSONEq(t, expectedJSONOut, out.String())
 }
 
 func TestConsoleJSONError(t *testing.T) {
 	out := new(bytes.Buffer)
-	assert.NoError(t, runConsole(out, fakemachine.NewFailingClient(), false, false, jsonFormat))
-	assert.JSONEq(t, `{"error":"console failed", "success":false}`, out.String())
+	assert.NoError(t, runConsole(out, fakemachine.NewFailingClient(), false, false, "", jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "error":"console failed", "success":false}`, out.String())
+}
+
+func TestConsoleWithPrintCredentialsEnvSuccess(t *testing.T) {
+	expectedOut := fmt.Sprintf(`export CRC_API_URL='%s'
+export CRC_WEB_CONSOLE_URL='%s'
+export CRC_ADMIN_USERNAME='kubeadmin'
+export CRC_ADMIN_PASSWORD='%s'
+export CRC_DEVELOPER_USERNAME='developer'
+export CRC_DEVELOPER_PASSWORD='developer'
+`, fakemachine.DummyClusterConfig.ClusterAPI, fakemachine.DummyClusterConfig.WebConsoleURL, fakemachine.DummyClusterConfig.KubeAdminPass)
+	out := new(bytes.Buffer)
+	assert.NoError(t, runConsole(out, fakemachine.NewClient(), false, true, "env", ""))
+	assert.Equal(t, expectedOut, out.String())
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -15,11 +16,21 @@ import (
 
 	"github.com/code-ready/crc/pkg/crc/adminhelper"
 	"github.com/code-ready/crc/pkg/crc/api"
+	"github.com/code-ready/crc/pkg/crc/cluster"
 	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/daemonclient"
+	"github.com/code-ready/crc/pkg/crc/logforwarding"
 	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/metrics"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/network/pac"
+	"github.com/code-ready/crc/pkg/crc/network/proxyrelay"
 	"github.com/code-ready/crc/pkg/crc/preflight"
+	"github.com/code-ready/crc/pkg/crc/services/dns"
+	"github.com/code-ready/crc/pkg/crc/validation"
+	"github.com/code-ready/crc/pkg/os/power"
 	"github.com/containers/gvisor-tap-vsock/pkg/types"
 	"github.com/containers/gvisor-tap-vsock/pkg/virtualnetwork"
 	"github.com/docker/go-units"
@@ -30,10 +41,14 @@ import (
 	"k8s.io/client-go/util/exec"
 )
 
-var watchdog bool
+var (
+	watchdog      bool
+	preflightOnly bool
+)
 
 func init() {
 	daemonCmd.Flags().BoolVar(&watchdog, "watchdog", false, "Monitor stdin and shutdown the daemon if stdin is closed")
+	daemonCmd.Flags().BoolVar(&preflightOnly, "preflight-only", false, "Only serve the preflight checks API, without the VM network, for IDE integrations")
 	rootCmd.AddCommand(daemonCmd)
 }
 
@@ -56,12 +71,20 @@ var daemonCmd = &cobra.Command{
 			return errors.New("daemon is already running")
 		}
 
+		if preflightOnly {
+			return runPreflightOnly()
+		}
+
 		if err := preflight.StartPreflightChecks(config); err != nil {
 			return exec.CodeExitError{
 				Err:  err,
 				Code: preflightFailedExitCode,
 			}
 		}
+		// the proxy relay uses the proxy selected by the PAC file
+		if err := setProxyDefaults(pac.ProxyFor); err != nil {
+			return err
+		}
 
 		virtualNetworkConfig := types.Configuration{
 			Debug:             false, // never log packets
@@ -153,14 +176,25 @@ func run(configuration *types.Configuration) error {
 		return err
 	}
 
+	// instances are accessed through a registry so that operations on
+	// different instances can run concurrently
+	instances := machine.NewInstances(int(validation.HostTotalMemory()/1024/1024), func(name string) machine.Client {
+		return machine.NewClient(name, logging.IsDebug(), config)
+	})
+	machineClient := instances.Get(constants.DefaultName)
+	// the API serves the instance named in the path of the requests or in
+	// the metadata of the gRPC calls, the default one otherwise
+	apiHandlers := api.NewInstanceHandlers(config, instances.Get, logging.Memory, segmentClient)
+
 	go func() {
 		if listener == nil {
 			return
 		}
 		mux := http.NewServeMux()
 		mux.Handle("/network/", http.StripPrefix("/network", vn.Mux()))
-		mux.Handle("/api/", http.StripPrefix("/api", api.NewMux(config, newMachine(), logging.Memory, segmentClient)))
-		if err := http.Serve(listener, handlers.LoggingHandler(os.Stderr, mux)); err != nil {
+		mux.Handle("/api/", http.StripPrefix("/api", api.NewInstancesMux(apiHandlers)))
+		grpcServer := api.NewInstancesGRPCServer(apiHandlers)
+		if err := http.Serve(listener, api.WithGRPC(handlers.LoggingHandler(os.Stderr, mux), grpcServer)); err != nil {
 			errCh <- errors.Wrap(err, "api http.Serve failed")
 		}
 	}()
@@ -176,6 +210,51 @@ func run(configuration *types.Configuration) error {
 		}
 	}()
 
+	// crio and the kubelet cannot authenticate to NTLM or Negotiate proxies,
+	// the VM uses this relay instead when proxy-auth is set
+	relayListener, err := vn.Listen("tcp", fmt.Sprintf("%s:%d", configuration.GatewayIP, constants.ProxyRelayPort))
+	if err != nil {
+		return err
+	}
+	go func() {
+		relay := proxyrelay.New(network.NewProxyConfig, func() network.ProxyAuth {
+			return crcConfig.GetProxyAuth(config)
+		})
+		if err := http.Serve(relayListener, relay); err != nil {
+			errCh <- errors.Wrap(err, "proxy relay http.Serve failed")
+		}
+	}()
+
+	if crcConfig.GetDNSService(config) == crcConfig.HostDNSService {
+		dnsConn, err := net.ListenPacket("udp", fmt.Sprintf("127.0.0.1:%d", constants.HostDNSPort))
+		if err != nil {
+			logging.Warnf("Cannot serve the cluster hostnames on the host: %v", err)
+		} else {
+			go func() {
+				if err := dns.NewHostServer().Serve(dnsConn); err != nil {
+					errCh <- errors.Wrap(err, "host DNS server failed")
+				}
+			}()
+		}
+	}
+
+	// Prometheus cannot scrape the socket of the API, the metrics are served
+	// on localhost only, they are not authenticated
+	if port := config.Get(crcConfig.MetricsPort).AsInt(); port != 0 {
+		metricsListener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		if err != nil {
+			logging.Warnf("Cannot serve the metrics on the host: %v", err)
+		} else {
+			go func() {
+				mux := http.NewServeMux()
+				mux.Handle("/metrics", metrics.NewHandler(metrics.CachedStatus(machineClient)))
+				if err := http.Serve(metricsListener, handlers.LoggingHandler(os.Stderr, mux)); err != nil {
+					errCh <- errors.Wrap(err, "metrics http.Serve failed")
+				}
+			}()
+		}
+	}
+
 	go func() {
 		if runtime.GOOS == "darwin" {
 			for {
@@ -200,6 +279,21 @@ func run(configuration *types.Configuration) error {
 		}
 	}()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go power.Watch(ctx, func() {
+		logging.Debug("Host is going to sleep")
+	}, func() {
+		logging.Info("Host resumed from sleep, synchronizing the VM...")
+		if err := machineClient.Reconcile(); err != nil {
+			logging.Warnf("Failed to synchronize the VM after resume: %v", err)
+		}
+	})
+
+	go machine.WatchLoadBalancers(ctx, constants.DefaultName, config)
+	go machine.WatchPortForwards(ctx, config)
+	go machine.WatchScheduledSnapshots(ctx, machineClient)
+
 	startupDone()
 
 	if logging.IsDebug() {
@@ -211,6 +305,12 @@ func run(configuration *types.Configuration) error {
 		}()
 	}
 
+	return waitForShutdown(errCh)
+}
+
+// waitForShutdown returns when the daemon is asked to stop, or with the first
+// error sent to errCh
+func waitForShutdown(errCh chan error) error {
 	c := make(chan os.Signal, 1)
 
 	if watchdog {
@@ -232,6 +332,31 @@ func run(configuration *types.Configuration) error {
 	}
 }
 
+// runPreflightOnly serves the preflight checks API of the daemon. It does not
+// need the host to be set up, so that clients can report what is missing.
+func runPreflightOnly() error {
+	listener, err := httpListener()
+	if err != nil {
+		return err
+	}
+	if listener == nil {
+		return errors.New("no listener for the daemon API")
+	}
+
+	errCh := make(chan error)
+	go func() {
+		mux := http.NewServeMux()
+		mux.Handle("/api/", http.StripPrefix("/api", api.NewPreflightMux(config, logging.Memory, segmentClient)))
+		if err := http.Serve(listener, handlers.LoggingHandler(os.Stderr, mux)); err != nil {
+			errCh <- errors.Wrap(err, "api http.Serve failed")
+		}
+	}()
+
+	startupDone()
+
+	return waitForShutdown(errCh)
+}
+
 // This API is only exposed in the virtual network (only the VM can reach this).
 // Any process inside the VM can reach it by connecting to gateway.crc.testing:80.
 func gatewayAPIMux() *http.ServeMux {
@@ -246,6 +371,9 @@ func gatewayAPIMux() *http.ServeMux {
 			return adminhelper.RemoveFromHostsFile(hostnames...)
 		})
 	})
+	mux.Handle(cluster.LogForwardingPath, logforwarding.NewHandler(func() string {
+		return crcConfig.GetLogForwarding(config).Directory
+	}))
 	return mux
 }
 
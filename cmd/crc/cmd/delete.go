@@ -5,20 +5,33 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
 	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
 	"github.com/code-ready/crc/pkg/crc/input"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/spf13/cobra"
 )
 
-var clearCache bool
+var (
+	clearCache          bool
+	keepBundle          bool
+	keepData            bool
+	ignoreMissing       bool
+	deleteOverrideToken string
+)
 
 func init() {
 	deleteCmd.Flags().BoolVarP(&clearCache, "clear-cache", "", false,
 		fmt.Sprintf("Clear the OpenShift cluster cache at: %s", constants.MachineCacheDir))
+	deleteCmd.Flags().BoolVar(&keepBundle, "keep-bundle", false, "Keep the extracted bundles when clearing the cache")
+	deleteCmd.Flags().BoolVar(&keepData, "keep-data", false,
+		fmt.Sprintf("Copy an etcd backup and the persistent volumes of the running cluster to %s before deleting it", filepath.Join(constants.MachineBaseDir, "backups")))
+	deleteCmd.Flags().BoolVar(&ignoreMissing, "ignore-missing", false, "Succeed without deleting anything when the OpenShift cluster does not exist")
+	deleteCmd.Flags().StringVar(&deleteOverrideToken, "override-token", "", "Protection token needed to delete a protected OpenShift cluster")
 	addOutputFormatFlag(deleteCmd)
 	addForceFlag(deleteCmd)
 	rootCmd.AddCommand(deleteCmd)
@@ -29,42 +42,69 @@ var deleteCmd = &cobra.Command{
 	Short: "Delete the OpenShift cluster",
 	Long:  "Delete the OpenShift cluster",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runDelete(os.Stdout, newMachine(), clearCache, constants.MachineCacheDir, outputFormat != jsonFormat, globalForce, outputFormat)
+		deleteConfig := types.DeleteConfig{
+			ClearCache:    clearCache,
+			KeepBundle:    keepBundle,
+			KeepData:      keepData,
+			IgnoreMissing: ignoreMissing,
+		}
+		return runDelete(os.Stdout, newMachine(), deleteConfig, constants.MachineCacheDir, outputFormat != jsonFormat, globalForce, deleteOverrideToken, outputFormat)
 	},
 }
 
-func deleteMachine(client machine.Client, clearCache bool, cacheDir string, interactive, force bool) (bool, error) {
-	if clearCache {
+// deleteMachine deletes the VM, it returns whether it was deleted and
+// whether it did not exist
+func deleteMachine(client machine.Client, deleteConfig types.DeleteConfig, cacheDir string, interactive, force bool, overrideToken string) (bool, bool, error) {
+	if deleteConfig.ClearCache {
 		if !interactive && !force {
-			return false, errors.New("non-interactive deletion requires --force")
+			return false, false, errors.New("non-interactive deletion requires --force")
 		}
-		yes := input.PromptUserForYesOrNo("Do you want to delete the OpenShift cluster cache", force)
-		if yes {
-			_ = os.RemoveAll(cacheDir)
+		if input.PromptUserForYesOrNo("Do you want to delete the OpenShift cluster cache", force) {
+			// the cache is cleared even when the cluster does not exist, once
+			// the cluster released its bundle so that it can be removed
+			defer func() {
+				_ = machine.ClearCache(cacheDir, deleteConfig.KeepBundle)
+			}()
 		}
 	}
 
-	if err := checkIfMachineMissing(client); err != nil {
-		return false, err
+	exists, err := client.Exists()
+	if err != nil {
+		return false, false, err
+	}
+	if !exists {
+		if deleteConfig.IgnoreMissing {
+			return false, true, nil
+		}
+		return false, false, crcErrors.VMNotExist
 	}
 
 	if !interactive && !force {
-		return false, errors.New("non-interactive deletion requires --force")
+		return false, false, errors.New("non-interactive deletion requires --force")
 	}
 
 	yes := input.PromptUserForYesOrNo("Do you want to delete the OpenShift cluster", force)
 	if yes {
 		defer logging.BackupLogFile()
-		return true, client.Delete()
+		result, err := client.Delete(types.DeleteConfig{
+			KeepData:        deleteConfig.KeepData,
+			IgnoreMissing:   deleteConfig.IgnoreMissing,
+			ProtectionToken: overrideToken,
+		})
+		if err != nil {
+			return false, false, err
+		}
+		return !result.AlreadyDeleted, result.AlreadyDeleted, nil
 	}
-	return false, nil
+	return false, false, nil
 }
 
-func runDelete(writer io.Writer, client machine.Client, clearCache bool, cacheDir string, interactive, force bool, outputFormat string) error {
-	machineDeleted, err := deleteMachine(client, clearCache, cacheDir, interactive, force)
+func runDelete(writer io.Writer, client machine.Client, deleteConfig types.DeleteConfig, cacheDir string, interactive, force bool, overrideToken, outputFormat string) error {
+	machineDeleted, alreadyDeleted, err := deleteMachine(client, deleteConfig, cacheDir, interactive, force, overrideToken)
 	return render(&deleteResult{
 		Success:        err == nil,
 		Error:          crcErrors.ToSerializableError(err),
+		AlreadyDeleted: alreadyDeleted,
 		machineDeleted: machineDeleted,
 	}, writer, outputFormat)
 }
@@ -72,6 +112,7 @@ func runDelete(writer io.Writer, client machine.Client, clearCache bool, cacheDi
 type deleteResult struct {
 	Success        bool                         `json:"success"`
 	Error          *crcErrors.SerializableError `json:"error,omitempty"`
+	AlreadyDeleted bool                         `json:"alreadyDeleted,omitempty"`
 	machineDeleted bool
 }
 
@@ -79,6 +120,10 @@ func (s *deleteResult) prettyPrintTo(writer io.Writer) error {
 	if s.Error != nil {
 		return s.Error
 	}
+	if s.AlreadyDeleted {
+		_, err := fmt.Fprintln(writer, "The OpenShift cluster does not exist")
+		return err
+	}
 	if s.machineDeleted {
 		if _, err := fmt.Fprintln(writer, "Deleted the OpenShift cluster"); err != nil {
 			return err
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -17,7 +19,7 @@ func TestPlainDelete(t *testing.T) {
 	defer os.RemoveAll(cacheDir)
 
 	out := new(bytes.Buffer)
-	assert.NoError(t, runDelete(out, fakemachine.NewClient(), true, cacheDir, true, true, ""))
+	assert.NoError(t, runDelete(out, fakemachine.NewClient(), types.DeleteConfig{ClearCache: true}, cacheDir, true, true, "", ""))
 	assert.Equal(t, "Deleted the OpenShift cluster\n", out.String())
 
 	_, err = os.Stat(cacheDir)
@@ -30,7 +32,7 @@ func TestNonForceDelete(t *testing.T) {
 	defer os.RemoveAll(cacheDir)
 
 	out := new(bytes.Buffer)
-	assert.NoError(t, runDelete(out, fakemachine.NewClient(), true, cacheDir, true, false, ""))
+	assert.NoError(t, runDelete(out, fakemachine.NewClient(), types.DeleteConfig{ClearCache: true}, cacheDir, true, false, "", ""))
 	assert.Equal(t, "", out.String())
 
 	_, err = os.Stat(cacheDir)
@@ -43,9 +45,73 @@ func TestJSONDelete(t *testing.T) {
 	defer os.RemoveAll(cacheDir)
 
 	out := new(bytes.Buffer)
-	assert.NoError(t, runDelete(out, fakemachine.NewClient(), true, cacheDir, false, true, jsonFormat))
-	assert.JSONEq(t, `{"success": true}`, out.String())
+	assert.NoError(t, runDelete(out, fakemachine.NewClient(), types.DeleteConfig{ClearCache: true}, cacheDir, false, true, "", jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": true}`, out.String())
 
 	_, err = os.Stat(cacheDir)
 	assert.True(t, os.IsNotExist(err))
 }
+
+func TestDeleteWithInvalidOverrideToken(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(cacheDir)
+
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runDelete(out, fakemachine.NewClient(), types.DeleteConfig{}, cacheDir, true, true, "0000", ""), "invalid protection token")
+	assert.NoError(t, runDelete(out, fakemachine.NewClient(), types.DeleteConfig{}, cacheDir, true, true, fakemachine.DummyProtectionToken, ""))
+}
+
+func TestDeleteKeepBundle(t *testing.T) {
+	cacheDir, err := ioutil.TempDir("", "cache")
+	require.NoError(t, err)
+	defer os.RemoveAll(cacheDir)
+
+	bundleDir := filepath.Join(cacheDir, "crc_libvirt_4.6.1")
+	require.NoError(t, os.Mkdir(bundleDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(bundleDir, "crc-bundle-info.json"), []byte("{}"), 0600))
+	require.NoError(t, os.Mkdir(filepath.Join(cacheDir, "crc-tray-linux"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(cacheDir, "crc_libvirt_4.6.1.crcbundle"), []byte("bundle"), 0600))
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runDelete(out, fakemachine.NewClient(), types.DeleteConfig{ClearCache: true, KeepBundle: true}, cacheDir, true, true, "", ""))
+
+	entries, err := ioutil.ReadDir(cacheDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "crc_libvirt_4.6.1", entries[0].Name())
+}
+
+func TestDeleteMissing(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runDelete(out, &fakemachine.Client{Missing: true}, types.DeleteConfig{}, "", false, true, "", ""), "Machine does not exist. Use 'crc start' to create it")
+}
+
+func TestDeleteIgnoreMissing(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runDelete(out, &fakemachine.Client{Missing: true}, types.DeleteConfig{IgnoreMissing: true}, "", false, false, "", jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": true, "alreadyDeleted": true}`, out.String())
+}
+
+func TestDeleteKeepsBundleUsedByOtherInstances(t *testing.T) {
+	baseDir, err := ioutil.TempDir("", "crc")
+	require.NoError(t, err)
+	defer os.RemoveAll(baseDir)
+
+	cacheDir := filepath.Join(baseDir, "cache")
+	bundleDir := filepath.Join(cacheDir, "crc_libvirt_4.6.1")
+	require.NoError(t, os.MkdirAll(bundleDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(bundleDir, "crc-bundle-info.json"), []byte("{}"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(cacheDir, "crc_libvirt_4.6.1.crcbundle"), []byte("bundle"), 0600))
+	machineDir := filepath.Join(baseDir, "machines", "test411")
+	require.NoError(t, os.MkdirAll(machineDir, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(machineDir, "config.json"), []byte(`{"Driver": {"BundleName": "crc_libvirt_4.6.1.crcbundle"}}`), 0600))
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, runDelete(out, fakemachine.NewClient(), types.DeleteConfig{ClearCache: true}, cacheDir, true, true, "", ""))
+
+	entries, err := ioutil.ReadDir(cacheDir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "crc_libvirt_4.6.1", entries[0].Name())
+}
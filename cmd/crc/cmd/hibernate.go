@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addOutputFormatFlag(hibernateCmd)
+	rootCmd.AddCommand(hibernateCmd)
+	addOutputFormatFlag(resumeCmd)
+	resumeCmd.Flags().BoolVar(&resumeSyncClock, "sync-clock", true, "Set the clock of a paused VM to the one of the host")
+	rootCmd.AddCommand(resumeCmd)
+}
+
+var resumeSyncClock bool
+
+var hibernateCmd = &cobra.Command{
+	Use:   "hibernate",
+	Short: "Hibernate the OpenShift cluster",
+	Long: "Stop the kubelet and all the containers of the OpenShift cluster without stopping the virtual machine. " +
+		"The cluster then uses almost no CPU and 'crc resume' brings it back much faster than 'crc start' after 'crc stop'",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runHibernate(os.Stdout, newMachine(), outputFormat)
+	},
+}
+
+var resumeCmd = &cobra.Command{
+	Use:   "resume",
+	Short: "Resume the paused VM or the hibernated OpenShift cluster",
+	Long: "Resume the virtual machine paused by 'crc pause', then start the kubelet stopped by 'crc hibernate' " +
+		"and wait for the OpenShift API server to be available",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runResume(os.Stdout, newMachine(), types.ResumeConfig{SyncClock: resumeSyncClock}, outputFormat)
+	},
+}
+
+func runHibernate(writer io.Writer, client machine.Client, outputFormat string) error {
+	err := checkIfMachineMissing(client)
+	if err == nil {
+		err = client.Hibernate()
+	}
+	return render(&hibernateResult{
+		Success: err == nil,
+		Error:   crcErrors.ToSerializableError(err),
+		message: "The OpenShift cluster is hibernated, use 'crc resume' to start it again",
+	}, writer, outputFormat)
+}
+
+func runResume(writer io.Writer, client machine.Client, resumeConfig types.ResumeConfig, outputFormat string) error {
+	err := checkIfMachineMissing(client)
+	if err == nil {
+		err = client.Resume(resumeConfig)
+	}
+	return render(&hibernateResult{
+		Success: err == nil,
+		Error:   crcErrors.ToSerializableError(err),
+		message: "Resumed the OpenShift cluster",
+	}, writer, outputFormat)
+}
+
+type hibernateResult struct {
+	Success bool                         `json:"success"`
+	Error   *crcErrors.SerializableError `json:"error,omitempty"`
+	message string
+}
+
+func (s *hibernateResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	_, err := fmt.Fprintln(writer, s.message)
+	return err
+}
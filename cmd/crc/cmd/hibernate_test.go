@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHibernatePlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runHibernate(out, fakemachine.NewClient(), ""))
+	assert.Equal(t, "The OpenShift cluster is hibernated, use 'crc resume' to start it again\n", out.String())
+}
+
+func TestHibernatePlainError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runHibernate(out, fakemachine.NewFailingClient(), ""), "hibernate failed")
+}
+
+func TestHibernateJSONError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runHibernate(out, fakemachine.NewFailingClient(), jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": false, "error": "hibernate failed"}`, out.String())
+}
+
+func TestResumePlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runResume(out, fakemachine.NewClient(), types.ResumeConfig{}, ""))
+	assert.Equal(t, "Resumed the OpenShift cluster\n", out.String())
+}
+
+func TestResumeJSONSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runResume(out, fakemachine.NewClient(), types.ResumeConfig{}, jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": true}`, out.String())
+}
@@ -1,21 +1,34 @@
 package cmd
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
 
+	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/output"
 	"github.com/spf13/cobra"
 )
 
-const jsonFormat = "json"
+const jsonFormat = output.JSON
 
 var (
 	outputFormat string
 )
 
+// addOutputFormatFlag adds the --output flag, its value is checked before the
+// command runs rather than when its result is rendered
 func addOutputFormatFlag(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&outputFormat, "output", "o", "", "Output format. One of: json")
+	preRunE := cmd.PreRunE
+	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+		if err := output.ValidateFormat(outputFormat); err != nil {
+			return err
+		}
+		if preRunE != nil {
+			return preRunE(cmd, args)
+		}
+		return nil
+	}
 }
 
 type prettyPrintable interface {
@@ -23,14 +36,21 @@ type prettyPrintable interface {
 }
 
 func render(obj prettyPrintable, writer io.Writer, outputFormat string) error {
-	switch outputFormat {
-	case jsonFormat:
-		encoder := json.NewEncoder(writer)
-		encoder.SetIndent("", "  ")
-		return encoder.Encode(obj)
-	case "":
-		return obj.prettyPrintTo(writer)
-	default:
-		return fmt.Errorf("invalid format: %s", outputFormat)
+	return output.Write(writer, outputFormat, obj, obj.prettyPrintTo)
+}
+
+// operationResult is the result of the commands which only print a message
+// once they succeed
+type operationResult struct {
+	Success bool                         `json:"success"`
+	Error   *crcErrors.SerializableError `json:"error,omitempty"`
+	message string
+}
+
+func (s *operationResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
 	}
+	_, err := fmt.Fprintln(writer, s.message)
+	return err
 }
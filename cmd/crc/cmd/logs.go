@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	followUnitLogs bool
+	unitLogsLines  int
+)
+
+func init() {
+	logsCmd.Flags().BoolVarP(&followUnitLogs, "follow", "f", false, "Keep printing the new messages until interrupted")
+	logsCmd.Flags().IntVarP(&unitLogsLines, "lines", "n", 0, "Number of the last messages to print, all of them if 0")
+	rootCmd.AddCommand(logsCmd)
+}
+
+var logsCmd = &cobra.Command{
+	Use:   "logs [--follow] [--lines N] UNIT",
+	Short: "Print the logs of a systemd unit of the VM",
+	Long:  "Print the journal of a systemd unit of the virtual machine, such as kubelet or crio",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer cancel()
+		return runLogs(ctx, os.Stdout, newMachine(), types.UnitLogsConfig{
+			Unit:   args[0],
+			Lines:  unitLogsLines,
+			Follow: followUnitLogs,
+		})
+	},
+}
+
+func runLogs(ctx context.Context, writer io.Writer, client machine.Client, logsConfig types.UnitLogsConfig) error {
+	if err := checkIfMachineMissing(client); err != nil {
+		return err
+	}
+	return client.TailUnitLogs(ctx, logsConfig, writer)
+}
@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogs(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runLogs(context.Background(), out, fakemachine.NewClient(), types.UnitLogsConfig{Unit: "kubelet"}))
+	assert.Equal(t, "2021-10-17T12:00:00+0000 crc systemd[1]: Started kubelet.\n", out.String())
+
+	assert.EqualError(t, runLogs(context.Background(), out, fakemachine.NewFailingClient(), types.UnitLogsConfig{Unit: "kubelet"}), "tailing unit logs failed")
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addOutputFormatFlag(pauseCmd)
+	rootCmd.AddCommand(pauseCmd)
+}
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause the virtual machine",
+	Long: "Suspend the virtual machine in memory, it then uses no CPU and 'crc resume' brings it back in a few seconds. " +
+		"This is not supported with hyperkit",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPause(os.Stdout, newMachine(), outputFormat)
+	},
+}
+
+func runPause(writer io.Writer, client machine.Client, outputFormat string) error {
+	err := checkIfMachineMissing(client)
+	if err == nil {
+		err = client.Pause(types.PauseConfig{})
+	}
+	return render(&hibernateResult{
+		Success: err == nil,
+		Error:   crcErrors.ToSerializableError(err),
+		message: "The virtual machine is paused, use 'crc resume' to resume it",
+	}, writer, outputFormat)
+}
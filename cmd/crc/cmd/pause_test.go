@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPausePlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runPause(out, fakemachine.NewClient(), ""))
+	assert.Equal(t, "The virtual machine is paused, use 'crc resume' to resume it\n", out.String())
+}
+
+func TestPauseJSONError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runPause(out, fakemachine.NewFailingClient(), jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": false, "error": "pause failed"}`, out.String())
+}
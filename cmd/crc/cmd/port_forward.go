@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/network/forward"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	portForwardCmd.AddCommand(portForwardAddCmd)
+	portForwardCmd.AddCommand(portForwardRemoveCmd)
+	addOutputFormatFlag(portForwardListCmd)
+	portForwardCmd.AddCommand(portForwardListCmd)
+	rootCmd.AddCommand(portForwardCmd)
+}
+
+var portForwardCmd = &cobra.Command{
+	Use:   "port-forward SUBCOMMAND [flags]",
+	Short: "Manage the forwards of host ports to the VM",
+	Long: "Forward ports of the host to ports of the virtual machine, for instance to reach node ports or the internal registry on localhost. " +
+		"The forwards are applied by the daemon while the virtual machine is running and are removed when it is deleted",
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var portForwardAddCmd = &cobra.Command{
+	Use:   "add [HOST:]PORT:VM-PORT",
+	Short: "Forward a port of the host to a port of the VM",
+	Long:  "Forward a port of the host to a port of the virtual machine, the host port listens on 127.0.0.1 unless HOST is given",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("Please provide the port to forward as in 'crc port-forward add 8080:30080'")
+		}
+		return runPortForwardAdd(os.Stdout, newMachine(), args[0])
+	},
+}
+
+var portForwardRemoveCmd = &cobra.Command{
+	Use:   "remove [HOST:]PORT",
+	Short: "Stop forwarding a port of the host to the VM",
+	Long:  "Stop forwarding a port of the host to the virtual machine",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return errors.New("Please provide the forwarded port as in 'crc port-forward remove 8080'")
+		}
+		return runPortForwardRemove(os.Stdout, newMachine(), args[0])
+	},
+}
+
+var portForwardListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the forwards of host ports to the VM",
+	Long:  "List the forwards of host ports to the virtual machine",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runPortForwardList(os.Stdout, newMachine(), outputFormat)
+	},
+}
+
+func runPortForwardAdd(writer io.Writer, client machine.Client, spec string) error {
+	portForward, err := forward.Parse(spec)
+	if err != nil {
+		return err
+	}
+	if err := checkIfMachineMissing(client); err != nil {
+		return err
+	}
+	if err := client.AddPortForward(portForward); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(writer, "Forwarding %s to port %d of the VM while it is running\n", portForward.Local, portForward.Remote)
+	return err
+}
+
+func runPortForwardRemove(writer io.Writer, client machine.Client, local string) error {
+	if err := checkIfMachineMissing(client); err != nil {
+		return err
+	}
+	if err := client.RemovePortForward(local); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(writer, "%s is no longer forwarded\n", local)
+	return err
+}
+
+func runPortForwardList(writer io.Writer, client machine.Client, outputFormat string) error {
+	var forwards []forward.Forward
+	err := checkIfMachineMissing(client)
+	if err == nil {
+		forwards, err = client.ListPortForwards()
+	}
+	return render(&portForwardListResult{
+		Success:  err == nil,
+		Error:    crcErrors.ToSerializableError(err),
+		Forwards: forwards,
+	}, writer, outputFormat)
+}
+
+type portForwardListResult struct {
+	Success  bool                         `json:"success"`
+	Error    *crcErrors.SerializableError `json:"error,omitempty"`
+	Forwards []forward.Forward            `json:"forwards,omitempty"`
+}
+
+func (s *portForwardListResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if len(s.Forwards) == 0 {
+		_, err := fmt.Fprintln(writer, "No port is forwarded")
+		return err
+	}
+	w := tabwriter.NewWriter(writer, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(w, "HOST\tVM PORT")
+	for _, portForward := range s.Forwards {
+		fmt.Fprintf(w, "%s\t%d\n", portForward.Local, portForward.Remote)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPortForwardAdd(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runPortForwardAdd(out, fakemachine.NewClient(), "8080:30080"))
+	assert.Equal(t, "Forwarding 127.0.0.1:8080 to port 30080 of the VM while it is running\n", out.String())
+
+	assert.Error(t, runPortForwardAdd(out, fakemachine.NewClient(), "8080"))
+	assert.EqualError(t, runPortForwardAdd(out, fakemachine.NewFailingClient(), "8080:30080"), "port forward failed")
+}
+
+func TestPortForwardListPlain(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runPortForwardList(out, fakemachine.NewClient(), ""))
+	assert.Equal(t, "HOST             VM PORT\n127.0.0.1:8080   30080\n", out.String())
+}
+
+func TestPortForwardListJSON(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runPortForwardList(out, fakemachine.NewClient(), jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": true, "forwards": [{"local": "127.0.0.1:8080", "remote": 30080}]}`, out.String())
+
+	out.Reset()
+	assert.NoError(t, runPortForwardList(out, fakemachine.NewFailingClient(), jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": false, "error": "port forwards failed"}`, out.String())
+}
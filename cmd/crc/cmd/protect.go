@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/spf13/cobra"
+)
+
+var (
+	removeProtection bool
+	protectionToken  string
+)
+
+func init() {
+	protectCmd.Flags().BoolVar(&removeProtection, "remove", false, "Remove the protection of the OpenShift cluster")
+	protectCmd.Flags().StringVar(&protectionToken, "token", "", "Protection token, needed with --remove")
+	addOutputFormatFlag(protectCmd)
+	rootCmd.AddCommand(protectCmd)
+}
+
+var protectCmd = &cobra.Command{
+	Use:   "protect",
+	Short: "Protect the OpenShift cluster against deletion",
+	Long: "Prevent the OpenShift cluster from being deleted or powered off unless the protection token is provided, " +
+		"the cluster stays protected after such an operation until 'crc protect --remove' is used",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProtect(os.Stdout, newMachine(), removeProtection, protectionToken, outputFormat)
+	},
+}
+
+func runProtect(writer io.Writer, client machine.Client, remove bool, token string, outputFormat string) error {
+	result := &protectResult{removed: remove}
+	err := checkIfMachineMissing(client)
+	if err == nil {
+		if remove {
+			err = client.Unprotect(token)
+		} else {
+			result.Token, err = client.Protect()
+		}
+	}
+	result.Success = err == nil
+	result.Error = crcErrors.ToSerializableError(err)
+	return render(result, writer, outputFormat)
+}
+
+type protectResult struct {
+	Success bool                         `json:"success"`
+	Error   *crcErrors.SerializableError `json:"error,omitempty"`
+	Token   string                       `json:"token,omitempty"`
+	removed bool
+}
+
+func (s *protectResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if s.removed {
+		_, err := fmt.Fprintln(writer, "The OpenShift cluster is no longer protected")
+		return err
+	}
+	_, err := fmt.Fprintf(writer, "The OpenShift cluster is protected, use 'crc delete --override-token %s' to delete it\n", s.Token)
+	return err
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtectPlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runProtect(out, fakemachine.NewClient(), false, "", ""))
+	assert.Equal(t, "The OpenShift cluster is protected, use 'crc delete --override-token 9f8e7d6c5b4a3210' to delete it\n", out.String())
+}
+
+func TestProtectJSONSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runProtect(out, fakemachine.NewClient(), false, "", jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": true, "token": "9f8e7d6c5b4a3210"}`, out.String())
+}
+
+func TestUnprotectPlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runProtect(out, fakemachine.NewClient(), true, fakemachine.DummyProtectionToken, ""))
+	assert.Equal(t, "The OpenShift cluster is no longer protected\n", out.String())
+}
+
+func TestUnprotectJSONError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runProtect(out, fakemachine.NewClient(), true, "0000", jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": false, "error": "invalid protection token"}`, out.String())
+}
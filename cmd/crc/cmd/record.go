@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"time"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/timeline"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+var recordDir string
+
+func init() {
+	rootCmd.PersistentFlags().StringVar(&recordDir, "record", "",
+		"Directory where the lifecycle phases, cluster events, logs and output of the command are recorded as a timeline (timeline.json and timeline.html), "+
+			"the timelines of the commands recorded in the same directory are merged")
+}
+
+// recording is the recording of the running command, nil when --record is
+// not used
+var recording *commandRecording
+
+type commandRecording struct {
+	dir      string
+	command  string
+	start    time.Time
+	recorder *timeline.Recorder
+
+	stdout       *os.File
+	stdoutWriter *os.File
+	outputDone   chan struct{}
+}
+
+// startRecording starts recording the command once the logging is set up
+func startRecording(cmd *cobra.Command) error {
+	if recordDir == "" {
+		return nil
+	}
+	if _, err := timeline.Load(recordDir); err != nil {
+		return err
+	}
+	recorder := timeline.FromContext(cmd.Context())
+	recorder.Start()
+	// the values changed by the command are redacted when it stops
+	recorder.Redact(recordedSecrets()...)
+	logrus.AddHook(recorder.LogHook())
+	r := &commandRecording{
+		dir:      recordDir,
+		command:  cmd.CommandPath(),
+		start:    time.Now(),
+		recorder: recorder,
+	}
+	if err := r.captureStdout(); err != nil {
+		return err
+	}
+	recording = r
+	return nil
+}
+
+// captureStdout records the lines written to the standard output while they
+// are still written to it
+func (r *commandRecording) captureStdout() error {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	r.stdout = os.Stdout
+	r.stdoutWriter = writer
+	r.outputDone = make(chan struct{})
+	os.Stdout = writer
+	go func() {
+		defer close(r.outputDone)
+		scanner := bufio.NewScanner(io.TeeReader(reader, r.stdout))
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			r.recorder.Output(scanner.Text())
+		}
+		// keep forwarding the output when a line is too long to be recorded
+		_, _ = io.Copy(r.stdout, reader)
+		reader.Close()
+	}()
+	return nil
+}
+
+// recordedSecrets returns the credentials which must not be written to the
+// timeline, the log messages and the output can contain them
+func recordedSecrets() []string {
+	secrets := crcConfig.RedactedValues(config)
+	password, err := machine.KubeAdminPassword(globalProfile)
+	if err != nil {
+		logging.Debugf("Cannot read the kubeadmin password to redact it: %v", err)
+	} else {
+		secrets = append(secrets, password)
+	}
+	return secrets
+}
+
+// stopRecording records the end of the command and exports the timeline
+func stopRecording(err error) {
+	if recording == nil {
+		return
+	}
+	os.Stdout = recording.stdout
+	recording.stdoutWriter.Close()
+	<-recording.outputDone
+
+	recording.recorder.Span(timeline.CommandKind, recording.command, time.Since(recording.start), err)
+	recording.recorder.Redact(recordedSecrets()...)
+	if err := timeline.Save(recording.dir, recording.recorder.Events()); err != nil {
+		logging.Errorf("Failed to save the timeline to %s: %v", recording.dir, err)
+	}
+	recording = nil
+}
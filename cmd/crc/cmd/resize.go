@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/spf13/cobra"
+)
+
+var resizeFlags types.VMConfig
+
+func init() {
+	resizeCmd.Flags().IntVarP(&resizeFlags.Memory, crcConfig.Memory, "m", 0, "MiB of memory to allocate to the OpenShift cluster")
+	resizeCmd.Flags().IntVarP(&resizeFlags.CPUs, crcConfig.CPUs, "c", 0, "Number of CPU cores to allocate to the OpenShift cluster")
+	resizeCmd.Flags().IntVarP(&resizeFlags.DiskSize, crcConfig.DiskSize, "d", 0, "Total size in GiB of the disk used by the OpenShift cluster, it can only grow")
+	addOutputFormatFlag(resizeCmd)
+	rootCmd.AddCommand(resizeCmd)
+}
+
+var resizeCmd = &cobra.Command{
+	Use:   "resize",
+	Short: "Change the memory, the CPUs and the disk size of the instance",
+	Long: "Change the memory, the CPUs and the disk size of the existing instance and store them in the configuration. " +
+		"They are applied right away to a stopped instance. The disk of a running instance is grown right away too, " +
+		"its memory and CPUs are changed on its next start.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if resizeFlags.Memory == 0 && resizeFlags.CPUs == 0 && resizeFlags.DiskSize == 0 {
+			return fmt.Errorf("one of --%s, --%s or --%s is needed", crcConfig.Memory, crcConfig.CPUs, crcConfig.DiskSize)
+		}
+		return runResize(os.Stdout, newMachine(), resizeFlags, outputFormat)
+	},
+}
+
+func runResize(writer io.Writer, client machine.Client, vmConfig types.VMConfig, outputFormat string) error {
+	var result *types.SetConfigResult
+	err := checkIfMachineMissing(client)
+	if err == nil {
+		result, err = client.SetConfig(vmConfig)
+	}
+	resize := &resizeResult{
+		Success: err == nil,
+		Error:   crcErrors.ToSerializableError(err),
+	}
+	if result != nil {
+		for _, change := range result.Changes {
+			resize.ResourceChanges = append(resize.ResourceChanges, resourceChange{
+				Resource: change.Resource,
+				Previous: change.Previous,
+				Current:  change.Current,
+			})
+			resize.changes = append(resize.changes, fmt.Sprintf("The instance now uses %s instead of %s", change.Format(change.Current), change.Format(change.Previous)))
+		}
+		for _, conflict := range result.Conflicts {
+			resize.ResourceConflicts = append(resize.ResourceConflicts, resourceConflict{
+				Resource:  conflict.Resource,
+				Current:   conflict.Current,
+				Requested: conflict.Requested,
+			})
+			resize.changes = append(resize.changes, fmt.Sprintf("The running instance uses %s, %s is applied on the next start", conflict.Format(conflict.Current), conflict.Format(conflict.Requested)))
+		}
+	}
+	return render(resize, writer, outputFormat)
+}
+
+type resizeResult struct {
+	Success           bool                         `json:"success"`
+	Error             *crcErrors.SerializableError `json:"error,omitempty"`
+	ResourceChanges   []resourceChange             `json:"resourceChanges,omitempty"`
+	ResourceConflicts []resourceConflict           `json:"resourceConflicts,omitempty"`
+
+	changes []string
+}
+
+func (s *resizeResult) prettyPrintTo(writer io.Writer) error {
+	if s.Error != nil {
+		return s.Error
+	}
+	if len(s.changes) == 0 {
+		return errors.New("either a change or a conflict is needed")
+	}
+	for _, change := range s.changes {
+		if _, err := fmt.Fprintln(writer, change); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResizePlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runResize(out, fakemachine.NewClient(), types.VMConfig{Memory: 12288, CPUs: 6}, ""))
+	assert.Equal(t, "The instance now uses 12288 MiB of memory instead of 9216 MiB of memory\nThe instance now uses 6 cpus instead of 4 cpus\n", out.String())
+}
+
+func TestResizeDiskSize(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runResize(out, fakemachine.NewClient(), types.VMConfig{DiskSize: 50}, ""))
+	assert.Equal(t, "The instance now uses a 50 GiB disk instead of a 31 GiB disk\n", out.String())
+}
+
+func TestResizePlainError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.EqualError(t, runResize(out, fakemachine.NewFailingClient(), types.VMConfig{CPUs: 6}, ""), "VM configuration change failed")
+}
+
+func TestResizeJSONSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runResize(out, fakemachine.NewClient(), types.VMConfig{CPUs: 6}, jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": true, "resourceChanges": [{"resource": "cpus", "previous": 4, "current": 6}]}`, out.String())
+}
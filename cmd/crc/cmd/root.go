@@ -15,10 +15,12 @@ import (
 	crcErr "github.com/code-ready/crc/pkg/crc/errors"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/profile"
 	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/preflight"
 	"github.com/code-ready/crc/pkg/crc/segment"
 	"github.com/code-ready/crc/pkg/crc/telemetry"
+	"github.com/code-ready/crc/pkg/crc/timeline"
 	"github.com/spf13/cobra"
 	"k8s.io/client-go/util/exec"
 )
@@ -40,6 +42,7 @@ var rootCmd = &cobra.Command{
 
 var (
 	globalForce   bool
+	globalProfile string
 	viper         *crcConfig.ViperStorage
 	config        *crcConfig.Config
 	segmentClient *segment.Client
@@ -55,7 +58,7 @@ func init() {
 		logging.Fatal(err.Error())
 	}
 
-	if err := setProxyDefaults(); err != nil {
+	if err := setProxyDefaults(nil); err != nil {
 		logging.Fatal(err.Error())
 	}
 
@@ -65,10 +68,13 @@ func init() {
 	}
 
 	// subcommands
-	rootCmd.AddCommand(cmdConfig.GetConfigCmd(config))
-	rootCmd.AddCommand(cmdBundle.GetBundleCmd(config))
+	rootCmd.AddCommand(cmdConfig.GetConfigCmd(config, &globalProfile))
+	rootCmd.AddCommand(cmdBundle.GetBundleCmd(config, &globalProfile))
 
+	rootCmd.PersistentFlags().StringVarP(&globalProfile, "profile", "p", constants.DefaultName,
+		"Name of the instance the command operates on, each profile has its own VM, kubeconfig contexts and cluster domain")
 	logging.AddLogLevelFlag(rootCmd.PersistentFlags())
+	logging.AddDebugCategoriesFlag(rootCmd.PersistentFlags())
 }
 
 func runPrerun(cmd *cobra.Command) error {
@@ -78,10 +84,18 @@ func runPrerun(cmd *cobra.Command) error {
 		logFile = constants.DaemonLogFilePath
 	}
 	logging.InitLogrus(logFile)
+	if err := startRecording(cmd); err != nil {
+		return err
+	}
 
 	for _, str := range defaultVersion().lines() {
 		logging.Debugf(str)
 	}
+	instanceProfile, err := profile.New(globalProfile)
+	if err != nil {
+		return err
+	}
+	globalProfile = instanceProfile.Name
 	return nil
 }
 
@@ -102,7 +116,8 @@ const (
 func Execute() {
 	attachMiddleware([]string{}, rootCmd)
 
-	if err := rootCmd.ExecuteContext(telemetry.NewContext(context.Background())); err != nil {
+	if err := rootCmd.ExecuteContext(timeline.NewContext(telemetry.NewContext(context.Background()))); err != nil {
+		stopRecording(err)
 		runPostrun()
 		_, _ = fmt.Fprintln(os.Stderr, err.Error())
 		var e exec.CodeExitError
@@ -112,6 +127,7 @@ func Execute() {
 			os.Exit(defaultErrorExitCode)
 		}
 	}
+	stopRecording(nil)
 	runPostrun()
 }
 
@@ -126,20 +142,18 @@ func checkIfMachineMissing(client machine.Client) error {
 	return nil
 }
 
-func setProxyDefaults() error {
-	httpProxy := config.Get(crcConfig.HTTPProxy).AsString()
-	httpsProxy := config.Get(crcConfig.HTTPSProxy).AsString()
-	noProxy := config.Get(crcConfig.NoProxy).AsString()
-	proxyCAFile := config.Get(crcConfig.ProxyCAFile).AsString()
-
-	proxyConfig, err := network.NewProxyDefaults(httpProxy, httpsProxy, noProxy, proxyCAFile)
+// setProxyDefaults sets the proxy used by crc, the PAC file is only evaluated
+// with evaluatePAC, by the commands which need it
+func setProxyDefaults(evaluatePAC func(pacFile, target string) (string, error)) error {
+	settings := crcConfig.GetProxySettings(config)
+	settings.EvaluatePAC = evaluatePAC
+	proxyConfig, err := network.NewProxyDefaultsFromSettings(settings)
 	if err != nil {
 		return err
 	}
 
 	if proxyConfig.IsEnabled() {
 		logging.Debugf(proxyConfig.String())
-		proxyConfig.ApplyToEnvironment()
 	}
 	return nil
 }
@@ -156,7 +170,7 @@ func newViperConfig() (*crcConfig.Config, *crcConfig.ViperStorage, error) {
 }
 
 func newMachine() machine.Client {
-	return machine.NewSynchronizedMachine(machine.NewClient(constants.DefaultName, logging.IsDebug(), config))
+	return machine.NewSynchronizedMachine(machine.NewClient(globalProfile, logging.IsDebug(), config))
 }
 
 func addForceFlag(cmd *cobra.Command) {
@@ -32,7 +32,7 @@ func TestSetupRenderActionJSONSuccess(t *testing.T) {
 	assert.NoError(t, render(&setupResult{
 		Success: true,
 	}, out, jsonFormat))
-	assert.JSONEq(t, `{"success": true}`, out.String())
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": true}`, out.String())
 }
 
 func TestSetupRenderActionJSONFailure(t *testing.T) {
@@ -41,5 +41,5 @@ func TestSetupRenderActionJSONFailure(t *testing.T) {
 		Success: false,
 		Error:   crcErrors.ToSerializableError(errors.New("broken")),
 	}, out, jsonFormat))
-	assert.JSONEq(t, `{"success": false, "error": "broken"}`, out.String())
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": false, "error": "broken"}`, out.String())
 }
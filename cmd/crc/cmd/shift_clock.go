@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/spf13/cobra"
+)
+
+var revertClock bool
+
+func init() {
+	shiftClockCmd.Flags().BoolVar(&revertClock, "revert", false, "Set the clock of the VM back to the one of the host")
+	addOutputFormatFlag(shiftClockCmd)
+	rootCmd.AddCommand(shiftClockCmd)
+}
+
+var shiftClockCmd = &cobra.Command{
+	Use:   "shift-clock [--revert] [OFFSET]",
+	Short: "Move the clock of the VM to test the certificates expiry",
+	Long: "Move the clock of the running VM OFFSET, such as 744h, away from the one of the host and stop its network time " +
+		"synchronization, so that the expiry and the renewal of the cluster certificates can be tested without waiting for them. " +
+		"The offset is kept across restarts until it is reverted, a stopped VM can only be reverted. The certificates renewed while " +
+		"the clock is shifted are not valid before the shifted time, the clock cannot be moved back before it and the instance " +
+		"has to be deleted to get back to the clock of the host. This is meant for the developers of crc.",
+	Hidden: true,
+	Args:   cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var offset time.Duration
+		switch {
+		case revertClock && len(args) == 0:
+		case !revertClock && len(args) == 1:
+			var err error
+			if offset, err = time.ParseDuration(args[0]); err != nil {
+				return fmt.Errorf("Invalid clock offset '%s': %v", args[0], err)
+			}
+		default:
+			return fmt.Errorf("either an offset or --revert is needed")
+		}
+		return runShiftClock(os.Stdout, newMachine(), offset, outputFormat)
+	},
+}
+
+func runShiftClock(writer io.Writer, client machine.Client, offset time.Duration, outputFormat string) error {
+	err := checkIfMachineMissing(client)
+	if err == nil {
+		err = client.ShiftClock(offset)
+	}
+	message := fmt.Sprintf("The clock of the VM is shifted by %s from the one of the host", offset)
+	if offset == 0 {
+		message = "The clock of the VM is the one of the host"
+	}
+	return render(&operationResult{
+		Success: err == nil,
+		Error:   crcErrors.ToSerializableError(err),
+		message: message,
+	}, writer, outputFormat)
+}
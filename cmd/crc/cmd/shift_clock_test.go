@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShiftClockPlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runShiftClock(out, fakemachine.NewClient(), 744*time.Hour, ""))
+	assert.Equal(t, "The clock of the VM is shifted by 744h0m0s from the one of the host\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, runShiftClock(out, fakemachine.NewClient(), 0, ""))
+	assert.Equal(t, "The clock of the VM is the one of the host\n", out.String())
+}
+
+func TestShiftClockJSONError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runShiftClock(out, fakemachine.NewFailingClient(), 744*time.Hour, jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": false, "error": "clock shift failed"}`, out.String())
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addOutputFormatFlag(snapshotCmd)
+	rootCmd.AddCommand(snapshotCmd)
+	addOutputFormatFlag(revertCmd)
+	rootCmd.AddCommand(revertCmd)
+}
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot NAME",
+	Short: "Snapshot the disk of the stopped instance",
+	Long: "Checkpoint the disk of the stopped instance as NAME, a configured cluster can then be restored with " +
+		"'crc revert' instead of being deleted and created again from the bundle",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSnapshot(os.Stdout, newMachine(), args[0], outputFormat)
+	},
+}
+
+var revertCmd = &cobra.Command{
+	Use:   "revert NAME",
+	Short: "Restore the stopped instance to a snapshot",
+	Long:  "Restore the disk of the stopped instance to its snapshot NAME, the changes made to the cluster since the snapshot are lost",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRevert(os.Stdout, newMachine(), args[0], outputFormat)
+	},
+}
+
+func runSnapshot(writer io.Writer, client machine.Client, snapshotName string, outputFormat string) error {
+	err := checkIfMachineMissing(client)
+	if err == nil {
+		err = client.Snapshot(snapshotName)
+	}
+	return render(&operationResult{
+		Success: err == nil,
+		Error:   crcErrors.ToSerializableError(err),
+		message: fmt.Sprintf("Created the snapshot %s, use 'crc revert %s' to restore it", snapshotName, snapshotName),
+	}, writer, outputFormat)
+}
+
+func runRevert(writer io.Writer, client machine.Client, snapshotName string, outputFormat string) error {
+	err := checkIfMachineMissing(client)
+	if err == nil {
+		err = client.Revert(snapshotName)
+	}
+	return render(&operationResult{
+		Success: err == nil,
+		Error:   crcErrors.ToSerializableError(err),
+		message: fmt.Sprintf("Reverted the instance to the snapshot %s, use 'crc start' to start it", snapshotName),
+	}, writer, outputFormat)
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotPlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runSnapshot(out, fakemachine.NewClient(), "configured", ""))
+	assert.Equal(t, "Created the snapshot configured, use 'crc revert configured' to restore it\n", out.String())
+}
+
+func TestRevertPlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runRevert(out, fakemachine.NewClient(), "configured", ""))
+	assert.Equal(t, "Reverted the instance to the snapshot configured, use 'crc start' to start it\n", out.String())
+}
+
+func TestRevertJSONError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runRevert(out, fakemachine.NewFailingClient(), "configured", jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": false, "error": "revert failed"}`, out.String())
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/util/exec"
+)
+
+func init() {
+	rootCmd.AddCommand(sshCmd)
+}
+
+var sshCmd = &cobra.Command{
+	Use:   "ssh [-- COMMAND [ARG...]]",
+	Short: "Open an ssh session in the VM",
+	Long: "Open an interactive shell in the virtual machine, or run COMMAND in it, as the user of the bundle and with the keys used by crc. " +
+		"The exit status of the command is the one of 'crc ssh'",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSSH(os.Stdin, os.Stdout, os.Stderr, newMachine(), args)
+	},
+}
+
+func runSSH(stdin io.Reader, stdout, stderr io.Writer, client machine.Client, command []string) error {
+	if err := checkIfMachineMissing(client); err != nil {
+		return err
+	}
+	err := client.SSH(types.SSHConfig{
+		Command: command,
+		Stdin:   stdin,
+		Stdout:  stdout,
+		Stderr:  stderr,
+	})
+	if status, ok := crcssh.ExitStatus(err); ok {
+		return exec.CodeExitError{Err: err, Code: status}
+	}
+	return err
+}
@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSH(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runSSH(strings.NewReader(""), out, out, fakemachine.NewClient(), []string{"hostname"}))
+	assert.Equal(t, "crc\n", out.String())
+
+	assert.EqualError(t, runSSH(strings.NewReader(""), out, out, fakemachine.NewFailingClient(), nil), "ssh failed")
+}
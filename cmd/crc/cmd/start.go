@@ -9,6 +9,7 @@ import (
 	"runtime"
 	"strings"
 	"text/template"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/code-ready/crc/pkg/crc/cluster"
@@ -16,12 +17,15 @@ import (
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/daemonclient"
 	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/input"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/network/pac"
 	"github.com/code-ready/crc/pkg/crc/preflight"
 	"github.com/code-ready/crc/pkg/crc/validation"
 	crcversion "github.com/code-ready/crc/pkg/crc/version"
+	"github.com/code-ready/crc/pkg/download"
 	crcos "github.com/code-ready/crc/pkg/os"
 	"github.com/code-ready/crc/pkg/os/shell"
 	pkgerrors "github.com/pkg/errors"
@@ -35,16 +39,20 @@ func init() {
 
 	flagSet := pflag.NewFlagSet("start", pflag.ExitOnError)
 	flagSet.StringP(crcConfig.Bundle, "b", constants.DefaultBundlePath, "The system bundle used for deployment of the OpenShift cluster")
-	flagSet.StringP(crcConfig.PullSecretFile, "p", "", fmt.Sprintf("File path of image pull secret (download from %s)", constants.CrcLandingPageURL))
-	flagSet.IntP(crcConfig.CPUs, "c", constants.DefaultCPUs, "Number of CPU cores to allocate to the OpenShift cluster")
-	flagSet.IntP(crcConfig.Memory, "m", constants.DefaultMemory, "MiB of memory to allocate to the OpenShift cluster")
+	flagSet.String(crcConfig.PullSecretFile, "", fmt.Sprintf("File path of image pull secret (download from %s)", constants.CrcLandingPageURL))
+	flagSet.IntP(crcConfig.CPUs, "c", constants.DefaultCPUs, "Number of CPU cores to allocate to the OpenShift cluster (chosen from the host CPUs when not set)")
+	flagSet.IntP(crcConfig.Memory, "m", constants.DefaultMemory, "MiB of memory to allocate to the OpenShift cluster (chosen from the host memory when not set)")
 	flagSet.UintP(crcConfig.DiskSize, "d", constants.DefaultDiskSize, "Total size in GiB of the disk used by the OpenShift cluster")
 	flagSet.StringP(crcConfig.NameServer, "n", "", "IPv4 address of nameserver to use for the OpenShift cluster")
 	flagSet.Bool(crcConfig.DisableUpdateCheck, false, "Don't check for update")
+	flagSet.Bool(crcConfig.SkipBundleSignature, false, "Extract the bundle even when its signature is missing or invalid")
 
 	startCmd.Flags().AddFlagSet(flagSet)
+	startCmd.Flags().BoolVar(&repairDriver, "repair-driver", false, "Repair the virtualization stack without asking, for instance by restarting libvirt or recreating the crc network, when the preflight checks find it broken")
 }
 
+var repairDriver bool
+
 var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start the OpenShift cluster",
@@ -61,6 +69,9 @@ func runStart(ctx context.Context) (*types.StartResult, error) {
 	if err := validateStartFlags(); err != nil {
 		return nil, err
 	}
+	if err := setProxyDefaults(pac.ProxyFor); err != nil {
+		return nil, err
+	}
 
 	if err := checkIfNewVersionAvailable(config.Get(crcConfig.DisableUpdateCheck).AsBool()); err != nil {
 		logging.Debugf("Unable to find out if a new version is available: %v", err)
@@ -68,9 +79,9 @@ func runStart(ctx context.Context) (*types.StartResult, error) {
 
 	startConfig := types.StartConfig{
 		BundlePath:        config.Get(crcConfig.Bundle).AsString(),
-		Memory:            config.Get(crcConfig.Memory).AsInt(),
+		Memory:            config.Get(crcConfig.Memory).AsAutomaticInt(),
 		DiskSize:          config.Get(crcConfig.DiskSize).AsInt(),
-		CPUs:              config.Get(crcConfig.CPUs).AsInt(),
+		CPUs:              config.Get(crcConfig.CPUs).AsAutomaticInt(),
 		NameServer:        config.Get(crcConfig.NameServer).AsString(),
 		PullSecret:        cluster.NewInteractivePullSecretLoader(config),
 		KubeAdminPassword: config.Get(crcConfig.KubeAdminPassword).AsString(),
@@ -85,9 +96,19 @@ func runStart(ctx context.Context) (*types.StartResult, error) {
 		}
 
 		if err := preflight.StartPreflightChecks(config); err != nil {
-			return nil, crcos.CodeExitError{
-				Err:  err,
-				Code: preflightFailedExitCode,
+			// the preflight checks are run again when the virtualization
+			// stack was broken and it could be repaired
+			if !repairDriverStack(repairDriver) {
+				return nil, crcos.CodeExitError{
+					Err:  err,
+					Code: preflightFailedExitCode,
+				}
+			}
+			if err := preflight.StartPreflightChecks(config); err != nil {
+				return nil, crcos.CodeExitError{
+					Err:  err,
+					Code: preflightFailedExitCode,
+				}
 			}
 		}
 	}
@@ -95,14 +116,96 @@ func runStart(ctx context.Context) (*types.StartResult, error) {
 	return client.Start(ctx, startConfig)
 }
 
+// repairDriverStack offers to repair the virtualization stack used by the
+// machine driver when it is broken, a common issue after an update of the
+// host. It returns whether the stack was repaired.
+func repairDriverStack(force bool) bool {
+	results := preflight.DriverHealth(config)
+	if preflight.Healthy(results) {
+		return false
+	}
+	for _, result := range results {
+		if !result.Success {
+			logging.Warnf("%s: %s", result.Name, result.Error)
+		}
+	}
+	if !input.PromptUserForYesOrNo("The virtualization stack is broken, do you want to repair it", force) {
+		return false
+	}
+	results = preflight.RepairDriver(config)
+	for _, result := range results {
+		if !result.Success {
+			logging.Errorf("Cannot repair %s: %s", result.Name, result.Error)
+		}
+	}
+	return preflight.Healthy(results)
+}
+
 func renderStartResult(result *types.StartResult, err error) error {
 	return render(&startResult{
-		Success:       err == nil,
-		Error:         crcErrors.ToSerializableError(err),
-		ClusterConfig: toClusterConfig(result),
+		Success:           err == nil,
+		Error:             crcErrors.ToSerializableError(err),
+		AlreadyRunning:    result != nil && result.AlreadyRunning,
+		ClusterConfig:     toClusterConfig(result),
+		ResourceConflicts: toResourceConflicts(result),
+		ResourceChanges:   toResourceChanges(result),
+		Summary:           toStartSummary(result),
 	}, os.Stdout, outputFormat)
 }
 
+func toStartSummary(result *types.StartResult) *startSummary {
+	if result == nil || result.Summary == nil {
+		return nil
+	}
+	summary := &startSummary{
+		ConsoleURL:     result.Summary.ConsoleURL,
+		APIURL:         result.Summary.APIURL,
+		KubeconfigPath: result.Summary.KubeconfigPath,
+		OcEnvCommand:   result.Summary.OcEnvCommand,
+		NextSteps:      result.Summary.NextSteps,
+	}
+	for _, l := range result.Summary.Logins {
+		summary.Logins = append(summary.Logins, login{
+			Role:         l.Role,
+			Username:     l.Username,
+			Password:     l.Password,
+			Context:      l.Context,
+			LoginCommand: l.LoginCommand,
+		})
+	}
+	return summary
+}
+
+func toResourceConflicts(result *types.StartResult) []resourceConflict {
+	if result == nil {
+		return nil
+	}
+	var conflicts []resourceConflict
+	for _, conflict := range result.ResourceConflicts {
+		conflicts = append(conflicts, resourceConflict{
+			Resource:  conflict.Resource,
+			Current:   conflict.Current,
+			Requested: conflict.Requested,
+		})
+	}
+	return conflicts
+}
+
+func toResourceChanges(result *types.StartResult) []resourceChange {
+	if result == nil {
+		return nil
+	}
+	var changes []resourceChange
+	for _, change := range result.ResourceChanges {
+		changes = append(changes, resourceChange{
+			Resource: change.Resource,
+			Previous: change.Previous,
+			Current:  change.Current,
+		})
+	}
+	return changes
+}
+
 func toClusterConfig(result *types.StartResult) *clusterConfig {
 	if result == nil {
 		return nil
@@ -135,10 +238,71 @@ type credentials struct {
 	Password string `json:"password"`
 }
 
+type resourceConflict struct {
+	Resource  string `json:"resource"`
+	Current   int    `json:"current"`
+	Requested int    `json:"requested"`
+}
+
+type resourceChange struct {
+	Resource string `json:"resource"`
+	Previous int    `json:"previous"`
+	Current  int    `json:"current"`
+}
+
+type login struct {
+	Role         string `json:"role"`
+	Username     string `json:"username"`
+	Password     string `json:"password"`
+	Context      string `json:"context"`
+	LoginCommand string `json:"loginCommand"`
+}
+
+type startSummary struct {
+	ConsoleURL     string   `json:"consoleUrl"`
+	APIURL         string   `json:"apiUrl"`
+	KubeconfigPath string   `json:"kubeconfigPath"`
+	Logins         []login  `json:"logins"`
+	OcEnvCommand   string   `json:"ocEnvCommand"`
+	NextSteps      []string `json:"nextSteps"`
+}
+
 type startResult struct {
-	Success       bool                         `json:"success"`
-	Error         *crcErrors.SerializableError `json:"error,omitempty"`
-	ClusterConfig *clusterConfig               `json:"clusterConfig,omitempty"`
+	Success           bool                         `json:"success"`
+	Error             *crcErrors.SerializableError `json:"error,omitempty"`
+	AlreadyRunning    bool                         `json:"alreadyRunning,omitempty"`
+	ClusterConfig     *clusterConfig               `json:"clusterConfig,omitempty"`
+	ResourceConflicts []resourceConflict           `json:"resourceConflicts,omitempty"`
+	ResourceChanges   []resourceChange             `json:"resourceChanges,omitempty"`
+	Summary           *startSummary                `json:"summary,omitempty"`
+}
+
+// summary returns the summary of the start, it is built from the cluster
+// configuration for the results which do not have one
+func (s *startResult) summary() *startSummary {
+	if s.Summary != nil {
+		return s.Summary
+	}
+	developerLogin := fmt.Sprintf("oc login -u %s %s", s.ClusterConfig.DeveloperCredentials.Username, s.ClusterConfig.URL)
+	return &startSummary{
+		ConsoleURL: s.ClusterConfig.WebConsoleURL,
+		APIURL:     s.ClusterConfig.URL,
+		Logins: []login{
+			{
+				Role:     types.AdminRole,
+				Username: s.ClusterConfig.AdminCredentials.Username,
+				Password: s.ClusterConfig.AdminCredentials.Password,
+			},
+			{
+				Role:         types.DeveloperRole,
+				Username:     s.ClusterConfig.DeveloperCredentials.Username,
+				Password:     s.ClusterConfig.DeveloperCredentials.Password,
+				LoginCommand: developerLogin,
+			},
+		},
+		OcEnvCommand: "crc oc-env",
+		NextSteps:    []string{developerLogin},
+	}
 }
 
 func (s *startResult) prettyPrintTo(writer io.Writer) error {
@@ -149,7 +313,7 @@ func (s *startResult) prettyPrintTo(writer io.Writer) error {
 		}
 		return s.Error
 	}
-	if s.ClusterConfig == nil {
+	if s.ClusterConfig == nil && s.Summary == nil {
 		return errors.New("either Error or ClusterConfig is needed")
 	}
 
@@ -177,8 +341,12 @@ func validateStartFlags() error {
 	if err := validation.ValidateDiskSize(config.Get(crcConfig.DiskSize).AsInt()); err != nil {
 		return err
 	}
-	if err := validation.ValidateBundle(config.Get(crcConfig.Bundle).AsString()); err != nil {
-		return err
+	// the bundle is downloaded by 'crc start' when it is configured with a URL
+	// or an image
+	if config.Get(crcConfig.BundleURL).AsString() == "" && config.Get(crcConfig.BundleImage).AsString() == "" {
+		if err := validation.ValidateBundle(config.Get(crcConfig.Bundle).AsString()); err != nil {
+			return err
+		}
 	}
 	if config.Get(crcConfig.NameServer).AsString() != "" {
 		if err := validation.ValidateIPAddress(config.Get(crcConfig.NameServer).AsString()); err != nil {
@@ -205,7 +373,15 @@ func checkIfNewVersionAvailable(noUpdateCheck bool) error {
 }
 
 func newVersionAvailable() (bool, string, string, error) {
-	release, err := crcversion.GetCRCLatestVersionFromMirror(network.HTTPTransport())
+	// the check must not slow down the start when the mirror is unreachable
+	downloader := download.New()
+	downloader.Attempts = 1
+	downloader.Timeout = 5 * time.Second
+	releaseMetaData, err := downloader.InMemory(crcversion.ReleaseInfoLink)
+	if err != nil {
+		return false, "", "", err
+	}
+	release, err := crcversion.ParseReleaseInfo(releaseMetaData)
 	if err != nil {
 		return false, "", "", err
 	}
@@ -229,29 +405,40 @@ func downloadLink(release *crcversion.CrcReleaseInfo) string {
 const startTemplate = `Started the OpenShift cluster.
 
 The server is accessible via web console at:
-  {{ .ClusterConfig.WebConsoleURL }}
-
-Log in as administrator:
-  Username: {{ .ClusterConfig.AdminCredentials.Username }}
-  Password: {{ .ClusterConfig.AdminCredentials.Password }}
-
-Log in as user:
-  Username: {{ .ClusterConfig.DeveloperCredentials.Username }}
-  Password: {{ .ClusterConfig.DeveloperCredentials.Password }}
-
+  {{ .Summary.ConsoleURL }}
+{{ range .Summary.Logins }}
+Log in as {{ roleTitle .Role }}:
+  Username: {{ .Username }}
+  Password: {{ .Password }}
+{{ end }}
 Use the 'oc' command line interface:
   {{ .CommandLinePrefix }} {{ .EvalCommandLine }}
-  {{ .CommandLinePrefix }} oc login -u {{ .ClusterConfig.DeveloperCredentials.Username }} {{ .ClusterConfig.URL }}
+{{- range .Summary.NextSteps }}
+  {{ $.CommandLinePrefix }} {{ . }}
+{{- end }}
 `
 
+// roleTitles are the names of the user roles in the start message
+var roleTitles = map[string]string{
+	types.AdminRole:     "administrator",
+	types.DeveloperRole: "user",
+}
+
+func roleTitle(role string) string {
+	if title, ok := roleTitles[role]; ok {
+		return title
+	}
+	return role
+}
+
 type templateVariables struct {
-	ClusterConfig     *clusterConfig
+	Summary           *startSummary
 	EvalCommandLine   string
 	CommandLinePrefix string
 }
 
 func writeTemplatedMessage(writer io.Writer, s *startResult) error {
-	parsed, err := template.New("template").Parse(startTemplate)
+	parsed, err := template.New("template").Funcs(template.FuncMap{"roleTitle": roleTitle}).Parse(startTemplate)
 	if err != nil {
 		return err
 	}
@@ -260,9 +447,10 @@ func writeTemplatedMessage(writer io.Writer, s *startResult) error {
 	if err != nil {
 		userShell = ""
 	}
+	summary := s.summary()
 	return parsed.Execute(writer, &templateVariables{
-		ClusterConfig:     s.ClusterConfig,
-		EvalCommandLine:   shell.GenerateUsageHint(userShell, "crc oc-env"),
+		Summary:           summary,
+		EvalCommandLine:   shell.GenerateUsageHint(userShell, summary.OcEnvCommand),
 		CommandLinePrefix: commandLinePrefix(userShell),
 	})
 }
@@ -280,7 +468,8 @@ func commandLinePrefix(shell string) string {
 const genericDaemonNotRunningMessage = "Is 'crc daemon' running? Cannot reach daemon API"
 
 func checkDaemonStarted() error {
-	if crcConfig.GetNetworkMode(config) == network.SystemNetworkingMode {
+	// the host DNS service is served by the daemon
+	if crcConfig.GetNetworkMode(config) == network.SystemNetworkingMode && crcConfig.GetDNSService(config) != crcConfig.HostDNSService {
 		return nil
 	}
 	daemonClient := daemonclient.New()
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/code-ready/crc/pkg/os/shell"
 	"github.com/stretchr/testify/assert"
 )
@@ -75,6 +76,7 @@ func TestRenderActionJSONSuccess(t *testing.T) {
 		},
 	}, out, jsonFormat))
 	assert.Equal(t, `{
+  "schemaVersion": 1,
   "success": true,
   "clusterConfig": {
     "cacert": "",
@@ -99,7 +101,7 @@ func TestRenderActionJSONFailure(t *testing.T) {
 		Success: false,
 		Error:   crcErrors.ToSerializableError(errors.New("broken")),
 	}, out, jsonFormat))
-	assert.JSONEq(t, `{"success": false, "error": "broken"}`, out.String())
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": false, "error": "broken"}`, out.String())
 }
 
 const unixTemplate = `Started the OpenShift cluster.
@@ -165,3 +167,79 @@ func expectedTemplate(shell string) string {
 	}
 	return unixTemplate
 }
+
+func TestRenderResourceConflicts(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, render(&startResult{
+		Success: true,
+		ResourceConflicts: toResourceConflicts(&types.StartResult{
+			ResourceConflicts: []types.ResourceConflict{
+				{Resource: "memory", Current: 9216, Requested: 16384},
+			},
+		}),
+	}, out, jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": true, "resourceConflicts": [{"resource": "memory", "current": 9216, "requested": 16384}]}`, out.String())
+}
+
+func TestRenderResourceChanges(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, render(&startResult{
+		Success: true,
+		ResourceChanges: toResourceChanges(&types.StartResult{
+			ResourceChanges: []types.ResourceChange{
+				{Resource: "cpus", Previous: 4, Current: 6},
+			},
+		}),
+	}, out, jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": true, "resourceChanges": [{"resource": "cpus", "previous": 4, "current": 6}]}`, out.String())
+}
+
+func TestRenderStartSummary(t *testing.T) {
+	result := &startResult{
+		Success: true,
+		Summary: toStartSummary(&types.StartResult{
+			Summary: &types.StartSummary{
+				ConsoleURL:     defaultWebConsoleURL,
+				APIURL:         defaultAPIURL,
+				KubeconfigPath: "/home/user/.kube/config",
+				Logins: []types.Login{
+					{Role: types.AdminRole, Username: "kubeadmin", Password: "secret", Context: "crc-admin", LoginCommand: "oc login -u kubeadmin " + defaultAPIURL},
+					{Role: types.DeveloperRole, Username: "developer", Password: "developer", Context: "crc-developer", LoginCommand: "oc login -u developer " + defaultAPIURL},
+				},
+				OcEnvCommand: "crc oc-env",
+				NextSteps:    []string{"oc login -u developer " + defaultAPIURL},
+			},
+		}),
+	}
+
+	out := new(bytes.Buffer)
+	assert.NoError(t, render(result, out, jsonFormat))
+	assert.JSONEq(t, `{
+  "schemaVersion": 1,
+  "success": true,
+  "summary": {
+    "consoleUrl": "https://console-openshift-console.apps-crc.testing",
+    "apiUrl": "https://api.crc.testing:6443",
+    "kubeconfigPath": "/home/user/.kube/config",
+    "logins": [
+      {"role": "admin", "username": "kubeadmin", "password": "secret", "context": "crc-admin", "loginCommand": "oc login -u kubeadmin https://api.crc.testing:6443"},
+      {"role": "developer", "username": "developer", "password": "developer", "context": "crc-developer", "loginCommand": "oc login -u developer https://api.crc.testing:6443"}
+    ],
+    "ocEnvCommand": "crc oc-env",
+    "nextSteps": ["oc login -u developer https://api.crc.testing:6443"]
+  }
+}`, out.String())
+
+	userShell, err := shell.GetShell("")
+	if err != nil {
+		if runtime.GOOS == "windows" {
+			userShell, err = shell.GetShell("cmd")
+		} else {
+			userShell, err = shell.GetShell("bash")
+		}
+	}
+	assert.NoError(t, err)
+	out.Reset()
+	assert.NoError(t, render(result, out, ""))
+	assert.Equal(t, expectedTemplate(userShell), out.String())
+}
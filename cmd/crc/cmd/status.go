@@ -5,7 +5,9 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strconv"
 	"text/tabwriter"
+	"time"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
 	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
@@ -30,15 +32,32 @@ var statusCmd = &cobra.Command{
 }
 
 type status struct {
-	Success          bool                         `json:"success"`
-	Error            *crcErrors.SerializableError `json:"error,omitempty"`
-	CrcStatus        string                       `json:"crcStatus,omitempty"`
-	OpenShiftStatus  types.OpenshiftStatus        `json:"openshiftStatus,omitempty"`
-	OpenShiftVersion string                       `json:"openshiftVersion,omitempty"`
-	DiskUsage        int64                        `json:"diskUsage,omitempty"`
-	DiskSize         int64                        `json:"diskSize,omitempty"`
-	CacheUsage       int64                        `json:"cacheUsage,omitempty"`
-	CacheDir         string                       `json:"cacheDir,omitempty"`
+	Success            bool                         `json:"success"`
+	Error              *crcErrors.SerializableError `json:"error,omitempty"`
+	CrcStatus          string                       `json:"crcStatus,omitempty"`
+	OpenShiftStatus    types.OpenshiftStatus        `json:"openshiftStatus,omitempty"`
+	OpenShiftVersion   string                       `json:"openshiftVersion,omitempty"`
+	DiskUsage          int64                        `json:"diskUsage,omitempty"`
+	DiskSize           int64                        `json:"diskSize,omitempty"`
+	CertsExpiry        *time.Time                   `json:"certsExpiry,omitempty"`
+	CertsRenewalNeeded bool                         `json:"certsRenewalNeeded,omitempty"`
+	ClusterID          string                       `json:"clusterID,omitempty"`
+	Uptime             int64                        `json:"uptime,omitempty"`
+	LastStart          *time.Time                   `json:"lastStart,omitempty"`
+	BundleBuildTime    *time.Time                   `json:"bundleBuildTime,omitempty"`
+	BundleOutdated     bool                         `json:"bundleOutdated,omitempty"`
+	CacheUsage         int64                        `json:"cacheUsage,omitempty"`
+	CacheDir           string                       `json:"cacheDir,omitempty"`
+	Hypervisor         *hypervisorStatus            `json:"hypervisor,omitempty"`
+}
+
+type hypervisorStatus struct {
+	AllocatedMemory int     `json:"allocatedMemory"`
+	AllocatedCPUs   int     `json:"allocatedCPUs"`
+	UsedMemory      int64   `json:"usedMemory,omitempty"`
+	CPUSteal        float64 `json:"cpuSteal,omitempty"`
+	DiskImageSize   int64   `json:"diskImageSize,omitempty"`
+	Snapshots       int     `json:"snapshots"`
 }
 
 func runStatus(writer io.Writer, client machine.Client, cacheDir, outputFormat string) error {
@@ -66,16 +85,44 @@ func getStatus(client machine.Client, cacheDir string) *status {
 		return &status{Success: false, Error: crcErrors.ToSerializableError(err)}
 	}
 
-	return &status{
-		Success:          true,
-		CrcStatus:        string(clusterStatus.CrcStatus),
-		OpenShiftStatus:  clusterStatus.OpenshiftStatus,
-		OpenShiftVersion: clusterStatus.OpenshiftVersion,
-		DiskUsage:        clusterStatus.DiskUse,
-		DiskSize:         clusterStatus.DiskSize,
-		CacheUsage:       size,
-		CacheDir:         cacheDir,
+	status := &status{
+		Success:            true,
+		CrcStatus:          string(clusterStatus.CrcStatus),
+		OpenShiftStatus:    clusterStatus.OpenshiftStatus,
+		OpenShiftVersion:   clusterStatus.OpenshiftVersion,
+		DiskUsage:          clusterStatus.DiskUse,
+		DiskSize:           clusterStatus.DiskSize,
+		CertsRenewalNeeded: clusterStatus.CertsRenewalNeeded,
+		ClusterID:          clusterStatus.ClusterID,
+		Uptime:             int64(clusterStatus.Uptime.Seconds()),
+		BundleOutdated:     clusterStatus.BundleOutdated,
+		CacheUsage:         size,
+		CacheDir:           cacheDir,
+	}
+	if !clusterStatus.CertsExpiry.IsZero() {
+		status.CertsExpiry = &clusterStatus.CertsExpiry
+	}
+	if !clusterStatus.LastStart.IsZero() {
+		status.LastStart = &clusterStatus.LastStart
+	}
+	if !clusterStatus.BundleBuildTime.IsZero() {
+		status.BundleBuildTime = &clusterStatus.BundleBuildTime
+	}
+	if hypervisor := clusterStatus.Hypervisor; hypervisor.AllocatedMemory != 0 {
+		status.Hypervisor = &hypervisorStatus{
+			AllocatedMemory: hypervisor.AllocatedMemory,
+			AllocatedCPUs:   hypervisor.AllocatedCPUs,
+			UsedMemory:      hypervisor.UsedMemory,
+			CPUSteal:        hypervisor.CPUSteal,
+			DiskImageSize:   hypervisor.DiskImageSize,
+			Snapshots:       hypervisor.Snapshots,
+		}
 	}
+	return status
+}
+
+type statusLine struct {
+	left, right string
 }
 
 func (s *status) prettyPrintTo(writer io.Writer) error {
@@ -84,17 +131,35 @@ func (s *status) prettyPrintTo(writer io.Writer) error {
 	}
 	w := tabwriter.NewWriter(writer, 0, 0, 1, ' ', 0)
 
-	lines := []struct {
-		left, right string
-	}{
+	lines := []statusLine{
 		{"CRC VM", s.CrcStatus},
 		{"OpenShift", openshiftStatus(s)},
 		{"Disk Usage", fmt.Sprintf(
 			"%s of %s (Inside the CRC VM)",
 			units.HumanSize(float64(s.DiskUsage)),
 			units.HumanSize(float64(s.DiskSize)))},
+	}
+	if s.Hypervisor != nil {
+		lines = append(lines, hypervisorLines(s.Hypervisor)...)
+	}
+	lines = append(lines, []statusLine{
 		{"Cache Usage", units.HumanSize(float64(s.CacheUsage))},
 		{"Cache Directory", s.CacheDir},
+	}...)
+	if s.Uptime != 0 {
+		lines = append(lines, statusLine{"Uptime", units.HumanDuration(time.Duration(s.Uptime) * time.Second)})
+	}
+	if s.LastStart != nil {
+		lines = append(lines, statusLine{"Last Start", s.LastStart.Format("2006-01-02 15:04 MST")})
+	}
+	if s.CertsExpiry != nil {
+		lines = append(lines, statusLine{"Certs Expiry", certsExpiry(s)})
+	}
+	if s.BundleBuildTime != nil {
+		lines = append(lines, statusLine{"Bundle Build Date", bundleBuildDate(s)})
+	}
+	if s.ClusterID != "" {
+		lines = append(lines, statusLine{"Cluster ID", s.ClusterID})
 	}
 	for _, line := range lines {
 		if err := printLine(w, line.left, line.right); err != nil {
@@ -104,6 +169,36 @@ func (s *status) prettyPrintTo(writer io.Writer) error {
 	return w.Flush()
 }
 
+func hypervisorLines(hypervisor *hypervisorStatus) []statusLine {
+	allocatedMemory := units.HumanSize(float64(hypervisor.AllocatedMemory) * units.MiB)
+	memory := fmt.Sprintf("%s allocated", allocatedMemory)
+	if hypervisor.UsedMemory != 0 {
+		memory = fmt.Sprintf("%s of %s (Inside the CRC VM)", units.HumanSize(float64(hypervisor.UsedMemory)), allocatedMemory)
+	}
+	return []statusLine{
+		{"Memory Usage", memory},
+		{"CPUs", fmt.Sprintf("%d (%.1f%% stolen by the host)", hypervisor.AllocatedCPUs, hypervisor.CPUSteal)},
+		{"Disk Image Size", fmt.Sprintf("%s (On the host)", units.HumanSize(float64(hypervisor.DiskImageSize)))},
+		{"Snapshots", strconv.Itoa(hypervisor.Snapshots)},
+	}
+}
+
+func certsExpiry(status *status) string {
+	expiry := status.CertsExpiry.Format("2006-01-02 15:04 MST")
+	if status.CertsRenewalNeeded {
+		return fmt.Sprintf("%s (renewal needed soon, it adds a few minutes to the next start)", expiry)
+	}
+	return expiry
+}
+
+func bundleBuildDate(status *status) string {
+	buildDate := status.BundleBuildTime.Format("2006-01-02")
+	if status.BundleOutdated {
+		return fmt.Sprintf("%s (its certificates expired, a new cluster renews them during its first start)", buildDate)
+	}
+	return buildDate
+}
+
 func openshiftStatus(status *status) string {
 	if status.OpenShiftVersion != "" {
 		return fmt.Sprintf("%s (v%s)", status.OpenShiftStatus, status.OpenShiftVersion)
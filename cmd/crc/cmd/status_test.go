@@ -25,11 +25,20 @@ func TestPlainStatus(t *testing.T) {
 	out := new(bytes.Buffer)
 	assert.NoError(t, runStatus(out, fakemachine.NewClient(), cacheDir, ""))
 
-	expected := `CRC VM:          Running
-OpenShift:       Running (v4.5.1)
-Disk Usage:      10GB of 20GB (Inside the CRC VM)
-Cache Usage:     10kB
-Cache Directory: %s
+	expected := `CRC VM:            Running
+OpenShift:         Running (v4.5.1)
+Disk Usage:        10GB of 20GB (Inside the CRC VM)
+Memory Usage:      6GB of 9.664GB (Inside the CRC VM)
+CPUs:              4 (1.5%% stolen by the host)
+Disk Image Size:   15GB (On the host)
+Snapshots:         0
+Cache Usage:       10kB
+Cache Directory:   %s
+Uptime:            3 hours
+Last Start:        2021-06-01 08:00 UTC
+Certs Expiry:      2031-01-01 00:00 UTC
+Bundle Build Date: 2021-05-01
+Cluster ID:        6c4b2c56-0e6f-4c23-8b4f-3f2b6f1b1e27
 `
 	assert.Equal(t, fmt.Sprintf(expected, cacheDir), out.String())
 }
@@ -45,14 +54,28 @@ func TestJsonStatus(t *testing.T) {
 	assert.NoError(t, runStatus(out, fakemachine.NewClient(), cacheDir, jsonFormat))
 
 	expected := `{
+  "schemaVersion": 1,
   "success": true,
   "crcStatus": "Running",
   "openshiftStatus": "Running",
   "openshiftVersion": "4.5.1",
   "diskUsage": 10000000000,
   "diskSize": 20000000000,
+  "certsExpiry": "2031-01-01T00:00:00Z",
+  "clusterID": "6c4b2c56-0e6f-4c23-8b4f-3f2b6f1b1e27",
+  "uptime": 9000,
+  "lastStart": "2021-06-01T08:00:00Z",
+  "bundleBuildTime": "2021-05-01T00:00:00Z",
   "cacheUsage": 10000,
-  "cacheDir": "%s"
+  "cacheDir": "%s",
+  "hypervisor": {
+    "allocatedMemory": 9216,
+    "allocatedCPUs": 4,
+    "usedMemory": 6000000000,
+    "cpuSteal": 1.5,
+    "diskImageSize": 15000000000,
+    "snapshots": 0
+  }
 }
 `
 	assert.Equal(t, fmt.Sprintf(expected, strings.ReplaceAll(cacheDir, `\`, `\\`)), out.String())
@@ -81,6 +104,7 @@ func TestJsonStatusWithError(t *testing.T) {
 	assert.NoError(t, runStatus(out, fakemachine.NewFailingClient(), cacheDir, jsonFormat))
 
 	expected := `{
+  "schemaVersion": 1,
   "success": false,
   "error": "broken"
 }
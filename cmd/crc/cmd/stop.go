@@ -9,6 +9,7 @@ import (
 	"github.com/code-ready/crc/pkg/crc/input"
 	"github.com/code-ready/crc/pkg/crc/machine"
 	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/spf13/cobra"
 )
 
@@ -27,52 +28,60 @@ var stopCmd = &cobra.Command{
 	},
 }
 
-func stopMachine(client machine.Client, interactive, force bool) (bool, error) {
+// stopMachine stops the VM, it returns whether it was powered off and
+// whether it was already stopped
+func stopMachine(client machine.Client, interactive, force bool) (bool, bool, error) {
 	if err := checkIfMachineMissing(client); err != nil {
-		return false, err
+		return false, false, err
 	}
 
-	vmState, err := client.Stop()
+	result, err := client.Stop()
 	if err != nil {
 		if !interactive && !force {
-			return false, err
+			return false, false, err
 		}
 		// Here we are checking the VM state and if it is still running then
 		// Ask user to forcefully power off it.
-		if vmState == state.Running {
+		if result != nil && result.State == state.Running {
 			// Most of the time force kill don't work and libvirt throw
 			// Device or resource busy error. To make sure we give some
 			// graceful time to cluster before kill it.
 			yes := input.PromptUserForYesOrNo("Do you want to force power off", force)
 			if yes {
-				err := client.PowerOff()
-				return true, err
+				_, err := client.PowerOff(types.PowerOffConfig{})
+				return true, false, err
 			}
 		}
-		return false, err
+		return false, false, err
 	}
-	return false, nil
+	return false, result.AlreadyStopped, nil
 }
 
 func runStop(writer io.Writer, client machine.Client, interactive, force bool, outputFormat string) error {
-	forced, err := stopMachine(client, interactive, force)
+	forced, alreadyStopped, err := stopMachine(client, interactive, force)
 	return render(&stopResult{
-		Success: err == nil,
-		Forced:  forced,
-		Error:   crcErrors.ToSerializableError(err),
+		Success:        err == nil,
+		Forced:         forced,
+		AlreadyStopped: alreadyStopped,
+		Error:          crcErrors.ToSerializableError(err),
 	}, writer, outputFormat)
 }
 
 type stopResult struct {
-	Success bool                         `json:"success"`
-	Forced  bool                         `json:"forced"`
-	Error   *crcErrors.SerializableError `json:"error,omitempty"`
+	Success        bool                         `json:"success"`
+	Forced         bool                         `json:"forced"`
+	AlreadyStopped bool                         `json:"alreadyStopped,omitempty"`
+	Error          *crcErrors.SerializableError `json:"error,omitempty"`
 }
 
 func (s *stopResult) prettyPrintTo(writer io.Writer) error {
 	if s.Error != nil {
 		return s.Error
 	}
+	if s.AlreadyStopped {
+		_, err := fmt.Fprintln(writer, "The OpenShift cluster is already stopped")
+		return err
+	}
 	if s.Forced {
 		_, err := fmt.Fprintln(writer, "Forcibly stopped the OpenShift cluster")
 		return err
@@ -27,17 +27,29 @@ func TestStopWithForcePlainError(t *testing.T) {
 func TestStopJSONSuccess(t *testing.T) {
 	out := new(bytes.Buffer)
 	assert.NoError(t, runStop(out, fakemachine.NewClient(), false, false, jsonFormat))
-	assert.JSONEq(t, `{"success": true, "forced": false}`, out.String())
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": true, "forced": false}`, out.String())
 }
 
 func TestStopJSONError(t *testing.T) {
 	out := new(bytes.Buffer)
 	assert.NoError(t, runStop(out, fakemachine.NewFailingClient(), false, false, jsonFormat))
-	assert.JSONEq(t, `{"success": false, "forced": false, "error": "stop failed"}`, out.String())
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": false, "forced": false, "error": "stop failed"}`, out.String())
 }
 
 func TestStopWithForceJSONError(t *testing.T) {
 	out := new(bytes.Buffer)
 	assert.NoError(t, runStop(out, fakemachine.NewFailingClient(), false, true, jsonFormat))
-	assert.JSONEq(t, `{"success": false, "forced": true, "error": "poweroff failed"}`, out.String())
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": false, "forced": true, "error": "poweroff failed"}`, out.String())
+}
+
+func TestStopAlreadyStopped(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runStop(out, &fakemachine.Client{Stopped: true}, false, false, ""))
+	assert.Equal(t, "The OpenShift cluster is already stopped\n", out.String())
+}
+
+func TestStopAlreadyStoppedJSON(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runStop(out, &fakemachine.Client{Stopped: true}, false, false, jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": true, "forced": false, "alreadyStopped": true}`, out.String())
 }
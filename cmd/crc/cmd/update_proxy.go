@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"io"
+	"os"
+
+	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	addOutputFormatFlag(updateProxyCmd)
+	rootCmd.AddCommand(updateProxyCmd)
+}
+
+var updateProxyCmd = &cobra.Command{
+	Use:   "update-proxy",
+	Short: "Apply the proxy settings to the running OpenShift cluster",
+	Long: "Apply the http-proxy, https-proxy, no-proxy and proxy-ca-file settings, or the proxy environment " +
+		"variables when they are not set, to the proxy configuration of the running OpenShift cluster, " +
+		"its kubelet and crio without restarting the instance",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runUpdateProxy(os.Stdout, newMachine(), outputFormat)
+	},
+}
+
+func runUpdateProxy(writer io.Writer, client machine.Client, outputFormat string) error {
+	err := checkIfMachineMissing(client)
+	if err == nil {
+		err = client.UpdateProxy()
+	}
+	return render(&operationResult{
+		Success: err == nil,
+		Error:   crcErrors.ToSerializableError(err),
+		message: "Updated the proxy configuration of the OpenShift cluster",
+	}, writer, outputFormat)
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateProxyPlainSuccess(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runUpdateProxy(out, fakemachine.NewClient(), ""))
+	assert.Equal(t, "Updated the proxy configuration of the OpenShift cluster\n", out.String())
+}
+
+func TestUpdateProxyJSONError(t *testing.T) {
+	out := new(bytes.Buffer)
+	assert.NoError(t, runUpdateProxy(out, fakemachine.NewFailingClient(), jsonFormat))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": false, "error": "proxy update failed"}`, out.String())
+}
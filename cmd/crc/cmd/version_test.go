@@ -31,6 +31,6 @@ func TestJsonVersion(t *testing.T) {
 		InstalledBundlePath: "",
 	}, "json"))
 
-	expected := `{"version": "1.13", "commit": "aabbcc", "openshiftVersion": "4.5.4", "embedded": false}`
+	expected := `{"schemaVersion": 1, "version": "1.13", "commit": "aabbcc", "openshiftVersion": "4.5.4", "embedded": false}`
 	assert.JSONEq(t, expected, out.String())
 }
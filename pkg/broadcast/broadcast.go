@@ -0,0 +1,70 @@
+// Package broadcast sends values to several subscribers without being slowed
+// down by the ones which do not receive them fast enough
+package broadcast
+
+import (
+	"sync"
+)
+
+// Broadcaster sends the published values to all its subscribers without
+// blocking. A subscriber which is not ready misses the values published until
+// it is, it then receives a marker with their count before the next value.
+type Broadcaster struct {
+	mu          sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	send    func(value interface{}) bool
+	marker  func(dropped int) interface{}
+	dropped int
+}
+
+func New() *Broadcaster {
+	return &Broadcaster{
+		subscribers: map[*subscriber]struct{}{},
+	}
+}
+
+// Subscribe adds a subscriber to which send delivers the values without
+// blocking, it returns false when the subscriber is not ready. marker returns
+// the value telling the subscriber how many values it missed. The returned
+// function removes the subscriber, it returns true on its first call only,
+// send is not called anymore once it returns.
+func (b *Broadcaster) Subscribe(send func(value interface{}) bool, marker func(dropped int) interface{}) func() bool {
+	s := &subscriber{
+		send:   send,
+		marker: marker,
+	}
+	b.mu.Lock()
+	b.subscribers[s] = struct{}{}
+	b.mu.Unlock()
+
+	return func() bool {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[s]; !ok {
+			return false
+		}
+		delete(b.subscribers, s)
+		return true
+	}
+}
+
+// Publish sends value to the subscribers which are ready
+func (b *Broadcaster) Publish(value interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subscribers {
+		if s.dropped > 0 {
+			if !s.send(s.marker(s.dropped)) {
+				s.dropped++
+				continue
+			}
+			s.dropped = 0
+		}
+		if !s.send(value) {
+			s.dropped++
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package broadcast
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func subscribe(broadcaster *Broadcaster, size int) (chan string, func() bool) {
+	ch := make(chan string, size)
+	return ch, broadcaster.Subscribe(func(value interface{}) bool {
+		select {
+		case ch <- value.(string):
+			return true
+		default:
+			return false
+		}
+	}, func(dropped int) interface{} {
+		return fmt.Sprintf("%d dropped", dropped)
+	})
+}
+
+func TestBroadcaster(t *testing.T) {
+	broadcaster := New()
+	first, cancelFirst := subscribe(broadcaster, 1)
+	second, cancelSecond := subscribe(broadcaster, 1)
+	defer cancelSecond()
+
+	broadcaster.Publish("value 1")
+	assert.Equal(t, "value 1", <-first)
+	assert.Equal(t, "value 1", <-second)
+
+	assert.True(t, cancelFirst())
+	assert.False(t, cancelFirst())
+	broadcaster.Publish("value 2")
+	assert.Len(t, first, 0)
+	assert.Equal(t, "value 2", <-second)
+}
+
+func TestBroadcasterSlowSubscriber(t *testing.T) {
+	broadcaster := New()
+	values, cancel := subscribe(broadcaster, 2)
+	defer cancel()
+
+	for i := 1; i <= 5; i++ {
+		broadcaster.Publish(fmt.Sprintf("value %d", i))
+	}
+	assert.Equal(t, "value 1", <-values)
+	assert.Equal(t, "value 2", <-values)
+
+	// the count of the missed values comes before the next value
+	broadcaster.Publish("value 6")
+	assert.Equal(t, "3 dropped", <-values)
+	assert.Equal(t, "value 6", <-values)
+
+	// the marker needs room as well, the value following it is missed
+	// when there is only room for the marker
+	broadcaster.Publish("value 7")
+	broadcaster.Publish("value 8")
+	broadcaster.Publish("value 9")
+	assert.Equal(t, "value 7", <-values)
+	broadcaster.Publish("value 10")
+	assert.Equal(t, "value 8", <-values)
+	assert.Equal(t, "1 dropped", <-values)
+	broadcaster.Publish("value 11")
+	assert.Equal(t, "1 dropped", <-values)
+	assert.Equal(t, "value 11", <-values)
+}
@@ -7,12 +7,14 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	apiClient "github.com/code-ready/crc/pkg/crc/api/client"
 	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/network/forward"
 	"github.com/code-ready/crc/pkg/crc/version"
 	"github.com/stretchr/testify/assert"
 )
@@ -62,6 +64,9 @@ func TestStatus(t *testing.T) {
 	defer client.Close()
 	statusResult, err := client.Status()
 	assert.NoError(t, err)
+	certsExpiry := time.Date(2031, time.January, 1, 0, 0, 0, 0, time.UTC)
+	lastStart := time.Date(2021, time.June, 1, 8, 0, 0, 0, time.UTC)
+	bundleBuildTime := time.Date(2021, time.May, 1, 0, 0, 0, 0, time.UTC)
 	assert.Equal(
 		t,
 		apiClient.ClusterStatusResult{
@@ -70,12 +75,40 @@ func TestStatus(t *testing.T) {
 			OpenshiftVersion: "4.5.1",
 			DiskUse:          int64(10000000000),
 			DiskSize:         int64(20000000000),
-			Success:          true,
+			CertsExpiry:      &certsExpiry,
+			ClusterID:        "6c4b2c56-0e6f-4c23-8b4f-3f2b6f1b1e27",
+			Hypervisor: types.HypervisorResources{
+				AllocatedMemory: 9216,
+				AllocatedCPUs:   4,
+				UsedMemory:      6000000000,
+				CPUSteal:        1.5,
+				DiskImageSize:   15000000000,
+			},
+			Uptime:          9000,
+			LastStart:       &lastStart,
+			BundleBuildTime: &bundleBuildTime,
+			Success:         true,
 		},
 		statusResult,
 	)
 }
 
+func TestReady(t *testing.T) {
+	client := newTestClient()
+	defer client.Close()
+	ready, reason, err := client.Ready()
+	assert.NoError(t, err)
+	assert.True(t, ready)
+	assert.Empty(t, reason)
+
+	ts := httptest.NewServer(NewMux(setupNewInMemoryConfig(), fakemachine.NewFailingClient(), &mockLogger{}, &mockTelemetry{}))
+	defer ts.Close()
+	ready, reason, err = apiClient.New(http.DefaultClient, ts.URL).Ready()
+	assert.NoError(t, err)
+	assert.False(t, ready)
+	assert.Equal(t, "broken", reason)
+}
+
 func TestStart(t *testing.T) {
 	client := newTestClient()
 	defer client.Close()
@@ -109,7 +142,7 @@ func TestSetup(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(
 		t,
-		apiClient.Result{
+		apiClient.StopResult{
 			Success: true,
 			Error:   "",
 		},
@@ -120,11 +153,11 @@ func TestSetup(t *testing.T) {
 func TestDelete(t *testing.T) {
 	client := newTestClient()
 	defer client.Close()
-	deleteResult, err := client.Delete()
+	deleteResult, err := client.Delete(apiClient.DeleteRequest{KeepBundle: true, ClearCache: true})
 	assert.NoError(t, err)
 	assert.Equal(
 		t,
-		apiClient.Result{
+		apiClient.DeleteResult{
 			Success: true,
 			Error:   "",
 		},
@@ -132,6 +165,30 @@ func TestDelete(t *testing.T) {
 	)
 }
 
+func TestPortForwards(t *testing.T) {
+	client := newTestClient()
+	defer client.Close()
+	expected := apiClient.PortForwardsResult{
+		Forwards: []forward.Forward{{Local: "127.0.0.1:8080", Remote: 30080}},
+		Success:  true,
+	}
+
+	result, err := client.AddPortForward(apiClient.PortForwardRequest{Local: "8080", Remote: 30080})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	result, err = client.PortForwards()
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	result, err = client.RemovePortForward("127.0.0.1:8080")
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	_, err = client.AddPortForward(apiClient.PortForwardRequest{Local: "8080"})
+	assert.EqualError(t, err, "Error occurred sending POST request to : /port-forwards : 400")
+}
+
 func TestConfigGet(t *testing.T) {
 	client := newTestClient()
 	defer client.Close()
@@ -280,8 +337,31 @@ func TestTelemetry(t *testing.T) {
 
 	_ = client.Telemetry("click start")
 	_ = client.Telemetry("click stop")
+	_ = client.Telemetry("click delete")
+	_ = client.Telemetry("click open console")
 
-	assert.Equal(t, []string{"click start", "click stop"}, telemetry.actions)
+	// the daemon reports the starts and the stops itself
+	assert.Equal(t, []string{"click delete", "click open console"}, telemetry.actions)
+}
+
+func TestTelemetryOfOperations(t *testing.T) {
+	telemetry := &mockTelemetry{}
+	ts := httptest.NewServer(NewMux(setupNewInMemoryConfig(), fakemachine.NewClient(), &mockLogger{}, telemetry))
+	defer ts.Close()
+	client := apiClient.New(http.DefaultClient, ts.URL)
+	_, err := client.Start(apiClient.StartConfig{})
+	assert.NoError(t, err)
+	_, err = client.Stop()
+	assert.NoError(t, err)
+
+	failingTelemetry := &mockTelemetry{}
+	failingTs := httptest.NewServer(NewMux(setupNewInMemoryConfig(), fakemachine.NewFailingClient(), &mockLogger{}, failingTelemetry))
+	defer failingTs.Close()
+	_, err = apiClient.New(http.DefaultClient, failingTs.URL).Stop()
+	assert.Error(t, err)
+
+	assert.Equal(t, []string{"daemon start: <nil>", "daemon stop: <nil>"}, telemetry.operations)
+	assert.Equal(t, []string{"daemon stop: stop failed"}, failingTelemetry.operations)
 }
 
 func TestPullSecret(t *testing.T) {
@@ -312,3 +392,25 @@ func TestPullSecret(t *testing.T) {
 
 	assert.Error(t, client.SetPullSecret("{}")) // invalid
 }
+
+func TestPreflightOnly(t *testing.T) {
+	ts := httptest.NewServer(NewPreflightMux(setupNewInMemoryConfig(), &mockLogger{}, &mockTelemetry{}))
+	defer ts.Close()
+
+	client := apiClient.New(http.DefaultClient, ts.URL)
+
+	vr, err := client.Version()
+	assert.NoError(t, err)
+	assert.True(t, vr.Success)
+
+	checks, err := client.PreflightChecks()
+	assert.NoError(t, err)
+	assert.True(t, checks.Success)
+	assert.NotEmpty(t, checks.Checks)
+
+	_, err = client.RunPreflightCheck("unknown")
+	assert.Error(t, err)
+
+	_, err = client.Status()
+	assert.Error(t, err)
+}
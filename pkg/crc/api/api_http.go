@@ -16,6 +16,19 @@ func NewMux(config crcConfig.Storage, machine machine.Client, logger Logger, tel
 	return server.Handler()
 }
 
+// NewPreflightMux serves the preflight checks of the host, with the version of
+// crc and its logs, for the clients which need them before the host is set up
+func NewPreflightMux(config crcConfig.Storage, logger Logger, telemetry Telemetry) http.Handler {
+	handler := NewHandler(config, nil, logger, telemetry)
+
+	server := newServer()
+	server.GET("/version", handler.GetVersion)
+	addPreflightRoutes(server, handler)
+	server.GET("/logs", handler.Logs)
+
+	return server.Handler()
+}
+
 func newServerWithRoutes(handler *Handler) *server {
 	server := newServer()
 
@@ -27,19 +40,60 @@ func newServerWithRoutes(handler *Handler) *server {
 
 	server.POST("/poweroff", handler.PowerOff)
 
+	server.POST("/hibernate", handler.Hibernate)
+	server.POST("/pause", handler.Pause)
+	server.POST("/resume", handler.Resume)
+
 	server.GET("/status", handler.Status)
+	server.GET("/readyz", handler.Readyz)
+	server.GET("/metrics", handler.Metrics)
 
 	server.DELETE("/delete", handler.Delete)
 	server.GET("/delete", handler.Delete)
 
+	server.POST("/protect", handler.Protect)
+	server.DELETE("/protect", handler.Unprotect)
+
+	server.GET("/history", handler.History)
+
 	server.GET("/version", handler.GetVersion)
 
+	addPreflightRoutes(server, handler)
+
 	server.GET("/webconsoleurl", handler.GetWebconsoleInfo)
 
+	server.GET("/credentials", handler.GetCredentials)
+
+	server.GET("/images", handler.Images)
+
+	server.GET("/network-selftest", handler.NetworkSelfTest)
+	server.POST("/network-selftest", handler.StartNetworkSelfTest)
+	server.GET("/health", handler.Health)
+
+	server.POST("/proxy", handler.UpdateProxy)
+
+	server.POST("/debug/clock", handler.ShiftClock)
+
+	server.POST("/vmconfig", handler.SetVMConfig)
+
+	server.POST("/snapshot", handler.Snapshot)
+	server.POST("/revert", handler.Revert)
+
+	server.POST("/exec", handler.Exec)
+	server.GET("/unit-logs", handler.UnitLogs)
+
 	server.GET("/config", handler.GetConfig)
 	server.POST("/config", handler.SetConfig)
 	server.DELETE("/config", handler.UnsetConfig)
 
+	server.GET("/host-services", handler.GetHostServices)
+	server.POST("/host-services", handler.AddHostService)
+	server.DELETE("/host-services", handler.RemoveHostService)
+
+	server.GET("/port-forwards", handler.GetPortForwards)
+	server.POST("/port-forwards", handler.AddPortForward)
+	server.DELETE("/port-forwards", handler.RemovePortForward)
+
 	server.GET("/logs", handler.Logs)
 
 	server.GET("/telemetry", handler.UploadTelemetry)
@@ -51,6 +105,14 @@ func newServerWithRoutes(handler *Handler) *server {
 	return server
 }
 
+func addPreflightRoutes(server *server, handler *Handler) {
+	server.GET("/preflight", handler.PreflightChecks)
+	server.POST("/preflight/check", handler.RunPreflightCheck)
+	server.POST("/preflight/fix", handler.FixPreflightCheck)
+	server.GET("/driver/health", handler.DriverHealth)
+	server.POST("/driver/repair", handler.RepairDriver)
+}
+
 func setPullSecret() func(c *context) error {
 	return func(c *context) error {
 		if err := cluster.StoreInKeyring(string(c.requestBody)); err != nil {
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -10,7 +11,9 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/code-ready/crc/pkg/crc/api/client"
 	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
@@ -48,6 +51,7 @@ func newMockServer(pullSecretPath string) *mockServer {
 	_, _ = config.Set(crcConfig.PullSecretFile, pullSecretPath)
 
 	handler := NewHandler(config, fakeMachine, &mockLogger{}, &mockTelemetry{})
+	handler.Preflight = &mockPreflight{}
 
 	return &mockServer{
 		server: newServerWithRoutes(handler),
@@ -211,11 +215,94 @@ var testCases = []testCase{
 		response: httpError(500).withBody("stop failed\n"),
 	},
 
+	// readyz
+	{
+		request:  get("readyz"),
+		response: httpError(200).withBody("ok\n"),
+	},
+
+	// readyz with failure
+	{
+		request:     get("readyz"),
+		failRequest: true,
+		// error message comes from fakemachine
+		response: httpError(503).withBody("broken\n"),
+	},
+
 	// poweroff
 	{
 		request:  post("poweroff"),
 		response: empty(),
 	},
+	{
+		request:  post("poweroff?token=0000"),
+		response: httpError(500).withBody("invalid protection token\n"),
+	},
+
+	// hibernate
+	{
+		request:  post("hibernate"),
+		response: empty(),
+	},
+
+	// hibernate with failure
+	{
+		request:     post("hibernate"),
+		failRequest: true,
+		// error message comes from fakemachine
+		response: httpError(500).withBody("hibernate failed\n"),
+	},
+
+	// resume
+	{
+		request:  post("resume"),
+		response: empty(),
+	},
+
+	{
+		request:  post("resume").withBody(`{"syncClock":true}`),
+		response: empty(),
+	},
+
+	// resume with failure
+	{
+		request:     post("resume"),
+		failRequest: true,
+		// error message comes from fakemachine
+		response: httpError(500).withBody("resume failed\n"),
+	},
+
+	// pause
+	{
+		request:  post("pause"),
+		response: empty(),
+	},
+
+	// pause with failure
+	{
+		request:     post("pause"),
+		failRequest: true,
+		// error message comes from fakemachine
+		response: httpError(500).withBody("pause failed\n"),
+	},
+
+	// protect
+	{
+		request:  post("protect"),
+		response: jSon(`{"Token":"9f8e7d6c5b4a3210","Success":true,"Error":""}`),
+	},
+	{
+		request:  delete("protect?token=9f8e7d6c5b4a3210"),
+		response: empty(),
+	},
+
+	// protect with failure
+	{
+		request:     post("protect"),
+		failRequest: true,
+		// error message comes from fakemachine
+		response: httpError(500).withBody("protect failed\n"),
+	},
 
 	// poweroff with failure
 	{
@@ -228,7 +315,7 @@ var testCases = []testCase{
 	// status
 	{
 		request:  get("status"),
-		response: jSon(`{"CrcStatus":"Running","OpenshiftStatus":"Running","OpenshiftVersion":"4.5.1","DiskUse":10000000000,"DiskSize":20000000000,"Error":"","Success":true}`),
+		response: jSon(`{"CrcStatus":"Running","OpenshiftStatus":"Running","OpenshiftVersion":"4.5.1","DiskUse":10000000000,"DiskSize":20000000000,"CertsExpiry":"2031-01-01T00:00:00Z","CertsRenewalNeeded":false,"ClusterID":"6c4b2c56-0e6f-4c23-8b4f-3f2b6f1b1e27","Hypervisor":{"AllocatedMemory":9216,"AllocatedCPUs":4,"UsedMemory":6000000000,"CPUSteal":1.5,"DiskImageSize":15000000000,"Snapshots":0},"Uptime":9000,"LastStart":"2021-06-01T08:00:00Z","BundleBuildTime":"2021-05-01T00:00:00Z","BundleOutdated":false,"Error":"","Success":true}`),
 	},
 
 	// status with failure
@@ -239,6 +326,15 @@ var testCases = []testCase{
 		response: httpError(500).withBody("broken\n"),
 	},
 
+	// metrics with failure, the certificates expiry of the metrics depends
+	// on the current time, TestMetrics checks them
+	{
+		request:     get("metrics"),
+		failRequest: true,
+		// error message comes from fakemachine
+		response: httpError(500).withBody("broken\n"),
+	},
+
 	// delete
 	{
 		request:  delete("delete"),
@@ -249,6 +345,19 @@ var testCases = []testCase{
 		response: empty(),
 	},
 
+	{
+		request:  delete("delete?clearCache=true&keepBundle=true&keepData=true"),
+		response: empty(),
+	},
+	{
+		request:  delete("delete?token=9f8e7d6c5b4a3210"),
+		response: empty(),
+	},
+	{
+		request:  delete("delete?token=0000"),
+		response: httpError(500).withBody("invalid protection token\n"),
+	},
+
 	// delete with failure
 	{
 		request:     delete("delete"),
@@ -285,6 +394,199 @@ var testCases = []testCase{
 		response: httpError(500).withBody("console failed\n"),
 	},
 
+	// credentials
+	{
+		request:  get("credentials"),
+		response: jSon(`{"cacert":"MIIDODCCAiCgAwIBAgIIRVfCKNUa1wIwDQYJ","webConsoleUrl":"https://console.foo.testing:6443","url":"https://foo.testing:6443","adminCredentials":{"username":"kubeadmin","password":"foobar"},"developerCredentials":{"username":"developer","password":"developer"}}`),
+	},
+	{
+		request:  get("credentials?format=netrc"),
+		response: jSon("machine foo.testing login kubeadmin password foobar\nmachine console.foo.testing login kubeadmin password foobar\n"),
+	},
+	{
+		request:  get("credentials?format=xml"),
+		response: httpError(400).withBody("invalid credentials format: xml, must be one of json, env, netrc, kubeconfig"),
+	},
+
+	// images
+	{
+		request:  get("images"),
+		response: jSon(`{"Images":[{"id":"sha256:7a8b2c","repoTags":["quay.io/crcont/routes-controller:latest"],"repoDigests":null,"size":52428800}],"Success":true,"Error":""}`),
+	},
+
+	// images with failure
+	{
+		request:     get("images"),
+		failRequest: true,
+		// error message comes from fakemachine
+		response: httpError(500).withBody("listing images failed\n"),
+	},
+
+	// network self-test which never ran
+	{
+		request:  get("network-selftest"),
+		response: jSon(`{"Success":true,"Error":"","Running":false,"Finished":null,"Failure":"","Healthy":false,"Checks":null}`),
+	},
+
+	// network self-test start with failure
+	{
+		request:     post("network-selftest"),
+		failRequest: true,
+		// error message comes from fakemachine
+		response: httpError(500).withBody("broken\n"),
+	},
+
+	// health without network self-test
+	{
+		request:  get("health"),
+		response: jSon(`{"Success":true,"Error":"","Healthy":false,"Driver":[{"Name":"check-libvirt-running","Passed":true,"Skipped":false,"Failure":""},{"Name":"check-crc-network","Passed":false,"Skipped":false,"Failure":"libvirt 'crc' network definition is incorrect"}],"NetworkSelfTest":null}`),
+	},
+
+	// proxy update
+	{
+		request:  post("proxy"),
+		response: empty(),
+	},
+
+	// proxy update with failure
+	{
+		request:     post("proxy"),
+		failRequest: true,
+		// error message comes from fakemachine
+		response: httpError(500).withBody("proxy update failed\n"),
+	},
+
+	// clock shift
+	{
+		request:  post("debug/clock").withBody(`{"offset":"744h"}`),
+		response: empty(),
+	},
+
+	{
+		request:  post("debug/clock").withBody(`{"offset":"31 days"}`),
+		response: httpError(500).withBody("Invalid clock offset '31 days': time: unknown unit \" days\" in duration \"31 days\"\n"),
+	},
+
+	// clock shift with failure
+	{
+		request:     post("debug/clock").withBody(`{"offset":"0"}`),
+		failRequest: true,
+		// error message comes from fakemachine
+		response: httpError(500).withBody("clock shift failed\n"),
+	},
+
+	// VM configuration change
+	{
+		request:  post("vmconfig").withBody(`{"memory":12288}`),
+		response: jSon(`{"Success":true,"Error":"","ResourceChanges":[{"Resource":"memory","Previous":9216,"Current":12288}]}`),
+	},
+
+	// disk resize
+	{
+		request:  post("vmconfig").withBody(`{"diskSize":50}`),
+		response: jSon(`{"Success":true,"Error":"","ResourceChanges":[{"Resource":"disk-size","Previous":31,"Current":50}]}`),
+	},
+
+	// VM configuration change with failure
+	{
+		request:     post("vmconfig").withBody(`{"cpus":6}`),
+		failRequest: true,
+		// error message comes from fakemachine
+		response: httpError(500).withBody("VM configuration change failed\n"),
+	},
+
+	// snapshot
+	{
+		request:  post("snapshot").withBody(`{"name":"configured"}`),
+		response: empty(),
+	},
+
+	// revert with failure
+	{
+		request:     post("revert").withBody(`{"name":"configured"}`),
+		failRequest: true,
+		// error message comes from fakemachine
+		response: httpError(500).withBody("revert failed\n"),
+	},
+
+	// history
+	{
+		request:  get("history"),
+		response: jSon(`{"Success":true,"Error":"","Entries":[{"time":"2021-09-01T10:00:00Z","user":"crc","operation":"start","parameters":{"cpus":4,"memory":9216},"success":true},{"time":"2021-09-01T18:00:00Z","user":"crc","operation":"delete","success":false,"error":"invalid protection token"}]}`),
+	},
+
+	// history with failure
+	{
+		request:     get("history"),
+		failRequest: true,
+		// error message comes from fakemachine
+		response: httpError(500).withBody("history failed\n"),
+	},
+
+	// preflight
+	{
+		request:  get("preflight"),
+		response: jSon(`{"Success":true,"Error":"","Checks":[{"Name":"check-bundle-extracted","Description":"Checking if CRC bundle is extracted in '$HOME/.crc'","FixDescription":"Getting bundle for the CRC executable","Fixable":true,"SetupOnly":false,"Skipped":false},{"Name":"check-ram","Description":"Checking minimum RAM requirements","FixDescription":"crc requires at least 9.7GB to run","Fixable":false,"SetupOnly":false,"Skipped":true}]}`),
+	},
+	{
+		request:  post("preflight/check").withBody(`{"name":"check-ram"}`),
+		response: jSon(`{"Success":true,"Error":"","Name":"check-ram","Passed":false,"Skipped":false,"Failure":"only 8GB of memory found"}`),
+	},
+	{
+		request:  post("preflight/check").withBody(`{"name":"check-cpu"}`),
+		response: httpError(500).withBody("Unknown preflight check 'check-cpu'\n"),
+	},
+	{
+		request:  post("preflight/check").withBody(`{}`),
+		response: httpError(400).withBody(`{"Success":false,"Error":"No preflight check name provided","Name":"","Passed":false,"Skipped":false,"Failure":""}`),
+	},
+	{
+		request:  post("preflight/fix").withBody(`{"name":"check-bundle-extracted"}`),
+		response: jSon(`{"Success":true,"Error":"","Name":"check-bundle-extracted","Passed":true,"Skipped":false,"Failure":""}`),
+	},
+	{
+		request:  post("preflight/fix").withBody(`{"name":"check-ram"}`),
+		response: httpError(500).withBody("Preflight check 'check-ram' cannot be fixed automatically\n"),
+	},
+
+	// driver health
+	{
+		request:  get("driver/health"),
+		response: jSon(`{"Success":true,"Error":"","Healthy":false,"Checks":[{"Name":"check-libvirt-running","Passed":true,"Skipped":false,"Failure":""},{"Name":"check-crc-network","Passed":false,"Skipped":false,"Failure":"libvirt 'crc' network definition is incorrect"}]}`),
+	},
+	{
+		request:  post("driver/repair"),
+		response: jSon(`{"Success":true,"Error":"","Healthy":true,"Checks":[{"Name":"check-libvirt-running","Passed":true,"Skipped":false,"Failure":""},{"Name":"check-crc-network","Passed":true,"Skipped":false,"Failure":""}]}`),
+	},
+
+	// exec
+	{
+		request:  post("exec").withBody(`{"command":["hostname"]}`),
+		response: jSon(`{"Stdout":"crc\n","Stderr":"","Success":true,"Error":""}`),
+	},
+
+	// exec with failure
+	{
+		request:     post("exec").withBody(`{"command":["hostname"]}`),
+		failRequest: true,
+		// error message comes from fakemachine
+		response: httpError(500).withBody("exec failed\n"),
+	},
+
+	// unit logs
+	{
+		request:  get("unit-logs?unit=kubelet&lines=10"),
+		response: jSon("2021-10-17T12:00:00+0000 crc systemd[1]: Started kubelet.\n"),
+	},
+
+	// unit logs with failure
+	{
+		request:     get("unit-logs?unit=kubelet"),
+		failRequest: true,
+		// error message comes from fakemachine
+		response: httpError(500).withBody("tailing unit logs failed\n"),
+	},
+
 	// config
 	{
 		request:  get("config?cpus"),
@@ -369,6 +671,59 @@ var testCases = []testCase{
 		request:  get("config?cpus"),
 		response: jSon(`{"Success":true,"Error":"","Configs":{"cpus":4}}`),
 	},
+
+	// host-services
+	{
+		request:  get("host-services"),
+		response: jSon(`{"Services":null,"Success":true,"Error":""}`),
+	},
+	{
+		request:  post("host-services").withBody(`{"name":"crc-proxy.service"}`),
+		response: jSon(`{"Services":["crc-proxy.service"],"Success":true,"Error":""}`),
+	},
+	{
+		request:  delete("host-services?name=crc-proxy.service"),
+		response: jSon(`{"Services":null,"Success":true,"Error":""}`),
+	},
+
+	// port-forwards
+	{
+		request:  get("port-forwards"),
+		response: jSon(`{"Forwards":[{"local":"127.0.0.1:8080","remote":30080}],"Success":true,"Error":""}`),
+	},
+	{
+		request:  post("port-forwards").withBody(`{"local":"8080","remote":30080}`),
+		response: jSon(`{"Forwards":[{"local":"127.0.0.1:8080","remote":30080}],"Success":true,"Error":""}`),
+	},
+	{
+		request:  delete("port-forwards?local=8080"),
+		response: jSon(`{"Forwards":[{"local":"127.0.0.1:8080","remote":30080}],"Success":true,"Error":""}`),
+	},
+
+	// port-forwards with failure
+	{
+		request:  post("port-forwards").withBody(`{"local":"8080","remote":0}`),
+		response: httpError(400).withBody("0 is not a valid VM port"),
+	},
+	{
+		request:  delete("port-forwards"),
+		response: httpError(400).withBody("No port forward provided"),
+	},
+	{
+		request:     get("port-forwards"),
+		failRequest: true,
+		response:    httpError(500).withBody("port forwards failed\n"),
+	},
+
+	// host-services with failure
+	{
+		request:  post("host-services").withBody(`{"name":""}`),
+		response: httpError(400).withBody("No host service provided"),
+	},
+	{
+		request:  post("host-services").withBody(`{"name":"/etc/systemd/system/crc.service"}`),
+		response: httpError(500).withBody("Value '/etc/systemd/system/crc.service' for configuration property 'host-services' is invalid, reason: host service list can't contain spaces or paths\n"),
+	},
 }
 
 var invalidHTTPMethods = []testCase{
@@ -399,6 +754,12 @@ var invalidHTTPMethods = []testCase{
 		request:  post("status"),
 		response: httpError(404).withBody("Not Found\n"),
 	},
+
+	// metrics
+	{
+		request:  post("metrics"),
+		response: httpError(404).withBody("Not Found\n"),
+	},
 	{
 		request:  delete("status"),
 		response: httpError(404).withBody("Not Found\n"),
@@ -484,6 +845,41 @@ func TestRequests(t *testing.T) {
 	}
 }
 
+func TestMetrics(t *testing.T) {
+	server := newMockServer("")
+	resp := sendRequest(server.Handler(), &request{httpMethod: http.MethodGet, resource: "metrics"})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", resp.Header.Get("Content-Type"))
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "crc_vm_state{state=\"Running\"} 1\n")
+	assert.Contains(t, string(body), "crc_disk_used_bytes 1e+10\n")
+	assert.Contains(t, string(body), "# TYPE crc_certs_expiry_days gauge\n")
+}
+
+func TestNetworkSelfTestRunsInBackground(t *testing.T) {
+	server := newMockServer("")
+	resp := sendRequest(server.Handler(), &request{httpMethod: http.MethodPost, resource: "network-selftest"})
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	var result client.NetworkSelfTestResult
+	require.Eventually(t, func() bool {
+		resp := sendRequest(server.Handler(), &request{httpMethod: http.MethodGet, resource: "network-selftest"})
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+		return !result.Running
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.NotNil(t, result.Finished)
+	assert.False(t, result.Healthy)
+	assert.Len(t, result.Checks, 4)
+
+	var health client.HealthResult
+	resp = sendRequest(server.Handler(), &request{httpMethod: http.MethodGet, resource: "health"})
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&health))
+	assert.False(t, health.Healthy)
+	require.NotNil(t, health.NetworkSelfTest)
+	assert.Equal(t, result.Checks, health.NetworkSelfTest.Checks)
+}
+
 func TestRoutes(t *testing.T) {
 	// this checks that we have test cases for all routes registered with the `api` entrypoint
 
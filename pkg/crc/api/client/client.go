@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -70,8 +71,8 @@ func (c *Client) Start(config StartConfig) (StartResult, error) {
 	return sr, nil
 }
 
-func (c *Client) Stop() (Result, error) {
-	var sr = Result{}
+func (c *Client) Stop() (StopResult, error) {
+	var sr = StopResult{}
 	body, err := c.sendGetRequest("/stop")
 	if err != nil {
 		return sr, err
@@ -83,9 +84,122 @@ func (c *Client) Stop() (Result, error) {
 	return sr, nil
 }
 
-func (c *Client) Delete() (Result, error) {
-	var dr = Result{}
-	body, err := c.sendGetRequest("/delete")
+// Ready returns whether the cluster is ready, with the reason when it is not
+func (c *Client) Ready() (bool, string, error) {
+	res, err := c.client.Get(fmt.Sprintf("%s%s", c.base, "/readyz"))
+	if err != nil {
+		return false, "", err
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return false, "", fmt.Errorf("Unknown error reading response: %w", err)
+	}
+	switch res.StatusCode {
+	case http.StatusOK:
+		return true, "", nil
+	case http.StatusServiceUnavailable:
+		return false, strings.TrimSpace(string(body)), nil
+	default:
+		return false, "", fmt.Errorf("Error occurred sending GET request to : %s : %d", "/readyz", res.StatusCode)
+	}
+}
+
+func (c *Client) Hibernate() (Result, error) {
+	return c.lifecycleRequest("/hibernate", nil)
+}
+
+// UpdateProxy applies the proxy settings to the running cluster
+func (c *Client) UpdateProxy() (Result, error) {
+	return c.lifecycleRequest("/proxy", nil)
+}
+
+func (c *Client) Pause() (Result, error) {
+	return c.lifecycleRequest("/pause", nil)
+}
+
+func (c *Client) Resume(req ResumeRequest) (Result, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("Failed to encode data to JSON: %w", err)
+	}
+	return c.lifecycleRequest("/resume", bytes.NewReader(data))
+}
+
+func (c *Client) ShiftClock(req ShiftClockRequest) (Result, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("Failed to encode data to JSON: %w", err)
+	}
+	return c.lifecycleRequest("/debug/clock", bytes.NewReader(data))
+}
+
+func (c *Client) Snapshot(req SnapshotRequest) (Result, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("Failed to encode data to JSON: %w", err)
+	}
+	return c.lifecycleRequest("/snapshot", bytes.NewReader(data))
+}
+
+func (c *Client) Revert(req SnapshotRequest) (Result, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("Failed to encode data to JSON: %w", err)
+	}
+	return c.lifecycleRequest("/revert", bytes.NewReader(data))
+}
+
+func (c *Client) SetVMConfig(req SetVMConfigRequest) (SetVMConfigResult, error) {
+	var result = SetVMConfigResult{}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return result, fmt.Errorf("Failed to encode data to JSON: %w", err)
+	}
+	body, err := c.sendPostRequest("/vmconfig", bytes.NewReader(data))
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(body, &result)
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+func (c *Client) lifecycleRequest(url string, data io.Reader) (Result, error) {
+	var r = Result{}
+	body, err := c.sendPostRequest(url, data)
+	if err != nil {
+		return r, err
+	}
+	err = json.Unmarshal(body, &r)
+	if err != nil {
+		return r, err
+	}
+	return r, nil
+}
+
+func (c *Client) Delete(req DeleteRequest) (DeleteResult, error) {
+	var dr = DeleteResult{}
+	query := url.Values{}
+	if req.ClearCache {
+		query.Set("clearCache", "true")
+	}
+	if req.KeepBundle {
+		query.Set("keepBundle", "true")
+	}
+	if req.KeepData {
+		query.Set("keepData", "true")
+	}
+	if req.IgnoreMissing {
+		query.Set("ignoreMissing", "true")
+	}
+	resource := "/delete"
+	if len(query) > 0 {
+		resource = fmt.Sprintf("%s?%s", resource, query.Encode())
+	}
+	body, err := c.sendGetRequest(resource)
 	if err != nil {
 		return dr, err
 	}
@@ -109,6 +223,221 @@ func (c *Client) WebconsoleURL() (ConsoleResult, error) {
 	return cr, nil
 }
 
+func (c *Client) History() (HistoryResult, error) {
+	var hr = HistoryResult{}
+	body, err := c.sendGetRequest("/history")
+	if err != nil {
+		return hr, err
+	}
+	err = json.Unmarshal(body, &hr)
+	if err != nil {
+		return hr, err
+	}
+	return hr, nil
+}
+
+func (c *Client) PreflightChecks() (PreflightChecksResult, error) {
+	var pr = PreflightChecksResult{}
+	body, err := c.sendGetRequest("/preflight")
+	if err != nil {
+		return pr, err
+	}
+	err = json.Unmarshal(body, &pr)
+	if err != nil {
+		return pr, err
+	}
+	return pr, nil
+}
+
+func (c *Client) RunPreflightCheck(name string) (PreflightCheckResult, error) {
+	return c.preflightRequest("/preflight/check", name)
+}
+
+func (c *Client) FixPreflightCheck(name string) (PreflightCheckResult, error) {
+	return c.preflightRequest("/preflight/fix", name)
+}
+
+func (c *Client) preflightRequest(url string, name string) (PreflightCheckResult, error) {
+	var pr = PreflightCheckResult{}
+	data, err := json.Marshal(PreflightCheckRequest{Name: name})
+	if err != nil {
+		return pr, fmt.Errorf("Failed to encode data to JSON: %w", err)
+	}
+	body, err := c.sendPostRequest(url, bytes.NewReader(data))
+	if err != nil {
+		return pr, err
+	}
+	err = json.Unmarshal(body, &pr)
+	if err != nil {
+		return pr, err
+	}
+	return pr, nil
+}
+
+func (c *Client) DriverHealth() (DriverHealthResult, error) {
+	var dr = DriverHealthResult{}
+	body, err := c.sendGetRequest("/driver/health")
+	if err != nil {
+		return dr, err
+	}
+	err = json.Unmarshal(body, &dr)
+	if err != nil {
+		return dr, err
+	}
+	return dr, nil
+}
+
+func (c *Client) RepairDriver() (DriverHealthResult, error) {
+	var dr = DriverHealthResult{}
+	body, err := c.sendPostRequest("/driver/repair", nil)
+	if err != nil {
+		return dr, err
+	}
+	err = json.Unmarshal(body, &dr)
+	if err != nil {
+		return dr, err
+	}
+	return dr, nil
+}
+
+func (c *Client) Images() (ImagesResult, error) {
+	var ir = ImagesResult{}
+	body, err := c.sendGetRequest("/images")
+	if err != nil {
+		return ir, err
+	}
+	err = json.Unmarshal(body, &ir)
+	if err != nil {
+		return ir, err
+	}
+	return ir, nil
+}
+
+// StartNetworkSelfTest starts the network self-test in the background, its
+// result is read with NetworkSelfTest
+func (c *Client) StartNetworkSelfTest() (NetworkSelfTestResult, error) {
+	var sr = NetworkSelfTestResult{}
+	body, err := c.sendPostRequest("/network-selftest", nil)
+	if err != nil {
+		return sr, err
+	}
+	err = json.Unmarshal(body, &sr)
+	if err != nil {
+		return sr, err
+	}
+	return sr, nil
+}
+
+func (c *Client) NetworkSelfTest() (NetworkSelfTestResult, error) {
+	var sr = NetworkSelfTestResult{}
+	body, err := c.sendGetRequest("/network-selftest")
+	if err != nil {
+		return sr, err
+	}
+	err = json.Unmarshal(body, &sr)
+	if err != nil {
+		return sr, err
+	}
+	return sr, nil
+}
+
+func (c *Client) Health() (HealthResult, error) {
+	var hr = HealthResult{}
+	body, err := c.sendGetRequest("/health")
+	if err != nil {
+		return hr, err
+	}
+	err = json.Unmarshal(body, &hr)
+	if err != nil {
+		return hr, err
+	}
+	return hr, nil
+}
+
+func (c *Client) Exec(req ExecRequest) (ExecResult, error) {
+	var er = ExecResult{}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return er, fmt.Errorf("Failed to encode data to JSON: %w", err)
+	}
+	body, err := c.sendPostRequest("/exec", bytes.NewReader(data))
+	if err != nil {
+		return er, err
+	}
+	err = json.Unmarshal(body, &er)
+	if err != nil {
+		return er, err
+	}
+	return er, nil
+}
+
+// UnitLogs copies the journal of a systemd unit of the VM to writer, with
+// follow it returns when the daemon or the VM closes the stream
+func (c *Client) UnitLogs(unit string, lines int, follow bool, writer io.Writer) error {
+	query := url.Values{}
+	query.Set("unit", unit)
+	if lines > 0 {
+		query.Set("lines", strconv.Itoa(lines))
+	}
+	if follow {
+		query.Set("follow", "true")
+	}
+	logsURL := fmt.Sprintf("/unit-logs?%s", query.Encode())
+	res, err := c.client.Get(fmt.Sprintf("%s%s", c.base, logsURL))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("Error occurred sending GET request to : %s : %d", logsURL, res.StatusCode)
+	}
+	_, err = io.Copy(writer, res.Body)
+	return err
+}
+
+func (c *Client) PortForwards() (PortForwardsResult, error) {
+	var pfr = PortForwardsResult{}
+	body, err := c.sendGetRequest("/port-forwards")
+	if err != nil {
+		return pfr, err
+	}
+	err = json.Unmarshal(body, &pfr)
+	if err != nil {
+		return pfr, err
+	}
+	return pfr, nil
+}
+
+func (c *Client) AddPortForward(req PortForwardRequest) (PortForwardsResult, error) {
+	var pfr = PortForwardsResult{}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return pfr, fmt.Errorf("Failed to encode data to JSON: %w", err)
+	}
+	body, err := c.sendPostRequest("/port-forwards", bytes.NewReader(data))
+	if err != nil {
+		return pfr, err
+	}
+	err = json.Unmarshal(body, &pfr)
+	if err != nil {
+		return pfr, err
+	}
+	return pfr, nil
+}
+
+func (c *Client) RemovePortForward(local string) (PortForwardsResult, error) {
+	var pfr = PortForwardsResult{}
+	body, err := c.sendDeleteRequest(fmt.Sprintf("/port-forwards?local=%s", url.QueryEscape(local)), nil)
+	if err != nil {
+		return pfr, err
+	}
+	err = json.Unmarshal(body, &pfr)
+	if err != nil {
+		return pfr, err
+	}
+	return pfr, nil
+}
+
 func (c *Client) GetConfig(configs []string) (GetConfigResult, error) {
 	var gcr = GetConfigResult{}
 	var escapeConfigs []string
@@ -251,7 +580,7 @@ func (c *Client) sendRequest(url string, method string, data io.Reader) ([]byte,
 
 	switch method {
 	case http.MethodPost:
-		if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated && res.StatusCode != http.StatusAccepted {
 			return nil, fmt.Errorf("Error occurred sending POST request to : %s : %d", url, res.StatusCode)
 		}
 	case http.MethodDelete, http.MethodGet:
@@ -1,7 +1,11 @@
 package client
 
 import (
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/network/forward"
 )
 
 type VersionResult struct {
@@ -17,21 +21,102 @@ type Result struct {
 }
 
 type StartResult struct {
+	Success           bool
+	Status            string
+	Error             string
+	ClusterConfig     types.ClusterConfig
+	KubeletStarted    bool
+	AlreadyRunning    bool                     `json:",omitempty"`
+	ResourceConflicts []types.ResourceConflict `json:",omitempty"`
+	ResourceChanges   []types.ResourceChange   `json:",omitempty"`
+	Summary           *types.StartSummary      `json:",omitempty"`
+}
+
+// StopResult is the result of stop and poweroff, AlreadyStopped is set when
+// the VM was not running
+type StopResult struct {
+	Success        bool
+	Error          string
+	AlreadyStopped bool `json:",omitempty"`
+}
+
+// DeleteResult is the result of delete, AlreadyDeleted is set when the
+// instance did not exist and the request has IgnoreMissing
+type DeleteResult struct {
 	Success        bool
-	Status         string
 	Error          string
-	ClusterConfig  types.ClusterConfig
-	KubeletStarted bool
+	AlreadyDeleted bool `json:",omitempty"`
 }
 
 type ClusterStatusResult struct {
-	CrcStatus        string
-	OpenshiftStatus  string
-	OpenshiftVersion string
-	DiskUse          int64
-	DiskSize         int64
-	Error            string
-	Success          bool
+	CrcStatus          string
+	OpenshiftStatus    string
+	OpenshiftVersion   string
+	DiskUse            int64
+	DiskSize           int64
+	CertsExpiry        *time.Time `json:",omitempty"`
+	CertsRenewalNeeded bool
+	ClusterID          string `json:",omitempty"`
+	Hypervisor         types.HypervisorResources
+	Uptime             int64      `json:",omitempty"`
+	LastStart          *time.Time `json:",omitempty"`
+	BundleBuildTime    *time.Time `json:",omitempty"`
+	BundleOutdated     bool
+	Error              string
+	Success            bool
+}
+
+type HistoryResult struct {
+	Success bool
+	Error   string
+	Entries []types.HistoryEntry
+}
+
+type PreflightCheck struct {
+	Name           string
+	Description    string
+	FixDescription string
+	Fixable        bool
+	SetupOnly      bool
+	Skipped        bool
+}
+
+type PreflightChecksResult struct {
+	Success bool
+	Error   string
+	Checks  []PreflightCheck
+}
+
+type PreflightCheckRequest struct {
+	Name string `json:"name"`
+}
+
+type PreflightCheckResult struct {
+	Success bool
+	Error   string
+	Name    string
+	// the check passes, after the fix for /preflight/fix requests
+	Passed  bool
+	Skipped bool
+	// why the check or its fix failed
+	Failure string
+}
+
+// DriverCheck is the outcome of a check of the virtualization stack
+type DriverCheck struct {
+	Name string
+	// the check passes, after the repair for /driver/repair requests
+	Passed  bool
+	Skipped bool
+	Failure string
+}
+
+type DriverHealthResult struct {
+	Success bool
+	Error   string
+	// all the checks pass
+	Healthy bool
+	Checks  []DriverCheck
 }
 
 type ConsoleResult struct {
@@ -40,6 +125,98 @@ type ConsoleResult struct {
 	Error         string
 }
 
+type ImagesResult struct {
+	Images  []cluster.Image
+	Success bool
+	Error   string
+}
+
+// NetworkSelfTestResult describes the last network self-test which ended,
+// the self-test runs in the background
+type NetworkSelfTestResult struct {
+	Success bool
+	Error   string
+	// a self-test is running
+	Running bool
+	// when the last self-test ended, nil when it never ran
+	Finished *time.Time
+	// why the last self-test could not run
+	Failure string
+	Healthy bool
+	Checks  []cluster.SelfTestCheck
+}
+
+// HealthResult aggregates the checks of the virtualization stack and the
+// last network self-test of the cluster
+type HealthResult struct {
+	Success bool
+	Error   string
+	// all the driver checks and the last network self-test pass
+	Healthy bool
+	Driver  []DriverCheck
+	// nil when the network self-test never ran
+	NetworkSelfTest *NetworkSelfTestResult
+}
+
+type ProtectResult struct {
+	Token   string
+	Success bool
+	Error   string
+}
+
+type DeleteRequest struct {
+	ClearCache    bool
+	KeepBundle    bool
+	KeepData      bool
+	IgnoreMissing bool
+}
+
+type ResumeRequest struct {
+	SyncClock bool `json:"syncClock"`
+}
+
+// ShiftClockRequest moves the clock of the VM Offset, a Go duration such as
+// "744h", away from the one of the host, "0" reverts it
+type ShiftClockRequest struct {
+	Offset string `json:"offset"`
+}
+
+// SnapshotRequest names the snapshot of the stopped instance to create or to
+// revert to
+type SnapshotRequest struct {
+	Name string `json:"name"`
+}
+
+// SetVMConfigRequest changes the memory, in MiB, the CPUs and the disk size,
+// in GiB, of the VM, a zero value keeps the current one
+type SetVMConfigRequest struct {
+	Memory   int `json:"memory,omitempty"`
+	CPUs     int `json:"cpus,omitempty"`
+	DiskSize int `json:"diskSize,omitempty"`
+}
+
+// SetVMConfigResult lists the resources changed in the stopped VM and the
+// disk grown in the running VM, and the ones of the running VM which are
+// applied on the next start
+type SetVMConfigResult struct {
+	Success           bool
+	Error             string
+	ResourceChanges   []types.ResourceChange   `json:",omitempty"`
+	ResourceConflicts []types.ResourceConflict `json:",omitempty"`
+}
+
+type ExecRequest struct {
+	Command    []string `json:"command"`
+	Privileged bool     `json:"privileged"`
+}
+
+type ExecResult struct {
+	Stdout  string
+	Stderr  string
+	Success bool
+	Error   string
+}
+
 // setOrUnsetConfigResult struct is used to return the result of
 // setconfig/unsetconfig command
 type SetOrUnsetConfigResult struct {
@@ -55,6 +232,28 @@ type GetConfigResult struct {
 	Configs map[string]interface{}
 }
 
+type PortForwardRequest struct {
+	// Local is the host address or the host port
+	Local  string `json:"local"`
+	Remote int    `json:"remote"`
+}
+
+type PortForwardsResult struct {
+	Forwards []forward.Forward
+	Success  bool
+	Error    string
+}
+
+type HostServiceRequest struct {
+	Name string `json:"name"`
+}
+
+type HostServicesResult struct {
+	Services []string
+	Success  bool
+	Error    string
+}
+
 type StartConfig struct {
 	PullSecretFile string `json:"pullSecretFile"`
 }
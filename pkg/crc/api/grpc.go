@@ -0,0 +1,371 @@
+package api
+
+import (
+	"bytes"
+	gocontext "context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/api/client"
+	"github.com/code-ready/crc/pkg/crc/api/daemonpb"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/notify"
+	"github.com/code-ready/crc/pkg/crc/telemetry"
+	"github.com/code-ready/crc/pkg/crc/version"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const defaultWatchStatusInterval = 5 * time.Second
+
+// NewGRPCServer returns the gRPC server of the daemon API, it uses the same
+// Handler as the REST API
+func NewGRPCServer(config crcConfig.Storage, machine machine.Client, logger Logger, telemetry Telemetry) *grpc.Server {
+	handler := NewHandler(config, machine, logger, telemetry)
+	return newGRPCServer(func(_ string) (*Handler, error) {
+		return handler, nil
+	})
+}
+
+func newGRPCServer(handlers func(name string) (*Handler, error)) *grpc.Server {
+	server := grpc.NewServer()
+	daemonpb.RegisterDaemonServer(server, &grpcServer{
+		handlers: handlers,
+		events:   notify.Events,
+	})
+	return server
+}
+
+// WithGRPC serves the gRPC requests with grpcServer and the other ones with
+// handler, the gRPC clients use HTTP/2 without TLS on the daemon socket
+func WithGRPC(handler http.Handler, grpcServer *grpc.Server) http.Handler {
+	return h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor == 2 && strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+			grpcServer.ServeHTTP(w, r)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}), &http2.Server{})
+}
+
+type grpcServer struct {
+	daemonpb.UnimplementedDaemonServer
+
+	handlers func(name string) (*Handler, error)
+	events   *notify.Broadcaster
+}
+
+// handler returns the handler of the instance named by the metadata of the
+// call, or of the default instance
+func (s *grpcServer) handler(ctx gocontext.Context) (*Handler, error) {
+	var name string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(ProfileMetadataKey); len(values) > 0 {
+			name = values[0]
+		}
+	}
+	return s.handlers(name)
+}
+
+func (s *grpcServer) Version(_ gocontext.Context, _ *emptypb.Empty) (*daemonpb.VersionResponse, error) {
+	return &daemonpb.VersionResponse{
+		CrcVersion:       version.GetCRCVersion(),
+		CommitSha:        version.GetCommitSha(),
+		OpenshiftVersion: version.GetBundleVersion(),
+	}, nil
+}
+
+func (s *grpcServer) Start(callCtx gocontext.Context, req *daemonpb.StartRequest) (*daemonpb.StartResponse, error) {
+	handler, err := s.handler(callCtx)
+	if err != nil {
+		return nil, err
+	}
+	ctx := telemetry.NewContext(gocontext.Background())
+	startTime := time.Now()
+	res, err := handler.start(ctx, client.StartConfig{PullSecretFile: req.PullSecretFile})
+	handler.uploadOperation(ctx, "start", startTime, err)
+	if err != nil {
+		return nil, err
+	}
+	return &daemonpb.StartResponse{
+		Status: string(res.Status),
+		ClusterConfig: &daemonpb.ClusterConfig{
+			ClusterApi:        res.ClusterConfig.ClusterAPI,
+			WebConsoleUrl:     res.ClusterConfig.WebConsoleURL,
+			KubeadminPassword: res.ClusterConfig.KubeAdminPass,
+			ClusterCaCert:     res.ClusterConfig.ClusterCACert,
+		},
+		KubeletStarted: res.KubeletStarted,
+		AlreadyRunning: res.AlreadyRunning,
+	}, nil
+}
+
+func (s *grpcServer) Stop(callCtx gocontext.Context, _ *emptypb.Empty) (*daemonpb.StopResponse, error) {
+	handler, err := s.handler(callCtx)
+	if err != nil {
+		return nil, err
+	}
+	ctx := telemetry.NewContext(gocontext.Background())
+	startTime := time.Now()
+	result, err := handler.Client.Stop()
+	handler.uploadOperation(ctx, "stop", startTime, err)
+	if err != nil {
+		return nil, err
+	}
+	return &daemonpb.StopResponse{AlreadyStopped: result.AlreadyStopped}, nil
+}
+
+func (s *grpcServer) PowerOff(ctx gocontext.Context, req *daemonpb.PowerOffRequest) (*daemonpb.StopResponse, error) {
+	handler, err := s.handler(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := handler.Client.PowerOff(types.PowerOffConfig{ProtectionToken: req.Token})
+	if err != nil {
+		return nil, err
+	}
+	return &daemonpb.StopResponse{AlreadyStopped: result.AlreadyStopped}, nil
+}
+
+func (s *grpcServer) Delete(ctx gocontext.Context, req *daemonpb.DeleteRequest) (*daemonpb.DeleteResponse, error) {
+	handler, err := s.handler(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := handler.Client.Delete(types.DeleteConfig{
+		ClearCache:      req.ClearCache,
+		KeepBundle:      req.KeepBundle,
+		KeepData:        req.KeepData,
+		IgnoreMissing:   req.IgnoreMissing,
+		ProtectionToken: req.Token,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &daemonpb.DeleteResponse{AlreadyDeleted: result.AlreadyDeleted}, nil
+}
+
+func (s *grpcServer) Hibernate(ctx gocontext.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	handler, err := s.handler(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, handler.Client.Hibernate()
+}
+
+func (s *grpcServer) Pause(ctx gocontext.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	handler, err := s.handler(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, handler.Client.Pause(types.PauseConfig{})
+}
+
+func (s *grpcServer) Resume(ctx gocontext.Context, req *daemonpb.ResumeRequest) (*emptypb.Empty, error) {
+	handler, err := s.handler(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, handler.Client.Resume(types.ResumeConfig{SyncClock: req.SyncClock})
+}
+
+func (s *grpcServer) UpdateProxy(ctx gocontext.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	handler, err := s.handler(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, handler.Client.UpdateProxy()
+}
+
+func (s *grpcServer) Status(ctx gocontext.Context, _ *emptypb.Empty) (*daemonpb.StatusResponse, error) {
+	handler, err := s.handler(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res, err := handler.Client.Status()
+	if err != nil {
+		return nil, err
+	}
+	return statusResponse(res), nil
+}
+
+func (s *grpcServer) WatchStatus(req *daemonpb.WatchStatusRequest, stream daemonpb.Daemon_WatchStatusServer) error {
+	handler, err := s.handler(stream.Context())
+	if err != nil {
+		return err
+	}
+	interval := defaultWatchStatusInterval
+	if req.Interval > 0 {
+		interval = time.Duration(req.Interval) * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last *daemonpb.StatusResponse
+	for {
+		res, err := handler.Client.Status()
+		if err != nil {
+			return err
+		}
+		status := statusResponse(res)
+		if last == nil || statusChanged(last, status) {
+			if err := stream.Send(status); err != nil {
+				return err
+			}
+			last = status
+		}
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// statusChanged compares the statuses without the uptime, which changes
+// every time
+func statusChanged(previous, current *daemonpb.StatusResponse) bool {
+	previous = proto.Clone(previous).(*daemonpb.StatusResponse)
+	current = proto.Clone(current).(*daemonpb.StatusResponse)
+	previous.Uptime, current.Uptime = 0, 0
+	return !proto.Equal(previous, current)
+}
+
+func (s *grpcServer) Logs(req *daemonpb.LogsRequest, stream daemonpb.Daemon_LogsServer) error {
+	handler, err := s.handler(stream.Context())
+	if err != nil {
+		return err
+	}
+	// subscribe before reading the buffer to not miss the messages logged
+	// in between
+	var messages <-chan string
+	if req.Follow {
+		var cancel func()
+		messages, cancel = handler.Logger.Subscribe()
+		defer cancel()
+	}
+	for _, message := range handler.Logger.Messages() {
+		if err := stream.Send(&daemonpb.LogMessage{Message: message}); err != nil {
+			return err
+		}
+	}
+	if !req.Follow {
+		return nil
+	}
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case message := <-messages:
+			if err := stream.Send(&daemonpb.LogMessage{Message: message}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *grpcServer) UnitLogs(req *daemonpb.UnitLogsRequest, stream daemonpb.Daemon_UnitLogsServer) error {
+	handler, err := s.handler(stream.Context())
+	if err != nil {
+		return err
+	}
+	writer := &lineSender{
+		send: func(line string) error {
+			return stream.Send(&daemonpb.LogMessage{Message: line})
+		},
+	}
+	logsConfig := types.UnitLogsConfig{
+		Unit:   req.Unit,
+		Lines:  int(req.Lines),
+		Follow: req.Follow,
+	}
+	// the journalctl command ends with the stream
+	if err := handler.Client.TailUnitLogs(stream.Context(), logsConfig, writer); err != nil {
+		return err
+	}
+	return writer.flush()
+}
+
+// lineSender sends each line written to it, without its line ending
+type lineSender struct {
+	send func(line string) error
+	buf  []byte
+}
+
+func (w *lineSender) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			return len(p), nil
+		}
+		line := string(w.buf[:i])
+		w.buf = w.buf[i+1:]
+		if err := w.send(line); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// flush sends the last line when it has no line ending
+func (w *lineSender) flush() error {
+	if len(w.buf) == 0 {
+		return nil
+	}
+	line := string(w.buf)
+	w.buf = nil
+	return w.send(line)
+}
+
+func (s *grpcServer) Events(_ *emptypb.Empty, stream daemonpb.Daemon_EventsServer) error {
+	events, cancel := s.events.Subscribe()
+	defer cancel()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event := <-events:
+			if err := stream.Send(&daemonpb.Event{
+				Type:     string(event.Type),
+				Instance: event.Instance,
+				Message:  event.Message,
+				Time:     timestamppb.New(event.Time),
+			}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func statusResponse(res *types.ClusterStatusResult) *daemonpb.StatusResponse {
+	return &daemonpb.StatusResponse{
+		CrcStatus:          string(res.CrcStatus),
+		OpenshiftStatus:    string(res.OpenshiftStatus),
+		OpenshiftVersion:   res.OpenshiftVersion,
+		DiskUse:            res.DiskUse,
+		DiskSize:           res.DiskSize,
+		CertsExpiry:        timestamp(res.CertsExpiry),
+		CertsRenewalNeeded: res.CertsRenewalNeeded,
+		ClusterId:          res.ClusterID,
+		Uptime:             int64(res.Uptime.Seconds()),
+		LastStart:          timestamp(res.LastStart),
+		BundleBuildTime:    timestamp(res.BundleBuildTime),
+		BundleOutdated:     res.BundleOutdated,
+	}
+}
+
+// timestamp leaves the unknown times unset
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,189 @@
+package api
+
+import (
+	gocontext "context"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/api/daemonpb"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/notify"
+	"github.com/code-ready/crc/pkg/crc/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// newGRPCTestClient serves the REST and the gRPC APIs on the same listener,
+// as the daemon does
+func newGRPCTestClient(t *testing.T, machine machine.Client) (daemonpb.DaemonClient, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	config := setupNewInMemoryConfig()
+	handler := WithGRPC(NewMux(config, machine, &mockLogger{}, &mockTelemetry{}),
+		NewGRPCServer(config, machine, &mockLogger{}, &mockTelemetry{}))
+	server := &http.Server{Handler: handler}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	t.Cleanup(func() {
+		_ = server.Close()
+	})
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return daemonpb.NewDaemonClient(conn), "http://" + listener.Addr().String()
+}
+
+func TestGRPCVersion(t *testing.T) {
+	client, _ := newGRPCTestClient(t, fakemachine.NewClient())
+	res, err := client.Version(gocontext.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, version.GetCRCVersion(), res.CrcVersion)
+	assert.Equal(t, version.GetBundleVersion(), res.OpenshiftVersion)
+}
+
+func TestGRPCAndRESTOnSameListener(t *testing.T) {
+	_, url := newGRPCTestClient(t, fakemachine.NewClient())
+	res, err := http.Get(url + "/version")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestGRPCStart(t *testing.T) {
+	client, _ := newGRPCTestClient(t, fakemachine.NewClient())
+	res, err := client.Start(gocontext.Background(), &daemonpb.StartRequest{})
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(&daemonpb.StartResponse{
+		ClusterConfig: &daemonpb.ClusterConfig{
+			ClusterApi:        fakemachine.DummyClusterConfig.ClusterAPI,
+			WebConsoleUrl:     fakemachine.DummyClusterConfig.WebConsoleURL,
+			KubeadminPassword: fakemachine.DummyClusterConfig.KubeAdminPass,
+			ClusterCaCert:     fakemachine.DummyClusterConfig.ClusterCACert,
+		},
+		KubeletStarted: true,
+	}, res), res.String())
+}
+
+func TestGRPCStatus(t *testing.T) {
+	client, _ := newGRPCTestClient(t, fakemachine.NewClient())
+	res, err := client.Status(gocontext.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, "Running", res.CrcStatus)
+	assert.Equal(t, "Running", res.OpenshiftStatus)
+	assert.Equal(t, int64(9000), res.Uptime)
+	assert.Equal(t, time.Date(2031, time.January, 1, 0, 0, 0, 0, time.UTC), res.CertsExpiry.AsTime())
+
+	stream, err := client.WatchStatus(gocontext.Background(), &daemonpb.WatchStatusRequest{Interval: 1})
+	require.NoError(t, err)
+	watched, err := stream.Recv()
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(res, watched))
+}
+
+func TestGRPCFailure(t *testing.T) {
+	client, _ := newGRPCTestClient(t, fakemachine.NewFailingClient())
+	_, err := client.Stop(gocontext.Background(), &emptypb.Empty{})
+	assert.EqualError(t, err, "rpc error: code = Unknown desc = stop failed")
+}
+
+func TestGRPCLogs(t *testing.T) {
+	client, _ := newGRPCTestClient(t, fakemachine.NewClient())
+
+	stream, err := client.Logs(gocontext.Background(), &daemonpb.LogsRequest{})
+	require.NoError(t, err)
+	var messages []string
+	for {
+		message, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		messages = append(messages, message.Message)
+	}
+	assert.Equal(t, []string{"message 1", "message 2", "message 3"}, messages)
+
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	defer cancel()
+	stream, err = client.Logs(ctx, &daemonpb.LogsRequest{Follow: true})
+	require.NoError(t, err)
+	messages = nil
+	for i := 0; i < 4; i++ {
+		message, err := stream.Recv()
+		require.NoError(t, err)
+		messages = append(messages, message.Message)
+	}
+	assert.Equal(t, []string{"message 1", "message 2", "message 3", "message 4"}, messages)
+}
+
+func TestGRPCUnitLogs(t *testing.T) {
+	client, _ := newGRPCTestClient(t, fakemachine.NewClient())
+
+	stream, err := client.UnitLogs(gocontext.Background(), &daemonpb.UnitLogsRequest{Unit: "kubelet", Follow: true})
+	require.NoError(t, err)
+	var messages []string
+	for {
+		message, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		messages = append(messages, message.Message)
+	}
+	assert.Equal(t, []string{"2021-10-17T12:00:00+0000 crc systemd[1]: Started kubelet."}, messages)
+
+	failingClient, _ := newGRPCTestClient(t, fakemachine.NewFailingClient())
+	stream, err = failingClient.UnitLogs(gocontext.Background(), &daemonpb.UnitLogsRequest{Unit: "kubelet"})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	assert.EqualError(t, err, "rpc error: code = Unknown desc = tailing unit logs failed")
+}
+
+func TestLineSender(t *testing.T) {
+	var lines []string
+	writer := &lineSender{
+		send: func(line string) error {
+			lines = append(lines, line)
+			return nil
+		},
+	}
+	_, err := writer.Write([]byte("first\nsec"))
+	require.NoError(t, err)
+	_, err = writer.Write([]byte("ond\nlast"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, lines)
+	require.NoError(t, writer.flush())
+	assert.Equal(t, []string{"first", "second", "last"}, lines)
+}
+
+func TestGRPCEvents(t *testing.T) {
+	client, _ := newGRPCTestClient(t, fakemachine.NewClient())
+	ctx, cancel := gocontext.WithCancel(gocontext.Background())
+	defer cancel()
+	stream, err := client.Events(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+
+	// the subscription is only known once the stream is served
+	go func() {
+		for ctx.Err() == nil {
+			notify.Events.Publish(notify.Event{Type: notify.DiskPressure, Instance: "crc", Message: "disk full"})
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "disk-pressure", event.Type)
+	assert.Equal(t, "crc", event.Instance)
+	assert.Equal(t, "disk full", event.Message)
+}
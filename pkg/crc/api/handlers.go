@@ -2,15 +2,26 @@ package api
 
 import (
 	gocontext "context"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/code-ready/crc/pkg/crc/api/client"
 	"github.com/code-ready/crc/pkg/crc/cluster"
 	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/credentials"
 	"github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/metrics"
+	"github.com/code-ready/crc/pkg/crc/network/forward"
 	"github.com/code-ready/crc/pkg/crc/preflight"
+	"github.com/code-ready/crc/pkg/crc/telemetry"
 	"github.com/code-ready/crc/pkg/crc/version"
 )
 
@@ -19,14 +30,57 @@ type Handler struct {
 	Client    machine.Client
 	Config    crcConfig.Storage
 	Telemetry Telemetry
+	Preflight Preflight
+
+	networkSelfTest networkSelfTest
+	metricsStatus   metrics.StatusFunc
 }
 
 type Logger interface {
 	Messages() []string
+	// Subscribe returns the channel receiving the next messages, and the
+	// function to call once they are not needed anymore
+	Subscribe() (<-chan string, func())
 }
 
 type Telemetry interface {
 	UploadAction(action, source, status string) error
+	// UploadOperation sends the outcome and the duration of an operation,
+	// with the step results recorded in ctx
+	UploadOperation(ctx gocontext.Context, action, source string, duration time.Duration, err error) error
+}
+
+// Preflight runs the preflight checks of the host
+type Preflight interface {
+	ListChecks() []preflight.CheckInfo
+	RunCheck(name string) (*preflight.CheckResult, error)
+	FixCheck(name string) (*preflight.CheckResult, error)
+	DriverHealth() []preflight.CheckResult
+	RepairDriver() []preflight.CheckResult
+}
+
+type hostPreflight struct {
+	config crcConfig.Storage
+}
+
+func (p *hostPreflight) ListChecks() []preflight.CheckInfo {
+	return preflight.ListChecks(p.config)
+}
+
+func (p *hostPreflight) RunCheck(name string) (*preflight.CheckResult, error) {
+	return preflight.RunCheck(p.config, name)
+}
+
+func (p *hostPreflight) FixCheck(name string) (*preflight.CheckResult, error) {
+	return preflight.FixCheck(p.config, name)
+}
+
+func (p *hostPreflight) DriverHealth() []preflight.CheckResult {
+	return preflight.DriverHealth(p.config)
+}
+
+func (p *hostPreflight) RepairDriver() []preflight.CheckResult {
+	return preflight.RepairDriver(p.config)
 }
 
 func (h *Handler) Logs(c *context) error {
@@ -42,6 +96,9 @@ func NewHandler(config crcConfig.Storage, machine machine.Client, logger Logger,
 		Config:    config,
 		Logger:    logger,
 		Telemetry: telemetry,
+		Preflight: &hostPreflight{config: config},
+
+		metricsStatus: metrics.CachedStatus(machine),
 	}
 }
 
@@ -50,21 +107,175 @@ func (h *Handler) Status(c *context) error {
 	if err != nil {
 		return err
 	}
-	return c.JSON(http.StatusOK, client.ClusterStatusResult{
-		CrcStatus:        string(res.CrcStatus),
-		OpenshiftStatus:  string(res.OpenshiftStatus),
-		OpenshiftVersion: res.OpenshiftVersion,
-		DiskUse:          res.DiskUse,
-		DiskSize:         res.DiskSize,
-		Success:          true,
-	})
+	result := client.ClusterStatusResult{
+		CrcStatus:          string(res.CrcStatus),
+		OpenshiftStatus:    string(res.OpenshiftStatus),
+		OpenshiftVersion:   res.OpenshiftVersion,
+		DiskUse:            res.DiskUse,
+		DiskSize:           res.DiskSize,
+		CertsRenewalNeeded: res.CertsRenewalNeeded,
+		ClusterID:          res.ClusterID,
+		Hypervisor:         res.Hypervisor,
+		Uptime:             int64(res.Uptime.Seconds()),
+		BundleOutdated:     res.BundleOutdated,
+		Success:            true,
+	}
+	if !res.CertsExpiry.IsZero() {
+		result.CertsExpiry = &res.CertsExpiry
+	}
+	if !res.LastStart.IsZero() {
+		result.LastStart = &res.LastStart
+	}
+	if !res.BundleBuildTime.IsZero() {
+		result.BundleBuildTime = &res.BundleBuildTime
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// Readyz answers 200 when the cluster is ready and 503 with the reason when it
+// is not, in the format of the Kubernetes readiness probes
+func (h *Handler) Readyz(c *context) error {
+	if reason := h.notReadyReason(); reason != "" {
+		return c.String(http.StatusServiceUnavailable, reason+"\n")
+	}
+	return c.String(http.StatusOK, "ok\n")
+}
+
+func (h *Handler) notReadyReason() string {
+	res, err := h.Client.Status()
+	if err != nil {
+		return err.Error()
+	}
+	if res.CrcStatus != state.Running {
+		return fmt.Sprintf("CodeReady Containers VM is %s", strings.ToLower(string(res.CrcStatus)))
+	}
+	if res.OpenshiftStatus != types.OpenshiftRunning {
+		return fmt.Sprintf("OpenShift is %s", strings.ToLower(string(res.OpenshiftStatus)))
+	}
+	return ""
 }
 
 func (h *Handler) Stop(c *context) error {
-	_, err := h.Client.Stop()
+	ctx := telemetry.NewContext(gocontext.Background())
+	startTime := time.Now()
+	result, err := h.Client.Stop()
+	h.uploadOperation(ctx, "stop", startTime, err)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, client.StopResult{
+		Success:        true,
+		AlreadyStopped: result.AlreadyStopped,
+	})
+}
+
+func (h *Handler) Hibernate(c *context) error {
+	if err := h.Client.Hibernate(); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, client.Result{
+		Success: true,
+	})
+}
+
+func (h *Handler) UpdateProxy(c *context) error {
+	if err := h.Client.UpdateProxy(); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, client.Result{
+		Success: true,
+	})
+}
+
+// ShiftClock moves the clock of the VM to test the certificates expiry, it is
+// meant for the developers of crc
+func (h *Handler) ShiftClock(c *context) error {
+	var req client.ShiftClockRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	offset, err := time.ParseDuration(req.Offset)
+	if err != nil {
+		return fmt.Errorf("Invalid clock offset '%s': %v", req.Offset, err)
+	}
+	if err := h.Client.ShiftClock(offset); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, client.Result{
+		Success: true,
+	})
+}
+
+// Snapshot checkpoints the disk of the stopped instance
+func (h *Handler) Snapshot(c *context) error {
+	var req client.SnapshotRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := h.Client.Snapshot(req.Name); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, client.Result{
+		Success: true,
+	})
+}
+
+// Revert restores the stopped instance to a snapshot
+func (h *Handler) Revert(c *context) error {
+	var req client.SnapshotRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if err := h.Client.Revert(req.Name); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, client.Result{
+		Success: true,
+	})
+}
+
+// SetVMConfig changes the memory, the CPUs and the disk size of the existing VM
+func (h *Handler) SetVMConfig(c *context) error {
+	var req client.SetVMConfigRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	result, err := h.Client.SetConfig(types.VMConfig{
+		Memory:   req.Memory,
+		CPUs:     req.CPUs,
+		DiskSize: req.DiskSize,
+	})
 	if err != nil {
 		return err
 	}
+	return c.JSON(http.StatusOK, client.SetVMConfigResult{
+		Success:           true,
+		ResourceChanges:   result.Changes,
+		ResourceConflicts: result.Conflicts,
+	})
+}
+
+func (h *Handler) Pause(c *context) error {
+	if err := h.Client.Pause(types.PauseConfig{}); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, client.Result{
+		Success: true,
+	})
+}
+
+func (h *Handler) Resume(c *context) error {
+	var req client.ResumeRequest
+	if len(c.requestBody) > 0 {
+		if err := c.Bind(&req); err != nil {
+			return err
+		}
+	}
+	if err := h.Client.Resume(types.ResumeConfig{
+		SyncClock: req.SyncClock,
+	}); err != nil {
+		return err
+	}
 	return c.JSON(http.StatusOK, client.Result{
 		Success: true,
 	})
@@ -74,11 +285,15 @@ func (h *Handler) PowerOff(c *context) error {
 	if c.method != http.MethodPost {
 		return c.String(http.StatusMethodNotAllowed, "Only POST is allowed")
 	}
-	if err := h.Client.PowerOff(); err != nil {
+	result, err := h.Client.PowerOff(types.PowerOffConfig{
+		ProtectionToken: c.url.Query().Get("token"),
+	})
+	if err != nil {
 		return err
 	}
-	return c.JSON(http.StatusOK, client.Result{
-		Success: true,
+	return c.JSON(http.StatusOK, client.StopResult{
+		Success:        true,
+		AlreadyStopped: result.AlreadyStopped,
 	})
 }
 
@@ -89,29 +304,46 @@ func (h *Handler) Start(c *context) error {
 			return err
 		}
 	}
-	if err := preflight.StartPreflightChecks(h.Config); err != nil {
-		return err
-	}
-
-	startConfig := getStartConfig(h.Config, parsedArgs)
-	res, err := h.Client.Start(gocontext.Background(), startConfig)
+	ctx := telemetry.NewContext(gocontext.Background())
+	startTime := time.Now()
+	res, err := h.start(ctx, parsedArgs)
+	h.uploadOperation(ctx, "start", startTime, err)
 	if err != nil {
 		return err
 	}
 	return c.JSON(http.StatusOK, client.StartResult{
-		Success:        true,
-		Status:         string(res.Status),
-		ClusterConfig:  res.ClusterConfig,
-		KubeletStarted: res.KubeletStarted,
+		Success:           true,
+		Status:            string(res.Status),
+		ClusterConfig:     res.ClusterConfig,
+		KubeletStarted:    res.KubeletStarted,
+		AlreadyRunning:    res.AlreadyRunning,
+		ResourceConflicts: res.ResourceConflicts,
+		ResourceChanges:   res.ResourceChanges,
+		Summary:           res.Summary,
 	})
 }
 
+func (h *Handler) start(ctx gocontext.Context, args client.StartConfig) (*types.StartResult, error) {
+	if err := preflight.StartPreflightChecks(h.Config); err != nil {
+		return nil, err
+	}
+	return h.Client.Start(ctx, getStartConfig(h.Config, args))
+}
+
+// uploadOperation reports the outcome of an operation of the daemon, it is
+// only sent when the user consented to the telemetry
+func (h *Handler) uploadOperation(ctx gocontext.Context, action string, startTime time.Time, err error) {
+	if uploadErr := h.Telemetry.UploadOperation(ctx, action, "daemon", time.Since(startTime), err); uploadErr != nil {
+		logging.Debugf("Cannot send data to telemetry: %v", uploadErr)
+	}
+}
+
 func getStartConfig(cfg crcConfig.Storage, args client.StartConfig) types.StartConfig {
 	return types.StartConfig{
 		BundlePath:        cfg.Get(crcConfig.Bundle).AsString(),
-		Memory:            cfg.Get(crcConfig.Memory).AsInt(),
+		Memory:            cfg.Get(crcConfig.Memory).AsAutomaticInt(),
 		DiskSize:          cfg.Get(crcConfig.DiskSize).AsInt(),
-		CPUs:              cfg.Get(crcConfig.CPUs).AsInt(),
+		CPUs:              cfg.Get(crcConfig.CPUs).AsAutomaticInt(),
 		NameServer:        cfg.Get(crcConfig.NameServer).AsString(),
 		PullSecret:        cluster.NewNonInteractivePullSecretLoader(cfg, args.PullSecretFile),
 		KubeAdminPassword: cfg.Get(crcConfig.KubeAdminPassword).AsString(),
@@ -128,15 +360,128 @@ func (h *Handler) GetVersion(c *context) error {
 }
 
 func (h *Handler) Delete(c *context) error {
-	err := h.Client.Delete()
+	query := c.url.Query()
+	result, err := h.Client.Delete(types.DeleteConfig{
+		ClearCache:      query.Get("clearCache") == "true",
+		KeepBundle:      query.Get("keepBundle") == "true",
+		KeepData:        query.Get("keepData") == "true",
+		IgnoreMissing:   query.Get("ignoreMissing") == "true",
+		ProtectionToken: query.Get("token"),
+	})
 	if err != nil {
 		return err
 	}
+	return c.JSON(http.StatusOK, client.DeleteResult{
+		Success:        true,
+		AlreadyDeleted: result.AlreadyDeleted,
+	})
+}
+
+func (h *Handler) Protect(c *context) error {
+	token, err := h.Client.Protect()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, client.ProtectResult{
+		Token:   token,
+		Success: true,
+	})
+}
+
+func (h *Handler) Unprotect(c *context) error {
+	if err := h.Client.Unprotect(c.url.Query().Get("token")); err != nil {
+		return err
+	}
 	return c.JSON(http.StatusOK, client.Result{
 		Success: true,
 	})
 }
 
+func (h *Handler) History(c *context) error {
+	entries, err := h.Client.History()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, client.HistoryResult{
+		Success: true,
+		Entries: entries,
+	})
+}
+
+func (h *Handler) PreflightChecks(c *context) error {
+	checks := []client.PreflightCheck{}
+	for _, check := range h.Preflight.ListChecks() {
+		checks = append(checks, client.PreflightCheck{
+			Name:           check.Name,
+			Description:    check.Description,
+			FixDescription: check.FixDescription,
+			Fixable:        check.Fixable,
+			SetupOnly:      check.SetupOnly,
+			Skipped:        check.Skipped,
+		})
+	}
+	return c.JSON(http.StatusOK, client.PreflightChecksResult{
+		Success: true,
+		Checks:  checks,
+	})
+}
+
+func (h *Handler) RunPreflightCheck(c *context) error {
+	return runPreflight(c, h.Preflight.RunCheck)
+}
+
+func (h *Handler) FixPreflightCheck(c *context) error {
+	return runPreflight(c, h.Preflight.FixCheck)
+}
+
+func runPreflight(c *context, run func(name string) (*preflight.CheckResult, error)) error {
+	var req client.PreflightCheckRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if req.Name == "" {
+		return c.JSON(http.StatusBadRequest, client.PreflightCheckResult{
+			Error: "No preflight check name provided",
+		})
+	}
+	res, err := run(req.Name)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, client.PreflightCheckResult{
+		Success: true,
+		Name:    res.Name,
+		Passed:  res.Success,
+		Skipped: res.Skipped,
+		Failure: res.Error,
+	})
+}
+
+func (h *Handler) DriverHealth(c *context) error {
+	return c.JSON(http.StatusOK, driverHealthResult(h.Preflight.DriverHealth()))
+}
+
+func (h *Handler) RepairDriver(c *context) error {
+	return c.JSON(http.StatusOK, driverHealthResult(h.Preflight.RepairDriver()))
+}
+
+func driverHealthResult(results []preflight.CheckResult) client.DriverHealthResult {
+	checks := []client.DriverCheck{}
+	for _, result := range results {
+		checks = append(checks, client.DriverCheck{
+			Name:    result.Name,
+			Passed:  result.Success,
+			Skipped: result.Skipped,
+			Failure: result.Error,
+		})
+	}
+	return client.DriverHealthResult{
+		Success: true,
+		Healthy: preflight.Healthy(results),
+		Checks:  checks,
+	}
+}
+
 func (h *Handler) GetWebconsoleInfo(c *context) error {
 	res, err := h.Client.GetConsoleURL()
 	if err != nil {
@@ -148,6 +493,120 @@ func (h *Handler) GetWebconsoleInfo(c *context) error {
 	})
 }
 
+func (h *Handler) GetCredentials(c *context) error {
+	format := credentials.JSONFormat
+	if value := c.url.Query().Get("format"); value != "" {
+		format = credentials.Format(value)
+	}
+	res, err := h.Client.GetConsoleURL()
+	if err != nil {
+		return err
+	}
+	creds := credentials.FromClusterConfig(res.ClusterConfig)
+	if format == credentials.JSONFormat {
+		return c.JSON(http.StatusOK, creds)
+	}
+	out, err := creds.Render(format)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	return c.String(http.StatusOK, out)
+}
+
+func (h *Handler) Images(c *context) error {
+	res, err := h.Client.ListImages()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, client.ImagesResult{
+		Images:  res.Images,
+		Success: true,
+	})
+}
+
+// StartNetworkSelfTest starts the network self-test of the running cluster
+// unless it is already running, it takes minutes so its result is read
+// with NetworkSelfTest
+func (h *Handler) StartNetworkSelfTest(c *context) error {
+	status, err := h.Client.Status()
+	if err != nil {
+		return err
+	}
+	if status.CrcStatus != state.Running {
+		return fmt.Errorf("Cannot run the network self-test, the instance is %s", strings.ToLower(string(status.CrcStatus)))
+	}
+	h.networkSelfTest.start(h.Client)
+	return c.JSON(http.StatusAccepted, h.networkSelfTest.status())
+}
+
+func (h *Handler) NetworkSelfTest(c *context) error {
+	return c.JSON(http.StatusOK, h.networkSelfTest.status())
+}
+
+// Health reports the health of the virtualization stack and of the network
+// of the cluster, as found by the last network self-test
+func (h *Handler) Health(c *context) error {
+	driver := driverHealthResult(h.Preflight.DriverHealth())
+	network := h.networkSelfTest.last()
+	return c.JSON(http.StatusOK, client.HealthResult{
+		Success:         true,
+		Healthy:         driver.Healthy && (network == nil || network.Healthy),
+		Driver:          driver.Checks,
+		NetworkSelfTest: network,
+	})
+}
+
+func (h *Handler) Exec(c *context) error {
+	var req client.ExecRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	res, err := h.Client.Exec(types.ExecConfig{
+		Command:    req.Command,
+		Privileged: req.Privileged,
+	})
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, client.ExecResult{
+		Stdout:  res.Stdout,
+		Stderr:  res.Stderr,
+		Success: true,
+	})
+}
+
+// UnitLogs streams the journal of the systemd unit of the VM given by the
+// unit parameter, with follow=true until the client closes the connection
+func (h *Handler) UnitLogs(c *context) error {
+	query := c.url.Query()
+	logsConfig := types.UnitLogsConfig{
+		Unit:   query.Get("unit"),
+		Follow: query.Get("follow") == "true",
+	}
+	if value := query.Get("lines"); value != "" {
+		lines, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("Invalid number of lines '%s'", value)
+		}
+		logsConfig.Lines = lines
+	}
+	return c.Stream(http.StatusOK, "text/plain; charset=UTF-8", func(ctx gocontext.Context, w io.Writer) error {
+		return h.Client.TailUnitLogs(ctx, logsConfig, w)
+	})
+}
+
+// Metrics serves the status of the instance in the text exposition format of
+// Prometheus
+func (h *Handler) Metrics(c *context) error {
+	status, err := h.metricsStatus()
+	if err != nil {
+		return err
+	}
+	return c.Stream(http.StatusOK, metrics.ContentType, func(_ gocontext.Context, w io.Writer) error {
+		return metrics.Write(w, status, time.Now())
+	})
+}
+
 func (h *Handler) SetConfig(c *context) error {
 	var req client.SetConfigRequest
 	if err := c.Bind(&req); err != nil {
@@ -165,11 +624,13 @@ func (h *Handler) SetConfig(c *context) error {
 	var successProps []string
 	var multiError = errors.MultiError{}
 	for k, v := range req.Properties {
+		oldValue := h.Config.Get(k).Value
 		_, err := h.Config.Set(k, v)
 		if err != nil {
 			multiError.Collect(err)
 			continue
 		}
+		h.configChanged(k, oldValue)
 		successProps = append(successProps, k)
 	}
 	if len(multiError.Errors) != 0 {
@@ -198,10 +659,12 @@ func (h *Handler) UnsetConfig(c *context) error {
 	var successProps []string
 	var multiError = errors.MultiError{}
 	for _, key := range req.Properties {
+		oldValue := h.Config.Get(key).Value
 		if _, err := h.Config.Unset(key); err != nil {
 			multiError.Collect(err)
 			continue
 		}
+		h.configChanged(key, oldValue)
 		successProps = append(successProps, key)
 	}
 	if len(multiError.Errors) != 0 {
@@ -213,6 +676,14 @@ func (h *Handler) UnsetConfig(c *context) error {
 	})
 }
 
+// configChanged tells the instance about the change of key, so that it is
+// recorded in its history and applied when needed
+func (h *Handler) configChanged(key string, oldValue interface{}) {
+	if _, err := h.Client.ConfigChanged(key, oldValue); err != nil {
+		logging.Debugf("Cannot record the change of %s: %v", key, err)
+	}
+}
+
 func (h *Handler) GetConfig(c *context) error {
 	queries := c.url.Query()
 	var req client.GetOrUnsetConfigRequest
@@ -224,7 +695,7 @@ func (h *Handler) GetConfig(c *context) error {
 		allConfigs := h.Config.AllConfigs()
 		configs := make(map[string]interface{})
 		for k, v := range allConfigs {
-			configs[k] = v.Value
+			configs[k] = v.DisplayValue()
 		}
 		return c.JSON(http.StatusOK, client.GetConfigResult{
 			Success: true,
@@ -238,7 +709,7 @@ func (h *Handler) GetConfig(c *context) error {
 		if v.Invalid {
 			continue
 		}
-		configs[key] = v.Value
+		configs[key] = v.DisplayValue()
 	}
 
 	if len(configs) == 0 {
@@ -250,12 +721,95 @@ func (h *Handler) GetConfig(c *context) error {
 	})
 }
 
+func (h *Handler) GetHostServices(c *context) error {
+	return c.JSON(http.StatusOK, client.HostServicesResult{
+		Services: crcConfig.GetHostServices(h.Config),
+		Success:  true,
+	})
+}
+
+func (h *Handler) AddHostService(c *context) error {
+	var req client.HostServiceRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	if req.Name == "" {
+		return c.String(http.StatusBadRequest, "No host service provided")
+	}
+	if err := crcConfig.AddHostService(h.Config, req.Name); err != nil {
+		return err
+	}
+	return h.GetHostServices(c)
+}
+
+func (h *Handler) RemoveHostService(c *context) error {
+	name := c.url.Query().Get("name")
+	if name == "" {
+		return c.String(http.StatusBadRequest, "No host service provided")
+	}
+	if err := crcConfig.RemoveHostService(h.Config, name); err != nil {
+		return err
+	}
+	return h.GetHostServices(c)
+}
+
+func (h *Handler) GetPortForwards(c *context) error {
+	forwards, err := h.Client.ListPortForwards()
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, client.PortForwardsResult{
+		Forwards: forwards,
+		Success:  true,
+	})
+}
+
+func (h *Handler) AddPortForward(c *context) error {
+	var req client.PortForwardRequest
+	if err := c.Bind(&req); err != nil {
+		return err
+	}
+	portForward, err := forward.New(req.Local, req.Remote)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	if err := h.Client.AddPortForward(portForward); err != nil {
+		return err
+	}
+	return h.GetPortForwards(c)
+}
+
+func (h *Handler) RemovePortForward(c *context) error {
+	local := c.url.Query().Get("local")
+	if local == "" {
+		return c.String(http.StatusBadRequest, "No port forward provided")
+	}
+	if err := h.Client.RemovePortForward(local); err != nil {
+		return err
+	}
+	return h.GetPortForwards(c)
+}
+
+// reportedByDaemon returns true for the actions of the tray, such as 'click
+// start', on an operation the daemon reports itself with uploadOperation, they
+// would be counted twice
+func reportedByDaemon(action string) bool {
+	switch strings.TrimPrefix(action, "click ") {
+	case "start", "stop":
+		return true
+	default:
+		return false
+	}
+}
+
 func (h *Handler) UploadTelemetry(c *context) error {
 	var req client.TelemetryRequest
 	if err := c.Bind(&req); err != nil {
 		return err
 	}
-	if err := h.Telemetry.UploadAction(req.Action, req.Source, req.Status); err != nil {
+	if reportedByDaemon(req.Action) {
+		logging.Debugf("Not sending '%s' to telemetry, the daemon reports the operation itself", req.Action)
+	} else if err := h.Telemetry.UploadAction(req.Action, req.Source, req.Status); err != nil {
 		return err
 	}
 	return c.JSON(http.StatusOK, client.Result{
@@ -1,7 +1,9 @@
 package api
 
 import (
+	gocontext "context"
 	"encoding/json"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -18,6 +20,9 @@ type context struct {
 	code         int
 	headers      map[string]string
 	responseBody []byte
+	// stream writes the response body while the request is served, instead
+	// of responseBody
+	stream func(ctx gocontext.Context, w io.Writer) error
 }
 
 func (c *context) Bind(r interface{}) error {
@@ -40,6 +45,45 @@ func (c *context) String(code int, r string) error {
 	return err
 }
 
+// Stream sends the output of fn as it is written, until fn returns or the
+// client goes away. The status code and the headers are sent with the first
+// write, an error returned by fn before it is reported like the errors of the
+// other handlers, later ones are only logged.
+func (c *context) Stream(code int, contentType string, fn func(ctx gocontext.Context, w io.Writer) error) error {
+	c.code = code
+	c.headers["Content-Type"] = contentType
+	c.stream = fn
+	return nil
+}
+
+// streamWriter sends the status code and the headers of the response before
+// the first write, and each write to the client right away
+type streamWriter struct {
+	w       http.ResponseWriter
+	c       *context
+	started bool
+}
+
+func (sw *streamWriter) start() {
+	if sw.started {
+		return
+	}
+	sw.started = true
+	for k, v := range sw.c.headers {
+		sw.w.Header().Set(k, v)
+	}
+	sw.w.WriteHeader(sw.c.code)
+}
+
+func (sw *streamWriter) Write(p []byte) (int, error) {
+	sw.start()
+	n, err := sw.w.Write(p)
+	if flusher, ok := sw.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	return n, err
+}
+
 type server struct {
 	routes     map[string]map[string]func(*context) error
 	routesLock sync.RWMutex
@@ -111,10 +155,24 @@ func (s *server) Handler() http.Handler {
 			return
 		}
 
-		w.WriteHeader(c.code)
+		if c.stream != nil {
+			sw := &streamWriter{w: w, c: c}
+			err := c.stream(r.Context(), sw)
+			switch {
+			case err != nil && !sw.started:
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			case err != nil:
+				logging.Error("Failed to stream response: ", err)
+			default:
+				sw.start()
+			}
+			return
+		}
+
 		for k, v := range c.headers {
 			w.Header().Set(k, v)
 		}
+		w.WriteHeader(c.code)
 		if _, err := w.Write(c.responseBody); err != nil {
 			logging.Error("Failed to send response: ", err)
 		}
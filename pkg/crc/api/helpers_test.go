@@ -1,7 +1,10 @@
 package api
 
 import (
+	gocontext "context"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/preflight"
@@ -46,11 +49,61 @@ func (*mockLogger) Messages() []string {
 	return []string{"message 1", "message 2", "message 3"}
 }
 
+func (*mockLogger) Subscribe() (<-chan string, func()) {
+	messages := make(chan string, 1)
+	messages <- "message 4"
+	return messages, func() {}
+}
+
 type mockTelemetry struct {
-	actions []string
+	actions    []string
+	operations []string
 }
 
 func (m *mockTelemetry) UploadAction(action, _, _ string) error {
 	m.actions = append(m.actions, action)
 	return nil
 }
+
+func (m *mockTelemetry) UploadOperation(_ gocontext.Context, action, source string, _ time.Duration, err error) error {
+	m.operations = append(m.operations, fmt.Sprintf("%s %s: %v", source, action, err))
+	return nil
+}
+
+type mockPreflight struct {
+}
+
+func (*mockPreflight) ListChecks() []preflight.CheckInfo {
+	return []preflight.CheckInfo{
+		{Name: "check-bundle-extracted", Description: "Checking if CRC bundle is extracted in '$HOME/.crc'", FixDescription: "Getting bundle for the CRC executable", Fixable: true},
+		{Name: "check-ram", Description: "Checking minimum RAM requirements", FixDescription: "crc requires at least 9.7GB to run", Skipped: true},
+	}
+}
+
+func (*mockPreflight) RunCheck(name string) (*preflight.CheckResult, error) {
+	if name != "check-ram" {
+		return nil, fmt.Errorf("Unknown preflight check '%s'", name)
+	}
+	return &preflight.CheckResult{Name: name, Error: "only 8GB of memory found"}, nil
+}
+
+func (*mockPreflight) DriverHealth() []preflight.CheckResult {
+	return []preflight.CheckResult{
+		{Name: "check-libvirt-running", Success: true},
+		{Name: "check-crc-network", Error: "libvirt 'crc' network definition is incorrect"},
+	}
+}
+
+func (*mockPreflight) RepairDriver() []preflight.CheckResult {
+	return []preflight.CheckResult{
+		{Name: "check-libvirt-running", Success: true},
+		{Name: "check-crc-network", Success: true},
+	}
+}
+
+func (*mockPreflight) FixCheck(name string) (*preflight.CheckResult, error) {
+	if name != "check-bundle-extracted" {
+		return nil, fmt.Errorf("Preflight check '%s' cannot be fixed automatically", name)
+	}
+	return &preflight.CheckResult{Name: name, Success: true}, nil
+}
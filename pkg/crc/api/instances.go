@@ -0,0 +1,109 @@
+package api
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/profile"
+	"google.golang.org/grpc"
+)
+
+const (
+	// InstancesPath is the prefix of the REST API of the instances other than
+	// the default one, e.g. /instances/<name>/status
+	InstancesPath = "/instances/"
+	// ProfileMetadataKey is the gRPC metadata selecting the instance a call
+	// applies to, the calls without it apply to the default instance
+	ProfileMetadataKey = "crc-profile"
+)
+
+// InstanceHandlers gives the handler of each instance, they are created on
+// first use and shared by the REST and gRPC APIs
+type InstanceHandlers struct {
+	newHandler func(name string) *Handler
+
+	lock     sync.Mutex
+	handlers map[string]*Handler
+	muxes    map[string]http.Handler
+}
+
+// NewInstanceHandlers serves the instances returned by instances, which must
+// return the same client for a given name
+func NewInstanceHandlers(config crcConfig.Storage, instances func(name string) machine.Client, logger Logger, telemetry Telemetry) *InstanceHandlers {
+	return &InstanceHandlers{
+		newHandler: func(name string) *Handler {
+			return NewHandler(config, instances(name), logger, telemetry)
+		},
+		handlers: make(map[string]*Handler),
+		muxes:    make(map[string]http.Handler),
+	}
+}
+
+// Get returns the handler of the instance called name, the default instance
+// when name is empty
+func (h *InstanceHandlers) Get(name string) (*Handler, error) {
+	instanceProfile, err := profile.New(name)
+	if err != nil {
+		return nil, err
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if handler, ok := h.handlers[instanceProfile.Name]; ok {
+		return handler, nil
+	}
+	handler := h.newHandler(instanceProfile.Name)
+	h.handlers[instanceProfile.Name] = handler
+	h.muxes[instanceProfile.Name] = newServerWithRoutes(handler).Handler()
+	return handler, nil
+}
+
+func (h *InstanceHandlers) mux(name string) (http.Handler, error) {
+	if _, err := h.Get(name); err != nil {
+		return nil, err
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.muxes[name], nil
+}
+
+// NewInstancesMux serves the REST API of the default instance, and the one of
+// the instance called name under /instances/<name>/
+func NewInstancesMux(handlers *InstanceHandlers) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := constants.DefaultName
+		if strings.HasPrefix(r.URL.Path, InstancesPath) {
+			path := strings.TrimPrefix(r.URL.Path, InstancesPath)
+			i := strings.Index(path, "/")
+			if i < 0 {
+				http.NotFound(w, r)
+				return
+			}
+			name = path[:i]
+			r2 := new(http.Request)
+			*r2 = *r
+			r2.URL = new(url.URL)
+			*r2.URL = *r.URL
+			r2.URL.Path = path[i:]
+			r2.URL.RawPath = ""
+			r = r2
+		}
+		mux, err := handlers.mux(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// NewInstancesGRPCServer returns the gRPC server of the daemon API, the calls
+// apply to the instance named by their ProfileMetadataKey metadata
+func NewInstancesGRPCServer(handlers *InstanceHandlers) *grpc.Server {
+	return newGRPCServer(handlers.Get)
+}
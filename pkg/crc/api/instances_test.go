@@ -0,0 +1,72 @@
+package api
+
+import (
+	gocontext "context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apiClient "github.com/code-ready/crc/pkg/crc/api/client"
+	"github.com/code-ready/crc/pkg/crc/api/daemonpb"
+	"github.com/code-ready/crc/pkg/crc/machine"
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func newInstanceHandlers() *InstanceHandlers {
+	clients := map[string]machine.Client{
+		"crc":   fakemachine.NewClient(),
+		"other": fakemachine.NewFailingClient(),
+	}
+	return NewInstanceHandlers(setupNewInMemoryConfig(), func(name string) machine.Client {
+		return clients[name]
+	}, &mockLogger{}, &mockTelemetry{})
+}
+
+func TestInstancesMux(t *testing.T) {
+	ts := httptest.NewServer(NewInstancesMux(newInstanceHandlers()))
+	defer ts.Close()
+
+	ready, _, err := apiClient.New(http.DefaultClient, ts.URL).Ready()
+	assert.NoError(t, err)
+	assert.True(t, ready)
+
+	ready, reason, err := apiClient.New(http.DefaultClient, ts.URL+"/instances/other").Ready()
+	assert.NoError(t, err)
+	assert.False(t, ready)
+	assert.Equal(t, "broken", reason)
+
+	res, err := http.Get(ts.URL + "/instances/Not_Valid/status")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestInstancesGRPCServer(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := NewInstancesGRPCServer(newInstanceHandlers())
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	defer server.Stop()
+
+	conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer conn.Close()
+	client := daemonpb.NewDaemonClient(conn)
+
+	res, err := client.Status(gocontext.Background(), &emptypb.Empty{})
+	require.NoError(t, err)
+	assert.Equal(t, "Running", res.CrcStatus)
+
+	ctx := metadata.AppendToOutgoingContext(gocontext.Background(), ProfileMetadataKey, "other")
+	_, err = client.Status(ctx, &emptypb.Empty{})
+	assert.EqualError(t, err, "rpc error: code = Unknown desc = broken")
+}
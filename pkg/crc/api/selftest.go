@@ -0,0 +1,79 @@
+package api
+
+import (
+	"sync"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/api/client"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine"
+)
+
+// networkSelfTest runs the network self-test of the cluster in the
+// background and keeps the result of the last run, the pod needs minutes to
+// pull its image and run its checks
+type networkSelfTest struct {
+	mu      sync.Mutex
+	running bool
+	// result of the last run which ended, nil until then
+	result *client.NetworkSelfTestResult
+}
+
+// start runs the self-test with machine unless it is already running
+func (t *networkSelfTest) start(machine machine.Client) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.running {
+		return
+	}
+	t.running = true
+	go func() {
+		result := runNetworkSelfTest(machine)
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.running = false
+		t.result = result
+	}()
+}
+
+func runNetworkSelfTest(machine machine.Client) *client.NetworkSelfTestResult {
+	res, err := machine.NetworkSelfTest()
+	finished := time.Now()
+	if err != nil {
+		logging.Warnf("Network self-test failed: %v", err)
+		return &client.NetworkSelfTestResult{
+			Success:  true,
+			Finished: &finished,
+			Failure:  err.Error(),
+		}
+	}
+	return &client.NetworkSelfTestResult{
+		Success:  true,
+		Finished: &finished,
+		Healthy:  res.Healthy,
+		Checks:   res.Checks,
+	}
+}
+
+// status returns the result of the last run and whether a run is ongoing
+func (t *networkSelfTest) status() client.NetworkSelfTestResult {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	status := client.NetworkSelfTestResult{Success: true}
+	if t.result != nil {
+		status = *t.result
+	}
+	status.Running = t.running
+	return status
+}
+
+// last returns the result of the last run, nil when no run ended
+func (t *networkSelfTest) last() *client.NetworkSelfTestResult {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.result == nil {
+		return nil
+	}
+	result := *t.result
+	return &result
+}
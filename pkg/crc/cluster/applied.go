@@ -0,0 +1,33 @@
+package cluster
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/pkg/errors"
+)
+
+// appliedStateDir holds the configurations crc applied to the cluster, it is
+// in the VM so that it goes away with the cluster
+const appliedStateDir = "/var/lib/crc"
+
+// applyIfChanged runs apply when state differs from the one recorded at its
+// previous success for name, so that a start which changes nothing does not
+// wait for the cluster API. An empty state is the same as no record.
+func applyIfChanged(sshRunner *ssh.Runner, name string, state []byte, apply func() error) error {
+	statePath := path.Join(appliedStateDir, name+".json")
+	applied, err := sshRunner.ReadRemoteFile(statePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("Failed to read %s: %v", statePath, err)
+	}
+	if string(applied) == string(state) {
+		return nil
+	}
+	if err := apply(); err != nil {
+		return err
+	}
+	_, err = deployFile(sshRunner, statePath, string(state))
+	return err
+}
@@ -0,0 +1,145 @@
+package cluster
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+)
+
+const (
+	KubeAPIServerAudit      = "kube-apiserver"
+	OpenShiftAPIServerAudit = "openshift-apiserver"
+	OAuthAPIServerAudit     = "oauth-apiserver"
+)
+
+type AuditLogOptions struct {
+	// Sources of the logs, defaults to all the API servers
+	Sources []string
+	// Only return events received in [Since, Until), zero values are not taken into account
+	Since time.Time
+	Until time.Time
+	// Only return the last Lines events of each source, 0 returns them all
+	Lines int
+}
+
+type AuditUser struct {
+	Username string   `json:"username"`
+	Groups   []string `json:"groups"`
+}
+
+type AuditObjectRef struct {
+	Resource  string `json:"resource"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+type AuditResponseStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Reason  string `json:"reason"`
+}
+
+// AuditEvent holds the fields of an API server audit event which are useful
+// when debugging RBAC and admission issues
+type AuditEvent struct {
+	Source                   string               `json:"source"`
+	AuditID                  string               `json:"auditID"`
+	Stage                    string               `json:"stage"`
+	Verb                     string               `json:"verb"`
+	RequestURI               string               `json:"requestURI"`
+	User                     AuditUser            `json:"user"`
+	ObjectRef                *AuditObjectRef      `json:"objectRef,omitempty"`
+	ResponseStatus           *AuditResponseStatus `json:"responseStatus,omitempty"`
+	Annotations              map[string]string    `json:"annotations,omitempty"`
+	RequestReceivedTimestamp time.Time            `json:"requestReceivedTimestamp"`
+}
+
+// GetAuditLogs reads the audit logs of the API servers running in the VM
+func GetAuditLogs(sshRunner *ssh.Runner, options AuditLogOptions) ([]AuditEvent, error) {
+	sources := options.Sources
+	if len(sources) == 0 {
+		sources = []string{KubeAPIServerAudit, OpenShiftAPIServerAudit, OAuthAPIServerAudit}
+	}
+	events := []AuditEvent{}
+	for _, source := range sources {
+		switch source {
+		case KubeAPIServerAudit, OpenShiftAPIServerAudit, OAuthAPIServerAudit:
+		default:
+			return nil, fmt.Errorf("unknown audit log source '%s'", source)
+		}
+		command := auditLogCommand(sshRunner.PrivilegedCommand, fmt.Sprintf("/var/log/%s", source), options)
+		// audit events may contain secrets
+		stdout, stderr, err := sshRunner.RunPrivate(command)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to read %s audit log %v: %s", source, err, stderr)
+		}
+		events = append(events, parseAuditLog(source, stdout, options.Since, options.Until)...)
+	}
+	return events, nil
+}
+
+// auditLogCommand returns the command printing the audit events of the logs
+// in dir matching options, from the oldest rotated log to the current one.
+// The logs can take hundreds of MB, the events are filtered in the VM.
+func auditLogCommand(privileged func(command string) string, dir string, options AuditLogOptions) string {
+	// the rotated logs are named after their rotation time, e.g.
+	// audit-2021-06-01T10-00-00.000.log, they sort before audit.log
+	command := fmt.Sprintf("%s | LC_ALL=C sort | %s",
+		privileged(fmt.Sprintf("find %s -maxdepth 1 -name 'audit*.log'", dir)),
+		privileged("xargs -r cat"))
+	if !options.Since.IsZero() || !options.Until.IsZero() {
+		// the timestamps of the events have a fixed format, they are
+		// compared as strings
+		command += fmt.Sprintf(` | awk -v since=%s -v until=%s 'match($0, /"requestReceivedTimestamp":"[^"]*"/) {`+
+			` t = substr($0, RSTART + 28, RLENGTH - 29); if ((since == "" || t >= since) && (until == "" || t < until)) print }'`,
+			auditTimestamp(options.Since), auditTimestamp(options.Until))
+	}
+	if options.Lines > 0 {
+		command += fmt.Sprintf(" | tail -n %d", options.Lines)
+	}
+	return command
+}
+
+// auditTimestamp formats t as the timestamps of the audit events, zero times
+// are empty
+func auditTimestamp(t time.Time) string {
+	if t.IsZero() {
+		return `""`
+	}
+	return t.UTC().Format("2006-01-02T15:04:05.000000Z")
+}
+
+func parseAuditLog(source, content string, since, until time.Time) []AuditEvent {
+	var events []AuditEvent
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	// audit events can be larger than the default 64k token size
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		var event AuditEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			logging.Debugf("Skipping invalid %s audit event: %v", source, err)
+			continue
+		}
+		if !since.IsZero() && event.RequestReceivedTimestamp.Before(since) {
+			continue
+		}
+		if !until.IsZero() && !event.RequestReceivedTimestamp.Before(until) {
+			continue
+		}
+		event.Source = source
+		events = append(events, event)
+	}
+	if err := scanner.Err(); err != nil {
+		logging.Debugf("Stopped reading %s audit log: %v", source, err)
+	}
+	return events
+}
@@ -0,0 +1,79 @@
+package cluster
+
+import (
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const auditLog = `{"kind":"Event","apiVersion":"audit.k8s.io/v1","level":"Metadata","auditID":"a1","stage":"ResponseComplete","requestURI":"/api/v1/namespaces/default/pods","verb":"list","user":{"username":"developer","groups":["system:authenticated:oauth"]},"objectRef":{"resource":"pods","namespace":"default","apiVersion":"v1"},"responseStatus":{"metadata":{},"status":"Failure","reason":"Forbidden","code":403},"requestReceivedTimestamp":"2021-06-01T10:00:00.000000Z","annotations":{"authorization.k8s.io/decision":"forbid"}}
+not json
+{"kind":"Event","apiVersion":"audit.k8s.io/v1","level":"Metadata","auditID":"a2","stage":"ResponseComplete","requestURI":"/healthz","verb":"get","user":{"username":"system:anonymous"},"responseStatus":{"metadata":{},"code":200},"requestReceivedTimestamp":"2021-06-01T11:00:00.000000Z"}
+`
+
+func TestParseAuditLog(t *testing.T) {
+	events := parseAuditLog(KubeAPIServerAudit, auditLog, time.Time{}, time.Time{})
+	assert.Len(t, events, 2)
+	assert.Equal(t, AuditEvent{
+		Source:     KubeAPIServerAudit,
+		AuditID:    "a1",
+		Stage:      "ResponseComplete",
+		Verb:       "list",
+		RequestURI: "/api/v1/namespaces/default/pods",
+		User: AuditUser{
+			Username: "developer",
+			Groups:   []string{"system:authenticated:oauth"},
+		},
+		ObjectRef: &AuditObjectRef{
+			Resource:  "pods",
+			Namespace: "default",
+		},
+		ResponseStatus: &AuditResponseStatus{
+			Code:   403,
+			Reason: "Forbidden",
+		},
+		Annotations:              map[string]string{"authorization.k8s.io/decision": "forbid"},
+		RequestReceivedTimestamp: time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC),
+	}, events[0])
+
+	events = parseAuditLog(KubeAPIServerAudit, auditLog, time.Date(2021, 6, 1, 10, 30, 0, 0, time.UTC), time.Time{})
+	assert.Len(t, events, 1)
+	assert.Equal(t, "a2", events[0].AuditID)
+
+	events = parseAuditLog(KubeAPIServerAudit, auditLog, time.Time{}, time.Date(2021, 6, 1, 11, 0, 0, 0, time.UTC))
+	assert.Len(t, events, 1)
+	assert.Equal(t, "a1", events[0].AuditID)
+}
+
+func TestAuditLogCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the command runs in the VM")
+	}
+	dir := t.TempDir()
+	rotated := `{"auditID":"a0","requestReceivedTimestamp":"2021-06-01T09:00:00.000000Z"}` + "\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "audit-2021-06-01T09-30-00.000.log"), []byte(rotated), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "audit.log"), []byte(auditLog), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "other.log"), []byte("other\n"), 0600))
+
+	run := func(options AuditLogOptions) []string {
+		out, err := exec.Command("sh", "-c", auditLogCommand(func(command string) string {
+			return command
+		}, dir, options)).Output()
+		require.NoError(t, err)
+		var ids []string
+		for _, event := range parseAuditLog(KubeAPIServerAudit, string(out), time.Time{}, time.Time{}) {
+			ids = append(ids, event.AuditID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"a0", "a1", "a2"}, run(AuditLogOptions{}))
+	assert.Equal(t, []string{"a1", "a2"}, run(AuditLogOptions{Since: time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)}))
+	assert.Equal(t, []string{"a0", "a1"}, run(AuditLogOptions{Until: time.Date(2021, 6, 1, 11, 0, 0, 0, time.UTC)}))
+	assert.Equal(t, []string{"a2"}, run(AuditLogOptions{Lines: 1}))
+}
@@ -12,7 +12,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/errors"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/network"
@@ -21,6 +20,7 @@ import (
 	crctls "github.com/code-ready/crc/pkg/crc/tls"
 	"github.com/code-ready/crc/pkg/crc/validation"
 	"github.com/pborman/uuid"
+	"k8s.io/client-go/tools/clientcmd"
 )
 
 // #nosec G101
@@ -33,32 +33,78 @@ const (
 	AggregatorClientCert = "/etc/kubernetes/static-pod-resources/kube-apiserver-certs/configmaps/aggregator-client-ca/ca-bundle.crt"
 )
 
-func CheckCertsValidity(sshRunner *ssh.Runner) (map[string]bool, error) {
-	statuses := make(map[string]bool)
+// GetCertsExpiry returns the expiry dates of the certificates which may need to be renewed on start
+func GetCertsExpiry(sshRunner *ssh.Runner) (map[string]time.Time, error) {
+	expiries := make(map[string]time.Time)
 	for _, cert := range []string{KubeletClientCert, KubeletServerCert, AggregatorClientCert} {
-		expired, err := checkCertValidity(sshRunner, cert)
+		expiryDate, err := getCertExpiry(sshRunner, cert)
 		if err != nil {
 			return nil, err
 		}
-		statuses[cert] = expired
+		expiries[cert] = expiryDate
+	}
+	return expiries, nil
+}
+
+// CheckCertsValidity tells for each certificate in certsExpiry if it has
+// expired at now, the time in the VM
+func CheckCertsValidity(certsExpiry map[string]time.Time, now time.Time) map[string]bool {
+	statuses := make(map[string]bool)
+	for cert, expiryDate := range certsExpiry {
+		if now.After(expiryDate) {
+			logging.Debugf("Certs have expired, they were valid till: %s", expiryDate.Format(time.RFC822))
+			statuses[cert] = true
+		} else {
+			statuses[cert] = false
+		}
+	}
+	return statuses
+}
+
+// CheckCertsNotBefore fails when a certificate is not valid yet at now, the
+// time in the VM. The certificates renewed while the clock of the VM was
+// shifted are only valid from the shifted time.
+func CheckCertsNotBefore(sshRunner *ssh.Runner, now time.Time) error {
+	certsNotBefore := make(map[string]time.Time)
+	for _, cert := range []string{KubeletClientCert, KubeletServerCert, AggregatorClientCert} {
+		notBefore, err := getCertDate(sshRunner, cert, "startdate")
+		if err != nil {
+			return err
+		}
+		certsNotBefore[cert] = notBefore
 	}
-	return statuses, nil
+	return checkCertsNotBefore(certsNotBefore, now)
+}
+
+func checkCertsNotBefore(certsNotBefore map[string]time.Time, now time.Time) error {
+	for cert, notBefore := range certsNotBefore {
+		if now.Before(notBefore) {
+			return fmt.Errorf("%s is not valid before %s", cert, notBefore.Format(time.RFC822))
+		}
+	}
+	return nil
 }
 
 func checkCertValidity(sshRunner *ssh.Runner, cert string) (bool, error) {
-	output, _, err := sshRunner.Run(fmt.Sprintf(`date --date="$(sudo openssl x509 -in %s -noout -enddate | cut -d= -f 2)" --iso-8601=seconds`, cert))
+	expiryDate, err := getCertExpiry(sshRunner, cert)
 	if err != nil {
 		return false, err
 	}
-	expiryDate, err := time.Parse(time.RFC3339, strings.TrimSpace(output))
+	return CheckCertsValidity(map[string]time.Time{cert: expiryDate}, time.Now())[cert], nil
+}
+
+func getCertExpiry(sshRunner *ssh.Runner, cert string) (time.Time, error) {
+	return getCertDate(sshRunner, cert, "enddate")
+}
+
+// getCertDate returns the start or the end of the validity of cert, field is
+// startdate or enddate
+func getCertDate(sshRunner *ssh.Runner, cert, field string) (time.Time, error) {
+	output, _, err := sshRunner.Run(fmt.Sprintf(`date --date="$(%s | cut -d= -f 2)" --iso-8601=seconds`, sshRunner.PrivilegedCommand(fmt.Sprintf("openssl x509 -in %s -noout -%s", cert, field))))
 	if err != nil {
-		return false, err
-	}
-	if time.Now().After(expiryDate) {
-		logging.Debugf("Certs have expired, they were valid till: %s", expiryDate.Format(time.RFC822))
-		return true, nil
+		return time.Time{}, err
 	}
-	return false, nil
+	return time.Parse(time.RFC3339, strings.TrimSpace(output))
 }
 
 // Return size of disk, used space in bytes and the mountpoint
@@ -143,7 +189,7 @@ func EnsurePullSecretPresentInTheCluster(ctx context.Context, ocConfig oc.Config
 	return nil
 }
 
-func EnsureGeneratedClientCAPresentInTheCluster(ctx context.Context, ocConfig oc.Config, sshRunner *ssh.Runner, selfSignedCACert *x509.Certificate, adminCert string) error {
+func EnsureGeneratedClientCAPresentInTheCluster(ctx context.Context, ocConfig oc.Config, sshRunner *ssh.Runner, selfSignedCACert *x509.Certificate, adminCert string, kubeconfigPath string) error {
 	selfSignedCAPem := crctls.CertToPem(selfSignedCACert)
 	if err := WaitForOpenshiftResource(ctx, ocConfig, "configmaps"); err != nil {
 		return err
@@ -169,7 +215,14 @@ func EnsureGeneratedClientCAPresentInTheCluster(ctx context.Context, ocConfig oc
 	if err != nil {
 		return fmt.Errorf("Failed to patch admin-kubeconfig-client-ca config map with new CA` %v: %s", err, stderr)
 	}
-	if err := sshRunner.CopyFile(constants.KubeconfigFilePath, ocConfig.KubeconfigPath, 0644); err != nil {
+	kubeconfig, err := ioutil.ReadFile(kubeconfigPath)
+	if err != nil {
+		return fmt.Errorf("Failed to read generated kubeconfig file: %v", err)
+	}
+	if _, err := clientcmd.Load(kubeconfig); err != nil {
+		return fmt.Errorf("Invalid generated kubeconfig file %s: %v", kubeconfigPath, err)
+	}
+	if err := sshRunner.InstallData(kubeconfig, ocConfig.KubeconfigPath, 0600); err != nil {
 		return fmt.Errorf("Failed to copy generated kubeconfig file to VM: %v", err)
 	}
 
@@ -192,11 +245,11 @@ func RemovePullSecretFromCluster(ctx context.Context, ocConfig oc.Config, sshRun
 func waitForPullSecretRemovedFromInstanceDisk(ctx context.Context, sshRunner *ssh.Runner) error {
 	logging.Info("Waiting for user's pull secret removed from instance disk...")
 	pullSecretPresentFunc := func() error {
-		stdout, stderr, err := sshRunner.RunPrivate(fmt.Sprintf("sudo cat %s", vmPullSecretPath))
+		content, err := sshRunner.ReadRemoteFile(vmPullSecretPath)
 		if err != nil {
-			return &errors.RetriableError{Err: fmt.Errorf("failed to read %s file: %v: %s", vmPullSecretPath, err, stderr)}
+			return &errors.RetriableError{Err: fmt.Errorf("failed to read %s file: %v", vmPullSecretPath, err)}
 		}
-		if err := validation.ImagePullSecret(stdout); err == nil {
+		if err := validation.ImagePullSecret(string(content)); err == nil {
 			return &errors.RetriableError{Err: fmt.Errorf("pull secret still part of instance disk")}
 		}
 		return nil
@@ -249,30 +302,39 @@ func RemoveOldRenderedMachineConfig(ocConfig oc.Config) error {
 	return nil
 }
 
-func EnsureClusterIDIsNotEmpty(ctx context.Context, ocConfig oc.Config) error {
+// EnsureClusterID sets the ID of the cluster to clusterID. When clusterID is
+// empty, the current ID is kept, or a new one is generated if there is none.
+// It returns the ID of the cluster.
+func EnsureClusterID(ctx context.Context, ocConfig oc.Config, clusterID string) (string, error) {
 	if err := WaitForOpenshiftResource(ctx, ocConfig, "clusterversion"); err != nil {
-		return err
+		return "", err
 	}
 
 	stdout, stderr, err := ocConfig.RunOcCommand("get", "clusterversion", "version", "-o", `jsonpath="{['spec']['clusterID']}"`)
 	if err != nil {
-		return fmt.Errorf("Failed to get clusterversion %v: %s", err, stderr)
+		return "", fmt.Errorf("Failed to get clusterversion %v: %s", err, stderr)
 	}
-	if strings.TrimSpace(stdout) != "" {
-		return nil
+	currentClusterID := strings.Trim(strings.TrimSpace(stdout), `"`)
+	if clusterID == "" {
+		if currentClusterID != "" {
+			return currentClusterID, nil
+		}
+		clusterID = uuid.New()
+	}
+	if clusterID == currentClusterID {
+		return clusterID, nil
 	}
 
 	logging.Info("Updating cluster ID...")
-	clusterID := uuid.New()
 	cmdArgs := []string{"patch", "clusterversion", "version", "-p",
 		fmt.Sprintf(`'{"spec":{"clusterID":"%s"}}'`, clusterID), "--type", "merge"}
 
 	_, stderr, err = ocConfig.RunOcCommand(cmdArgs...)
 	if err != nil {
-		return fmt.Errorf("Failed to update cluster ID %v: %s", err, stderr)
+		return "", fmt.Errorf("Failed to update cluster ID %v: %s", err, stderr)
 	}
 
-	return nil
+	return clusterID, nil
 }
 
 func AddProxyConfigToCluster(ctx context.Context, sshRunner *ssh.Runner, ocConfig oc.Config, proxy *network.ProxyConfig) error {
@@ -316,7 +378,7 @@ func AddProxyConfigToCluster(ctx context.Context, sshRunner *ssh.Runner, ocConfi
 	if err != nil {
 		return fmt.Errorf("Failed to encode to json: %v", err)
 	}
-	logging.Debugf("Patch string %s", string(patchEncode))
+	logging.Debugf("Proxy configuration of the cluster: %s", proxy.String())
 
 	cmdArgs := []string{"patch", "proxy", "cluster", "-p", fmt.Sprintf("'%s'", string(patchEncode)), "-n", "openshift-config", "--type", "merge"}
 	if _, stderr, err := ocConfig.RunOcCommandPrivate(cmdArgs...); err != nil {
@@ -384,11 +446,11 @@ func EnsurePullSecretPresentOnInstanceDisk(sshRunner *ssh.Runner, pullSecret Pul
 func WaitForPullSecretPresentOnInstanceDisk(ctx context.Context, sshRunner *ssh.Runner) error {
 	logging.Info("Waiting for user's pull secret part of instance disk...")
 	pullSecretPresentFunc := func() error {
-		stdout, stderr, err := sshRunner.RunPrivate(fmt.Sprintf("sudo cat %s", vmPullSecretPath))
+		content, err := sshRunner.ReadRemoteFile(vmPullSecretPath)
 		if err != nil {
-			return fmt.Errorf("failed to read %s file: %v: %s", vmPullSecretPath, err, stderr)
+			return fmt.Errorf("failed to read %s file: %v", vmPullSecretPath, err)
 		}
-		if err := validation.ImagePullSecret(stdout); err != nil {
+		if err := validation.ImagePullSecret(string(content)); err != nil {
 			return &errors.RetriableError{Err: fmt.Errorf("pull secret not updated to disk")}
 		}
 		return nil
@@ -464,3 +526,30 @@ func DeleteMCOLeaderLease(ctx context.Context, ocConfig oc.Config) error {
 	_, _, err := ocConfig.RunOcCommand("delete", "-n", "openshift-machine-config-operator", "cm", "machine-config-controller")
 	return err
 }
+
+// GetConsoleURL returns the URL of the web console as exposed by its route in the cluster
+func GetConsoleURL(ocConfig oc.Config) (string, error) {
+	stdout, stderr, err := ocConfig.RunOcCommand("get", "route", "console", "-n", "openshift-console", "-o", `jsonpath="{.spec.host}"`)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get the console route %v: %s", err, stderr)
+	}
+	host := strings.Trim(strings.TrimSpace(stdout), `"`)
+	if host == "" {
+		return "", fmt.Errorf("console route has no host")
+	}
+	return fmt.Sprintf("https://%s", host), nil
+}
+
+// GetAPIServerURL returns the URL of the API server as reported by the
+// infrastructure resource of the cluster
+func GetAPIServerURL(ocConfig oc.Config) (string, error) {
+	stdout, stderr, err := ocConfig.RunOcCommand("get", "infrastructure", "cluster", "-o", `jsonpath="{.status.apiServerURL}"`)
+	if err != nil {
+		return "", fmt.Errorf("Failed to get the API server URL %v: %s", err, stderr)
+	}
+	apiServerURL := strings.Trim(strings.TrimSpace(stdout), `"`)
+	if apiServerURL == "" {
+		return "", fmt.Errorf("infrastructure has no API server URL")
+	}
+	return apiServerURL, nil
+}
@@ -0,0 +1,20 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCertsNotBefore(t *testing.T) {
+	now := time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)
+	certsNotBefore := map[string]time.Time{
+		KubeletClientCert: now.Add(-24 * time.Hour),
+		KubeletServerCert: now.Add(-time.Hour),
+	}
+	assert.NoError(t, checkCertsNotBefore(certsNotBefore, now))
+
+	certsNotBefore[KubeletServerCert] = now.Add(744 * time.Hour)
+	assert.EqualError(t, checkCertsNotBefore(certsNotBefore, now), KubeletServerCert+" is not valid before 02 Jul 21 00:00 UTC")
+}
@@ -27,11 +27,22 @@ type Status struct {
 	Progressing bool
 	Disabled    bool
 
+	// conditions of each of the operators, in the order they are listed
+	Operators []OperatorStatus
+
 	progressing []string
 	degraded    []string
 	unavailable []string
 }
 
+// OperatorStatus holds the conditions of a cluster operator
+type OperatorStatus struct {
+	Name        string
+	Available   bool
+	Degraded    bool
+	Progressing bool
+}
+
 const maxNames = 5
 
 func (status *Status) String() string {
@@ -79,45 +90,145 @@ func (status *Status) IsReady() bool {
 	return status.Available && !status.Progressing && !status.Degraded && !status.Disabled
 }
 
-func GetClusterOperatorsStatus(ctx context.Context, ip string, kubeconfigFilePath string) (*Status, error) {
+// notReadyOperators describes the operators of selector, or all of them when
+// it is empty, which are not ready, with the reason of their condition
+func notReadyOperators(operators []openshiftapi.ClusterOperator, selector []string) []string {
+	var notReady []string
+	seen := map[string]bool{}
+	for _, operator := range operators {
+		if len(selector) > 0 && !contains(operator.Name, selector) {
+			continue
+		}
+		seen[operator.Name] = true
+		for _, condition := range operator.Status.Conditions {
+			var state string
+			switch {
+			case condition.Type == openshiftapi.OperatorAvailable && condition.Status != openshiftapi.ConditionTrue:
+				state = "not available"
+			case condition.Type == openshiftapi.OperatorDegraded && condition.Status == openshiftapi.ConditionTrue:
+				state = "degraded"
+			case condition.Type == openshiftapi.OperatorProgressing && condition.Status == openshiftapi.ConditionTrue:
+				state = "progressing"
+			default:
+				continue
+			}
+			if condition.Reason != "" {
+				state = fmt.Sprintf("%s (%s)", state, condition.Reason)
+			}
+			notReady = append(notReady, fmt.Sprintf("%s is %s", operator.Name, state))
+			break
+		}
+	}
+	for _, name := range selector {
+		if !seen[name] {
+			notReady = append(notReady, fmt.Sprintf("%s is not created", name))
+		}
+	}
+	sort.Strings(notReady)
+	return notReady
+}
+
+// maximum length of the condition messages in the progress summaries
+const maxProgressMessageLength = 120
+
+// operatorsProgress describes the operators of selector, or all of them when
+// it is empty, which are progressing or degraded, with the message of their
+// condition
+func operatorsProgress(operators []openshiftapi.ClusterOperator, selector []string) []string {
+	var progress []string
+	for _, operator := range operators {
+		if len(selector) > 0 && !contains(operator.Name, selector) {
+			continue
+		}
+		for _, condition := range operator.Status.Conditions {
+			var state string
+			switch {
+			case condition.Type == openshiftapi.OperatorDegraded && condition.Status == openshiftapi.ConditionTrue:
+				state = "degraded"
+			case condition.Type == openshiftapi.OperatorProgressing && condition.Status == openshiftapi.ConditionTrue:
+				state = "progressing"
+			default:
+				continue
+			}
+			message := conditionMessage(condition)
+			if message == "" {
+				progress = append(progress, fmt.Sprintf("%s is %s", operator.Name, state))
+			} else {
+				progress = append(progress, fmt.Sprintf("%s is %s: %s", operator.Name, state, message))
+			}
+			break
+		}
+	}
+	sort.Strings(progress)
+	return progress
+}
+
+// conditionMessage returns the first line of the message of condition,
+// shortened to maxProgressMessageLength, or its reason when it has no message
+func conditionMessage(condition openshiftapi.ClusterOperatorStatusCondition) string {
+	message := strings.TrimSpace(condition.Message)
+	if message == "" {
+		return condition.Reason
+	}
+	if i := strings.IndexByte(message, '\n'); i >= 0 {
+		message = message[:i]
+	}
+	if len(message) > maxProgressMessageLength {
+		message = message[:maxProgressMessageLength] + "..."
+	}
+	return message
+}
+
+// GetClusterOperatorsStatus returns the aggregated status of the cluster
+// operators, only taking into account the ones in selector if it is not empty
+func GetClusterOperatorsStatus(ctx context.Context, ip string, kubeconfigFilePath string, selector ...string) (*Status, error) {
 	lister, err := kubernetesClient(ip, kubeconfigFilePath)
 	if err != nil {
 		return nil, err
 	}
-	return getStatus(ctx, lister.ConfigV1().ClusterOperators(), []string{})
+	return getStatus(ctx, lister.ConfigV1().ClusterOperators(), selector)
 }
 
 func getStatus(ctx context.Context, lister operatorLister, selector []string) (*Status, error) {
-	cs := &Status{
-		Available: true,
-	}
-
 	co, err := lister.List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, err
 	}
+	return aggregateStatus(co.Items, selector)
+}
+
+func aggregateStatus(operators []openshiftapi.ClusterOperator, selector []string) (*Status, error) {
+	cs := &Status{
+		Available: true,
+	}
 
 	found := false
-	for _, c := range co.Items {
+	seen := map[string]bool{}
+	for _, c := range operators {
 		if len(selector) > 0 && !contains(c.ObjectMeta.Name, selector) {
 			continue
 		}
 		found = true
+		seen[c.ObjectMeta.Name] = true
+		operator := OperatorStatus{Name: c.ObjectMeta.Name, Available: true}
 		for _, con := range c.Status.Conditions {
 			switch con.Type {
 			case openshiftapi.OperatorAvailable:
+				operator.Available = con.Status == openshiftapi.ConditionTrue
 				if con.Status != openshiftapi.ConditionTrue {
 					logging.Debug(c.ObjectMeta.Name, " operator not available, Reason: ", con.Reason)
 					cs.unavailable = append(cs.unavailable, c.ObjectMeta.Name)
 					cs.Available = false
 				}
 			case openshiftapi.OperatorDegraded:
+				operator.Degraded = con.Status == openshiftapi.ConditionTrue
 				if con.Status == openshiftapi.ConditionTrue {
 					logging.Debug(c.ObjectMeta.Name, " operator is degraded, Reason: ", con.Reason)
 					cs.degraded = append(cs.degraded, c.ObjectMeta.Name)
 					cs.Degraded = true
 				}
 			case openshiftapi.OperatorProgressing:
+				operator.Progressing = con.Status == openshiftapi.ConditionTrue
 				if con.Status == openshiftapi.ConditionTrue {
 					logging.Debug(c.ObjectMeta.Name, " operator is still progressing, Reason: ", con.Reason)
 					cs.progressing = append(cs.progressing, c.ObjectMeta.Name)
@@ -136,10 +247,20 @@ func getStatus(ctx context.Context, lister operatorLister, selector []string) (*
 				logging.Debugf("Unexpected operator status for %s: %s", c.ObjectMeta.Name, con.Type)
 			}
 		}
+		cs.Operators = append(cs.Operators, operator)
 	}
 	if !found {
 		return nil, errors.New("no cluster operator found")
 	}
+	// selected operators which are not created yet are not available
+	for _, name := range selector {
+		if !seen[name] {
+			logging.Debug(name, " operator not found")
+			cs.Operators = append(cs.Operators, OperatorStatus{Name: name})
+			cs.unavailable = append(cs.unavailable, name)
+			cs.Available = false
+		}
+	}
 	return cs, nil
 }
 
@@ -15,10 +15,20 @@ import (
 var (
 	available = &Status{
 		Available: true,
+		Operators: []OperatorStatus{
+			{Name: "authentication", Available: true},
+			{Name: "cloud-credential", Available: true},
+			{Name: "cluster-autoscaler", Available: true},
+		},
 	}
 	progressing = &Status{
 		Available:   true,
 		Progressing: true,
+		Operators: []OperatorStatus{
+			{Name: "authentication", Available: true, Progressing: true},
+			{Name: "cloud-credential", Available: true},
+			{Name: "cluster-autoscaler", Available: true},
+		},
 		progressing: []string{"authentication"},
 	}
 )
@@ -35,6 +45,21 @@ func TestGetClusterOperatorsStatusProgressing(t *testing.T) {
 	assert.Equal(t, progressing, status)
 }
 
+func TestGetClusterOperatorsStatusWithSelector(t *testing.T) {
+	status, err := getStatus(context.Background(), lister("co-progressing.json"), []string{"cloud-credential"})
+	assert.NoError(t, err)
+	assert.Equal(t, &Status{
+		Available: true,
+		Operators: []OperatorStatus{{Name: "cloud-credential", Available: true}},
+	}, status)
+
+	status, err = getStatus(context.Background(), lister("co-progressing.json"), []string{"cloud-credential", "kube-apiserver"})
+	assert.NoError(t, err)
+	assert.False(t, status.Available)
+	assert.Equal(t, []OperatorStatus{{Name: "cloud-credential", Available: true}, {Name: "kube-apiserver"}}, status.Operators)
+	assert.Equal(t, "Operator kube-apiserver is not yet available", status.String())
+}
+
 type mockLister struct {
 	file string
 }
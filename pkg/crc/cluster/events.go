@@ -0,0 +1,112 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/timeline"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const eventsPollingInterval = 20 * time.Second
+
+// abnormalEventReasons are the reasons of the warning events which explain
+// why the cluster is slow to become ready
+var abnormalEventReasons = map[string]bool{
+	"FailedScheduling":   true,
+	"FailedMount":        true,
+	"FailedCreate":       true,
+	"Failed":             true,
+	"BackOff":            true,
+	"ErrImagePull":       true,
+	"ImagePullBackOff":   true,
+	"InspectFailed":      true,
+	"NodeNotReady":       true,
+	"FailedAttachVolume": true,
+}
+
+func getWarningEvents(ocConfig oc.Config) (*corev1.EventList, error) {
+	stdout, stderr, err := ocConfig.WithFailFast().RunOcCommand("get", "events", "--all-namespaces", "--field-selector", "type=Warning", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get events %v: %s", err, stderr)
+	}
+	var events corev1.EventList
+	if err := json.Unmarshal([]byte(stdout), &events); err != nil {
+		return nil, err
+	}
+	return &events, nil
+}
+
+func isAbnormalEvent(event *corev1.Event) bool {
+	if abnormalEventReasons[event.Reason] {
+		return true
+	}
+	message := strings.ToLower(event.Message)
+	return strings.Contains(message, "x509") || strings.Contains(message, "certificate")
+}
+
+func eventTime(event *corev1.Event) time.Time {
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.FirstTimestamp.Time
+}
+
+type eventMonitor struct {
+	since time.Time
+	// number of occurrences of the events already reported, by event uid
+	reported map[string]int32
+}
+
+// check returns the messages for the abnormal events which occurred since the
+// monitor was started and were not reported yet
+func (monitor *eventMonitor) check(events *corev1.EventList) []string {
+	var messages []string
+	for i := range events.Items {
+		event := &events.Items[i]
+		if !isAbnormalEvent(event) || eventTime(event).Before(monitor.since) {
+			continue
+		}
+		if count, ok := monitor.reported[string(event.UID)]; ok && count >= event.Count {
+			continue
+		}
+		monitor.reported[string(event.UID)] = event.Count
+		messages = append(messages, fmt.Sprintf("%s/%s %s: %s: %s",
+			event.InvolvedObject.Namespace, strings.ToLower(event.InvolvedObject.Kind), event.InvolvedObject.Name,
+			event.Reason, strings.TrimSpace(event.Message)))
+	}
+	return messages
+}
+
+// MonitorEvents periodically reports the abnormal warning events of the
+// cluster, such as image pull or scheduling failures, until ctx is cancelled
+func MonitorEvents(ctx context.Context, ocConfig oc.Config) {
+	monitor := &eventMonitor{
+		since:    time.Now(),
+		reported: make(map[string]int32),
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(eventsPollingInterval):
+		}
+		events, err := getWarningEvents(ocConfig)
+		if err != nil {
+			logging.Debugf("Cannot get cluster events: %v", err)
+			continue
+		}
+		for _, message := range monitor.check(events) {
+			logging.Warnf("Cluster event: %s", message)
+			timeline.FromContext(ctx).Add(timeline.Event{Kind: timeline.ClusterEventKind, Level: "warning", Message: message})
+		}
+	}
+}
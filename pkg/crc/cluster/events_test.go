@@ -0,0 +1,54 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func warningEvent(uid, reason, message string, count int32, lastTimestamp time.Time) corev1.Event {
+	return corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			UID: types.UID(uid),
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind:      "Pod",
+			Namespace: "openshift-console",
+			Name:      "console-7d8f9",
+		},
+		Reason:        reason,
+		Message:       message,
+		Type:          corev1.EventTypeWarning,
+		Count:         count,
+		LastTimestamp: metav1.NewTime(lastTimestamp),
+	}
+}
+
+func TestEventMonitor(t *testing.T) {
+	start := time.Now()
+	monitor := &eventMonitor{
+		since:    start,
+		reported: make(map[string]int32),
+	}
+
+	events := &corev1.EventList{
+		Items: []corev1.Event{
+			warningEvent("1", "FailedScheduling", "0/1 nodes are available: 1 Insufficient memory.", 1, start.Add(time.Minute)),
+			warningEvent("2", "FailedScheduling", "0/1 nodes are available: 1 node(s) were unschedulable.", 1, start.Add(-time.Minute)),
+			warningEvent("3", "Unhealthy", "Readiness probe failed: x509: certificate has expired or is not yet valid", 1, start.Add(time.Minute)),
+			warningEvent("4", "Unhealthy", "Readiness probe failed: HTTP probe failed with statuscode: 500", 1, start.Add(time.Minute)),
+		},
+	}
+	assert.Equal(t, []string{
+		"openshift-console/pod console-7d8f9: FailedScheduling: 0/1 nodes are available: 1 Insufficient memory.",
+		"openshift-console/pod console-7d8f9: Unhealthy: Readiness probe failed: x509: certificate has expired or is not yet valid",
+	}, monitor.check(events))
+	// already reported events are only reported again when they occur again
+	assert.Empty(t, monitor.check(events))
+	events.Items[0].Count = 2
+	assert.Len(t, monitor.check(events), 1)
+}
@@ -0,0 +1,52 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/code-ready/crc/pkg/crc/systemd"
+	"github.com/code-ready/crc/pkg/crc/systemd/states"
+	"github.com/pkg/errors"
+)
+
+const kubeletService = "kubelet"
+
+// Hibernate stops the kubelet and the containers it runs, including the static
+// pods of the control plane, so that the cluster uses almost no CPU. The VM
+// keeps running: the services which are not managed by the kubelet, such as
+// sshd and dnsmasq, stay available.
+func Hibernate(sshRunner *ssh.Runner) error {
+	logging.Info("Stopping kubelet and all containers...")
+	if err := systemd.NewInstanceSystemdCommander(sshRunner).Stop(kubeletService); err != nil {
+		return errors.Wrap(err, "Failed to stop kubelet")
+	}
+	// xargs -r does not run crictl when there is no container left
+	_, stderr, err := sshRunner.RunPrivileged("Stopping all containers", `-- sh -c 'crictl ps -q | xargs -r crictl stop'`)
+	if err != nil {
+		return fmt.Errorf("Failed to stop all containers %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// Resume starts the kubelet stopped by Hibernate and waits for the apiserver
+// to be back
+func Resume(ctx context.Context, sshRunner *ssh.Runner) error {
+	logging.Info("Starting OpenShift kubelet service")
+	if err := systemd.NewInstanceSystemdCommander(sshRunner).Start(kubeletService); err != nil {
+		return errors.Wrap(err, "Failed to start kubelet")
+	}
+	return WaitForAPIServer(ctx, oc.UseOCWithSSH(sshRunner))
+}
+
+// IsKubeletRunning tells if the kubelet of the VM is running, it is not after
+// Hibernate
+func IsKubeletRunning(sshRunner *ssh.Runner) (bool, error) {
+	status, err := systemd.NewInstanceSystemdCommander(sshRunner).Status(kubeletService)
+	if err != nil {
+		return false, err
+	}
+	return status == states.Running, nil
+}
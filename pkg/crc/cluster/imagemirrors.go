@@ -0,0 +1,77 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+)
+
+const imageMirrorsPolicyName = "crc-image-mirrors"
+
+func imageContentSourcePolicy(sources []bundle.ImageContentSource) ([]byte, error) {
+	type repositoryDigestMirrors struct {
+		Source  string   `json:"source"`
+		Mirrors []string `json:"mirrors"`
+	}
+	var mirrors []repositoryDigestMirrors
+	for _, source := range sources {
+		mirrors = append(mirrors, repositoryDigestMirrors{
+			Source:  source.Source,
+			Mirrors: source.Mirrors,
+		})
+	}
+	return json.MarshalIndent(map[string]interface{}{
+		"apiVersion": "operator.openshift.io/v1alpha1",
+		"kind":       "ImageContentSourcePolicy",
+		"metadata": map[string]string{
+			"name": imageMirrorsPolicyName,
+		},
+		"spec": map[string]interface{}{
+			"repositoryDigestMirrors": mirrors,
+		},
+	}, "", "  ")
+}
+
+// EnsureImageMirrorsInTheCluster configures the cluster to pull the images of
+// sources from their mirrors, and removes this configuration when sources is
+// empty. The cluster is only changed when sources differ from the previous start.
+func EnsureImageMirrorsInTheCluster(ctx context.Context, sshRunner *ssh.Runner, ocConfig oc.Config, sources []bundle.ImageContentSource) error {
+	var policy []byte
+	if len(sources) > 0 {
+		var err error
+		if policy, err = imageContentSourcePolicy(sources); err != nil {
+			return err
+		}
+		logging.Debugf("Image content source policy: %s", policy)
+	}
+	return applyIfChanged(sshRunner, imageMirrorsPolicyName, policy, func() error {
+		return applyImageMirrors(ctx, sshRunner, ocConfig, policy)
+	})
+}
+
+func applyImageMirrors(ctx context.Context, sshRunner *ssh.Runner, ocConfig oc.Config, policy []byte) error {
+	if err := WaitForOpenshiftResource(ctx, ocConfig, "imagecontentsourcepolicy"); err != nil {
+		return err
+	}
+	if len(policy) == 0 {
+		logging.Info("Removing image mirrors...")
+		if _, stderr, err := ocConfig.RunOcCommand("delete", "imagecontentsourcepolicy", imageMirrorsPolicyName, "--ignore-not-found"); err != nil {
+			return fmt.Errorf("Failed to remove image mirrors %v: %s", err, stderr)
+		}
+		return nil
+	}
+	logging.Info("Configuring image mirrors...")
+	policyFileName := fmt.Sprintf("/tmp/%s.json", imageMirrorsPolicyName)
+	if err := sshRunner.CopyData(policy, policyFileName, 0644); err != nil {
+		return err
+	}
+	if _, stderr, err := ocConfig.RunOcCommand("apply", "-f", policyFileName); err != nil {
+		return fmt.Errorf("Failed to add image mirrors %v: %s", err, stderr)
+	}
+	return nil
+}
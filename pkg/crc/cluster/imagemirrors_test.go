@@ -0,0 +1,34 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageContentSourcePolicy(t *testing.T) {
+	policy, err := imageContentSourcePolicy([]bundle.ImageContentSource{
+		{
+			Source:  "quay.io/openshift-release-dev/ocp-release",
+			Mirrors: []string{"registry.corp.example:5000/ocp4/release"},
+		},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+  "apiVersion": "operator.openshift.io/v1alpha1",
+  "kind": "ImageContentSourcePolicy",
+  "metadata": {
+    "name": "crc-image-mirrors"
+  },
+  "spec": {
+    "repositoryDigestMirrors": [
+      {
+        "source": "quay.io/openshift-release-dev/ocp-release",
+        "mirrors": ["registry.corp.example:5000/ocp4/release"]
+      }
+    ]
+  }
+}`, string(policy))
+}
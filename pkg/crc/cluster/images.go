@@ -0,0 +1,107 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+)
+
+const maxParallelImagePulls = 3
+
+type Image struct {
+	ID          string   `json:"id"`
+	RepoTags    []string `json:"repoTags"`
+	RepoDigests []string `json:"repoDigests"`
+	Size        int64    `json:"size"`
+}
+
+// crictl serializes the image size as a string
+type crictlImage struct {
+	ID          string   `json:"id"`
+	RepoTags    []string `json:"repoTags"`
+	RepoDigests []string `json:"repoDigests"`
+	Size        string   `json:"size"`
+}
+
+type crictlImageList struct {
+	Images []crictlImage `json:"images"`
+}
+
+// ListImages returns the container images present in the crio storage of the VM
+func ListImages(sshRunner *ssh.Runner) ([]Image, error) {
+	stdout, stderr, err := sshRunner.RunPrivileged("Listing container images", "crictl", "images", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to list container images %v: %s", err, stderr)
+	}
+	return parseCrictlImages(stdout)
+}
+
+func parseCrictlImages(output string) ([]Image, error) {
+	var list crictlImageList
+	if err := json.Unmarshal([]byte(output), &list); err != nil {
+		return nil, err
+	}
+	images := []Image{}
+	for _, img := range list.Images {
+		size, err := strconv.ParseInt(img.Size, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected size for image %s: %s", img.ID, img.Size)
+		}
+		images = append(images, Image{
+			ID:          img.ID,
+			RepoTags:    img.RepoTags,
+			RepoDigests: img.RepoDigests,
+			Size:        size,
+		})
+	}
+	return images, nil
+}
+
+// PullImages pulls the given images in the VM, with at most maxParallelImagePulls
+// pulls running at the same time
+func PullImages(ctx context.Context, sshRunner *ssh.Runner, images []string) error {
+	var (
+		wg       sync.WaitGroup
+		lock     sync.Mutex
+		done     int
+		multiErr errors.MultiError
+	)
+	sem := make(chan struct{}, maxParallelImagePulls)
+	for _, image := range images {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		case sem <- struct{}{}:
+		}
+		wg.Add(1)
+		go func(image string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			_, stderr, err := sshRunner.RunPrivileged(fmt.Sprintf("Pulling %s", image), "crictl", "pull", image)
+
+			lock.Lock()
+			defer lock.Unlock()
+			done++
+			if err != nil {
+				multiErr.Collect(fmt.Errorf("Failed to pull %s: %s", image, strings.TrimSpace(stderr)))
+				return
+			}
+			logging.Infof("Pulled image %s (%d/%d)", image, done, len(images))
+		}(image)
+	}
+	wg.Wait()
+	if len(multiErr.Errors) != 0 {
+		return multiErr
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCrictlImages(t *testing.T) {
+	images, err := parseCrictlImages(`{
+  "images": [
+    {
+      "id": "sha256:7a8b2c",
+      "repoTags": ["quay.io/crcont/routes-controller:latest"],
+      "repoDigests": ["quay.io/crcont/routes-controller@sha256:9d4e"],
+      "size": "52428800",
+      "uid": null,
+      "username": ""
+    }
+  ]
+}`)
+	assert.NoError(t, err)
+	assert.Equal(t, []Image{
+		{
+			ID:          "sha256:7a8b2c",
+			RepoTags:    []string{"quay.io/crcont/routes-controller:latest"},
+			RepoDigests: []string{"quay.io/crcont/routes-controller@sha256:9d4e"},
+			Size:        52428800,
+		},
+	}, images)
+
+	_, err = parseCrictlImages(`{"images": [{"id": "sha256:7a8b2c", "size": "big"}]}`)
+	assert.EqualError(t, err, "unexpected size for image sha256:7a8b2c: big")
+}
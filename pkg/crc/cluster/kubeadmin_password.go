@@ -6,38 +6,97 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
+	"os"
 	"strings"
 
-	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/credentials/keyring"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/oc"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// GenerateKubeAdminUserPassword creates and put updated kubeadmin password to ~/.crc/machine/crc/kubeadmin-password
-func GenerateKubeAdminUserPassword() error {
+// KubeAdminPasswordStore keeps the kubeadmin password of an instance in the
+// keyring of the OS. File, such as ~/.crc/machine/crc/kubeadmin-password, is
+// only used when the keyring is not available, such as on Linux hosts without
+// a desktop session.
+type KubeAdminPasswordStore struct {
+	KeyringKey string
+	File       string
+}
+
+// Get returns the password, a password found in File is moved to the keyring
+// when it is available
+func (s KubeAdminPasswordStore) Get() (string, error) {
+	password, err := keyring.Get(s.KeyringKey)
+	if err == nil {
+		return password, nil
+	}
+	rawData, fileErr := ioutil.ReadFile(s.File)
+	if fileErr != nil {
+		if os.IsNotExist(fileErr) && !errors.Is(err, keyring.ErrNotFound) {
+			return "", fmt.Errorf("Cannot read the kubeadmin password from the keyring: %w", err)
+		}
+		return "", fileErr
+	}
+	password = strings.TrimSpace(string(rawData))
+	if errors.Is(err, keyring.ErrNotFound) {
+		if err := s.Set(password); err != nil {
+			return "", err
+		}
+	}
+	return password, nil
+}
+
+// Set stores password in the keyring and removes File, or writes it to File
+// when the keyring is not available
+func (s KubeAdminPasswordStore) Set(password string) error {
+	err := keyring.Set(s.KeyringKey, password)
+	if err == nil {
+		if err := os.Remove(s.File); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	logging.Debugf("Cannot store the kubeadmin password in the keyring, it is written to %s: %v", s.File, err)
+	return ioutil.WriteFile(s.File, []byte(password), 0600)
+}
+
+// Delete removes the password from the keyring and File
+func (s KubeAdminPasswordStore) Delete() error {
+	if err := keyring.Delete(s.KeyringKey); err != nil {
+		logging.Debugf("Cannot remove the kubeadmin password from the keyring: %v", err)
+	}
+	if err := os.Remove(s.File); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// GenerateKubeAdminUserPassword creates a new kubeadmin password in
+// passwordStore
+func GenerateKubeAdminUserPassword(passwordStore KubeAdminPasswordStore) error {
 	logging.Infof("Generating new password for the kubeadmin user")
-	kubeAdminPasswordFile := constants.GetKubeAdminPasswordPath()
 	kubeAdminPassword, err := GenerateRandomPasswordHash(23)
 	if err != nil {
 		return fmt.Errorf("Cannot generate the kubeadmin user password: %w", err)
 	}
-	return ioutil.WriteFile(kubeAdminPasswordFile, []byte(kubeAdminPassword), 0600)
+	return passwordStore.Set(kubeAdminPassword)
 }
 
 // UpdateKubeAdminUserPassword updates the htpasswd secret
-func UpdateKubeAdminUserPassword(ctx context.Context, ocConfig oc.Config, newPassword string) error {
+func UpdateKubeAdminUserPassword(ctx context.Context, ocConfig oc.Config, passwordStore KubeAdminPasswordStore, newPassword string) error {
 	if newPassword != "" {
 		logging.Infof("Overriding password for kubeadmin user")
-		if err := ioutil.WriteFile(constants.GetKubeAdminPasswordPath(), []byte(strings.TrimSpace(newPassword)), 0600); err != nil {
+		if err := passwordStore.Set(strings.TrimSpace(newPassword)); err != nil {
 			return err
 		}
 	}
 
-	kubeAdminPassword, err := GetKubeadminPassword()
+	kubeAdminPassword, err := passwordStore.Get()
 	if err != nil {
 		return fmt.Errorf("Cannot generate the kubeadmin user password: %w", err)
 	}
@@ -77,15 +136,6 @@ func UpdateKubeAdminUserPassword(ctx context.Context, ocConfig oc.Config, newPas
 	return nil
 }
 
-func GetKubeadminPassword() (string, error) {
-	kubeAdminPasswordFile := constants.GetKubeAdminPasswordPath()
-	rawData, err := ioutil.ReadFile(kubeAdminPasswordFile)
-	if err != nil {
-		return "", err
-	}
-	return strings.TrimSpace(string(rawData)), nil
-}
-
 // generateRandomPasswordHash generates a hash of a random ASCII password
 // 5char-5char-5char-5char
 // Copied from openshift/installer https://github.com/openshift/installer/blob/master/pkg/asset/password/password.go
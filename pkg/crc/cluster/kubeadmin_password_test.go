@@ -1,11 +1,50 @@
 package cluster
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/code-ready/crc/pkg/crc/credentials/keyring"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+func TestKubeAdminPasswordStore(t *testing.T) {
+	keyring.MockInit()
+
+	dir, err := ioutil.TempDir("", "kubeadmin-password")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	passwordStore := KubeAdminPasswordStore{
+		KeyringKey: "kubeadmin-password-crc",
+		File:       filepath.Join(dir, "kubeadmin-password"),
+	}
+	_, err = passwordStore.Get()
+	assert.Error(t, err)
+
+	// the password files of the older versions are moved to the keyring
+	require.NoError(t, ioutil.WriteFile(passwordStore.File, []byte("a2b4c-6d8e1-f3g5h-7i9jk\n"), 0600))
+	password, err := passwordStore.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "a2b4c-6d8e1-f3g5h-7i9jk", password)
+	assert.NoFileExists(t, passwordStore.File)
+	password, err = keyring.Get("kubeadmin-password-crc")
+	assert.NoError(t, err)
+	assert.Equal(t, "a2b4c-6d8e1-f3g5h-7i9jk", password)
+
+	assert.NoError(t, passwordStore.Set("m2n4p-6q8r1-s3t5u-7v9wx"))
+	password, err = passwordStore.Get()
+	assert.NoError(t, err)
+	assert.Equal(t, "m2n4p-6q8r1-s3t5u-7v9wx", password)
+
+	assert.NoError(t, passwordStore.Delete())
+	_, err = passwordStore.Get()
+	assert.Error(t, err)
+}
+
 func TestCompareHtpasswdWithOneUsername(t *testing.T) {
 	htpasswd, err := getHtpasswd(map[string]string{"username": "password1"}, []string{})
 	assert.NoError(t, err)
@@ -0,0 +1,57 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/code-ready/crc/pkg/crc/oc"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// LoadBalancerPort is a TCP port of a LoadBalancer service, which the cluster
+// serves on NodePort of the node
+type LoadBalancerPort struct {
+	Namespace string
+	Service   string
+	Port      int32
+	NodePort  int32
+}
+
+// GetLoadBalancerPorts returns the TCP ports of the LoadBalancer services of the cluster
+func GetLoadBalancerPorts(ocConfig oc.Config) ([]LoadBalancerPort, error) {
+	stdout, stderr, err := ocConfig.WithFailFast().RunOcCommand("get", "services", "--all-namespaces", "-o", "json")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get services %v: %s", err, stderr)
+	}
+	var services corev1.ServiceList
+	if err := json.Unmarshal([]byte(stdout), &services); err != nil {
+		return nil, err
+	}
+	return loadBalancerPorts(&services), nil
+}
+
+func loadBalancerPorts(services *corev1.ServiceList) []LoadBalancerPort {
+	var ports []LoadBalancerPort
+	for _, service := range services.Items {
+		if service.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			continue
+		}
+		for _, port := range service.Spec.Ports {
+			// the node port is only allocated once the service is accepted
+			if port.NodePort == 0 || (port.Protocol != "" && port.Protocol != corev1.ProtocolTCP) {
+				continue
+			}
+			ports = append(ports, LoadBalancerPort{
+				Namespace: service.Namespace,
+				Service:   service.Name,
+				Port:      port.Port,
+				NodePort:  port.NodePort,
+			})
+		}
+	}
+	sort.Slice(ports, func(i, j int) bool {
+		return ports[i].Port < ports[j].Port
+	})
+	return ports
+}
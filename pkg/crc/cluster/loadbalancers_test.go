@@ -0,0 +1,46 @@
+package cluster
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const servicesJSON = `{
+  "items": [
+    {
+      "metadata": {"name": "web", "namespace": "demo"},
+      "spec": {
+        "type": "LoadBalancer",
+        "ports": [
+          {"port": 8080, "protocol": "TCP", "nodePort": 31080},
+          {"port": 5353, "protocol": "UDP", "nodePort": 31053}
+        ]
+      }
+    },
+    {
+      "metadata": {"name": "db", "namespace": "demo"},
+      "spec": {"type": "ClusterIP", "ports": [{"port": 5432, "protocol": "TCP"}]}
+    },
+    {
+      "metadata": {"name": "pending", "namespace": "demo"},
+      "spec": {"type": "LoadBalancer", "ports": [{"port": 9000, "protocol": "TCP"}]}
+    },
+    {
+      "metadata": {"name": "grpc", "namespace": "other"},
+      "spec": {"type": "LoadBalancer", "ports": [{"port": 7000, "nodePort": 31700}]}
+    }
+  ]
+}`
+
+func TestLoadBalancerPorts(t *testing.T) {
+	var services corev1.ServiceList
+	require.NoError(t, json.Unmarshal([]byte(servicesJSON), &services))
+	assert.Equal(t, []LoadBalancerPort{
+		{Namespace: "other", Service: "grpc", Port: 7000, NodePort: 31700},
+		{Namespace: "demo", Service: "web", Port: 8080, NodePort: 31080},
+	}, loadBalancerPorts(&services))
+}
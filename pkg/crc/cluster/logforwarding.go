@@ -0,0 +1,276 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+)
+
+const (
+	logForwardingName      = "crc-log-forwarding"
+	logForwardingImage     = "cr.fluentbit.io/fluent/fluent-bit:1.8"
+	logForwardingConfigKey = "fluent-bit.conf"
+	// path of the handler of the crc daemon receiving the logs on the gateway
+	LogForwardingPath = "/logs"
+)
+
+// fluentBitConfiguration collects the logs of the containers and of the
+// kubelet and crio services, and sends them to target
+func fluentBitConfiguration(target crcConfig.LogForwardingTarget) string {
+	var conf strings.Builder
+	conf.WriteString(`[SERVICE]
+    Flush        5
+    Parsers_File parsers.conf
+
+[INPUT]
+    Name             tail
+    Path             /var/log/containers/*.log
+    Parser           cri
+    Tag              kube.*
+    Mem_Buf_Limit    5MB
+    Skip_Long_Lines  On
+
+[INPUT]
+    Name            systemd
+    Path            /var/log/journal
+    Systemd_Filter  _SYSTEMD_UNIT=kubelet.service
+    Systemd_Filter  _SYSTEMD_UNIT=crio.service
+    Tag             host.*
+
+[FILTER]
+    Name       kubernetes
+    Match      kube.*
+    Merge_Log  Off
+    Labels     Off
+
+[FILTER]
+    Name          nest
+    Match         kube.*
+    Operation     lift
+    Nested_under  kubernetes
+    Add_prefix    kubernetes_
+
+`)
+	if target.LokiURL != nil {
+		port := target.LokiURL.Port()
+		if port == "" {
+			port = "3100"
+			if target.LokiURL.Scheme == "https" {
+				port = "443"
+			}
+		}
+		tls := "Off"
+		if target.LokiURL.Scheme == "https" {
+			tls = "On"
+		}
+		fmt.Fprintf(&conf, `[OUTPUT]
+    Name        loki
+    Match       *
+    Host        %s
+    Port        %s
+    Tls         %s
+    Labels      job=crc
+    Label_keys  $kubernetes_namespace_name,$kubernetes_pod_name,$kubernetes_container_name,$_SYSTEMD_UNIT
+`, target.LokiURL.Hostname(), port, tls)
+		// without a path, fluent-bit uses the push endpoint of Loki
+		if uri := target.LokiURL.RequestURI(); uri != "/" {
+			fmt.Fprintf(&conf, "    Uri         %s\n", uri)
+		}
+		return conf.String()
+	}
+	fmt.Fprintf(&conf, `[OUTPUT]
+    Name    http
+    Match   *
+    Host    %s
+    Port    80
+    URI     %s
+    Format  json_lines
+`, constants.VSockGateway, LogForwardingPath)
+	return conf.String()
+}
+
+func logForwardingResources(target crcConfig.LogForwardingTarget) ([]byte, error) {
+	metadata := map[string]string{
+		"name":      logForwardingName,
+		"namespace": logForwardingName,
+	}
+	labels := map[string]string{
+		"app": logForwardingName,
+	}
+	return json.MarshalIndent(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items": []interface{}{
+			map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Namespace",
+				"metadata": map[string]string{
+					"name": logForwardingName,
+				},
+			},
+			map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ServiceAccount",
+				"metadata":   metadata,
+			},
+			map[string]interface{}{
+				"apiVersion": "rbac.authorization.k8s.io/v1",
+				"kind":       "ClusterRole",
+				"metadata": map[string]string{
+					"name": logForwardingName,
+				},
+				"rules": []interface{}{
+					map[string]interface{}{
+						"apiGroups": []string{""},
+						"resources": []string{"pods", "namespaces"},
+						"verbs":     []string{"get", "list", "watch"},
+					},
+					map[string]interface{}{
+						"apiGroups":     []string{"security.openshift.io"},
+						"resources":     []string{"securitycontextconstraints"},
+						"resourceNames": []string{"privileged"},
+						"verbs":         []string{"use"},
+					},
+				},
+			},
+			map[string]interface{}{
+				"apiVersion": "rbac.authorization.k8s.io/v1",
+				"kind":       "ClusterRoleBinding",
+				"metadata": map[string]string{
+					"name": logForwardingName,
+				},
+				"roleRef": map[string]string{
+					"apiGroup": "rbac.authorization.k8s.io",
+					"kind":     "ClusterRole",
+					"name":     logForwardingName,
+				},
+				"subjects": []interface{}{
+					map[string]string{
+						"kind":      "ServiceAccount",
+						"name":      logForwardingName,
+						"namespace": logForwardingName,
+					},
+				},
+			},
+			map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   metadata,
+				"data": map[string]string{
+					logForwardingConfigKey: fluentBitConfiguration(target),
+				},
+			},
+			map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "DaemonSet",
+				"metadata":   metadata,
+				"spec": map[string]interface{}{
+					"selector": map[string]interface{}{
+						"matchLabels": labels,
+					},
+					"template": map[string]interface{}{
+						"metadata": map[string]interface{}{
+							"labels": labels,
+						},
+						"spec": map[string]interface{}{
+							"serviceAccountName": logForwardingName,
+							"tolerations": []interface{}{
+								map[string]string{"operator": "Exists"},
+							},
+							"containers": []interface{}{
+								map[string]interface{}{
+									"name":  "fluent-bit",
+									"image": logForwardingImage,
+									"securityContext": map[string]bool{
+										"privileged": true,
+									},
+									"resources": map[string]interface{}{
+										"requests": map[string]string{
+											"cpu":    "10m",
+											"memory": "32Mi",
+										},
+									},
+									"volumeMounts": []interface{}{
+										map[string]interface{}{
+											"name":      "varlog",
+											"mountPath": "/var/log",
+											"readOnly":  true,
+										},
+										map[string]interface{}{
+											"name":      "config",
+											"mountPath": "/fluent-bit/etc/" + logForwardingConfigKey,
+											"subPath":   logForwardingConfigKey,
+										},
+									},
+								},
+							},
+							"volumes": []interface{}{
+								map[string]interface{}{
+									"name": "varlog",
+									"hostPath": map[string]string{
+										"path": "/var/log",
+									},
+								},
+								map[string]interface{}{
+									"name": "config",
+									"configMap": map[string]string{
+										"name": logForwardingName,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, "", "  ")
+}
+
+// EnsureLogForwardingInTheCluster runs fluent-bit on the node to forward the
+// logs of the cluster to target, and removes it when target is disabled. The
+// cluster is only changed when target differs from the previous start.
+func EnsureLogForwardingInTheCluster(ctx context.Context, sshRunner *ssh.Runner, ocConfig oc.Config, target crcConfig.LogForwardingTarget) error {
+	var resources []byte
+	if target.IsEnabled() {
+		var err error
+		if resources, err = logForwardingResources(target); err != nil {
+			return err
+		}
+		logging.Debugf("Log forwarding resources: %s", resources)
+	}
+	return applyIfChanged(sshRunner, logForwardingName, resources, func() error {
+		return applyLogForwarding(ctx, sshRunner, ocConfig, target, resources)
+	})
+}
+
+func applyLogForwarding(ctx context.Context, sshRunner *ssh.Runner, ocConfig oc.Config, target crcConfig.LogForwardingTarget, resources []byte) error {
+	if err := WaitForOpenshiftResource(ctx, ocConfig, "daemonset"); err != nil {
+		return err
+	}
+	if !target.IsEnabled() {
+		logging.Info("Removing log forwarding...")
+		if _, stderr, err := ocConfig.RunOcCommand("delete", "namespace", logForwardingName, "--ignore-not-found"); err != nil {
+			return fmt.Errorf("Failed to remove log forwarding %v: %s", err, stderr)
+		}
+		if _, stderr, err := ocConfig.RunOcCommand("delete", "clusterrolebinding,clusterrole", logForwardingName, "--ignore-not-found"); err != nil {
+			return fmt.Errorf("Failed to remove log forwarding %v: %s", err, stderr)
+		}
+		return nil
+	}
+	logging.Info("Configuring log forwarding...")
+	resourcesFileName := fmt.Sprintf("/tmp/%s.json", logForwardingName)
+	if err := sshRunner.CopyData(resources, resourcesFileName, 0644); err != nil {
+		return err
+	}
+	if _, stderr, err := ocConfig.RunOcCommand("apply", "-f", resourcesFileName); err != nil {
+		return fmt.Errorf("Failed to configure log forwarding %v: %s", err, stderr)
+	}
+	return nil
+}
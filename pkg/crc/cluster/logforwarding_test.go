@@ -0,0 +1,26 @@
+package cluster
+
+import (
+	"net/url"
+	"testing"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFluentBitConfiguration(t *testing.T) {
+	conf := fluentBitConfiguration(crcConfig.LogForwardingTarget{Directory: "/var/log/crc"})
+	assert.Contains(t, conf, "    Name    http\n    Match   *\n    Host    192.168.127.1\n    Port    80\n    URI     /logs\n")
+
+	lokiURL, err := url.Parse("https://loki.example.com")
+	assert.NoError(t, err)
+	conf = fluentBitConfiguration(crcConfig.LogForwardingTarget{LokiURL: lokiURL})
+	assert.Contains(t, conf, "    Name        loki\n    Match       *\n    Host        loki.example.com\n    Port        443\n    Tls         On\n")
+	assert.NotContains(t, conf, "Uri")
+
+	lokiURL, err = url.Parse("http://loki.example.com:8080/tenant/loki/api/v1/push")
+	assert.NoError(t, err)
+	conf = fluentBitConfiguration(crcConfig.LogForwardingTarget{LokiURL: lokiURL})
+	assert.Contains(t, conf, "    Port        8080\n    Tls         Off\n")
+	assert.Contains(t, conf, "    Uri         /tenant/loki/api/v1/push\n")
+}
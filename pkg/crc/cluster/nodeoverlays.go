@@ -0,0 +1,154 @@
+package cluster
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/code-ready/crc/pkg/crc/systemd"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	kubeletConfigPath = "/etc/kubernetes/kubelet.conf"
+	// the kubelet configuration of the bundle, the overlay is always merged into it
+	kubeletConfigOrigPath   = "/etc/kubernetes/kubelet.conf.crc-orig"
+	kubeletLogLevelDropIn   = "/etc/systemd/system/kubelet.service.d/30-crc-log-level.conf"
+	crioConfigOverlayDropIn = "/etc/crio/crio.conf.d/99-crc-overlay.conf"
+	crioService             = "crio"
+)
+
+// EnsureNodeOverlays applies the kubelet and crio overlays to the VM, and
+// removes the ones which are no longer configured. crio and a running kubelet
+// are restarted when their configuration changes.
+func EnsureNodeOverlays(sshRunner *ssh.Runner, overlays crcConfig.NodeOverlays) error {
+	sd := systemd.NewInstanceSystemdCommander(sshRunner)
+
+	crioChanged, err := deployFile(sshRunner, crioConfigOverlayDropIn, overlays.CrioConfig)
+	if err != nil {
+		return err
+	}
+	if crioChanged {
+		logging.Info("Restarting crio to apply its configuration overlay")
+		if err := sd.Restart(crioService); err != nil {
+			return errors.Wrap(err, "Failed to restart crio")
+		}
+	}
+
+	var logLevelDropIn string
+	if overlays.KubeletLogLevel != "" {
+		logLevelDropIn = fmt.Sprintf("[Service]\nEnvironment=\"KUBELET_LOG_LEVEL=%s\"\n", overlays.KubeletLogLevel)
+	}
+	dropInChanged, err := deployFile(sshRunner, kubeletLogLevelDropIn, logLevelDropIn)
+	if err != nil {
+		return err
+	}
+	if dropInChanged {
+		if err := sd.DaemonReload(); err != nil {
+			return err
+		}
+	}
+	configChanged, err := ensureKubeletConfigOverlay(sshRunner, overlays.KubeletConfig)
+	if err != nil {
+		return err
+	}
+
+	if !dropInChanged && !configChanged {
+		return nil
+	}
+	running, err := IsKubeletRunning(sshRunner)
+	if err != nil || !running {
+		// the configuration is picked up when the kubelet is started
+		return err
+	}
+	logging.Info("Restarting kubelet to apply its configuration overlay")
+	return sd.Restart(kubeletService)
+}
+
+// ensureKubeletConfigOverlay merges overlay into the kubelet configuration of
+// the bundle, and restores it when overlay is empty
+func ensureKubeletConfigOverlay(sshRunner *ssh.Runner, overlay map[string]interface{}) (bool, error) {
+	_, _, err := sshRunner.RunPrivileged("Checking the kubelet configuration backup", "test", "-f", kubeletConfigOrigPath)
+	backupExists := err == nil
+	if len(overlay) == 0 {
+		if !backupExists {
+			return false, nil
+		}
+		if _, stderr, err := sshRunner.RunPrivileged("Restoring the kubelet configuration", "mv", "-f", kubeletConfigOrigPath, kubeletConfigPath); err != nil {
+			return false, fmt.Errorf("Failed to restore the kubelet configuration %v: %s", err, stderr)
+		}
+		return true, nil
+	}
+
+	if !backupExists {
+		if _, stderr, err := sshRunner.RunPrivileged("Saving the kubelet configuration", "cp", "-a", kubeletConfigPath, kubeletConfigOrigPath); err != nil {
+			return false, fmt.Errorf("Failed to save the kubelet configuration %v: %s", err, stderr)
+		}
+	}
+	orig, err := sshRunner.ReadRemoteFile(kubeletConfigOrigPath)
+	if err != nil {
+		return false, fmt.Errorf("Failed to read the kubelet configuration: %v", err)
+	}
+	merged, err := mergeKubeletConfig(orig, overlay)
+	if err != nil {
+		return false, err
+	}
+	return deployFile(sshRunner, kubeletConfigPath, string(merged))
+}
+
+// mergeKubeletConfig returns the JSON kubelet configuration made of base
+// with the fields of overlay, nested objects are merged recursively
+func mergeKubeletConfig(base []byte, overlay map[string]interface{}) ([]byte, error) {
+	jsonData, err := yaml.ToJSON(base)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot parse the kubelet configuration")
+	}
+	config := map[string]interface{}{}
+	if err := json.Unmarshal(jsonData, &config); err != nil {
+		return nil, errors.Wrap(err, "Cannot parse the kubelet configuration")
+	}
+	mergeObjects(config, overlay)
+	return json.MarshalIndent(config, "", "  ")
+}
+
+func mergeObjects(dst, src map[string]interface{}) {
+	for key, value := range src {
+		srcObject, srcIsObject := value.(map[string]interface{})
+		dstObject, dstIsObject := dst[key].(map[string]interface{})
+		if srcIsObject && dstIsObject {
+			mergeObjects(dstObject, srcObject)
+			continue
+		}
+		dst[key] = value
+	}
+}
+
+// deployFile installs content at filename in the VM unless it is already
+// there, an empty content removes the file. It returns true when the file changed.
+func deployFile(sshRunner *ssh.Runner, filename string, content string) (bool, error) {
+	current, err := sshRunner.ReadRemoteFile(filename)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, fmt.Errorf("Failed to read %s: %v", filename, err)
+	}
+	if string(current) == content {
+		return false, nil
+	}
+	if content == "" {
+		if _, stderr, err := sshRunner.RunPrivileged(fmt.Sprintf("Removing %s", filename), "rm", "-f", filename); err != nil {
+			return false, fmt.Errorf("Failed to remove %s %v: %s", filename, err, stderr)
+		}
+		return true, nil
+	}
+	if _, stderr, err := sshRunner.RunPrivileged(fmt.Sprintf("Creating %s", path.Dir(filename)), "mkdir", "-p", path.Dir(filename)); err != nil {
+		return false, fmt.Errorf("Failed to create %s %v: %s", path.Dir(filename), err, stderr)
+	}
+	if err := sshRunner.InstallData([]byte(content), filename, 0644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
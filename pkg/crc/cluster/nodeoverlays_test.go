@@ -0,0 +1,42 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const kubeletConfig = `kind: KubeletConfiguration
+apiVersion: kubelet.config.k8s.io/v1beta1
+maxPods: 250
+podPidsLimit: 4096
+systemReserved:
+  cpu: 500m
+  memory: 1Gi
+`
+
+func TestMergeKubeletConfig(t *testing.T) {
+	merged, err := mergeKubeletConfig([]byte(kubeletConfig), map[string]interface{}{
+		"maxPods": 300,
+		"systemReserved": map[string]interface{}{
+			"memory": "2Gi",
+		},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"kind": "KubeletConfiguration",
+		"apiVersion": "kubelet.config.k8s.io/v1beta1",
+		"maxPods": 300,
+		"podPidsLimit": 4096,
+		"systemReserved": {"cpu": "500m", "memory": "2Gi"}
+	}`, string(merged))
+}
+
+func TestMergeKubeletConfigJSON(t *testing.T) {
+	merged, err := mergeKubeletConfig([]byte(`{"kind":"KubeletConfiguration","maxPods":250}`), map[string]interface{}{
+		"podPidsLimit": 8192,
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"kind": "KubeletConfiguration", "maxPods": 250, "podPidsLimit": 8192}`, string(merged))
+}
@@ -0,0 +1,272 @@
+package cluster
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"time"
+
+	crcerrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	crctls "github.com/code-ready/crc/pkg/crc/tls"
+)
+
+const (
+	profileAPICertSecret  = "crc-profile-api-cert"
+	profileAppsCertSecret = "crc-profile-apps-cert"
+	profileCertsFileName  = "/tmp/crc-profile-certs.json"
+
+	// the certificates are regenerated when they expire within this delay
+	profileCertsRenewBefore = 30 * 24 * time.Hour
+)
+
+// ProfileCertificates are the serving certificates of the domains of a named
+// profile, such as api.test411.crc.testing and *.apps.test411.crc.testing.
+// The bundle only has certificates for the domains of the default profile.
+type ProfileCertificates struct {
+	APIHostname string
+	// AppsDomain is the domain of the console routes, without leading dot
+	AppsDomain string
+	CACert     *x509.Certificate
+	CAKey      *rsa.PrivateKey
+}
+
+func (certs ProfileCertificates) componentRoutes() []map[string]interface{} {
+	var routes []map[string]interface{}
+	for _, route := range []struct{ name, hostname string }{
+		{"console", "console-openshift-console"},
+		{"downloads", "downloads-openshift-console"},
+	} {
+		routes = append(routes, map[string]interface{}{
+			"namespace":                "openshift-console",
+			"name":                     route.name,
+			"hostname":                 fmt.Sprintf("%s.%s", route.hostname, certs.AppsDomain),
+			"servingCertKeyPairSecret": map[string]string{"name": profileAppsCertSecret},
+		})
+	}
+	return routes
+}
+
+func (certs ProfileCertificates) namedCertificate() map[string]interface{} {
+	return map[string]interface{}{
+		"names":              []string{certs.APIHostname},
+		"servingCertificate": map[string]string{"name": profileAPICertSecret},
+	}
+}
+
+// EnsureProfileCertificates makes the API server and the console routes of
+// the cluster serve certificates of the domains of the profile. The API
+// server keeps serving the certificate of the bundle domain too, the new one
+// is only used for the connections to the API hostname of the profile.
+func EnsureProfileCertificates(ctx context.Context, sshRunner *ssh.Runner, ocConfig oc.Config, certs ProfileCertificates) error {
+	if err := WaitForOpenshiftResource(ctx, ocConfig, "apiserver"); err != nil {
+		return err
+	}
+	if err := ensureProfileCertSecrets(sshRunner, ocConfig, certs); err != nil {
+		return err
+	}
+
+	if err := ensureListEntries(ocConfig, "apiserver", "servingCerts", "namedCertificates",
+		[]map[string]interface{}{certs.namedCertificate()}, func(entry map[string]interface{}) bool {
+			return reflect.DeepEqual(entry["servingCertificate"], map[string]interface{}{"name": profileAPICertSecret})
+		}); err != nil {
+		return fmt.Errorf("Failed to update the certificates of the API server %v", err)
+	}
+	if err := ensureListEntries(ocConfig, "ingress.config.openshift.io", "", "componentRoutes",
+		certs.componentRoutes(), func(entry map[string]interface{}) bool {
+			return entry["namespace"] == "openshift-console" && (entry["name"] == "console" || entry["name"] == "downloads")
+		}); err != nil {
+		return fmt.Errorf("Failed to update the console routes %v", err)
+	}
+	return nil
+}
+
+// ensureListEntries sets the entries of the list of the spec of the cluster
+// resource, after removing the ones owned returns true for. The other
+// entries are kept. The resource is not changed when the entries are already
+// there, changing the certificates of the API server rolls it out.
+func ensureListEntries(ocConfig oc.Config, resource, parent, field string, entries []map[string]interface{}, owned func(map[string]interface{}) bool) error {
+	path := "{.spec." + field + "}"
+	if parent != "" {
+		path = fmt.Sprintf("{.spec.%s.%s}", parent, field)
+	}
+	stdout, stderr, err := ocConfig.RunOcCommand("get", resource, "cluster", "-o", fmt.Sprintf("jsonpath='%s'", path))
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, stderr)
+	}
+	var existing []map[string]interface{}
+	if stdout = strings.TrimSpace(stdout); stdout != "" {
+		if err := json.Unmarshal([]byte(stdout), &existing); err != nil {
+			return err
+		}
+	}
+
+	list, changed := mergeListEntries(existing, entries, owned)
+	if !changed {
+		return nil
+	}
+	var patch interface{} = map[string]interface{}{field: list}
+	if parent != "" {
+		patch = map[string]interface{}{parent: patch}
+	}
+	patchJSON, err := json.Marshal(map[string]interface{}{"spec": patch})
+	if err != nil {
+		return err
+	}
+	if _, stderr, err := ocConfig.RunOcCommand("patch", resource, "cluster", "--type", "merge", "-p", fmt.Sprintf("'%s'", patchJSON)); err != nil {
+		return fmt.Errorf("%v: %s", err, stderr)
+	}
+	return nil
+}
+
+// mergeListEntries replaces the entries owned returns true for with entries,
+// it returns false when they were already there
+func mergeListEntries(existing, entries []map[string]interface{}, owned func(map[string]interface{}) bool) ([]map[string]interface{}, bool) {
+	var kept, previous []map[string]interface{}
+	for _, entry := range existing {
+		if owned(entry) {
+			previous = append(previous, entry)
+		} else {
+			kept = append(kept, entry)
+		}
+	}
+	// compare through JSON, the entries read from the cluster only have
+	// generic types
+	previousJSON, _ := json.Marshal(previous)
+	entriesJSON, _ := json.Marshal(entries)
+	if string(previousJSON) == string(entriesJSON) {
+		return existing, false
+	}
+	return append(kept, entries...), true
+}
+
+// ensureProfileCertSecrets creates the secrets of the certificates, they are
+// regenerated when they are missing, expire soon, or are not signed by the CA
+// of the profile anymore
+func ensureProfileCertSecrets(sshRunner *ssh.Runner, ocConfig oc.Config, certs ProfileCertificates) error {
+	valid := true
+	for secret, dnsName := range map[string]string{
+		profileAPICertSecret:  certs.APIHostname,
+		profileAppsCertSecret: "console-openshift-console." + certs.AppsDomain,
+	} {
+		if !profileCertSecretValid(ocConfig, secret, dnsName, certs.CACert, time.Now()) {
+			valid = false
+		}
+	}
+	if valid {
+		return nil
+	}
+
+	logging.Info("Generating the certificates of the domains of the profile...")
+	secrets, err := profileCertSecrets(certs)
+	if err != nil {
+		return err
+	}
+	if err := sshRunner.CopyData(secrets, profileCertsFileName, 0600); err != nil {
+		return err
+	}
+	defer func() {
+		if _, _, err := sshRunner.Run("rm", "-f", profileCertsFileName); err != nil {
+			logging.Debugf("Cannot remove %s: %v", profileCertsFileName, err)
+		}
+	}()
+	if _, stderr, err := ocConfig.RunOcCommandPrivate("apply", "-f", profileCertsFileName); err != nil {
+		return fmt.Errorf("Failed to add the certificates of the profile %v: %s", err, stderr)
+	}
+	return nil
+}
+
+func profileCertSecretValid(ocConfig oc.Config, secret, dnsName string, caCert *x509.Certificate, now time.Time) bool {
+	stdout, _, err := ocConfig.RunOcCommand("get", "secret", secret, "-n", "openshift-config", "-o", `jsonpath='{.data.tls\.crt}'`)
+	if err != nil {
+		return false
+	}
+	certPem, err := base64.StdEncoding.DecodeString(strings.TrimSpace(stdout))
+	if err != nil {
+		return false
+	}
+	return certValidFor(certPem, dnsName, caCert, now)
+}
+
+func certValidFor(certPem []byte, dnsName string, caCert *x509.Certificate, now time.Time) bool {
+	cert, err := crctls.PemToCert(certPem)
+	if err != nil {
+		return false
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	_, err = cert.Verify(x509.VerifyOptions{
+		DNSName:     dnsName,
+		Roots:       roots,
+		CurrentTime: now.Add(profileCertsRenewBefore),
+	})
+	return err == nil
+}
+
+func profileCertSecrets(certs ProfileCertificates) ([]byte, error) {
+	var items []map[string]interface{}
+	for secret, dnsNames := range map[string][]string{
+		profileAPICertSecret:  {certs.APIHostname},
+		profileAppsCertSecret: {"*." + certs.AppsDomain},
+	} {
+		key, cert, err := crctls.GenerateSignedCertificate(certs.CAKey, certs.CACert, &crctls.CertCfg{
+			Subject:      pkix.Name{CommonName: dnsNames[0], OrganizationalUnit: []string{"crc"}},
+			DNSNames:     dnsNames,
+			KeyUsages:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+			ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+			Validity:     crctls.ValidityOneYear,
+		})
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Secret",
+			"type":       "kubernetes.io/tls",
+			"metadata": map[string]string{
+				"name":      secret,
+				"namespace": "openshift-config",
+			},
+			"stringData": map[string]string{
+				"tls.crt": string(crctls.CertToPem(cert)),
+				"tls.key": string(crctls.PrivateKeyToPem(key)),
+			},
+		})
+	}
+	return json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      items,
+	})
+}
+
+// WaitForProfileAPICertificate waits until the API server at ip serves the
+// certificate of the API hostname of the profile, the kube-apiserver
+// operator rolls it out a few minutes after it is configured
+func WaitForProfileAPICertificate(ctx context.Context, ip, apiHostname string, caCert *x509.Certificate, timeout time.Duration) error {
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	served := func() error {
+		dialer := &net.Dialer{Timeout: 10 * time.Second}
+		conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(ip, "6443"), &tls.Config{
+			ServerName: apiHostname,
+			RootCAs:    roots,
+			MinVersion: tls.VersionTLS12,
+		})
+		if err != nil {
+			return &crcerrors.RetriableError{Err: err}
+		}
+		return conn.Close()
+	}
+	return crcerrors.Retry(ctx, timeout, served, 5*time.Second)
+}
@@ -0,0 +1,86 @@
+package cluster
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	crctls "github.com/code-ready/crc/pkg/crc/tls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeListEntries(t *testing.T) {
+	certs := ProfileCertificates{APIHostname: "api.test411.crc.testing"}
+	owned := func(entry map[string]interface{}) bool {
+		servingCertificate, ok := entry["servingCertificate"].(map[string]interface{})
+		return ok && servingCertificate["name"] == profileAPICertSecret
+	}
+	var userEntry map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"names":["api.example.com"],"servingCertificate":{"name":"user-cert"}}`), &userEntry))
+
+	list, changed := mergeListEntries([]map[string]interface{}{userEntry}, []map[string]interface{}{certs.namedCertificate()}, owned)
+	assert.True(t, changed)
+	listJSON, err := json.Marshal(list)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+  {"names":["api.example.com"],"servingCertificate":{"name":"user-cert"}},
+  {"names":["api.test411.crc.testing"],"servingCertificate":{"name":"crc-profile-api-cert"}}
+]`, string(listJSON))
+
+	// read back from the cluster, the entries only have generic types
+	var existing []map[string]interface{}
+	require.NoError(t, json.Unmarshal(listJSON, &existing))
+	_, changed = mergeListEntries(existing, []map[string]interface{}{certs.namedCertificate()}, owned)
+	assert.False(t, changed)
+
+	certs.APIHostname = "api.dev.crc.testing"
+	list, changed = mergeListEntries(existing, []map[string]interface{}{certs.namedCertificate()}, owned)
+	assert.True(t, changed)
+	assert.Len(t, list, 2)
+}
+
+func TestProfileCertSecrets(t *testing.T) {
+	caKey, caCert, err := crctls.GetSelfSignedCA()
+	require.NoError(t, err)
+	_, otherCert, err := crctls.GetSelfSignedCA()
+	require.NoError(t, err)
+
+	certs := ProfileCertificates{
+		APIHostname: "api.test411.crc.testing",
+		AppsDomain:  "apps.test411.crc.testing",
+		CACert:      caCert,
+		CAKey:       caKey,
+	}
+	secretsJSON, err := profileCertSecrets(certs)
+	require.NoError(t, err)
+	var secrets struct {
+		Items []struct {
+			Type     string `json:"type"`
+			Metadata struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"metadata"`
+			StringData map[string]string `json:"stringData"`
+		} `json:"items"`
+	}
+	require.NoError(t, json.Unmarshal(secretsJSON, &secrets))
+	require.Len(t, secrets.Items, 2)
+
+	certPems := map[string][]byte{}
+	for _, secret := range secrets.Items {
+		assert.Equal(t, "kubernetes.io/tls", secret.Type)
+		assert.Equal(t, "openshift-config", secret.Metadata.Namespace)
+		_, err := crctls.PemToPrivateKey([]byte(secret.StringData["tls.key"]))
+		assert.NoError(t, err)
+		certPems[secret.Metadata.Name] = []byte(secret.StringData["tls.crt"])
+	}
+
+	now := time.Now()
+	assert.True(t, certValidFor(certPems[profileAPICertSecret], "api.test411.crc.testing", caCert, now))
+	assert.False(t, certValidFor(certPems[profileAPICertSecret], "api.crc.testing", caCert, now))
+	assert.True(t, certValidFor(certPems[profileAppsCertSecret], "console-openshift-console.apps.test411.crc.testing", caCert, now))
+	// signed by another CA, or expiring soon
+	assert.False(t, certValidFor(certPems[profileAPICertSecret], "api.test411.crc.testing", otherCert, now))
+	assert.False(t, certValidFor(certPems[profileAPICertSecret], "api.test411.crc.testing", caCert, now.Add(340*24*time.Hour)))
+}
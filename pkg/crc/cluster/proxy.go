@@ -0,0 +1,151 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/code-ready/crc/pkg/crc/systemd"
+	"github.com/pkg/errors"
+)
+
+const (
+	clusterProxyName   = "crc-cluster-proxy"
+	kubeletProxyDropIn = "/etc/systemd/system/kubelet.service.d/20-crc-proxy.conf"
+	crioProxyDropIn    = "/etc/systemd/system/crio.service.d/20-crc-proxy.conf"
+)
+
+// UpdateProxySettings applies proxy to the proxy object of the running
+// cluster and to the environment of the kubelet and crio, or removes the
+// proxy configuration from them when proxy is disabled. The machine config
+// operator does not run in the instance, so the node never gets the proxy
+// configuration of the cluster by itself.
+func UpdateProxySettings(ctx context.Context, sshRunner *ssh.Runner, ocConfig oc.Config, proxy *network.ProxyConfig) error {
+	if err := EnsureProxyConfigInCluster(ctx, sshRunner, ocConfig, proxy); err != nil {
+		return err
+	}
+	return EnsureNodeProxyEnvironment(sshRunner, proxy)
+}
+
+// EnsureProxyConfigInCluster sets the proxy object of the cluster, and clears
+// it when the proxy was disabled since it was set. Nothing is done when the
+// proxy configuration did not change since it was applied.
+func EnsureProxyConfigInCluster(ctx context.Context, sshRunner *ssh.Runner, ocConfig oc.Config, proxy *network.ProxyConfig) error {
+	state, err := proxyState(proxy)
+	if err != nil {
+		return err
+	}
+	return applyIfChanged(sshRunner, clusterProxyName, state, func() error {
+		if !proxy.IsEnabled() {
+			return RemoveProxyConfigFromCluster(ctx, ocConfig)
+		}
+		logging.Info("Adding proxy configuration to the cluster...")
+		return AddProxyConfigToCluster(ctx, sshRunner, ocConfig, proxy)
+	})
+}
+
+// proxyState is what the proxy object of the cluster is made of, it is empty
+// when the proxy is disabled
+func proxyState(proxy *network.ProxyConfig) ([]byte, error) {
+	if !proxy.IsEnabled() {
+		return nil, nil
+	}
+	return json.Marshal(map[string]string{
+		"httpProxy":  proxy.HTTPProxy,
+		"httpsProxy": proxy.HTTPSProxy,
+		"noProxy":    proxy.GetNoProxyString(),
+		"trustedCA":  proxy.ProxyCACert,
+	})
+}
+
+// RemoveProxyConfigFromCluster clears the proxy object of the cluster, it does
+// nothing when the cluster does not use a proxy
+func RemoveProxyConfigFromCluster(ctx context.Context, ocConfig oc.Config) error {
+	if err := WaitForOpenshiftResource(ctx, ocConfig, "proxy"); err != nil {
+		return err
+	}
+	stdout, stderr, err := ocConfig.RunOcCommandPrivate("get", "proxy", "cluster", "-o", `jsonpath="{.spec.httpProxy}{.spec.httpsProxy}"`)
+	if err != nil {
+		return fmt.Errorf("Failed to get the proxy configuration of the cluster %v: %s", err, stderr)
+	}
+	if strings.Trim(strings.TrimSpace(stdout), `"`) == "" {
+		return nil
+	}
+
+	logging.Info("Removing the proxy configuration from the cluster...")
+	patch := `'{"spec":{"httpProxy":"","httpsProxy":"","noProxy":"","trustedCA":{"name":""}}}'`
+	if _, stderr, err := ocConfig.RunOcCommand("patch", "proxy", "cluster", "-p", patch, "--type", "merge"); err != nil {
+		return fmt.Errorf("Failed to remove the proxy configuration %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// EnsureNodeProxyEnvironment sets the proxy environment variables of the
+// kubelet and crio, so that they pull the images through the proxy. crio and
+// a running kubelet are restarted when their environment changes.
+func EnsureNodeProxyEnvironment(sshRunner *ssh.Runner, proxy *network.ProxyConfig) error {
+	dropIn := proxyEnvironmentDropIn(proxy)
+	crioChanged, err := deployFile(sshRunner, crioProxyDropIn, dropIn)
+	if err != nil {
+		return err
+	}
+	kubeletChanged, err := deployFile(sshRunner, kubeletProxyDropIn, dropIn)
+	if err != nil {
+		return err
+	}
+	if !crioChanged && !kubeletChanged {
+		return nil
+	}
+
+	sd := systemd.NewInstanceSystemdCommander(sshRunner)
+	if err := sd.DaemonReload(); err != nil {
+		return err
+	}
+	if crioChanged {
+		logging.Info("Restarting crio to apply the proxy configuration")
+		if err := sd.Restart(crioService); err != nil {
+			return errors.Wrap(err, "Failed to restart crio")
+		}
+	}
+	if !kubeletChanged {
+		return nil
+	}
+	running, err := IsKubeletRunning(sshRunner)
+	if err != nil || !running {
+		// the environment is picked up when the kubelet is started
+		return err
+	}
+	logging.Info("Restarting kubelet to apply the proxy configuration")
+	return sd.Restart(kubeletService)
+}
+
+// proxyEnvironmentDropIn returns the systemd drop-in setting the proxy
+// environment variables of a service, it is empty when proxy is disabled
+func proxyEnvironmentDropIn(proxy *network.ProxyConfig) string {
+	if !proxy.IsEnabled() {
+		return ""
+	}
+	var dropIn strings.Builder
+	dropIn.WriteString("[Service]\n")
+	for _, variable := range []struct {
+		name  string
+		value string
+	}{
+		{"HTTP_PROXY", proxy.HTTPProxy},
+		{"HTTPS_PROXY", proxy.HTTPSProxy},
+		{"NO_PROXY", proxy.GetNoProxyString()},
+	} {
+		if variable.value == "" {
+			continue
+		}
+		// '%' starts a specifier in unit files, the credentials of the proxy
+		// URLs are often percent-encoded
+		fmt.Fprintf(&dropIn, "Environment=\"%s=%s\"\n", variable.name, strings.ReplaceAll(variable.value, "%", "%%"))
+	}
+	return dropIn.String()
+}
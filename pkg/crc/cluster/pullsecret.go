@@ -12,19 +12,18 @@ import (
 
 	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/credentials/keyring"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/validation"
 	crcversion "github.com/code-ready/crc/pkg/crc/version"
 	crcos "github.com/code-ready/crc/pkg/os"
 
 	"github.com/AlecAivazis/survey/v2"
-	"github.com/zalando/go-keyring"
 )
 
-const (
-	keyringService = "crc"
-	keyringUser    = "compressed-pull-secret"
-)
+// the pull secret is compressed to fit in the 2560 bytes of a credential of
+// the Windows Credential Manager
+const pullSecretKeyringKey = "compressed-pull-secret"
 
 type PullSecretLoader interface {
 	Value() (string, error)
@@ -107,7 +106,7 @@ func (loader *nonInteractivePullSecretLoader) Value() (string, error) {
 }
 
 func loadFromKeyring() (string, error) {
-	pullsecret, err := keyring.Get(keyringService, keyringUser)
+	pullsecret, err := keyring.Get(pullSecretKeyringKey)
 	if err != nil {
 		return "", err
 	}
@@ -144,11 +143,11 @@ func StoreInKeyring(pullSecret string) error {
 	if err := compressor.Close(); err != nil {
 		return err
 	}
-	return keyring.Set(keyringService, keyringUser, base64.StdEncoding.EncodeToString(b.Bytes()))
+	return keyring.Set(pullSecretKeyringKey, base64.StdEncoding.EncodeToString(b.Bytes()))
 }
 
 func ForgetPullSecret() error {
-	_ = keyring.Delete(keyringService, keyringUser)
+	_ = keyring.Delete(pullSecretKeyringKey)
 	return nil
 }
 
@@ -0,0 +1,143 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+)
+
+const (
+	registryStorageName      = "crc-registry-storage"
+	registryNamespace        = "openshift-image-registry"
+	registryConfigResource   = "configs.imageregistry.operator.openshift.io"
+	previousClaimAnnotation  = "crc.dev/previous-claim"
+	registryStorageCapacity  = "100Gi"
+	registryStorageFileName  = "/tmp/crc-registry-storage.json"
+	registryEmptyDirStorage  = `{"spec":{"storage":{"pvc":null,"emptyDir":{}}}}`
+	registryClaimStorageTmpl = `{"spec":{"storage":{"emptyDir":null,"pvc":{"claim":"%s"}}}}`
+)
+
+func registryStorageVolume(path, previousClaim string) ([]byte, error) {
+	return json.MarshalIndent(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items": []map[string]interface{}{
+			{
+				"apiVersion": "v1",
+				"kind":       "PersistentVolume",
+				"metadata": map[string]interface{}{
+					"name": registryStorageName,
+				},
+				"spec": map[string]interface{}{
+					"capacity":                      map[string]string{"storage": registryStorageCapacity},
+					"accessModes":                   []string{"ReadWriteOnce", "ReadWriteMany"},
+					"persistentVolumeReclaimPolicy": "Retain",
+					"storageClassName":              "",
+					"hostPath":                      map[string]string{"path": path},
+					"claimRef": map[string]string{
+						"namespace": registryNamespace,
+						"name":      registryStorageName,
+					},
+				},
+			},
+			{
+				"apiVersion": "v1",
+				"kind":       "PersistentVolumeClaim",
+				"metadata": map[string]interface{}{
+					"name":      registryStorageName,
+					"namespace": registryNamespace,
+					"annotations": map[string]string{
+						previousClaimAnnotation: previousClaim,
+					},
+				},
+				"spec": map[string]interface{}{
+					"accessModes":      []string{"ReadWriteMany"},
+					"storageClassName": "",
+					"volumeName":       registryStorageName,
+					"resources": map[string]interface{}{
+						"requests": map[string]string{"storage": registryStorageCapacity},
+					},
+				},
+			},
+		},
+	}, "", "  ")
+}
+
+// EnsureRegistryStorage makes the internal registry store the images in the
+// directory of the VM at path, through a hostPath persistent volume. The
+// storage configured before is restored when path is empty. The cluster is
+// only changed when path differs from the previous start.
+func EnsureRegistryStorage(ctx context.Context, sshRunner *ssh.Runner, ocConfig oc.Config, path string) error {
+	return applyIfChanged(sshRunner, registryStorageName, []byte(path), func() error {
+		return applyRegistryStorage(ctx, sshRunner, ocConfig, path)
+	})
+}
+
+func applyRegistryStorage(ctx context.Context, sshRunner *ssh.Runner, ocConfig oc.Config, path string) error {
+	if err := WaitForOpenshiftResource(ctx, ocConfig, registryConfigResource); err != nil {
+		return err
+	}
+	claim, stderr, err := ocConfig.RunOcCommand("get", registryConfigResource, "cluster", "-o", `jsonpath='{.spec.storage.pvc.claim}'`)
+	if err != nil {
+		return fmt.Errorf("Failed to get the registry configuration %v: %s", err, stderr)
+	}
+	claim = strings.TrimSpace(claim)
+
+	if path == "" {
+		if claim != registryStorageName {
+			return nil
+		}
+		return restoreRegistryStorage(ocConfig)
+	}
+	if claim == registryStorageName {
+		return nil
+	}
+
+	logging.Info("Moving the storage of the internal registry...")
+	volume, err := registryStorageVolume(path, claim)
+	if err != nil {
+		return err
+	}
+	if err := sshRunner.CopyData(volume, registryStorageFileName, 0644); err != nil {
+		return err
+	}
+	if _, stderr, err := ocConfig.RunOcCommand("apply", "-f", registryStorageFileName); err != nil {
+		return fmt.Errorf("Failed to create the registry persistent volume %v: %s", err, stderr)
+	}
+	if _, stderr, err := ocConfig.RunOcCommand("patch", registryConfigResource, "cluster", "--type", "merge", "-p",
+		fmt.Sprintf("'%s'", fmt.Sprintf(registryClaimStorageTmpl, registryStorageName))); err != nil {
+		return fmt.Errorf("Failed to update the registry storage %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// restoreRegistryStorage configures the registry with the storage it had
+// before EnsureRegistryStorage, the images stay in the volume which is no
+// longer used
+func restoreRegistryStorage(ocConfig oc.Config) error {
+	previousClaim, stderr, err := ocConfig.RunOcCommand("get", "pvc", registryStorageName, "-n", registryNamespace,
+		"-o", fmt.Sprintf(`jsonpath='{.metadata.annotations.%s}'`, strings.ReplaceAll(previousClaimAnnotation, ".", `\.`)))
+	if err != nil {
+		return fmt.Errorf("Failed to get the registry persistent volume claim %v: %s", err, stderr)
+	}
+	patch := registryEmptyDirStorage
+	if previousClaim = strings.TrimSpace(previousClaim); previousClaim != "" {
+		patch = fmt.Sprintf(registryClaimStorageTmpl, previousClaim)
+	}
+	logging.Info("Restoring the storage of the internal registry...")
+	if _, stderr, err := ocConfig.RunOcCommand("patch", registryConfigResource, "cluster", "--type", "merge", "-p", fmt.Sprintf("'%s'", patch)); err != nil {
+		return fmt.Errorf("Failed to update the registry storage %v: %s", err, stderr)
+	}
+	if _, stderr, err := ocConfig.RunOcCommand("delete", "pvc", registryStorageName, "-n", registryNamespace, "--ignore-not-found", "--wait=false"); err != nil {
+		return fmt.Errorf("Failed to remove the registry persistent volume claim %v: %s", err, stderr)
+	}
+	if _, stderr, err := ocConfig.RunOcCommand("delete", "pv", registryStorageName, "--ignore-not-found", "--wait=false"); err != nil {
+		return fmt.Errorf("Failed to remove the registry persistent volume %v: %s", err, stderr)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryStorageVolume(t *testing.T) {
+	volume, err := registryStorageVolume("/var/mnt/crc-registry", "crc-image-registry-storage")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+  "apiVersion": "v1",
+  "kind": "List",
+  "items": [
+    {
+      "apiVersion": "v1",
+      "kind": "PersistentVolume",
+      "metadata": {"name": "crc-registry-storage"},
+      "spec": {
+        "capacity": {"storage": "100Gi"},
+        "accessModes": ["ReadWriteOnce", "ReadWriteMany"],
+        "persistentVolumeReclaimPolicy": "Retain",
+        "storageClassName": "",
+        "hostPath": {"path": "/var/mnt/crc-registry"},
+        "claimRef": {"namespace": "openshift-image-registry", "name": "crc-registry-storage"}
+      }
+    },
+    {
+      "apiVersion": "v1",
+      "kind": "PersistentVolumeClaim",
+      "metadata": {
+        "name": "crc-registry-storage",
+        "namespace": "openshift-image-registry",
+        "annotations": {"crc.dev/previous-claim": "crc-image-registry-storage"}
+      },
+      "spec": {
+        "accessModes": ["ReadWriteMany"],
+        "storageClassName": "",
+        "volumeName": "crc-registry-storage",
+        "resources": {"requests": {"storage": "100Gi"}}
+      }
+    }
+  ]
+}`, string(volume))
+}
@@ -0,0 +1,92 @@
+package cluster
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/oc"
+)
+
+// telemetryRegistry is the entry of the pull secret with which Telemetry and
+// the Insights operator authenticate their reports, it ties them to the
+// account of the user
+const telemetryRegistry = "cloud.openshift.com"
+
+// EnsureRemoteHealthReporting adds the cloud.openshift.com credentials of
+// pullSec to the pull secret of the cluster, or removes them when enabled is
+// false, so that the cluster sends no Telemetry or Insights report
+func EnsureRemoteHealthReporting(ocConfig oc.Config, pullSec PullSecretLoader, enabled bool) error {
+	stdout, stderr, err := ocConfig.RunOcCommandPrivate("get", "secret", "pull-secret", "-n", "openshift-config", "-o", `jsonpath="{['data']['\.dockerconfigjson']}"`)
+	if err != nil {
+		return fmt.Errorf("Failed to get pull secret %v: %s", err, stderr)
+	}
+	current, err := base64.StdEncoding.DecodeString(strings.Trim(strings.TrimSpace(stdout), `"`))
+	if err != nil {
+		return err
+	}
+	var userSecret string
+	if enabled {
+		if userSecret, err = pullSec.Value(); err != nil {
+			return err
+		}
+	}
+	updated, changed, err := setTelemetryAuth(current, []byte(userSecret), enabled)
+	if err != nil || !changed {
+		return err
+	}
+
+	if enabled {
+		logging.Info("Enabling Telemetry and Insights reports of the cluster...")
+	} else {
+		logging.Info("Disabling Telemetry and Insights reports of the cluster...")
+	}
+	cmdArgs := []string{"patch", "secret", "pull-secret", "-p",
+		fmt.Sprintf(`'{"data":{".dockerconfigjson":"%s"}}'`, base64.StdEncoding.EncodeToString(updated)),
+		"-n", "openshift-config", "--type", "merge"}
+	if _, stderr, err := ocConfig.RunOcCommandPrivate(cmdArgs...); err != nil {
+		return fmt.Errorf("Failed to update pull secret %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// setTelemetryAuth copies the cloud.openshift.com entry of userSecret to
+// secret when enabled is true, and removes it from secret otherwise. It
+// returns false when secret does not change.
+func setTelemetryAuth(secret, userSecret []byte, enabled bool) ([]byte, bool, error) {
+	var content map[string]interface{}
+	if err := json.Unmarshal(secret, &content); err != nil {
+		return nil, false, fmt.Errorf("invalid pull secret: %v", err)
+	}
+	auths, ok := content["auths"].(map[string]interface{})
+	if !ok {
+		return nil, false, fmt.Errorf("invalid pull secret: missing 'auths' JSON-object field")
+	}
+	_, present := auths[telemetryRegistry]
+	if present == enabled {
+		return secret, false, nil
+	}
+
+	if enabled {
+		var user struct {
+			Auths map[string]interface{} `json:"auths"`
+		}
+		if err := json.Unmarshal(userSecret, &user); err != nil {
+			return nil, false, fmt.Errorf("invalid pull secret: %v", err)
+		}
+		auth, ok := user.Auths[telemetryRegistry]
+		if !ok {
+			return secret, false, nil
+		}
+		auths[telemetryRegistry] = auth
+	} else {
+		delete(auths, telemetryRegistry)
+	}
+	updated, err := json.Marshal(content)
+	if err != nil {
+		return nil, false, err
+	}
+	return updated, true, nil
+}
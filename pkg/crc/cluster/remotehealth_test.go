@@ -0,0 +1,38 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetTelemetryAuth(t *testing.T) {
+	userSecret := []byte(`{"auths":{"cloud.openshift.com":{"auth":"telemetry"},"quay.io":{"auth":"secret1"}}}`) // #nosec G101
+	withoutTelemetry := []byte(`{"auths":{"quay.io":{"auth":"secret1"}}}`)                                      // #nosec G101
+
+	updated, changed, err := setTelemetryAuth(userSecret, nil, false)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.JSONEq(t, string(withoutTelemetry), string(updated))
+
+	_, changed, err = setTelemetryAuth(withoutTelemetry, nil, false)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	updated, changed, err = setTelemetryAuth(withoutTelemetry, userSecret, true)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.JSONEq(t, string(userSecret), string(updated))
+
+	_, changed, err = setTelemetryAuth(userSecret, userSecret, true)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	_, changed, err = setTelemetryAuth(withoutTelemetry, withoutTelemetry, true)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	_, _, err = setTelemetryAuth([]byte(`{}`), nil, false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,168 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+)
+
+const (
+	resourceSamplingInterval = 30 * time.Second
+	// number of consecutive samples a resource must be exhausted before warning
+	exhaustedSamples   = 4
+	cpuUsageThreshold  = 0.95
+	memAvailableRatio  = 0.05
+	swappedPagesPerSec = 100
+)
+
+type ResourceUsage struct {
+	MemTotal     uint64
+	MemAvailable uint64
+	// total number of pages swapped in and out since boot
+	SwappedPages uint64
+	// CPU time since boot, in USER_HZ
+	CPUBusy  uint64
+	CPUTotal uint64
+	// CPU time stolen by the hypervisor since boot, in USER_HZ
+	CPUSteal uint64
+	// boot time of the VM, zero when unknown
+	BootTime time.Time
+}
+
+// GetResourceUsage samples the memory and CPU usage of the VM
+func GetResourceUsage(sshRunner *ssh.Runner) (*ResourceUsage, error) {
+	stdout, stderr, err := sshRunner.Run("cat /proc/meminfo /proc/vmstat /proc/stat")
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get resource usage %v: %s", err, stderr)
+	}
+	return parseResourceUsage(stdout)
+}
+
+func parseResourceUsage(output string) (*ResourceUsage, error) {
+	usage := &ResourceUsage{}
+	foundCPU := false
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:", "MemAvailable:":
+			// values are in kB
+			value, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			if fields[0] == "MemTotal:" {
+				usage.MemTotal = value * 1024
+			} else {
+				usage.MemAvailable = value * 1024
+			}
+		case "pswpin", "pswpout":
+			value, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			usage.SwappedPages += value
+		case "cpu":
+			// cpu user nice system idle iowait irq softirq steal ...
+			for i, field := range fields[1:] {
+				value, err := strconv.ParseUint(field, 10, 64)
+				if err != nil {
+					return nil, err
+				}
+				usage.CPUTotal += value
+				// idle and iowait
+				if i != 3 && i != 4 {
+					usage.CPUBusy += value
+				}
+				if i == 7 {
+					usage.CPUSteal = value
+				}
+			}
+			foundCPU = true
+		case "btime":
+			// seconds since the epoch
+			value, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			usage.BootTime = time.Unix(value, 0)
+		}
+	}
+	if usage.MemTotal == 0 || !foundCPU {
+		return nil, fmt.Errorf("unexpected resource usage output")
+	}
+	return usage, nil
+}
+
+type resourceMonitor struct {
+	previous          *ResourceUsage
+	cpuExhausted      int
+	memoryExhausted   int
+	cpuWarningSent    bool
+	memoryWarningSent bool
+}
+
+// check compares usage with the previous sample and returns the warnings to emit
+func (monitor *resourceMonitor) check(usage *ResourceUsage, elapsed time.Duration) []string {
+	var warnings []string
+	previous := monitor.previous
+	monitor.previous = usage
+	if previous == nil {
+		return nil
+	}
+
+	swapping := float64(usage.SwappedPages-previous.SwappedPages)/elapsed.Seconds() > swappedPagesPerSec
+	if swapping || float64(usage.MemAvailable) < float64(usage.MemTotal)*memAvailableRatio {
+		monitor.memoryExhausted++
+	} else {
+		monitor.memoryExhausted = 0
+	}
+	if cpuTotal := usage.CPUTotal - previous.CPUTotal; cpuTotal > 0 && float64(usage.CPUBusy-previous.CPUBusy)/float64(cpuTotal) > cpuUsageThreshold {
+		monitor.cpuExhausted++
+	} else {
+		monitor.cpuExhausted = 0
+	}
+
+	if monitor.memoryExhausted >= exhaustedSamples && !monitor.memoryWarningSent {
+		monitor.memoryWarningSent = true
+		warnings = append(warnings, "The VM has been running out of memory for several minutes, this slows down the cluster start. "+
+			"You can increase the memory allocated to the VM with 'crc config set memory <MiB>'")
+	}
+	if monitor.cpuExhausted >= exhaustedSamples && !monitor.cpuWarningSent {
+		monitor.cpuWarningSent = true
+		warnings = append(warnings, "The VM CPUs have been fully used for several minutes, this slows down the cluster start. "+
+			"You can increase the number of CPUs allocated to the VM with 'crc config set cpus <count>'")
+	}
+	return warnings
+}
+
+// MonitorResourceUsage periodically samples the resource usage of the VM until
+// ctx is cancelled, and warns when memory or CPU are exhausted for several minutes
+func MonitorResourceUsage(ctx context.Context, sshRunner *ssh.Runner) {
+	monitor := &resourceMonitor{}
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resourceSamplingInterval):
+		}
+		usage, err := GetResourceUsage(sshRunner)
+		if err != nil {
+			logging.Debugf("Cannot get VM resource usage: %v", err)
+			continue
+		}
+		now := time.Now()
+		for _, warning := range monitor.check(usage, now.Sub(last)) {
+			logging.Warn(warning)
+		}
+		last = now
+	}
+}
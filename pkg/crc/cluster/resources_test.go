@@ -0,0 +1,58 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func procOutput(memAvailable, swapped, busy, idle uint64) string {
+	return fmt.Sprintf(`MemTotal:       10000000 kB
+MemFree:          100000 kB
+MemAvailable:   %d kB
+pgpgin 1234
+pswpin %d
+pswpout 0
+cpu  %d 0 0 %d 0 0 0 5 0 0
+cpu0 1 0 0 1 0 0 0 0 0 0
+btime 1634000000
+`, memAvailable, swapped, busy, idle)
+}
+
+func TestParseResourceUsage(t *testing.T) {
+	usage, err := parseResourceUsage(procOutput(5000000, 42, 300, 700))
+	require.NoError(t, err)
+	assert.Equal(t, &ResourceUsage{
+		MemTotal:     10000000 * 1024,
+		MemAvailable: 5000000 * 1024,
+		SwappedPages: 42,
+		CPUBusy:      305,
+		CPUTotal:     1005,
+		CPUSteal:     5,
+		BootTime:     time.Unix(1634000000, 0),
+	}, usage)
+
+	_, err = parseResourceUsage("")
+	assert.Error(t, err)
+}
+
+func TestResourceMonitor(t *testing.T) {
+	monitor := &resourceMonitor{}
+	var warnings []string
+	for i := uint64(0); i <= exhaustedSamples+2; i++ {
+		usage, err := parseResourceUsage(procOutput(100000, 0, 1000*i, 10*i))
+		require.NoError(t, err)
+		warnings = append(warnings, monitor.check(usage, resourceSamplingInterval)...)
+	}
+	assert.Len(t, warnings, 2)
+
+	monitor = &resourceMonitor{}
+	for i := uint64(0); i <= exhaustedSamples+2; i++ {
+		usage, err := parseResourceUsage(procOutput(5000000, 0, 100*i, 1000*i))
+		require.NoError(t, err)
+		assert.Empty(t, monitor.check(usage, time.Minute))
+	}
+}
@@ -0,0 +1,172 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	crcerrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// the image is pulled from a registry which needs no pull secret, its
+	// pull is the registry check
+	NetworkSelfTestImage = "registry.access.redhat.com/ubi8/ubi-minimal:latest"
+
+	RegistryPullCheck = "registry-pull"
+	ClusterDNSCheck   = "cluster-dns"
+	ExternalDNSCheck  = "external-dns"
+	EgressCheck       = "egress"
+
+	selfTestPodName      = "crc-network-selftest"
+	selfTestNamespace    = "default"
+	selfTestManifestPath = "/tmp/crc-network-selftest.json"
+	selfTestTimeout      = 3 * time.Minute
+)
+
+// each check of the script prints '<check> ok' or '<check> failed'
+var selfTestScript = strings.Join([]string{
+	checkCommand(ClusterDNSCheck, "getent hosts kubernetes.default.svc.cluster.local"),
+	checkCommand(ExternalDNSCheck, "getent hosts quay.io"),
+	checkCommand(EgressCheck, "curl --silent --show-error --output /dev/null --max-time 15 https://quay.io"),
+}, "\n")
+
+func checkCommand(check, command string) string {
+	return fmt.Sprintf("if %s >/dev/null 2>&1; then echo '%s ok'; else echo '%s failed'; fi", command, check, check)
+}
+
+type SelfTestCheck struct {
+	Name    string `json:"name"`
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// RunNetworkSelfTest runs a short-lived pod which resolves a cluster service
+// and an external name and connects to an external registry, to find the
+// cluster networking issues which the checks of the node do not see. The
+// pull of the pod image checks the access to the registries.
+func RunNetworkSelfTest(ctx context.Context, sshRunner *ssh.Runner) ([]SelfTestCheck, error) {
+	ocConfig := oc.UseOCWithSSH(sshRunner)
+	if err := deleteSelfTestPod(ocConfig); err != nil {
+		return nil, err
+	}
+	manifest, err := json.Marshal(selfTestPod())
+	if err != nil {
+		return nil, err
+	}
+	if err := sshRunner.CopyData(manifest, selfTestManifestPath, 0644); err != nil {
+		return nil, err
+	}
+	if _, stderr, err := ocConfig.RunOcCommand("create", "-f", selfTestManifestPath); err != nil {
+		return nil, fmt.Errorf("Failed to create the self-test pod %v: %s", err, stderr)
+	}
+	defer func() {
+		if err := deleteSelfTestPod(ocConfig); err != nil {
+			logging.Debugf("Cannot delete the self-test pod: %v", err)
+		}
+	}()
+
+	var pod corev1.Pod
+	waitForPod := func() error {
+		stdout, stderr, err := ocConfig.RunOcCommand("get", "pod", selfTestPodName, "--namespace", selfTestNamespace, "-o", "json")
+		if err != nil {
+			return &crcerrors.RetriableError{Err: fmt.Errorf("%v: %s", err, stderr)}
+		}
+		if err := json.Unmarshal([]byte(stdout), &pod); err != nil {
+			return err
+		}
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed || imagePullError(&pod) != "" {
+			return nil
+		}
+		return &crcerrors.RetriableError{Err: fmt.Errorf("the self-test pod is %s", pod.Status.Phase)}
+	}
+	if err := crcerrors.Retry(ctx, selfTestTimeout, waitForPod, 3*time.Second); err != nil {
+		return nil, fmt.Errorf("The self-test pod did not complete: %v", err)
+	}
+
+	if reason := imagePullError(&pod); reason != "" {
+		return []SelfTestCheck{{Name: RegistryPullCheck, Message: fmt.Sprintf("Cannot pull %s: %s", NetworkSelfTestImage, reason)}}, nil
+	}
+	logs, stderr, err := ocConfig.RunOcCommand("logs", selfTestPodName, "--namespace", selfTestNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get the self-test pod logs %v: %s", err, stderr)
+	}
+	return append([]SelfTestCheck{{Name: RegistryPullCheck, Success: true}}, parseSelfTestOutput(logs)...), nil
+}
+
+func deleteSelfTestPod(ocConfig oc.Config) error {
+	if _, stderr, err := ocConfig.RunOcCommand("delete", "pod", selfTestPodName, "--namespace", selfTestNamespace, "--ignore-not-found", "--grace-period=0"); err != nil {
+		return fmt.Errorf("Failed to delete the self-test pod %v: %s", err, stderr)
+	}
+	return nil
+}
+
+func selfTestPod() *corev1.Pod {
+	deadline := int64(selfTestTimeout / time.Second)
+	gracePeriod := int64(0)
+	return &corev1.Pod{
+		TypeMeta: metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      selfTestPodName,
+			Namespace: selfTestNamespace,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			ActiveDeadlineSeconds:         &deadline,
+			TerminationGracePeriodSeconds: &gracePeriod,
+			Containers: []corev1.Container{
+				{
+					Name:    "selftest",
+					Image:   NetworkSelfTestImage,
+					Command: []string{"/bin/sh", "-c", selfTestScript},
+				},
+			},
+		},
+	}
+}
+
+// imagePullError returns why the image of the pod cannot be pulled, it is
+// empty while the pull is in progress or once it succeeded
+func imagePullError(pod *corev1.Pod) string {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting == nil {
+			continue
+		}
+		switch status.State.Waiting.Reason {
+		case "ErrImagePull", "ImagePullBackOff", "InvalidImageName":
+			if status.State.Waiting.Message != "" {
+				return status.State.Waiting.Message
+			}
+			return status.State.Waiting.Reason
+		}
+	}
+	return ""
+}
+
+func parseSelfTestOutput(output string) []SelfTestCheck {
+	results := map[string]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		results[fields[0]] = fields[1] == "ok"
+	}
+	var checks []SelfTestCheck
+	for _, name := range []string{ClusterDNSCheck, ExternalDNSCheck, EgressCheck} {
+		success, found := results[name]
+		check := SelfTestCheck{Name: name, Success: success}
+		if !found {
+			check.Message = "The check did not run"
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
@@ -0,0 +1,35 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseSelfTestOutput(t *testing.T) {
+	checks := parseSelfTestOutput("cluster-dns ok\nexternal-dns failed\n")
+	assert.Equal(t, []SelfTestCheck{
+		{Name: ClusterDNSCheck, Success: true},
+		{Name: ExternalDNSCheck, Success: false},
+		{Name: EgressCheck, Success: false, Message: "The check did not run"},
+	}, checks)
+}
+
+func TestImagePullError(t *testing.T) {
+	pod := &corev1.Pod{
+		Status: corev1.PodStatus{
+			ContainerStatuses: []corev1.ContainerStatus{
+				{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}}},
+			},
+		},
+	}
+	assert.Empty(t, imagePullError(pod))
+
+	pod.Status.ContainerStatuses[0].State.Waiting = &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"}
+	assert.Equal(t, "Back-off pulling image", imagePullError(pod))
+}
+
+func TestSelfTestScript(t *testing.T) {
+	assert.Contains(t, selfTestScript, "if getent hosts kubernetes.default.svc.cluster.local >/dev/null 2>&1; then echo 'cluster-dns ok'; else echo 'cluster-dns failed'; fi")
+}
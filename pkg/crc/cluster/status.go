@@ -2,62 +2,296 @@ package cluster
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/network"
+	openshiftapi "github.com/openshift/api/config/v1"
+	clientset "github.com/openshift/client-go/config/clientset/versioned"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
-// WaitForClusterStable checks that the cluster is running a number of consecutive times
-func WaitForClusterStable(ctx context.Context, ip string, kubeconfigFilePath string, proxy *network.ProxyConfig) error {
+const (
+	clusterStableTimeout = 10 * time.Minute
+	// the operators must stay ready for this long to consider the cluster stable
+	stabilityPeriod = time.Minute
+	// delay before listing the operators again when the watch failed, it
+	// doubles after each failure up to maxWatchRetryInterval
+	watchRetryInterval    = 5 * time.Second
+	maxWatchRetryInterval = 40 * time.Second
+)
+
+// the operators which are still progressing or degraded are summarized this
+// often while waiting for the cluster
+var progressInterval = 30 * time.Second
+
+// the apiserver ends the watches after this duration, they are then resumed
+var watchTimeoutSeconds int64 = 5 * 60
+
+type operatorWatcher interface {
+	operatorLister
+	Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error)
+}
+
+// WaitForClusterStable waits for the cluster operators to be ready for stabilityPeriod.
+// When operators is not empty, only these operators need to be available.
+// The operators are watched rather than polled, so that readiness is noticed
+// as soon as it happens without loading the apiserver.
+func WaitForClusterStable(ctx context.Context, ip string, kubeconfigFilePath string, proxy *network.ProxyConfig, operators []string) error {
 	if ctx.Err() != nil {
 		return ctx.Err()
 	}
 
 	startTime := time.Now()
 
-	retryDuration := 30 * time.Second
-	retryCount := 20 // 10 minutes
-
+	timeout := clusterStableTimeout
 	if proxy.IsEnabled() {
-		// In case proxy is enabled increase the retry count
-		// to 10 and this will add addition 5 mins.
-		retryCount += 10
+		// In case proxy is enabled, give the cluster 5 more minutes
+		timeout += 5 * time.Minute
 	}
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
 
-	numConsecutive := 3
-	var count int // holds num of consecutive matches
+	client, err := kubernetesWatchClient(ip, kubeconfigFilePath)
+	if err != nil {
+		return err
+	}
+	tracker := &operatorsTracker{selector: operators}
+	err = tracker.waitForStable(waitCtx, client.ConfigV1().ClusterOperators(), stabilityPeriod)
+	if err == nil {
+		logging.Debugf("Cluster took %s to stabilize", time.Since(startTime))
+		return nil
+	}
+	if ctx.Err() == nil && errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("cluster operators are still not stable after %s: %s", time.Since(startTime).Round(time.Second), tracker.diagnostics())
+	}
+	return err
+}
+
+func kubernetesWatchClient(ip string, kubeconfigFilePath string) (*clientset.Clientset, error) {
+	config, err := kubernetesClientConfiguration(ip, kubeconfigFilePath)
+	if err != nil {
+		return nil, err
+	}
+	// the client timeout would interrupt the watches, they are bounded by
+	// watchTimeoutSeconds instead
+	config.Timeout = 0
+	return clientset.NewForConfig(config)
+}
 
-	for i := 0; i < retryCount; i++ {
-		status, err := GetClusterOperatorsStatus(ctx, ip, kubeconfigFilePath)
+// waitForStable returns when the operators are stable for period. The
+// operators are listed again with a backoff while the apiserver cannot be
+// reached or the watch fails.
+func (tracker *operatorsTracker) waitForStable(ctx context.Context, client operatorWatcher, period time.Duration) error {
+	retryInterval := watchRetryInterval
+	tracker.lastProgress = time.Now()
+	for {
+		attemptStart := time.Now()
+		err := tracker.listAndWatch(ctx, client, period)
 		if err == nil {
-			// update counter for consecutive matches
-			if status.IsReady() {
-				count++
-				if count == 1 {
-					logging.Info("All operators are available. Ensuring stability...")
-				} else {
-					logging.Infof("Operators are stable (%d/%d)...", count, numConsecutive)
-				}
-			} else {
-				logging.Info(status.String())
-				count = 0
-			}
-			// break if done
-			if count == numConsecutive {
-				logging.Debugf("Cluster took %s to stabilize", time.Since(startTime))
-				return nil
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		tracker.lastError = err
+		logging.Debugf("Cannot watch cluster operators: %v", err)
+		// the apiserver was reachable for a while, it is not starting anymore
+		if time.Since(attemptStart) > maxWatchRetryInterval {
+			retryInterval = watchRetryInterval
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryInterval):
+		}
+		retryInterval *= 2
+		if retryInterval > maxWatchRetryInterval {
+			retryInterval = maxWatchRetryInterval
+		}
+	}
+}
+
+// operatorsTracker keeps the state of the cluster operators up to date from
+// watch events
+type operatorsTracker struct {
+	selector  []string
+	operators map[string]openshiftapi.ClusterOperator
+	// when the operators became ready, zero when they are not ready
+	readySince  time.Time
+	lastMessage string
+	// when the progress of the operators was last summarized
+	lastProgress time.Time
+	// the last error of the apiserver, reported when the operators could not be listed
+	lastError error
+}
+
+// listAndWatch lists the operators, then follows their changes until they
+// are stable for period. Interrupted watches are resumed from the last seen
+// resource version. It returns an error when the operators must be listed again.
+func (tracker *operatorsTracker) listAndWatch(ctx context.Context, client operatorWatcher, period time.Duration) error {
+	list, err := client.List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	tracker.operators = map[string]openshiftapi.ClusterOperator{}
+	for _, operator := range list.Items {
+		tracker.operators[operator.Name] = operator
+	}
+	tracker.update(time.Now())
+	resourceVersion := list.ResourceVersion
+
+	for {
+		watcher, err := client.Watch(ctx, metav1.ListOptions{
+			ResourceVersion:     resourceVersion,
+			TimeoutSeconds:      &watchTimeoutSeconds,
+			AllowWatchBookmarks: true,
+		})
+		if err != nil {
+			return err
+		}
+		var stable bool
+		resourceVersion, stable, err = tracker.follow(ctx, watcher, resourceVersion, period)
+		watcher.Stop()
+		if err != nil || stable {
+			return err
+		}
+		logging.Debugf("Resuming cluster operators watch from version %s", resourceVersion)
+	}
+}
+
+// follow applies the events of watcher until the operators are stable for
+// period or the watch ends. It returns the last seen resource version.
+func (tracker *operatorsTracker) follow(ctx context.Context, watcher watch.Interface, resourceVersion string, period time.Duration) (string, bool, error) {
+	for {
+		var stableTimer, progressTimer <-chan time.Time
+		if !tracker.readySince.IsZero() {
+			remaining := time.Until(tracker.readySince.Add(period))
+			if remaining <= 0 {
+				return resourceVersion, true, nil
 			}
+			stableTimer = time.After(remaining)
 		} else {
-			count = 0
+			progressTimer = time.After(time.Until(tracker.lastProgress.Add(progressInterval)))
 		}
+
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(retryDuration):
+			return resourceVersion, false, ctx.Err()
+		case <-stableTimer:
+			return resourceVersion, true, nil
+		case <-progressTimer:
+			tracker.lastProgress = time.Now()
+			tracker.logProgress()
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return resourceVersion, false, nil
+			}
+			if event.Type == watch.Error {
+				// most likely the resource version is too old, the operators must be listed again
+				return resourceVersion, false, apierrors.FromObject(event.Object)
+			}
+			operator, ok := event.Object.(*openshiftapi.ClusterOperator)
+			if !ok {
+				continue
+			}
+			resourceVersion = operator.ResourceVersion
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				tracker.operators[operator.Name] = *operator
+			case watch.Deleted:
+				delete(tracker.operators, operator.Name)
+			default:
+				continue
+			}
+			tracker.update(time.Now())
 		}
 	}
+}
 
-	return fmt.Errorf("cluster operators are still not stable after %s", time.Since(startTime))
+// update recomputes the readiness of the operators after a change
+func (tracker *operatorsTracker) update(now time.Time) {
+	operators := make([]openshiftapi.ClusterOperator, 0, len(tracker.operators))
+	for _, operator := range tracker.operators {
+		operators = append(operators, operator)
+	}
+	status, err := aggregateStatus(operators, tracker.selector)
+	if err != nil {
+		tracker.readySince = time.Time{}
+		logging.Debugf("Cannot get cluster operators status: %v", err)
+		return
+	}
+	if !status.IsReady() && !(len(tracker.selector) > 0 && status.Available) {
+		tracker.readySince = time.Time{}
+		tracker.log(status.String())
+		return
+	}
+	if !tracker.readySince.IsZero() {
+		return
+	}
+	tracker.readySince = now
+	if len(tracker.selector) > 0 {
+		tracker.log(fmt.Sprintf("Operators %s are available. Ensuring stability...", strings.Join(tracker.selector, ", ")))
+	} else {
+		tracker.log("All operators are available. Ensuring stability...")
+	}
+}
+
+// diagnostics describes why the operators are not stable
+func (tracker *operatorsTracker) diagnostics() string {
+	if tracker.operators == nil {
+		if tracker.lastError != nil {
+			return fmt.Sprintf("the cluster operators could not be listed: %v", tracker.lastError)
+		}
+		return "the cluster operators could not be listed"
+	}
+	operators := make([]openshiftapi.ClusterOperator, 0, len(tracker.operators))
+	for _, operator := range tracker.operators {
+		operators = append(operators, operator)
+	}
+	notReady := notReadyOperators(operators, tracker.selector)
+	if len(notReady) == 0 {
+		return "the operators did not stay ready long enough"
+	}
+	return strings.Join(notReady, ", ")
+}
+
+// progress describes the operators which are still progressing or degraded
+func (tracker *operatorsTracker) progress() []string {
+	operators := make([]openshiftapi.ClusterOperator, 0, len(tracker.operators))
+	for _, operator := range tracker.operators {
+		operators = append(operators, operator)
+	}
+	return operatorsProgress(operators, tracker.selector)
+}
+
+// logProgress summarizes the operators which are still progressing or
+// degraded, it is done periodically even when they do not change
+func (tracker *operatorsTracker) logProgress() {
+	progress := tracker.progress()
+	if len(progress) == 0 {
+		return
+	}
+	if len(progress) == 1 {
+		logging.Info("Waiting for 1 cluster operator:")
+	} else {
+		logging.Infof("Waiting for %d cluster operators:", len(progress))
+	}
+	for _, line := range progress {
+		logging.Infof("  %s", line)
+	}
+}
+
+// log only reports the changes of the operators state, not every event
+func (tracker *operatorsTracker) log(message string) {
+	if message == tracker.lastMessage {
+		return
+	}
+	tracker.lastMessage = message
+	logging.Info(message)
 }
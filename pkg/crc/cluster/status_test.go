@@ -0,0 +1,137 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	v1 "github.com/openshift/api/config/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+type fakeOperatorWatcher struct {
+	list     *v1.ClusterOperatorList
+	watchers chan *watch.FakeWatcher
+	// resource versions the watches were started from
+	resourceVersions []string
+}
+
+func (f *fakeOperatorWatcher) List(ctx context.Context, opts metav1.ListOptions) (*v1.ClusterOperatorList, error) {
+	return f.list.DeepCopy(), nil
+}
+
+func (f *fakeOperatorWatcher) Watch(ctx context.Context, opts metav1.ListOptions) (watch.Interface, error) {
+	f.resourceVersions = append(f.resourceVersions, opts.ResourceVersion)
+	watcher := watch.NewFakeWithChanSize(10, false)
+	f.watchers <- watcher
+	return watcher, nil
+}
+
+func readOperators(t *testing.T, file string) *v1.ClusterOperatorList {
+	bin, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	var list v1.ClusterOperatorList
+	require.NoError(t, json.Unmarshal(bin, &list))
+	return &list
+}
+
+func TestWaitForStableOperators(t *testing.T) {
+	progressing := readOperators(t, "testdata/co-progressing.json")
+	progressing.ResourceVersion = "10"
+	available := readOperators(t, "testdata/co.json")
+
+	client := &fakeOperatorWatcher{
+		list:     progressing,
+		watchers: make(chan *watch.FakeWatcher, 2),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	done := make(chan error)
+	go func() {
+		done <- (&operatorsTracker{}).waitForStable(ctx, client, 100*time.Millisecond)
+	}()
+
+	// the first watch ends before the operators are ready, it is resumed
+	watcher := <-client.watchers
+	authentication := progressing.Items[0].DeepCopy()
+	authentication.ResourceVersion = "11"
+	watcher.Modify(authentication)
+	watcher.Stop()
+
+	watcher = <-client.watchers
+	for i := range available.Items {
+		operator := available.Items[i].DeepCopy()
+		operator.ResourceVersion = "12"
+		watcher.Modify(operator)
+	}
+
+	assert.NoError(t, <-done)
+	assert.Equal(t, []string{"10", "11"}, client.resourceVersions)
+}
+
+func TestWaitForStableOperatorsTimeout(t *testing.T) {
+	client := &fakeOperatorWatcher{
+		list:     readOperators(t, "testdata/co-progressing.json"),
+		watchers: make(chan *watch.FakeWatcher, 1),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	tracker := &operatorsTracker{}
+	assert.Equal(t, context.DeadlineExceeded, tracker.waitForStable(ctx, client, 100*time.Millisecond))
+	assert.Equal(t, "authentication is progressing (AsExpected)", tracker.diagnostics())
+}
+
+func TestOperatorsTrackerDiagnosticsWithoutOperators(t *testing.T) {
+	tracker := &operatorsTracker{lastError: errors.New("connection refused")}
+	assert.Equal(t, "the cluster operators could not be listed: connection refused", tracker.diagnostics())
+}
+
+func TestNotReadyOperatorsWithSelector(t *testing.T) {
+	operators := readOperators(t, "testdata/co-progressing.json").Items
+	assert.Equal(t, []string{"authentication is progressing (AsExpected)", "foo is not created"},
+		notReadyOperators(operators, []string{"authentication", "foo", "cloud-credential"}))
+}
+
+func TestOperatorsProgress(t *testing.T) {
+	operators := []v1.ClusterOperator{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "kube-apiserver"},
+			Status: v1.ClusterOperatorStatus{Conditions: []v1.ClusterOperatorStatusCondition{
+				{Type: v1.OperatorAvailable, Status: v1.ConditionTrue},
+				{Type: v1.OperatorProgressing, Status: v1.ConditionTrue, Reason: "NodeInstaller", Message: "NodeInstallerProgressing: 1 nodes are at revision 11\nmore details"},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "dns"},
+			Status: v1.ClusterOperatorStatus{Conditions: []v1.ClusterOperatorStatusCondition{
+				{Type: v1.OperatorDegraded, Status: v1.ConditionTrue, Reason: "DNSDegraded"},
+			}},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "network"},
+			Status: v1.ClusterOperatorStatus{Conditions: []v1.ClusterOperatorStatusCondition{
+				{Type: v1.OperatorAvailable, Status: v1.ConditionTrue},
+				{Type: v1.OperatorProgressing, Status: v1.ConditionFalse},
+			}},
+		},
+	}
+	tracker := &operatorsTracker{operators: map[string]v1.ClusterOperator{}}
+	for _, operator := range operators {
+		tracker.operators[operator.Name] = operator
+	}
+	assert.Equal(t, []string{
+		"dns is degraded: DNSDegraded",
+		"kube-apiserver is progressing: NodeInstallerProgressing: 1 nodes are at revision 11",
+	}, tracker.progress())
+
+	tracker.selector = []string{"network", "dns"}
+	assert.Equal(t, []string{"dns is degraded: DNSDegraded"}, tracker.progress())
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+const bundleExtension = ".crcbundle"
+
+// ParseBundleURLs parses the comma-separated list of the mirrors of the
+// bundle, which are tried in order. The mirrors must serve the same file.
+func ParseBundleURLs(input string) ([]string, error) {
+	var urls []string
+	if strings.TrimSpace(input) == "" {
+		return urls, nil
+	}
+	var bundleName string
+	for _, item := range strings.Split(input, ",") {
+		item = strings.TrimSpace(item)
+		u, err := url.Parse(item)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("'%s' is not a valid bundle URL, expected an http or https URL", item)
+		}
+		name := path.Base(u.Path)
+		if !strings.HasSuffix(name, bundleExtension) {
+			return nil, fmt.Errorf("'%s' is not a valid bundle URL, the file name must end with %s", item, bundleExtension)
+		}
+		if bundleName != "" && name != bundleName {
+			return nil, fmt.Errorf("The bundle mirrors must serve the same file, got %s and %s", bundleName, name)
+		}
+		bundleName = name
+		urls = append(urls, item)
+	}
+	return urls, nil
+}
+
+// BundleURLFileName returns the file name of the bundle downloaded from urls
+func BundleURLFileName(urls []string) string {
+	if len(urls) == 0 {
+		return ""
+	}
+	u, err := url.Parse(urls[0])
+	if err != nil {
+		return ""
+	}
+	return path.Base(u.Path)
+}
+
+func ValidateBundleURLs(value interface{}) (bool, string) {
+	if _, err := ParseBundleURLs(cast.ToString(value)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// ValidateSHA256 checks that value is empty or a hex-encoded sha256 sum
+func ValidateSHA256(value interface{}) (bool, string) {
+	sum := cast.ToString(value)
+	if sum == "" {
+		return true, ""
+	}
+	if decoded, err := hex.DecodeString(sum); err != nil || len(decoded) != 32 {
+		return false, "expected a sha256 sum of 64 hexadecimal characters"
+	}
+	return true, ""
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBundleURLs(t *testing.T) {
+	urls, err := ParseBundleURLs("")
+	require.NoError(t, err)
+	assert.Empty(t, urls)
+
+	urls, err = ParseBundleURLs("https://mirror.example.com/crc/crc_libvirt_4.7.5.crcbundle, http://10.0.0.2/crc_libvirt_4.7.5.crcbundle")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://mirror.example.com/crc/crc_libvirt_4.7.5.crcbundle", "http://10.0.0.2/crc_libvirt_4.7.5.crcbundle"}, urls)
+	assert.Equal(t, "crc_libvirt_4.7.5.crcbundle", BundleURLFileName(urls))
+
+	_, err = ParseBundleURLs("ftp://mirror.example.com/crc_libvirt_4.7.5.crcbundle")
+	assert.Error(t, err)
+	_, err = ParseBundleURLs("https://mirror.example.com/crc_libvirt_4.7.5.tar.xz")
+	assert.Error(t, err)
+	_, err = ParseBundleURLs("https://a.example.com/crc_libvirt_4.7.5.crcbundle,https://b.example.com/crc_libvirt_4.7.6.crcbundle")
+	assert.Error(t, err)
+}
+
+func TestValidateSHA256(t *testing.T) {
+	valid, _ := ValidateSHA256("")
+	assert.True(t, valid)
+	valid, _ = ValidateSHA256("e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855")
+	assert.True(t, valid)
+	valid, _ = ValidateSHA256("e3b0c442")
+	assert.False(t, valid)
+	valid, _ = ValidateSHA256("not-a-checksum")
+	assert.False(t, valid)
+}
@@ -12,10 +12,27 @@ func RequiresRestartMsg(key string, _ interface{}) string {
 		"stop the CRC instance with 'crc stop' and restart it with 'crc start'.", key)
 }
 
+func RequiresDeleteMsg(key string, _ interface{}) string {
+	return fmt.Sprintf("Changes to configuration property '%s' are only applied when the CRC instance is created.\n"+
+		"If you already have a CRC instance, then for this configuration change to take effect, "+
+		"delete the CRC instance with 'crc delete' and start it with 'crc start'.", key)
+}
+
 func SuccessfullyApplied(key string, value interface{}) string {
 	return fmt.Sprintf("Successfully configured %s to %s", key, cast.ToString(value))
 }
 
+// SuccessfullyAppliedSecret does not show the value of the setting, which
+// is a credential
+func SuccessfullyAppliedSecret(key string, _ interface{}) string {
+	return fmt.Sprintf("Successfully configured %s", key)
+}
+
+func RequiresDaemonRestartMsg(key string, _ interface{}) string {
+	return fmt.Sprintf("Changes to configuration property '%s' are only applied when 'crc daemon' is started.\n"+
+		"If 'crc daemon' is already running, then for this configuration change to take effect, stop it and start it again.", key)
+}
+
 func RequiresCRCSetup(key string, _ interface{}) string {
 	return fmt.Sprintf("Changes to configuration property '%s' are only applied during 'crc setup'.\n"+
 		"Please run 'crc setup' for this configuration to take effect.", key)
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/spf13/cast"
+)
+
+// ClusterIDPolicy tells which ID the cluster reports to Insights and Telemetry
+type ClusterIDPolicy string
+
+const (
+	// RandomClusterID keeps the ID of the cluster, a new one is generated
+	// when the instance is created
+	RandomClusterID ClusterIDPolicy = "random"
+	// PreserveClusterID reuses the same ID when the instance is deleted and
+	// created again, for a stable identity
+	PreserveClusterID ClusterIDPolicy = "preserve"
+	// AnonymousClusterID generates a new ID at every start and removes the
+	// credentials of the account of the user with which the cluster sends
+	// its Insights and Telemetry reports, so that it sends none
+	AnonymousClusterID ClusterIDPolicy = "anonymous"
+)
+
+func parseClusterIDPolicy(input string) (ClusterIDPolicy, error) {
+	switch input {
+	case string(RandomClusterID), "":
+		return RandomClusterID, nil
+	case string(PreserveClusterID):
+		return PreserveClusterID, nil
+	case string(AnonymousClusterID):
+		return AnonymousClusterID, nil
+	default:
+		return RandomClusterID, fmt.Errorf("Cannot parse cluster ID policy '%s'", input)
+	}
+}
+
+func ValidateClusterIDPolicy(value interface{}) (bool, string) {
+	if _, err := parseClusterIDPolicy(cast.ToString(value)); err != nil {
+		return false, fmt.Sprintf("cluster ID policy should be either %s, %s or %s", RandomClusterID, PreserveClusterID, AnonymousClusterID)
+	}
+	return true, ""
+}
+
+func GetClusterIDPolicy(config Storage) ClusterIDPolicy {
+	input := config.Get(ClusterIdentity).AsString()
+	policy, err := parseClusterIDPolicy(input)
+	if err != nil {
+		logging.Errorf("unexpected cluster ID policy %s, using default", input)
+	}
+	return policy
+}
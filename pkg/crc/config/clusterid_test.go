@@ -0,0 +1,23 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterIDPolicy(t *testing.T) {
+	cfg := New(NewEmptyInMemoryStorage())
+	RegisterSettings(cfg)
+
+	assert.Equal(t, RandomClusterID, GetClusterIDPolicy(cfg))
+	_, err := cfg.Set(ClusterIdentity, "preserve")
+	assert.NoError(t, err)
+	assert.Equal(t, PreserveClusterID, GetClusterIDPolicy(cfg))
+	_, err = cfg.Set(ClusterIdentity, "anonymous")
+	assert.NoError(t, err)
+	assert.Equal(t, AnonymousClusterID, GetClusterIDPolicy(cfg))
+
+	_, err = cfg.Set(ClusterIdentity, "fixed")
+	assert.Error(t, err)
+}
@@ -1,15 +1,20 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
 
+	"github.com/code-ready/crc/pkg/crc/credentials/keyring"
+	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/spf13/cast"
 )
 
 const (
 	configPropDoesntExistMsg = "Configuration property '%s' does not exist"
 	invalidProp              = "Value '%v' for configuration property '%s' is invalid, reason: %s"
+
+	maskedValue = "********"
 )
 
 type Config struct {
@@ -57,6 +62,44 @@ func (c *Config) AddSetting(name string, defValue interface{}, validationFn Vali
 	}
 }
 
+// AddSecretSetting adds a setting like AddSetting, its value is kept in the
+// keyring of the OS instead of the configuration file when the keyring is
+// available
+func (c *Config) AddSecretSetting(name string, defValue interface{}, validationFn ValidationFnType, callbackFn SetFn, help string) {
+	c.AddSetting(name, defValue, validationFn, callbackFn, help)
+	setting := c.settingsByName[name]
+	setting.secret = true
+	c.settingsByName[name] = setting
+}
+
+func secretKeyringKey(key string) string {
+	return "config-" + key
+}
+
+// setSecret stores the value of a secret setting in the keyring and removes
+// it from the configuration file, it is written to the configuration file
+// when the keyring is not available
+func (c *Config) setSecret(key string, value interface{}) error {
+	if err := keyring.Set(secretKeyringKey(key), cast.ToString(value)); err != nil {
+		logging.Debugf("Cannot store %s in the keyring, it is written to the configuration file: %v", key, err)
+		return c.storage.Set(key, value)
+	}
+	return c.storage.Unset(key)
+}
+
+// getSecret returns the value of a secret setting, the configuration file
+// and the environment are used when the keyring has none
+func (c *Config) getSecret(key string) interface{} {
+	value, err := keyring.Get(secretKeyringKey(key))
+	if err == nil {
+		return value
+	}
+	if !errors.Is(err, keyring.ErrNotFound) {
+		logging.Debugf("Cannot read %s from the keyring: %v", key, err)
+	}
+	return c.storage.Get(key)
+}
+
 // Set sets the value for a given config key
 func (c *Config) Set(key string, value interface{}) (string, error) {
 	setting, ok := c.settingsByName[key]
@@ -86,7 +129,12 @@ func (c *Config) Set(key string, value interface{}) (string, error) {
 		}
 	}
 
-	if err := c.storage.Set(key, castValue); err != nil {
+	if setting.secret {
+		err = c.setSecret(key, castValue)
+	} else {
+		err = c.storage.Set(key, castValue)
+	}
+	if err != nil {
 		return "", err
 	}
 
@@ -95,11 +143,16 @@ func (c *Config) Set(key string, value interface{}) (string, error) {
 
 // Unset unsets a given config key
 func (c *Config) Unset(key string) (string, error) {
-	_, ok := c.settingsByName[key]
+	setting, ok := c.settingsByName[key]
 	if !ok {
 		return "", fmt.Errorf(configPropDoesntExistMsg, key)
 	}
 
+	if setting.secret {
+		if err := keyring.Delete(secretKeyringKey(key)); err != nil {
+			logging.Debugf("Cannot remove %s from the keyring: %v", key, err)
+		}
+	}
 	if err := c.storage.Unset(key); err != nil {
 		return "", err
 	}
@@ -114,8 +167,14 @@ func (c *Config) Get(key string) SettingValue {
 			Invalid: true,
 		}
 	}
-	value := c.storage.Get(key)
-	if value == nil {
+	var value interface{}
+	if setting.secret {
+		value = c.getSecret(key)
+	} else {
+		value = c.storage.Get(key)
+	}
+	isSet := value != nil
+	if !isSet {
 		value = setting.defaultValue
 	}
 	var err error
@@ -144,5 +203,7 @@ func (c *Config) Get(key string) SettingValue {
 	return SettingValue{
 		Value:     value,
 		IsDefault: reflect.DeepEqual(setting.defaultValue, value),
+		IsSecret:  setting.secret,
+		IsSet:     isSet,
 	}
 }
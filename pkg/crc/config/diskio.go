@@ -0,0 +1,137 @@
+package config
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/spf13/cast"
+)
+
+// DiskCache is the host page cache policy of the VM disk, as understood by qemu
+type DiskCache string
+
+const (
+	DefaultDiskCache      DiskCache = "default"
+	NoDiskCache           DiskCache = "none"
+	WritethroughDiskCache DiskCache = "writethrough"
+	WritebackDiskCache    DiskCache = "writeback"
+	DirectsyncDiskCache   DiskCache = "directsync"
+	// UnsafeDiskCache ignores the flush requests of the guest, the disk
+	// image may be corrupted when the host crashes
+	UnsafeDiskCache DiskCache = "unsafe"
+)
+
+var diskCacheModes = []DiskCache{DefaultDiskCache, NoDiskCache, WritethroughDiskCache, WritebackDiskCache, DirectsyncDiskCache, UnsafeDiskCache}
+
+// DiskIO is how qemu submits the IO requests of the VM disk to the host
+type DiskIO string
+
+const (
+	ThreadsDiskIO DiskIO = "threads"
+	// NativeDiskIO uses Linux AIO, it requires the host page cache to be bypassed
+	NativeDiskIO  DiskIO = "native"
+	IOUringDiskIO DiskIO = "io_uring"
+)
+
+var diskIOModes = []DiskIO{ThreadsDiskIO, NativeDiskIO, IOUringDiskIO}
+
+// DiskIOTuning are the driver settings of the VM disk
+type DiskIOTuning struct {
+	CacheMode DiskCache
+	IOMode    DiskIO
+}
+
+func parseDiskCacheMode(input string) (DiskCache, error) {
+	for _, mode := range diskCacheModes {
+		if input == string(mode) {
+			return mode, nil
+		}
+	}
+	return DefaultDiskCache, fmt.Errorf("Cannot parse disk cache mode '%s'", input)
+}
+
+func parseDiskIOMode(input string) (DiskIO, error) {
+	for _, mode := range diskIOModes {
+		if input == string(mode) {
+			return mode, nil
+		}
+	}
+	return ThreadsDiskIO, fmt.Errorf("Cannot parse disk IO mode '%s'", input)
+}
+
+func (tuning DiskIOTuning) validate() error {
+	if tuning.IOMode == NativeDiskIO && tuning.CacheMode != NoDiskCache && tuning.CacheMode != DirectsyncDiskCache {
+		return fmt.Errorf("disk IO mode %s requires disk cache mode %s or %s", NativeDiskIO, NoDiskCache, DirectsyncDiskCache)
+	}
+	return nil
+}
+
+func validateDiskIOSupported() (bool, string) {
+	if runtime.GOOS != "linux" {
+		return false, "Disk IO tuning is only supported on Linux"
+	}
+	return true, ""
+}
+
+func diskIOTuning(cacheMode, ioMode string, fastUnsafe bool) (DiskIOTuning, error) {
+	cache, err := parseDiskCacheMode(cacheMode)
+	if err != nil {
+		return DiskIOTuning{}, err
+	}
+	io, err := parseDiskIOMode(ioMode)
+	if err != nil {
+		return DiskIOTuning{}, err
+	}
+	tuning := DiskIOTuning{
+		CacheMode: cache,
+		IOMode:    io,
+	}
+	if fastUnsafe {
+		tuning = DiskIOTuning{
+			CacheMode: UnsafeDiskCache,
+			IOMode:    ThreadsDiskIO,
+		}
+	}
+	return tuning, tuning.validate()
+}
+
+// GetDiskIOTuning returns the disk settings of the VM, disk-fast-unsafe
+// overrides the other ones
+func GetDiskIOTuning(config Storage) DiskIOTuning {
+	tuning, err := diskIOTuning(config.Get(DiskCacheMode).AsString(), config.Get(DiskIOMode).AsString(), config.Get(DiskFastUnsafe).AsBool())
+	if err != nil {
+		logging.Errorf("unexpected disk IO settings: %v, using default", err)
+		return DiskIOTuning{
+			CacheMode: DefaultDiskCache,
+			IOMode:    ThreadsDiskIO,
+		}
+	}
+	return tuning
+}
+
+func diskIOValidator(config Storage, key string) ValidationFnType {
+	return func(value interface{}) (bool, string) {
+		if ok, msg := validateDiskIOSupported(); !ok {
+			return ok, msg
+		}
+		cacheMode := config.Get(DiskCacheMode).AsString()
+		ioMode := config.Get(DiskIOMode).AsString()
+		fastUnsafe := config.Get(DiskFastUnsafe).AsBool()
+		switch key {
+		case DiskCacheMode:
+			cacheMode = cast.ToString(value)
+		case DiskIOMode:
+			ioMode = cast.ToString(value)
+		case DiskFastUnsafe:
+			if ok, msg := ValidateBool(value); !ok {
+				return ok, msg
+			}
+			fastUnsafe = cast.ToBool(value)
+		}
+		if _, err := diskIOTuning(cacheMode, ioMode, fastUnsafe); err != nil {
+			return false, err.Error()
+		}
+		return true, ""
+	}
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiskIOTuning(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("disk IO tuning is only supported on Linux")
+	}
+	cfg := New(NewEmptyInMemoryStorage())
+	RegisterSettings(cfg)
+
+	assert.Equal(t, DiskIOTuning{CacheMode: DefaultDiskCache, IOMode: ThreadsDiskIO}, GetDiskIOTuning(cfg))
+
+	_, err := cfg.Set(DiskIOMode, "native")
+	assert.EqualError(t, err, "Value 'native' for configuration property 'disk-io-mode' is invalid, reason: disk IO mode native requires disk cache mode none or directsync")
+	_, err = cfg.Set(DiskCacheMode, "none")
+	assert.NoError(t, err)
+	_, err = cfg.Set(DiskIOMode, "native")
+	assert.NoError(t, err)
+	assert.Equal(t, DiskIOTuning{CacheMode: NoDiskCache, IOMode: NativeDiskIO}, GetDiskIOTuning(cfg))
+
+	_, err = cfg.Set(DiskCacheMode, "writeback")
+	assert.Error(t, err)
+	_, err = cfg.Set(DiskCacheMode, "fast")
+	assert.Error(t, err)
+
+	_, err = cfg.Set(DiskFastUnsafe, true)
+	assert.NoError(t, err)
+	assert.Equal(t, DiskIOTuning{CacheMode: UnsafeDiskCache, IOMode: ThreadsDiskIO}, GetDiskIOTuning(cfg))
+}
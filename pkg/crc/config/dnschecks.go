@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/spf13/cast"
+)
+
+// CheckSeverity tells what a failing DNS check does to the start
+type CheckSeverity string
+
+const (
+	// FailCheck stops the start
+	FailCheck CheckSeverity = "fail"
+	// WarnCheck logs a warning and continues the start
+	WarnCheck CheckSeverity = "warn"
+	// SkipCheck does not run the check
+	SkipCheck CheckSeverity = "skip"
+)
+
+func parseCheckSeverity(input string) (CheckSeverity, error) {
+	switch input {
+	case string(FailCheck):
+		return FailCheck, nil
+	case string(WarnCheck):
+		return WarnCheck, nil
+	case string(SkipCheck):
+		return SkipCheck, nil
+	default:
+		return "", fmt.Errorf("Cannot parse check severity '%s'", input)
+	}
+}
+
+// ValidateCheckSeverity accepts an empty value, which is the default severity
+// of the check
+func ValidateCheckSeverity(value interface{}) (bool, string) {
+	input := cast.ToString(value)
+	if input == "" {
+		return true, ""
+	}
+	if _, err := parseCheckSeverity(input); err != nil {
+		return false, fmt.Sprintf("check severity should be either %s, %s or %s", FailCheck, WarnCheck, SkipCheck)
+	}
+	return true, ""
+}
+
+// GetDNSCheckSeverity returns the severity of the DNS check of key. The
+// internal and host checks fail by default, they only warn with the user
+// network mode in which the DNS of the host is not configured for the
+// cluster. The public check only warns by default.
+func GetDNSCheckSeverity(config Storage, key string) CheckSeverity {
+	input := config.Get(key).AsString()
+	if input != "" {
+		severity, err := parseCheckSeverity(input)
+		if err == nil {
+			return severity
+		}
+		logging.Errorf("unexpected severity %s of %s, using default", input, key)
+	}
+	if key == DNSCheckPublic || GetNetworkMode(config) == network.UserNetworkingMode {
+		return WarnCheck
+	}
+	return FailCheck
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDNSCheckSeverity(t *testing.T) {
+	cfg := New(NewEmptyInMemoryStorage())
+	RegisterSettings(cfg)
+
+	_, err := cfg.Set(NetworkMode, string(network.SystemNetworkingMode))
+	assert.NoError(t, err)
+	assert.Equal(t, FailCheck, GetDNSCheckSeverity(cfg, DNSCheckInternal))
+	assert.Equal(t, FailCheck, GetDNSCheckSeverity(cfg, DNSCheckHost))
+	assert.Equal(t, WarnCheck, GetDNSCheckSeverity(cfg, DNSCheckPublic))
+
+	_, err = cfg.Set(NetworkMode, string(network.UserNetworkingMode))
+	assert.NoError(t, err)
+	assert.Equal(t, WarnCheck, GetDNSCheckSeverity(cfg, DNSCheckInternal))
+	assert.Equal(t, WarnCheck, GetDNSCheckSeverity(cfg, DNSCheckHost))
+
+	_, err = cfg.Set(DNSCheckHost, "skip")
+	assert.NoError(t, err)
+	assert.Equal(t, SkipCheck, GetDNSCheckSeverity(cfg, DNSCheckHost))
+	_, err = cfg.Set(DNSCheckPublic, "fail")
+	assert.NoError(t, err)
+	assert.Equal(t, FailCheck, GetDNSCheckSeverity(cfg, DNSCheckPublic))
+
+	_, err = cfg.Set(DNSCheckInternal, "ignore")
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/spf13/cast"
+)
+
+// DNSServiceMode tells where the cluster hostnames are resolved for the host
+type DNSServiceMode string
+
+const (
+	// VMDNSService resolves them with the DNS server running in the VM, the
+	// resolver of the host forwards the queries of the cluster domains to it
+	VMDNSService DNSServiceMode = "vm"
+	// HostDNSService resolves them with 'crc daemon' on the loopback
+	// interface, the host does not need to reach the port 53 of the VM
+	HostDNSService DNSServiceMode = "host"
+)
+
+func parseDNSService(input string) (DNSServiceMode, error) {
+	switch input {
+	case string(VMDNSService), "":
+		return VMDNSService, nil
+	case string(HostDNSService):
+		return HostDNSService, nil
+	default:
+		return VMDNSService, fmt.Errorf("Cannot parse DNS service '%s'", input)
+	}
+}
+
+func validateDNSService(cfg Storage) ValidationFnType {
+	return func(value interface{}) (bool, string) {
+		service, err := parseDNSService(cast.ToString(value))
+		if err != nil {
+			return false, fmt.Sprintf("DNS service should be either %s or %s", VMDNSService, HostDNSService)
+		}
+		if service != HostDNSService {
+			return true, ""
+		}
+		if runtime.GOOS == "windows" {
+			return false, "the host DNS service is not supported on Windows"
+		}
+		if GetNetworkMode(cfg) != network.SystemNetworkingMode {
+			return false, fmt.Sprintf("the host DNS service can only be used with %s set to '%s', the %s network mode always resolves the cluster hostnames on the host",
+				NetworkMode, network.SystemNetworkingMode, network.UserNetworkingMode)
+		}
+		return true, ""
+	}
+}
+
+func GetDNSService(config Storage) DNSServiceMode {
+	input := config.Get(DNSService).AsString()
+	service, err := parseDNSService(input)
+	if err != nil {
+		logging.Errorf("unexpected DNS service %s, using default", input)
+	}
+	return service
+}
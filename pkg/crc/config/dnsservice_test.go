@@ -0,0 +1,33 @@
+package config
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDNSService(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the host DNS service is not supported on Windows")
+	}
+	cfg := New(NewEmptyInMemoryStorage())
+	RegisterSettings(cfg)
+
+	assert.Equal(t, VMDNSService, GetDNSService(cfg))
+
+	_, err := cfg.Set(NetworkMode, string(network.UserNetworkingMode))
+	assert.NoError(t, err)
+	_, err = cfg.Set(DNSService, "host")
+	assert.Error(t, err)
+
+	_, err = cfg.Set(NetworkMode, string(network.SystemNetworkingMode))
+	assert.NoError(t, err)
+	_, err = cfg.Set(DNSService, "host")
+	assert.NoError(t, err)
+	assert.Equal(t, HostDNSService, GetDNSService(cfg))
+
+	_, err = cfg.Set(DNSService, "daemon")
+	assert.Error(t, err)
+}
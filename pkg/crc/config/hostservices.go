@@ -0,0 +1,44 @@
+package config
+
+import (
+	"strings"
+)
+
+// GetHostServices returns the host services started and stopped with the VM
+func GetHostServices(config Storage) []string {
+	var services []string
+	for _, service := range strings.Split(config.Get(HostServices).AsString(), ",") {
+		if service = strings.TrimSpace(service); service != "" {
+			services = append(services, service)
+		}
+	}
+	return services
+}
+
+// AddHostService registers service to be started and stopped with the VM
+func AddHostService(config Storage, service string) error {
+	services := GetHostServices(config)
+	for _, existing := range services {
+		if existing == service {
+			return nil
+		}
+	}
+	_, err := config.Set(HostServices, strings.Join(append(services, service), ","))
+	return err
+}
+
+// RemoveHostService unregisters service
+func RemoveHostService(config Storage, service string) error {
+	var services []string
+	for _, existing := range GetHostServices(config) {
+		if existing != service {
+			services = append(services, existing)
+		}
+	}
+	if len(services) == 0 {
+		_, err := config.Unset(HostServices)
+		return err
+	}
+	_, err := config.Set(HostServices, strings.Join(services, ","))
+	return err
+}
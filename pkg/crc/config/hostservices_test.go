@@ -0,0 +1,25 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostServices(t *testing.T) {
+	cfg := New(NewEmptyInMemoryStorage())
+	RegisterSettings(cfg)
+
+	assert.Empty(t, GetHostServices(cfg))
+	assert.NoError(t, AddHostService(cfg, "crc-proxy.service"))
+	assert.NoError(t, AddHostService(cfg, "port-forward.service"))
+	assert.NoError(t, AddHostService(cfg, "crc-proxy.service"))
+	assert.Equal(t, []string{"crc-proxy.service", "port-forward.service"}, GetHostServices(cfg))
+
+	assert.NoError(t, RemoveHostService(cfg, "crc-proxy.service"))
+	assert.Equal(t, []string{"port-forward.service"}, GetHostServices(cfg))
+	assert.NoError(t, RemoveHostService(cfg, "port-forward.service"))
+	assert.True(t, cfg.Get(HostServices).IsDefault)
+
+	assert.Error(t, AddHostService(cfg, "/etc/systemd/system/crc.service"))
+}
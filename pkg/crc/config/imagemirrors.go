@@ -0,0 +1,54 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+)
+
+// ParseImageMirrors parses a comma-separated list of 'source=mirror' pairs,
+// such as 'quay.io/openshift-release-dev=registry.corp.example/ocp4', a source
+// can be listed several times to have several mirrors
+func ParseImageMirrors(input string) ([]bundle.ImageContentSource, error) {
+	var sources []bundle.ImageContentSource
+	indexes := make(map[string]int)
+	if strings.TrimSpace(input) == "" {
+		return sources, nil
+	}
+	for _, item := range strings.Split(input, ",") {
+		parts := strings.Split(strings.TrimSpace(item), "=")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("'%s' is not a valid image mirror, expected 'source=mirror'", item)
+		}
+		for _, repository := range parts {
+			if !isValidRepository(repository) {
+				return nil, fmt.Errorf("'%s' is not a valid repository, tags and digests are not allowed", repository)
+			}
+		}
+		if index, ok := indexes[parts[0]]; ok {
+			sources[index].Mirrors = append(sources[index].Mirrors, parts[1])
+			continue
+		}
+		indexes[parts[0]] = len(sources)
+		sources = append(sources, bundle.ImageContentSource{
+			Source:  parts[0],
+			Mirrors: []string{parts[1]},
+		})
+	}
+	return sources, nil
+}
+
+// isValidRepository tells if repository has no tag or digest, a ':' is only
+// allowed in the registry host, as in 'registry.corp.example:5000/ocp4'
+func isValidRepository(repository string) bool {
+	if strings.ContainsAny(repository, " \t@") {
+		return false
+	}
+	parts := strings.SplitN(repository, "/", 2)
+	return len(parts) == 1 || !strings.Contains(parts[1], ":")
+}
+
+func GetImageMirrors(config Storage) ([]bundle.ImageContentSource, error) {
+	return ParseImageMirrors(config.Get(ImageMirrors).AsString())
+}
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImageMirrors(t *testing.T) {
+	sources, err := ParseImageMirrors("quay.io/openshift-release-dev/ocp-release=registry.corp.example:5000/ocp4/release, quay.io/openshift-release-dev/ocp-release=mirror.corp.example/ocp4/release,registry.redhat.io=registry.corp.example:5000")
+	require.NoError(t, err)
+	assert.Equal(t, []bundle.ImageContentSource{
+		{
+			Source:  "quay.io/openshift-release-dev/ocp-release",
+			Mirrors: []string{"registry.corp.example:5000/ocp4/release", "mirror.corp.example/ocp4/release"},
+		},
+		{
+			Source:  "registry.redhat.io",
+			Mirrors: []string{"registry.corp.example:5000"},
+		},
+	}, sources)
+
+	sources, err = ParseImageMirrors("")
+	assert.NoError(t, err)
+	assert.Empty(t, sources)
+
+	_, err = ParseImageMirrors("quay.io/openshift-release-dev")
+	assert.EqualError(t, err, "'quay.io/openshift-release-dev' is not a valid image mirror, expected 'source=mirror'")
+	_, err = ParseImageMirrors("quay.io/openshift-release-dev/ocp-release:4.8.2=registry.corp.example/ocp4")
+	assert.EqualError(t, err, "'quay.io/openshift-release-dev/ocp-release:4.8.2' is not a valid repository, tags and digests are not allowed")
+}
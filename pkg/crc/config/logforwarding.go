@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+// LogForwardingTarget tells where the logs of the cluster are sent, at most one of
+// LokiURL and Directory is set
+type LogForwardingTarget struct {
+	// push endpoint of a Loki server reachable from the VM
+	LokiURL *url.URL
+	// host directory where the crc daemon writes the logs
+	Directory string
+}
+
+func (forwarding LogForwardingTarget) IsEnabled() bool {
+	return forwarding.LokiURL != nil || forwarding.Directory != ""
+}
+
+// ParseLogForwarding parses an http(s) URL of a Loki server, or the absolute
+// path of a host directory, an empty input disables log forwarding
+func ParseLogForwarding(input string) (LogForwardingTarget, error) {
+	input = strings.TrimSpace(input)
+	switch {
+	case input == "":
+		return LogForwardingTarget{}, nil
+	case strings.HasPrefix(input, "http://"), strings.HasPrefix(input, "https://"):
+		lokiURL, err := url.Parse(input)
+		if err != nil || lokiURL.Hostname() == "" {
+			return LogForwardingTarget{}, fmt.Errorf("'%s' is not a valid Loki URL", input)
+		}
+		return LogForwardingTarget{LokiURL: lokiURL}, nil
+	case filepath.IsAbs(input):
+		return LogForwardingTarget{Directory: filepath.Clean(input)}, nil
+	default:
+		return LogForwardingTarget{}, fmt.Errorf("'%s' is neither a Loki URL nor an absolute directory path", input)
+	}
+}
+
+func GetLogForwarding(config Storage) LogForwardingTarget {
+	input := config.Get(LogForwarding).AsString()
+	forwarding, err := ParseLogForwarding(input)
+	if err != nil {
+		logging.Errorf("unexpected log forwarding %s, log forwarding is disabled", input)
+	}
+	return forwarding
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLogForwarding(t *testing.T) {
+	target, err := ParseLogForwarding("")
+	require.NoError(t, err)
+	assert.False(t, target.IsEnabled())
+
+	target, err = ParseLogForwarding("http://192.168.130.1:3100")
+	require.NoError(t, err)
+	assert.True(t, target.IsEnabled())
+	assert.Equal(t, "192.168.130.1", target.LokiURL.Hostname())
+	assert.Empty(t, target.Directory)
+
+	_, err = ParseLogForwarding("http://")
+	assert.Error(t, err)
+	_, err = ParseLogForwarding("relative/logs")
+	assert.Error(t, err)
+}
+
+func TestLogForwardingToDirectoryRequiresUserNetworking(t *testing.T) {
+	cfg := New(NewEmptyInMemoryStorage())
+	RegisterSettings(cfg)
+
+	_, err := cfg.Set(NetworkMode, string(network.SystemNetworkingMode))
+	require.NoError(t, err)
+	_, err = cfg.Set(LogForwarding, "/var/log/crc")
+	assert.Error(t, err)
+	_, err = cfg.Set(LogForwarding, "http://192.168.130.1:3100")
+	assert.NoError(t, err)
+
+	_, err = cfg.Set(NetworkMode, string(network.UserNetworkingMode))
+	require.NoError(t, err)
+	_, err = cfg.Set(LogForwarding, "/var/log/crc")
+	assert.NoError(t, err)
+	assert.Equal(t, "/var/log/crc", GetLogForwarding(cfg).Directory)
+}
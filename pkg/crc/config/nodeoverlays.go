@@ -0,0 +1,148 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cast"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const maxKubeletLogLevel = 10
+
+// NodeOverlays are the kubelet and crio settings of the VM which override the
+// ones of the bundle, for testing node-level settings
+type NodeOverlays struct {
+	// fields merged into the KubeletConfiguration of the node, such as maxPods
+	KubeletConfig map[string]interface{}
+	// verbosity of the kubelet, the one of the bundle is kept when empty
+	KubeletLogLevel string
+	// crio.conf drop-in, such as pids_limit or log_level in [crio.runtime]
+	CrioConfig string
+}
+
+// the keys of the kubelet configuration which cannot be overridden
+var reservedKubeletConfigKeys = []string{"kind", "apiVersion"}
+
+// ReadKubeletConfigOverlay reads a YAML or JSON file with KubeletConfiguration fields
+func ReadKubeletConfigOverlay(path string) (map[string]interface{}, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseKubeletConfigOverlay(data)
+}
+
+func parseKubeletConfigOverlay(data []byte) (map[string]interface{}, error) {
+	jsonData, err := yaml.ToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubelet configuration overlay: %w", err)
+	}
+	overlay := map[string]interface{}{}
+	if err := json.Unmarshal(jsonData, &overlay); err != nil {
+		return nil, fmt.Errorf("kubelet configuration overlay must be a mapping of KubeletConfiguration fields: %w", err)
+	}
+	for _, key := range reservedKubeletConfigKeys {
+		if _, ok := overlay[key]; ok {
+			return nil, fmt.Errorf("kubelet configuration overlay cannot change '%s'", key)
+		}
+	}
+	return overlay, nil
+}
+
+var (
+	crioTableLine    = regexp.MustCompile(`^\[[A-Za-z0-9_.-]+\]$`)
+	crioKeyValueLine = regexp.MustCompile(`^[A-Za-z0-9_-]+\s*=\s*\S`)
+)
+
+// ReadCrioConfigOverlay reads a crio.conf drop-in
+func ReadCrioConfigOverlay(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if err := validateCrioConfigOverlay(string(data)); err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// validateCrioConfigOverlay catches the obvious mistakes, crio fails to start
+// on the other ones
+func validateCrioConfigOverlay(data string) error {
+	for i, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || crioTableLine.MatchString(line) || crioKeyValueLine.MatchString(line) {
+			continue
+		}
+		return fmt.Errorf("invalid crio configuration overlay, line %d is neither a table nor a key/value pair: %s", i+1, line)
+	}
+	return nil
+}
+
+func validateKubeletLogLevel(input string) error {
+	if input == "" {
+		return nil
+	}
+	level, err := strconv.Atoi(input)
+	if err != nil || level < 0 || level > maxKubeletLogLevel {
+		return fmt.Errorf("kubelet log level must be an integer between 0 and %d", maxKubeletLogLevel)
+	}
+	return nil
+}
+
+func ValidateKubeletConfigOverlay(value interface{}) (bool, string) {
+	if ok, msg := ValidatePath(value); !ok {
+		return ok, msg
+	}
+	if _, err := ReadKubeletConfigOverlay(cast.ToString(value)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+func ValidateCrioConfigOverlay(value interface{}) (bool, string) {
+	if ok, msg := ValidatePath(value); !ok {
+		return ok, msg
+	}
+	if _, err := ReadCrioConfigOverlay(cast.ToString(value)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+func ValidateKubeletLogLevel(value interface{}) (bool, string) {
+	if err := validateKubeletLogLevel(cast.ToString(value)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// GetNodeOverlays reads the overlay files configured by the user
+func GetNodeOverlays(config Storage) (NodeOverlays, error) {
+	overlays := NodeOverlays{
+		KubeletLogLevel: config.Get(KubeletLogLevel).AsString(),
+	}
+	if err := validateKubeletLogLevel(overlays.KubeletLogLevel); err != nil {
+		return NodeOverlays{}, err
+	}
+	if path := config.Get(KubeletConfigOverlay).AsString(); path != "" {
+		kubeletConfig, err := ReadKubeletConfigOverlay(path)
+		if err != nil {
+			return NodeOverlays{}, err
+		}
+		overlays.KubeletConfig = kubeletConfig
+	}
+	if path := config.Get(CrioConfigOverlay).AsString(); path != "" {
+		crioConfig, err := ReadCrioConfigOverlay(path)
+		if err != nil {
+			return NodeOverlays{}, err
+		}
+		overlays.CrioConfig = crioConfig
+	}
+	return overlays, nil
+}
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKubeletConfigOverlay(t *testing.T) {
+	overlay, err := parseKubeletConfigOverlay([]byte("maxPods: 300\npodPidsLimit: 8192\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"maxPods": float64(300), "podPidsLimit": float64(8192)}, overlay)
+
+	_, err = parseKubeletConfigOverlay([]byte("- maxPods\n"))
+	assert.Error(t, err)
+	_, err = parseKubeletConfigOverlay([]byte("kind: Pod\n"))
+	assert.EqualError(t, err, "kubelet configuration overlay cannot change 'kind'")
+}
+
+func TestValidateCrioConfigOverlay(t *testing.T) {
+	assert.NoError(t, validateCrioConfigOverlay("# raise the limits\n[crio.runtime]\npids_limit = 4096\nlog_level = \"debug\"\n"))
+	assert.EqualError(t, validateCrioConfigOverlay("[crio.runtime]\npids_limit 4096\n"),
+		"invalid crio configuration overlay, line 2 is neither a table nor a key/value pair: pids_limit 4096")
+}
+
+func TestValidateKubeletLogLevel(t *testing.T) {
+	assert.NoError(t, validateKubeletLogLevel(""))
+	assert.NoError(t, validateKubeletLogLevel("4"))
+	assert.Error(t, validateKubeletLogLevel("11"))
+	assert.Error(t, validateKubeletLogLevel("debug"))
+}
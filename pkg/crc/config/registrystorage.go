@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/spf13/cast"
+)
+
+type RegistryStorageType string
+
+const (
+	// BundleRegistryStorage keeps the storage of the bundle, a persistent
+	// volume in the disk of the VM
+	BundleRegistryStorage RegistryStorageType = "bundle"
+	// DiskRegistryStorage stores the images in a dedicated disk of the VM,
+	// it is kept when the VM is deleted
+	DiskRegistryStorage RegistryStorageType = "disk"
+	// HostDirRegistryStorage stores the images in a host directory shared
+	// into the VM
+	HostDirRegistryStorage RegistryStorageType = "host-dir"
+)
+
+// RegistryStorageTarget is where the internal registry of the cluster stores
+// the images pushed to it
+type RegistryStorageTarget struct {
+	Type RegistryStorageType
+	// HostDir is the host directory of HostDirRegistryStorage
+	HostDir string
+}
+
+// ParseRegistryStorage parses 'bundle', 'disk' or the absolute path of a
+// host directory
+func ParseRegistryStorage(value string) (RegistryStorageTarget, error) {
+	switch value {
+	case "", string(BundleRegistryStorage):
+		return RegistryStorageTarget{Type: BundleRegistryStorage}, nil
+	case string(DiskRegistryStorage):
+		return RegistryStorageTarget{Type: DiskRegistryStorage}, nil
+	}
+	if !filepath.IsAbs(value) {
+		return RegistryStorageTarget{}, fmt.Errorf("'%s' is not '%s', '%s' or the absolute path of a directory", value, BundleRegistryStorage, DiskRegistryStorage)
+	}
+	return RegistryStorageTarget{Type: HostDirRegistryStorage, HostDir: filepath.Clean(value)}, nil
+}
+
+// ValidateRegistryStorage checks if the registry storage is valid and
+// supported on this platform
+func ValidateRegistryStorage(value interface{}) (bool, string) {
+	storage, err := ParseRegistryStorage(cast.ToString(value))
+	if err != nil {
+		return false, err.Error()
+	}
+	if storage.Type == BundleRegistryStorage {
+		return true, ""
+	}
+	if runtime.GOOS != "linux" {
+		return false, fmt.Sprintf("only the '%s' registry storage is supported on %s", BundleRegistryStorage, runtime.GOOS)
+	}
+	if storage.Type == HostDirRegistryStorage {
+		if info, err := os.Stat(storage.HostDir); err != nil || !info.IsDir() {
+			return false, fmt.Sprintf("'%s' is not a directory", storage.HostDir)
+		}
+	}
+	return true, ""
+}
+
+// GetRegistryStorage returns where the internal registry stores the images
+func GetRegistryStorage(config Storage) RegistryStorageTarget {
+	storage, err := ParseRegistryStorage(config.Get(RegistryStorage).AsString())
+	if err != nil {
+		return RegistryStorageTarget{Type: BundleRegistryStorage}
+	}
+	return storage
+}
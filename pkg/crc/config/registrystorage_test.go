@@ -0,0 +1,29 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRegistryStorage(t *testing.T) {
+	for value, expected := range map[string]RegistryStorageTarget{
+		"":                   {Type: BundleRegistryStorage},
+		"bundle":             {Type: BundleRegistryStorage},
+		"disk":               {Type: DiskRegistryStorage},
+		"/srv/crc-registry/": {Type: HostDirRegistryStorage, HostDir: "/srv/crc-registry"},
+	} {
+		storage, err := ParseRegistryStorage(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, storage, value)
+	}
+	_, err := ParseRegistryStorage("registry")
+	assert.EqualError(t, err, "'registry' is not 'bundle', 'disk' or the absolute path of a directory")
+}
+
+func TestGetRegistryStorage(t *testing.T) {
+	cfg := New(NewEmptyInMemoryStorage())
+	RegisterSettings(cfg)
+	assert.Equal(t, RegistryStorageTarget{Type: BundleRegistryStorage}, GetRegistryStorage(cfg))
+}
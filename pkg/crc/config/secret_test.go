@@ -0,0 +1,40 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/credentials/keyring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretSetting(t *testing.T) {
+	keyring.MockInit()
+	storage := NewEmptyInMemoryStorage()
+	cfg := New(storage)
+	RegisterSettings(cfg)
+
+	_, err := cfg.Set(ProxyPassword, "pa55")
+	require.NoError(t, err)
+	// kept in the keyring, not in the configuration file
+	assert.Nil(t, storage.Get(ProxyPassword))
+	password, err := keyring.Get("config-" + ProxyPassword)
+	assert.NoError(t, err)
+	assert.Equal(t, "pa55", password)
+
+	value := cfg.Get(ProxyPassword)
+	assert.Equal(t, "pa55", value.AsString())
+	assert.True(t, value.IsSecret)
+	assert.Equal(t, "********", value.DisplayValue())
+	assert.Equal(t, "********", cfg.AllConfigs()[ProxyPassword].DisplayValue())
+	assert.Equal(t, "", cfg.Get(ProxyUser).DisplayValue())
+
+	_, err = cfg.Unset(ProxyPassword)
+	require.NoError(t, err)
+	assert.Equal(t, "", cfg.Get(ProxyPassword).AsString())
+	assert.Equal(t, "", cfg.Get(ProxyPassword).DisplayValue())
+
+	// the values set before the keyring was used are still read
+	require.NoError(t, storage.Set(ProxyPassword, "legacy"))
+	assert.Equal(t, "legacy", cfg.Get(ProxyPassword).AsString())
+}
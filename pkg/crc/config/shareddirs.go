@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// vmSystemDirs are the directories of the VM which cannot be hidden by a
+// shared directory
+var vmSystemDirs = []string{"/", "/boot", "/dev", "/etc", "/proc", "/run", "/sys", "/sysroot", "/usr", "/var"}
+
+// SharedDir is a host directory shared into the VM
+type SharedDir struct {
+	// Source is the directory of the host
+	Source string
+	// Target is where the directory is mounted in the VM
+	Target string
+}
+
+// ParseSharedDirs parses a comma-separated list of host directories, each
+// one optionally followed by '=' and the directory where it is mounted in
+// the VM. It is mounted at the same path by default, so that the hostPath
+// volumes use the paths of the host.
+func ParseSharedDirs(input string) ([]SharedDir, error) {
+	var sharedDirs []SharedDir
+	targets := make(map[string]bool)
+	if strings.TrimSpace(input) == "" {
+		return sharedDirs, nil
+	}
+	for _, item := range strings.Split(input, ",") {
+		parts := strings.Split(strings.TrimSpace(item), "=")
+		if len(parts) > 2 || parts[0] == "" {
+			return nil, fmt.Errorf("'%s' is not a valid shared directory, expected 'host-dir' or 'host-dir=vm-dir'", item)
+		}
+		sharedDir := SharedDir{Source: filepath.Clean(parts[0]), Target: path.Clean(filepath.ToSlash(parts[0]))}
+		if len(parts) == 2 {
+			sharedDir.Target = path.Clean(parts[1])
+		}
+		if !filepath.IsAbs(sharedDir.Source) {
+			return nil, fmt.Errorf("'%s' is not an absolute path", parts[0])
+		}
+		if !path.IsAbs(sharedDir.Target) {
+			return nil, fmt.Errorf("'%s' is not an absolute path in the VM", sharedDir.Target)
+		}
+		for _, dir := range vmSystemDirs {
+			if sharedDir.Target == dir {
+				return nil, fmt.Errorf("'%s' is a system directory of the VM, it cannot be used as shared directory", dir)
+			}
+		}
+		if targets[sharedDir.Target] {
+			return nil, fmt.Errorf("several directories are shared at '%s'", sharedDir.Target)
+		}
+		targets[sharedDir.Target] = true
+		sharedDirs = append(sharedDirs, sharedDir)
+	}
+	return sharedDirs, nil
+}
+
+// GetSharedDirs returns the host directories shared into the VM
+func GetSharedDirs(config Storage) []SharedDir {
+	sharedDirs, _ := ParseSharedDirs(config.Get(SharedDirs).AsString())
+	return sharedDirs
+}
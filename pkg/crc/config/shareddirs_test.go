@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSharedDirs(t *testing.T) {
+	sharedDirs, err := ParseSharedDirs("/home/user/src, /srv/data/=/mnt/data")
+	require.NoError(t, err)
+	assert.Equal(t, []SharedDir{
+		{Source: "/home/user/src", Target: "/home/user/src"},
+		{Source: "/srv/data", Target: "/mnt/data"},
+	}, sharedDirs)
+
+	sharedDirs, err = ParseSharedDirs("")
+	assert.NoError(t, err)
+	assert.Empty(t, sharedDirs)
+
+	_, err = ParseSharedDirs("src")
+	assert.EqualError(t, err, "'src' is not an absolute path")
+	_, err = ParseSharedDirs("/home/user/src=src")
+	assert.EqualError(t, err, "'src' is not an absolute path in the VM")
+	_, err = ParseSharedDirs("/home/user/etc=/etc/")
+	assert.EqualError(t, err, "'/etc' is a system directory of the VM, it cannot be used as shared directory")
+	_, err = ParseSharedDirs("/home/user/src=/mnt/src,/srv/src=/mnt/src")
+	assert.EqualError(t, err, "several directories are shared at '/mnt/src'")
+	_, err = ParseSharedDirs("/a=/b=/c")
+	assert.EqualError(t, err, "'/a=/b=/c' is not a valid shared directory, expected 'host-dir' or 'host-dir=vm-dir'")
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// SnapshotScheduleType tells how often 'crc daemon' snapshots the running
+// instance
+type SnapshotScheduleType string
+
+const (
+	// NeverSnapshot disables the scheduled snapshots
+	NeverSnapshot  SnapshotScheduleType = "never"
+	HourlySnapshot SnapshotScheduleType = "hourly"
+	DailySnapshot  SnapshotScheduleType = "daily"
+)
+
+const DefaultSnapshotKeep = 3
+
+func parseSnapshotSchedule(input string) (SnapshotScheduleType, error) {
+	switch input {
+	case "", string(NeverSnapshot):
+		return NeverSnapshot, nil
+	case string(HourlySnapshot):
+		return HourlySnapshot, nil
+	case string(DailySnapshot):
+		return DailySnapshot, nil
+	default:
+		return "", fmt.Errorf("Cannot parse snapshot schedule '%s'", input)
+	}
+}
+
+// Interval is the time between two scheduled snapshots, it is 0 when they are
+// disabled
+func (schedule SnapshotScheduleType) Interval() time.Duration {
+	switch schedule {
+	case HourlySnapshot:
+		return time.Hour
+	case DailySnapshot:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+func ValidateSnapshotSchedule(value interface{}) (bool, string) {
+	if _, err := parseSnapshotSchedule(cast.ToString(value)); err != nil {
+		return false, fmt.Sprintf("snapshot schedule should be either %s, %s or %s", NeverSnapshot, HourlySnapshot, DailySnapshot)
+	}
+	return true, ""
+}
+
+// ValidateSnapshotKeep checks that at least one scheduled snapshot is kept
+func ValidateSnapshotKeep(value interface{}) (bool, string) {
+	keep, err := cast.ToIntE(value)
+	if err != nil || keep < 1 {
+		return false, "requires integer value >= 1"
+	}
+	return true, ""
+}
+
+// GetSnapshotSchedule returns how often the running instance is snapshotted
+func GetSnapshotSchedule(config Storage) SnapshotScheduleType {
+	schedule, err := parseSnapshotSchedule(config.Get(SnapshotSchedule).AsString())
+	if err != nil {
+		return NeverSnapshot
+	}
+	return schedule
+}
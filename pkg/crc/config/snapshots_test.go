@@ -0,0 +1,26 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotSchedule(t *testing.T) {
+	cfg := New(NewEmptyInMemoryStorage())
+	RegisterSettings(cfg)
+
+	assert.Equal(t, NeverSnapshot, GetSnapshotSchedule(cfg))
+	assert.Equal(t, time.Duration(0), GetSnapshotSchedule(cfg).Interval())
+	assert.Equal(t, DefaultSnapshotKeep, cfg.Get(SnapshotKeep).AsInt())
+
+	_, err := cfg.Set(SnapshotSchedule, string(DailySnapshot))
+	assert.NoError(t, err)
+	assert.Equal(t, 24*time.Hour, GetSnapshotSchedule(cfg).Interval())
+
+	_, err = cfg.Set(SnapshotSchedule, "weekly")
+	assert.Error(t, err)
+	_, err = cfg.Set(SnapshotKeep, 0)
+	assert.Error(t, err)
+}
@@ -19,12 +19,28 @@ type Setting struct {
 	validationFn ValidationFnType
 	callbackFn   SetFn
 	Help         string
+	// secret settings are kept in the keyring of the OS when it is
+	// available, and their values are not displayed
+	secret bool
 }
 
 type SettingValue struct {
 	Value     interface{}
 	Invalid   bool
 	IsDefault bool
+	IsSecret  bool
+	// the value comes from the configuration file, the environment or a
+	// flag, even when it is equal to the default value
+	IsSet bool
+}
+
+// DisplayValue returns the value to show to the user, the values of the
+// secret settings are masked
+func (v SettingValue) DisplayValue() interface{} {
+	if v.IsSecret && v.AsString() != "" {
+		return maskedValue
+	}
+	return v.Value
 }
 
 func (v SettingValue) AsBool() bool {
@@ -39,6 +55,15 @@ func (v SettingValue) AsInt() int {
 	return cast.ToInt(v.Value)
 }
 
+// AsAutomaticInt returns 0 for a setting which is not set, so that its value
+// is chosen automatically, and the value as an int otherwise
+func (v SettingValue) AsAutomaticInt() int {
+	if !v.IsSet {
+		return 0
+	}
+	return v.AsInt()
+}
+
 // validationFnType takes the key, value as args and checks if valid
 type ValidationFnType func(interface{}) (bool, string)
 type SetFn func(string, interface{}) string
@@ -2,11 +2,15 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"runtime"
 	"strings"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/notify"
 	"github.com/code-ready/crc/pkg/crc/validation"
+	"github.com/code-ready/crc/pkg/oci"
 	"github.com/spf13/cast"
 )
 
@@ -112,9 +116,105 @@ func ValidateNoProxy(value interface{}) (bool, string) {
 	return true, ""
 }
 
+// ValidateImageList checks if the comma-separated list of container images has the correct format
+func ValidateImageList(value interface{}) (bool, string) {
+	if strings.ContainsAny(cast.ToString(value), " \t") {
+		return false, "image list can't contain spaces"
+	}
+	return true, ""
+}
+
+// ValidateImageMirrors checks if the comma-separated list of image mirrors has the correct format
+func ValidateImageMirrors(value interface{}) (bool, string) {
+	if _, err := ParseImageMirrors(cast.ToString(value)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// ValidateOperatorList checks if the comma-separated list of cluster operators has the correct format
+func ValidateOperatorList(value interface{}) (bool, string) {
+	if strings.ContainsAny(cast.ToString(value), " \t") {
+		return false, "operator list can't contain spaces"
+	}
+	return true, ""
+}
+
+// ValidateCommandList checks if the comma-separated list of commands has the correct format
+func ValidateCommandList(value interface{}) (bool, string) {
+	if strings.ContainsAny(cast.ToString(value), " \t/") {
+		return false, "command list can't contain spaces or paths"
+	}
+	return true, ""
+}
+
+// ValidateSharedDirs checks if the comma-separated list of shared directories
+// has the correct format and if the host directories exist
+func ValidateSharedDirs(value interface{}) (bool, string) {
+	if runtime.GOOS != "linux" && cast.ToString(value) != "" {
+		return false, "shared directories are only supported on Linux"
+	}
+	sharedDirs, err := ParseSharedDirs(cast.ToString(value))
+	if err != nil {
+		return false, err.Error()
+	}
+	for _, sharedDir := range sharedDirs {
+		if info, err := os.Stat(sharedDir.Source); err != nil || !info.IsDir() {
+			return false, fmt.Sprintf("'%s' is not a directory", sharedDir.Source)
+		}
+	}
+	return true, ""
+}
+
+// ValidateHostServiceList checks if the comma-separated list of host services has the correct format
+func ValidateHostServiceList(value interface{}) (bool, string) {
+	if runtime.GOOS == "windows" && cast.ToString(value) != "" {
+		return false, "host services are not supported on Windows"
+	}
+	if strings.ContainsAny(cast.ToString(value), " \t/") {
+		return false, "host service list can't contain spaces or paths"
+	}
+	return true, ""
+}
+
+// ValidatePACFile checks if the proxy auto-config file is an HTTP URL or an
+// existing file
+func ValidatePACFile(value interface{}) (bool, string) {
+	if err := network.ValidatePACFile(cast.ToString(value)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// ValidateWebhookURL checks if the notification webhook URL is valid
+func ValidateWebhookURL(value interface{}) (bool, string) {
+	if err := notify.ValidateWebhookURL(cast.ToString(value)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// ValidateImageReference checks if the image reference is valid
+func ValidateImageReference(value interface{}) (bool, string) {
+	if err := oci.ValidateReference(cast.ToString(value)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
 func ValidateYesNo(value interface{}) (bool, string) {
 	if cast.ToString(value) == "yes" || cast.ToString(value) == "no" {
 		return true, ""
 	}
 	return false, "must be yes or no"
 }
+
+// ValidateMetricsPort checks if the port of the metrics endpoint is 0, which
+// disables it, or an unprivileged port
+func ValidateMetricsPort(value interface{}) (bool, string) {
+	port, err := cast.ToIntE(value)
+	if err != nil || (port != 0 && (port < 1024 || port > 65535)) {
+		return false, "requires 0 or an integer value between 1024 and 65535"
+	}
+	return true, ""
+}
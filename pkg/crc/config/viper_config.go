@@ -59,6 +59,11 @@ func (c *ViperStorage) Get(key string) interface{} {
 	if err != nil {
 		return nil
 	}
+	// the defaults of the flags are the ones of the settings, they are not
+	// values set by the user
+	if !viperInstance.IsSet(key) {
+		return nil
+	}
 	return viperInstance.Get(key)
 }
 
@@ -56,6 +56,7 @@ func TestViperConfigSetAndGet(t *testing.T) {
 	assert.Equal(t, SettingValue{
 		Value:     5,
 		IsDefault: false,
+		IsSet:     true,
 	}, config.Get(cpus))
 
 	bin, err := ioutil.ReadFile(configFile)
@@ -104,6 +105,7 @@ func TestViperConfigSetReloadAndGet(t *testing.T) {
 	assert.Equal(t, SettingValue{
 		Value:     5,
 		IsDefault: false,
+		IsSet:     true,
 	}, config.Get(cpus))
 }
 
@@ -131,6 +133,7 @@ func TestViperConfigLoadDefaultValue(t *testing.T) {
 	assert.Equal(t, SettingValue{
 		Value:     4,
 		IsDefault: true,
+		IsSet:     true,
 	}, config.Get(cpus))
 
 	config, err = newTestConfig(configFile, "CRC")
@@ -139,6 +142,7 @@ func TestViperConfigLoadDefaultValue(t *testing.T) {
 	assert.Equal(t, SettingValue{
 		Value:     4,
 		IsDefault: true,
+		IsSet:     true,
 	}, config.Get(cpus))
 }
 
@@ -175,6 +179,7 @@ func TestViperConfigBindFlagSet(t *testing.T) {
 	assert.Equal(t, SettingValue{
 		Value:     5,
 		IsDefault: false,
+		IsSet:     true,
 	}, config.Get(cpus))
 
 	_, err = config.Set(cpus, "6")
@@ -203,6 +208,7 @@ func TestViperConfigCastSet(t *testing.T) {
 	assert.Equal(t, SettingValue{
 		Value:     5,
 		IsDefault: false,
+		IsSet:     true,
 	}, config.Get(cpus))
 
 	bin, err := ioutil.ReadFile(configFile)
@@ -258,10 +264,12 @@ func TestTwoInstancesSharingSameConfiguration(t *testing.T) {
 	assert.Equal(t, SettingValue{
 		Value:     5,
 		IsDefault: false,
+		IsSet:     true,
 	}, config2.Get(cpus))
 	assert.Equal(t, SettingValue{
 		Value:     5,
 		IsDefault: false,
+		IsSet:     true,
 	}, config1.Get(cpus))
 }
 
@@ -319,3 +327,25 @@ func TestTwoInstancesSetAndUnsetSameConfiguration(t *testing.T) {
 	assert.Equal(t, 4, config2.Get(cpus).Value)
 	assert.Equal(t, 4, config1.Get(cpus).Value)
 }
+
+func TestViperConfigAutomaticInt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cfg")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, "crc.json")
+
+	storage, err := NewViperStorage(configFile, "CRC")
+	require.NoError(t, err)
+	config := New(storage)
+	config.AddSetting(cpus, 4, ValidateCPUs, RequiresRestartMsg, "")
+
+	flagSet := pflag.NewFlagSet("start", pflag.ExitOnError)
+	flagSet.IntP(cpus, "c", 4, "")
+	_ = storage.BindFlagSet(flagSet)
+
+	assert.Equal(t, 0, config.Get(cpus).AsAutomaticInt())
+
+	// the default value is used when the user asks for it explicitly
+	assert.NoError(t, flagSet.Set(cpus, "4"))
+	assert.Equal(t, 4, config.Get(cpus).AsAutomaticInt())
+}
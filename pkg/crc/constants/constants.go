@@ -8,6 +8,7 @@ import (
 
 	"github.com/YourFin/binappend"
 	"github.com/code-ready/crc/pkg/crc/version"
+	crcos "github.com/code-ready/crc/pkg/os"
 )
 
 const (
@@ -32,8 +33,10 @@ const (
 	DefaultContext            = "admin"
 	DaemonHTTPEndpoint        = "http://unix/api"
 
-	VSockGateway = "192.168.127.1"
-	VsockSSHPort = 2222
+	VSockGateway   = "192.168.127.1"
+	VsockSSHPort   = 2222
+	ProxyRelayPort = 3128
+	HostDNSPort    = 10053
 
 	OkdPullSecret = `{"auths":{"fake":{"auth": "Zm9vOmJhcgo="}}}` // #nosec G101
 
@@ -91,14 +94,35 @@ var (
 	ConfigPath         = filepath.Join(CrcBaseDir, ConfigFile)
 	LogFilePath        = filepath.Join(CrcBaseDir, LogFile)
 	DaemonLogFilePath  = filepath.Join(CrcBaseDir, DaemonLogFile)
-	MachineBaseDir     = CrcBaseDir
+	ExecAuditLogPath   = filepath.Join(CrcBaseDir, "exec-audit.log")
+	MachineBaseDir     = machineBaseDir()
 	MachineCacheDir    = filepath.Join(MachineBaseDir, "cache")
 	MachineInstanceDir = filepath.Join(MachineBaseDir, "machines")
 	DefaultBundlePath  = defaultBundlePath()
 	DaemonSocketPath   = filepath.Join(CrcBaseDir, "crc.sock")
+	HostDNSRecordsDir  = filepath.Join(CrcBaseDir, "dns")
 	KubeconfigFilePath = filepath.Join(MachineInstanceDir, DefaultName, "kubeconfig")
 )
 
+// StorageDirEnv names the environment variable with an alternate directory
+// for the bundles and the instances, for the hosts where '$HOME/.crc' is on a
+// filesystem which cannot hold them
+const StorageDirEnv = "CRC_STORAGE_DIR"
+
+func machineBaseDir() string {
+	dir := os.Getenv(StorageDirEnv)
+	if dir == "" {
+		return CrcBaseDir
+	}
+	if absDir, err := filepath.Abs(dir); err == nil {
+		dir = absDir
+	}
+	if normalized, err := crcos.NormalizePath(dir); err == nil {
+		return normalized
+	}
+	return dir
+}
+
 func defaultBundlePath() string {
 	if version.IsInstaller() {
 		return filepath.Join(version.InstallPath(), GetDefaultBundle())
@@ -119,12 +143,20 @@ func GetHomeDir() string {
 	if err != nil {
 		panic("Failed to get homeDir: " + err.Error())
 	}
+	// the paths given to the hypervisors are all below the home directory
+	if normalized, err := crcos.NormalizePath(homeDir); err == nil {
+		return normalized
+	}
 	return homeDir
 }
 
-// EnsureBaseDirectoryExists create the ~/.crc directory if it is not present
+// EnsureBaseDirectoryExists create the ~/.crc directory and the storage
+// directory if they are not present
 func EnsureBaseDirectoriesExist() error {
-	return os.MkdirAll(CrcBaseDir, 0750)
+	if err := os.MkdirAll(CrcBaseDir, 0750); err != nil {
+		return err
+	}
+	return os.MkdirAll(MachineBaseDir, 0750)
 }
 
 // BundleEmbedded returns true if the executable was compiled to contain the bundle
@@ -153,6 +185,23 @@ func contains(arr []string, str string) bool {
 	return false
 }
 
+// GetClusterDomain returns the base domain of the cluster running in the
+// instance called name, e.g. api.<name>.crc.testing for a name other than the default one
+func GetClusterDomain(name string) string {
+	if name == "" || name == DefaultName {
+		return ClusterDomain
+	}
+	return fmt.Sprintf(".%s%s", name, ClusterDomain)
+}
+
+// GetAppsDomain returns the domain of the routes of the cluster running in the instance called name
+func GetAppsDomain(name string) string {
+	if name == "" || name == DefaultName {
+		return AppsDomain
+	}
+	return fmt.Sprintf(".apps%s", GetClusterDomain(name))
+}
+
 func GetPublicKeyPath() string {
 	return filepath.Join(MachineInstanceDir, DefaultName, "id_ecdsa.pub")
 }
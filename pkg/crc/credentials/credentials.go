@@ -0,0 +1,152 @@
+package credentials
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	AdminUsername     = "kubeadmin"
+	DeveloperUsername = "developer"
+	DeveloperPassword = "developer"
+)
+
+type Format string
+
+const (
+	JSONFormat       Format = "json"
+	EnvFormat        Format = "env"
+	NetrcFormat      Format = "netrc"
+	KubeconfigFormat Format = "kubeconfig"
+)
+
+var Formats = []Format{JSONFormat, EnvFormat, NetrcFormat, KubeconfigFormat}
+
+type UserCredentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Credentials holds what is needed to log into the cluster
+type Credentials struct {
+	ClusterCACert        string          `json:"cacert"`
+	WebConsoleURL        string          `json:"webConsoleUrl"`
+	URL                  string          `json:"url"`
+	AdminCredentials     UserCredentials `json:"adminCredentials"`
+	DeveloperCredentials UserCredentials `json:"developerCredentials"`
+}
+
+func FromClusterConfig(clusterConfig types.ClusterConfig) Credentials {
+	return Credentials{
+		ClusterCACert: clusterConfig.ClusterCACert,
+		WebConsoleURL: clusterConfig.WebConsoleURL,
+		URL:           clusterConfig.ClusterAPI,
+		AdminCredentials: UserCredentials{
+			Username: AdminUsername,
+			Password: clusterConfig.KubeAdminPass,
+		},
+		DeveloperCredentials: UserCredentials{
+			Username: DeveloperUsername,
+			Password: DeveloperPassword,
+		},
+	}
+}
+
+// Render returns the credentials in the given format, for consumption by scripts and tools
+func (c Credentials) Render(format Format) (string, error) {
+	switch format {
+	case JSONFormat:
+		content, err := json.MarshalIndent(c, "", "  ")
+		if err != nil {
+			return "", err
+		}
+		return string(content) + "\n", nil
+	case EnvFormat:
+		return c.env(), nil
+	case NetrcFormat:
+		return c.netrc()
+	case KubeconfigFormat:
+		return c.kubeconfig()
+	default:
+		return "", fmt.Errorf("invalid credentials format: %s, must be one of %s", format, formatList())
+	}
+}
+
+func formatList() string {
+	var formats []string
+	for _, format := range Formats {
+		formats = append(formats, string(format))
+	}
+	return strings.Join(formats, ", ")
+}
+
+func (c Credentials) env() string {
+	vars := []struct {
+		name  string
+		value string
+	}{
+		{"CRC_API_URL", c.URL},
+		{"CRC_WEB_CONSOLE_URL", c.WebConsoleURL},
+		{"CRC_ADMIN_USERNAME", c.AdminCredentials.Username},
+		{"CRC_ADMIN_PASSWORD", c.AdminCredentials.Password},
+		{"CRC_DEVELOPER_USERNAME", c.DeveloperCredentials.Username},
+		{"CRC_DEVELOPER_PASSWORD", c.DeveloperCredentials.Password},
+	}
+	var b strings.Builder
+	for _, v := range vars {
+		fmt.Fprintf(&b, "export %s='%s'\n", v.name, strings.ReplaceAll(v.value, "'", `'\''`))
+	}
+	return b.String()
+}
+
+// netrc returns entries for the API server and the web console, .netrc only
+// allows one login per host so the admin credentials are used
+func (c Credentials) netrc() (string, error) {
+	var b strings.Builder
+	for _, rawURL := range []string{c.URL, c.WebConsoleURL} {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, "machine %s login %s password %s\n", u.Hostname(), c.AdminCredentials.Username, c.AdminCredentials.Password)
+	}
+	return b.String(), nil
+}
+
+// kubeconfig returns a kubeconfig snippet with a context for each user, it
+// can be merged with an existing kubeconfig through the KUBECONFIG variable
+func (c Credentials) kubeconfig() (string, error) {
+	caCert, err := base64.StdEncoding.DecodeString(c.ClusterCACert)
+	if err != nil {
+		return "", fmt.Errorf("invalid cluster CA certificate: %w", err)
+	}
+	cfg := clientcmdapi.NewConfig()
+	cfg.Clusters[constants.DefaultName] = &clientcmdapi.Cluster{
+		Server:                   c.URL,
+		CertificateAuthorityData: caCert,
+	}
+	for _, user := range []UserCredentials{c.AdminCredentials, c.DeveloperCredentials} {
+		cfg.AuthInfos[user.Username] = &clientcmdapi.AuthInfo{
+			Username: user.Username,
+			Password: user.Password,
+		}
+		cfg.Contexts[fmt.Sprintf("%s-%s", constants.DefaultName, user.Username)] = &clientcmdapi.Context{
+			Cluster:  constants.DefaultName,
+			AuthInfo: user.Username,
+		}
+	}
+	cfg.CurrentContext = fmt.Sprintf("%s-%s", constants.DefaultName, c.DeveloperCredentials.Username)
+	content, err := clientcmd.Write(*cfg)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
@@ -0,0 +1,42 @@
+package credentials
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var clusterConfig = types.ClusterConfig{
+	ClusterCACert: base64.StdEncoding.EncodeToString([]byte("ca")),
+	KubeAdminPass: "it's-secret",
+	ClusterAPI:    "https://api.crc.testing:6443",
+	WebConsoleURL: "https://console-openshift-console.apps-crc.testing",
+}
+
+func TestRenderEnv(t *testing.T) {
+	out, err := FromClusterConfig(clusterConfig).Render(EnvFormat)
+	require.NoError(t, err)
+	assert.Equal(t, `export CRC_API_URL='https://api.crc.testing:6443'
+export CRC_WEB_CONSOLE_URL='https://console-openshift-console.apps-crc.testing'
+export CRC_ADMIN_USERNAME='kubeadmin'
+export CRC_ADMIN_PASSWORD='it'\''s-secret'
+export CRC_DEVELOPER_USERNAME='developer'
+export CRC_DEVELOPER_PASSWORD='developer'
+`, out)
+}
+
+func TestRenderNetrc(t *testing.T) {
+	out, err := FromClusterConfig(clusterConfig).Render(NetrcFormat)
+	require.NoError(t, err)
+	assert.Equal(t, `machine api.crc.testing login kubeadmin password it's-secret
+machine console-openshift-console.apps-crc.testing login kubeadmin password it's-secret
+`, out)
+}
+
+func TestRenderInvalidFormat(t *testing.T) {
+	_, err := FromClusterConfig(clusterConfig).Render("xml")
+	assert.EqualError(t, err, "invalid credentials format: xml, must be one of json, env, netrc, kubeconfig")
+}
@@ -0,0 +1,40 @@
+// Package keyring keeps the secrets of crc in the keyring of the OS: the
+// Keychain on macOS, the Credential Manager on Windows and the Secret Service
+// of the desktop session, such as GNOME Keyring, on Linux
+package keyring
+
+import (
+	"errors"
+
+	gokeyring "github.com/zalando/go-keyring"
+)
+
+// service groups the secrets of crc in the keyring
+const service = "crc"
+
+// ErrNotFound is returned by Get when the keyring has no secret for the key
+var ErrNotFound = gokeyring.ErrNotFound
+
+// Get returns the secret stored for key
+func Get(key string) (string, error) {
+	return gokeyring.Get(service, key)
+}
+
+// Set stores secret for key, replacing the previous one
+func Set(key, secret string) error {
+	return gokeyring.Set(service, key, secret)
+}
+
+// Delete removes the secret stored for key, it is not an error when there is
+// none
+func Delete(key string) error {
+	if err := gokeyring.Delete(service, key); err != nil && !errors.Is(err, gokeyring.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// MockInit replaces the keyring of the OS with an in-memory one, for the tests
+func MockInit() {
+	gokeyring.MockInit()
+}
@@ -0,0 +1,137 @@
+package logforwarding
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+// record is a log line sent by the fluent-bit http output with the json_lines format
+type record struct {
+	Namespace string `json:"kubernetes_namespace_name"`
+	Pod       string `json:"kubernetes_pod_name"`
+	Container string `json:"kubernetes_container_name"`
+	Message   string `json:"message"`
+
+	SystemdUnit    string `json:"_SYSTEMD_UNIT"`
+	SystemdMessage string `json:"MESSAGE"`
+}
+
+const (
+	// fluent-bit flushes its buffers every 5 seconds, a request is much
+	// smaller than this
+	maxRequestSize = 8 * 1024 * 1024
+	// a log file growing larger is renamed with the .1 suffix, replacing the
+	// previous one, so that a container takes at most twice this size
+	maxLogFileSize = 10 * 1024 * 1024
+)
+
+var unsafeCharacters = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
+
+func sanitize(name string) string {
+	name = unsafeCharacters.ReplaceAllString(name, "_")
+	if name == "" || name == "." || name == ".." {
+		return "unknown"
+	}
+	return name
+}
+
+// path returns the file of the record, relative to the logs directory, and its message
+func (r *record) path() (string, string) {
+	if r.SystemdUnit != "" || r.SystemdMessage != "" {
+		return filepath.Join("host", sanitize(strings.TrimSuffix(r.SystemdUnit, ".service"))+".log"), r.SystemdMessage
+	}
+	return filepath.Join(sanitize(r.Namespace), fmt.Sprintf("%s_%s.log", sanitize(r.Pod), sanitize(r.Container))), r.Message
+}
+
+// Handler writes the logs forwarded from the cluster to the directory
+// returned by logsDir, one file per container and per host service. Each of
+// them keeps about the last maxLogFileSize to 2*maxLogFileSize bytes of logs.
+// logsDir is called for each request, so that changes to the configuration are
+// picked up without restarting the daemon, it returns an empty string when
+// the logs must not be written.
+type Handler struct {
+	logsDir func() string
+	lock    sync.Mutex
+}
+
+func NewHandler(logsDir func() string) *Handler {
+	return &Handler{logsDir: logsDir}
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "post only", http.StatusBadRequest)
+		return
+	}
+	dir := h.logsDir()
+	if dir == "" {
+		http.Error(w, "log forwarding to the host is disabled", http.StatusNotFound)
+		return
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	lines := map[string][]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		path, message := rec.path()
+		lines[path] = append(lines[path], strings.TrimSuffix(message, "\n"))
+	}
+	if err := scanner.Err(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	for path, messages := range lines {
+		if err := appendLines(filepath.Join(dir, path), messages); err != nil {
+			logging.Debugf("Cannot write forwarded logs: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+func appendLines(path string, lines []string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return err
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() >= maxLogFileSize {
+		if err := os.Rename(path, path+".1"); err != nil {
+			return err
+		}
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(strings.Join(lines, "\n") + "\n"); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
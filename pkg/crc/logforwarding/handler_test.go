@@ -0,0 +1,85 @@
+package logforwarding
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	dir := t.TempDir()
+	handler := NewHandler(func() string {
+		return dir
+	})
+
+	body := `{"date":1.6e9,"kubernetes_namespace_name":"openshift-dns","kubernetes_pod_name":"dns-default-x8z2k","kubernetes_container_name":"dns","message":"first\n"}
+{"date":1.6e9,"kubernetes_namespace_name":"openshift-dns","kubernetes_pod_name":"dns-default-x8z2k","kubernetes_container_name":"dns","message":"second"}
+{"date":1.6e9,"_SYSTEMD_UNIT":"kubelet.service","MESSAGE":"kubelet started"}
+{"date":1.6e9,"kubernetes_namespace_name":"../..","kubernetes_pod_name":"a/b","kubernetes_container_name":"c","message":"escaped"}
+`
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/logs", strings.NewReader(body)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "openshift-dns", "dns-default-x8z2k_dns.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "first\nsecond\n", string(content))
+
+	content, err = ioutil.ReadFile(filepath.Join(dir, "host", "kubelet.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "kubelet started\n", string(content))
+
+	content, err = ioutil.ReadFile(filepath.Join(dir, ".._..", "a_b_c.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "escaped\n", string(content))
+}
+
+func TestHandlerDisabled(t *testing.T) {
+	handler := NewHandler(func() string {
+		return ""
+	})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/logs", strings.NewReader(`{"message":"lost"}`)))
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHandlerRotatesLargeFiles(t *testing.T) {
+	dir := t.TempDir()
+	handler := NewHandler(func() string {
+		return dir
+	})
+	path := filepath.Join(dir, "host", "kubelet.log")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0750))
+	require.NoError(t, ioutil.WriteFile(path, bytes.Repeat([]byte("x"), maxLogFileSize), 0600))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/logs", strings.NewReader(`{"_SYSTEMD_UNIT":"kubelet.service","MESSAGE":"kubelet started"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "kubelet started\n", string(content))
+	info, err := os.Stat(path + ".1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(maxLogFileSize), info.Size())
+}
+
+func TestHandlerRejectsLargeRequests(t *testing.T) {
+	dir := t.TempDir()
+	handler := NewHandler(func() string {
+		return dir
+	})
+	line := `{"_SYSTEMD_UNIT":"kubelet.service","MESSAGE":"` + strings.Repeat("x", 1000) + `"}` + "\n"
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/logs", strings.NewReader(strings.Repeat(line, maxRequestSize/len(line)+1))))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.NoFileExists(t, filepath.Join(dir, "host", "kubelet.log"))
+}
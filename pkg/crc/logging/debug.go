@@ -0,0 +1,137 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+)
+
+// DebugCategory is an operation whose detailed debug logs can be enabled on
+// its own, so that the logs needed to troubleshoot an issue can be produced
+// without the noise of the other operations
+type DebugCategory string
+
+const (
+	// DriverDebug is the output of the virtualization driver
+	DriverDebug DebugCategory = "driver"
+	// SSHDebug is the transcript of the commands run in the VM
+	SSHDebug DebugCategory = "ssh"
+	// OcDebug is the oc commands run against the cluster and their output
+	OcDebug DebugCategory = "oc"
+	// HTTPDebug is the HTTP requests sent by crc
+	HTTPDebug DebugCategory = "http"
+
+	allDebugCategories  = "all"
+	noneDebugCategories = "none"
+)
+
+var (
+	DebugCategories = []DebugCategory{DriverDebug, SSHDebug, OcDebug, HTTPDebug}
+
+	// the HTTP requests are only logged on demand, the other categories are
+	// logged unless a list of categories is given
+	defaultDebugCategories = "driver,ssh,oc"
+
+	debugCategories = defaultDebugCategoriesValue()
+	enabledDebug    = mustParseDebugCategories(debugCategories)
+)
+
+func defaultDebugCategoriesValue() string {
+	if env := os.Getenv("CRC_DEBUG_OPERATIONS"); env != "" {
+		return env
+	}
+	return defaultDebugCategories
+}
+
+func mustParseDebugCategories(input string) map[DebugCategory]bool {
+	categories, err := ParseDebugCategories(input)
+	if err != nil {
+		categories, _ = ParseDebugCategories(defaultDebugCategories)
+	}
+	return categories
+}
+
+// ParseDebugCategories parses a comma-separated list of debug categories,
+// 'all' enables all of them and 'none' disables all of them
+func ParseDebugCategories(input string) (map[DebugCategory]bool, error) {
+	enabled := map[DebugCategory]bool{}
+	for _, item := range strings.Split(input, ",") {
+		item = strings.TrimSpace(item)
+		switch item {
+		case "", noneDebugCategories:
+			continue
+		case allDebugCategories:
+			for _, category := range DebugCategories {
+				enabled[category] = true
+			}
+			continue
+		}
+		if !isDebugCategory(DebugCategory(item)) {
+			return nil, fmt.Errorf("'%s' is not a valid debug operation, expected one of %s, %s or %s", item, debugCategoryNames(), allDebugCategories, noneDebugCategories)
+		}
+		enabled[DebugCategory(item)] = true
+	}
+	return enabled, nil
+}
+
+func isDebugCategory(category DebugCategory) bool {
+	for _, c := range DebugCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+func debugCategoryNames() string {
+	var names []string
+	for _, category := range DebugCategories {
+		names = append(names, string(category))
+	}
+	return strings.Join(names, ", ")
+}
+
+func AddDebugCategoriesFlag(flagset *pflag.FlagSet) {
+	flagset.StringVar(&debugCategories, "debug-operations", defaultDebugCategoriesValue(),
+		fmt.Sprintf("operations with detailed debug logs in the log file (comma-separated list of %s, or %s or %s)", debugCategoryNames(), allDebugCategories, noneDebugCategories))
+}
+
+// SetDebugCategories enables the detailed debug logs of the given operations only
+func SetDebugCategories(input string) error {
+	categories, err := ParseDebugCategories(input)
+	if err != nil {
+		return err
+	}
+	debugCategories = input
+	enabledDebug = categories
+	return nil
+}
+
+func IsDebugEnabled(category DebugCategory) bool {
+	return enabledDebug[category]
+}
+
+// DebugfFor logs at debug level when the debug logs of category are enabled
+func DebugfFor(category DebugCategory, s string, args ...interface{}) {
+	if !IsDebugEnabled(category) {
+		return
+	}
+	logrus.WithField("operation", string(category)).Debugf(s, args...)
+}
+
+// driverDebugFilter drops the debug output of the driver plugins when the
+// driver debug logs are disabled. These lines are logged by libmachine, the
+// only way to tell them apart is their '(machine) DBG | ' prefix.
+type driverDebugFilter struct {
+	logrus.Formatter
+}
+
+func (f *driverDebugFilter) Format(entry *logrus.Entry) ([]byte, error) {
+	if entry.Level == logrus.DebugLevel && !IsDebugEnabled(DriverDebug) && strings.Contains(entry.Message, ") DBG | ") {
+		return nil, nil
+	}
+	return f.Formatter.Format(entry)
+}
@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDebugCategories(t *testing.T) {
+	categories, err := ParseDebugCategories("ssh, http")
+	assert.NoError(t, err)
+	assert.Equal(t, map[DebugCategory]bool{SSHDebug: true, HTTPDebug: true}, categories)
+
+	categories, err = ParseDebugCategories("all")
+	assert.NoError(t, err)
+	assert.Len(t, categories, len(DebugCategories))
+
+	categories, err = ParseDebugCategories("none")
+	assert.NoError(t, err)
+	assert.Empty(t, categories)
+
+	_, err = ParseDebugCategories("ssh,kubelet")
+	assert.EqualError(t, err, "'kubelet' is not a valid debug operation, expected one of driver, ssh, oc, http, all or none")
+}
+
+func TestDriverDebugFilter(t *testing.T) {
+	defer func() {
+		assert.NoError(t, SetDebugCategories(defaultDebugCategories))
+	}()
+	filter := &driverDebugFilter{Formatter: &logrus.TextFormatter{DisableTimestamp: true}}
+	driverEntry := &logrus.Entry{Level: logrus.DebugLevel, Message: "(crc) DBG | Getting state of the VM"}
+	otherEntry := &logrus.Entry{Level: logrus.DebugLevel, Message: "Running SSH command: exit 0"}
+
+	assert.NoError(t, SetDebugCategories("ssh"))
+	out, err := filter.Format(driverEntry)
+	assert.NoError(t, err)
+	assert.Empty(t, out)
+	out, err = filter.Format(otherEntry)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, out)
+
+	assert.NoError(t, SetDebugCategories("driver"))
+	out, err = filter.Format(driverEntry)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, out)
+}
@@ -2,20 +2,29 @@ package logging
 
 import (
 	"container/ring"
+	"fmt"
 	"sync"
 
+	"github.com/code-ready/crc/pkg/broadcast"
 	"github.com/sirupsen/logrus"
 )
 
+// subscriberBuffer is the number of messages kept for a subscriber which
+// does not receive them fast enough, it is told how many of the next ones
+// it missed
+const subscriberBuffer = 100
+
 // This hook keeps in memory n messages from error to info level
 type inMemoryHook struct {
-	messages *ring.Ring
-	lock     sync.RWMutex
+	messages    *ring.Ring
+	subscribers *broadcast.Broadcaster
+	lock        sync.RWMutex
 }
 
 func newInMemoryHook(size int) *inMemoryHook {
 	return &inMemoryHook{
-		messages: ring.New(size),
+		messages:    ring.New(size),
+		subscribers: broadcast.New(),
 	}
 }
 
@@ -28,9 +37,31 @@ func (h *inMemoryHook) Fire(entry *logrus.Entry) error {
 	defer h.lock.Unlock()
 	h.messages.Value = entry.Message
 	h.messages = h.messages.Next()
+	h.subscribers.Publish(entry.Message)
 	return nil
 }
 
+// Subscribe returns the channel receiving the next messages, and the
+// function to call once they are not needed anymore
+func (h *inMemoryHook) Subscribe() (<-chan string, func()) {
+	ch := make(chan string, subscriberBuffer)
+	unsubscribe := h.subscribers.Subscribe(func(message interface{}) bool {
+		select {
+		case ch <- message.(string):
+			return true
+		default:
+			return false
+		}
+	}, func(dropped int) interface{} {
+		return fmt.Sprintf("%d log messages were dropped, the client did not read them fast enough", dropped)
+	})
+	return ch, func() {
+		if unsubscribe() {
+			close(ch)
+		}
+	}
+}
+
 func (h *inMemoryHook) Messages() []string {
 	h.lock.RLock()
 	defer h.lock.RUnlock()
@@ -44,3 +44,32 @@ func TestRace(t *testing.T) {
 		}
 	}
 }
+
+func TestSubscribe(t *testing.T) {
+	memory := newInMemoryHook(5)
+	messages, cancel := memory.Subscribe()
+
+	assert.NoError(t, memory.Fire(&logrus.Entry{Message: "message 1"}))
+	assert.Equal(t, "message 1", <-messages)
+
+	cancel()
+	assert.NoError(t, memory.Fire(&logrus.Entry{Message: "message 2"}))
+	_, open := <-messages
+	assert.False(t, open)
+}
+
+func TestSubscribeSlowReader(t *testing.T) {
+	memory := newInMemoryHook(5)
+	messages, cancel := memory.Subscribe()
+	defer cancel()
+
+	for i := 0; i < subscriberBuffer+3; i++ {
+		assert.NoError(t, memory.Fire(&logrus.Entry{Message: "message"}))
+	}
+	for i := 0; i < subscriberBuffer; i++ {
+		<-messages
+	}
+	assert.NoError(t, memory.Fire(&logrus.Entry{Message: "last message"}))
+	assert.Equal(t, "3 log messages were dropped, the client did not read them fast enough", <-messages)
+	assert.Equal(t, "last message", <-messages)
+}
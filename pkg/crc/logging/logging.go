@@ -46,6 +46,13 @@ func InitLogrus(logFilePath string) {
 	}
 	// send logs to file
 	logrus.SetOutput(logfile)
+	if _, ok := logrus.StandardLogger().Formatter.(*driverDebugFilter); !ok {
+		logrus.SetFormatter(&driverDebugFilter{Formatter: logrus.StandardLogger().Formatter})
+	}
+
+	if err := SetDebugCategories(debugCategories); err != nil {
+		logrus.Fatal(err)
+	}
 
 	logrus.SetLevel(logrus.TraceLevel)
 
@@ -57,11 +64,11 @@ func InitLogrus(logFilePath string) {
 	logrus.AddHook(Memory)
 
 	// Add hook to send error/fatal to stderr
-	logrus.AddHook(newstdErrHook(level, &logrus.TextFormatter{
+	logrus.AddHook(newstdErrHook(level, &driverDebugFilter{Formatter: &logrus.TextFormatter{
 		ForceColors:            terminal.IsTerminal(int(os.Stderr.Fd())),
 		DisableTimestamp:       true,
 		DisableLevelTruncation: false,
-	}))
+	}}))
 
 	for k, v := range logrus.StandardLogger().Hooks {
 		originalHooks[k] = v
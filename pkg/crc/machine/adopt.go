@@ -0,0 +1,63 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/libmachine/host"
+	"github.com/pkg/errors"
+)
+
+// runningVMFingerprint describes an instance which is found running on start,
+// for example when it is left over by a previous CI job
+type runningVMFingerprint struct {
+	BundleName      string
+	CertsExpiry     time.Time
+	OpenshiftStatus types.OpenshiftStatus
+}
+
+// problems returns why the running instance cannot be used as is, it is then
+// verified by going through the whole start sequence
+func (fingerprint runningVMFingerprint) problems(bundleName string, now time.Time) []string {
+	var problems []string
+	if fingerprint.BundleName != bundleName {
+		problems = append(problems, fmt.Sprintf("it uses the bundle '%s' instead of '%s'", fingerprint.BundleName, bundleName))
+	}
+	if !fingerprint.CertsExpiry.IsZero() && fingerprint.CertsExpiry.Before(now) {
+		problems = append(problems, fmt.Sprintf("its certificates expired on %s", fingerprint.CertsExpiry.Format(time.RFC3339)))
+	}
+	if fingerprint.OpenshiftStatus != types.OpenshiftRunning {
+		problems = append(problems, fmt.Sprintf("OpenShift is %s", strings.ToLower(string(fingerprint.OpenshiftStatus))))
+	}
+	return problems
+}
+
+func (client *client) fingerprintRunningVM(ctx context.Context, host *host.Host, crcBundleMetadata *bundle.CrcBundleInfo) (runningVMFingerprint, error) {
+	ip, err := getIP(host, client.useVSock())
+	if err != nil {
+		return runningVMFingerprint{}, errors.Wrap(err, "Error getting the IP")
+	}
+	openshiftStatus, _ := client.openshiftStatus(ctx, ip)
+	fingerprint := runningVMFingerprint{
+		BundleName:      crcBundleMetadata.GetBundleName(),
+		CertsExpiry:     client.getCertsExpiry(ip, crcBundleMetadata),
+		OpenshiftStatus: openshiftStatus,
+	}
+	logging.Debugf("Running VM fingerprint: %+v", fingerprint)
+	return fingerprint, nil
+}
+
+// runningVMProblems tells if the running instance can be reused as is,
+// with the requested bundle, and otherwise why it must be verified
+func (client *client) runningVMProblems(ctx context.Context, host *host.Host, crcBundleMetadata *bundle.CrcBundleInfo, bundleName string) []string {
+	fingerprint, err := client.fingerprintRunningVM(ctx, host, crcBundleMetadata)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	return fingerprint.problems(bundleName, time.Now())
+}
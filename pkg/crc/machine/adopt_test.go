@@ -0,0 +1,40 @@
+package machine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunningVMProblems(t *testing.T) {
+	now := time.Date(2021, time.September, 1, 0, 0, 0, 0, time.UTC)
+	bundleName := "crc_libvirt_4.8.2"
+
+	assert.Empty(t, runningVMFingerprint{
+		BundleName:      bundleName,
+		CertsExpiry:     now.Add(24 * time.Hour),
+		OpenshiftStatus: types.OpenshiftRunning,
+	}.problems(bundleName, now))
+	assert.Empty(t, runningVMFingerprint{
+		BundleName:      bundleName,
+		OpenshiftStatus: types.OpenshiftRunning,
+	}.problems(bundleName, now))
+
+	assert.Equal(t, []string{
+		"its certificates expired on 2021-08-31T00:00:00Z",
+		"OpenShift is degraded",
+	}, runningVMFingerprint{
+		BundleName:      bundleName,
+		CertsExpiry:     now.Add(-24 * time.Hour),
+		OpenshiftStatus: types.OpenshiftDegraded,
+	}.problems(bundleName, now))
+
+	assert.Equal(t, []string{
+		"it uses the bundle 'crc_libvirt_4.7.18' instead of 'crc_libvirt_4.8.2'",
+	}, runningVMFingerprint{
+		BundleName:      "crc_libvirt_4.7.18",
+		OpenshiftStatus: types.OpenshiftRunning,
+	}.problems(bundleName, now))
+}
@@ -0,0 +1,108 @@
+package bundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	crcos "github.com/code-ready/crc/pkg/os"
+	"github.com/spf13/cast"
+)
+
+const sourceInfoFilename = "crc-bundle-source.json"
+
+// CleanupPolicy tells what to do with a .crcbundle archive once it has been extracted
+type CleanupPolicy string
+
+const (
+	KeepBundle   CleanupPolicy = "keep"
+	DeleteBundle CleanupPolicy = "delete"
+)
+
+func parseCleanupPolicy(input string) (CleanupPolicy, error) {
+	switch input {
+	case string(KeepBundle):
+		return KeepBundle, nil
+	case string(DeleteBundle):
+		return DeleteBundle, nil
+	default:
+		return KeepBundle, fmt.Errorf("Cannot parse bundle cleanup policy '%s'", input)
+	}
+}
+
+func ParseCleanupPolicy(input string) CleanupPolicy {
+	policy, err := parseCleanupPolicy(input)
+	if err != nil {
+		logging.Warnf("Unexpected bundle cleanup policy '%s', using '%s' instead", input, KeepBundle)
+	}
+	return policy
+}
+
+func ValidateCleanupPolicy(val interface{}) (bool, string) {
+	if _, err := parseCleanupPolicy(cast.ToString(val)); err != nil {
+		return false, fmt.Sprintf("bundle cleanup policy should be either %s or %s", KeepBundle, DeleteBundle)
+	}
+	return true, ""
+}
+
+// SourceInfo records what happened to the archive a bundle was extracted from
+type SourceInfo struct {
+	Path      string        `json:"path"`
+	Policy    CleanupPolicy `json:"policy"`
+	RemovedAt time.Time     `json:"removedAt"`
+}
+
+// Cleanup applies policy to the bundle archive at path. This must only be
+// called once the bundle has been successfully extracted and verified.
+func (repo *Repository) Cleanup(path string, policy CleanupPolicy) error {
+	if policy != DeleteBundle || !crcos.FileExists(path) {
+		return nil
+	}
+	bundleDir := filepath.Join(repo.CacheDir, GetBundleNameWithoutExtension(filepath.Base(path)))
+	if !crcos.FileExists(filepath.Join(bundleDir, metadataFilename)) {
+		return fmt.Errorf("bundle %s is not extracted, refusing to delete it", path)
+	}
+	// record the removal before doing it so that the archive is never gone without a trace
+	content, err := json.Marshal(SourceInfo{
+		Path:      path,
+		Policy:    policy,
+		RemovedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(bundleDir, sourceInfoFilename), content, 0600); err != nil {
+		return err
+	}
+	logging.Infof("Deleting bundle archive %s as it is no longer needed", path)
+	return os.Remove(path)
+}
+
+// GetSourceInfo returns the cleanup information recorded for bundleName, or
+// nil if its archive was never removed
+func (repo *Repository) GetSourceInfo(bundleName string) (*SourceInfo, error) {
+	content, err := ioutil.ReadFile(filepath.Join(repo.CacheDir, GetBundleNameWithoutExtension(bundleName), sourceInfoFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var info SourceInfo
+	if err := json.Unmarshal(content, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func Cleanup(path string, policy CleanupPolicy) error {
+	return defaultRepo.Cleanup(path, policy)
+}
+
+func GetSourceInfo(bundleName string) (*SourceInfo, error) {
+	return defaultRepo.GetSourceInfo(bundleName)
+}
@@ -0,0 +1,54 @@
+package bundle
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCleanup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "repo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	archive := filepath.Join(dir, "crc_libvirt_4.6.1.crcbundle")
+	require.NoError(t, ioutil.WriteFile(archive, []byte("bundle"), 0600))
+
+	repo := &Repository{
+		CacheDir: dir,
+	}
+
+	assert.EqualError(t, repo.Cleanup(archive, DeleteBundle), "bundle "+archive+" is not extracted, refusing to delete it")
+	assert.FileExists(t, archive)
+
+	createDummyBundleContent(t, dir, "crc_libvirt_4.6.1", "1.0")
+
+	assert.NoError(t, repo.Cleanup(archive, KeepBundle))
+	assert.FileExists(t, archive)
+	info, err := repo.GetSourceInfo("crc_libvirt_4.6.1.crcbundle")
+	assert.NoError(t, err)
+	assert.Nil(t, info)
+
+	assert.NoError(t, repo.Cleanup(archive, DeleteBundle))
+	assert.NoFileExists(t, archive)
+	info, err = repo.GetSourceInfo("crc_libvirt_4.6.1.crcbundle")
+	assert.NoError(t, err)
+	assert.Equal(t, archive, info.Path)
+	assert.Equal(t, DeleteBundle, info.Policy)
+
+	// the bundle is still usable once its archive is gone
+	_, err = repo.Get("crc_libvirt_4.6.1.crcbundle")
+	assert.NoError(t, err)
+}
+
+func TestValidateCleanupPolicy(t *testing.T) {
+	valid, _ := ValidateCleanupPolicy("delete")
+	assert.True(t, valid)
+	valid, msg := ValidateCleanupPolicy("compress")
+	assert.False(t, valid)
+	assert.Equal(t, "bundle cleanup policy should be either keep or delete", msg)
+}
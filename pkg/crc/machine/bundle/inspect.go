@@ -0,0 +1,95 @@
+package bundle
+
+import (
+	"strings"
+)
+
+// Inspection describes the content of a bundle and where it comes from
+type Inspection struct {
+	Name             string `json:"name"`
+	Type             string `json:"type"`
+	FormatVersion    string `json:"formatVersion"`
+	Driver           string `json:"driver"`
+	OpenShiftVersion string `json:"openshiftVersion"`
+	RHCOSVersion     string `json:"rhcosVersion,omitempty"`
+	ReleaseImage     string `json:"releaseImage,omitempty"`
+	InstallerVersion string `json:"installerVersion"`
+	SncVersion       string `json:"sncVersion"`
+	BuildTime        string `json:"buildTime"`
+
+	ImageContentSources []ImageContentSource `json:"imageContentSources"`
+	Operators           []Operator           `json:"operators"`
+	Files               []InspectedFile      `json:"files"`
+}
+
+type InspectedFile struct {
+	File
+	Type string `json:"type"`
+}
+
+const diskImageType = "disk-image"
+
+func (bundle *CrcBundleInfo) Inspect() *Inspection {
+	inspection := &Inspection{
+		Name:                bundle.GetBundleName(),
+		Type:                bundle.Type,
+		FormatVersion:       bundle.Version,
+		Driver:              bundle.DriverInfo.Name,
+		RHCOSVersion:        bundle.BuildInfo.RHCOSVersion,
+		ReleaseImage:        releaseImage(bundle.BuildInfo.OpenshiftInstallerVersion),
+		SncVersion:          bundle.BuildInfo.SncVersion,
+		BuildTime:           bundle.BuildInfo.BuildTime,
+		ImageContentSources: bundle.ClusterInfo.ImageContentSources,
+		Operators:           bundle.ClusterInfo.Operators,
+		Files:               []InspectedFile{},
+	}
+	if bundle.ClusterInfo.OpenShiftVersion != nil {
+		inspection.OpenShiftVersion = bundle.GetOpenshiftVersion()
+	}
+	if lines := strings.SplitN(bundle.BuildInfo.OpenshiftInstallerVersion, "\n", 2); len(lines) > 0 {
+		inspection.InstallerVersion = strings.TrimPrefix(lines[0], "./")
+	}
+	if inspection.ImageContentSources == nil {
+		inspection.ImageContentSources = []ImageContentSource{}
+	}
+	if inspection.Operators == nil {
+		inspection.Operators = []Operator{}
+	}
+	for _, diskImage := range bundle.Storage.DiskImages {
+		inspection.Files = append(inspection.Files, InspectedFile{
+			File: diskImage.File,
+			Type: diskImageType,
+		})
+	}
+	for _, file := range bundle.Storage.Files {
+		inspection.Files = append(inspection.Files, InspectedFile{
+			File: file.File,
+			Type: string(file.Type),
+		})
+	}
+	return inspection
+}
+
+// releaseImage extracts the OpenShift release image from the output of
+// 'openshift-install version'
+func releaseImage(installerVersion string) string {
+	for _, line := range strings.Split(installerVersion, "\n") {
+		if strings.HasPrefix(line, "release image ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "release image "))
+		}
+	}
+	return ""
+}
+
+func (repo *Repository) Inspect(bundleName string) (*Inspection, error) {
+	bundleInfo, err := repo.Get(bundleName)
+	if err != nil {
+		return nil, err
+	}
+	return bundleInfo.Inspect(), nil
+}
+
+// Inspect returns the content of the bundle bundleName from the cache
+func Inspect(bundleName string) (*Inspection, error) {
+	return defaultRepo.Inspect(bundleName)
+}
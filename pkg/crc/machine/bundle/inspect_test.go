@@ -0,0 +1,71 @@
+package bundle
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect(t *testing.T) {
+	var bundle CrcBundleInfo
+	require.NoError(t, json.Unmarshal([]byte(jsonForBundle("crc_libvirt_4.6.1")), &bundle))
+	bundle.BuildInfo.RHCOSVersion = "46.82.202010091720-0"
+	bundle.ClusterInfo.ImageContentSources = []ImageContentSource{
+		{
+			Source:  "quay.io/openshift-release-dev/ocp-release",
+			Mirrors: []string{"registry.example.com/ocp4/openshift4"},
+		},
+	}
+	bundle.ClusterInfo.Operators = []Operator{
+		{
+			Name:    "kube-apiserver",
+			Version: "4.6.1",
+		},
+	}
+
+	assert.Equal(t, &Inspection{
+		Name:             "crc_libvirt_4.6.1",
+		Type:             "snc",
+		FormatVersion:    "1.0",
+		Driver:           "libvirt",
+		OpenShiftVersion: "4.6.1",
+		RHCOSVersion:     "46.82.202010091720-0",
+		ReleaseImage:     "registry.svc.ci.openshift.org/origin/release:4.5",
+		InstallerVersion: "openshift-install v4.6.0",
+		SncVersion:       "git4.1.14-137-g14e7",
+		BuildTime:        "2020-10-26T04:48:26+00:00",
+		ImageContentSources: []ImageContentSource{
+			{
+				Source:  "quay.io/openshift-release-dev/ocp-release",
+				Mirrors: []string{"registry.example.com/ocp4/openshift4"},
+			},
+		},
+		Operators: []Operator{
+			{
+				Name:    "kube-apiserver",
+				Version: "4.6.1",
+			},
+		},
+		Files: []InspectedFile{
+			{
+				File: File{
+					Name:     "crc.qcow2",
+					Size:     "9",
+					Checksum: "245a0e5acd4f09000a9a5f37d731082ed1cf3fdcad1b5320cbe9b153c9fd82a4",
+				},
+				Type: "disk-image",
+			},
+			{
+				File: File{
+					Name:     constants.OcExecutableName,
+					Size:     "72728632",
+					Checksum: "983f0883a6dffd601afa663d10161bfd8033fd6d45cf587a9cb22e9a681d6047",
+				},
+				Type: "oc-executable",
+			},
+		},
+	}, bundle.Inspect())
+}
@@ -0,0 +1,101 @@
+package bundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/pkg/errors"
+)
+
+const manifestFilename = "crc-bundle-manifest.json"
+
+// manifestEntry records a file of an extracted bundle as it was right after
+// its extraction
+type manifestEntry struct {
+	Size      int64  `json:"size"`
+	Sha256sum string `json:"sha256sum"`
+}
+
+// manifest maps the paths of the files of an extracted bundle, relative to
+// the bundle directory, to their manifestEntry
+type manifest map[string]manifestEntry
+
+// writeManifest records the size and hash of all the files in bundleDir
+func writeManifest(bundleDir string) error {
+	m := manifest{}
+	err := filepath.Walk(bundleDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.Mode().IsRegular() || info.Name() == manifestFilename {
+			return err
+		}
+		checksum, err := sha256sum(path)
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(bundleDir, path)
+		if err != nil {
+			return err
+		}
+		m[filepath.ToSlash(relPath)] = manifestEntry{
+			Size:      info.Size(),
+			Sha256sum: checksum,
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.Wrap(err, "cannot compute the checksums of the bundle files")
+	}
+	content, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(bundleDir, manifestFilename), content, 0600)
+}
+
+// verifyManifest checks the files in bundleDir against the manifest recorded
+// at extraction. Loading a bundle only checks that its files are there with
+// the same size, all of them are hashed again when hash is true, which reads
+// the whole bundle. Bundles extracted before the manifest was introduced are
+// not verified.
+func verifyManifest(bundleDir string, hash bool) error {
+	content, err := ioutil.ReadFile(filepath.Join(bundleDir, manifestFilename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			logging.Debugf("No manifest in %s, skipping bundle files verification", bundleDir)
+			return nil
+		}
+		return err
+	}
+	var m manifest
+	if err := json.Unmarshal(content, &m); err != nil {
+		return errors.Wrapf(err, "cannot parse %s", manifestFilename)
+	}
+	for relPath, entry := range m {
+		path := filepath.Join(bundleDir, filepath.FromSlash(relPath))
+		info, err := os.Stat(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return fmt.Errorf("%s not found in bundle", relPath)
+			}
+			return err
+		}
+		if info.Size() != entry.Size {
+			return fmt.Errorf("%s is corrupted: got size %d instead of %d", relPath, info.Size(), entry.Size)
+		}
+		if !hash {
+			continue
+		}
+		logging.Debugf("Verifying the checksum of %s", relPath)
+		checksum, err := sha256sum(path)
+		if err != nil {
+			return err
+		}
+		if checksum != entry.Sha256sum {
+			return fmt.Errorf("%s is corrupted: unexpected sha256sum %s", relPath, checksum)
+		}
+	}
+	return nil
+}
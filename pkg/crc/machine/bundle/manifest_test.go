@@ -0,0 +1,54 @@
+package bundle
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bundle")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	diskImage := filepath.Join(dir, "crc.qcow2")
+	require.NoError(t, ioutil.WriteFile(diskImage, []byte("disk image"), 0600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "bin"), 0750))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "bin", "oc"), []byte("openshift-client"), 0600))
+
+	require.NoError(t, writeManifest(dir))
+	assert.NoError(t, verifyManifest(dir, true))
+
+	// same content, only the modification time changed
+	later := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(diskImage, later, later))
+	assert.NoError(t, verifyManifest(dir, true))
+
+	// corrupted without changing its size nor its modification time
+	info, err := os.Stat(diskImage)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(diskImage, []byte("disk-image"), 0600))
+	require.NoError(t, os.Chtimes(diskImage, info.ModTime(), info.ModTime()))
+	assert.NoError(t, verifyManifest(dir, false))
+	assert.Contains(t, verifyManifest(dir, true).Error(), "crc.qcow2 is corrupted: unexpected sha256sum")
+
+	require.NoError(t, ioutil.WriteFile(diskImage, []byte("truncated"), 0600))
+	assert.EqualError(t, verifyManifest(dir, false), "crc.qcow2 is corrupted: got size 9 instead of 10")
+
+	require.NoError(t, ioutil.WriteFile(diskImage, []byte("disk image"), 0600))
+	require.NoError(t, os.Remove(filepath.Join(dir, "bin", "oc")))
+	assert.EqualError(t, verifyManifest(dir, true), "bin/oc not found in bundle")
+}
+
+func TestVerifyWithoutManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bundle")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.NoError(t, verifyManifest(dir, true))
+}
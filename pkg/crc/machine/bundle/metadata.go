@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/Masterminds/semver/v3"
+	"github.com/code-ready/crc/pkg/crc/constants"
 )
 
 // Metadata structure to unmarshal the crc-bundle-info.json file
@@ -31,6 +32,7 @@ type BuildInfo struct {
 	BuildTime                 string `json:"buildTime"`
 	OpenshiftInstallerVersion string `json:"openshiftInstallerVersion"`
 	SncVersion                string `json:"sncVersion"`
+	RHCOSVersion              string `json:"rhcosVersion,omitempty"`
 }
 
 type ClusterInfo struct {
@@ -39,8 +41,29 @@ type ClusterInfo struct {
 	BaseDomain          string          `json:"baseDomain"`
 	AppsDomain          string          `json:"appsDomain"`
 	SSHPrivateKeyFile   string          `json:"sshPrivateKeyFile"`
+	SSHUser             string          `json:"sshUser,omitempty"`
+	PrivilegeEscalation string          `json:"privilegeEscalation,omitempty"`
 	KubeConfig          string          `json:"kubeConfig"`
 	OpenshiftPullSecret string          `json:"openshiftPullSecret,omitempty"`
+	// registries the images of the cluster are pulled from instead of their source
+	ImageContentSources []ImageContentSource `json:"imageContentSources,omitempty"`
+	Operators           []Operator           `json:"operators,omitempty"`
+	// resources the cluster needs to run, memory is in MiB
+	MinimumMemory int `json:"minimumMemory,omitempty"`
+	MinimumCPUs   int `json:"minimumCPUs,omitempty"`
+	// host CPU features the VM needs to boot: /proc/cpuinfo flag names, x86-64
+	// microarchitecture levels such as x86-64-v2, or alternatives such as vmx|svm
+	RequiredCPUFeatures []string `json:"requiredCPUFeatures,omitempty"`
+}
+
+type ImageContentSource struct {
+	Source  string   `json:"source"`
+	Mirrors []string `json:"mirrors"`
+}
+
+type Operator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
 }
 
 type Node struct {
@@ -137,6 +160,45 @@ func (bundle *CrcBundleInfo) GetSSHKeyPath() string {
 	return bundle.resolvePath(bundle.ClusterInfo.SSHPrivateKeyFile)
 }
 
+func (bundle *CrcBundleInfo) GetSSHUser() string {
+	if bundle.ClusterInfo.SSHUser == "" {
+		return constants.DefaultSSHUser
+	}
+	return bundle.ClusterInfo.SSHUser
+}
+
+// GetPrivilegeEscalation returns how root access is obtained by the ssh user, "sudo" by default
+func (bundle *CrcBundleInfo) GetPrivilegeEscalation() string {
+	if bundle.ClusterInfo.PrivilegeEscalation == "" {
+		return "sudo"
+	}
+	return bundle.ClusterInfo.PrivilegeEscalation
+}
+
+// GetSSHUserHomeDir returns the home directory of the ssh user in the VM
+func (bundle *CrcBundleInfo) GetSSHUserHomeDir() string {
+	if bundle.GetSSHUser() == "root" {
+		return "/root"
+	}
+	return fmt.Sprintf("/home/%s", bundle.GetSSHUser())
+}
+
+// GetMinimumMemory returns the memory in MiB needed by the cluster of the bundle
+func (bundle *CrcBundleInfo) GetMinimumMemory() int {
+	if bundle.ClusterInfo.MinimumMemory == 0 {
+		return constants.DefaultMemory
+	}
+	return bundle.ClusterInfo.MinimumMemory
+}
+
+// GetMinimumCPUs returns the number of CPUs needed by the cluster of the bundle
+func (bundle *CrcBundleInfo) GetMinimumCPUs() int {
+	if bundle.ClusterInfo.MinimumCPUs == 0 {
+		return constants.DefaultCPUs
+	}
+	return bundle.ClusterInfo.MinimumCPUs
+}
+
 func (bundle *CrcBundleInfo) GetKernelPath() string {
 	if bundle.Nodes[0].Kernel == "" {
 		return ""
@@ -0,0 +1,145 @@
+package bundle
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+// InUseError is returned when removing a bundle would break the instances
+// created from it, their disk image is backed by the one of the bundle
+type InUseError struct {
+	Bundle    string
+	Instances []string
+}
+
+func (err *InUseError) Error() string {
+	return fmt.Sprintf("bundle %s is used by the instances %s, delete them first or use --force to remove it anyway",
+		err.Bundle, strings.Join(err.Instances, ", "))
+}
+
+// machineConfig is the part of the libmachine configuration of an instance
+// telling which bundle it was created from
+type machineConfig struct {
+	Driver struct {
+		BundleName string
+	}
+}
+
+// References returns the sorted names of the instances created from the
+// bundle, according to the configuration of the machines in MachinesDir
+func (repo *Repository) References(bundleName string) ([]string, error) {
+	if repo.MachinesDir == "" {
+		return nil, nil
+	}
+	dirs, err := ioutil.ReadDir(repo.MachinesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	bundleName = GetBundleNameWithoutExtension(bundleName)
+	var instances []string
+	for _, dir := range dirs {
+		if !dir.IsDir() {
+			continue
+		}
+		data, err := ioutil.ReadFile(filepath.Join(repo.MachinesDir, dir.Name(), "config.json"))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		var config machineConfig
+		if err := json.Unmarshal(data, &config); err != nil {
+			logging.Debugf("Cannot read the configuration of %s: %v", dir.Name(), err)
+			continue
+		}
+		if GetBundleNameWithoutExtension(config.Driver.BundleName) == bundleName {
+			instances = append(instances, dir.Name())
+		}
+	}
+	sort.Strings(instances)
+	return instances, nil
+}
+
+// Remove deletes the extracted bundle and its archive from the cache. It
+// fails with an InUseError when instances use the bundle, unless force is
+// set.
+func (repo *Repository) Remove(bundleName string, force bool) error {
+	bundleName = GetBundleNameWithoutExtension(bundleName)
+	bundleDir := filepath.Join(repo.CacheDir, bundleName)
+	archivePath := filepath.Join(repo.CacheDir, bundleName+bundleExtension)
+	if !IsExtracted(bundleDir) && !crcos.FileExists(archivePath) {
+		return fmt.Errorf("bundle %s is not in %s", bundleName, repo.CacheDir)
+	}
+	instances, err := repo.References(bundleName)
+	if err != nil {
+		return err
+	}
+	if len(instances) != 0 {
+		if !force {
+			return &InUseError{Bundle: bundleName, Instances: instances}
+		}
+		logging.Warnf("Removing bundle %s, the instances %s will not start anymore", bundleName, strings.Join(instances, ", "))
+	}
+	if err := os.RemoveAll(bundleDir); err != nil {
+		return err
+	}
+	if err := os.Remove(archivePath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// Prune deletes the extracted bundles no instance uses and returns their
+// names
+func (repo *Repository) Prune() ([]string, error) {
+	files, err := ioutil.ReadDir(repo.CacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var pruned []string
+	for _, file := range files {
+		if !file.IsDir() || !IsExtracted(filepath.Join(repo.CacheDir, file.Name())) {
+			continue
+		}
+		instances, err := repo.References(file.Name())
+		if err != nil {
+			return pruned, err
+		}
+		if len(instances) != 0 {
+			logging.Debugf("Keeping bundle %s, it is used by %s", file.Name(), strings.Join(instances, ", "))
+			continue
+		}
+		if err := repo.Remove(file.Name(), false); err != nil {
+			return pruned, err
+		}
+		pruned = append(pruned, file.Name())
+	}
+	return pruned, nil
+}
+
+func References(bundleName string) ([]string, error) {
+	return defaultRepo.References(bundleName)
+}
+
+func Remove(bundleName string, force bool) error {
+	return defaultRepo.Remove(bundleName, force)
+}
+
+func Prune() ([]string, error) {
+	return defaultRepo.Prune()
+}
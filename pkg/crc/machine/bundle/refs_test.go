@@ -0,0 +1,79 @@
+package bundle
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeMachineConfig(t *testing.T, machinesDir, instance, bundleName string) {
+	require.NoError(t, os.MkdirAll(filepath.Join(machinesDir, instance), 0750))
+	config := fmt.Sprintf(`{"ConfigVersion": 3, "Driver": {"MachineName": %q, "BundleName": %q}, "DriverName": "libvirt"}`, instance, bundleName)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(machinesDir, instance, "config.json"), []byte(config), 0600))
+}
+
+func TestReferences(t *testing.T) {
+	dir := t.TempDir()
+	createDummyBundleContent(t, dir, "crc_libvirt_4.6.1", "1.0")
+	machinesDir := t.TempDir()
+	repo := &Repository{CacheDir: dir, MachinesDir: machinesDir}
+
+	writeMachineConfig(t, machinesDir, "test411", "crc_libvirt_4.6.1.crcbundle")
+	writeMachineConfig(t, machinesDir, "crc", "crc_libvirt_4.6.1.crcbundle")
+	writeMachineConfig(t, machinesDir, "other", "crc_libvirt_4.7.0.crcbundle")
+	instances, err := repo.References("crc_libvirt_4.6.1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"crc", "test411"}, instances)
+
+	err = repo.Remove("crc_libvirt_4.6.1", false)
+	var inUse *InUseError
+	require.True(t, errors.As(err, &inUse))
+	assert.EqualError(t, err, "bundle crc_libvirt_4.6.1 is used by the instances crc, test411, delete them first or use --force to remove it anyway")
+	assert.True(t, IsExtracted(filepath.Join(dir, "crc_libvirt_4.6.1")))
+
+	require.NoError(t, os.RemoveAll(filepath.Join(machinesDir, "crc")))
+	require.NoError(t, os.RemoveAll(filepath.Join(machinesDir, "test411")))
+	instances, err = repo.References("crc_libvirt_4.6.1")
+	require.NoError(t, err)
+	assert.Empty(t, instances)
+	require.NoError(t, repo.Remove("crc_libvirt_4.6.1", false))
+	assert.False(t, IsExtracted(filepath.Join(dir, "crc_libvirt_4.6.1")))
+}
+
+func TestForceRemove(t *testing.T) {
+	dir := t.TempDir()
+	createDummyBundleContent(t, dir, "crc_libvirt_4.6.1", "1.0")
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "crc_libvirt_4.6.1.crcbundle"), []byte("bundle"), 0600))
+	machinesDir := t.TempDir()
+	repo := &Repository{CacheDir: dir, MachinesDir: machinesDir}
+	writeMachineConfig(t, machinesDir, "crc", "crc_libvirt_4.6.1.crcbundle")
+
+	require.NoError(t, repo.Remove("crc_libvirt_4.6.1", true))
+	entries, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	createDummyBundleContent(t, dir, "crc_libvirt_4.6.1", "1.0")
+	createDummyBundleContent(t, dir, "crc_libvirt_4.7.0", "1.0")
+	machinesDir := t.TempDir()
+	repo := &Repository{CacheDir: dir, MachinesDir: machinesDir}
+	writeMachineConfig(t, machinesDir, "crc", "crc_libvirt_4.7.0.crcbundle")
+
+	pruned, err := repo.Prune()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"crc_libvirt_4.6.1"}, pruned)
+	assert.True(t, IsExtracted(filepath.Join(dir, "crc_libvirt_4.7.0")))
+
+	bundles, err := repo.List()
+	require.NoError(t, err)
+	require.Len(t, bundles, 1)
+}
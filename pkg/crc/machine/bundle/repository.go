@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -29,6 +30,9 @@ const (
 type Repository struct {
 	CacheDir string
 	OcBinDir string
+	// MachinesDir holds the configuration of the instances, which tells
+	// the bundles they use
+	MachinesDir string
 }
 
 func (repo *Repository) Get(bundleName string) (*CrcBundleInfo, error) {
@@ -53,6 +57,9 @@ func (repo *Repository) Get(bundleName string) (*CrcBundleInfo, error) {
 	if err := bundleInfo.verify(); err != nil {
 		return nil, err
 	}
+	if err := verifyManifest(path, false); err != nil {
+		return nil, err
+	}
 	if fmt.Sprintf(".%s", bundleInfo.ClusterInfo.AppsDomain) != constants.AppsDomain {
 		return nil, fmt.Errorf("unexpected bundle, it must have %s apps domain", constants.AppsDomain)
 	}
@@ -138,21 +145,90 @@ func (bundle *CrcBundleInfo) createSymlinkOrCopyPodmanClient(ocBinDir string) er
 	return os.Symlink(podmanInBundle, podmanInBinDir)
 }
 
-func (repo *Repository) Extract(path string) error {
-	bundleName := filepath.Base(path)
+func (repo *Repository) Extract(path string, signatureCheck SignatureCheck) error {
+	if err := signatureCheck.Verify(path); err != nil {
+		return &SignatureError{Path: path, Err: err}
+	}
+	return repo.install(filepath.Base(path), func(tmpDir string) error {
+		_, err := extract.Uncompress(path, tmpDir, true)
+		return err
+	})
+}
+
+// ExtractStream extracts the bundle called bundleName from reader, while it
+// is downloaded. verify is called once the whole bundle is read, and the
+// bundle is only installed when it succeeds.
+func (repo *Repository) ExtractStream(bundleName string, reader io.Reader, verify func() error) error {
+	return repo.install(bundleName, func(tmpDir string) error {
+		if _, err := extract.UncompressStream(reader, tmpDir); err != nil {
+			return err
+		}
+		// the end of the archive may not be read by the extraction, it is
+		// needed to verify the bundle
+		if _, err := io.Copy(ioutil.Discard, reader); err != nil {
+			return err
+		}
+		return verify()
+	})
+}
+
+// InstallFiles installs the bundle called bundleName from its files, which
+// write creates in the directory it is given. It is used for the bundles
+// which are not distributed as an archive.
+func (repo *Repository) InstallFiles(bundleName string, write func(bundleDir string) error) error {
+	return repo.install(bundleName, func(tmpDir string) error {
+		bundleDir := filepath.Join(tmpDir, GetBundleNameWithoutExtension(bundleName))
+		if err := os.MkdirAll(bundleDir, 0750); err != nil {
+			return err
+		}
+		if err := write(bundleDir); err != nil {
+			return err
+		}
+		return makeExecutablesExecutable(bundleDir)
+	})
+}
+
+// makeExecutablesExecutable sets the permissions of the executables listed
+// in the metadata of the bundle in bundleDir, they are lost when the files
+// are not extracted from an archive
+func makeExecutablesExecutable(bundleDir string) error {
+	content, err := ioutil.ReadFile(filepath.Join(bundleDir, metadataFilename))
+	if err != nil {
+		return errors.Wrapf(err, "error reading %s file", metadataFilename)
+	}
+	var bundleInfo CrcBundleInfo
+	if err := json.Unmarshal(content, &bundleInfo); err != nil {
+		return errors.Wrap(err, "error Unmarshal the data")
+	}
+	for _, file := range bundleInfo.Storage.Files {
+		if file.Type != OcExecutable && file.Type != PodmanExecutable {
+			continue
+		}
+		if err := os.Chmod(filepath.Join(bundleDir, file.Name), 0755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+// install extracts a bundle to a temporary directory with uncompress, then
+// moves it to the cache
+func (repo *Repository) install(bundleName string, uncompress func(tmpDir string) error) error {
 	tmpDir := filepath.Join(repo.CacheDir, "tmp-extract")
 	_ = os.RemoveAll(tmpDir) // clean up before using it
 	defer func() {
 		_ = os.RemoveAll(tmpDir) // clean up after using it
 	}()
 
-	if _, err := extract.Uncompress(path, tmpDir, true); err != nil {
+	if err := uncompress(tmpDir); err != nil {
 		return err
 	}
 
 	bundleBaseDir := GetBundleNameWithoutExtension(bundleName)
 	bundleDir := filepath.Join(repo.CacheDir, bundleBaseDir)
+	if err := checkPathLengths(filepath.Join(tmpDir, bundleBaseDir), bundleDir); err != nil {
+		return err
+	}
 	_ = os.RemoveAll(bundleDir)
 	err := crcerrors.Retry(context.Background(), time.Minute, func() error {
 		if err := os.Rename(filepath.Join(tmpDir, bundleBaseDir), bundleDir); err != nil {
@@ -163,10 +239,33 @@ func (repo *Repository) Extract(path string) error {
 	if err != nil {
 		return err
 	}
+	if err := writeManifest(bundleDir); err != nil {
+		return err
+	}
 
 	return os.Chmod(bundleDir, 0755)
 }
 
+// checkPathLengths checks that the hypervisor can use the files extracted to
+// extractDir once they are moved to bundleDir
+func checkPathLengths(extractDir, bundleDir string) error {
+	return filepath.Walk(extractDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(extractDir, path)
+		if err != nil {
+			return err
+		}
+		return crcos.CheckPathLength(filepath.Join(bundleDir, rel))
+	})
+}
+
+// IsExtracted returns true when dir holds an extracted bundle
+func IsExtracted(dir string) bool {
+	return crcos.FileExists(filepath.Join(dir, metadataFilename))
+}
+
 func (repo *Repository) List() ([]CrcBundleInfo, error) {
 	files, err := ioutil.ReadDir(repo.CacheDir)
 	if err != nil {
@@ -191,8 +290,9 @@ func (repo *Repository) List() ([]CrcBundleInfo, error) {
 }
 
 var defaultRepo = &Repository{
-	CacheDir: constants.MachineCacheDir,
-	OcBinDir: constants.CrcOcBinDir,
+	CacheDir:    constants.MachineCacheDir,
+	OcBinDir:    constants.CrcOcBinDir,
+	MachinesDir: constants.MachineInstanceDir,
 }
 
 func Get(bundleName string) (*CrcBundleInfo, error) {
@@ -203,13 +303,41 @@ func Use(bundleName string) (*CrcBundleInfo, error) {
 	return defaultRepo.Use(bundleName)
 }
 
-func Extract(path string) (*CrcBundleInfo, error) {
-	if err := defaultRepo.Extract(path); err != nil {
+func Extract(path string, signatureCheck SignatureCheck) (*CrcBundleInfo, error) {
+	if err := defaultRepo.Extract(path, signatureCheck); err != nil {
 		return nil, err
 	}
 	return defaultRepo.Get(filepath.Base(path))
 }
 
+func ExtractStream(bundleName string, reader io.Reader, verify func() error) (*CrcBundleInfo, error) {
+	if err := defaultRepo.ExtractStream(bundleName, reader, verify); err != nil {
+		return nil, err
+	}
+	return defaultRepo.Get(bundleName)
+}
+
+func InstallFiles(bundleName string, write func(bundleDir string) error) (*CrcBundleInfo, error) {
+	if err := defaultRepo.InstallFiles(bundleName, write); err != nil {
+		return nil, err
+	}
+	return defaultRepo.Get(bundleName)
+}
+
+// Verify hashes all the files of the extracted bundle bundleName, to detect
+// a corruption which does not change their size
+func (repo *Repository) Verify(bundleName string) error {
+	bundleInfo, err := repo.Get(bundleName)
+	if err != nil {
+		return err
+	}
+	return verifyManifest(bundleInfo.cachedPath, true)
+}
+
+func Verify(bundleName string) error {
+	return defaultRepo.Verify(bundleName)
+}
+
 func List() ([]CrcBundleInfo, error) {
 	return defaultRepo.List()
 }
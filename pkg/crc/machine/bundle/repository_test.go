@@ -1,6 +1,7 @@
 package bundle
 
 import (
+	"errors"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -8,7 +9,9 @@ import (
 	"testing"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/extract"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUse(t *testing.T) {
@@ -71,7 +74,7 @@ func TestExtract(t *testing.T) {
 		OcBinDir: ocBinDir,
 	}
 
-	assert.NoError(t, repo.Extract(filepath.Join("testdata", testBundle(t))))
+	assert.NoError(t, repo.Extract(filepath.Join("testdata", testBundle(t)), SignatureCheck{}))
 
 	bundle, err := repo.Get(testBundle(t))
 	assert.NoError(t, err)
@@ -83,6 +86,55 @@ func TestExtract(t *testing.T) {
 	assert.EqualError(t, err, "kubeconfig not found in bundle")
 }
 
+func TestExtractStream(t *testing.T) {
+	repo := &Repository{
+		CacheDir: t.TempDir(),
+		OcBinDir: t.TempDir(),
+	}
+
+	extractStream := func(verify func() error) error {
+		file, err := os.Open(filepath.Join("testdata", testBundle(t)))
+		require.NoError(t, err)
+		defer file.Close()
+		return repo.ExtractStream(testBundle(t), file, verify)
+	}
+
+	assert.EqualError(t, extractStream(func() error { return errors.New("invalid checksum") }), "invalid checksum")
+	_, err := repo.Get(testBundle(t))
+	assert.Error(t, err)
+
+	assert.NoError(t, extractStream(func() error { return nil }))
+	bundle, err := repo.Get(testBundle(t))
+	require.NoError(t, err)
+	assert.Equal(t, "4.6.1", bundle.GetOpenshiftVersion())
+}
+
+func TestInstallFiles(t *testing.T) {
+	repo := &Repository{
+		CacheDir: t.TempDir(),
+		OcBinDir: t.TempDir(),
+	}
+	extractedDir := t.TempDir()
+	_, err := extract.Uncompress(filepath.Join("testdata", testBundle(t)), extractedDir, false)
+	require.NoError(t, err)
+
+	assert.NoError(t, repo.InstallFiles(testBundle(t), func(bundleDir string) error {
+		files, err := ioutil.ReadDir(filepath.Join(extractedDir, GetBundleNameWithoutExtension(testBundle(t))))
+		if err != nil {
+			return err
+		}
+		for _, file := range files {
+			if err := os.Rename(filepath.Join(extractedDir, GetBundleNameWithoutExtension(testBundle(t)), file.Name()), filepath.Join(bundleDir, file.Name())); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+	bundle, err := repo.Get(testBundle(t))
+	require.NoError(t, err)
+	assert.Equal(t, "4.6.1", bundle.GetOpenshiftVersion())
+}
+
 func testBundle(t *testing.T) string {
 	switch runtime.GOOS {
 	case "darwin":
@@ -0,0 +1,175 @@
+package bundle
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/pkg/errors"
+)
+
+const signatureExtension = ".sig"
+
+// SignatureCheck describes how the detached signature of a bundle is
+// verified before it is extracted
+type SignatureCheck struct {
+	// PublicKeyPath is the PEM-encoded public key the bundle is signed
+	// with, the signature is not verified when it is empty
+	PublicKeyPath string
+	// Skip extracts the bundle even when its signature is missing or invalid
+	Skip bool
+}
+
+// SignatureError is returned when a bundle is not extracted because its
+// signature cannot be verified
+type SignatureError struct {
+	Path string
+	Err  error
+}
+
+func (err *SignatureError) Error() string {
+	return fmt.Sprintf("refusing to extract %s: %v", filepath.Base(err.Path), err.Err)
+}
+
+func (err *SignatureError) Unwrap() error {
+	return err.Err
+}
+
+// SignaturePath returns the path of the detached signature of the bundle at
+// bundlePath
+func SignaturePath(bundlePath string) string {
+	return bundlePath + signatureExtension
+}
+
+// Verify checks the detached signature of the bundle at bundlePath. The
+// signature is the base64-encoded signature of the sha256 sum of the bundle,
+// as produced by 'cosign sign-blob', or the raw signature as produced by
+// 'openssl dgst -sha256 -sign'.
+func (check SignatureCheck) Verify(bundlePath string) error {
+	if check.PublicKeyPath == "" {
+		logging.Debugf("No bundle signing key configured, not verifying the signature of %s", bundlePath)
+		return nil
+	}
+	return check.result(bundlePath, verifySignature(bundlePath, check.PublicKeyPath))
+}
+
+// VerifyDigest checks the signature of the bundle called name against the
+// sha256 digest of its content, for bundles which are extracted while they
+// are downloaded
+func (check SignatureCheck) VerifyDigest(name string, digest, signature []byte) error {
+	if check.PublicKeyPath == "" {
+		logging.Debugf("No bundle signing key configured, not verifying the signature of %s", name)
+		return nil
+	}
+	return check.result(name, verifyDigestSignature(name, check.PublicKeyPath, digest, decodeSignature(signature)))
+}
+
+func (check SignatureCheck) result(name string, err error) error {
+	if err != nil {
+		if check.Skip {
+			logging.Warnf("Extracting %s despite the failed signature verification: %v", name, err)
+			return nil
+		}
+		return err
+	}
+	logging.Debugf("Verified the signature of %s", name)
+	return nil
+}
+
+func verifySignature(bundlePath, publicKeyPath string) error {
+	signature, err := readSignature(SignaturePath(bundlePath))
+	if err != nil {
+		return err
+	}
+	digest, err := sha256File(bundlePath)
+	if err != nil {
+		return err
+	}
+	return verifyDigestSignature(bundlePath, publicKeyPath, digest, signature)
+}
+
+func verifyDigestSignature(name, publicKeyPath string, digest, signature []byte) error {
+	publicKey, err := readPublicKey(publicKeyPath)
+	if err != nil {
+		return err
+	}
+	if !verifyDigest(publicKey, digest, signature) {
+		return fmt.Errorf("invalid signature for %s, the bundle may have been tampered with", name)
+	}
+	return nil
+}
+
+func readPublicKey(path string) (crypto.PublicKey, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read the bundle signing key")
+	}
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM-encoded public key", path)
+	}
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot parse the public key in %s", path)
+	}
+	switch publicKey.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey:
+		return publicKey, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T in %s, expected an ECDSA or RSA key", publicKey, path)
+	}
+}
+
+func readSignature(path string) ([]byte, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("missing bundle signature %s", path)
+		}
+		return nil, errors.Wrap(err, "cannot read the bundle signature")
+	}
+	return decodeSignature(content), nil
+}
+
+// decodeSignature returns the raw signature, signatures can be base64-encoded
+func decodeSignature(content []byte) []byte {
+	if decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(content))); err == nil {
+		return decoded
+	}
+	return content
+}
+
+func sha256File(path string) ([]byte, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+func verifyDigest(publicKey crypto.PublicKey, digest, signature []byte) bool {
+	switch key := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		return ecdsa.VerifyASN1(key, digest, signature)
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest, signature) == nil
+	default:
+		return false
+	}
+}
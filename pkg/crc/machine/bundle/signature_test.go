@@ -0,0 +1,89 @@
+package bundle
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSignedBundle(t *testing.T, dir string, key *ecdsa.PrivateKey, content string) string {
+	bundlePath := filepath.Join(dir, "crc_libvirt_4.6.1.crcbundle")
+	require.NoError(t, ioutil.WriteFile(bundlePath, []byte(content), 0600))
+	digest := sha256.Sum256([]byte(content))
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(SignaturePath(bundlePath), []byte(base64.StdEncoding.EncodeToString(signature)), 0600))
+	return bundlePath
+}
+
+func writePublicKey(t *testing.T, dir string, key *ecdsa.PrivateKey) string {
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+	keyPath := filepath.Join(dir, "bundle.pub")
+	require.NoError(t, ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+	return keyPath
+}
+
+func TestVerifySignature(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signature")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	bundlePath := writeSignedBundle(t, dir, key, "bundle content")
+	check := SignatureCheck{PublicKeyPath: writePublicKey(t, dir, key)}
+
+	assert.NoError(t, check.Verify(bundlePath))
+
+	require.NoError(t, ioutil.WriteFile(bundlePath, []byte("tampered content"), 0600))
+	assert.EqualError(t, check.Verify(bundlePath), "invalid signature for "+bundlePath+", the bundle may have been tampered with")
+
+	check.Skip = true
+	assert.NoError(t, check.Verify(bundlePath))
+}
+
+func TestVerifySignatureMissing(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signature")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	bundlePath := writeSignedBundle(t, dir, key, "bundle content")
+	require.NoError(t, os.Remove(SignaturePath(bundlePath)))
+
+	assert.NoError(t, SignatureCheck{}.Verify(bundlePath))
+	assert.EqualError(t, SignatureCheck{PublicKeyPath: writePublicKey(t, dir, key)}.Verify(bundlePath), "missing bundle signature "+SignaturePath(bundlePath))
+}
+
+func TestExtractRefusesInvalidSignature(t *testing.T) {
+	dir, err := ioutil.TempDir("", "repo")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	repo := &Repository{
+		CacheDir: dir,
+		OcBinDir: dir,
+	}
+
+	err = repo.Extract(filepath.Join("testdata", testBundle(t)), SignatureCheck{PublicKeyPath: writePublicKey(t, dir, otherKey)})
+	var signatureErr *SignatureError
+	assert.True(t, errors.As(err, &signatureErr))
+	_, err = os.Stat(filepath.Join(dir, GetBundleNameWithoutExtension(testBundle(t))))
+	assert.True(t, os.IsNotExist(err))
+}
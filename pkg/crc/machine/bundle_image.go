@@ -0,0 +1,236 @@
+package machine
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/oci"
+	"github.com/docker/go-units"
+	"github.com/pkg/errors"
+)
+
+const (
+	bundleExtension = ".crcbundle"
+	// bundleImageRecordFile remembers the bundle stored in the last bundle
+	// image, an existing cluster can be started without the registry
+	bundleImageRecordFile = "bundle-image.json"
+	maxSignatureSize      = 64 * 1024
+)
+
+// bundleImage is a bundle distributed as an OCI artifact. Its manifest has
+// the file name of the bundle as org.opencontainers.image.title annotation,
+// like its layers, as pushed by 'oras push'. The artifact either has a layer
+// with the bundle archive, and optionally one with its detached signature,
+// or one layer per file of the bundle, such as the metadata, the disk image
+// and the kernel.
+type bundleImage struct {
+	ref    oci.Reference
+	client *oci.Client
+	// manifest is nil when the registry cannot be reached
+	manifest *oci.Manifest
+}
+
+type bundleImageRecord struct {
+	Image  string `json:"image"`
+	Bundle string `json:"bundle"`
+	Digest string `json:"digest"`
+}
+
+// resolveBundleImage returns the file name of the bundle stored in image
+func resolveBundleImage(image string) (string, *bundleImage, error) {
+	ref, err := oci.ParseReference(image)
+	if err != nil {
+		return "", nil, err
+	}
+	bundleImage := &bundleImage{ref: ref, client: oci.New()}
+	manifest, err := bundleImage.client.Manifest(ref)
+	if err != nil {
+		record, recordErr := readBundleImageRecord()
+		if recordErr != nil || record.Image != image {
+			return "", nil, errors.Wrapf(err, "Failed to fetch the bundle image %s", image)
+		}
+		logging.Warnf("Cannot fetch the bundle image %s, using the bundle %s pulled previously: %v", image, record.Bundle, err)
+		return record.Bundle, bundleImage, nil
+	}
+	bundleName, err := bundleImageName(manifest)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "Invalid bundle image %s", image)
+	}
+	logging.Debugf("Bundle image %s (%s) stores %s", image, manifest.Digest, bundleName)
+	if err := writeBundleImageRecord(bundleImageRecord{Image: image, Bundle: bundleName, Digest: manifest.Digest}); err != nil {
+		logging.Debugf("Cannot write the bundle image record: %v", err)
+	}
+	bundleImage.manifest = manifest
+	return bundleName, bundleImage, nil
+}
+
+// bundleImageName returns the file name of the bundle in the artifact
+// described by manifest
+func bundleImageName(manifest *oci.Manifest) (string, error) {
+	name := manifest.Title()
+	if name == "" {
+		for _, layer := range manifest.Layers {
+			if strings.HasSuffix(layer.Title(), bundleExtension) {
+				name = layer.Title()
+			}
+		}
+	}
+	if name == "" {
+		return "", fmt.Errorf("the manifest has no %s annotation with the name of the bundle", oci.AnnotationTitle)
+	}
+	if !strings.HasSuffix(name, bundleExtension) || !isFileName(name) {
+		return "", fmt.Errorf("'%s' is not a valid bundle name", name)
+	}
+	return name, nil
+}
+
+// isFileName checks that a name coming from the registry cannot escape
+// the directory where it is written
+func isFileName(name string) bool {
+	return name != "" && name != "." && name != ".." && filepath.Base(name) == name && !strings.ContainsAny(name, `/\`)
+}
+
+func (image *bundleImage) layer(title string) (oci.Descriptor, bool) {
+	for _, layer := range image.manifest.Layers {
+		if layer.Title() == title {
+			return layer, true
+		}
+	}
+	return oci.Descriptor{}, false
+}
+
+// pull installs the bundle called bundleName from the registry
+func (image *bundleImage) pull(bundleName string, signatureCheck bundle.SignatureCheck) error {
+	if image.manifest == nil {
+		return fmt.Errorf("Failed to pull %s from %s, the registry cannot be reached", bundleName, image.ref)
+	}
+	if archive, ok := image.layer(bundleName); ok {
+		return image.pullArchive(bundleName, archive, signatureCheck)
+	}
+	return image.pullFiles(bundleName, signatureCheck)
+}
+
+// pullArchive extracts the bundle archive while it is pulled
+func (image *bundleImage) pullArchive(bundleName string, archive oci.Descriptor, signatureCheck bundle.SignatureCheck) error {
+	var signature []byte
+	if signatureCheck.PublicKeyPath != "" {
+		var err error
+		if signature, err = image.readSignature(bundleName); err != nil {
+			return errors.Wrap(err, "Failed to pull the bundle signature")
+		}
+	}
+
+	logging.Infof("Pulling and extracting bundle %s (%s) from %s...", bundleName, units.HumanSize(float64(archive.Size)), image.ref)
+	image.client.Downloader.Progress = logDownloadProgress
+	reader, err := image.client.Layer(image.ref, archive)
+	if err != nil {
+		return errors.Wrap(err, "Failed to pull the bundle")
+	}
+	defer reader.Close()
+	hash := sha256.New()
+	var verifyErr error
+	_, err = bundle.ExtractStream(bundleName, io.TeeReader(reader, hash), func() error {
+		verifyErr = verifyStreamedBundle(bundleName, hash.Sum(nil), "", signatureCheck, signature)
+		return verifyErr
+	})
+	if verifyErr != nil {
+		return verifyErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "Failed to pull the bundle")
+	}
+	return nil
+}
+
+// readSignature returns the detached signature of the bundle archive, it is
+// empty when the artifact has none
+func (image *bundleImage) readSignature(bundleName string) ([]byte, error) {
+	layer, ok := image.layer(filepath.Base(bundle.SignaturePath(bundleName)))
+	if !ok {
+		return nil, nil
+	}
+	reader, err := image.client.Layer(image.ref, layer)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return ioutil.ReadAll(io.LimitReader(reader, maxSignatureSize))
+}
+
+// pullFiles installs the bundle from the layers storing its files
+func (image *bundleImage) pullFiles(bundleName string, signatureCheck bundle.SignatureCheck) error {
+	if signatureCheck.PublicKeyPath != "" {
+		err := &bundle.SignatureError{Path: bundleName, Err: errors.New("only the bundle images storing the bundle archive can be verified")}
+		if !signatureCheck.Skip {
+			return err
+		}
+		logging.Warnf("Pulling %s despite the failed signature verification: %v", bundleName, err.Err)
+	}
+	for _, layer := range image.manifest.Layers {
+		if !isFileName(layer.Title()) {
+			return fmt.Errorf("Invalid bundle image %s, the layer %s has the invalid file name '%s'", image.ref, layer.Digest, layer.Title())
+		}
+	}
+
+	logging.Infof("Pulling bundle %s from %s...", bundleName, image.ref)
+	image.client.Downloader.Progress = logDownloadProgress
+	_, err := bundle.InstallFiles(bundleName, func(bundleDir string) error {
+		for _, layer := range image.manifest.Layers {
+			logging.Infof("Pulling %s (%s)", layer.Title(), units.HumanSize(float64(layer.Size)))
+			if err := image.pullFile(layer, filepath.Join(bundleDir, layer.Title())); err != nil {
+				return errors.Wrapf(err, "Failed to pull %s", layer.Title())
+			}
+		}
+		return nil
+	})
+	return err
+}
+
+func (image *bundleImage) pullFile(layer oci.Descriptor, path string) error {
+	reader, err := image.client.Layer(image.ref, layer)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, reader); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func readBundleImageRecord() (*bundleImageRecord, error) {
+	content, err := ioutil.ReadFile(filepath.Join(constants.MachineCacheDir, bundleImageRecordFile))
+	if err != nil {
+		return nil, err
+	}
+	var record bundleImageRecord
+	if err := json.Unmarshal(content, &record); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func writeBundleImageRecord(record bundleImageRecord) error {
+	content, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(constants.MachineCacheDir, 0750); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(constants.MachineCacheDir, bundleImageRecordFile), content, 0600)
+}
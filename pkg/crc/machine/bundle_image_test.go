@@ -0,0 +1,39 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/oci"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func titled(title string) map[string]string {
+	return map[string]string{oci.AnnotationTitle: title}
+}
+
+func TestBundleImageName(t *testing.T) {
+	name, err := bundleImageName(&oci.Manifest{Annotations: titled("crc_libvirt_4.7.0.crcbundle")})
+	require.NoError(t, err)
+	assert.Equal(t, "crc_libvirt_4.7.0.crcbundle", name)
+
+	name, err = bundleImageName(&oci.Manifest{Layers: []oci.Descriptor{
+		{Annotations: titled("crc_libvirt_4.7.0.crcbundle.sig")},
+		{Annotations: titled("crc_libvirt_4.7.0.crcbundle")},
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, "crc_libvirt_4.7.0.crcbundle", name)
+
+	_, err = bundleImageName(&oci.Manifest{Layers: []oci.Descriptor{{Annotations: titled("crc.qcow2")}}})
+	assert.EqualError(t, err, "the manifest has no org.opencontainers.image.title annotation with the name of the bundle")
+
+	_, err = bundleImageName(&oci.Manifest{Annotations: titled("../crc_libvirt_4.7.0.crcbundle")})
+	assert.EqualError(t, err, "'../crc_libvirt_4.7.0.crcbundle' is not a valid bundle name")
+}
+
+func TestIsFileName(t *testing.T) {
+	assert.True(t, isFileName("crc.qcow2"))
+	for _, name := range []string{"", ".", "..", "../crc.qcow2", "dir/crc.qcow2", `dir\crc.qcow2`, "/crc.qcow2"} {
+		assert.False(t, isFileName(name), name)
+	}
+}
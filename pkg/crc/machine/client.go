@@ -2,12 +2,16 @@ package machine
 
 import (
 	"context"
+	"io"
+	"strings"
 	"time"
 
 	crcConfig "github.com/code-ready/crc/pkg/crc/config"
-	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/crc/machine/profile"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/network/forward"
 	"github.com/kofalt/go-memoize"
 )
 
@@ -16,14 +20,37 @@ type Client interface {
 	GetConsoleURL() (*types.ConsoleResult, error)
 	ConnectionDetails() (*types.ConnectionDetails, error)
 
-	Delete() error
+	Delete(deleteConfig types.DeleteConfig) (*types.DeleteResult, error)
 	Exists() (bool, error)
-	PowerOff() error
+	PowerOff(powerOffConfig types.PowerOffConfig) (*types.StopResult, error)
 	Start(ctx context.Context, startConfig types.StartConfig) (*types.StartResult, error)
 	Status() (*types.ClusterStatusResult, error)
-	Stop() (state.State, error)
+	Stop() (*types.StopResult, error)
+	Hibernate() error
+	Pause(pauseConfig types.PauseConfig) error
+	Resume(resumeConfig types.ResumeConfig) error
 	IsRunning() (bool, error)
 	GenerateBundle(forceStop bool) error
+	CompactDisk(forceStop bool) (*types.CompactDiskResult, error)
+	ListImages() (*types.ImagesResult, error)
+	NetworkSelfTest() (*types.NetworkSelfTestResult, error)
+	Reconcile() error
+	ScheduledSnapshot() error
+	Snapshot(snapshotName string) error
+	Revert(snapshotName string) error
+	UpdateProxy() error
+	ShiftClock(offset time.Duration) error
+	Exec(execConfig types.ExecConfig) (*types.ExecResult, error)
+	SSH(sshConfig types.SSHConfig) error
+	TailUnitLogs(ctx context.Context, logsConfig types.UnitLogsConfig, writer io.Writer) error
+	Protect() (string, error)
+	Unprotect(token string) error
+	ConfigChanged(key string, oldValue interface{}) (*types.ConfigChangeResult, error)
+	SetConfig(vmConfig types.VMConfig) (*types.SetConfigResult, error)
+	History() ([]types.HistoryEntry, error)
+	AddPortForward(portForward forward.Forward) error
+	RemovePortForward(local string) error
+	ListPortForwards() ([]forward.Forward, error)
 }
 
 type client struct {
@@ -32,21 +59,31 @@ type client struct {
 	config crcConfig.Storage
 
 	diskDetails *memoize.Memoizer
+	certsExpiry *memoize.Memoizer
+	guestUsage  *memoize.Memoizer
+	snapshots   *memoize.Memoizer
 }
 
 func NewClient(name string, debug bool, config crcConfig.Storage) Client {
-	return &client{
+	return newHistoryClient(newNotifierClient(&client{
 		name:        name,
 		debug:       debug,
 		config:      config,
 		diskDetails: memoize.NewMemoizer(time.Minute, 5*time.Minute),
-	}
+		certsExpiry: memoize.NewMemoizer(time.Hour, 5*time.Minute),
+		guestUsage:  memoize.NewMemoizer(10*time.Second, time.Minute),
+		snapshots:   memoize.NewMemoizer(time.Minute, 5*time.Minute),
+	}, name, config), name, config)
 }
 
 func (client *client) GetName() string {
 	return client.name
 }
 
+func (client *client) profile() profile.Profile {
+	return profile.Profile{Name: client.name}
+}
+
 func (client *client) useVSock() bool {
 	return client.networkMode() == network.UserNetworkingMode
 }
@@ -55,6 +92,88 @@ func (client *client) networkMode() network.Mode {
 	return crcConfig.GetNetworkMode(client.config)
 }
 
+func (client *client) networkMTU() int {
+	return client.config.Get(crcConfig.NetworkMTU).AsInt()
+}
+
 func (client *client) monitoringEnabled() bool {
 	return client.config.Get(crcConfig.EnableClusterMonitoring).AsBool()
 }
+
+func (client *client) readinessOperators() []string {
+	var operators []string
+	for _, operator := range strings.Split(client.config.Get(crcConfig.ReadinessOperators).AsString(), ",") {
+		if operator = strings.TrimSpace(operator); operator != "" {
+			operators = append(operators, operator)
+		}
+	}
+	return operators
+}
+
+func (client *client) execPolicy() execPolicy {
+	return execPolicy{
+		allowed:    client.commandList(crcConfig.ExecAllowedCommands),
+		privileged: client.commandList(crcConfig.ExecPrivilegedCommands),
+	}
+}
+
+func (client *client) commandList(key string) []string {
+	var commands []string
+	for _, command := range strings.Split(client.config.Get(key).AsString(), ",") {
+		if command = strings.TrimSpace(command); command != "" {
+			commands = append(commands, command)
+		}
+	}
+	return commands
+}
+
+func (client *client) bundleCleanupPolicy() bundle.CleanupPolicy {
+	return bundle.ParseCleanupPolicy(client.config.Get(crcConfig.BundleCleanupPolicy).AsString())
+}
+
+func (client *client) bundleURLs() ([]string, error) {
+	return crcConfig.ParseBundleURLs(client.config.Get(crcConfig.BundleURL).AsString())
+}
+
+func (client *client) bundleImage() string {
+	return client.config.Get(crcConfig.BundleImage).AsString()
+}
+
+func (client *client) bundleSHA256() string {
+	return client.config.Get(crcConfig.BundleSHA256).AsString()
+}
+
+func (client *client) bundleSignatureCheck() bundle.SignatureCheck {
+	return bundle.SignatureCheck{
+		PublicKeyPath: client.config.Get(crcConfig.BundleSigningKey).AsString(),
+		Skip:          client.config.Get(crcConfig.SkipBundleSignature).AsBool(),
+	}
+}
+
+func (client *client) dnsForwardZones() ([]network.ForwardZone, error) {
+	return network.ParseForwardZones(client.config.Get(crcConfig.DNSForwardZones).AsString())
+}
+
+func (client *client) dnsUpstreamServers() ([]network.UpstreamServer, error) {
+	return network.ParseUpstreamServers(client.config.Get(crcConfig.DNSUpstreamServers).AsString())
+}
+
+func (client *client) imageMirrors() ([]bundle.ImageContentSource, error) {
+	return crcConfig.GetImageMirrors(client.config)
+}
+
+func (client *client) proxyAuth() network.ProxyAuth {
+	return crcConfig.GetProxyAuth(client.config)
+}
+
+func (client *client) hostDNS() bool {
+	return crcConfig.GetDNSService(client.config) == crcConfig.HostDNSService
+}
+
+func (client *client) dnsCheckSeverity(key string) crcConfig.CheckSeverity {
+	return crcConfig.GetDNSCheckSeverity(client.config, key)
+}
+
+func (client *client) logForwarding() crcConfig.LogForwardingTarget {
+	return crcConfig.GetLogForwarding(client.config)
+}
@@ -0,0 +1,72 @@
+package machine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/store"
+	"github.com/pkg/errors"
+)
+
+// ShiftClock moves the clock of the running VM offset away from the one of the
+// host and stops its network time synchronization, so that the expiry and the
+// renewal of the cluster certificates can be tested without waiting for them.
+// The offset is kept across restarts, an offset of 0 reverts to the host clock.
+// The certificates renewed while the clock is shifted are not valid before
+// the shifted time, the clock cannot be moved back before it.
+func (client *client) ShiftClock(offset time.Duration) error {
+	running, err := client.IsRunning()
+	if err != nil {
+		return err
+	}
+	if !running {
+		if offset != 0 {
+			return errors.New("machine is not running, its clock can only be reverted")
+		}
+		// the next start keeps the clock of the host and checks the
+		// certificates are already valid
+		return store.ForInstance(client.name).SetClockOffset(0)
+	}
+	_, sshRunner, err := loadVM(client)
+	if err != nil {
+		return err
+	}
+	defer sshRunner.Close()
+
+	if offset < client.clockOffset() {
+		if err := cluster.CheckCertsNotBefore(sshRunner, time.Now().Add(offset)); err != nil {
+			return errors.Wrap(err, "The certificates were renewed while the clock of the VM was shifted, keep a larger offset or delete the instance")
+		}
+	}
+
+	ntp := "off"
+	if offset == 0 {
+		ntp = "on"
+	}
+	if _, stderr, err := sshRunner.RunPrivileged("Setting network time synchronization", "timedatectl", "set-ntp", ntp); err != nil {
+		return fmt.Errorf("Failed to turn %s network time synchronization %v: %s", ntp, err, stderr)
+	}
+	now := time.Now().Add(offset)
+	logging.Infof("Setting the clock of the VM to %s", now.Format(time.RFC1123))
+	if _, stderr, err := sshRunner.RunPrivileged("Setting clock", "date", "-u", "-s", fmt.Sprintf("@%d", now.Unix())); err != nil {
+		return fmt.Errorf("Failed to set the VM clock %v: %s", err, stderr)
+	}
+	return store.ForInstance(client.name).SetClockOffset(offset)
+}
+
+// clockOffset returns the offset set by ShiftClock, 0 when the VM uses the
+// clock of the host
+func (client *client) clockOffset() time.Duration {
+	offset, err := store.ForInstance(client.name).ClockOffset()
+	if err != nil {
+		logging.Debugf("Cannot read the clock offset: %v", err)
+	}
+	return offset
+}
+
+// now returns the time in the VM, the certificates expiry is compared with it
+func (client *client) now() time.Time {
+	return time.Now().Add(client.clockOffset())
+}
@@ -0,0 +1,170 @@
+package machine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/config"
+	"github.com/code-ready/crc/pkg/crc/machine/profile"
+	"github.com/code-ready/crc/pkg/libmachine"
+	crcos "github.com/code-ready/crc/pkg/os"
+	libmachinestate "github.com/code-ready/machine/libmachine/state"
+	"github.com/pkg/errors"
+)
+
+// files of the instance directory which are copied to the clones, the
+// machine configuration and the instance state are created for each clone.
+// The cloned disk only authorizes the SSH keys of the source and uses its
+// kubeadmin password, which is copied with copyKubeAdminPassword.
+// The kubeconfig and the CA of the profile are not copied: the first start of
+// the clone generates its own admin client certificate, and the certificates
+// of its domains signed by its own CA.
+var clonedInstanceFiles = []string{"id_ecdsa", "id_ecdsa.pub", "id_rsa"}
+
+// Clone creates the instance newName from the disk of the stopped instance
+// source, so that a cluster prepared once can be stamped out in minutes. The
+// disk image shares its blocks with the source one when the filesystem
+// supports it. The clone gets its own VM in the hypervisor, its own state,
+// and its own certificates, kubeconfig contexts and cluster ID when it is
+// started. Nothing of the clone is left behind when it fails.
+func Clone(cfg crcConfig.Storage, source, newName string) (err error) {
+	if err := profile.ValidateName(newName); err != nil {
+		return err
+	}
+	libMachineAPIClient, cleanup := createLibMachineClient()
+	defer cleanup()
+
+	exists, err := libMachineAPIClient.Exists(newName)
+	if err != nil {
+		return errors.Wrapf(err, "Cannot determine if %s exists", newName)
+	}
+	if exists {
+		return fmt.Errorf("Instance %s already exists", newName)
+	}
+	host, err := libMachineAPIClient.Load(source)
+	if err != nil {
+		return errors.Wrap(err, "Cannot load machine")
+	}
+	vmState, err := host.Driver.GetState()
+	if err != nil {
+		return errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != libmachinestate.Stopped {
+		return fmt.Errorf("Instance %s must be stopped to be cloned", source)
+	}
+	if encrypted, err := diskEncrypted(host); err != nil {
+		return err
+	} else if encrypted {
+		return fmt.Errorf("The disk of %s is encrypted, it cannot be cloned", source)
+	}
+	driver, err := loadDriverConfig(host)
+	if err != nil {
+		return errors.Wrap(err, "Cannot load driver config")
+	}
+	crcBundleMetadata, err := getBundleMetadataFromDriver(host.Driver)
+	if err != nil {
+		return errors.Wrap(err, "Error loading bundle metadata")
+	}
+
+	sourceDir := profile.Profile{Name: source}.MachineDir()
+	cloneDir := profile.Profile{Name: newName}.MachineDir()
+	if err := os.MkdirAll(cloneDir, 0700); err != nil {
+		return err
+	}
+	cloneClient := &client{name: newName, config: cfg}
+	cloneCreated := false
+	defer func() {
+		if err == nil {
+			return
+		}
+		if cloneCreated {
+			if err := libMachineAPIClient.Remove(newName); err != nil {
+				logging.Debugf("Cannot remove the VM of %s: %v", newName, err)
+			}
+			cloneClient.releaseInstanceNetwork()
+		}
+		if err := kubeAdminPasswordStore(profile.Profile{Name: newName}).Delete(); err != nil {
+			logging.Debugf("Cannot remove the kubeadmin password of %s: %v", newName, err)
+		}
+		if err := os.RemoveAll(cloneDir); err != nil {
+			logging.Debugf("Cannot remove %s: %v", cloneDir, err)
+		}
+	}()
+
+	sourceDisk := driver.ResolveStorePath(fmt.Sprintf("%s.%s", driver.MachineName, driver.ImageFormat))
+	sourceClient := &client{name: source, config: cfg}
+	diskIOTuning := crcConfig.GetDiskIOTuning(cfg)
+	machineConfig := config.MachineConfig{
+		Name:            newName,
+		BundleName:      crcBundleMetadata.GetBundleName(),
+		CPUs:            driver.CPU,
+		Memory:          driver.Memory,
+		DiskSize:        int(driver.DiskCapacity / 1024 / 1024 / 1024),
+		NetworkMode:     sourceClient.networkMode(),
+		ImageSourcePath: sourceDisk,
+		ImageFormat:     driver.ImageFormat,
+		SSHKeyPath:      crcBundleMetadata.GetSSHKeyPath(),
+		KernelCmdLine:   crcBundleMetadata.GetKernelCommandLine(),
+		Initramfs:       crcBundleMetadata.GetInitramfsPath(),
+		Kernel:          crcBundleMetadata.GetKernelPath(),
+		KubeConfig:      crcBundleMetadata.GetKubeConfigPath(),
+		DiskCacheMode:   string(diskIOTuning.CacheMode),
+		DiskIOMode:      string(diskIOTuning.IOMode),
+	}
+	logging.Infof("Creating instance %s...", newName)
+	cloneCreated = true
+	if _, err := createVM(libMachineAPIClient, machineConfig); err != nil {
+		return errors.Wrap(err, "Error creating machine")
+	}
+	// the drivers copy the image without sharing its blocks, the copy is
+	// replaced by a clone of the source disk
+	logging.Infof("Cloning the disk of %s...", source)
+	if err := cloneDisk(libMachineAPIClient, newName, sourceDisk); err != nil {
+		return err
+	}
+	if err := cloneClient.configureInstanceNetwork(); err != nil {
+		return errors.Wrap(err, "Error configuring the network of the machine")
+	}
+	if err := copyInstanceFiles(sourceDir, cloneDir); err != nil {
+		return err
+	}
+	if err := copyKubeAdminPassword(kubeAdminPasswordStore(profile.Profile{Name: source}), kubeAdminPasswordStore(profile.Profile{Name: newName})); err != nil {
+		return err
+	}
+	if err := libMachineAPIClient.SetExists(newName); err != nil {
+		return fmt.Errorf("Failed to record VM existence: %s", err)
+	}
+	return nil
+}
+
+func cloneDisk(api libmachine.API, name, sourceDisk string) error {
+	host, err := api.Load(name)
+	if err != nil {
+		return errors.Wrap(err, "Cannot load machine")
+	}
+	driver, err := loadDriverConfig(host)
+	if err != nil {
+		return errors.Wrap(err, "Cannot load driver config")
+	}
+	disk := driver.ResolveStorePath(fmt.Sprintf("%s.%s", driver.MachineName, driver.ImageFormat))
+	if err := os.Remove(disk); err != nil {
+		return err
+	}
+	return crcos.CloneFile(sourceDisk, disk)
+}
+
+func copyInstanceFiles(sourceDir, cloneDir string) error {
+	for _, name := range clonedInstanceFiles {
+		source := filepath.Join(sourceDir, name)
+		if !crcos.FileExists(source) {
+			continue
+		}
+		if err := crcos.CopyFileContents(source, filepath.Join(cloneDir, name), 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
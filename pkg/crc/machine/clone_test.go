@@ -0,0 +1,46 @@
+package machine
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/code-ready/crc/pkg/crc/credentials/keyring"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCopyInstanceFiles(t *testing.T) {
+	sourceDir := t.TempDir()
+	cloneDir := t.TempDir()
+	require.NoError(t, ioutil.WriteFile(filepath.Join(sourceDir, "id_ecdsa"), []byte("key"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(sourceDir, "kubeconfig"), []byte("kubeconfig"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(sourceDir, "ca.crt"), []byte("ca"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(sourceDir, "crc-state.json"), []byte("{}"), 0600))
+
+	require.NoError(t, copyInstanceFiles(sourceDir, cloneDir))
+	content, err := ioutil.ReadFile(filepath.Join(cloneDir, "id_ecdsa"))
+	require.NoError(t, err)
+	assert.Equal(t, "key", string(content))
+	// the clone generates its own certificates
+	assert.NoFileExists(t, filepath.Join(cloneDir, "kubeconfig"))
+	assert.NoFileExists(t, filepath.Join(cloneDir, "ca.crt"))
+	assert.NoFileExists(t, filepath.Join(cloneDir, "crc-state.json"))
+}
+
+func TestCopyKubeAdminPassword(t *testing.T) {
+	keyring.MockInit()
+	source := cluster.KubeAdminPasswordStore{KeyringKey: "kubeadmin-password-crc", File: filepath.Join(t.TempDir(), "kubeadmin-password")}
+	clone := cluster.KubeAdminPasswordStore{KeyringKey: "kubeadmin-password-clone", File: filepath.Join(t.TempDir(), "kubeadmin-password")}
+
+	require.NoError(t, copyKubeAdminPassword(source, clone))
+	_, err := clone.Get()
+	assert.Error(t, err)
+
+	require.NoError(t, source.Set("a2b4c-6d8e1-f3g5h-7i9jk"))
+	require.NoError(t, copyKubeAdminPassword(source, clone))
+	password, err := clone.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "a2b4c-6d8e1-f3g5h-7i9jk", password)
+}
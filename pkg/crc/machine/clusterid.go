@@ -0,0 +1,53 @@
+package machine
+
+import (
+	"context"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/store"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/pborman/uuid"
+	"github.com/pkg/errors"
+)
+
+// ensureClusterID sets the identity the cluster reports to Insights and
+// Telemetry according to the cluster-identity setting, and records its ID for
+// status
+func (client *client) ensureClusterID(ctx context.Context, ocConfig oc.Config, pullSecret cluster.PullSecretLoader) error {
+	policy := crcConfig.GetClusterIDPolicy(client.config)
+	wantedClusterID, err := wantedClusterID(policy, client.name)
+	if err != nil {
+		return err
+	}
+	clusterID, err := cluster.EnsureClusterID(ctx, ocConfig, wantedClusterID)
+	if err != nil {
+		return err
+	}
+	if err := cluster.EnsureRemoteHealthReporting(ocConfig, pullSecret, policy != crcConfig.AnonymousClusterID); err != nil {
+		return err
+	}
+	if policy == crcConfig.PreserveClusterID {
+		if err := store.Global().SetPreservedClusterID(client.name, clusterID); err != nil {
+			return errors.Wrap(err, "Cannot record the cluster ID")
+		}
+	}
+	if err := store.ForInstance(client.name).SetClusterID(clusterID); err != nil {
+		logging.Debugf("Cannot record the cluster ID: %v", err)
+	}
+	return nil
+}
+
+// wantedClusterID returns the ID the cluster must have, or an empty string when
+// the current one is kept
+func wantedClusterID(policy crcConfig.ClusterIDPolicy, name string) (string, error) {
+	switch policy {
+	case crcConfig.PreserveClusterID:
+		return store.Global().PreservedClusterID(name)
+	case crcConfig.AnonymousClusterID:
+		return uuid.New(), nil
+	default:
+		return "", nil
+	}
+}
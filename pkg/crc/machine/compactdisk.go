@@ -0,0 +1,88 @@
+package machine
+
+import (
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	crcos "github.com/code-ready/crc/pkg/os"
+	libmachinestate "github.com/code-ready/machine/libmachine/state"
+	"github.com/pkg/errors"
+)
+
+// CompactDisk gives back to the host the space of the VM disk image which is
+// not used by the guest anymore. The unused blocks are first trimmed inside
+// the VM, which is then stopped so that the image can be compacted. A
+// running VM is only stopped when forceStop is true.
+func (client *client) CompactDisk(forceStop bool) (*types.CompactDiskResult, error) {
+	libMachineAPIClient, cleanup := createLibMachineClient()
+	defer cleanup()
+	host, err := libMachineAPIClient.Load(client.name)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot load machine")
+	}
+	driver, err := loadDriverConfig(host)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot load driver config")
+	}
+	diskImage := driver.ResolveStorePath(fmt.Sprintf("%s.%s", driver.MachineName, driver.ImageFormat))
+
+	result := &types.CompactDiskResult{}
+	if result.SizeBefore, err = crcos.AllocatedFileSize(diskImage); err != nil {
+		return nil, errors.Wrap(err, "Cannot get disk image size")
+	}
+
+	vmState, err := host.Driver.GetState()
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState == libmachinestate.Running {
+		if !forceStop {
+			return nil, errors.New("The instance must be stopped to compact its disk")
+		}
+		if err := client.trimDisk(); err != nil {
+			return nil, err
+		}
+		if _, err := client.Stop(); err != nil {
+			return nil, err
+		}
+		result.Stopped = true
+	} else {
+		logging.Warn("The instance is not running, only the blocks trimmed during its last run are reclaimed")
+	}
+
+	if encrypted, err := diskEncrypted(host); err != nil {
+		return nil, err
+	} else if encrypted {
+		return nil, errors.New("The disk image is encrypted, it cannot be compacted")
+	}
+	if snapshots, err := snapshotCount(client.name); err == nil && snapshots > 0 {
+		return nil, fmt.Errorf("The disk image cannot be compacted while the VM has %d snapshots", snapshots)
+	}
+	logging.Infof("Compacting %s...", diskImage)
+	if err := compactDiskImage(diskImage); err != nil {
+		return nil, errors.Wrap(err, "Cannot compact disk image")
+	}
+
+	if result.SizeAfter, err = crcos.AllocatedFileSize(diskImage); err != nil {
+		return nil, errors.Wrap(err, "Cannot get disk image size")
+	}
+	return result, nil
+}
+
+// trimDisk discards the unused blocks of the filesystems of the running VM
+func (client *client) trimDisk() error {
+	_, sshRunner, err := loadVM(client)
+	if err != nil {
+		return err
+	}
+	defer sshRunner.Close()
+
+	logging.Info("Trimming the filesystems of the VM...")
+	stdout, stderr, err := sshRunner.RunPrivileged("Trimming the filesystems", "fstrim", "--all", "--verbose")
+	if err != nil {
+		return fmt.Errorf("Failed to trim the filesystems %v: %s", err, stderr)
+	}
+	logging.Debug(stdout)
+	return nil
+}
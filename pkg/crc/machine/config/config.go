@@ -16,11 +16,30 @@ type MachineConfig struct {
 	SSHKeyPath      string
 	KubeConfig      string
 
+	// libvirt specific configuration
+	DiskCacheMode  string
+	DiskIOMode     string
+	DiskEncryption bool
+
 	// HyperKit specific configuration
 	KernelCmdLine string
 	Initramfs     string
 	Kernel        string
 
+	// Host directories shared into the VM
+	SharedDirs []SharedDir
+
 	// Experimental features
 	NetworkMode network.Mode
 }
+
+// SharedDirTagPrefix prefixes the tags of the shared directories
+const SharedDirTagPrefix = "crc-dir"
+
+// SharedDir is a host directory exported to the VM with the tag Tag, it is
+// mounted at Target once the VM is running
+type SharedDir struct {
+	Source string
+	Target string
+	Tag    string
+}
@@ -0,0 +1,134 @@
+package machine
+
+import (
+	"context"
+	"path/filepath"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/store"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/network"
+	libmachinestate "github.com/code-ready/machine/libmachine/state"
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+)
+
+// ConfigChanged tells when the change of the setting key from oldValue to its
+// current value takes effect on the existing instance, and records it as
+// pending until then
+func (client *client) ConfigChanged(key string, oldValue interface{}) (*types.ConfigChangeResult, error) {
+	exists, err := client.Exists()
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		// the instance is created with the new configuration
+		return &types.ConfigChangeResult{
+			Impact: types.ConfigAppliedLive,
+		}, nil
+	}
+
+	libMachineAPIClient, cleanup := createLibMachineClient()
+	defer cleanup()
+	host, err := libMachineAPIClient.Load(client.name)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot load machine")
+	}
+	vmState, err := host.Driver.GetState()
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot get machine state")
+	}
+	crcBundleMetadata, err := getBundleMetadataFromDriver(host.Driver)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error loading bundle metadata")
+	}
+
+	newValue := client.config.Get(key).Value
+	impact := configChangeImpact(key, oldValue, newValue, crcBundleMetadata.GetBundleName())
+	if key == crcConfig.DNSForwardZones && vmState == libmachinestate.Running && client.networkMode() == network.SystemNetworkingMode {
+		// the DNS server of the VM picks the forward zones without a restart
+		if err := client.applyDNSForwardZones(); err != nil {
+			logging.Warnf("Failed to apply the DNS forward zones to the running VM: %v", err)
+		} else {
+			impact = types.ConfigAppliedLive
+		}
+	}
+
+	if err := store.ForInstance(client.name).UpdatePendingConfigChanges(func(pending map[string]store.PendingConfigChange) {
+		if impact == types.ConfigAppliedLive {
+			delete(pending, key)
+		} else {
+			pending[key] = store.PendingConfigChange{
+				Value:  newValue,
+				Impact: string(impact),
+			}
+		}
+	}); err != nil {
+		return nil, err
+	}
+
+	return &types.ConfigChangeResult{
+		Impact:          impact,
+		InstanceRunning: vmState == libmachinestate.Running,
+	}, nil
+}
+
+func (client *client) applyDNSForwardZones() error {
+	crcBundleMetadata, sshRunner, err := loadVM(client)
+	if err != nil {
+		return err
+	}
+	defer sshRunner.Close()
+	return client.refreshDNS(context.Background(), sshRunner, crcBundleMetadata)
+}
+
+func configChangeImpact(key string, oldValue, newValue interface{}, currentBundle string) types.ConfigChangeImpact {
+	switch key {
+	case crcConfig.Bundle:
+		if filepath.Base(cast.ToString(newValue)) == currentBundle {
+			return types.ConfigAppliedLive
+		}
+		return types.ConfigRequiresDelete
+	case crcConfig.NetworkMode, crcConfig.DiskCacheMode, crcConfig.DiskIOMode, crcConfig.DiskFastUnsafe, crcConfig.DiskEncryption:
+		return types.ConfigRequiresDelete
+	case crcConfig.DiskSize:
+		// disks can grow but not shrink
+		if cast.ToInt(newValue) < cast.ToInt(oldValue) {
+			return types.ConfigRequiresDelete
+		}
+		return types.ConfigAppliedAtStart
+	case crcConfig.Memory, crcConfig.CPUs, crcConfig.NameServer, crcConfig.DNSForwardZones,
+		crcConfig.PullSecretFile, crcConfig.KubeAdminPassword, crcConfig.EnableClusterMonitoring,
+		crcConfig.HTTPProxy, crcConfig.HTTPSProxy, crcConfig.NoProxy, crcConfig.ProxyCAFile,
+		crcConfig.ProxyUser, crcConfig.ProxyPassword, crcConfig.ProxyPACFile,
+		crcConfig.PrePullImages, crcConfig.ReadinessOperators, crcConfig.ImageMirrors,
+		crcConfig.ClusterIdentity, crcConfig.LogForwarding, crcConfig.DNSUpstreamServers,
+		crcConfig.KubeletConfigOverlay, crcConfig.KubeletLogLevel, crcConfig.CrioConfigOverlay,
+		crcConfig.NetworkMTU:
+		return types.ConfigAppliedAtStart
+	default:
+		return types.ConfigAppliedLive
+	}
+}
+
+// warnPendingConfigChanges logs the configuration changes which are still not
+// applied to the instance, and forgets the ones which are applied by this start
+func (client *client) warnPendingConfigChanges(alreadyRunning bool) {
+	if err := store.ForInstance(client.name).UpdatePendingConfigChanges(func(pending map[string]store.PendingConfigChange) {
+		for key, change := range pending {
+			switch types.ConfigChangeImpact(change.Impact) {
+			case types.ConfigRequiresDelete:
+				logging.Warnf("The change of '%s' is only applied after the instance is deleted with 'crc delete'", key)
+			case types.ConfigAppliedAtStart:
+				if alreadyRunning {
+					logging.Warnf("The change of '%s' is only applied after the instance is stopped with 'crc stop' and started again", key)
+				} else {
+					delete(pending, key)
+				}
+			}
+		}
+	}); err != nil {
+		logging.Debugf("Cannot update pending configuration changes: %v", err)
+	}
+}
@@ -0,0 +1,21 @@
+package machine
+
+import (
+	"testing"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigChangeImpact(t *testing.T) {
+	currentBundle := "crc_libvirt_4.8.2.crcbundle"
+
+	assert.Equal(t, types.ConfigAppliedLive, configChangeImpact(crcConfig.Bundle, "", "/home/user/Downloads/crc_libvirt_4.8.2.crcbundle", currentBundle))
+	assert.Equal(t, types.ConfigRequiresDelete, configChangeImpact(crcConfig.Bundle, "", "/home/user/Downloads/crc_libvirt_4.9.0.crcbundle", currentBundle))
+	assert.Equal(t, types.ConfigAppliedAtStart, configChangeImpact(crcConfig.DiskSize, 31, 40, currentBundle))
+	assert.Equal(t, types.ConfigRequiresDelete, configChangeImpact(crcConfig.DiskSize, 40, 31, currentBundle))
+	assert.Equal(t, types.ConfigAppliedAtStart, configChangeImpact(crcConfig.Memory, 9216, 12288, currentBundle))
+	assert.Equal(t, types.ConfigRequiresDelete, configChangeImpact(crcConfig.DiskCacheMode, "default", "unsafe", currentBundle))
+	assert.Equal(t, types.ConfigAppliedLive, configChangeImpact(crcConfig.ConsentTelemetry, "", "yes", currentBundle))
+}
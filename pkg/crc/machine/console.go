@@ -3,6 +3,7 @@ package machine
 import (
 	"github.com/code-ready/crc/pkg/crc/machine/state"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
+	libmachinestate "github.com/code-ready/machine/libmachine/state"
 	"github.com/pkg/errors"
 )
 
@@ -28,10 +29,13 @@ func (client *client) GetConsoleURL() (*types.ConsoleResult, error) {
 		return nil, errors.Wrap(err, "Error loading bundle metadata")
 	}
 
-	clusterConfig, err := getClusterConfig(crcBundleMetadata)
+	clusterConfig, err := getClusterConfig(client.profile(), crcBundleMetadata)
 	if err != nil {
 		return nil, errors.Wrap(err, "Error loading cluster configuration")
 	}
+	if vmState == libmachinestate.Running {
+		client.updateClusterConfigFromRunningVM(host, crcBundleMetadata, clusterConfig)
+	}
 
 	return &types.ConsoleResult{
 		ClusterConfig: *clusterConfig,
@@ -1,13 +1,58 @@
 package machine
 
 import (
+	"context"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/code-ready/crc/pkg/crc/constants"
+	crcerrors "github.com/code-ready/crc/pkg/crc/errors"
 	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/services/dns"
+	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/code-ready/crc/pkg/libmachine/host"
+	libmachinestate "github.com/code-ready/machine/libmachine/state"
 	"github.com/pkg/errors"
 )
 
-func (client *client) Delete() error {
+// directory of the VM holding the hostPath persistent volumes of the cluster
+const persistentVolumesDir = "/mnt/pv-data"
+
+// Delete removes the VM and the state of the instance. When the instance does
+// not exist, it fails unless deleteConfig.IgnoreMissing is set.
+func (client *client) Delete(deleteConfig types.DeleteConfig) (*types.DeleteResult, error) {
+	exists, err := client.Exists()
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		if !deleteConfig.IgnoreMissing {
+			return nil, crcerrors.VMNotExist
+		}
+		if deleteConfig.ClearCache {
+			if err := ClearCache(constants.MachineCacheDir, deleteConfig.KeepBundle); err != nil {
+				return nil, errors.Wrap(err, "Cannot clear the cache")
+			}
+		}
+		return &types.DeleteResult{AlreadyDeleted: true}, nil
+	}
+	if err := client.delete(deleteConfig); err != nil {
+		return nil, err
+	}
+	return &types.DeleteResult{}, nil
+}
+
+func (client *client) delete(deleteConfig types.DeleteConfig) error {
+	if err := client.checkNotProtected(deleteConfig.ProtectionToken); err != nil {
+		return err
+	}
+
 	libMachineAPIClient, cleanup := createLibMachineClient()
 	defer cleanup()
 	host, err := libMachineAPIClient.Load(client.name)
@@ -16,6 +61,24 @@ func (client *client) Delete() error {
 		return errors.Wrap(err, "Cannot load machine")
 	}
 
+	if deleteConfig.KeepData {
+		backupDir, err := client.exportData(host)
+		if err != nil {
+			return errors.Wrap(err, "Cannot keep the data of the cluster, it was not deleted")
+		}
+		logging.Infof("The data of the cluster was copied to %s", backupDir)
+	}
+
+	client.stopHostServices()
+	if err := dns.RunPostDelete(client.name); err != nil {
+		logging.Warnf("Failed to remove the DNS configuration of the host: %v", err)
+	}
+	client.removeHostRouting(host)
+	if err := deleteSnapshots(client.name); err != nil {
+		logging.Warnf("Failed to delete the snapshots of the machine: %v", err)
+	}
+	client.forgetSnapshotCount()
+	removeDiskEncryption(host)
 	if err := host.Driver.Remove(); err != nil {
 		return errors.Wrap(err, "Driver cannot remove machine")
 	}
@@ -23,11 +86,129 @@ func (client *client) Delete() error {
 	if err := libMachineAPIClient.Remove(client.name); err != nil {
 		return errors.Wrap(err, "Cannot remove machine")
 	}
+	client.releaseInstanceNetwork()
+
+	deleteKubeAdminPasswords(client.name)
+	if err := os.Remove(client.profile().PortForwardsPath()); err != nil && !os.IsNotExist(err) {
+		logging.Warnf("Failed to remove the port forwards: %v", err)
+	}
 
-	if err := cleanKubeconfig(getGlobalKubeConfigPath(), getGlobalKubeConfigPath()); err != nil {
+	if err := cleanKubeconfig(client.name, getGlobalKubeConfigPath(), getGlobalKubeConfigPath()); err != nil {
 		if !errors.Is(err, os.ErrNotExist) {
 			logging.Warnf("Failed to remove crc contexts from kubeconfig: %v", err)
 		}
 	}
+
+	if deleteConfig.ClearCache {
+		if err := ClearCache(constants.MachineCacheDir, deleteConfig.KeepBundle); err != nil {
+			return errors.Wrap(err, "Cannot clear the cache")
+		}
+	}
 	return nil
 }
+
+// exportData copies an etcd backup and the persistent volumes of the running
+// cluster to a new directory of the host, and returns its path
+func (client *client) exportData(host *host.Host) (string, error) {
+	vmState, err := host.Driver.GetState()
+	if err != nil {
+		return "", errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != libmachinestate.Running {
+		return "", errors.New("the cluster must be running to copy its data")
+	}
+	crcBundleMetadata, err := getBundleMetadataFromDriver(host.Driver)
+	if err != nil {
+		return "", errors.Wrap(err, "Error loading bundle metadata")
+	}
+	ip, err := getIP(host, client.useVSock())
+	if err != nil {
+		return "", errors.Wrap(err, "Error getting the IP")
+	}
+	sshRunner, err := client.createSSHRunner(ip, getSSHPort(client.useVSock()), crcBundleMetadata, client.profile().PrivateKeyPath(), client.profile().RsaPrivateKeyPath(), crcBundleMetadata.GetSSHKeyPath())
+	if err != nil {
+		return "", errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+
+	backupDir := filepath.Join(constants.MachineBaseDir, "backups", fmt.Sprintf("%s-%s", client.name, time.Now().Format("20060102-150405")))
+	if err := os.MkdirAll(backupDir, 0700); err != nil {
+		return "", err
+	}
+
+	etcdBackupDir := filepath.Join(crcBundleMetadata.GetSSHUserHomeDir(), "crc-etcd-backup")
+	logging.Infof("Creating an etcd backup of the cluster...")
+	if _, stderr, err := sshRunner.RunPrivileged("Creating an etcd backup", "/usr/local/bin/cluster-backup.sh", etcdBackupDir); err != nil {
+		return "", fmt.Errorf("Failed to create the etcd backup: %v: %s", err, stderr)
+	}
+	defer func() {
+		_, _, _ = sshRunner.RunPrivileged("Removing the etcd backup", "rm", "-rf", etcdBackupDir)
+	}()
+	if err := copyDirectoryFromVM(sshRunner, etcdBackupDir, filepath.Join(backupDir, "etcd-backup.tar")); err != nil {
+		return "", err
+	}
+
+	logging.Infof("Copying the persistent volumes of the cluster...")
+	if err := copyDirectoryFromVM(sshRunner, persistentVolumesDir, filepath.Join(backupDir, "pv-data.tar")); err != nil {
+		return "", err
+	}
+	return backupDir, nil
+}
+
+// copyDirectoryFromVM writes the content of the VM directory dir as a tar
+// archive to the host file destination
+func copyDirectoryFromVM(sshRunner *crcssh.Runner, dir, destination string) error {
+	file, err := os.OpenFile(destination, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if stderr, err := sshRunner.StreamPrivileged(context.Background(), fmt.Sprintf("Copying %s", dir), file, "tar", "-C", dir, "-cf", "-", "."); err != nil {
+		return fmt.Errorf("Failed to copy %s from the VM: %v: %s", dir, err, stderr)
+	}
+	return file.Close()
+}
+
+// ClearCache removes the content of cacheDir, except the extracted bundles
+// when keepBundles is true. The bundles used by instances are always kept,
+// removing them would break the instances, the instances are in the machines
+// directory next to cacheDir.
+func ClearCache(cacheDir string, keepBundles bool) error {
+	entries, err := ioutil.ReadDir(cacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	repo := &bundle.Repository{
+		CacheDir:    cacheDir,
+		MachinesDir: filepath.Join(filepath.Dir(cacheDir), filepath.Base(constants.MachineInstanceDir)),
+	}
+	keptReferences := false
+	for _, entry := range entries {
+		path := filepath.Join(cacheDir, entry.Name())
+		if entry.IsDir() && bundle.IsExtracted(path) {
+			if keepBundles {
+				logging.Debugf("Keeping the extracted bundle %s", path)
+				continue
+			}
+			instances, err := repo.References(entry.Name())
+			if err != nil {
+				return err
+			}
+			if len(instances) != 0 {
+				logging.Warnf("Keeping bundle %s, it is used by the instances %s", entry.Name(), strings.Join(instances, ", "))
+				keptReferences = true
+				continue
+			}
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+	}
+	if keepBundles || keptReferences {
+		return nil
+	}
+	return os.RemoveAll(cacheDir)
+}
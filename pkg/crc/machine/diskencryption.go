@@ -0,0 +1,95 @@
+package machine
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/credentials/keyring"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/profile"
+	"github.com/code-ready/crc/pkg/libmachine/host"
+)
+
+// diskImagePath returns the path of the disk image of the VM of host
+func diskImagePath(host *host.Host) (string, error) {
+	driver, err := loadDriverConfig(host)
+	if err != nil {
+		return "", err
+	}
+	return driver.ResolveStorePath(fmt.Sprintf("%s.%s", driver.MachineName, driver.ImageFormat)), nil
+}
+
+// encryptDisk encrypts the disk image of the new VM of host with a random
+// passphrase. The passphrase is only kept in the keyring, a file next to the
+// disk image would defeat the encryption.
+func encryptDisk(host *host.Host) error {
+	diskImage, err := diskImagePath(host)
+	if err != nil {
+		return err
+	}
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return err
+	}
+	passphrase := base64.RawURLEncoding.EncodeToString(random)
+	if err := keyring.Set(profile.Profile{Name: host.Name}.DiskPassphraseKeyringKey(), passphrase); err != nil {
+		return fmt.Errorf("The disk of the VM cannot be encrypted without a keyring to keep its passphrase: %v", err)
+	}
+	logging.Info("Encrypting the disk of the VM...")
+	return encryptDiskImage(host.Name, diskImage, passphrase)
+}
+
+// loadDiskPassphrase gives the passphrase of the encrypted disk of the VM of
+// host to the hypervisor before the VM starts, it does nothing when the disk
+// is not encrypted
+func loadDiskPassphrase(host *host.Host) error {
+	diskImage, err := diskImagePath(host)
+	if err != nil {
+		return err
+	}
+	secretUUID, err := diskEncryptionSecret(host.Name, diskImage)
+	if err != nil || secretUUID == "" {
+		return err
+	}
+	passphrase, err := keyring.Get(profile.Profile{Name: host.Name}.DiskPassphraseKeyringKey())
+	if err != nil {
+		return fmt.Errorf("Cannot read the passphrase of the encrypted disk of the VM from the keyring: %v", err)
+	}
+	return loadDiskEncryptionSecret(secretUUID, diskImage, passphrase)
+}
+
+// diskEncrypted returns true when the disk image of the VM of host is
+// encrypted
+func diskEncrypted(host *host.Host) (bool, error) {
+	diskImage, err := diskImagePath(host)
+	if err != nil {
+		return false, err
+	}
+	secretUUID, err := diskEncryptionSecret(host.Name, diskImage)
+	return secretUUID != "", err
+}
+
+// removeDiskEncryption removes the passphrase of the encrypted disk of the VM
+// of host from the hypervisor and from the keyring, it must run before the VM
+// is removed
+func removeDiskEncryption(host *host.Host) {
+	diskImage, err := diskImagePath(host)
+	if err != nil {
+		logging.Debugf("Cannot find the disk image of %s: %v", host.Name, err)
+		return
+	}
+	secretUUID, err := diskEncryptionSecret(host.Name, diskImage)
+	if err != nil {
+		logging.Debugf("Cannot read the encryption of the disk of %s: %v", host.Name, err)
+	}
+	if secretUUID == "" {
+		return
+	}
+	if err := undefineDiskEncryptionSecret(secretUUID); err != nil {
+		logging.Debugf("Cannot remove the disk encryption secret of %s: %v", host.Name, err)
+	}
+	if err := keyring.Delete(profile.Profile{Name: host.Name}.DiskPassphraseKeyringKey()); err != nil {
+		logging.Warnf("Failed to remove the passphrase of the disk from the keyring: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package machine
+
+import (
+	"fmt"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/config"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/libmachine/host"
+	"github.com/code-ready/machine/libmachine/drivers"
+	libmachinestate "github.com/code-ready/machine/libmachine/state"
+	"github.com/pkg/errors"
+)
+
+// resizeDisk grows the disk of host to sizeGiB, so that an instance running
+// out of space does not have to be deleted. The disk of a running instance is
+// grown online when the hypervisor supports it, growRunningDisk then grows its
+// root filesystem. The root filesystem of a stopped instance is grown by its
+// next start. The change is nil when the disk already has this size, and the
+// host must be saved otherwise.
+func (client *client) resizeDisk(host *host.Host, vmState libmachinestate.State, sizeGiB int) (*types.ResourceChange, error) {
+	driver, err := loadDriverConfig(host)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot load driver config")
+	}
+	change, err := diskSizeChange(client.name, driver.DiskCapacity, sizeGiB)
+	if err != nil || change == nil {
+		return nil, err
+	}
+	if snapshots, err := snapshotCount(client.name); err == nil && snapshots > 0 {
+		return nil, fmt.Errorf("The disk of %s cannot be resized while it has %d snapshots", client.name, snapshots)
+	}
+
+	switch vmState {
+	case libmachinestate.Running:
+		diskImage := driver.ResolveStorePath(fmt.Sprintf("%s.%s", driver.MachineName, driver.ImageFormat))
+		if err := resizeDiskOnline(client.name, diskImage, config.ConvertGiBToBytes(sizeGiB)); err != nil {
+			return nil, err
+		}
+	case libmachinestate.Stopped:
+	default:
+		return nil, fmt.Errorf("The disk of %s cannot be resized while it is %s", client.name, vmState)
+	}
+
+	logging.Infof("Resizing the disk of %s to %d GiB...", client.name, sizeGiB)
+	if err := setDiskSize(host, sizeGiB); err != nil {
+		if err == drivers.ErrNotImplemented {
+			return nil, errors.New("The machine driver does not support resizing the disk")
+		}
+		return nil, errors.Wrap(err, "Cannot resize the disk")
+	}
+	if vmState != libmachinestate.Running {
+		logging.Infof("The root filesystem of %s is grown on its next start", client.name)
+	}
+	return change, nil
+}
+
+// diskSizeChange returns the change of a disk of capacity bytes to sizeGiB,
+// nil when it already has this size. Disks cannot shrink.
+func diskSizeChange(name string, capacity uint64, sizeGiB int) (*types.ResourceChange, error) {
+	currentGiB := int(capacity / 1024 / 1024 / 1024)
+	switch requested := config.ConvertGiBToBytes(sizeGiB); {
+	case requested == capacity:
+		logging.Infof("The disk of %s is already %d GiB", name, sizeGiB)
+		return nil, nil
+	case requested < capacity:
+		return nil, fmt.Errorf("The disk of %s is %d GiB, it cannot be reduced to %d GiB", name, currentGiB, sizeGiB)
+	}
+	return &types.ResourceChange{Resource: crcConfig.DiskSize, Previous: currentGiB, Current: sizeGiB}, nil
+}
+
+// growRunningDisk makes the root filesystem of the running instance use its
+// grown disk
+func (client *client) growRunningDisk() error {
+	_, sshRunner, err := loadVM(client)
+	if err != nil {
+		return err
+	}
+	defer sshRunner.Close()
+	// SCSI disks, used by Hyper-V, do not report their new size by themselves
+	if _, _, err := sshRunner.RunPrivileged("Rescanning the disks", `sh -c 'for rescan in /sys/class/block/sd?/device/rescan; do [ -e "$rescan" ] && echo 1 > "$rescan"; done; true'`); err != nil {
+		logging.Debugf("Cannot rescan the disks: %v", err)
+	}
+	if err := growRootFileSystem(sshRunner); err != nil {
+		return errors.Wrap(err, "Error updating filesystem size")
+	}
+	return nil
+}
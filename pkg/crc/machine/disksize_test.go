@@ -0,0 +1,24 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskSizeChange(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+
+	change, err := diskSizeChange("crc", 31*gib, 50)
+	require.NoError(t, err)
+	assert.Equal(t, &types.ResourceChange{Resource: "disk-size", Previous: 31, Current: 50}, change)
+
+	change, err = diskSizeChange("crc", 50*gib, 50)
+	assert.NoError(t, err)
+	assert.Nil(t, change)
+
+	_, err = diskSizeChange("crc", 50*gib, 40)
+	assert.EqualError(t, err, "The disk of crc is 50 GiB, it cannot be reduced to 40 GiB")
+}
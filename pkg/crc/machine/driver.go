@@ -1,6 +1,7 @@
 package machine
 
 import (
+	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/config"
 	"github.com/code-ready/crc/pkg/libmachine/host"
 	libmachine "github.com/code-ready/machine/libmachine/drivers"
@@ -51,6 +52,11 @@ func setDiskSize(host *host.Host, diskSizeGiB int) error {
 		if driver.DiskCapacity == capacity {
 			return false
 		}
+		// the disk may have been grown by resizeDisk, it cannot shrink
+		if capacity < driver.DiskCapacity {
+			logging.Warnf("The disk size of the VM is %d GiB, it cannot be reduced to %d GiB", driver.DiskCapacity/1024/1024/1024, diskSizeGiB)
+			return false
+		}
 		driver.DiskCapacity = capacity
 		return true
 	}
@@ -3,6 +3,10 @@ package machine
 import (
 	"encoding/json"
 	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/machine/config"
@@ -35,3 +39,122 @@ func updateDriverConfig(host *host.Host, driver *machineHyperkit.Driver) error {
 
 	return host.UpdateConfig(driverData)
 }
+
+// consoleLogPath returns the path of the file where the hypervisor logs the VM output
+func consoleLogPath(name string) string {
+	return filepath.Join(constants.MachineInstanceDir, name, "console-ring")
+}
+
+// snapshotCount returns the number of snapshots of the VM, they are clones of
+// the raw disk image made by createSnapshot
+func snapshotCount(name string) (int, error) {
+	snapshots, err := ioutil.ReadDir(snapshotsDir(name))
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+	return len(snapshots), nil
+}
+
+// createSnapshot clones the disk image to dir, hyperkit does not support
+// snapshots but APFS clones share the blocks of the image
+func createSnapshot(_, _, diskImage, dir string) error {
+	return cloneDiskImage(diskImage, filepath.Join(dir, filepath.Base(diskImage)))
+}
+
+// createLiveSnapshot clones the disk image of the running VM to dir
+func createLiveSnapshot(_, _, diskImage, dir string, quiesce func() (func() error, error)) error {
+	return cloneFrozenDiskImage(diskImage, dir, quiesce)
+}
+
+func revertSnapshot(_, _, diskImage, dir string) error {
+	return cloneDiskImage(filepath.Join(dir, filepath.Base(diskImage)), diskImage)
+}
+
+// deleteSnapshot does nothing, the clone of the disk image is in the
+// snapshot directory
+func deleteSnapshot(_, _, _ string) error {
+	return nil
+}
+
+// deleteSnapshots does nothing, the snapshots are removed with the instance
+// directory
+func deleteSnapshots(_ string) error {
+	return nil
+}
+
+// compactDiskImage does nothing, hyperkit already punches holes in the sparse
+// raw image when the guest trims blocks
+func compactDiskImage(_ string) error {
+	return nil
+}
+
+var errPauseNotSupported = errors.New("hyperkit does not support pausing the VM")
+
+// pauseVM fails, hyperkit cannot suspend the VM
+func pauseVM(_ string) error {
+	return errPauseNotSupported
+}
+
+// resumeVM fails, a hyperkit VM is never paused
+func resumeVM(_ string) error {
+	return errPauseNotSupported
+}
+
+// resizeDiskOnline fails, hyperkit cannot resize the disk of a running VM
+func resizeDiskOnline(_, _ string, _ uint64) error {
+	return errors.New("hyperkit cannot resize the disk of a running VM, stop it with 'crc stop' first")
+}
+
+// configureSharedDirs fails when directories are shared, hyperkit cannot
+// export them with virtiofs
+func configureSharedDirs(_ string, sharedDirs []config.SharedDir) error {
+	if len(sharedDirs) > 0 {
+		return errors.New("hyperkit does not support sharing directories with the VM")
+	}
+	return nil
+}
+
+// attachRegistryDisk fails when a disk is requested, the hyperkit driver
+// cannot attach extra disks
+func attachRegistryDisk(_, path string) error {
+	if path != "" {
+		return errors.New("hyperkit does not support attaching a registry disk to the VM")
+	}
+	return nil
+}
+
+// configureInstanceAddress does nothing, the DHCP server of vmnet gives each
+// VM its own address
+func configureInstanceAddress(_ string, _ int, _ *net.IPNet) error {
+	return nil
+}
+
+func removeInstanceAddress(_ int, _ *net.IPNet) error {
+	return nil
+}
+
+// diskEncryptionSupported fails, only the libvirt driver encrypts the disk
+// of the VM
+func diskEncryptionSupported() error {
+	return errors.New("hyperkit does not support encrypting the disk of the VM")
+}
+
+// encryptDiskImage fails, see diskEncryptionSupported
+func encryptDiskImage(_, _, _ string) error {
+	return diskEncryptionSupported()
+}
+
+// diskEncryptionSecret returns an empty string, the disk is never encrypted
+func diskEncryptionSecret(_, _ string) (string, error) {
+	return "", nil
+}
+
+// loadDiskEncryptionSecret does nothing, the disk is never encrypted
+func loadDiskEncryptionSecret(_, _, _ string) error {
+	return nil
+}
+
+// undefineDiskEncryptionSecret does nothing, the disk is never encrypted
+func undefineDiskEncryptionSecret(_ string) error {
+	return nil
+}
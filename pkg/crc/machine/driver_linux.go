@@ -1,15 +1,26 @@
 package machine
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/machine/config"
 	"github.com/code-ready/crc/pkg/crc/machine/libvirt"
+	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/libmachine"
 	"github.com/code-ready/crc/pkg/libmachine/host"
+	crcos "github.com/code-ready/crc/pkg/os"
 	machineLibvirt "github.com/code-ready/machine/drivers/libvirt"
+	libvirtxml "github.com/libvirt/libvirt-go-xml"
+	"github.com/pborman/uuid"
 )
 
 func newHost(api libmachine.API, machineConfig config.MachineConfig) (*host.Host, error) {
@@ -43,3 +54,335 @@ func (r *RPCServerDriver) SetConfigRaw(data []byte, _ *struct{}) error {
 	return json.Unmarshal(data, &r.ActualDriver)
 }
 */
+
+// consoleLogPath returns the path of the file where the hypervisor logs the VM output
+func consoleLogPath(name string) string {
+	return fmt.Sprintf("/var/log/libvirt/qemu/%s.log", name)
+}
+
+// snapshotCount returns the number of snapshots libvirt keeps for the VM and
+// of the clones of the disk image made by createLiveSnapshot
+func snapshotCount(name string) (int, error) {
+	stdout, stderr, err := crcos.RunWithDefaultLocale("virsh", "--readonly", "--connect", "qemu:///system", "snapshot-list", "--name", name)
+	if err != nil {
+		return 0, fmt.Errorf("Failed to list the VM snapshots %v: %s", err, stderr)
+	}
+	count := len(strings.Fields(stdout))
+	snapshots, err := ioutil.ReadDir(snapshotsDir(name))
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+	for _, snapshot := range snapshots {
+		if _, ok := liveSnapshotImage(filepath.Join(snapshotsDir(name), snapshot.Name())); ok {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// pauseVM suspends the VM in memory
+func pauseVM(name string) error {
+	return virsh("suspend", name)
+}
+
+// resumeVM resumes the VM suspended by pauseVM
+func resumeVM(name string) error {
+	return virsh("resume", name)
+}
+
+func virsh(command string, name string) error {
+	if _, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", command, name); err != nil {
+		return fmt.Errorf("Failed to %s the VM %v: %s", command, err, stderr)
+	}
+	return nil
+}
+
+// compactDiskImage rewrites the qcow2 image without the clusters the guest
+// trimmed, the VM must be stopped
+func compactDiskImage(path string) error {
+	compactPath := path + ".compact"
+	if _, stderr, err := crcos.RunWithDefaultLocale("qemu-img", "convert", "-f", "qcow2", "-O", "qcow2", path, compactPath); err != nil {
+		_ = os.Remove(compactPath)
+		return fmt.Errorf("Failed to compact the disk image %v: %s", err, stderr)
+	}
+	return os.Rename(compactPath, path)
+}
+
+// createSnapshot creates an internal snapshot of the qcow2 disk image of the
+// stopped VM, it only holds the disk
+func createSnapshot(name, snapshotName, _, _ string) error {
+	if _, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "snapshot-create-as", "--domain", name, "--name", snapshotName, "--atomic"); err != nil {
+		return fmt.Errorf("Failed to create the VM snapshot %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// createLiveSnapshot clones the disk image of the running VM to dir, a
+// libvirt snapshot of a running domain would save its memory and pause it
+func createLiveSnapshot(_, _, diskImage, dir string, quiesce func() (func() error, error)) error {
+	return cloneFrozenDiskImage(diskImage, dir, quiesce)
+}
+
+// liveSnapshotImage returns the disk image cloned by createLiveSnapshot to
+// dir, false for the libvirt snapshots
+func liveSnapshotImage(dir string) (string, bool) {
+	images, _ := filepath.Glob(filepath.Join(dir, "*.qcow2"))
+	if len(images) == 0 {
+		return "", false
+	}
+	return images[0], true
+}
+
+func revertSnapshot(name, snapshotName, diskImage, dir string) error {
+	if image, ok := liveSnapshotImage(dir); ok {
+		return cloneDiskImage(image, diskImage)
+	}
+	if _, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "snapshot-revert", "--domain", name, "--snapshotname", snapshotName); err != nil {
+		return fmt.Errorf("Failed to revert the VM to its snapshot %v: %s", err, stderr)
+	}
+	return nil
+}
+
+func deleteSnapshot(name, snapshotName, dir string) error {
+	// the clones are removed with the snapshot directory
+	if _, ok := liveSnapshotImage(dir); ok {
+		return nil
+	}
+	if _, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "snapshot-delete", "--domain", name, "--snapshotname", snapshotName); err != nil {
+		return fmt.Errorf("Failed to delete the VM snapshot %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// deleteSnapshots removes the snapshot metadata, libvirt refuses to undefine
+// a VM with snapshots. The snapshots themselves are in the disk image, which
+// is removed with the VM. It tries all the snapshots before failing.
+func deleteSnapshots(name string) error {
+	stdout, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "snapshot-list", "--name", name)
+	if err != nil {
+		return fmt.Errorf("Failed to list the VM snapshots %v: %s", err, stderr)
+	}
+	var failed []string
+	for _, snapshotName := range strings.Fields(stdout) {
+		if _, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "snapshot-delete", "--domain", name, "--snapshotname", snapshotName, "--metadata"); err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v: %s)", snapshotName, err, strings.TrimSpace(stderr)))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("Failed to delete the VM snapshots %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// resizeDiskOnline grows the disk image of the running VM to capacity bytes,
+// the guest is notified of the new size. libvirt only resizes the image
+// again when it is smaller than the requested capacity.
+func resizeDiskOnline(name, diskImage string, capacity uint64) error {
+	if _, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "blockresize", name, diskImage, "--size", fmt.Sprintf("%dB", capacity)); err != nil {
+		return fmt.Errorf("Failed to resize the disk of the running VM %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// configureSharedDirs exports the host directories of sharedDirs to the
+// stopped VM as virtiofs filesystems, libvirt starts a virtiofsd process for
+// each of them when the VM starts
+func configureSharedDirs(name string, sharedDirs []config.SharedDir) error {
+	return updateDomain(name, "shared directories", func(domain *libvirtxml.Domain) bool {
+		return libvirt.UpdateSharedDirs(domain, sharedDirs)
+	})
+}
+
+// attachRegistryDisk attaches the qcow2 image at path to the stopped VM, it
+// is created if needed. The disk is detached when path is empty, the image
+// is kept.
+func attachRegistryDisk(name, path string) error {
+	if path != "" && !crcos.FileExists(path) {
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			return err
+		}
+		if _, stderr, err := crcos.RunWithDefaultLocale("qemu-img", "create", "-f", "qcow2", path, fmt.Sprintf("%dG", registryDiskSizeGiB)); err != nil {
+			return fmt.Errorf("Failed to create the registry disk %v: %s", err, stderr)
+		}
+	}
+	return updateDomain(name, "registry disk", func(domain *libvirtxml.Domain) bool {
+		return libvirt.UpdateExtraDisk(domain, registryDiskSerial, path)
+	})
+}
+
+// updateDomain applies update to the definition of the stopped VM, it is
+// only redefined when update returns true
+func updateDomain(name, what string, update func(domain *libvirtxml.Domain) bool) error {
+	domain, err := readDomain(name)
+	if err != nil {
+		return err
+	}
+	if !update(domain) {
+		return nil
+	}
+	xml, err := domain.Marshal()
+	if err != nil {
+		return err
+	}
+	file, err := writeTempFile("crc-domain-*.xml", xml)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file)
+	if _, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "define", file); err != nil {
+		return fmt.Errorf("Failed to update the %s of the VM %v: %s", what, err, stderr)
+	}
+	return nil
+}
+
+// readDomain returns the persistent definition of the libvirt domain name
+func readDomain(name string) (*libvirtxml.Domain, error) {
+	stdout, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "dumpxml", "--inactive", name)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read the VM definition %v: %s", err, stderr)
+	}
+	domain := &libvirtxml.Domain{}
+	if err := domain.Unmarshal(stdout); err != nil {
+		return nil, fmt.Errorf("Failed to parse the VM definition: %v", err)
+	}
+	return domain, nil
+}
+
+// writeTempFile writes content to a new temporary file only readable by the
+// user and returns its path
+func writeTempFile(pattern, content string) (string, error) {
+	file, err := ioutil.TempFile("", pattern)
+	if err != nil {
+		return "", err
+	}
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// diskEncryptionSupported returns nil, qemu encrypts qcow2 images with LUKS
+func diskEncryptionSupported() error {
+	return nil
+}
+
+// encryptDiskImage converts the disk image of the new VM name to a LUKS
+// encrypted qcow2 image with passphrase, and makes the domain open it with
+// a libvirt secret
+func encryptDiskImage(name, diskImage, passphrase string) error {
+	passphraseFile, err := writeTempFile("crc-passphrase-*", passphrase)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(passphraseFile)
+	encryptedPath := diskImage + ".encrypted"
+	if _, stderr, err := crcos.RunWithDefaultLocale("qemu-img", "convert", "-f", "qcow2", "-O", "qcow2",
+		"--object", fmt.Sprintf("secret,id=sec0,file=%s", passphraseFile),
+		"-o", "encrypt.format=luks,encrypt.key-secret=sec0", diskImage, encryptedPath); err != nil {
+		_ = os.Remove(encryptedPath)
+		return fmt.Errorf("Failed to encrypt the disk image %v: %s", err, stderr)
+	}
+	if err := os.Rename(encryptedPath, diskImage); err != nil {
+		return err
+	}
+	secretUUID := uuid.New()
+	return updateDomain(name, "disk encryption", func(domain *libvirtxml.Domain) bool {
+		return libvirt.UpdateDiskEncryption(domain, diskImage, secretUUID)
+	})
+}
+
+// diskEncryptionSecret returns the UUID of the libvirt secret of the disk
+// image of the VM name, an empty string when it is not encrypted
+func diskEncryptionSecret(name, diskImage string) (string, error) {
+	domain, err := readDomain(name)
+	if err != nil {
+		return "", err
+	}
+	return libvirt.DiskEncryptionSecret(domain, diskImage), nil
+}
+
+// loadDiskEncryptionSecret defines the ephemeral libvirt secret secretUUID
+// with passphrase, libvirt forgets it when its daemon restarts so it is
+// defined before each start of the VM
+func loadDiskEncryptionSecret(secretUUID, diskImage, passphrase string) error {
+	xml, err := libvirt.EncryptionSecretXML(secretUUID, diskImage)
+	if err != nil {
+		return err
+	}
+	secretFile, err := writeTempFile("crc-secret-*.xml", xml)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(secretFile)
+	if _, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "secret-define", secretFile); err != nil {
+		return fmt.Errorf("Failed to define the disk encryption secret %v: %s", err, stderr)
+	}
+	// the value is passed in a file, it would be visible to all the users of
+	// the host on the command line
+	valueFile, err := writeTempFile("crc-secret-value-*", base64.StdEncoding.EncodeToString([]byte(passphrase)))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(valueFile)
+	if _, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "secret-set-value", "--secret", secretUUID, "--file", valueFile); err != nil {
+		return fmt.Errorf("Failed to set the disk encryption secret %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// undefineDiskEncryptionSecret removes the libvirt secret secretUUID
+func undefineDiskEncryptionSecret(secretUUID string) error {
+	if _, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "secret-undefine", secretUUID); err != nil {
+		return fmt.Errorf("Failed to remove the disk encryption secret %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// configureInstanceAddress gives the VM with a non-zero network index its own
+// MAC address, and the address of its index on the crc network. The VM with
+// the index 0 uses the ones of the network definition.
+func configureInstanceAddress(name string, index int, subnet *net.IPNet) error {
+	if index == 0 {
+		return nil
+	}
+	ip, err := network.NthInstanceIP(subnet, index)
+	if err != nil {
+		return err
+	}
+	mac := libvirt.InstanceMACAddress(index)
+	if err := updateDomain(name, "network interface", func(domain *libvirtxml.Domain) bool {
+		return libvirt.UpdateInterfaceMAC(domain, mac)
+	}); err != nil {
+		return err
+	}
+	// the entry left by a deleted instance with the same index is replaced
+	_ = removeInstanceAddress(index, subnet)
+	if _, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "net-update", libvirt.DefaultNetwork,
+		"add-last", "ip-dhcp-host", libvirt.DHCPHostXML(mac, ip.String()), "--live", "--config"); err != nil {
+		return fmt.Errorf("Failed to reserve %s for the VM on the %s network %v: %s", ip, libvirt.DefaultNetwork, err, stderr)
+	}
+	return nil
+}
+
+// removeInstanceAddress removes the address of the VM with the network index
+// from the crc network
+func removeInstanceAddress(index int, subnet *net.IPNet) error {
+	if index == 0 {
+		return nil
+	}
+	ip, err := network.NthInstanceIP(subnet, index)
+	if err != nil {
+		return err
+	}
+	if _, stderr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "net-update", libvirt.DefaultNetwork,
+		"delete", "ip-dhcp-host", libvirt.DHCPHostXML(libvirt.InstanceMACAddress(index), ip.String()), "--live", "--config"); err != nil {
+		return fmt.Errorf("Failed to release %s on the %s network %v: %s", ip, libvirt.DefaultNetwork, err, stderr)
+	}
+	return nil
+}
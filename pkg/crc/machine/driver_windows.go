@@ -3,12 +3,17 @@ package machine
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
 
 	"github.com/code-ready/crc/pkg/crc/machine/config"
 	"github.com/code-ready/crc/pkg/crc/machine/hyperv"
 	machineHyperv "github.com/code-ready/crc/pkg/drivers/hyperv"
 	"github.com/code-ready/crc/pkg/libmachine"
 	"github.com/code-ready/crc/pkg/libmachine/host"
+	"github.com/code-ready/crc/pkg/os/windows/powershell"
 )
 
 func newHost(api libmachine.API, machineConfig config.MachineConfig) (*host.Host, error) {
@@ -33,3 +38,142 @@ func updateDriverConfig(host *host.Host, driver *machineHyperv.Driver) error {
 	}
 	return host.UpdateConfig(driverData)
 }
+
+// consoleLogPath returns the path of the file where the hypervisor logs the VM output,
+// Hyper-V does not keep such a log
+func consoleLogPath(_ string) string {
+	return ""
+}
+
+// snapshotCount returns the number of checkpoints Hyper-V keeps for the VM
+func snapshotCount(name string) (int, error) {
+	stdout, stderr, err := powershell.Execute(fmt.Sprintf("@(Hyper-V\\Get-VMSnapshot -VMName %s).Count", name))
+	if err != nil {
+		return 0, fmt.Errorf("Failed to list the VM checkpoints %v: %s", err, stderr)
+	}
+	return strconv.Atoi(strings.TrimSpace(stdout))
+}
+
+// pauseVM suspends the VM in memory
+func pauseVM(name string) error {
+	if _, stderr, err := powershell.Execute(fmt.Sprintf("Hyper-V\\Suspend-VM -Name %s", name)); err != nil {
+		return fmt.Errorf("Failed to pause the VM %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// resumeVM resumes the VM suspended by pauseVM
+func resumeVM(name string) error {
+	if _, stderr, err := powershell.Execute(fmt.Sprintf("Hyper-V\\Resume-VM -Name %s", name)); err != nil {
+		return fmt.Errorf("Failed to resume the VM %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// compactDiskImage releases the space of the blocks the guest trimmed from
+// the dynamically expanding VHDX, the VM must be stopped
+func compactDiskImage(path string) error {
+	if _, stderr, err := powershell.Execute(fmt.Sprintf("Hyper-V\\Optimize-VHD -Path '%s' -Mode Full", path)); err != nil {
+		return fmt.Errorf("Failed to compact the disk image %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// createSnapshot creates a checkpoint of the stopped VM, it only holds the
+// disk
+func createSnapshot(name, snapshotName, _, _ string) error {
+	if _, stderr, err := powershell.Execute(fmt.Sprintf("Hyper-V\\Checkpoint-VM -Name %s -SnapshotName '%s'", name, snapshotName)); err != nil {
+		return fmt.Errorf("Failed to create the VM checkpoint %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// createLiveSnapshot creates a production checkpoint of the running VM,
+// Hyper-V freezes its filesystems through the VSS daemon of the guest itself
+// and does not save its memory. The standard checkpoints which would save it
+// are not used as a fallback.
+func createLiveSnapshot(name, snapshotName, _, _ string, _ func() (func() error, error)) error {
+	if _, stderr, err := powershell.Execute(fmt.Sprintf("Hyper-V\\Set-VM -Name %s -CheckpointType ProductionOnly", name)); err != nil {
+		return fmt.Errorf("Failed to enable the production checkpoints of the VM %v: %s", err, stderr)
+	}
+	return createSnapshot(name, snapshotName, "", "")
+}
+
+func revertSnapshot(name, snapshotName, _, _ string) error {
+	if _, stderr, err := powershell.Execute(fmt.Sprintf("Hyper-V\\Restore-VMSnapshot -VMName %s -Name '%s' -Confirm:$false", name, snapshotName)); err != nil {
+		return fmt.Errorf("Failed to revert the VM to its checkpoint %v: %s", err, stderr)
+	}
+	return nil
+}
+
+func deleteSnapshot(name, snapshotName, _ string) error {
+	if _, stderr, err := powershell.Execute(fmt.Sprintf("Hyper-V\\Remove-VMSnapshot -VMName %s -Name '%s'", name, snapshotName)); err != nil {
+		return fmt.Errorf("Failed to delete the VM checkpoint %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// deleteSnapshots does nothing, Hyper-V removes the checkpoints with the VM
+func deleteSnapshots(_ string) error {
+	return nil
+}
+
+// resizeDiskOnline does nothing, the driver resizes the VHDX of the running
+// VM itself
+func resizeDiskOnline(_, _ string, _ uint64) error {
+	return nil
+}
+
+// configureSharedDirs fails when directories are shared, Hyper-V cannot
+// export them with virtiofs
+func configureSharedDirs(_ string, sharedDirs []config.SharedDir) error {
+	if len(sharedDirs) > 0 {
+		return errors.New("Hyper-V does not support sharing directories with the VM")
+	}
+	return nil
+}
+
+// attachRegistryDisk fails when a disk is requested, the Hyper-V driver
+// cannot attach extra disks
+func attachRegistryDisk(_, path string) error {
+	if path != "" {
+		return errors.New("Hyper-V does not support attaching a registry disk to the VM")
+	}
+	return nil
+}
+
+// configureInstanceAddress does nothing, the DHCP server of the Hyper-V switch gives each
+// VM its own address
+func configureInstanceAddress(_ string, _ int, _ *net.IPNet) error {
+	return nil
+}
+
+func removeInstanceAddress(_ int, _ *net.IPNet) error {
+	return nil
+}
+
+// diskEncryptionSupported fails, only the libvirt driver encrypts the disk
+// of the VM
+func diskEncryptionSupported() error {
+	return errors.New("Hyper-V does not support encrypting the disk of the VM")
+}
+
+// encryptDiskImage fails, see diskEncryptionSupported
+func encryptDiskImage(_, _, _ string) error {
+	return diskEncryptionSupported()
+}
+
+// diskEncryptionSecret returns an empty string, the disk is never encrypted
+func diskEncryptionSecret(_, _ string) (string, error) {
+	return "", nil
+}
+
+// loadDiskEncryptionSecret does nothing, the disk is never encrypted
+func loadDiskEncryptionSecret(_, _, _ string) error {
+	return nil
+}
+
+// undefineDiskEncryptionSecret does nothing, the disk is never encrypted
+func undefineDiskEncryptionSecret(_ string) error {
+	return nil
+}
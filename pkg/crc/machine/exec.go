@@ -0,0 +1,128 @@
+package machine
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/pkg/errors"
+)
+
+type execAuditEntry struct {
+	Time       time.Time `json:"time"`
+	Command    []string  `json:"command"`
+	Privileged bool      `json:"privileged"`
+	Allowed    bool      `json:"allowed"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Exec runs a command in the VM on behalf of an API client. Only the commands
+// listed in the exec-allowed-commands setting can be run, or in the
+// exec-privileged-commands setting when run as root, and every request,
+// allowed or denied, is recorded in the exec audit log.
+func (client *client) Exec(execConfig types.ExecConfig) (*types.ExecResult, error) {
+	entry := execAuditEntry{
+		Time:       time.Now(),
+		Command:    execConfig.Command,
+		Privileged: execConfig.Privileged,
+	}
+	result, err := client.exec(execConfig, &entry)
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if err := writeExecAuditEntry(constants.ExecAuditLogPath, entry); err != nil {
+		logging.Warnf("Failed to write exec audit log: %v", err)
+	}
+	return result, err
+}
+
+func (client *client) exec(execConfig types.ExecConfig, entry *execAuditEntry) (*types.ExecResult, error) {
+	if err := checkExecPolicy(client.execPolicy(), execConfig.Command, execConfig.Privileged); err != nil {
+		return nil, err
+	}
+	entry.Allowed = true
+
+	running, err := client.IsRunning()
+	if err != nil {
+		return nil, err
+	}
+	if !running {
+		return nil, errors.New("machine is not running")
+	}
+	_, sshRunner, err := loadVM(client)
+	if err != nil {
+		return nil, err
+	}
+	defer sshRunner.Close()
+
+	quoted := make([]string, 0, len(execConfig.Command))
+	for _, arg := range execConfig.Command {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	command := strings.Join(quoted, " ")
+	if execConfig.Privileged {
+		command = sshRunner.PrivilegedCommand(command)
+	}
+	logging.Infof("Running '%s' in the VM on behalf of an API client", strings.Join(execConfig.Command, " "))
+	stdout, stderr, err := sshRunner.Run(command)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to run '%s' %v: %s", execConfig.Command[0], err, stderr)
+	}
+	return &types.ExecResult{
+		Stdout: stdout,
+		Stderr: stderr,
+	}, nil
+}
+
+// execPolicy lists the commands which can be run in the VM, as the ssh user
+// and as root
+type execPolicy struct {
+	allowed    []string
+	privileged []string
+}
+
+func checkExecPolicy(policy execPolicy, command []string, privileged bool) error {
+	if len(command) == 0 || command[0] == "" {
+		return errors.New("no command provided")
+	}
+	allowedCommands, setting, what := policy.allowed, crcConfig.ExecAllowedCommands, "running commands"
+	if privileged {
+		allowedCommands, setting, what = policy.privileged, crcConfig.ExecPrivilegedCommands, "running commands as root"
+	}
+	if len(allowedCommands) == 0 {
+		return fmt.Errorf("%s in the VM is disabled, use 'crc config set %s' to enable it", what, setting)
+	}
+	for _, allowed := range allowedCommands {
+		if command[0] == allowed {
+			return nil
+		}
+	}
+	return fmt.Errorf("'%s' is not in the list of commands allowed by the %s setting", command[0], setting)
+}
+
+// shellQuote quotes arg so that it is passed as a single argument by the VM shell
+func shellQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+func writeExecAuditEntry(path string, entry execAuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,32 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckExecPolicy(t *testing.T) {
+	policy := execPolicy{allowed: []string{"journalctl", "crictl"}}
+
+	assert.NoError(t, checkExecPolicy(policy, []string{"crictl", "ps"}, false))
+	assert.EqualError(t, checkExecPolicy(policy, []string{"rm", "-rf", "/"}, false), "'rm' is not in the list of commands allowed by the exec-allowed-commands setting")
+	assert.EqualError(t, checkExecPolicy(policy, []string{"/usr/bin/crictl"}, false), "'/usr/bin/crictl' is not in the list of commands allowed by the exec-allowed-commands setting")
+	assert.EqualError(t, checkExecPolicy(policy, nil, false), "no command provided")
+	assert.Error(t, checkExecPolicy(execPolicy{}, []string{"crictl"}, false))
+}
+
+func TestCheckExecPolicyPrivileged(t *testing.T) {
+	policy := execPolicy{allowed: []string{"journalctl", "crictl"}}
+	assert.EqualError(t, checkExecPolicy(policy, []string{"crictl", "ps"}, true), "running commands as root in the VM is disabled, use 'crc config set exec-privileged-commands' to enable it")
+
+	policy.privileged = []string{"crictl"}
+	assert.NoError(t, checkExecPolicy(policy, []string{"crictl", "ps"}, true))
+	assert.EqualError(t, checkExecPolicy(policy, []string{"journalctl"}, true), "'journalctl' is not in the list of commands allowed by the exec-privileged-commands setting")
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'journalctl'`, shellQuote("journalctl"))
+	assert.Equal(t, `'$(reboot)'`, shellQuote("$(reboot)"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+}
@@ -3,10 +3,15 @@ package fakemachine
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"time"
 
+	"github.com/code-ready/crc/pkg/crc/cluster"
 	"github.com/code-ready/crc/pkg/crc/machine/state"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/network/forward"
 )
 
 func NewClient() *Client {
@@ -21,6 +26,10 @@ func NewFailingClient() *Client {
 
 type Client struct {
 	Failing bool
+	// the VM is stopped, Stop and PowerOff do nothing
+	Stopped bool
+	// the instance does not exist
+	Missing bool
 }
 
 var DummyClusterConfig = types.ClusterConfig{
@@ -36,11 +45,20 @@ func (c *Client) GetName() string {
 	return "crc"
 }
 
-func (c *Client) Delete() error {
+func (c *Client) Delete(deleteConfig types.DeleteConfig) (*types.DeleteResult, error) {
 	if c.Failing {
-		return errors.New("delete failed")
+		return nil, errors.New("delete failed")
 	}
-	return nil
+	if c.Missing {
+		if !deleteConfig.IgnoreMissing {
+			return nil, errors.New("machine does not exist")
+		}
+		return &types.DeleteResult{AlreadyDeleted: true}, nil
+	}
+	if err := checkProtectionToken(deleteConfig.ProtectionToken); err != nil {
+		return nil, err
+	}
+	return &types.DeleteResult{}, nil
 }
 
 func (c *Client) GetConsoleURL() (*types.ConsoleResult, error) {
@@ -61,11 +79,14 @@ func (c *Client) ConnectionDetails() (*types.ConnectionDetails, error) {
 	return nil, errors.New("not implemented")
 }
 
-func (c *Client) PowerOff() error {
+func (c *Client) PowerOff(powerOffConfig types.PowerOffConfig) (*types.StopResult, error) {
 	if c.Failing {
-		return errors.New("poweroff failed")
+		return &types.StopResult{State: state.Running}, errors.New("poweroff failed")
 	}
-	return nil
+	if err := checkProtectionToken(powerOffConfig.ProtectionToken); err != nil {
+		return &types.StopResult{State: state.Running}, err
+	}
+	return &types.StopResult{State: state.Stopped, AlreadyStopped: c.Stopped}, nil
 }
 
 func (c *Client) GenerateBundle(forceStop bool) error {
@@ -75,6 +96,34 @@ func (c *Client) GenerateBundle(forceStop bool) error {
 	return nil
 }
 
+func (c *Client) CompactDisk(forceStop bool) (*types.CompactDiskResult, error) {
+	if c.Failing {
+		return nil, errors.New("disk compaction failed")
+	}
+	return &types.CompactDiskResult{
+		SizeBefore: 31e9,
+		SizeAfter:  18e9,
+		Stopped:    forceStop,
+	}, nil
+}
+
+func (c *Client) SetConfig(vmConfig types.VMConfig) (*types.SetConfigResult, error) {
+	if c.Failing {
+		return nil, errors.New("VM configuration change failed")
+	}
+	result := &types.SetConfigResult{}
+	if vmConfig.Memory != 0 {
+		result.Changes = append(result.Changes, types.ResourceChange{Resource: "memory", Previous: 9216, Current: vmConfig.Memory})
+	}
+	if vmConfig.CPUs != 0 {
+		result.Changes = append(result.Changes, types.ResourceChange{Resource: "cpus", Previous: 4, Current: vmConfig.CPUs})
+	}
+	if vmConfig.DiskSize != 0 {
+		result.Changes = append(result.Changes, types.ResourceChange{Resource: "disk-size", Previous: 31, Current: vmConfig.DiskSize})
+	}
+	return result, nil
+}
+
 func (c *Client) Start(ctx context.Context, startConfig types.StartConfig) (*types.StartResult, error) {
 	if c.Failing {
 		return nil, errors.New("Failed to start")
@@ -85,11 +134,11 @@ func (c *Client) Start(ctx context.Context, startConfig types.StartConfig) (*typ
 	}, nil
 }
 
-func (c *Client) Stop() (state.State, error) {
+func (c *Client) Stop() (*types.StopResult, error) {
 	if c.Failing {
-		return state.Running, errors.New("stop failed")
+		return &types.StopResult{State: state.Running}, errors.New("stop failed")
 	}
-	return state.Stopped, nil
+	return &types.StopResult{State: state.Stopped, AlreadyStopped: c.Stopped}, nil
 }
 
 func (c *Client) Status() (*types.ClusterStatusResult, error) {
@@ -102,11 +151,226 @@ func (c *Client) Status() (*types.ClusterStatusResult, error) {
 		OpenshiftVersion: "4.5.1",
 		DiskUse:          10_000_000_000,
 		DiskSize:         20_000_000_000,
+		CertsExpiry:      time.Date(2031, time.January, 1, 0, 0, 0, 0, time.UTC),
+		ClusterID:        "6c4b2c56-0e6f-4c23-8b4f-3f2b6f1b1e27",
+		Hypervisor: types.HypervisorResources{
+			AllocatedMemory: 9216,
+			AllocatedCPUs:   4,
+			UsedMemory:      6_000_000_000,
+			CPUSteal:        1.5,
+			DiskImageSize:   15_000_000_000,
+		},
+		Uptime:          2*time.Hour + 30*time.Minute,
+		LastStart:       time.Date(2021, time.June, 1, 8, 0, 0, 0, time.UTC),
+		BundleBuildTime: time.Date(2021, time.May, 1, 0, 0, 0, 0, time.UTC),
+	}, nil
+}
+
+func (c *Client) ListImages() (*types.ImagesResult, error) {
+	if c.Failing {
+		return nil, errors.New("listing images failed")
+	}
+	return &types.ImagesResult{
+		Images: []cluster.Image{
+			{
+				ID:       "sha256:7a8b2c",
+				RepoTags: []string{"quay.io/crcont/routes-controller:latest"},
+				Size:     52428800,
+			},
+		},
+	}, nil
+}
+
+func (c *Client) NetworkSelfTest() (*types.NetworkSelfTestResult, error) {
+	if c.Failing {
+		return nil, errors.New("network self-test failed")
+	}
+	return &types.NetworkSelfTestResult{
+		Healthy: false,
+		Checks: []cluster.SelfTestCheck{
+			{Name: cluster.RegistryPullCheck, Success: true},
+			{Name: cluster.ClusterDNSCheck, Success: true},
+			{Name: cluster.ExternalDNSCheck, Success: true},
+			{Name: cluster.EgressCheck, Success: false},
+		},
+	}, nil
+}
+
+func (c *Client) ConfigChanged(key string, oldValue interface{}) (*types.ConfigChangeResult, error) {
+	if c.Failing {
+		return nil, errors.New("config change failed")
+	}
+	return &types.ConfigChangeResult{
+		Impact: types.ConfigAppliedLive,
 	}, nil
 }
 
+func (c *Client) History() ([]types.HistoryEntry, error) {
+	if c.Failing {
+		return nil, errors.New("history failed")
+	}
+	return []types.HistoryEntry{
+		{
+			Time:      time.Date(2021, time.September, 1, 10, 0, 0, 0, time.UTC),
+			User:      "crc",
+			Operation: "start",
+			Parameters: map[string]interface{}{
+				"memory": 9216,
+				"cpus":   4,
+			},
+			Success: true,
+		},
+		{
+			Time:      time.Date(2021, time.September, 1, 18, 0, 0, 0, time.UTC),
+			User:      "crc",
+			Operation: "delete",
+			Success:   false,
+			Error:     "invalid protection token",
+		},
+	}, nil
+}
+
+func (c *Client) AddPortForward(portForward forward.Forward) error {
+	if c.Failing {
+		return errors.New("port forward failed")
+	}
+	return portForward.Validate()
+}
+
+func (c *Client) RemovePortForward(local string) error {
+	if c.Failing {
+		return errors.New("port forward removal failed")
+	}
+	return nil
+}
+
+func (c *Client) ListPortForwards() ([]forward.Forward, error) {
+	if c.Failing {
+		return nil, errors.New("port forwards failed")
+	}
+	return []forward.Forward{
+		{Local: "127.0.0.1:8080", Remote: 30080},
+	}, nil
+}
+
+func (c *Client) Hibernate() error {
+	if c.Failing {
+		return errors.New("hibernate failed")
+	}
+	return nil
+}
+
+func (c *Client) UpdateProxy() error {
+	if c.Failing {
+		return errors.New("proxy update failed")
+	}
+	return nil
+}
+
+func (c *Client) ShiftClock(_ time.Duration) error {
+	if c.Failing {
+		return errors.New("clock shift failed")
+	}
+	return nil
+}
+
+func (c *Client) Pause(_ types.PauseConfig) error {
+	if c.Failing {
+		return errors.New("pause failed")
+	}
+	return nil
+}
+
+func (c *Client) Resume(_ types.ResumeConfig) error {
+	if c.Failing {
+		return errors.New("resume failed")
+	}
+	return nil
+}
+
+const DummyProtectionToken = "9f8e7d6c5b4a3210"
+
+func (c *Client) Protect() (string, error) {
+	if c.Failing {
+		return "", errors.New("protect failed")
+	}
+	return DummyProtectionToken, nil
+}
+
+func (c *Client) Unprotect(token string) error {
+	if c.Failing {
+		return errors.New("unprotect failed")
+	}
+	if token != DummyProtectionToken {
+		return errors.New("invalid protection token")
+	}
+	return nil
+}
+
+// checkProtectionToken accepts an empty token, the fake instance is not
+// protected, or DummyProtectionToken
+func checkProtectionToken(token string) error {
+	if token != "" && token != DummyProtectionToken {
+		return errors.New("invalid protection token")
+	}
+	return nil
+}
+
+func (c *Client) Exec(execConfig types.ExecConfig) (*types.ExecResult, error) {
+	if c.Failing {
+		return nil, errors.New("exec failed")
+	}
+	return &types.ExecResult{
+		Stdout: "crc\n",
+	}, nil
+}
+
+func (c *Client) TailUnitLogs(_ context.Context, logsConfig types.UnitLogsConfig, writer io.Writer) error {
+	if c.Failing {
+		return errors.New("tailing unit logs failed")
+	}
+	_, err := fmt.Fprintf(writer, "2021-10-17T12:00:00+0000 crc systemd[1]: Started %s.\n", logsConfig.Unit)
+	return err
+}
+
+func (c *Client) SSH(sshConfig types.SSHConfig) error {
+	if c.Failing {
+		return errors.New("ssh failed")
+	}
+	_, err := fmt.Fprintln(sshConfig.Stdout, "crc")
+	return err
+}
+
+func (c *Client) Reconcile() error {
+	if c.Failing {
+		return errors.New("reconcile failed")
+	}
+	return nil
+}
+
+func (c *Client) ScheduledSnapshot() error {
+	if c.Failing {
+		return errors.New("snapshot failed")
+	}
+	return nil
+}
+
+func (c *Client) Snapshot(_ string) error {
+	if c.Failing {
+		return errors.New("snapshot failed")
+	}
+	return nil
+}
+
+func (c *Client) Revert(_ string) error {
+	if c.Failing {
+		return errors.New("revert failed")
+	}
+	return nil
+}
+
 func (c *Client) Exists() (bool, error) {
-	return true, nil
+	return !c.Missing, nil
 }
 
 func (c *Client) IsRunning() (bool, error) {
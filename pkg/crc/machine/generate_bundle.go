@@ -10,6 +10,7 @@ import (
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/code-ready/crc/pkg/crc/oc"
 	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
 	"github.com/code-ready/machine/libmachine/state"
@@ -17,6 +18,12 @@ import (
 )
 
 func (client *client) GenerateBundle(forceStop bool) error {
+	// the pull secret is removed and the VM is stopped below, an encrypted
+	// disk must be refused before that
+	if err := client.checkDiskNotEncrypted(); err != nil {
+		return err
+	}
+
 	bundleMetadata, sshRunner, err := loadVM(client)
 	if err != nil {
 		return err
@@ -35,7 +42,7 @@ func (client *client) GenerateBundle(forceStop bool) error {
 	// Stop the cluster
 	if _, err := client.Stop(); err != nil {
 		if forceStop {
-			if err := client.PowerOff(); err != nil {
+			if _, err := client.PowerOff(types.PowerOffConfig{}); err != nil {
 				return err
 			}
 		} else {
@@ -71,7 +78,7 @@ func (client *client) GenerateBundle(forceStop bool) error {
 		return err
 	}
 
-	if err := copier.CopyPrivateSSHKey(constants.GetPrivateKeyPath()); err != nil {
+	if err := copier.CopyPrivateSSHKey(client.profile().PrivateKeyPath()); err != nil {
 		return err
 	}
 
@@ -82,7 +89,7 @@ func (client *client) GenerateBundle(forceStop bool) error {
 	// Copy disk image
 	logging.Infof("Copying the disk image to %s", customBundleNameWithoutExtension)
 	logging.Debugf("Absolute path of custom bundle directory: %s", customBundleDir)
-	diskPath, diskFormat, err := copyDiskImage(customBundleDir)
+	diskPath, diskFormat, err := copyDiskImage(client.profile(), customBundleDir)
 	if err != nil {
 		return err
 	}
@@ -103,6 +110,22 @@ func (client *client) GenerateBundle(forceStop bool) error {
 	return nil
 }
 
+func (client *client) checkDiskNotEncrypted() error {
+	libMachineAPIClient, cleanup := createLibMachineClient()
+	defer cleanup()
+
+	host, err := libMachineAPIClient.Load(client.name)
+	if err != nil {
+		return errors.Wrap(err, "Cannot load machine")
+	}
+	if encrypted, err := diskEncrypted(host); err != nil {
+		return err
+	} else if encrypted {
+		return errors.New("The disk image is encrypted, it cannot be used to generate a bundle")
+	}
+	return nil
+}
+
 func loadVM(client *client) (*bundle.CrcBundleInfo, *crcssh.Runner, error) {
 	libMachineAPIClient, cleanup := createLibMachineClient()
 	defer cleanup()
@@ -129,7 +152,7 @@ func loadVM(client *client) (*bundle.CrcBundleInfo, *crcssh.Runner, error) {
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "Error getting the IP")
 	}
-	sshRunner, err := crcssh.CreateRunner(instanceIP, getSSHPort(client.useVSock()), crcBundleMetadata.GetSSHKeyPath(), constants.GetPrivateKeyPath(), constants.GetRsaPrivateKeyPath())
+	sshRunner, err := client.createSSHRunner(instanceIP, getSSHPort(client.useVSock()), crcBundleMetadata, crcBundleMetadata.GetSSHKeyPath(), client.profile().PrivateKeyPath(), client.profile().RsaPrivateKeyPath())
 	if err != nil {
 		return nil, nil, errors.Wrap(err, "Error creating the ssh client")
 	}
@@ -5,16 +5,16 @@ import (
 	"path/filepath"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/machine/profile"
 	crcos "github.com/code-ready/crc/pkg/os"
 )
 
-func copyDiskImage(destDir string) (string, string, error) {
+func copyDiskImage(instanceProfile profile.Profile, destDir string) (string, string, error) {
 	const destFormat = "qcow2"
 
-	imageName := fmt.Sprintf("%s.qcow2", constants.DefaultName)
-
-	srcPath := filepath.Join(constants.MachineInstanceDir, constants.DefaultName, imageName)
-	destPath := filepath.Join(destDir, imageName)
+	srcPath := filepath.Join(instanceProfile.MachineDir(), fmt.Sprintf("%s.qcow2", instanceProfile.Name))
+	// the image in the bundle keeps the name of the default instance
+	destPath := filepath.Join(destDir, fmt.Sprintf("%s.qcow2", constants.DefaultName))
 
 	_, _, err := crcos.RunWithDefaultLocale("qemu-img", "convert", "-f", "qcow2", "-O", destFormat, srcPath, destPath)
 	if err != nil {
@@ -5,8 +5,10 @@ package machine
 import (
 	"fmt"
 	"runtime"
+
+	"github.com/code-ready/crc/pkg/crc/machine/profile"
 )
 
-func copyDiskImage(dirName string) (string, string, error) {
+func copyDiskImage(instanceProfile profile.Profile, dirName string) (string, string, error) {
 	return "", "", fmt.Errorf("Not implemented for %s", runtime.GOOS)
 }
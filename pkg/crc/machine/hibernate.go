@@ -0,0 +1,57 @@
+package machine
+
+import (
+	"context"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/store"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+)
+
+// Hibernate stops the cluster without stopping the VM, for a resume which is
+// much faster than a start
+func (client *client) Hibernate() error {
+	_, sshRunner, err := loadVM(client)
+	if err != nil {
+		return err
+	}
+	defer sshRunner.Close()
+
+	// recorded first so that a partially hibernated cluster is not reported as unreachable
+	if err := store.ForInstance(client.name).SetHibernated(true); err != nil {
+		return err
+	}
+	return cluster.Hibernate(sshRunner)
+}
+
+// resumeCluster starts the cluster stopped by Hibernate
+func (client *client) resumeCluster() error {
+	_, sshRunner, err := loadVM(client)
+	if err != nil {
+		return err
+	}
+	defer sshRunner.Close()
+
+	if err := cluster.Resume(context.Background(), sshRunner); err != nil {
+		return err
+	}
+	return store.ForInstance(client.name).SetHibernated(false)
+}
+
+func (client *client) isHibernated() bool {
+	hibernated, err := store.ForInstance(client.name).Hibernated()
+	if err != nil {
+		logging.Debugf("Cannot get hibernation state: %v", err)
+		return false
+	}
+	return hibernated
+}
+
+// openshiftStatus returns the status of the cluster of a running VM
+func (client *client) openshiftStatus(ctx context.Context, ip string) (types.OpenshiftStatus, []cluster.OperatorStatus) {
+	if client.isHibernated() {
+		return types.OpenshiftHibernated, nil
+	}
+	return getOpenShiftStatus(ctx, ip, client.profile().KubeconfigPath())
+}
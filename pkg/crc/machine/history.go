@@ -0,0 +1,241 @@
+package machine
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"os/user"
+	"path/filepath"
+	"time"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+)
+
+const (
+	historyStart         = "start"
+	historyStop          = "stop"
+	historyDelete        = "delete"
+	historyPowerOff      = "poweroff"
+	historyHibernate     = "hibernate"
+	historyPause         = "pause"
+	historyResume        = "resume"
+	historyConfigChanged = "config-change"
+	historySetConfig     = "set-config"
+	historyUpdateProxy   = "update-proxy"
+	historyShiftClock    = "shift-clock"
+	historySnapshot      = "snapshot"
+	historyRevert        = "revert"
+)
+
+// historyPath is the append-only file where the lifecycle operations of the
+// instance called name are recorded. It is kept out of the instance directory
+// so that it outlives the deletion of the instance.
+func historyPath(name string) string {
+	return filepath.Join(constants.CrcBaseDir, "history", name+".jsonl")
+}
+
+// History returns the lifecycle operations of the instance, oldest first
+func (client *client) History() ([]types.HistoryEntry, error) {
+	return readHistory(historyPath(client.name))
+}
+
+// historyClient records the lifecycle operations of the instance and their
+// outcome, to find out who changed a shared instance and how
+type historyClient struct {
+	Client
+	config crcConfig.Storage
+	path   string
+}
+
+func newHistoryClient(underlying Client, name string, config crcConfig.Storage) *historyClient {
+	return &historyClient{
+		Client: underlying,
+		config: config,
+		path:   historyPath(name),
+	}
+}
+
+func (client *historyClient) record(operation string, parameters map[string]interface{}, err error) {
+	entry := types.HistoryEntry{
+		Time:       time.Now(),
+		User:       currentUser(),
+		Operation:  operation,
+		Parameters: parameters,
+		Success:    err == nil,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if err := appendHistoryEntry(client.path, entry); err != nil {
+		logging.Warnf("Failed to record %s in the instance history: %v", operation, err)
+	}
+}
+
+func (client *historyClient) Start(ctx context.Context, startConfig types.StartConfig) (*types.StartResult, error) {
+	result, err := client.Client.Start(ctx, startConfig)
+	client.record(historyStart, map[string]interface{}{
+		"bundle":   startConfig.BundlePath,
+		"memory":   startConfig.Memory,
+		"cpus":     startConfig.CPUs,
+		"diskSize": startConfig.DiskSize,
+	}, err)
+	return result, err
+}
+
+func (client *historyClient) Stop() (*types.StopResult, error) {
+	result, err := client.Client.Stop()
+	client.record(historyStop, nil, err)
+	return result, err
+}
+
+func (client *historyClient) Hibernate() error {
+	err := client.Client.Hibernate()
+	client.record(historyHibernate, nil, err)
+	return err
+}
+
+func (client *historyClient) Pause(pauseConfig types.PauseConfig) error {
+	err := client.Client.Pause(pauseConfig)
+	client.record(historyPause, nil, err)
+	return err
+}
+
+func (client *historyClient) Resume(resumeConfig types.ResumeConfig) error {
+	err := client.Client.Resume(resumeConfig)
+	client.record(historyResume, nil, err)
+	return err
+}
+
+func (client *historyClient) UpdateProxy() error {
+	err := client.Client.UpdateProxy()
+	client.record(historyUpdateProxy, nil, err)
+	return err
+}
+
+func (client *historyClient) ShiftClock(offset time.Duration) error {
+	err := client.Client.ShiftClock(offset)
+	client.record(historyShiftClock, map[string]interface{}{
+		"offset": offset.String(),
+	}, err)
+	return err
+}
+
+func (client *historyClient) Snapshot(snapshotName string) error {
+	err := client.Client.Snapshot(snapshotName)
+	client.record(historySnapshot, map[string]interface{}{
+		"name": snapshotName,
+	}, err)
+	return err
+}
+
+func (client *historyClient) Revert(snapshotName string) error {
+	err := client.Client.Revert(snapshotName)
+	client.record(historyRevert, map[string]interface{}{
+		"name": snapshotName,
+	}, err)
+	return err
+}
+
+func (client *historyClient) PowerOff(powerOffConfig types.PowerOffConfig) (*types.StopResult, error) {
+	result, err := client.Client.PowerOff(powerOffConfig)
+	client.record(historyPowerOff, nil, err)
+	return result, err
+}
+
+func (client *historyClient) Delete(deleteConfig types.DeleteConfig) (*types.DeleteResult, error) {
+	result, err := client.Client.Delete(deleteConfig)
+	client.record(historyDelete, map[string]interface{}{
+		"clearCache": deleteConfig.ClearCache,
+		"keepBundle": deleteConfig.KeepBundle,
+		"keepData":   deleteConfig.KeepData,
+	}, err)
+	return result, err
+}
+
+func (client *historyClient) ConfigChanged(key string, oldValue interface{}) (*types.ConfigChangeResult, error) {
+	result, err := client.Client.ConfigChanged(key, oldValue)
+	parameters := map[string]interface{}{
+		"key":      key,
+		"oldValue": redactSetting(key, oldValue),
+		"newValue": redactSetting(key, client.config.Get(key).Value),
+	}
+	if result != nil {
+		parameters["impact"] = result.Impact
+	}
+	client.record(historyConfigChanged, parameters, err)
+	return result, err
+}
+
+func (client *historyClient) SetConfig(vmConfig types.VMConfig) (*types.SetConfigResult, error) {
+	result, err := client.Client.SetConfig(vmConfig)
+	client.record(historySetConfig, map[string]interface{}{
+		"memory":   vmConfig.Memory,
+		"cpus":     vmConfig.CPUs,
+		"diskSize": vmConfig.DiskSize,
+	}, err)
+	return result, err
+}
+
+func redactSetting(key string, value interface{}) interface{} {
+	for _, redacted := range crcConfig.RedactedSettings {
+		if key == redacted && value != nil && value != "" {
+			return "<redacted>"
+		}
+	}
+	return value
+}
+
+func currentUser() string {
+	u, err := user.Current()
+	if err != nil {
+		logging.Debugf("Cannot get current user: %v", err)
+		return ""
+	}
+	return u.Username
+}
+
+func appendHistoryEntry(path string, entry types.HistoryEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readHistory(path string) ([]types.HistoryEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []types.HistoryEntry{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	entries := []types.HistoryEntry{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry types.HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			logging.Debugf("Skipping invalid history entry: %v", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
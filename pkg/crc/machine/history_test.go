@@ -0,0 +1,61 @@
+package machine
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistoryClient(t *testing.T) {
+	cfg := crcConfig.New(crcConfig.NewEmptyInMemoryStorage())
+	crcConfig.RegisterSettings(cfg)
+	_, err := cfg.Set(crcConfig.KubeAdminPassword, "secret")
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "history", "crc.jsonl")
+	client := &historyClient{
+		Client: fakemachine.NewClient(),
+		config: cfg,
+		path:   path,
+	}
+	_, err = client.Start(context.Background(), types.StartConfig{Memory: 9216, CPUs: 4})
+	require.NoError(t, err)
+	_, err = client.ConfigChanged(crcConfig.KubeAdminPassword, "")
+	require.NoError(t, err)
+	client.Client = fakemachine.NewFailingClient()
+	_, err = client.Delete(types.DeleteConfig{})
+	assert.Error(t, err)
+
+	entries, err := readHistory(path)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	assert.Equal(t, "start", entries[0].Operation)
+	assert.True(t, entries[0].Success)
+	assert.Equal(t, float64(9216), entries[0].Parameters["memory"])
+	assert.Equal(t, currentUser(), entries[0].User)
+
+	assert.Equal(t, "config-change", entries[1].Operation)
+	assert.Equal(t, map[string]interface{}{
+		"key":      crcConfig.KubeAdminPassword,
+		"oldValue": "",
+		"newValue": "<redacted>",
+		"impact":   "live",
+	}, entries[1].Parameters)
+
+	assert.Equal(t, "delete", entries[2].Operation)
+	assert.False(t, entries[2].Success)
+	assert.Equal(t, "delete failed", entries[2].Error)
+}
+
+func TestReadMissingHistory(t *testing.T) {
+	entries, err := readHistory(filepath.Join(t.TempDir(), "crc.jsonl"))
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
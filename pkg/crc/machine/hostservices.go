@@ -0,0 +1,28 @@
+package machine
+
+import (
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+// startHostServices starts the host services depending on the cluster, such
+// as reverse proxies, once the cluster is started
+func (client *client) startHostServices() {
+	for _, service := range crcConfig.GetHostServices(client.config) {
+		logging.Infof("Starting host service %s...", service)
+		if err := startHostService(service); err != nil {
+			logging.Warnf("Failed to start host service %s: %v", service, err)
+		}
+	}
+}
+
+// stopHostServices stops the host services depending on the cluster before
+// the VM goes away
+func (client *client) stopHostServices() {
+	for _, service := range crcConfig.GetHostServices(client.config) {
+		logging.Infof("Stopping host service %s...", service)
+		if err := stopHostService(service); err != nil {
+			logging.Warnf("Failed to stop host service %s: %v", service, err)
+		}
+	}
+}
@@ -0,0 +1,14 @@
+package machine
+
+import (
+	"github.com/code-ready/crc/pkg/os/launchd"
+)
+
+// host services are launchd agents
+func startHostService(label string) error {
+	return launchd.StartAgent(label)
+}
+
+func stopHostService(label string) error {
+	return launchd.StopAgent(label)
+}
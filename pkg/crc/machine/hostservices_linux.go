@@ -0,0 +1,14 @@
+package machine
+
+import (
+	"github.com/code-ready/crc/pkg/crc/systemd"
+)
+
+// host services are systemd user units
+func startHostService(name string) error {
+	return systemd.NewHostSystemdCommander().User().Start(name)
+}
+
+func stopHostService(name string) error {
+	return systemd.NewHostSystemdCommander().User().Stop(name)
+}
@@ -0,0 +1,13 @@
+package machine
+
+import (
+	"errors"
+)
+
+func startHostService(name string) error {
+	return errors.New("host services are not supported on Windows")
+}
+
+func stopHostService(name string) error {
+	return errors.New("host services are not supported on Windows")
+}
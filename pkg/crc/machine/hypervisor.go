@@ -0,0 +1,84 @@
+package machine
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/libmachine/host"
+	crcos "github.com/code-ready/crc/pkg/os"
+	"github.com/pkg/errors"
+)
+
+// getHypervisorResources returns the resources the hypervisor gives to the VM
+// and the space its disk image uses on the host
+func (client *client) getHypervisorResources(host *host.Host) types.HypervisorResources {
+	var resources types.HypervisorResources
+	driver, err := loadDriverConfig(host)
+	if err != nil {
+		logging.Debugf("Cannot load driver config: %v", err)
+		return resources
+	}
+	resources.AllocatedMemory = driver.Memory
+	resources.AllocatedCPUs = driver.CPU
+
+	diskImage := driver.ResolveStorePath(fmt.Sprintf("%s.%s", driver.MachineName, driver.ImageFormat))
+	if resources.DiskImageSize, err = crcos.AllocatedFileSize(diskImage); err != nil {
+		logging.Debugf("Cannot get disk image size: %v", err)
+	}
+	if resources.Snapshots, err = client.getSnapshotCount(); err != nil {
+		logging.Debugf("Cannot get snapshot count: %v", err)
+	}
+	return resources
+}
+
+// getSnapshotCount returns the number of snapshots of the VM, asking the
+// hypervisor at most once a minute unless crc changes them
+func (client *client) getSnapshotCount() (int, error) {
+	count, err, _ := client.snapshots.Memoize("snapshots", func() (interface{}, error) {
+		return snapshotCount(client.name)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count.(int), nil
+}
+
+// forgetSnapshotCount makes the next Status count the snapshots again, after
+// crc created or deleted some
+func (client *client) forgetSnapshotCount() {
+	client.snapshots.Storage.Delete("snapshots")
+}
+
+// getGuestUsage returns the memory used by the guest, in bytes, the
+// percentage of its CPU time stolen by the host since boot, and its boot time
+func (client *client) getGuestUsage(ip string, bundle *bundle.CrcBundleInfo) (int64, float64, time.Time) {
+	usage, err, _ := client.guestUsage.Memoize("usage", func() (interface{}, error) {
+		sshRunner, err := client.createSSHRunner(ip, getSSHPort(client.useVSock()), bundle, client.profile().PrivateKeyPath(), client.profile().RsaPrivateKeyPath(), bundle.GetSSHKeyPath())
+		if err != nil {
+			return nil, errors.Wrap(err, "Error creating the ssh client")
+		}
+		defer sshRunner.Close()
+		return cluster.GetResourceUsage(sshRunner)
+	})
+	if err != nil {
+		logging.Debugf("Cannot get guest resource usage: %v", err)
+		return 0, 0, time.Time{}
+	}
+	resourceUsage := usage.(*cluster.ResourceUsage)
+	return usedMemory(resourceUsage), cpuSteal(resourceUsage), resourceUsage.BootTime
+}
+
+func usedMemory(usage *cluster.ResourceUsage) int64 {
+	return int64(usage.MemTotal - usage.MemAvailable)
+}
+
+func cpuSteal(usage *cluster.ResourceUsage) float64 {
+	if usage.CPUTotal == 0 {
+		return 0
+	}
+	return float64(usage.CPUSteal) * 100 / float64(usage.CPUTotal)
+}
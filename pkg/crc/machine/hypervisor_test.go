@@ -0,0 +1,35 @@
+package machine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/kofalt/go-memoize"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuestUsage(t *testing.T) {
+	usage := &cluster.ResourceUsage{
+		MemTotal:     8 * 1024 * 1024 * 1024,
+		MemAvailable: 3 * 1024 * 1024 * 1024,
+		CPUTotal:     2000,
+		CPUSteal:     50,
+	}
+	assert.Equal(t, int64(5*1024*1024*1024), usedMemory(usage))
+	assert.Equal(t, 2.5, cpuSteal(usage))
+	assert.Equal(t, 0.0, cpuSteal(&cluster.ResourceUsage{}))
+}
+
+func TestSnapshotCountIsMemoized(t *testing.T) {
+	client := &client{name: "crc", snapshots: memoize.NewMemoizer(time.Minute, 5*time.Minute)}
+	client.snapshots.Storage.SetDefault("snapshots", 3)
+
+	count, err := client.getSnapshotCount()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	client.forgetSnapshotCount()
+	_, found := client.snapshots.Storage.Get("snapshots")
+	assert.False(t, found)
+}
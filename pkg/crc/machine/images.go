@@ -0,0 +1,59 @@
+package machine
+
+import (
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	libmachinestate "github.com/code-ready/machine/libmachine/state"
+	"github.com/pkg/errors"
+)
+
+func (client *client) ListImages() (*types.ImagesResult, error) {
+	libMachineAPIClient, cleanup := createLibMachineClient()
+	defer cleanup()
+	host, err := libMachineAPIClient.Load(client.name)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot load machine")
+	}
+	vmState, err := host.Driver.GetState()
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != libmachinestate.Running {
+		return nil, errors.New("machine is not running")
+	}
+
+	crcBundleMetadata, err := getBundleMetadataFromDriver(host.Driver)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error loading bundle metadata")
+	}
+	ip, err := getIP(host, client.useVSock())
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting the IP")
+	}
+	sshRunner, err := client.createSSHRunner(ip, getSSHPort(client.useVSock()), crcBundleMetadata, client.profile().PrivateKeyPath(), client.profile().RsaPrivateKeyPath(), crcBundleMetadata.GetSSHKeyPath())
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+
+	images, err := cluster.ListImages(sshRunner)
+	if err != nil {
+		return nil, err
+	}
+	return &types.ImagesResult{
+		Images: images,
+	}, nil
+}
+
+func (client *client) prePullImages() []string {
+	var images []string
+	for _, image := range strings.Split(client.config.Get(crcConfig.PrePullImages).AsString(), ",") {
+		if image = strings.TrimSpace(image); image != "" {
+			images = append(images, image)
+		}
+	}
+	return images
+}
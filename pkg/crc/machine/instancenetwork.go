@@ -0,0 +1,68 @@
+package machine
+
+import (
+	"fmt"
+	"io/ioutil"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/store"
+	"github.com/code-ready/crc/pkg/libmachine"
+	libmachinestate "github.com/code-ready/machine/libmachine/state"
+)
+
+// configureInstanceNetwork gives the new VM its own address on the VM network
+// with the system network mode, so that the instances of several profiles can
+// run at the same time
+func (client *client) configureInstanceNetwork() error {
+	if client.useVSock() {
+		return nil
+	}
+	index, err := store.Global().NetworkIndex(client.name, constants.DefaultName)
+	if err != nil {
+		return err
+	}
+	return configureInstanceAddress(client.name, index, crcConfig.GetNetworkCIDR(client.config))
+}
+
+// releaseInstanceNetwork frees the address of the deleted VM
+func (client *client) releaseInstanceNetwork() {
+	if !client.useVSock() {
+		index, err := store.Global().NetworkIndex(client.name, constants.DefaultName)
+		if err == nil {
+			err = removeInstanceAddress(index, crcConfig.GetNetworkCIDR(client.config))
+		}
+		if err != nil {
+			logging.Debugf("Cannot remove the address of %s from the VM network: %v", client.name, err)
+		}
+	}
+	if err := store.Global().ReleaseNetworkIndex(client.name); err != nil {
+		logging.Debugf("Cannot release the network index of %s: %v", client.name, err)
+	}
+}
+
+// checkNoOtherInstanceRunning fails when the VM of another instance is
+// running, the user network mode forwards the same host ports to all of them
+func checkNoOtherInstanceRunning(api libmachine.API, name string) error {
+	entries, err := ioutil.ReadDir(constants.MachineInstanceDir)
+	if err != nil {
+		return nil
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || entry.Name() == name {
+			continue
+		}
+		if exists, err := api.Exists(entry.Name()); err != nil || !exists {
+			continue
+		}
+		host, err := api.Load(entry.Name())
+		if err != nil {
+			continue
+		}
+		if vmState, err := host.Driver.GetState(); err == nil && vmState == libmachinestate.Running {
+			return fmt.Errorf("The instance %s is running, only one instance can run at a time with the user network mode, stop it with 'crc stop --profile %s' first", entry.Name(), entry.Name())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+)
+
+// Instances gives access to several instances which can be operated
+// concurrently. Operations on a single instance are serialized, and the
+// host memory is shared between the instances being started or running.
+type Instances struct {
+	newClient func(name string) Client
+	// host memory which can be allocated to instances, in MiB
+	totalMemory int
+
+	lock    sync.Mutex
+	clients map[string]Client
+	// memory allocated to the instances started through Instances, in MiB
+	reservedMemory map[string]int
+}
+
+func NewInstances(totalMemory int, newClient func(name string) Client) *Instances {
+	return &Instances{
+		newClient:      newClient,
+		totalMemory:    totalMemory,
+		clients:        make(map[string]Client),
+		reservedMemory: make(map[string]int),
+	}
+}
+
+// Get returns the client of the instance called name, the same client is
+// returned for every call with the same name
+func (instances *Instances) Get(name string) Client {
+	instances.lock.Lock()
+	defer instances.lock.Unlock()
+
+	if client, ok := instances.clients[name]; ok {
+		return client
+	}
+	client := &reservingClient{
+		Client:    NewSynchronizedMachine(instances.newClient(name)),
+		name:      name,
+		instances: instances,
+	}
+	instances.clients[name] = client
+	return client
+}
+
+func (instances *Instances) reserve(name string, memory int) error {
+	instances.lock.Lock()
+	defer instances.lock.Unlock()
+
+	reserved := 0
+	for instance, instanceMemory := range instances.reservedMemory {
+		if instance != name {
+			reserved += instanceMemory
+		}
+	}
+	if reserved+memory > instances.totalMemory {
+		return fmt.Errorf("not enough memory to start %s: %d MiB requested, %d MiB available (%d MiB used by other instances)",
+			name, memory, instances.totalMemory-reserved, reserved)
+	}
+	instances.reservedMemory[name] = memory
+	return nil
+}
+
+func (instances *Instances) release(name string) {
+	instances.lock.Lock()
+	defer instances.lock.Unlock()
+
+	delete(instances.reservedMemory, name)
+}
+
+// reservingClient accounts for the memory used by the instance when it is
+// started and stopped
+type reservingClient struct {
+	Client
+	name      string
+	instances *Instances
+}
+
+func (client *reservingClient) Start(ctx context.Context, startConfig types.StartConfig) (*types.StartResult, error) {
+	reserve := func(memory int) error {
+		return client.instances.reserve(client.name, memory)
+	}
+	if startConfig.Memory != 0 {
+		if err := reserve(startConfig.Memory); err != nil {
+			return nil, err
+		}
+	} else {
+		// the memory is chosen from the bundle and the host during the
+		// start, it is reserved once it is known
+		ctx = withMemoryReservation(ctx, reserve)
+	}
+	result, err := client.Client.Start(ctx, startConfig)
+	if err != nil {
+		client.instances.release(client.name)
+	}
+	return result, err
+}
+
+func (client *reservingClient) Stop() (*types.StopResult, error) {
+	result, err := client.Client.Stop()
+	if err == nil {
+		client.instances.release(client.name)
+	}
+	return result, err
+}
+
+func (client *reservingClient) PowerOff(powerOffConfig types.PowerOffConfig) (*types.StopResult, error) {
+	result, err := client.Client.PowerOff(powerOffConfig)
+	if err == nil {
+		client.instances.release(client.name)
+	}
+	return result, err
+}
+
+func (client *reservingClient) CompactDisk(forceStop bool) (*types.CompactDiskResult, error) {
+	result, err := client.Client.CompactDisk(forceStop)
+	if err == nil && result.Stopped {
+		client.instances.release(client.name)
+	}
+	return result, err
+}
+
+func (client *reservingClient) Delete(deleteConfig types.DeleteConfig) (*types.DeleteResult, error) {
+	result, err := client.Client.Delete(deleteConfig)
+	if err == nil {
+		client.instances.release(client.name)
+	}
+	return result, err
+}
+
+type memoryReservationKey struct{}
+
+// withMemoryReservation returns a context in which reserveMemory calls
+// reserve
+func withMemoryReservation(ctx context.Context, reserve func(memory int) error) context.Context {
+	return context.WithValue(ctx, memoryReservationKey{}, reserve)
+}
+
+// reserveMemory reserves the memory in MiB of an instance started with ctx,
+// it does nothing when the instance is not started through Instances
+func reserveMemory(ctx context.Context, memory int) error {
+	if reserve, ok := ctx.Value(memoryReservationKey{}).(func(memory int) error); ok {
+		return reserve(memory)
+	}
+	return nil
+}
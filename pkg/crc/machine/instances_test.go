@@ -0,0 +1,61 @@
+package machine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInstancesMemoryReservation(t *testing.T) {
+	instances := NewInstances(16384, func(name string) Client {
+		return fakemachine.NewClient()
+	})
+	first := instances.Get("first")
+	second := instances.Get("second")
+	assert.Same(t, first, instances.Get("first"))
+
+	_, err := first.Start(context.Background(), types.StartConfig{Memory: 9216})
+	assert.NoError(t, err)
+	_, err = second.Start(context.Background(), types.StartConfig{Memory: 9216})
+	assert.EqualError(t, err, "not enough memory to start second: 9216 MiB requested, 7168 MiB available (9216 MiB used by other instances)")
+
+	// restarting an instance does not count its memory twice
+	_, err = first.Start(context.Background(), types.StartConfig{Memory: 10240})
+	assert.NoError(t, err)
+
+	_, err = first.Stop()
+	assert.NoError(t, err)
+	_, err = second.Start(context.Background(), types.StartConfig{Memory: 9216})
+	assert.NoError(t, err)
+}
+
+// choosingMachine chooses the memory of the instances started without one,
+// like the start of a real instance
+type choosingMachine struct {
+	*fakemachine.Client
+}
+
+func (m *choosingMachine) Start(ctx context.Context, startConfig types.StartConfig) (*types.StartResult, error) {
+	if startConfig.Memory == 0 {
+		if err := reserveMemory(ctx, 12288); err != nil {
+			return nil, err
+		}
+	}
+	return m.Client.Start(ctx, startConfig)
+}
+
+func TestInstancesReserveChosenMemory(t *testing.T) {
+	instances := NewInstances(16384, func(name string) Client {
+		return &choosingMachine{fakemachine.NewClient()}
+	})
+
+	_, err := instances.Get("first").Start(context.Background(), types.StartConfig{})
+	assert.NoError(t, err)
+	_, err = instances.Get("second").Start(context.Background(), types.StartConfig{})
+	assert.EqualError(t, err, "not enough memory to start second: 12288 MiB requested, 4096 MiB available (12288 MiB used by other instances)")
+	_, err = instances.Get("second").Start(context.Background(), types.StartConfig{Memory: 4096})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,66 @@
+package machine
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/profile"
+)
+
+// kubeAdminPasswordStore returns where the kubeadmin password of the instance
+// is kept
+func kubeAdminPasswordStore(instanceProfile profile.Profile) cluster.KubeAdminPasswordStore {
+	return cluster.KubeAdminPasswordStore{
+		KeyringKey: instanceProfile.KubeAdminPasswordKeyringKey(),
+		File:       instanceProfile.KubeAdminPasswordPath(),
+	}
+}
+
+// KubeAdminPassword returns the kubeadmin password of the instance called
+// name, an error when it has none
+func KubeAdminPassword(name string) (string, error) {
+	return kubeAdminPasswordStore(profile.Profile{Name: name}).Get()
+}
+
+// snapshotKubeAdminPasswordStore returns where the kubeadmin password of the
+// snapshot snapshotName of the instance name is kept, it must match the one
+// of the disk when the snapshot is reverted
+func snapshotKubeAdminPasswordStore(name, snapshotName string) cluster.KubeAdminPasswordStore {
+	return cluster.KubeAdminPasswordStore{
+		KeyringKey: profile.Profile{Name: name}.KubeAdminPasswordKeyringKey() + "@" + snapshotName,
+		File:       filepath.Join(snapshotDir(name, snapshotName), "kubeadmin-password"),
+	}
+}
+
+// copyKubeAdminPassword copies the kubeadmin password to the clones and the
+// snapshots of an instance, nothing is copied when there is none
+func copyKubeAdminPassword(from, to cluster.KubeAdminPasswordStore) error {
+	password, err := from.Get()
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return to.Set(password)
+}
+
+// deleteKubeAdminPasswords removes the kubeadmin passwords of the instance
+// name and of its snapshots from the keyring
+func deleteKubeAdminPasswords(name string) {
+	snapshots, err := ioutil.ReadDir(snapshotsDir(name))
+	if err != nil && !os.IsNotExist(err) {
+		logging.Debugf("Cannot list the snapshots of %s: %v", name, err)
+	}
+	for _, snapshot := range snapshots {
+		if err := snapshotKubeAdminPasswordStore(name, snapshot.Name()).Delete(); err != nil {
+			logging.Debugf("Cannot remove the kubeadmin password of snapshot %s: %v", snapshot.Name(), err)
+		}
+	}
+	if err := kubeAdminPasswordStore(profile.Profile{Name: name}).Delete(); err != nil {
+		logging.Warnf("Failed to remove the kubeadmin password: %v", err)
+	}
+}
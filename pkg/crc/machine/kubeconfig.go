@@ -14,7 +14,10 @@ import (
 	"time"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
+	crcerrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/machine/profile"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
+	crctls "github.com/code-ready/crc/pkg/crc/tls"
 	"github.com/openshift/oc/pkg/helpers/tokencmd"
 	"k8s.io/apimachinery/third_party/forked/golang/netutil"
 	restclient "k8s.io/client-go/rest"
@@ -22,10 +25,14 @@ import (
 	"k8s.io/client-go/tools/clientcmd/api"
 )
 
-const (
-	adminContext     = "crc-admin"
-	developerContext = "crc-developer"
-)
+// kubeconfigName returns the name of the kubeconfig context or user for the
+// instance called name, e.g. crc-admin or crc-<name>-admin
+func kubeconfigName(prefix, name, suffix string) string {
+	if name == constants.DefaultName {
+		return fmt.Sprintf("%s%s", prefix, suffix)
+	}
+	return fmt.Sprintf("%s-%s%s", prefix, name, suffix)
+}
 
 func updateClientCrtAndKeyToKubeconfig(clientKey, clientCrt []byte, srcKubeconfigPath, destKubeconfigPath string) error {
 	cfg, err := clientcmd.LoadFromFile(srcKubeconfigPath)
@@ -46,7 +53,10 @@ func updateClientCrtAndKeyToKubeconfig(clientKey, clientCrt []byte, srcKubeconfi
 	return clientcmd.WriteToFile(*cfg, destKubeconfigPath)
 }
 
-func writeKubeconfig(ip string, clusterConfig *types.ClusterConfig) error {
+// writeKubeconfig adds the contexts of the instance to the kubeconfig of the
+// user, profileCACert is the CA of the certificate the API server serves for
+// the hostname of a named profile, nil when it does not serve one
+func writeKubeconfig(name, ip string, clusterConfig *types.ClusterConfig, profileCACert *x509.Certificate) error {
 	kubeconfig := getGlobalKubeConfigPath()
 	dir := filepath.Dir(kubeconfig)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -59,7 +69,14 @@ func writeKubeconfig(ip string, clusterConfig *types.ClusterConfig) error {
 	if err != nil {
 		return err
 	}
-	host, err := hostname(clusterConfig.ClusterAPI)
+	server, tlsServerName, err := kubeconfigServer(name, clusterConfig.ClusterAPI, profileCACert != nil)
+	if err != nil {
+		return err
+	}
+	if profileCACert != nil {
+		ca = append(ca, crctls.CertToPem(profileCACert)...)
+	}
+	host, err := hostname(server)
 	if err != nil {
 		return err
 	}
@@ -69,14 +86,16 @@ func writeKubeconfig(ip string, clusterConfig *types.ClusterConfig) error {
 		return err
 	}
 	cfg.Clusters[host] = &api.Cluster{
-		Server:                   clusterConfig.ClusterAPI,
+		Server:                   server,
+		TLSServerName:            tlsServerName,
 		CertificateAuthorityData: ca,
 	}
 
-	if err := addContext(cfg, ip, clusterConfig, ca, adminContext, "kubeadmin", clusterConfig.KubeAdminPass); err != nil {
+	adminContext := kubeconfigName("crc", name, "-admin")
+	if err := addContext(cfg, ip, clusterConfig, ca, host, adminContext, kubeconfigName("kubeadmin", name, ""), "kubeadmin", clusterConfig.KubeAdminPass); err != nil {
 		return err
 	}
-	if err := addContext(cfg, ip, clusterConfig, ca, developerContext, "developer", "developer"); err != nil {
+	if err := addContext(cfg, ip, clusterConfig, ca, host, kubeconfigName("crc", name, "-developer"), kubeconfigName("developer", name, ""), developerUsername, developerPassword); err != nil {
 		return err
 	}
 
@@ -87,7 +106,48 @@ func writeKubeconfig(ip string, clusterConfig *types.ClusterConfig) error {
 	return clientcmd.WriteToFile(*cfg, kubeconfig)
 }
 
+// kubeconfigServer returns the API server URL of the instance called name in
+// the kubeconfig of the user, and the name of the server certificate when it
+// differs from the URL. The instances other than the default one are reached
+// through their own domain so that their kubeconfig entries do not collide,
+// the certificate of the domain of the bundle is used until the API server
+// serves the one of their domain.
+func kubeconfigServer(name, clusterAPI string, profileCertificate bool) (string, string, error) {
+	if name == constants.DefaultName {
+		return clusterAPI, "", nil
+	}
+	u, err := url.Parse(clusterAPI)
+	if err != nil {
+		return "", "", err
+	}
+	port := u.Port()
+	if port == "" {
+		port = "6443"
+	}
+	server := fmt.Sprintf("https://%s:%s", profile.Profile{Name: name}.APIHostname(), port)
+	if profileCertificate {
+		return server, "", nil
+	}
+	return server, u.Hostname(), nil
+}
+
 func certificateAuthority(kubeconfigFile string) ([]byte, error) {
+	cluster, err := crcCluster(kubeconfigFile)
+	if err != nil {
+		return nil, err
+	}
+	return cluster.CertificateAuthorityData, nil
+}
+
+func apiServerURL(kubeconfigFile string) (string, error) {
+	cluster, err := crcCluster(kubeconfigFile)
+	if err != nil {
+		return "", err
+	}
+	return cluster.Server, nil
+}
+
+func crcCluster(kubeconfigFile string) (*api.Cluster, error) {
 	builtin, err := clientcmd.LoadFromFile(kubeconfigFile)
 	if err != nil {
 		return nil, err
@@ -96,7 +156,7 @@ func certificateAuthority(kubeconfigFile string) ([]byte, error) {
 	if !ok {
 		return nil, fmt.Errorf("crc cluster not found in kubeconfig %s", kubeconfigFile)
 	}
-	return cluster.CertificateAuthorityData, nil
+	return cluster, nil
 }
 
 func adminClientCertificate(kubeconfigFile string) (string, error) {
@@ -122,17 +182,52 @@ func hostname(clusterAPI string) (string, error) {
 	return strings.ReplaceAll(h, ".", "-"), nil
 }
 
-func addContext(cfg *api.Config, ip string, clusterConfig *types.ClusterConfig, ca []byte, context, username, password string) error {
-	host, err := hostname(clusterConfig.ClusterAPI)
+func addContext(cfg *api.Config, ip string, clusterConfig *types.ClusterConfig, ca []byte, cluster, context, authInfo, username, password string) error {
+	token, err := requestToken(ip, clusterConfig, ca, username, password)
 	if err != nil {
 		return err
 	}
+	cfg.AuthInfos[authInfo] = &api.AuthInfo{
+		Token: token,
+	}
+	cfg.Contexts[context] = &api.Context{
+		Cluster:   cluster,
+		AuthInfo:  authInfo,
+		Namespace: "default",
+	}
+	return nil
+}
+
+// waitForLogin waits until the OAuth server delivers tokens to the kubeadmin
+// and developer users, the authentication operator can still be settling
+// when the cluster operators are reported as available
+func waitForLogin(ctx gocontext.Context, ip string, clusterConfig *types.ClusterConfig, timeout time.Duration) error {
+	ca, err := certificateAuthority(clusterConfig.KubeConfig)
+	if err != nil {
+		return err
+	}
+	login := func() error {
+		for username, password := range map[string]string{
+			"kubeadmin": clusterConfig.KubeAdminPass,
+			"developer": "developer",
+		} {
+			if _, err := requestToken(ip, clusterConfig, ca, username, password); err != nil {
+				return &crcerrors.RetriableError{Err: fmt.Errorf("cannot login as %s: %w", username, err)}
+			}
+		}
+		return nil
+	}
+	return crcerrors.Retry(ctx, timeout, login, 5*time.Second)
+}
+
+// requestToken logs into the cluster and returns an OAuth access token
+func requestToken(ip string, clusterConfig *types.ClusterConfig, ca []byte, username, password string) (string, error) {
 	roots := x509.NewCertPool()
 	ok := roots.AppendCertsFromPEM(ca)
 	if !ok {
-		return fmt.Errorf("failed to parse root certificate")
+		return "", fmt.Errorf("failed to parse root certificate")
 	}
-	token, err := tokencmd.RequestToken(&restclient.Config{
+	return tokencmd.RequestToken(&restclient.Config{
 		Proxy: clusterConfig.ProxyConfig.ProxyFunc(),
 		Host:  clusterConfig.ClusterAPI,
 		Transport: &http.Transport{
@@ -141,27 +236,18 @@ func addContext(cfg *api.Config, ip string, clusterConfig *types.ClusterConfig,
 				MinVersion: tls.VersionTLS12,
 			},
 			DialContext: func(ctx gocontext.Context, network, address string) (net.Conn, error) {
-				port := strings.SplitN(address, ":", 2)[1]
+				_, port, err := net.SplitHostPort(address)
+				if err != nil {
+					return nil, err
+				}
 				dialer := net.Dialer{
 					Timeout:   30 * time.Second,
 					KeepAlive: 30 * time.Second,
 				}
-				return dialer.Dial(network, fmt.Sprintf("%s:%s", ip, port))
+				return dialer.Dial(network, net.JoinHostPort(ip, port))
 			},
 		},
 	}, nil, username, password)
-	if err != nil {
-		return err
-	}
-	cfg.AuthInfos[username] = &api.AuthInfo{
-		Token: token,
-	}
-	cfg.Contexts[context] = &api.Context{
-		Cluster:   host,
-		AuthInfo:  username,
-		Namespace: "default",
-	}
-	return nil
 }
 
 // getGlobalKubeConfigPath returns the path to the first entry in the KUBECONFIG environment variable
@@ -176,16 +262,16 @@ func getGlobalKubeConfigPath() string {
 	return filepath.Join(constants.GetHomeDir(), ".kube", "config")
 }
 
-func cleanKubeconfig(input, output string) error {
+func cleanKubeconfig(name, input, output string) error {
 	cfg, err := clientcmd.LoadFromFile(input)
 	if err != nil {
 		return err
 	}
 
 	var clusterNames []string
-	for name, cluster := range cfg.Clusters {
-		if cluster.Server == fmt.Sprintf("https://api%s:6443", constants.ClusterDomain) {
-			clusterNames = append(clusterNames, name)
+	for clusterName, cluster := range cfg.Clusters {
+		if cluster.Server == fmt.Sprintf("https://api%s:6443", constants.GetClusterDomain(name)) {
+			clusterNames = append(clusterNames, clusterName)
 		}
 	}
 	var contextNames []string
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -40,17 +41,64 @@ func TestCertificateAuthority(t *testing.T) {
 	assert.Equal(t, expectedString, string(st), "")
 }
 
+func TestAPIServerURL(t *testing.T) {
+	f, err := ioutil.TempFile("", "kubeconfig")
+	assert.NoError(t, err, "")
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(dummyKubeconfigFileContent)
+	assert.NoError(t, err, "")
+	url, err := apiServerURL(f.Name())
+	assert.NoError(t, err, "")
+	assert.Equal(t, "https://api.crc.testing:6443", url)
+}
+
+func TestKubeconfigNames(t *testing.T) {
+	assert.Equal(t, "crc-admin", kubeconfigName("crc", constants.DefaultName, "-admin"))
+	assert.Equal(t, "kubeadmin", kubeconfigName("kubeadmin", constants.DefaultName, ""))
+	assert.Equal(t, "crc-ci-admin", kubeconfigName("crc", "ci", "-admin"))
+	assert.Equal(t, "kubeadmin-ci", kubeconfigName("kubeadmin", "ci", ""))
+
+	assert.Equal(t, ".crc.testing", constants.GetClusterDomain(constants.DefaultName))
+	assert.Equal(t, ".apps-crc.testing", constants.GetAppsDomain(constants.DefaultName))
+	assert.Equal(t, ".ci.crc.testing", constants.GetClusterDomain("ci"))
+	assert.Equal(t, ".apps.ci.crc.testing", constants.GetAppsDomain("ci"))
+}
+
+func TestKubeconfigServer(t *testing.T) {
+	server, tlsServerName, err := kubeconfigServer(constants.DefaultName, "https://api.crc.testing:6443", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.crc.testing:6443", server)
+	assert.Empty(t, tlsServerName)
+
+	server, tlsServerName, err = kubeconfigServer("test411", "https://api.crc.testing:6443", false)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.test411.crc.testing:6443", server)
+	assert.Equal(t, "api.crc.testing", tlsServerName)
+
+	// the API server serves the certificate of the domain of the profile
+	server, tlsServerName, err = kubeconfigServer("test411", "https://api.crc.testing:6443", true)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.test411.crc.testing:6443", server)
+	assert.Empty(t, tlsServerName)
+}
+
 func TestCleanKubeconfig(t *testing.T) {
 	dir, err := ioutil.TempDir("", "clean")
 	assert.NoError(t, err)
 	defer os.RemoveAll(dir)
 
-	assert.NoError(t, cleanKubeconfig(filepath.Join("testdata", "kubeconfig.in"), filepath.Join(dir, "kubeconfig")))
+	assert.NoError(t, cleanKubeconfig(constants.DefaultName, filepath.Join("testdata", "kubeconfig.in"), filepath.Join(dir, "kubeconfig")))
 	actual, err := ioutil.ReadFile(filepath.Join(dir, "kubeconfig"))
 	assert.NoError(t, err)
 	expected, err := ioutil.ReadFile(filepath.Join("testdata", "kubeconfig.out"))
 	assert.NoError(t, err)
 	assert.YAMLEq(t, string(expected), string(actual))
+
+	// the entries of the default instance are kept when another one is deleted
+	assert.NoError(t, cleanKubeconfig("ci", filepath.Join("testdata", "kubeconfig.out"), filepath.Join(dir, "kubeconfig")))
+	actual, err = ioutil.ReadFile(filepath.Join(dir, "kubeconfig"))
+	assert.NoError(t, err)
+	assert.YAMLEq(t, string(expected), string(actual))
 }
 
 func TestUpdateUserCaAndKeyToKubeconfig(t *testing.T) {
@@ -9,11 +9,17 @@ const (
 	DefaultNetwork     = "crc"
 	DefaultStoragePool = "crc"
 
-	// Static addresses
+	// Static MAC address of the VM, its IP address is derived from the network-cidr setting
 	MACAddress = "52:fd:fc:07:21:82"
-	IPAddress  = "192.168.130.11"
 )
 
+// InstanceMACAddress returns the static MAC address of the VM with the given
+// network index, the VM with the index 0 uses MACAddress
+func InstanceMACAddress(index int) string {
+	suffix := 0x2182 + index
+	return fmt.Sprintf("52:fd:fc:07:%02x:%02x", (suffix>>8)&0xff, suffix&0xff)
+}
+
 const (
 	MachineDriverCommand = "crc-driver-libvirt"
 	MachineDriverVersion = "0.13.1"
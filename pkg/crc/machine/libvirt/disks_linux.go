@@ -0,0 +1,65 @@
+package libvirt
+
+import (
+	"fmt"
+
+	libvirtxml "github.com/libvirt/libvirt-go-xml"
+)
+
+// UpdateExtraDisk attaches the qcow2 image at path to domain as a virtio
+// disk with the given serial, the VM finds it at
+// /dev/disk/by-id/virtio-<serial>. The disk is detached when path is empty.
+// It returns false when the domain already had this disk.
+func UpdateExtraDisk(domain *libvirtxml.Domain, serial, path string) bool {
+	if domain.Devices == nil {
+		domain.Devices = &libvirtxml.DomainDeviceList{}
+	}
+	var disks []libvirtxml.DomainDisk
+	usedTargets := map[string]bool{}
+	found := false
+	for _, disk := range domain.Devices.Disks {
+		if disk.Serial == serial {
+			if path != "" && diskFile(disk) == path {
+				found = true
+				disks = append(disks, disk)
+			}
+			continue
+		}
+		if disk.Target != nil {
+			usedTargets[disk.Target.Dev] = true
+		}
+		disks = append(disks, disk)
+	}
+	if found || (path == "" && len(disks) == len(domain.Devices.Disks)) {
+		return false
+	}
+	if path != "" {
+		disks = append(disks, libvirtxml.DomainDisk{
+			Device: "disk",
+			Driver: &libvirtxml.DomainDiskDriver{Name: "qemu", Type: "qcow2"},
+			Source: &libvirtxml.DomainDiskSource{
+				File: &libvirtxml.DomainDiskSourceFile{File: path},
+			},
+			Target: &libvirtxml.DomainDiskTarget{Dev: freeDiskTarget(usedTargets), Bus: "virtio"},
+			Serial: serial,
+		})
+	}
+	domain.Devices.Disks = disks
+	return true
+}
+
+func diskFile(disk libvirtxml.DomainDisk) string {
+	if disk.Source == nil || disk.Source.File == nil {
+		return ""
+	}
+	return disk.Source.File.File
+}
+
+func freeDiskTarget(usedTargets map[string]bool) string {
+	for c := 'b'; c <= 'z'; c++ {
+		if dev := fmt.Sprintf("vd%c", c); !usedTargets[dev] {
+			return dev
+		}
+	}
+	return ""
+}
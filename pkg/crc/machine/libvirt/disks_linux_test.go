@@ -0,0 +1,75 @@
+package libvirt
+
+import (
+	"testing"
+
+	libvirtxml "github.com/libvirt/libvirt-go-xml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const domainWithDiskXML = `<domain type='kvm'>
+  <name>crc</name>
+  <devices>
+    <disk type='file' device='disk'>
+      <driver name='qemu' type='qcow2'/>
+      <source file='/home/user/.crc/machines/crc/crc.qcow2'/>
+      <target dev='vda' bus='virtio'/>
+    </disk>
+  </devices>
+</domain>`
+
+func TestUpdateExtraDisk(t *testing.T) {
+	domain := &libvirtxml.Domain{}
+	require.NoError(t, domain.Unmarshal(domainWithDiskXML))
+	assert.False(t, UpdateExtraDisk(domain, "crc-registry", ""))
+
+	assert.True(t, UpdateExtraDisk(domain, "crc-registry", "/home/user/.crc/registry/crc.qcow2"))
+	require.Len(t, domain.Devices.Disks, 2)
+	disk := domain.Devices.Disks[1]
+	assert.Equal(t, "/home/user/.crc/registry/crc.qcow2", disk.Source.File.File)
+	assert.Equal(t, "vdb", disk.Target.Dev)
+	assert.Equal(t, "crc-registry", disk.Serial)
+
+	xml, err := domain.Marshal()
+	require.NoError(t, err)
+	domain = &libvirtxml.Domain{}
+	require.NoError(t, domain.Unmarshal(xml))
+	assert.False(t, UpdateExtraDisk(domain, "crc-registry", "/home/user/.crc/registry/crc.qcow2"))
+
+	assert.True(t, UpdateExtraDisk(domain, "crc-registry", ""))
+	require.Len(t, domain.Devices.Disks, 1)
+	assert.Equal(t, "vda", domain.Devices.Disks[0].Target.Dev)
+}
+
+func TestUpdateDiskEncryption(t *testing.T) {
+	const path = "/home/user/.crc/machines/crc/crc.qcow2"
+	const secretUUID = "4a9b2d4c-31cb-4d0e-9f6e-2b9b3b4b0c1a"
+	domain := &libvirtxml.Domain{}
+	require.NoError(t, domain.Unmarshal(domainWithDiskXML))
+	assert.Equal(t, "", DiskEncryptionSecret(domain, path))
+	assert.False(t, UpdateDiskEncryption(domain, "/home/user/.crc/registry/crc.qcow2", secretUUID))
+
+	assert.True(t, UpdateDiskEncryption(domain, path, secretUUID))
+	xml, err := domain.Marshal()
+	require.NoError(t, err)
+	assert.Contains(t, xml, `<encryption format="luks">`)
+	assert.Contains(t, xml, `<secret type="passphrase" uuid="4a9b2d4c-31cb-4d0e-9f6e-2b9b3b4b0c1a"></secret>`)
+
+	domain = &libvirtxml.Domain{}
+	require.NoError(t, domain.Unmarshal(xml))
+	assert.Equal(t, secretUUID, DiskEncryptionSecret(domain, path))
+	assert.False(t, UpdateDiskEncryption(domain, path, secretUUID))
+}
+
+func TestEncryptionSecretXML(t *testing.T) {
+	xml, err := EncryptionSecretXML("4a9b2d4c-31cb-4d0e-9f6e-2b9b3b4b0c1a", "/home/user/.crc/machines/crc/crc.qcow2")
+	require.NoError(t, err)
+	assert.Equal(t, `<secret ephemeral="yes" private="yes">
+  <description>CodeReady Containers disk encryption passphrase</description>
+  <uuid>4a9b2d4c-31cb-4d0e-9f6e-2b9b3b4b0c1a</uuid>
+  <usage type="volume">
+    <volume>/home/user/.crc/machines/crc/crc.qcow2</volume>
+  </usage>
+</secret>`, xml)
+}
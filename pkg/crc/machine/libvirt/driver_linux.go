@@ -19,6 +19,13 @@ func CreateHost(machineConfig config.MachineConfig) *libvirt.Driver {
 		libvirtDriver.Network = DefaultNetwork
 	}
 
+	if machineConfig.DiskCacheMode != "" {
+		libvirtDriver.CacheMode = machineConfig.DiskCacheMode
+	}
+	if machineConfig.DiskIOMode != "" {
+		libvirtDriver.IOMode = machineConfig.DiskIOMode
+	}
+
 	libvirtDriver.StoragePool = DefaultStoragePool
 	return libvirtDriver
 }
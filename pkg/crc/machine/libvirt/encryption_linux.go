@@ -0,0 +1,60 @@
+package libvirt
+
+import (
+	libvirtxml "github.com/libvirt/libvirt-go-xml"
+)
+
+// UpdateDiskEncryption makes domain open its LUKS encrypted qcow2 image at
+// path with the passphrase of the libvirt secret secretUUID. It returns false
+// when the domain has no such disk or already uses this secret.
+func UpdateDiskEncryption(domain *libvirtxml.Domain, path, secretUUID string) bool {
+	if domain.Devices == nil {
+		return false
+	}
+	for i, disk := range domain.Devices.Disks {
+		if diskFile(disk) != path {
+			continue
+		}
+		if DiskEncryptionSecret(domain, path) == secretUUID {
+			return false
+		}
+		domain.Devices.Disks[i].Source.Encryption = &libvirtxml.DomainDiskEncryption{
+			Format: "luks",
+			Secret: &libvirtxml.DomainDiskSecret{Type: "passphrase", UUID: secretUUID},
+		}
+		return true
+	}
+	return false
+}
+
+// DiskEncryptionSecret returns the UUID of the libvirt secret of the disk of
+// domain with the image at path, or an empty string when it is not encrypted
+func DiskEncryptionSecret(domain *libvirtxml.Domain, path string) string {
+	if domain.Devices == nil {
+		return ""
+	}
+	for _, disk := range domain.Devices.Disks {
+		if diskFile(disk) != path || disk.Source.Encryption == nil || disk.Source.Encryption.Secret == nil {
+			continue
+		}
+		return disk.Source.Encryption.Secret.UUID
+	}
+	return ""
+}
+
+// EncryptionSecretXML returns the definition of the libvirt secret secretUUID
+// holding the passphrase of the image at path. libvirt keeps its value in
+// memory only, and does not give it back.
+func EncryptionSecretXML(secretUUID, path string) (string, error) {
+	secret := libvirtxml.Secret{
+		Ephemeral:   "yes",
+		Private:     "yes",
+		UUID:        secretUUID,
+		Description: "CodeReady Containers disk encryption passphrase",
+		Usage: &libvirtxml.SecretUsage{
+			Type:   "volume",
+			Volume: path,
+		},
+	}
+	return secret.Marshal()
+}
@@ -0,0 +1,34 @@
+package libvirt
+
+import (
+	"fmt"
+
+	libvirtxml "github.com/libvirt/libvirt-go-xml"
+)
+
+// UpdateInterfaceMAC sets the MAC address of the interface of domain on the
+// crc network, the DHCP server of the network gives the VM the address
+// associated with it. It returns false when the interface already had it.
+func UpdateInterfaceMAC(domain *libvirtxml.Domain, mac string) bool {
+	if domain.Devices == nil {
+		return false
+	}
+	changed := false
+	for i, iface := range domain.Devices.Interfaces {
+		if iface.Source == nil || iface.Source.Network == nil || iface.Source.Network.Network != DefaultNetwork {
+			continue
+		}
+		if iface.MAC != nil && iface.MAC.Address == mac {
+			continue
+		}
+		domain.Devices.Interfaces[i].MAC = &libvirtxml.DomainInterfaceMAC{Address: mac}
+		changed = true
+	}
+	return changed
+}
+
+// DHCPHostXML is the DHCP entry of the crc network giving ip to the VM with
+// the MAC address mac
+func DHCPHostXML(mac, ip string) string {
+	return fmt.Sprintf("<host mac='%s' ip='%s'/>", mac, ip)
+}
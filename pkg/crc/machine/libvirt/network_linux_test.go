@@ -0,0 +1,35 @@
+package libvirt
+
+import (
+	"testing"
+
+	libvirtxml "github.com/libvirt/libvirt-go-xml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const domainWithInterfaceXML = `<domain type='kvm'>
+  <name>dev</name>
+  <devices>
+    <interface type='network'>
+      <mac address='52:fd:fc:07:21:82'/>
+      <source network='crc'/>
+      <model type='virtio'/>
+    </interface>
+  </devices>
+</domain>`
+
+func TestUpdateInterfaceMAC(t *testing.T) {
+	domain := &libvirtxml.Domain{}
+	require.NoError(t, domain.Unmarshal(domainWithInterfaceXML))
+	assert.False(t, UpdateInterfaceMAC(domain, MACAddress))
+
+	assert.True(t, UpdateInterfaceMAC(domain, InstanceMACAddress(1)))
+	assert.Equal(t, "52:fd:fc:07:21:83", domain.Devices.Interfaces[0].MAC.Address)
+	assert.False(t, UpdateInterfaceMAC(domain, InstanceMACAddress(1)))
+}
+
+func TestInstanceMACAddress(t *testing.T) {
+	assert.Equal(t, MACAddress, InstanceMACAddress(0))
+	assert.Equal(t, "52:fd:fc:07:22:00", InstanceMACAddress(0x7e))
+}
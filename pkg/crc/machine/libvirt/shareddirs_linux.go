@@ -0,0 +1,70 @@
+package libvirt
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/machine/config"
+	libvirtxml "github.com/libvirt/libvirt-go-xml"
+)
+
+// UpdateSharedDirs replaces the virtiofs filesystems of domain with the ones
+// of sharedDirs, the filesystems which were not added by crc are kept. virtiofs needs the memory of the VM to be shared with the
+// virtiofsd processes, it stays shared when the directories are no longer
+// shared. It returns false when the domain already had these filesystems.
+func UpdateSharedDirs(domain *libvirtxml.Domain, sharedDirs []config.SharedDir) bool {
+	if domain.Devices == nil {
+		domain.Devices = &libvirtxml.DomainDeviceList{}
+	}
+	// libvirt adds the addresses of the devices, the filesystems are
+	// compared by source and tag
+	var filesystems []libvirtxml.DomainFilesystem
+	var currentDirs []config.SharedDir
+	for _, filesystem := range domain.Devices.Filesystems {
+		if filesystem.Target != nil && strings.HasPrefix(filesystem.Target.Dir, config.SharedDirTagPrefix) {
+			currentDirs = append(currentDirs, config.SharedDir{Source: sourceDir(filesystem), Tag: filesystem.Target.Dir})
+			continue
+		}
+		filesystems = append(filesystems, filesystem)
+	}
+	var wantedDirs []config.SharedDir
+	for _, sharedDir := range sharedDirs {
+		wantedDirs = append(wantedDirs, config.SharedDir{Source: sharedDir.Source, Tag: sharedDir.Tag})
+	}
+
+	var wanted []libvirtxml.DomainFilesystem
+	for _, sharedDir := range sharedDirs {
+		wanted = append(wanted, libvirtxml.DomainFilesystem{
+			AccessMode: "passthrough",
+			Driver:     &libvirtxml.DomainFilesystemDriver{Type: "virtiofs"},
+			Source: &libvirtxml.DomainFilesystemSource{
+				Mount: &libvirtxml.DomainFilesystemSourceMount{Dir: sharedDir.Source},
+			},
+			Target: &libvirtxml.DomainFilesystemTarget{Dir: sharedDir.Tag},
+		})
+	}
+	if len(wanted) > 0 && !hasSharedMemory(domain) {
+		if domain.MemoryBacking == nil {
+			domain.MemoryBacking = &libvirtxml.DomainMemoryBacking{}
+		}
+		domain.MemoryBacking.MemorySource = &libvirtxml.DomainMemorySource{Type: "memfd"}
+		domain.MemoryBacking.MemoryAccess = &libvirtxml.DomainMemoryAccess{Mode: "shared"}
+	} else if reflect.DeepEqual(currentDirs, wantedDirs) {
+		return false
+	}
+	domain.Devices.Filesystems = append(filesystems, wanted...)
+	return true
+}
+
+func sourceDir(filesystem libvirtxml.DomainFilesystem) string {
+	if filesystem.Source == nil || filesystem.Source.Mount == nil {
+		return ""
+	}
+	return filesystem.Source.Mount.Dir
+}
+
+func hasSharedMemory(domain *libvirtxml.Domain) bool {
+	return domain.MemoryBacking != nil &&
+		domain.MemoryBacking.MemorySource != nil && domain.MemoryBacking.MemorySource.Type == "memfd" &&
+		domain.MemoryBacking.MemoryAccess != nil && domain.MemoryBacking.MemoryAccess.Mode == "shared"
+}
@@ -0,0 +1,53 @@
+package libvirt
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/config"
+	libvirtxml "github.com/libvirt/libvirt-go-xml"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const domainXML = `<domain type='kvm'>
+  <name>crc</name>
+  <memory unit='KiB'>9437184</memory>
+  <devices>
+    <filesystem type='mount' accessmode='passthrough'>
+      <source dir='/srv/other'/>
+      <target dir='other'/>
+    </filesystem>
+  </devices>
+</domain>`
+
+func TestUpdateSharedDirs(t *testing.T) {
+	domain := &libvirtxml.Domain{}
+	require.NoError(t, domain.Unmarshal(domainXML))
+	assert.False(t, UpdateSharedDirs(domain, nil))
+
+	sharedDirs := []config.SharedDir{{Source: "/home/user/src", Target: "/home/user/src", Tag: "crc-dir0"}}
+	assert.True(t, UpdateSharedDirs(domain, sharedDirs))
+	assert.Equal(t, "memfd", domain.MemoryBacking.MemorySource.Type)
+	assert.Equal(t, "shared", domain.MemoryBacking.MemoryAccess.Mode)
+	require.Len(t, domain.Devices.Filesystems, 2)
+	assert.Equal(t, "other", domain.Devices.Filesystems[0].Target.Dir)
+	assert.Equal(t, "virtiofs", domain.Devices.Filesystems[1].Driver.Type)
+	assert.Equal(t, "/home/user/src", domain.Devices.Filesystems[1].Source.Mount.Dir)
+	assert.Equal(t, "crc-dir0", domain.Devices.Filesystems[1].Target.Dir)
+
+	// the definition read back from libvirt is unchanged
+	xml, err := domain.Marshal()
+	require.NoError(t, err)
+	domain = &libvirtxml.Domain{}
+	require.NoError(t, domain.Unmarshal(xml))
+	assert.False(t, UpdateSharedDirs(domain, sharedDirs))
+
+	sharedDirs[0].Source = "/home/user/other"
+	assert.True(t, UpdateSharedDirs(domain, sharedDirs))
+	require.Len(t, domain.Devices.Filesystems, 2)
+	assert.Equal(t, "/home/user/other", domain.Devices.Filesystems[1].Source.Mount.Dir)
+
+	assert.True(t, UpdateSharedDirs(domain, nil))
+	require.Len(t, domain.Devices.Filesystems, 1)
+	assert.Equal(t, "other", domain.Devices.Filesystems[0].Target.Dir)
+}
@@ -5,17 +5,24 @@ const (
 	<name>{{ .NetworkName }}</name>
 	<uuid>49eee855-d342-46c3-9ed3-b8d1758814cd</uuid>
 	<forward mode='nat'>
-	  <nat>
+	  <nat{{ if .IPv6 }} ipv6='yes'{{ end }}>
 		<port start='1024' end='65535'/>
 	  </nat>
 	</forward>
 	<bridge name='crc' stp='on' delay='0'/>
 	<mac address='52:54:00:fd:be:d0'/>
-	<ip family='ipv4' address='192.168.130.1' prefix='24'>
+	<ip family='ipv4' address='{{ .HostIP }}' prefix='{{ .Prefix }}'>
 	  <dhcp>
 		<host mac='{{ .MAC }}' ip='{{ .IP }}'/>
 	  </dhcp>
 	</ip>
+	{{- if .IPv6 }}
+	<ip family='ipv6' address='{{ .HostIPv6 }}' prefix='{{ .IPv6Prefix }}'>
+	  <dhcp>
+		<range start='{{ .IPv6 }}' end='{{ .IPv6 }}'/>
+	  </dhcp>
+	</ip>
+	{{- end }}
   </network>`
 )
 
@@ -23,4 +30,12 @@ type NetworkConfig struct {
 	NetworkName string
 	MAC         string
 	IP          string
+	HostIP      string
+	Prefix      int
+	// the optional IPv6 subnet, the VM is alone on the network and gets the
+	// only address of the DHCPv6 range, libvirt matches the IPv6 hosts by
+	// DUID and not by MAC address
+	IPv6       string
+	HostIPv6   string
+	IPv6Prefix int
 }
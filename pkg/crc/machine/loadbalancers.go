@@ -0,0 +1,134 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/daemonclient"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+)
+
+const loadBalancersPollingInterval = 10 * time.Second
+
+type portForwarder interface {
+	Expose(req *types.ExposeRequest) error
+	Unexpose(req *types.UnexposeRequest) error
+}
+
+// loadBalancerForwarder forwards the host ports of the LoadBalancer services
+// to their node ports in the VM
+type loadBalancerForwarder struct {
+	forwarder portForwarder
+	// forwards created for the load balancers, by local address
+	forwards map[string]types.ExposeRequest
+	// local addresses which could not be forwarded, reported only once
+	failed map[string]bool
+}
+
+func newLoadBalancerForwarder(forwarder portForwarder) *loadBalancerForwarder {
+	return &loadBalancerForwarder{
+		forwarder: forwarder,
+		forwards:  map[string]types.ExposeRequest{},
+		failed:    map[string]bool{},
+	}
+}
+
+// WatchLoadBalancers forwards the host ports of the LoadBalancer services of
+// the cluster to the VM until ctx is cancelled, so that they can be reached on
+// localhost. This is only possible with user mode networking, and only done
+// while the expose-load-balancers setting is enabled.
+func WatchLoadBalancers(ctx context.Context, name string, config crcConfig.Storage) {
+	lbForwarder := newLoadBalancerForwarder(daemonclient.New().NetworkClient)
+	ocConfig := oc.UseOCWithConfig(name)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(loadBalancersPollingInterval):
+		}
+		if crcConfig.GetNetworkMode(config) != network.UserNetworkingMode || !config.Get(crcConfig.ExposeLoadBalancers).AsBool() {
+			lbForwarder.sync(nil)
+			continue
+		}
+		ports, err := cluster.GetLoadBalancerPorts(ocConfig)
+		if err != nil {
+			// the cluster is not running, the forwards are kept for its next start
+			logging.Debugf("Cannot get load balancer services: %v", err)
+			continue
+		}
+		lbForwarder.sync(ports)
+	}
+}
+
+func (lb *loadBalancerForwarder) sync(ports []cluster.LoadBalancerPort) {
+	wanted := map[string]cluster.LoadBalancerPort{}
+	for _, port := range ports {
+		local := fmt.Sprintf(":%d", port.Port)
+		if isReservedPort(local) {
+			lb.reportFailure(local, "Cannot forward port %d to the %s/%s load balancer, crc already uses it", port.Port, port.Namespace, port.Service)
+			continue
+		}
+		if _, ok := wanted[local]; ok {
+			lb.reportFailure(local, "Cannot forward port %d to the %s/%s load balancer, another load balancer uses it", port.Port, port.Namespace, port.Service)
+			continue
+		}
+		wanted[local] = port
+	}
+
+	for local, forward := range lb.forwards {
+		port, ok := wanted[local]
+		if ok && forward.Remote == loadBalancerRemote(port) {
+			continue
+		}
+		if err := lb.forwarder.Unexpose(&types.UnexposeRequest{Local: local}); err != nil {
+			logging.Debugf("Failed to stop forwarding %s: %v", local, err)
+		}
+		delete(lb.forwards, local)
+	}
+	for local := range lb.failed {
+		if _, ok := wanted[local]; !ok {
+			delete(lb.failed, local)
+		}
+	}
+
+	for local, port := range wanted {
+		if _, ok := lb.forwards[local]; ok {
+			continue
+		}
+		forward := types.ExposeRequest{Local: local, Remote: loadBalancerRemote(port)}
+		if err := lb.forwarder.Expose(&forward); err != nil {
+			lb.reportFailure(local, "Cannot forward port %d to the %s/%s load balancer: %v", port.Port, port.Namespace, port.Service, err)
+			continue
+		}
+		logging.Infof("Forwarding port %d to the %s/%s load balancer", port.Port, port.Namespace, port.Service)
+		lb.forwards[local] = forward
+		delete(lb.failed, local)
+	}
+}
+
+func (lb *loadBalancerForwarder) reportFailure(local string, format string, args ...interface{}) {
+	if lb.failed[local] {
+		return
+	}
+	lb.failed[local] = true
+	logging.Warnf(format, args...)
+}
+
+func loadBalancerRemote(port cluster.LoadBalancerPort) string {
+	return fmt.Sprintf("%s:%d", virtualMachineIP, port.NodePort)
+}
+
+func isReservedPort(local string) bool {
+	for _, port := range vsockPorts() {
+		if port.Local == local {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package machine
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePortForwarder struct {
+	exposed map[string]string
+	failing map[string]bool
+}
+
+func (f *fakePortForwarder) Expose(req *types.ExposeRequest) error {
+	if f.failing[req.Local] {
+		return errors.New("permission denied")
+	}
+	f.exposed[req.Local] = req.Remote
+	return nil
+}
+
+func (f *fakePortForwarder) Unexpose(req *types.UnexposeRequest) error {
+	delete(f.exposed, req.Local)
+	return nil
+}
+
+func TestLoadBalancerForwarder(t *testing.T) {
+	forwarder := &fakePortForwarder{
+		exposed: map[string]string{},
+		failing: map[string]bool{":81": true},
+	}
+	lb := newLoadBalancerForwarder(forwarder)
+
+	lb.sync([]cluster.LoadBalancerPort{
+		{Namespace: "demo", Service: "web", Port: 8080, NodePort: 31080},
+		{Namespace: "demo", Service: "api", Port: 6443, NodePort: 31443},
+		{Namespace: "other", Service: "web", Port: 8080, NodePort: 32080},
+		{Namespace: "demo", Service: "db", Port: 5432, NodePort: 31432},
+	})
+	assert.Equal(t, map[string]string{
+		":8080": "192.168.127.2:31080",
+		":5432": "192.168.127.2:31432",
+	}, forwarder.exposed)
+
+	// node port changed, db was deleted, and the forwarder cannot listen on port 81
+	lb.sync([]cluster.LoadBalancerPort{
+		{Namespace: "demo", Service: "web", Port: 8080, NodePort: 31081},
+		{Namespace: "demo", Service: "web81", Port: 81, NodePort: 31082},
+	})
+	assert.Equal(t, map[string]string{
+		":8080": "192.168.127.2:31081",
+	}, forwarder.exposed)
+	assert.True(t, lb.failed[":81"])
+
+	lb.sync(nil)
+	assert.Empty(t, forwarder.exposed)
+	assert.Empty(t, lb.forwards)
+}
@@ -5,17 +5,23 @@ import (
 	"fmt"
 
 	"github.com/code-ready/crc/pkg/crc/cluster"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/crc/machine/profile"
+	"github.com/code-ready/crc/pkg/crc/machine/store"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
 	"github.com/code-ready/crc/pkg/libmachine"
 	"github.com/code-ready/crc/pkg/libmachine/host"
 	"github.com/code-ready/machine/libmachine/drivers"
 )
 
-func getClusterConfig(bundleInfo *bundle.CrcBundleInfo) (*types.ClusterConfig, error) {
-	kubeadminPassword, err := cluster.GetKubeadminPassword()
+func getClusterConfig(instanceProfile profile.Profile, bundleInfo *bundle.CrcBundleInfo) (*types.ClusterConfig, error) {
+	kubeadminPassword, err := kubeAdminPasswordStore(instanceProfile).Get()
 	if err != nil {
 		return nil, fmt.Errorf("Error reading kubeadmin password from bundle %v", err)
 	}
@@ -27,16 +33,87 @@ func getClusterConfig(bundleInfo *bundle.CrcBundleInfo) (*types.ClusterConfig, e
 	if err != nil {
 		return nil, err
 	}
+	clusterAPI, err := apiServerURL(bundleInfo.GetKubeConfigPath())
+	if err != nil || clusterAPI == "" {
+		logging.Debugf("Cannot get API server URL from the bundle kubeconfig: %v", err)
+		clusterAPI = fmt.Sprintf("https://%s:6443", bundleInfo.GetAPIHostname())
+	}
+	if clusterAPI, err = instanceAPIURL(instanceProfile, clusterAPI); err != nil {
+		return nil, err
+	}
 	return &types.ClusterConfig{
 		ClusterCACert: base64.StdEncoding.EncodeToString(clusterCACert),
 		KubeConfig:    bundleInfo.GetKubeConfigPath(),
 		KubeAdminPass: kubeadminPassword,
 		WebConsoleURL: fmt.Sprintf("https://%s", bundleInfo.GetAppHostname("console-openshift-console")),
-		ClusterAPI:    fmt.Sprintf("https://%s:6443", bundleInfo.GetAPIHostname()),
+		ClusterAPI:    clusterAPI,
 		ProxyConfig:   proxyConfig,
 	}, nil
 }
 
+// instanceAPIURL returns the URL of the API server of the cluster at
+// clusterAPI as reached from the host, the instances other than the default
+// one use the API hostname in their own domain
+func instanceAPIURL(instanceProfile profile.Profile, clusterAPI string) (string, error) {
+	server, _, err := kubeconfigServer(instanceProfile.Name, clusterAPI, true)
+	return server, err
+}
+
+// updateClusterConfigFromCluster replaces the URLs guessed from the bundle
+// metadata with the ones actually used by the running cluster, and keeps them
+// in the store of the instance for the next commands
+func (client *client) updateClusterConfigFromCluster(clusterConfig *types.ClusterConfig, ocConfig oc.Config) {
+	consoleURL, err := cluster.GetConsoleURL(ocConfig)
+	if err != nil {
+		logging.Debugf("Cannot get the console URL from the cluster: %v", err)
+		return
+	}
+	apiServerURL, err := cluster.GetAPIServerURL(ocConfig)
+	if err != nil {
+		logging.Debugf("Cannot get the API server URL from the cluster: %v", err)
+		return
+	}
+	clusterAPI, err := instanceAPIURL(client.profile(), apiServerURL)
+	if err != nil {
+		logging.Debugf("Cannot parse the API server URL %s: %v", apiServerURL, err)
+		return
+	}
+	clusterConfig.WebConsoleURL = consoleURL
+	clusterConfig.ClusterAPI = clusterAPI
+	if err := store.ForInstance(client.name).SetClusterURLs(store.ClusterURLs{
+		WebConsole: consoleURL,
+		API:        clusterAPI,
+	}); err != nil {
+		logging.Debugf("Cannot store the cluster URLs: %v", err)
+	}
+}
+
+// updateClusterConfigFromRunningVM uses the URLs of the cluster stored during
+// the last start, the cluster is only queried when they are not stored
+func (client *client) updateClusterConfigFromRunningVM(host *host.Host, bundleInfo *bundle.CrcBundleInfo, clusterConfig *types.ClusterConfig) {
+	urls, err := store.ForInstance(client.name).ClusterURLs()
+	if err != nil {
+		logging.Debugf("Cannot read the stored cluster URLs: %v", err)
+	}
+	if urls != nil {
+		clusterConfig.WebConsoleURL = urls.WebConsole
+		clusterConfig.ClusterAPI = urls.API
+		return
+	}
+	ip, err := getIP(host, client.useVSock())
+	if err != nil {
+		logging.Debugf("Cannot get VM IP: %v", err)
+		return
+	}
+	sshRunner, err := client.createSSHRunner(ip, getSSHPort(client.useVSock()), bundleInfo, client.profile().PrivateKeyPath(), client.profile().RsaPrivateKeyPath(), bundleInfo.GetSSHKeyPath())
+	if err != nil {
+		logging.Debugf("Cannot create the ssh client: %v", err)
+		return
+	}
+	defer sshRunner.Close()
+	client.updateClusterConfigFromCluster(clusterConfig, oc.UseOCWithSSH(sshRunner))
+}
+
 func getBundleMetadataFromDriver(driver drivers.Driver) (*bundle.CrcBundleInfo, error) {
 	bundleName, err := driver.GetBundleName()
 	if err != nil {
@@ -65,6 +142,10 @@ func getSSHPort(vsockNetwork bool) int {
 	return constants.DefaultSSHPort
 }
 
+func (client *client) createSSHRunner(ip string, port int, bundleInfo *bundle.CrcBundleInfo, privateKeys ...string) (*crcssh.Runner, error) {
+	return crcssh.CreateRunnerForUser(crcConfig.GetSSHBackend(client.config), bundleInfo.GetSSHUser(), crcssh.PrivilegeEscalation(bundleInfo.GetPrivilegeEscalation()), ip, port, privateKeys...)
+}
+
 func getIP(h *host.Host, vsockNetwork bool) (string, error) {
 	if vsockNetwork {
 		return "127.0.0.1", nil
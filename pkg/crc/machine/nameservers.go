@@ -0,0 +1,85 @@
+package machine
+
+import (
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/store"
+	"github.com/code-ready/crc/pkg/crc/network"
+	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/pkg/errors"
+)
+
+// upstreamNameServers returns the nameservers the VM must use: the one given
+// with the nameserver setting first, then the upstream servers configured for
+// the network mode
+func upstreamNameServers(nameServer string, servers []network.UpstreamServer, mode network.Mode) []network.NameServer {
+	if nameServer != "" {
+		servers = append([]network.UpstreamServer{{NameServer: network.NameServer{IPAddress: nameServer}}}, servers...)
+	}
+	return network.UpstreamNameServersForMode(servers, mode)
+}
+
+// staleNameServers returns the nameservers of previous which are not wanted anymore
+func staleNameServers(previous []string, wanted []network.NameServer) []network.NameServer {
+	var stale []network.NameServer
+	for _, address := range previous {
+		if !containsNameServer(wanted, address) {
+			stale = append(stale, network.NameServer{IPAddress: address})
+		}
+	}
+	return stale
+}
+
+func containsNameServer(nameServers []network.NameServer, address string) bool {
+	for _, ns := range nameServers {
+		if ns.IPAddress == address {
+			return true
+		}
+	}
+	return false
+}
+
+// reconcileNameServers makes the VM use the configured upstream nameservers.
+// The nameservers added by a previous start which are not configured anymore
+// are removed, the other nameservers of the VM are kept.
+func (client *client) reconcileNameServers(sshRunner *crcssh.Runner, nameServer string) error {
+	servers, err := client.dnsUpstreamServers()
+	if err != nil {
+		return errors.Wrap(err, "Invalid upstream DNS servers")
+	}
+	wanted := upstreamNameServers(nameServer, servers, client.networkMode())
+
+	instanceStore := store.ForInstance(client.name)
+	previous, err := instanceStore.UpstreamNameServers()
+	if err != nil {
+		logging.Debugf("Cannot read the nameservers added during the last start: %v", err)
+	}
+	if stale := staleNameServers(previous, wanted); len(stale) > 0 {
+		for _, ns := range stale {
+			logging.Infof("Removing %s from the nameservers of the instance...", ns.IPAddress)
+		}
+		if err := network.RemoveNameserversFromInstance(sshRunner, stale); err != nil {
+			return err
+		}
+	}
+
+	resolvValues, err := network.GetResolvValuesFromInstance(sshRunner)
+	if err != nil {
+		return err
+	}
+	var missing []network.NameServer
+	for _, ns := range wanted {
+		if !containsNameServer(resolvValues.NameServers, ns.IPAddress) {
+			logging.Infof("Adding %s as nameserver to the instance...", ns.IPAddress)
+			missing = append(missing, ns)
+		}
+	}
+	if err := network.AddNameserversToInstance(sshRunner, missing); err != nil {
+		return err
+	}
+
+	var applied []string
+	for _, ns := range wanted {
+		applied = append(applied, ns.IPAddress)
+	}
+	return instanceStore.SetUpstreamNameServers(applied)
+}
@@ -0,0 +1,28 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpstreamNameServers(t *testing.T) {
+	servers, err := network.ParseUpstreamServers("1.1.1.1,user:8.8.8.8,system:10.0.0.53")
+	assert.NoError(t, err)
+
+	assert.Equal(t, []network.NameServer{{IPAddress: "1.1.1.1"}, {IPAddress: "10.0.0.53"}},
+		upstreamNameServers("", servers, network.SystemNetworkingMode))
+	assert.Equal(t, []network.NameServer{{IPAddress: "9.9.9.9"}, {IPAddress: "1.1.1.1"}, {IPAddress: "8.8.8.8"}},
+		upstreamNameServers("9.9.9.9", servers, network.UserNetworkingMode))
+	assert.Equal(t, []network.NameServer{{IPAddress: "1.1.1.1"}, {IPAddress: "8.8.8.8"}},
+		upstreamNameServers("1.1.1.1", servers, network.UserNetworkingMode))
+	assert.Empty(t, upstreamNameServers("", nil, network.SystemNetworkingMode))
+}
+
+func TestStaleNameServers(t *testing.T) {
+	wanted := []network.NameServer{{IPAddress: "1.1.1.1"}, {IPAddress: "8.8.8.8"}}
+	assert.Equal(t, []network.NameServer{{IPAddress: "10.0.0.53"}}, staleNameServers([]string{"1.1.1.1", "10.0.0.53"}, wanted))
+	assert.Empty(t, staleNameServers([]string{"8.8.8.8"}, wanted))
+	assert.Empty(t, staleNameServers(nil, wanted))
+}
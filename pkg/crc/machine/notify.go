@@ -0,0 +1,129 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/store"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/notify"
+	"github.com/docker/go-units"
+)
+
+// the disk of the VM is under pressure when it is fuller than this percentage
+const diskPressureThreshold = 90
+
+// the notifications waiting to be sent, the next ones are dropped
+const notificationQueueSize = 16
+
+// notifierClient notifies the users of the lifecycle events of the
+// instance, as configured by the notify-* settings. The status is polled by
+// the daemon and the tray, the warnings are only sent once until their
+// cause goes away, also across commands. The notifications are sent in the
+// background, a slow webhook does not delay the operations.
+type notifierClient struct {
+	Client
+	name        string
+	newNotifier func() notify.Notifier
+	state       *store.Store
+
+	startSender sync.Once
+	queue       chan notify.Event
+	sending     sync.WaitGroup
+}
+
+func newNotifierClient(underlying Client, name string, config crcConfig.Storage) *notifierClient {
+	return &notifierClient{
+		Client: underlying,
+		name:   name,
+		newNotifier: func() notify.Notifier {
+			return notify.New(config.Get(crcConfig.NotifyDesktop).AsBool(), config.Get(crcConfig.NotifyWebhookURL).AsString())
+		},
+		state: store.ForInstance(name),
+	}
+}
+
+func (client *notifierClient) notify(eventType notify.EventType, message string) {
+	event := notify.Event{
+		Type:     eventType,
+		Instance: client.name,
+		Message:  message,
+		Time:     time.Now(),
+	}
+	notify.Events.Publish(event)
+
+	client.startSender.Do(func() {
+		client.queue = make(chan notify.Event, notificationQueueSize)
+		go client.send()
+	})
+	client.sending.Add(1)
+	select {
+	case client.queue <- event:
+	default:
+		client.sending.Done()
+		logging.Warnf("Too many pending notifications, the %s notification is dropped", eventType)
+	}
+}
+
+// send sends the queued notifications in order
+func (client *notifierClient) send() {
+	for event := range client.queue {
+		if err := client.newNotifier().Notify(event); err != nil {
+			logging.Warnf("Failed to send the %s notification: %v", event.Type, err)
+		}
+		client.sending.Done()
+	}
+}
+
+// warn sends the warning when active becomes true
+func (client *notifierClient) warn(eventType notify.EventType, active bool, message string) {
+	var alreadyWarned bool
+	if err := client.state.UpdateActiveWarnings(func(warnings map[string]bool) {
+		alreadyWarned = warnings[string(eventType)]
+		if active {
+			warnings[string(eventType)] = true
+		} else {
+			delete(warnings, string(eventType))
+		}
+	}); err != nil {
+		logging.Debugf("Cannot update the active warnings: %v", err)
+	}
+
+	if active && !alreadyWarned {
+		client.notify(eventType, message)
+	}
+}
+
+func (client *notifierClient) Start(ctx context.Context, startConfig types.StartConfig) (*types.StartResult, error) {
+	result, err := client.Client.Start(ctx, startConfig)
+	if err != nil {
+		client.notify(notify.StartFailed, fmt.Sprintf("The OpenShift cluster failed to start: %v", err))
+	} else {
+		client.notify(notify.StartSucceeded, "The OpenShift cluster is running")
+	}
+	return result, err
+}
+
+func (client *notifierClient) Status() (*types.ClusterStatusResult, error) {
+	status, err := client.Client.Status()
+	if err != nil {
+		return status, err
+	}
+	client.warn(notify.CertsExpiring, status.CertsRenewalNeeded,
+		fmt.Sprintf("The cluster certificates expire on %s, they are renewed during the next start", status.CertsExpiry.Format(time.RFC1123)))
+	diskPressure := percentage(status.DiskUse, status.DiskSize) >= diskPressureThreshold
+	client.warn(notify.DiskPressure, diskPressure,
+		fmt.Sprintf("The disk of the cluster is %d%% full (%s of %s)", percentage(status.DiskUse, status.DiskSize), units.HumanSize(float64(status.DiskUse)), units.HumanSize(float64(status.DiskSize))))
+	return status, nil
+}
+
+func percentage(value, total int64) int64 {
+	if total == 0 {
+		return 0
+	}
+	return value * 100 / total
+}
@@ -0,0 +1,87 @@
+package machine
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/fakemachine"
+	"github.com/code-ready/crc/pkg/crc/machine/store"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/notify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingNotifier struct {
+	mu     sync.Mutex
+	events []notify.Event
+}
+
+func (notifier *recordingNotifier) Notify(event notify.Event) error {
+	notifier.mu.Lock()
+	defer notifier.mu.Unlock()
+	notifier.events = append(notifier.events, event)
+	return nil
+}
+
+type fullDiskClient struct {
+	*fakemachine.Client
+	diskUse int64
+}
+
+func (client *fullDiskClient) Status() (*types.ClusterStatusResult, error) {
+	status, err := client.Client.Status()
+	if err != nil {
+		return nil, err
+	}
+	status.DiskUse = client.diskUse
+	return status, nil
+}
+
+func TestNotifierClient(t *testing.T) {
+	notifier := &recordingNotifier{}
+	underlying := &fullDiskClient{Client: fakemachine.NewClient(), diskUse: 19_000_000_000}
+	state := store.New(filepath.Join(t.TempDir(), "crc-state.json"))
+	newClient := func() *notifierClient {
+		return &notifierClient{
+			Client:      underlying,
+			name:        "crc",
+			newNotifier: func() notify.Notifier { return notifier },
+			state:       state,
+		}
+	}
+	client := newClient()
+
+	_, err := client.Start(context.Background(), types.StartConfig{})
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = client.Status()
+		require.NoError(t, err)
+	}
+	// the next commands remember the warnings
+	_, err = newClient().Status()
+	require.NoError(t, err)
+	underlying.diskUse = 1_000_000_000
+	_, err = client.Status()
+	require.NoError(t, err)
+	underlying.diskUse = 19_000_000_000
+	_, err = client.Status()
+	require.NoError(t, err)
+	client.sending.Wait()
+
+	var eventTypes []notify.EventType
+	for _, event := range notifier.events {
+		assert.Equal(t, "crc", event.Instance)
+		eventTypes = append(eventTypes, event.Type)
+	}
+	assert.Equal(t, []notify.EventType{notify.StartSucceeded, notify.DiskPressure, notify.DiskPressure}, eventTypes)
+	assert.Equal(t, "The disk of the cluster is 95% full (19GB of 20GB)", notifier.events[1].Message)
+
+	client.Client = fakemachine.NewFailingClient()
+	_, err = client.Start(context.Background(), types.StartConfig{})
+	assert.Error(t, err)
+	client.sending.Wait()
+	assert.Equal(t, notify.StartFailed, notifier.events[3].Type)
+}
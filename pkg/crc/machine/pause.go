@@ -0,0 +1,88 @@
+package machine
+
+import (
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/machine/libmachine/state"
+	"github.com/pkg/errors"
+)
+
+// Pause suspends the VM in memory, it then uses no CPU and Resume brings it
+// back without going through the start sequence
+func (client *client) Pause(_ types.PauseConfig) error {
+	vmState, err := client.vmState()
+	if err != nil {
+		return err
+	}
+	if vmState != state.Running {
+		return errors.New("CodeReady Containers VM is not running")
+	}
+
+	logging.Info("Pausing the CodeReady Containers VM...")
+	if err := pauseVM(client.name); err != nil {
+		return err
+	}
+	client.stopHostServices()
+	return nil
+}
+
+// Resume resumes the VM suspended by Pause, then starts the cluster if it was
+// stopped by Hibernate
+func (client *client) Resume(resumeConfig types.ResumeConfig) error {
+	vmState, err := client.vmState()
+	if err != nil {
+		return err
+	}
+	switch vmState {
+	case state.Paused:
+		logging.Info("Resuming the CodeReady Containers VM...")
+		if err := resumeVM(client.name); err != nil {
+			return err
+		}
+		if resumeConfig.SyncClock {
+			if err := client.syncClock(); err != nil {
+				logging.Warnf("Failed to set the clock of the VM: %v", err)
+			}
+		}
+		client.startHostServices()
+		if !client.isHibernated() {
+			return nil
+		}
+	case state.Running:
+	default:
+		return errors.New("CodeReady Containers VM is not running")
+	}
+	return client.resumeCluster()
+}
+
+func (client *client) vmState() (state.State, error) {
+	libMachineAPIClient, cleanup := createLibMachineClient()
+	defer cleanup()
+	host, err := libMachineAPIClient.Load(client.name)
+	if err != nil {
+		return state.Error, errors.Wrap(err, "Cannot load machine")
+	}
+	vmState, err := host.Driver.GetState()
+	if err != nil {
+		return state.Error, errors.Wrap(err, "Cannot get machine state")
+	}
+	return vmState, nil
+}
+
+// syncClock sets the clock of the VM to the one of the host, shifted by the
+// offset set by ShiftClock
+func (client *client) syncClock() error {
+	_, sshRunner, err := loadVM(client)
+	if err != nil {
+		return err
+	}
+	defer sshRunner.Close()
+
+	dateCmd := fmt.Sprintf("date -s @%d", client.now().Unix())
+	if _, stderr, err := sshRunner.RunPrivileged("Setting clock same as host", dateCmd); err != nil {
+		return fmt.Errorf("%v: %s", err, stderr)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/daemonclient"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/profile"
+	"github.com/code-ready/crc/pkg/crc/network/forward"
+	crcos "github.com/code-ready/crc/pkg/os"
+	"github.com/containers/gvisor-tap-vsock/pkg/types"
+)
+
+const portForwardsPollingInterval = 5 * time.Second
+
+// AddPortForward declares a forward of a host port to a port of the VM. It
+// is active while the VM is running, the forwards are applied by the daemon.
+func (client *client) AddPortForward(portForward forward.Forward) error {
+	if err := portForward.Validate(); err != nil {
+		return err
+	}
+	if client.useVSock() && isReservedPort(":"+portForward.LocalPort()) {
+		return fmt.Errorf("port %s cannot be forwarded, crc already uses it", portForward.LocalPort())
+	}
+	if err := os.MkdirAll(client.profile().MachineDir(), 0750); err != nil {
+		return err
+	}
+	return forward.Add(client.profile().PortForwardsPath(), portForward)
+}
+
+// RemovePortForward removes the forward of the host address or the host
+// port local
+func (client *client) RemovePortForward(local string) error {
+	return forward.Remove(client.profile().PortForwardsPath(), local)
+}
+
+func (client *client) ListPortForwards() ([]forward.Forward, error) {
+	return forward.Load(client.profile().PortForwardsPath())
+}
+
+// WatchPortForwards applies the port forwards of the instances until ctx is
+// cancelled. The forwards of an instance are only active while its VM is
+// running, they are removed when it is stopped or deleted.
+func WatchPortForwards(ctx context.Context, config crcConfig.Storage) {
+	manager := newPortForwardManager(daemonclient.New().NetworkClient)
+	defer manager.sync(nil, nil)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(portForwardsPollingInterval):
+		}
+		var userForwards []forward.Forward
+		systemForwards := map[forward.Forward]string{}
+		for _, name := range instancesWithPortForwards() {
+			client := &client{name: name, config: config}
+			forwards, ip := client.activePortForwards()
+			if client.useVSock() {
+				userForwards = append(userForwards, forwards...)
+				continue
+			}
+			for _, portForward := range forwards {
+				systemForwards[portForward] = ip
+			}
+		}
+		manager.sync(userForwards, systemForwards)
+	}
+}
+
+// instancesWithPortForwards returns the names of the instances which
+// declared port forwards
+func instancesWithPortForwards() []string {
+	dirs, err := ioutil.ReadDir(constants.MachineInstanceDir)
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, dir := range dirs {
+		if dir.IsDir() && crcos.FileExists(profile.Profile{Name: dir.Name()}.PortForwardsPath()) {
+			names = append(names, dir.Name())
+		}
+	}
+	return names
+}
+
+// activePortForwards returns the forwards of the instance and the IP of its
+// VM, there are none when it is not running
+func (client *client) activePortForwards() ([]forward.Forward, string) {
+	forwards, err := client.ListPortForwards()
+	if err != nil {
+		logging.Debugf("Cannot read the port forwards of %s: %v", client.name, err)
+		return nil, ""
+	}
+	if len(forwards) == 0 {
+		return nil, ""
+	}
+	if running, err := client.IsRunning(); err != nil || !running {
+		return nil, ""
+	}
+	connectionDetails, err := client.ConnectionDetails()
+	if err != nil {
+		logging.Debugf("Cannot get the IP of %s: %v", client.name, err)
+		return nil, ""
+	}
+	return forwards, connectionDetails.IP
+}
+
+// portForwardManager applies the port forwards, through the virtual network
+// of the daemon with user mode networking, or with TCP proxies to the IP of
+// the VMs otherwise
+type portForwardManager struct {
+	forwarder portForwarder
+	proxies   *forward.Proxies
+	// forwards exposed by the virtual network, by host address
+	exposed map[string]types.ExposeRequest
+	// host addresses which could not be forwarded, reported only once
+	failed map[string]bool
+}
+
+func newPortForwardManager(forwarder portForwarder) *portForwardManager {
+	return &portForwardManager{
+		forwarder: forwarder,
+		proxies:   forward.NewProxies(),
+		exposed:   map[string]types.ExposeRequest{},
+		failed:    map[string]bool{},
+	}
+}
+
+// sync applies userForwards with the virtual network, and systemForwards
+// with TCP proxies to the IP of their VM
+func (m *portForwardManager) sync(userForwards []forward.Forward, systemForwards map[forward.Forward]string) {
+	wanted := map[string]types.ExposeRequest{}
+	for _, portForward := range userForwards {
+		wanted[portForward.Local] = types.ExposeRequest{Local: portForward.Local, Remote: fmt.Sprintf("%s:%d", virtualMachineIP, portForward.Remote)}
+	}
+	for local, exposed := range m.exposed {
+		if wanted[local] == exposed {
+			continue
+		}
+		if err := m.forwarder.Unexpose(&types.UnexposeRequest{Local: local}); err != nil {
+			logging.Debugf("Failed to stop forwarding %s: %v", local, err)
+		}
+		logging.Infof("Stopped forwarding %s to %s", local, exposed.Remote)
+		delete(m.exposed, local)
+	}
+	active := map[string]bool{}
+	for local, req := range wanted {
+		active[local] = true
+		if _, ok := m.exposed[local]; ok {
+			continue
+		}
+		req := req
+		if err := m.forwarder.Expose(&req); err != nil {
+			m.reportFailure(local, "Cannot forward %s to %s: %v", local, req.Remote, err)
+			continue
+		}
+		logging.Infof("Forwarding %s to %s", local, req.Remote)
+		m.exposed[local] = req
+		delete(m.failed, local)
+	}
+
+	for portForward := range systemForwards {
+		active[portForward.Local] = true
+	}
+	for local, err := range m.proxies.Sync(systemForwards) {
+		m.reportFailure(local, "Cannot forward %s to the VM: %v", local, err)
+	}
+	for local := range m.failed {
+		if !active[local] {
+			delete(m.failed, local)
+		}
+	}
+}
+
+func (m *portForwardManager) reportFailure(local string, format string, args ...interface{}) {
+	if m.failed[local] {
+		return
+	}
+	m.failed[local] = true
+	logging.Warnf(format, args...)
+}
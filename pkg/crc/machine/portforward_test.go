@@ -0,0 +1,39 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/network/forward"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPortForwardManager(t *testing.T) {
+	forwarder := &fakePortForwarder{
+		exposed: map[string]string{},
+		failing: map[string]bool{"127.0.0.1:80": true},
+	}
+	manager := newPortForwardManager(forwarder)
+	defer manager.sync(nil, nil)
+
+	manager.sync([]forward.Forward{
+		{Local: "127.0.0.1:8080", Remote: 30080},
+		{Local: "127.0.0.1:80", Remote: 30081},
+	}, nil)
+	assert.Equal(t, map[string]string{
+		"127.0.0.1:8080": "192.168.127.2:30080",
+	}, forwarder.exposed)
+	assert.True(t, manager.failed["127.0.0.1:80"])
+
+	// the VM port changed and the failing forward was removed
+	manager.sync([]forward.Forward{
+		{Local: "127.0.0.1:8080", Remote: 30082},
+	}, nil)
+	assert.Equal(t, map[string]string{
+		"127.0.0.1:8080": "192.168.127.2:30082",
+	}, forwarder.exposed)
+	assert.Empty(t, manager.failed)
+
+	manager.sync(nil, nil)
+	assert.Empty(t, forwarder.exposed)
+	assert.Empty(t, manager.exposed)
+}
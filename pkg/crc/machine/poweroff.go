@@ -1,18 +1,35 @@
 package machine
 
-import "github.com/pkg/errors"
+import (
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	libmachinestate "github.com/code-ready/machine/libmachine/state"
+	"github.com/pkg/errors"
+)
 
-func (client *client) PowerOff() error {
+// PowerOff kills the VM, it does nothing when the VM is already stopped
+func (client *client) PowerOff(powerOffConfig types.PowerOffConfig) (*types.StopResult, error) {
 	libMachineAPIClient, cleanup := createLibMachineClient()
 	defer cleanup()
 
 	host, err := libMachineAPIClient.Load(client.name)
 	if err != nil {
-		return errors.Wrap(err, "Cannot load machine")
+		return &types.StopResult{State: state.Error}, errors.Wrap(err, "Cannot load machine")
+	}
+	vmState, err := host.Driver.GetState()
+	if err != nil {
+		return &types.StopResult{State: state.Error}, errors.Wrap(err, "Cannot get VM status")
+	}
+	if vmState == libmachinestate.Stopped {
+		return &types.StopResult{State: state.Stopped, AlreadyStopped: true}, nil
 	}
 
+	if err := client.checkNotProtected(powerOffConfig.ProtectionToken); err != nil {
+		return &types.StopResult{State: state.FromMachine(vmState)}, err
+	}
+	client.stopHostServices()
 	if err := host.Kill(); err != nil {
-		return errors.Wrap(err, "Cannot kill machine")
+		return &types.StopResult{State: state.FromMachine(vmState)}, errors.Wrap(err, "Cannot kill machine")
 	}
-	return nil
+	return &types.StopResult{State: state.Stopped}, nil
 }
@@ -0,0 +1,135 @@
+// Package profile gives the per-instance paths and domains of the named
+// instances, so that several clusters can be created side by side. The
+// default profile keeps the paths and domains used before profiles existed.
+package profile
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+)
+
+// the profile name is used in the cluster domain, it must be a DNS label
+var nameRegexp = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+type Profile struct {
+	Name string
+}
+
+// Default is the profile used when no profile is given
+func Default() Profile {
+	return Profile{Name: constants.DefaultName}
+}
+
+// ValidateName checks that name can be used as a profile name
+func ValidateName(name string) error {
+	if !nameRegexp.MatchString(name) {
+		return fmt.Errorf("'%s' is not a valid profile name, it must only contain lowercase letters, digits and '-'", name)
+	}
+	return nil
+}
+
+// New returns the profile called name, the default one when name is empty
+func New(name string) (Profile, error) {
+	if name == "" {
+		return Default(), nil
+	}
+	if err := ValidateName(name); err != nil {
+		return Profile{}, err
+	}
+	return Profile{Name: name}, nil
+}
+
+func (p Profile) String() string {
+	return p.Name
+}
+
+func (p Profile) IsDefault() bool {
+	return p.Name == "" || p.Name == constants.DefaultName
+}
+
+// MachineDir is the directory of the VM and of the files of the instance
+func (p Profile) MachineDir() string {
+	return filepath.Join(constants.MachineInstanceDir, p.Name)
+}
+
+func (p Profile) PrivateKeyPath() string {
+	return filepath.Join(p.MachineDir(), "id_ecdsa")
+}
+
+func (p Profile) PublicKeyPath() string {
+	return filepath.Join(p.MachineDir(), "id_ecdsa.pub")
+}
+
+// RsaPrivateKeyPath is the key of the instances created before v1.20.0
+func (p Profile) RsaPrivateKeyPath() string {
+	return filepath.Join(p.MachineDir(), "id_rsa")
+}
+
+// KubeconfigPath is the admin kubeconfig of the cluster
+func (p Profile) KubeconfigPath() string {
+	return filepath.Join(p.MachineDir(), "kubeconfig")
+}
+
+// PortForwardsPath stores the host ports forwarded to the VM
+func (p Profile) PortForwardsPath() string {
+	return filepath.Join(p.MachineDir(), "port-forwards.json")
+}
+
+// KubeAdminPasswordPath keeps the kubeadmin password when the keyring of the
+// OS is not available
+func (p Profile) KubeAdminPasswordPath() string {
+	return filepath.Join(p.MachineDir(), "kubeadmin-password")
+}
+
+// KubeAdminPasswordKeyringKey is the key of the kubeadmin password in the
+// keyring of the OS
+func (p Profile) KubeAdminPasswordKeyringKey() string {
+	return fmt.Sprintf("kubeadmin-password-%s", p.Name)
+}
+
+// DiskPassphraseKeyringKey is the key of the passphrase of the encrypted disk
+// of the VM in the keyring of the OS
+func (p Profile) DiskPassphraseKeyringKey() string {
+	return fmt.Sprintf("disk-passphrase-%s", p.Name)
+}
+
+// CACertPath is the CA signing the serving certificates of the domains of a
+// named profile
+func (p Profile) CACertPath() string {
+	return filepath.Join(p.MachineDir(), "ca.crt")
+}
+
+func (p Profile) CAKeyPath() string {
+	return filepath.Join(p.MachineDir(), "ca.key")
+}
+
+// ClusterDomain is the base domain of the cluster, e.g. .crc.testing or
+// .<name>.crc.testing
+func (p Profile) ClusterDomain() string {
+	return constants.GetClusterDomain(p.Name)
+}
+
+// AppsDomain is the domain of the routes of the cluster, e.g.
+// .apps-crc.testing or .apps.<name>.crc.testing
+func (p Profile) AppsDomain() string {
+	return constants.GetAppsDomain(p.Name)
+}
+
+// HostDomain is the domain through which the cluster of a named profile is
+// reached from the host, such as test411.crc.testing. It is empty for the
+// default profile, which uses the domains of the bundle.
+func (p Profile) HostDomain() string {
+	if p.IsDefault() {
+		return ""
+	}
+	return strings.TrimPrefix(p.ClusterDomain(), ".")
+}
+
+// APIHostname is the hostname of the API server in the domain of the profile
+func (p Profile) APIHostname() string {
+	return fmt.Sprintf("api%s", p.ClusterDomain())
+}
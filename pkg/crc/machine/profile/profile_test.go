@@ -0,0 +1,58 @@
+package profile
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateName(t *testing.T) {
+	assert.NoError(t, ValidateName("crc"))
+	assert.NoError(t, ValidateName("team-a-2"))
+	assert.Error(t, ValidateName(""))
+	assert.Error(t, ValidateName("Team"))
+	assert.Error(t, ValidateName("-team"))
+	assert.Error(t, ValidateName("team.a"))
+	assert.Error(t, ValidateName("../crc"))
+}
+
+func TestNew(t *testing.T) {
+	p, err := New("")
+	require.NoError(t, err)
+	assert.True(t, p.IsDefault())
+
+	p, err = New("test411")
+	require.NoError(t, err)
+	assert.False(t, p.IsDefault())
+
+	_, err = New("Test411")
+	assert.Error(t, err)
+}
+
+func TestPaths(t *testing.T) {
+	assert.Equal(t, constants.GetPrivateKeyPath(), Default().PrivateKeyPath())
+	assert.Equal(t, constants.GetPublicKeyPath(), Default().PublicKeyPath())
+	assert.Equal(t, constants.GetRsaPrivateKeyPath(), Default().RsaPrivateKeyPath())
+	assert.Equal(t, constants.KubeconfigFilePath, Default().KubeconfigPath())
+	assert.Equal(t, constants.GetKubeAdminPasswordPath(), Default().KubeAdminPasswordPath())
+
+	dev := Profile{Name: "dev"}
+	assert.Equal(t, filepath.Join(constants.MachineInstanceDir, "dev", "id_ecdsa"), dev.PrivateKeyPath())
+	assert.Equal(t, filepath.Join(constants.MachineInstanceDir, "dev", "kubeconfig"), dev.KubeconfigPath())
+	assert.Equal(t, "kubeadmin-password-dev", dev.KubeAdminPasswordKeyringKey())
+	assert.Equal(t, "disk-passphrase-dev", dev.DiskPassphraseKeyringKey())
+}
+
+func TestDomains(t *testing.T) {
+	assert.Equal(t, ".crc.testing", Default().ClusterDomain())
+	assert.Equal(t, "", Default().HostDomain())
+	assert.Equal(t, "api.crc.testing", Default().APIHostname())
+
+	dev := Profile{Name: "dev"}
+	assert.Equal(t, ".apps.dev.crc.testing", dev.AppsDomain())
+	assert.Equal(t, "dev.crc.testing", dev.HostDomain())
+	assert.Equal(t, "api.dev.crc.testing", dev.APIHostname())
+}
@@ -0,0 +1,58 @@
+package machine
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/code-ready/crc/pkg/crc/machine/profile"
+	crctls "github.com/code-ready/crc/pkg/crc/tls"
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+// profileCA returns the CA signing the serving certificates of the domains of
+// the named profile, it is created with the first start of the instance and
+// deleted with it
+func profileCA(instanceProfile profile.Profile) (*rsa.PrivateKey, *x509.Certificate, error) {
+	if crcos.FileExists(instanceProfile.CACertPath()) && crcos.FileExists(instanceProfile.CAKeyPath()) {
+		return readProfileCA(instanceProfile)
+	}
+	key, cert, err := crctls.GenerateSelfSignedCertificate(&crctls.CertCfg{
+		Subject:   pkix.Name{CommonName: fmt.Sprintf("crc-%s-ca", instanceProfile.Name), OrganizationalUnit: []string{"crc"}},
+		KeyUsages: x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		Validity:  crctls.ValidityTenYears,
+		IsCA:      true,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ioutil.WriteFile(instanceProfile.CAKeyPath(), crctls.PrivateKeyToPem(key), 0600); err != nil {
+		return nil, nil, err
+	}
+	if err := ioutil.WriteFile(instanceProfile.CACertPath(), crctls.CertToPem(cert), 0644); err != nil {
+		return nil, nil, err
+	}
+	return key, cert, nil
+}
+
+func readProfileCA(instanceProfile profile.Profile) (*rsa.PrivateKey, *x509.Certificate, error) {
+	keyPem, err := ioutil.ReadFile(instanceProfile.CAKeyPath())
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := crctls.PemToPrivateKey(keyPem)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid CA key %s: %v", instanceProfile.CAKeyPath(), err)
+	}
+	certPem, err := ioutil.ReadFile(instanceProfile.CACertPath())
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := crctls.PemToCert(certPem)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid CA certificate %s: %v", instanceProfile.CACertPath(), err)
+	}
+	return key, cert, nil
+}
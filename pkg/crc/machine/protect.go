@@ -0,0 +1,69 @@
+package machine
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/machine/store"
+	"github.com/pkg/errors"
+)
+
+// Protect prevents the instance from being deleted or powered off until
+// Unprotect is called with the returned token
+func (client *client) Protect() (string, error) {
+	exists, err := client.Exists()
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", errors.New("machine does not exist")
+	}
+	instanceStore := store.ForInstance(client.name)
+	token, err := instanceStore.ProtectionToken()
+	if err != nil {
+		return "", err
+	}
+	if token != "" {
+		return token, nil
+	}
+
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.Wrap(err, "Cannot generate protection token")
+	}
+	token = hex.EncodeToString(buf)
+	if err := instanceStore.SetProtectionToken(token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// Unprotect removes the protection of the instance, token must be the one
+// returned by Protect
+func (client *client) Unprotect(token string) error {
+	if err := client.checkNotProtected(token); err != nil {
+		return err
+	}
+	return store.ForInstance(client.name).SetProtectionToken("")
+}
+
+// checkNotProtected fails when the instance is protected and token is not
+// the protection token, the protection itself is left untouched so that a
+// token only allows the operation it is given to
+func (client *client) checkNotProtected(token string) error {
+	expected, err := store.ForInstance(client.name).ProtectionToken()
+	if err != nil {
+		return err
+	}
+	if expected == "" {
+		return nil
+	}
+	if token == "" {
+		return fmt.Errorf("%s is protected, the protection token must be provided to delete or power it off", client.name)
+	}
+	if token != expected {
+		return errors.New("invalid protection token")
+	}
+	return nil
+}
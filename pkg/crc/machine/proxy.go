@@ -0,0 +1,136 @@
+package machine
+
+import (
+	"context"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/store"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/network/pac"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
+	libmachinestate "github.com/code-ready/machine/libmachine/state"
+	"github.com/pkg/errors"
+)
+
+// the settings applied to the running cluster by UpdateProxy
+var proxySettings = []string{crcConfig.HTTPProxy, crcConfig.HTTPSProxy, crcConfig.NoProxy, crcConfig.ProxyCAFile,
+	crcConfig.ProxyUser, crcConfig.ProxyPassword, crcConfig.ProxyPACFile}
+
+// clusterProxyConfig returns the proxy configuration of the cluster, the
+// instance IPs are never proxied and the proxy connections go through the
+// relay of the daemon when the proxy needs authentication
+func (client *client) clusterProxyConfig(baseDomain string, instanceIPs ...string) (*network.ProxyConfig, error) {
+	proxyConfig, err := getProxyConfig(baseDomain)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range instanceIPs {
+		if ip != "" {
+			proxyConfig.AddNoProxy(ip)
+		}
+	}
+	if auth := client.proxyAuth(); auth != network.NoProxyAuth && proxyConfig.IsEnabled() && client.useVSock() {
+		logging.Infof("Relaying the proxy connections of the VM with %s authentication", auth)
+		proxyConfig = proxyConfig.Relayed(constants.VSockGateway, constants.ProxyRelayPort)
+	}
+	return proxyConfig, nil
+}
+
+// instanceIPv6 returns the address of the VM in the IPv6 subnet of the VM
+// network, it is empty on IPv4-only networks and with the user network mode
+func (client *client) instanceIPv6(ctx context.Context, sshRunner *crcssh.Runner) (string, error) {
+	subnet := crcConfig.GetNetworkIPv6CIDR(client.config)
+	if subnet == nil || client.networkMode() != network.SystemNetworkingMode {
+		return "", nil
+	}
+	ip, err := network.WaitForInstanceIPv6(ctx, sshRunner, subnet)
+	if err != nil {
+		return "", errors.Wrapf(err, "Error getting the IPv6 address of the instance, run 'crc setup' after changing %s", crcConfig.NetworkIPv6CIDR)
+	}
+	logging.Debugf("CodeReady Containers instance has the IPv6 address %s", ip)
+	return ip, nil
+}
+
+// updateProxyDefaults sets the proxy of crc and of the cluster from the
+// current settings, the commands not changing the proxy of the cluster do not
+// evaluate the PAC file
+func (client *client) updateProxyDefaults() error {
+	settings := crcConfig.GetProxySettings(client.config)
+	settings.EvaluatePAC = pac.ProxyFor
+	if _, err := network.NewProxyDefaultsFromSettings(settings); err != nil {
+		return errors.Wrap(err, "Invalid proxy configuration")
+	}
+	return nil
+}
+
+// UpdateProxy applies the current proxy settings, and the proxy environment
+// of the host when they are not set, to the running cluster, its kubelet and
+// crio without restarting the instance
+func (client *client) UpdateProxy() error {
+	// the settings may have changed since this process started
+	if err := client.updateProxyDefaults(); err != nil {
+		return err
+	}
+
+	libMachineAPIClient, cleanup := createLibMachineClient()
+	defer cleanup()
+	host, err := libMachineAPIClient.Load(client.name)
+	if err != nil {
+		return errors.Wrap(err, "Cannot load machine")
+	}
+	vmState, err := host.Driver.GetState()
+	if err != nil {
+		return errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != libmachinestate.Running {
+		return errors.New("machine is not running")
+	}
+	crcBundleMetadata, err := getBundleMetadataFromDriver(host.Driver)
+	if err != nil {
+		return errors.Wrap(err, "Error loading bundle metadata")
+	}
+	instanceIP, err := getIP(host, client.useVSock())
+	if err != nil {
+		return errors.Wrap(err, "Error getting the IP")
+	}
+	sshRunner, err := client.createSSHRunner(instanceIP, getSSHPort(client.useVSock()), crcBundleMetadata, client.profile().PrivateKeyPath(), client.profile().RsaPrivateKeyPath(), crcBundleMetadata.GetSSHKeyPath())
+	if err != nil {
+		return errors.Wrap(err, "Error creating the ssh client")
+	}
+	defer sshRunner.Close()
+
+	ctx := context.Background()
+	instanceIPv6, err := client.instanceIPv6(ctx, sshRunner)
+	if err != nil {
+		return err
+	}
+	proxyConfig, err := client.clusterProxyConfig(crcBundleMetadata.ClusterInfo.BaseDomain, instanceIP, instanceIPv6)
+	if err != nil {
+		return errors.Wrap(err, "Error getting proxy configuration")
+	}
+
+	ocConfig := oc.UseOCWithSSH(sshRunner)
+	if err := cluster.UpdateProxySettings(ctx, sshRunner, ocConfig, proxyConfig); err != nil {
+		return errors.Wrap(err, "Failed to update the proxy configuration of the cluster")
+	}
+	waitForProxyPropagation(ctx, ocConfig, proxyConfig)
+
+	client.forgetPendingConfigChanges(proxySettings...)
+	return nil
+}
+
+// forgetPendingConfigChanges removes the settings applied to the running
+// instance from its pending configuration changes
+func (client *client) forgetPendingConfigChanges(keys ...string) {
+	if err := store.ForInstance(client.name).UpdatePendingConfigChanges(func(pending map[string]store.PendingConfigChange) {
+		for _, key := range keys {
+			delete(pending, key)
+		}
+	}); err != nil {
+		logging.Debugf("Cannot update pending configuration changes: %v", err)
+	}
+}
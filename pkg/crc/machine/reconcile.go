@@ -0,0 +1,83 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/services/dns"
+	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/pkg/errors"
+)
+
+// Reconcile brings a running VM back in sync with the host after the host
+// resumed from sleep: the VM clock is reset, the configuration of the DNS
+// server is rendered again and the server restarted, and the kubelet
+// certificates which expired in the meantime are renewed.
+func (client *client) Reconcile() error {
+	running, err := client.IsRunning()
+	if err != nil {
+		return err
+	}
+	if !running {
+		return nil
+	}
+
+	crcBundleMetadata, sshRunner, err := loadVM(client)
+	if err != nil {
+		return err
+	}
+	defer sshRunner.Close()
+
+	logging.Info("Synchronizing the VM clock with the host...")
+	if _, stderr, err := sshRunner.RunPrivileged("Synchronizing clock", "date", "-u", "-s", fmt.Sprintf("@%d", client.now().Unix())); err != nil {
+		return fmt.Errorf("Failed to set the VM clock %v: %s", err, stderr)
+	}
+
+	if err := client.refreshDNS(context.Background(), sshRunner, crcBundleMetadata); err != nil {
+		logging.Warnf("Failed to restart the DNS server of the VM: %v", err)
+	}
+
+	certsExpiry, err := cluster.GetCertsExpiry(sshRunner)
+	if err != nil {
+		return errors.Wrap(err, "Failed to check certificate validity")
+	}
+	certsExpired := cluster.CheckCertsValidity(certsExpiry, client.now())
+	if certsExpired[cluster.KubeletClientCert] || certsExpired[cluster.KubeletServerCert] {
+		return cluster.ApproveCSRAndWaitForCertsRenewal(context.Background(), sshRunner, oc.UseOCWithSSH(sshRunner),
+			certsExpired[cluster.KubeletClientCert], certsExpired[cluster.KubeletServerCert])
+	}
+	return nil
+}
+
+// refreshDNS renders the configuration of the DNS server of the running VM
+// again and restarts it, the upstream servers of the VM may have changed
+// with the network of the host, or the forward zones with the configuration
+func (client *client) refreshDNS(ctx context.Context, sshRunner *crcssh.Runner, bundleInfo *bundle.CrcBundleInfo) error {
+	if client.networkMode() != network.SystemNetworkingMode {
+		return nil
+	}
+	libMachineAPIClient, cleanup := createLibMachineClient()
+	defer cleanup()
+	host, err := libMachineAPIClient.Load(client.name)
+	if err != nil {
+		return errors.Wrap(err, "Cannot load machine")
+	}
+	ip, err := getIP(host, client.useVSock())
+	if err != nil {
+		return errors.Wrap(err, "Error getting the IP")
+	}
+	ipv6, err := client.instanceIPv6(ctx, sshRunner)
+	if err != nil {
+		return err
+	}
+	serviceConfig, err := client.servicePostStartConfig(sshRunner, bundleInfo, ip, ipv6)
+	if err != nil {
+		return err
+	}
+	return dns.RefreshDnsmasq(serviceConfig)
+}
@@ -0,0 +1,89 @@
+package machine
+
+import (
+	"fmt"
+	"path/filepath"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/config"
+	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
+)
+
+const (
+	// registryStorageMountPoint is the directory of the VM where the
+	// storage of the internal registry is mounted, with both the registry
+	// disk and the shared host directory
+	registryStorageMountPoint = "/var/mnt/crc-registry"
+	registryDiskSerial        = "crc-registry"
+	// the registry disk is a sparse qcow2 image, it only uses the space of
+	// the pushed images
+	registryDiskSizeGiB = 100
+)
+
+// registryDiskPath is the image of the registry disk of the instance called
+// name, it is kept out of the instance directory so that the images survive
+// the deletion of the VM
+func registryDiskPath(name string) string {
+	return filepath.Join(constants.MachineBaseDir, "registry", name+".qcow2")
+}
+
+func (client *client) registryStorage() crcConfig.RegistryStorageTarget {
+	return crcConfig.GetRegistryStorage(client.config)
+}
+
+// registrySharedDir is the shared directory storing the images of the
+// registry, it is nil unless the storage is a host directory
+func (client *client) registrySharedDir() *config.SharedDir {
+	storage := client.registryStorage()
+	if storage.Type != crcConfig.HostDirRegistryStorage {
+		return nil
+	}
+	return &config.SharedDir{
+		Source: storage.HostDir,
+		Target: registryStorageMountPoint,
+		Tag:    config.SharedDirTagPrefix + "-registry",
+	}
+}
+
+// configureRegistryDisk attaches the registry disk to the stopped VM when
+// the registry uses it, and detaches it otherwise
+func (client *client) configureRegistryDisk() error {
+	path := ""
+	if client.registryStorage().Type == crcConfig.DiskRegistryStorage {
+		path = registryDiskPath(client.name)
+	}
+	return attachRegistryDisk(client.name, path)
+}
+
+// mountRegistryDisk formats the registry disk when it is new and mounts it
+func mountRegistryDisk(sshRunner *crcssh.Runner) error {
+	device := fmt.Sprintf("/dev/disk/by-id/virtio-%s", registryDiskSerial)
+	if _, _, err := sshRunner.Run("mountpoint", "-q", registryStorageMountPoint); err == nil {
+		return nil
+	}
+	if _, _, err := sshRunner.RunPrivileged("Checking the registry disk filesystem", "blkid", device); err != nil {
+		logging.Info("Formatting the registry disk...")
+		if _, stderr, err := sshRunner.RunPrivileged("Formatting the registry disk", "mkfs.xfs", "-L", registryDiskSerial, device); err != nil {
+			return fmt.Errorf("Failed to format the registry disk %v: %s", err, stderr)
+		}
+	}
+	if _, _, err := sshRunner.RunPrivileged(fmt.Sprintf("Creating %s", registryStorageMountPoint), "mkdir", "-p", registryStorageMountPoint); err != nil {
+		return err
+	}
+	if _, stderr, err := sshRunner.RunPrivileged("Mounting the registry disk",
+		"mount", "-o", "context="+sharedDirContext, device, registryStorageMountPoint); err != nil {
+		return fmt.Errorf("Failed to mount the registry disk %v: %s", err, stderr)
+	}
+	return nil
+}
+
+// prepareRegistryStorage makes the storage of the registry writable by the
+// registry pod, which runs with an arbitrary user ID
+func prepareRegistryStorage(sshRunner *crcssh.Runner) error {
+	if _, stderr, err := sshRunner.RunPrivileged("Allowing the registry to write its storage", "chmod", "0777", registryStorageMountPoint); err != nil {
+		return fmt.Errorf("Failed to change the permissions of the registry storage %v: %s", err, stderr)
+	}
+	return nil
+}
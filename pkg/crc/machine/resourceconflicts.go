@@ -0,0 +1,82 @@
+package machine
+
+import (
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/store"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/libmachine/host"
+	"github.com/spf13/cast"
+)
+
+// resourceConflicts compares the memory and the CPUs requested in startConfig
+// with the ones of the existing VM. Resources which are chosen automatically,
+// with a zero value, never conflict.
+func resourceConflicts(startConfig types.StartConfig, memory int, cpus int) []types.ResourceConflict {
+	var conflicts []types.ResourceConflict
+	if startConfig.Memory != 0 && startConfig.Memory != memory {
+		conflicts = append(conflicts, types.ResourceConflict{
+			Resource:  crcConfig.Memory,
+			Current:   memory,
+			Requested: startConfig.Memory,
+		})
+	}
+	if startConfig.CPUs != 0 && startConfig.CPUs != cpus {
+		conflicts = append(conflicts, types.ResourceConflict{
+			Resource:  crcConfig.CPUs,
+			Current:   cpus,
+			Requested: startConfig.CPUs,
+		})
+	}
+	return conflicts
+}
+
+// checkRunningVMResources reports the resources of startConfig which cannot
+// be applied because the VM is already running, and records them so that the
+// next start applies them
+func (client *client) checkRunningVMResources(host *host.Host, startConfig types.StartConfig) []types.ResourceConflict {
+	driver, err := loadDriverConfig(host)
+	if err != nil {
+		logging.Debugf("Cannot load driver configuration: %v", err)
+		return nil
+	}
+	conflicts := resourceConflicts(startConfig, driver.Memory, driver.CPU)
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	for _, conflict := range conflicts {
+		logging.Warnf("The running VM uses %s, %s was requested", conflict.Format(conflict.Current), conflict.Format(conflict.Requested))
+	}
+	if err := store.ForInstance(client.name).UpdatePendingConfigChanges(func(pending map[string]store.PendingConfigChange) {
+		for _, conflict := range conflicts {
+			pending[conflict.Resource] = store.PendingConfigChange{
+				Value:  conflict.Requested,
+				Impact: string(types.ConfigAppliedAtStart),
+			}
+		}
+	}); err != nil {
+		logging.Debugf("Cannot update pending configuration changes: %v", err)
+	}
+	return conflicts
+}
+
+// applyPendingResources uses the memory and the CPUs requested while the VM
+// was running for the resources which are not set in startConfig, they are
+// forgotten by updateVMConfig once the VM uses them
+func (client *client) applyPendingResources(startConfig types.StartConfig) types.StartConfig {
+	pending, err := store.ForInstance(client.name).PendingConfigChanges()
+	if err != nil {
+		logging.Debugf("Cannot read pending configuration changes: %v", err)
+		return startConfig
+	}
+	if change, ok := pending[crcConfig.Memory]; ok && startConfig.Memory == 0 {
+		startConfig.Memory = cast.ToInt(change.Value)
+		logging.Infof("Applying the memory requested while the VM was running: %d MiB", startConfig.Memory)
+	}
+	if change, ok := pending[crcConfig.CPUs]; ok && startConfig.CPUs == 0 {
+		startConfig.CPUs = cast.ToInt(change.Value)
+		logging.Infof("Applying the CPUs requested while the VM was running: %d", startConfig.CPUs)
+	}
+	return startConfig
+}
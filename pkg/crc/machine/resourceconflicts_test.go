@@ -0,0 +1,29 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceConflicts(t *testing.T) {
+	assert.Empty(t, resourceConflicts(types.StartConfig{}, 9216, 4))
+	assert.Empty(t, resourceConflicts(types.StartConfig{Memory: 9216, CPUs: 4}, 9216, 4))
+	assert.Equal(t, []types.ResourceConflict{
+		{Resource: "memory", Current: 9216, Requested: 16384},
+		{Resource: "cpus", Current: 4, Requested: 6},
+	}, resourceConflicts(types.StartConfig{Memory: 16384, CPUs: 6}, 9216, 4))
+	assert.Equal(t, []types.ResourceConflict{
+		{Resource: "cpus", Current: 4, Requested: 6},
+	}, resourceConflicts(types.StartConfig{CPUs: 6}, 9216, 4))
+}
+
+func TestResourceConflictFormat(t *testing.T) {
+	memory := types.ResourceConflict{Resource: "memory", Current: 9216, Requested: 16384}
+	assert.Equal(t, "9216 MiB of memory", memory.Format(memory.Current))
+	cpus := types.ResourceConflict{Resource: "cpus", Current: 4, Requested: 6}
+	assert.Equal(t, "6 cpus", cpus.Format(cpus.Requested))
+	disk := types.ResourceChange{Resource: "disk-size", Previous: 31, Current: 50}
+	assert.Equal(t, "a 50 GiB disk", disk.Format(disk.Current))
+}
@@ -0,0 +1,88 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/telemetry"
+	"github.com/code-ready/crc/pkg/crc/validation"
+	"github.com/docker/go-units"
+)
+
+// recommendedMemory returns the memory in MiB to allocate to an instance
+// needing at least minimum MiB: half of the host memory, but never less than
+// minimum unless the host does not have that much memory
+func recommendedMemory(minimum int, hostMemory int) int {
+	if hostMemory/2 >= minimum {
+		return hostMemory / 2
+	}
+	if hostMemory < minimum {
+		return hostMemory
+	}
+	return minimum
+}
+
+// recommendedCPUs returns the number of CPUs to allocate to an instance
+// needing at least minimum CPUs: half of the host CPUs, but never less than
+// minimum
+func recommendedCPUs(minimum int, hostCPUs int) int {
+	if hostCPUs/2 < minimum {
+		return minimum
+	}
+	return hostCPUs / 2
+}
+
+// resolveResources chooses the memory and the CPUs which are not set in
+// startConfig, warns about the ones which are below the bundle requirements,
+// and validates the result
+func (client *client) resolveResources(ctx context.Context, startConfig types.StartConfig, crcBundleMetadata *bundle.CrcBundleInfo) (types.StartConfig, error) {
+	minimumMemory := crcBundleMetadata.GetMinimumMemory()
+	if client.monitoringEnabled() && minimumMemory < minimumMemoryForMonitoring {
+		minimumMemory = minimumMemoryForMonitoring
+	}
+	if startConfig.Memory == 0 {
+		hostMemory := int(validation.HostTotalMemory() / 1024 / 1024)
+		startConfig.Memory = recommendedMemory(minimumMemory, hostMemory)
+		logging.Infof("Using %s of memory, chosen from the %s of the host (set 'memory' to override)",
+			units.BytesSize(float64(startConfig.Memory)*1024*1024),
+			units.BytesSize(float64(hostMemory)*1024*1024))
+		if startConfig.Memory < minimumMemory {
+			logging.Warnf("The host does not have the %s of memory needed by the cluster, it may not start",
+				units.BytesSize(float64(minimumMemory)*1024*1024))
+		}
+	} else if startConfig.Memory < crcBundleMetadata.GetMinimumMemory() {
+		logging.Warnf("%s of memory is configured but bundle %s needs at least %s, the cluster may not start",
+			units.BytesSize(float64(startConfig.Memory)*1024*1024),
+			crcBundleMetadata.GetBundleName(),
+			units.BytesSize(float64(crcBundleMetadata.GetMinimumMemory())*1024*1024))
+	}
+
+	if startConfig.CPUs == 0 {
+		hostCPUs := validation.HostCPUs()
+		startConfig.CPUs = recommendedCPUs(crcBundleMetadata.GetMinimumCPUs(), hostCPUs)
+		logging.Infof("Using %d CPUs, chosen from the %d CPUs of the host (set 'cpus' to override)", startConfig.CPUs, hostCPUs)
+	} else if startConfig.CPUs < crcBundleMetadata.GetMinimumCPUs() {
+		logging.Warnf("%d CPUs are configured but bundle %s needs at least %d, the cluster may not start",
+			startConfig.CPUs, crcBundleMetadata.GetBundleName(), crcBundleMetadata.GetMinimumCPUs())
+	}
+
+	telemetry.SetCPUs(ctx, startConfig.CPUs)
+	telemetry.SetMemory(ctx, uint64(startConfig.Memory)*1024*1024)
+
+	if err := client.validateStartConfig(startConfig); err != nil {
+		return startConfig, err
+	}
+	return startConfig, reserveMemory(ctx, startConfig.Memory)
+}
+
+func (client *client) validateStartConfig(startConfig types.StartConfig) error {
+	if client.monitoringEnabled() && startConfig.Memory < minimumMemoryForMonitoring {
+		return fmt.Errorf("Too little memory (%s) allocated to the virtual machine to start the monitoring stack, %s is the minimum",
+			units.BytesSize(float64(startConfig.Memory)*1024*1024),
+			units.BytesSize(minimumMemoryForMonitoring*1024*1024))
+	}
+	return nil
+}
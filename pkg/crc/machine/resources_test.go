@@ -0,0 +1,16 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecommendedResources(t *testing.T) {
+	assert.Equal(t, 16384, recommendedMemory(9216, 32768))
+	assert.Equal(t, 9216, recommendedMemory(9216, 16384))
+	assert.Equal(t, 8192, recommendedMemory(9216, 8192))
+
+	assert.Equal(t, 6, recommendedCPUs(4, 12))
+	assert.Equal(t, 4, recommendedCPUs(4, 4))
+}
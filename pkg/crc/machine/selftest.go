@@ -0,0 +1,34 @@
+package machine
+
+import (
+	"context"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/pkg/errors"
+)
+
+// NetworkSelfTest checks the DNS, the egress and the registry access from a
+// pod of the running cluster
+func (client *client) NetworkSelfTest() (*types.NetworkSelfTestResult, error) {
+	_, sshRunner, err := loadVM(client)
+	if err != nil {
+		return nil, err
+	}
+	defer sshRunner.Close()
+
+	checks, err := cluster.RunNetworkSelfTest(context.Background(), sshRunner)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot run the network self-test")
+	}
+	result := &types.NetworkSelfTestResult{
+		Healthy: true,
+		Checks:  checks,
+	}
+	for _, check := range checks {
+		if !check.Success {
+			result.Healthy = false
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,53 @@
+package machine
+
+import (
+	"fmt"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/config"
+	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/pkg/errors"
+)
+
+// sharedDirContext is the SELinux context of the shared directories, the
+// containers of the cluster can use them as hostPath volumes
+const sharedDirContext = "system_u:object_r:container_file_t:s0"
+
+// sharedDirs returns the host directories to share into the VM, with the
+// tags identifying them in the VM, including the one of the registry storage
+func (client *client) sharedDirs() []config.SharedDir {
+	var sharedDirs []config.SharedDir
+	for i, sharedDir := range crcConfig.GetSharedDirs(client.config) {
+		sharedDirs = append(sharedDirs, config.SharedDir{
+			Source: sharedDir.Source,
+			Target: sharedDir.Target,
+			Tag:    fmt.Sprintf("%s%d", config.SharedDirTagPrefix, i),
+		})
+	}
+	if registryDir := client.registrySharedDir(); registryDir != nil {
+		sharedDirs = append(sharedDirs, *registryDir)
+	}
+	return sharedDirs
+}
+
+// mountSharedDirs mounts the shared directories in the VM, the mounts do
+// not persist across reboots
+func mountSharedDirs(sshRunner *crcssh.Runner, sharedDirs []config.SharedDir) error {
+	for _, sharedDir := range sharedDirs {
+		target := shellQuote(sharedDir.Target)
+		if _, _, err := sshRunner.Run("mountpoint", "-q", target); err == nil {
+			logging.Debugf("%s is already mounted in the VM", sharedDir.Target)
+			continue
+		}
+		logging.Infof("Mounting %s at %s in the VM", sharedDir.Source, sharedDir.Target)
+		if _, _, err := sshRunner.RunPrivileged(fmt.Sprintf("Creating %s", sharedDir.Target), "mkdir", "-p", target); err != nil {
+			return errors.Wrapf(err, "Failed to create %s in the VM", sharedDir.Target)
+		}
+		if _, stderr, err := sshRunner.RunPrivileged(fmt.Sprintf("Mounting %s", sharedDir.Target),
+			"mount", "-t", "virtiofs", "-o", "context="+sharedDirContext, sharedDir.Tag, target); err != nil {
+			return fmt.Errorf("Failed to mount %s at %s in the VM: %v: %s", sharedDir.Source, sharedDir.Target, err, stderr)
+		}
+	}
+	return nil
+}
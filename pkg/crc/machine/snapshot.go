@@ -0,0 +1,169 @@
+package machine
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/profile"
+	crcos "github.com/code-ready/crc/pkg/os"
+	libmachinestate "github.com/code-ready/machine/libmachine/state"
+	"github.com/pkg/errors"
+)
+
+// the snapshot name is passed to virsh and PowerShell and used as a directory
+// name
+var snapshotNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9][-_.a-zA-Z0-9]{0,62}$`)
+
+func validateSnapshotName(snapshotName string) error {
+	if !snapshotNameRegexp.MatchString(snapshotName) {
+		return fmt.Errorf("'%s' is not a valid snapshot name, it must only contain letters, digits, '-', '_' and '.'", snapshotName)
+	}
+	return nil
+}
+
+func snapshotsDir(name string) string {
+	return filepath.Join(profile.Profile{Name: name}.MachineDir(), "snapshots")
+}
+
+// snapshotDir keeps the instance files of the snapshot, the SSH keys and the
+// kubeadmin password must match the ones of the disk when it is reverted
+func snapshotDir(name, snapshotName string) string {
+	return filepath.Join(snapshotsDir(name), snapshotName)
+}
+
+// Snapshot checkpoints the disk of the stopped instance as snapshotName, a
+// configured cluster can then be restored with Revert instead of being
+// deleted and created again from the bundle. libvirt and Hyper-V keep the
+// snapshot with the VM, the disk image is cloned with hyperkit.
+func (client *client) Snapshot(snapshotName string) error {
+	name := client.name
+	if err := validateSnapshotName(snapshotName); err != nil {
+		return err
+	}
+	diskImage, err := stoppedInstanceDisk(name, "snapshotted")
+	if err != nil {
+		return err
+	}
+	defer client.forgetSnapshotCount()
+	return takeSnapshot(name, snapshotName, func(dir string) error {
+		return createSnapshot(name, snapshotName, diskImage, dir)
+	})
+}
+
+// takeSnapshot snapshots the disk image of the instance with create, which
+// gets the snapshot directory, and copies the instance files to it
+func takeSnapshot(name, snapshotName string, create func(dir string) error) error {
+	dir := snapshotDir(name, snapshotName)
+	if _, err := os.Stat(dir); err == nil {
+		return fmt.Errorf("Snapshot %s of %s already exists", snapshotName, name)
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+	logging.Infof("Creating snapshot %s of %s...", snapshotName, name)
+	if err := create(dir); err != nil {
+		_ = os.RemoveAll(dir)
+		return err
+	}
+	if err := copyInstanceFiles(profile.Profile{Name: name}.MachineDir(), dir); err != nil {
+		_ = deleteSnapshot(name, snapshotName, dir)
+		_ = os.RemoveAll(dir)
+		return err
+	}
+	if err := copyKubeAdminPassword(kubeAdminPasswordStore(profile.Profile{Name: name}), snapshotKubeAdminPasswordStore(name, snapshotName)); err != nil {
+		_ = deleteSnapshot(name, snapshotName, dir)
+		_ = os.RemoveAll(dir)
+		return err
+	}
+	return nil
+}
+
+// Revert restores the stopped instance to its snapshot snapshotName, the
+// changes made to the cluster since the snapshot are lost
+func (client *client) Revert(snapshotName string) error {
+	name := client.name
+	if err := validateSnapshotName(snapshotName); err != nil {
+		return err
+	}
+	diskImage, err := stoppedInstanceDisk(name, "reverted")
+	if err != nil {
+		return err
+	}
+	dir := snapshotDir(name, snapshotName)
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("Snapshot %s of %s does not exist", snapshotName, name)
+	}
+	logging.Infof("Reverting %s to snapshot %s...", name, snapshotName)
+	if err := revertSnapshot(name, snapshotName, diskImage, dir); err != nil {
+		return err
+	}
+	if err := copyInstanceFiles(dir, profile.Profile{Name: name}.MachineDir()); err != nil {
+		return err
+	}
+	return copyKubeAdminPassword(snapshotKubeAdminPasswordStore(name, snapshotName), kubeAdminPasswordStore(profile.Profile{Name: name}))
+}
+
+// removeSnapshot deletes the snapshot snapshotName of the instance name
+func removeSnapshot(name, snapshotName string) error {
+	dir := snapshotDir(name, snapshotName)
+	if err := deleteSnapshot(name, snapshotName, dir); err != nil {
+		return err
+	}
+	if err := snapshotKubeAdminPasswordStore(name, snapshotName).Delete(); err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// stoppedInstanceDisk returns the disk image of the instance name, which must
+// be stopped to be snapshotted or reverted
+func stoppedInstanceDisk(name, operation string) (string, error) {
+	libMachineAPIClient, cleanup := createLibMachineClient()
+	defer cleanup()
+	host, err := libMachineAPIClient.Load(name)
+	if err != nil {
+		return "", errors.Wrap(err, "Cannot load machine")
+	}
+	vmState, err := host.Driver.GetState()
+	if err != nil {
+		return "", errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != libmachinestate.Stopped {
+		return "", fmt.Errorf("Instance %s must be stopped to be %s", name, operation)
+	}
+	driver, err := loadDriverConfig(host)
+	if err != nil {
+		return "", errors.Wrap(err, "Cannot load driver config")
+	}
+	return driver.ResolveStorePath(fmt.Sprintf("%s.%s", driver.MachineName, driver.ImageFormat)), nil
+}
+
+// cloneFrozenDiskImage clones diskImage to dir while the filesystem of the
+// running VM is frozen by quiesce. A full copy would keep it frozen for too
+// long, it fails when the blocks cannot be shared.
+func cloneFrozenDiskImage(diskImage, dir string, quiesce func() (func() error, error)) error {
+	thaw, err := quiesce()
+	if err != nil {
+		return errors.Wrap(err, "Cannot freeze the filesystem of the VM")
+	}
+	err = crcos.ReflinkFile(diskImage, filepath.Join(dir, filepath.Base(diskImage)))
+	if thawErr := thaw(); err == nil && thawErr != nil {
+		err = errors.Wrap(thawErr, "Cannot thaw the filesystem of the VM")
+	}
+	return err
+}
+
+// cloneDiskImage copies src to dst, the blocks are shared when the
+// filesystem supports it and dst is only replaced once the copy is complete
+func cloneDiskImage(src, dst string) error {
+	tmp := dst + ".crc-new"
+	_ = os.Remove(tmp)
+	if err := crcos.CloneFile(src, tmp); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, dst)
+}
@@ -0,0 +1,16 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateSnapshotName(t *testing.T) {
+	assert.NoError(t, validateSnapshotName("configured"))
+	assert.NoError(t, validateSnapshotName("before_upgrade-4.7.0"))
+	assert.Error(t, validateSnapshotName(""))
+	assert.Error(t, validateSnapshotName("-configured"))
+	assert.Error(t, validateSnapshotName("it's"))
+	assert.Error(t, validateSnapshotName("../machines"))
+}
@@ -0,0 +1,221 @@
+package machine
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/libmachine/host"
+	libmachinestate "github.com/code-ready/machine/libmachine/state"
+	"github.com/pkg/errors"
+)
+
+const (
+	scheduledSnapshotPrefix         = "auto-"
+	scheduledSnapshotTimeFormat     = "20060102-150405"
+	snapshotSchedulePollingInterval = 5 * time.Minute
+)
+
+type scheduledSnapshot struct {
+	name string
+	time time.Time
+}
+
+// WatchScheduledSnapshots asks machineClient to take the scheduled snapshots
+// of its instance until ctx is cancelled
+func WatchScheduledSnapshots(ctx context.Context, machineClient Client) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(snapshotSchedulePollingInterval):
+		}
+		if err := machineClient.ScheduledSnapshot(); err != nil {
+			logging.Warnf("Failed to take the scheduled snapshot of %s: %v", machineClient.GetName(), err)
+		}
+	}
+}
+
+// listScheduledSnapshots returns the scheduled snapshots of the instance
+// name, from the oldest to the newest
+func listScheduledSnapshots(name string) ([]scheduledSnapshot, error) {
+	files, err := ioutil.ReadDir(snapshotsDir(name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var snapshots []scheduledSnapshot
+	for _, file := range files {
+		if !file.IsDir() || !strings.HasPrefix(file.Name(), scheduledSnapshotPrefix) {
+			continue
+		}
+		snapshotTime, err := time.Parse(scheduledSnapshotTimeFormat, strings.TrimPrefix(file.Name(), scheduledSnapshotPrefix))
+		if err != nil {
+			// named by the user
+			continue
+		}
+		snapshots = append(snapshots, scheduledSnapshot{name: file.Name(), time: snapshotTime})
+	}
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].time.Before(snapshots[j].time)
+	})
+	return snapshots, nil
+}
+
+// snapshotDue returns true when the newest scheduled snapshot is older than
+// interval
+func snapshotDue(snapshots []scheduledSnapshot, interval time.Duration, now time.Time) bool {
+	return len(snapshots) == 0 || now.Sub(snapshots[len(snapshots)-1].time) >= interval
+}
+
+// expiredSnapshots returns the oldest snapshots beyond the keep newest ones
+func expiredSnapshots(snapshots []scheduledSnapshot, keep int) []scheduledSnapshot {
+	if len(snapshots) <= keep {
+		return nil
+	}
+	return snapshots[:len(snapshots)-keep]
+}
+
+// ScheduledSnapshot snapshots the running instance when the snapshot-schedule
+// setting asks for it, and deletes the oldest scheduled snapshots beyond
+// snapshot-keep. The snapshots are named after their UTC time, such as
+// auto-20211017-120000.
+func (client *client) ScheduledSnapshot() error {
+	interval := crcConfig.GetSnapshotSchedule(client.config).Interval()
+	if interval == 0 {
+		return nil
+	}
+	return client.runScheduledSnapshot(interval, time.Now().UTC())
+}
+
+func (client *client) runScheduledSnapshot(interval time.Duration, now time.Time) error {
+	snapshots, err := listScheduledSnapshots(client.name)
+	if err != nil {
+		return err
+	}
+	if !snapshotDue(snapshots, interval, now) {
+		return nil
+	}
+
+	libMachineAPIClient, cleanup := createLibMachineClient()
+	defer cleanup()
+	exists, err := libMachineAPIClient.Exists(client.name)
+	if err != nil || !exists {
+		return err
+	}
+	host, err := libMachineAPIClient.Load(client.name)
+	if err != nil {
+		return errors.Wrap(err, "Cannot load machine")
+	}
+	vmState, err := host.Driver.GetState()
+	if err != nil {
+		return errors.Wrap(err, "Cannot get machine state")
+	}
+	if vmState != libmachinestate.Running {
+		// only the changes made while the instance runs need protection
+		return nil
+	}
+	driver, err := loadDriverConfig(host)
+	if err != nil {
+		return errors.Wrap(err, "Cannot load driver config")
+	}
+	diskImage := driver.ResolveStorePath(fmt.Sprintf("%s.%s", driver.MachineName, driver.ImageFormat))
+
+	snapshotName := scheduledSnapshotPrefix + now.Format(scheduledSnapshotTimeFormat)
+	defer client.forgetSnapshotCount()
+	if err := takeSnapshot(client.name, snapshotName, func(dir string) error {
+		return createLiveSnapshot(client.name, snapshotName, diskImage, dir, func() (func() error, error) {
+			return client.freezeGuestFilesystem(host)
+		})
+	}); err != nil {
+		return err
+	}
+
+	keep := client.config.Get(crcConfig.SnapshotKeep).AsInt()
+	for _, snapshot := range expiredSnapshots(append(snapshots, scheduledSnapshot{name: snapshotName, time: now}), keep) {
+		logging.Debugf("Deleting the scheduled snapshot %s of %s", snapshot.name, client.name)
+		if err := removeSnapshot(client.name, snapshot.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// guestFreezeTimeout is how long the filesystem of the VM stays frozen at
+// most, the VM thaws it by itself when crc does not do it in time
+const guestFreezeTimeout = time.Minute
+
+// freezeGuestFilesystem freezes the root filesystem of the VM, which holds
+// etcd and the other files of the cluster, so that the snapshot of the
+// running VM is consistent, and returns the function thawing it. Freezing
+// flushes the filesystem to the disk. A single ssh session freezes and thaws
+// the filesystem, a new session would block on the frozen filesystem. The VM
+// thaws it when the session is lost, or after guestFreezeTimeout.
+func (client *client) freezeGuestFilesystem(host *host.Host) (func() error, error) {
+	instanceIP, err := getIP(host, client.useVSock())
+	if err != nil {
+		return nil, errors.Wrap(err, "Error getting the IP")
+	}
+	crcBundleMetadata, err := getBundleMetadataFromDriver(host.Driver)
+	if err != nil {
+		return nil, errors.Wrap(err, "Error loading bundle metadata")
+	}
+	sshRunner, err := client.createSSHRunner(instanceIP, getSSHPort(client.useVSock()), crcBundleMetadata, client.profile().PrivateKeyPath(), client.profile().RsaPrivateKeyPath())
+	if err != nil {
+		return nil, errors.Wrap(err, "Error creating the ssh client")
+	}
+
+	logging.Debugf("Using root access: freezing the filesystem of the VM to snapshot it")
+	script := fmt.Sprintf("timeout %d sh -c 'fsfreeze --freeze / && echo frozen && read _'; fsfreeze --unfreeze /", int(guestFreezeTimeout.Seconds()))
+	return freezeWithSession(func(stdin io.Reader, stdout, stderr io.Writer) error {
+		defer sshRunner.Close()
+		return sshRunner.Shell(sshRunner.PrivilegedCommand(fmt.Sprintf(`sh -c "%s"`, script)), stdin, stdout, stderr)
+	})
+}
+
+// freezeWithSession runs session, which freezes a filesystem, prints "frozen"
+// and thaws it once its standard input is closed. It returns once the
+// filesystem is frozen, with the function thawing it.
+func freezeWithSession(session func(stdin io.Reader, stdout, stderr io.Writer) error) (func() error, error) {
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	var stderr bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		err := session(stdinReader, stdoutWriter, &stderr)
+		stdoutWriter.Close()
+		done <- err
+	}()
+
+	output := bufio.NewReader(stdoutReader)
+	line, _ := output.ReadString('\n')
+	go func() {
+		_, _ = io.Copy(ioutil.Discard, output)
+	}()
+	thaw := func() error {
+		stdinWriter.Close()
+		if err := <-done; err != nil {
+			return fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil
+	}
+	if strings.TrimSpace(line) != "frozen" {
+		err := thaw()
+		if err == nil {
+			err = errors.New("the filesystem was not frozen")
+		}
+		return nil, err
+	}
+	return thaw, nil
+}
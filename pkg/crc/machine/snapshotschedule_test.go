@@ -0,0 +1,54 @@
+package machine
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledSnapshots(t *testing.T) {
+	now := time.Date(2021, time.October, 17, 12, 0, 0, 0, time.UTC)
+	snapshots := []scheduledSnapshot{
+		{name: "auto-20211014-120000", time: now.Add(-72 * time.Hour)},
+		{name: "auto-20211015-120000", time: now.Add(-48 * time.Hour)},
+		{name: "auto-20211016-130000", time: now.Add(-23 * time.Hour)},
+	}
+
+	assert.True(t, snapshotDue(nil, 24*time.Hour, now))
+	assert.False(t, snapshotDue(snapshots, 24*time.Hour, now))
+	assert.True(t, snapshotDue(snapshots, time.Hour, now))
+
+	assert.Equal(t, snapshots[:1], expiredSnapshots(snapshots, 2))
+	assert.Empty(t, expiredSnapshots(snapshots, 3))
+}
+
+func TestFreezeWithSession(t *testing.T) {
+	thawed := make(chan struct{})
+	thaw, err := freezeWithSession(func(stdin io.Reader, stdout, _ io.Writer) error {
+		fmt.Fprintln(stdout, "frozen")
+		// the session thaws the filesystem once its input is closed
+		_, err := ioutil.ReadAll(stdin)
+		close(thawed)
+		return err
+	})
+	require.NoError(t, err)
+	select {
+	case <-thawed:
+		t.Fatal("thawed before the snapshot")
+	default:
+	}
+	assert.NoError(t, thaw())
+	<-thawed
+
+	_, err = freezeWithSession(func(_ io.Reader, _, stderr io.Writer) error {
+		fmt.Fprintln(stderr, "fsfreeze: /: freeze failed: Operation not supported")
+		return errors.New("exit status 1")
+	})
+	assert.EqualError(t, err, "exit status 1: fsfreeze: /: freeze failed: Operation not supported")
+}
@@ -0,0 +1,32 @@
+package machine
+
+import (
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/pkg/errors"
+)
+
+// SSH opens an ssh session in the VM as the user of the bundle, with the
+// keys crc uses for its own commands. It runs the command of sshConfig, or a
+// login shell when it has none, until it exits.
+func (client *client) SSH(sshConfig types.SSHConfig) error {
+	running, err := client.IsRunning()
+	if err != nil {
+		return err
+	}
+	if !running {
+		return errors.New("machine is not running")
+	}
+	_, sshRunner, err := loadVM(client)
+	if err != nil {
+		return err
+	}
+	defer sshRunner.Close()
+
+	quoted := make([]string, 0, len(sshConfig.Command))
+	for _, arg := range sshConfig.Command {
+		quoted = append(quoted, shellQuote(arg))
+	}
+	return sshRunner.Shell(strings.Join(quoted, " "), sshConfig.Stdin, sshConfig.Stdout, sshConfig.Stderr)
+}
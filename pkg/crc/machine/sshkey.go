@@ -0,0 +1,97 @@
+package machine
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/profile"
+	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/pkg/errors"
+)
+
+// errSSHKeyPairMismatch is returned when the private key is replaced but not
+// the public one, the instance cannot be accessed once the next start
+// authorizes the old public key only
+var errSSHKeyPairMismatch = errors.New("the SSH key pair is inconsistent")
+
+// rotateSSHKeyPair replaces the SSH key pair of the instance when policy
+// requires it. The new public key is authorized next to the current one and
+// the local key pair is only replaced once a login with the new key succeeds,
+// the current key is removed from the VM by updateSSHKeyPair afterwards. On
+// failure the current key pair keeps working.
+func rotateSSHKeyPair(sshRunner *crcssh.Runner, homeDir string, instanceProfile profile.Profile, policy crcssh.RotationPolicy, connect func(privateKey string) (*crcssh.Runner, error)) error {
+	privateKeyPath := instanceProfile.PrivateKeyPath()
+	publicKeyPath := instanceProfile.PublicKeyPath()
+	keyInfo, err := os.Stat(privateKeyPath)
+	if err != nil {
+		return errors.Wrapf(err, "Cannot get the age of the SSH key %s", privateKeyPath)
+	}
+	if !policy.Due(keyInfo.ModTime(), time.Now()) {
+		logging.Debugf("The SSH key pair was created on %s, no rotation is needed", keyInfo.ModTime().Format(time.RFC3339))
+		return nil
+	}
+	currentPublicKey, err := ioutil.ReadFile(publicKeyPath)
+	if err != nil {
+		return errors.Wrapf(err, "Cannot read the public SSH key %s", publicKeyPath)
+	}
+
+	logging.Info("Rotating the SSH key pair...")
+	newPrivateKeyPath := privateKeyPath + ".new"
+	newPublicKeyPath := newPrivateKeyPath + ".pub"
+	for _, keyPath := range []string{newPrivateKeyPath, newPublicKeyPath} {
+		if err := os.Remove(keyPath); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "Cannot remove the leftover SSH key %s", keyPath)
+		}
+	}
+	if err := crcssh.GenerateSSHKey(newPrivateKeyPath); err != nil {
+		return errors.Wrap(err, "Cannot generate the new SSH key pair")
+	}
+	// the new key pair is left behind when the rotation fails
+	defer func() {
+		_ = os.Remove(newPrivateKeyPath)
+		_ = os.Remove(newPublicKeyPath)
+	}()
+	newPublicKey, err := ioutil.ReadFile(newPublicKeyPath)
+	if err != nil {
+		return errors.Wrapf(err, "Cannot read the new public SSH key %s", newPublicKeyPath)
+	}
+
+	authorizedKeysPath := path.Join(homeDir, ".ssh", "authorized_keys")
+	bothKeys := append(append([]byte{}, currentPublicKey...), newPublicKey...)
+	if err := sshRunner.InstallData(bothKeys, authorizedKeysPath, 0600); err != nil {
+		return errors.Wrap(err, "Cannot authorize the new SSH key in the VM")
+	}
+	if err := checkSSHLogin(connect, newPrivateKeyPath); err != nil {
+		if restoreErr := sshRunner.InstallData(currentPublicKey, authorizedKeysPath, 0600); restoreErr != nil {
+			logging.Debugf("Cannot remove the new SSH key from the VM: %v", restoreErr)
+		}
+		return errors.Wrap(err, "Cannot log in the VM with the new SSH key")
+	}
+
+	if err := os.Rename(newPrivateKeyPath, privateKeyPath); err != nil {
+		return errors.Wrapf(err, "Cannot replace the SSH key %s", privateKeyPath)
+	}
+	if err := os.Rename(newPublicKeyPath, publicKeyPath); err != nil {
+		// the VM still accepts both keys at this point
+		if writeErr := ioutil.WriteFile(publicKeyPath, newPublicKey, 0644); writeErr != nil {
+			return fmt.Errorf("%w: %s is replaced but not %s, write the public key of %s to it before the next start: %v",
+				errSSHKeyPairMismatch, privateKeyPath, publicKeyPath, privateKeyPath, writeErr)
+		}
+	}
+	logging.Debug("The SSH key pair is rotated, the previous key is removed from the VM")
+	return nil
+}
+
+func checkSSHLogin(connect func(privateKey string) (*crcssh.Runner, error), privateKeyPath string) error {
+	runner, err := connect(privateKeyPath)
+	if err != nil {
+		return err
+	}
+	defer runner.Close()
+	_, _, err = runner.Run("true")
+	return err
+}
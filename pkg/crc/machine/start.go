@@ -3,34 +3,42 @@ package machine
 import (
 	"context"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 
 	"github.com/code-ready/crc/pkg/crc/cluster"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/constants"
 	crcerrors "github.com/code-ready/crc/pkg/crc/errors"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/bundle"
 	"github.com/code-ready/crc/pkg/crc/machine/config"
+	"github.com/code-ready/crc/pkg/crc/machine/profile"
 	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/store"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
 	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/oc"
-	"github.com/code-ready/crc/pkg/crc/services"
-	"github.com/code-ready/crc/pkg/crc/services/dns"
+	"github.com/code-ready/crc/pkg/crc/pipeline"
 	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
-	"github.com/code-ready/crc/pkg/crc/systemd"
 	"github.com/code-ready/crc/pkg/crc/telemetry"
+	"github.com/code-ready/crc/pkg/crc/timeline"
 	crctls "github.com/code-ready/crc/pkg/crc/tls"
+	"github.com/code-ready/crc/pkg/download"
+	"github.com/code-ready/crc/pkg/embed"
 	"github.com/code-ready/crc/pkg/libmachine"
 	"github.com/code-ready/crc/pkg/libmachine/host"
+	crcos "github.com/code-ready/crc/pkg/os"
 	"github.com/code-ready/machine/libmachine/drivers"
 	libmachinestate "github.com/code-ready/machine/libmachine/state"
 	"github.com/docker/go-units"
@@ -40,44 +48,196 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-const minimumMemoryForMonitoring = 14336
+const (
+	minimumMemoryForMonitoring = 14336
+	loginTimeout               = 5 * time.Minute
+	// the kube-apiserver operator rolls out a new revision to serve the
+	// certificate of a named profile
+	profileCertTimeout = 10 * time.Minute
+
+	connectVMRetries         = 5
+	clusterStepRetries       = 2
+	clusterStepRetryInterval = 10 * time.Second
+	// the images are pre-pulled on a best effort basis, the start goes on
+	// when it takes longer
+	prePullImagesTimeout = 10 * time.Minute
+)
 
-func getCrcBundleInfo(bundleName, bundlePath string) (*bundle.CrcBundleInfo, error) {
+func getCrcBundleInfo(bundleName, bundlePath string, cleanupPolicy bundle.CleanupPolicy, source bundleSource, signatureCheck bundle.SignatureCheck) (*bundle.CrcBundleInfo, error) {
 	bundleInfo, err := bundle.Use(bundleName)
 	if err == nil {
 		logging.Infof("Loading bundle: %s...", bundleName)
-		return bundleInfo, nil
+	} else {
+		logging.Debugf("Failed to load bundle %s: %v", bundleName, err)
+		if err := extractBundle(bundleName, bundlePath, cleanupPolicy, source, signatureCheck); err != nil {
+			var signatureErr *bundle.SignatureError
+			if errors.As(err, &signatureErr) {
+				return nil, fmt.Errorf("%v, use '--%s' to extract it anyway", err, crcConfig.SkipBundleSignature)
+			}
+			return nil, err
+		}
+		bundleInfo, err = bundle.Use(bundleName)
+		if err != nil {
+			return nil, err
+		}
+	}
+	// the bundle may have been extracted by 'crc setup', the policy is applied here as well
+	if err := bundle.Cleanup(bundlePath, cleanupPolicy); err != nil {
+		logging.Warnf("Failed to clean up bundle %s: %v", bundlePath, err)
+	}
+	return bundleInfo, nil
+}
+
+// bundleSource is where the bundle is downloaded from when it is not on disk
+type bundleSource struct {
+	urls   []string
+	sha256 string
+	// signature downloads the detached signature of the bundle as well
+	signature bool
+	// image is the OCI artifact the bundle is pulled from instead of urls
+	image *bundleImage
+}
+
+// extractBundle extracts the bundle archive, which is downloaded first when it
+// is missing. The archive is extracted while it is downloaded when it would
+// be deleted once extracted.
+func extractBundle(bundleName, bundlePath string, cleanupPolicy bundle.CleanupPolicy, source bundleSource, signatureCheck bundle.SignatureCheck) error {
+	if source.image != nil {
+		return source.image.pull(filepath.Base(bundlePath), signatureCheck)
+	}
+	if !crcos.FileExists(bundlePath) && len(source.urls) > 0 && cleanupPolicy == bundle.DeleteBundle {
+		return streamBundle(bundlePath, source, signatureCheck)
+	}
+	if err := ensureBundleArchiveExists(bundlePath, source); err != nil {
+		return err
 	}
-	logging.Debugf("Failed to load bundle %s: %v", bundleName, err)
 	logging.Infof("Extracting bundle: %s...", bundleName)
-	if _, err := bundle.Extract(bundlePath); err != nil {
-		return nil, err
+	_, err := bundle.Extract(bundlePath, signatureCheck)
+	return err
+}
+
+// ensureBundleArchiveExists downloads the bundle archive from the configured
+// mirrors, or extracts the default bundle archive from the crc executable
+// again when it was removed, so that a corrupted cached bundle can be replaced
+func ensureBundleArchiveExists(bundlePath string, source bundleSource) error {
+	if crcos.FileExists(bundlePath) {
+		return nil
+	}
+	if len(source.urls) > 0 {
+		return downloadBundle(bundlePath, source)
+	}
+	if bundlePath != constants.DefaultBundlePath || !constants.BundleEmbedded() {
+		return nil
 	}
-	return bundle.Use(bundleName)
+	logging.Infof("Extracting embedded bundle %s to %s", constants.GetDefaultBundle(), filepath.Dir(bundlePath))
+	return embed.Extract(constants.GetDefaultBundle(), bundlePath)
 }
 
-func (client *client) updateVMConfig(startConfig types.StartConfig, api libmachine.API, host *host.Host) error {
-	/* Memory */
-	logging.Debugf("Updating CRC VM configuration")
-	if err := setMemory(host, startConfig.Memory); err != nil {
-		logging.Debugf("Failed to update CRC VM configuration: %v", err)
-		if err == drivers.ErrNotImplemented {
-			logging.Warn("Memory configuration change has been ignored as the machine driver does not support it")
-		} else {
+func newBundleDownloader(source bundleSource) *download.Downloader {
+	if source.sha256 == "" {
+		logging.Warnf("'%s' is not set, the downloaded bundle will not be verified", crcConfig.BundleSHA256)
+	}
+	downloader := download.New()
+	downloader.Progress = logDownloadProgress
+	return downloader
+}
+
+func logDownloadProgress(complete, total int64) {
+	if total > 0 {
+		logging.Infof("Downloaded %d%% of %s", complete*100/total, units.HumanSize(float64(total)))
+	}
+}
+
+func (source bundleSource) signatureURLs() []string {
+	var signatureURLs []string
+	for _, url := range source.urls {
+		signatureURLs = append(signatureURLs, bundle.SignaturePath(url))
+	}
+	return signatureURLs
+}
+
+// downloadBundle downloads the bundle to a partial file next to bundlePath,
+// it is renamed to bundlePath once its checksum is verified so that an
+// interrupted download is never taken for the bundle. The bundle and its
+// signature are saved under the name of the bundle whatever the file names
+// of the mirrors are.
+func downloadBundle(bundlePath string, source bundleSource) error {
+	downloader := newBundleDownloader(source)
+	logging.Infof("Downloading bundle %s...", filepath.Base(bundlePath))
+	partialPath := bundlePath + ".part"
+	if _, err := downloader.DownloadWithChecksum(partialPath, 0600, source.sha256, source.urls...); err != nil {
+		return errors.Wrap(err, "Failed to download the bundle")
+	}
+	if source.signature {
+		signaturePath := bundle.SignaturePath(bundlePath)
+		// the signature of another download of the bundle would be resumed
+		if err := os.Remove(signaturePath); err != nil && !os.IsNotExist(err) {
 			return err
 		}
+		downloader.Progress = nil
+		if _, err := downloader.Download(signaturePath, 0600, source.signatureURLs()...); err != nil {
+			return errors.Wrap(err, "Failed to download the bundle signature")
+		}
 	}
-	if err := setVcpus(host, startConfig.CPUs); err != nil {
-		logging.Debugf("Failed to update CRC VM configuration: %v", err)
-		if err == drivers.ErrNotImplemented {
-			logging.Warn("CPU configuration change has been ignored as the machine driver does not support it")
-		} else {
-			return err
+	return os.Rename(partialPath, bundlePath)
+}
+
+// streamBundle extracts the bundle while it is downloaded, without saving the
+// archive. It is only installed once its checksum and signature are verified.
+func streamBundle(bundlePath string, source bundleSource, signatureCheck bundle.SignatureCheck) error {
+	bundleName := filepath.Base(bundlePath)
+	downloader := newBundleDownloader(source)
+	var signature []byte
+	if source.signature {
+		var err error
+		if signature, err = downloader.InMemory(source.signatureURLs()...); err != nil {
+			return errors.Wrap(err, "Failed to download the bundle signature")
 		}
 	}
-	if err := api.Save(host); err != nil {
+
+	logging.Infof("Downloading and extracting bundle %s...", bundleName)
+	var verifyErr error
+	err := downloader.Stream(func(reader io.Reader) error {
+		hash := sha256.New()
+		_, err := bundle.ExtractStream(bundleName, io.TeeReader(reader, hash), func() error {
+			verifyErr = verifyStreamedBundle(bundleName, hash.Sum(nil), source.sha256, signatureCheck, signature)
+			return verifyErr
+		})
 		return err
+	}, source.urls...)
+	if verifyErr != nil {
+		return verifyErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "Failed to download the bundle")
 	}
+	return nil
+}
+
+func verifyStreamedBundle(bundleName string, digest []byte, sha256sum string, signatureCheck bundle.SignatureCheck, signature []byte) error {
+	if sha256sum != "" && !strings.EqualFold(hex.EncodeToString(digest), sha256sum) {
+		return fmt.Errorf("Invalid checksum for %s: expected %s, got %x", bundleName, sha256sum, digest)
+	}
+	if err := signatureCheck.VerifyDigest(bundleName, digest, signature); err != nil {
+		return &bundle.SignatureError{Path: bundleName, Err: err}
+	}
+	return nil
+}
+
+// updateVMConfig applies startConfig to the stopped VM, and returns the
+// resources it changed and the ones which the driver cannot change
+func (client *client) updateVMConfig(startConfig types.StartConfig, api libmachine.API, host *host.Host) ([]types.ResourceChange, []types.ResourceConflict, error) {
+	logging.Debugf("Updating CRC VM configuration")
+	changes, conflicts, err := resizeVM(host, startConfig.Memory, startConfig.CPUs)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := api.Save(host); err != nil {
+		return nil, nil, err
+	}
+	// the memory and the CPUs requested while the VM was running are applied
+	// now, or replaced by the ones of startConfig
+	client.forgetPendingConfigChanges(crcConfig.Memory, crcConfig.CPUs)
 
 	/* Disk size */
 	if startConfig.DiskSize != constants.DefaultDiskSize {
@@ -86,15 +246,58 @@ func (client *client) updateVMConfig(startConfig types.StartConfig, api libmachi
 			if err == drivers.ErrNotImplemented {
 				logging.Warn("Disk size configuration change has been ignored as the machine driver does not support it")
 			} else {
-				return err
+				return nil, nil, err
 			}
 		}
 		if err := api.Save(host); err != nil {
-			return err
+			return nil, nil, err
 		}
 	}
 
-	return nil
+	return changes, conflicts, nil
+}
+
+// resizeVM sets the memory, in MiB, and the CPUs of the stopped VM, a zero
+// value keeps the current one. It returns the resources it changed and the
+// ones which the driver cannot change.
+func resizeVM(host *host.Host, memory, cpus int) ([]types.ResourceChange, []types.ResourceConflict, error) {
+	driver, err := loadDriverConfig(host)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		changes   []types.ResourceChange
+		conflicts []types.ResourceConflict
+	)
+	/* Memory */
+	if memory != 0 && memory != driver.Memory {
+		if err := setMemory(host, memory); err != nil {
+			logging.Debugf("Failed to update CRC VM configuration: %v", err)
+			if err != drivers.ErrNotImplemented {
+				return nil, nil, err
+			}
+			logging.Warn("Memory configuration change has been ignored as the machine driver does not support it")
+			conflicts = append(conflicts, resourceConflicts(types.StartConfig{Memory: memory}, driver.Memory, driver.CPU)...)
+		} else {
+			changes = append(changes, types.ResourceChange{Resource: crcConfig.Memory, Previous: driver.Memory, Current: memory})
+		}
+	}
+	if cpus != 0 && cpus != driver.CPU {
+		if err := setVcpus(host, cpus); err != nil {
+			logging.Debugf("Failed to update CRC VM configuration: %v", err)
+			if err != drivers.ErrNotImplemented {
+				return nil, nil, err
+			}
+			logging.Warn("CPU configuration change has been ignored as the machine driver does not support it")
+			conflicts = append(conflicts, resourceConflicts(types.StartConfig{CPUs: cpus}, driver.Memory, driver.CPU)...)
+		} else {
+			changes = append(changes, types.ResourceChange{Resource: crcConfig.CPUs, Previous: driver.CPU, Current: cpus})
+		}
+	}
+	for _, change := range changes {
+		logging.Infof("Changing the VM from %s to %s", change.Format(change.Previous), change.Format(change.Current))
+	}
+	return changes, conflicts, nil
 }
 
 func growRootFileSystem(sshRunner *crcssh.Runner) error {
@@ -133,11 +336,11 @@ func growRootFileSystem(sshRunner *crcssh.Runner) error {
 	return nil
 }
 func (client *client) Start(ctx context.Context, startConfig types.StartConfig) (*types.StartResult, error) {
-	telemetry.SetCPUs(ctx, startConfig.CPUs)
-	telemetry.SetMemory(ctx, uint64(startConfig.Memory)*1024*1024)
 	telemetry.SetDiskSize(ctx, uint64(startConfig.DiskSize)*1024*1024*1024)
 
-	if err := client.validateStartConfig(startConfig); err != nil {
+	// the bundle download and the cluster use the proxy selected by the PAC
+	// file, and the settings may have changed since the daemon started
+	if err := client.updateProxyDefaults(); err != nil {
 		return nil, err
 	}
 
@@ -150,6 +353,26 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 		return nil, errors.Wrap(err, "Cannot determine if VM exists")
 	}
 
+	bundleURLs, err := client.bundleURLs()
+	if err != nil {
+		return nil, err
+	}
+	signatureCheck := client.bundleSignatureCheck()
+	source := bundleSource{urls: bundleURLs, sha256: client.bundleSHA256(), signature: signatureCheck.PublicKeyPath != ""}
+	if len(bundleURLs) > 0 {
+		startConfig.BundlePath = filepath.Join(constants.MachineCacheDir, crcConfig.BundleURLFileName(bundleURLs))
+	}
+	if image := client.bundleImage(); image != "" {
+		if len(bundleURLs) > 0 {
+			return nil, fmt.Errorf("'%s' and '%s' cannot be used together", crcConfig.BundleURL, crcConfig.BundleImage)
+		}
+		bundleFileName, bundleImage, err := resolveBundleImage(image)
+		if err != nil {
+			return nil, err
+		}
+		startConfig.BundlePath = filepath.Join(constants.MachineCacheDir, bundleFileName)
+		source.image = bundleImage
+	}
 	bundleName := bundle.GetBundleNameWithoutExtension(filepath.Base(startConfig.BundlePath))
 
 	if !exists {
@@ -161,13 +384,21 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 			return nil, errors.Wrap(err, "Failed to ask for pull secret")
 		}
 
-		crcBundleMetadata, err := getCrcBundleInfo(bundleName, startConfig.BundlePath)
+		crcBundleMetadata, err := getCrcBundleInfo(bundleName, startConfig.BundlePath, client.bundleCleanupPolicy(), source, signatureCheck)
 		if err != nil {
 			return nil, errors.Wrap(err, "Error getting bundle metadata")
 		}
+		startConfig, err = client.resolveResources(ctx, startConfig, crcBundleMetadata)
+		if err != nil {
+			return nil, err
+		}
 
 		logging.Infof("Creating CodeReady Containers VM for OpenShift %s...", crcBundleMetadata.GetOpenshiftVersion())
 
+		diskIOTuning := crcConfig.GetDiskIOTuning(client.config)
+		if diskIOTuning.CacheMode == crcConfig.UnsafeDiskCache {
+			logging.Warn("The disk of the VM uses the unsafe cache mode, it may be corrupted if the host crashes")
+		}
 		machineConfig := config.MachineConfig{
 			Name:            client.name,
 			BundleName:      bundleName,
@@ -182,10 +413,17 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 			Initramfs:       crcBundleMetadata.GetInitramfsPath(),
 			Kernel:          crcBundleMetadata.GetKernelPath(),
 			KubeConfig:      crcBundleMetadata.GetKubeConfigPath(),
+			DiskCacheMode:   string(diskIOTuning.CacheMode),
+			DiskIOMode:      string(diskIOTuning.IOMode),
+			DiskEncryption:  client.config.Get(crcConfig.DiskEncryption).AsBool(),
+			SharedDirs:      client.sharedDirs(),
 		}
 		if err := createHost(libMachineAPIClient, machineConfig); err != nil {
 			return nil, errors.Wrap(err, "Error creating machine")
 		}
+		if err := client.configureInstanceNetwork(); err != nil {
+			return nil, errors.Wrap(err, "Error configuring the network of the machine")
+		}
 	} else {
 		telemetry.SetStartType(ctx, telemetry.StartStartType)
 	}
@@ -211,268 +449,104 @@ func (client *client) Start(ctx context.Context, startConfig types.StartConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "Error getting the machine state")
 	}
+	if vmState == libmachinestate.Paused {
+		return nil, errors.New("The CodeReady Containers VM is paused, use 'crc resume' to resume it")
+	}
+	// a running instance which is not healthy is adopted: it goes through
+	// the start sequence without being started again
+	adopting := false
 	if vmState == libmachinestate.Running {
+		if problems := client.runningVMProblems(ctx, host, crcBundleMetadata, bundleName); len(problems) > 0 {
+			logging.Infof("A CodeReady Containers VM for OpenShift %s is already running but %s, verifying it...",
+				crcBundleMetadata.GetOpenshiftVersion(), strings.Join(problems, " and "))
+			adopting = true
+			telemetry.SetStartType(ctx, telemetry.AdoptedStartType)
+		}
+	}
+	if vmState == libmachinestate.Running && !adopting {
 		logging.Infof("A CodeReady Containers VM for OpenShift %s is already running", crcBundleMetadata.GetOpenshiftVersion())
-		clusterConfig, err := getClusterConfig(crcBundleMetadata)
+		clusterConfig, err := getClusterConfig(client.profile(), crcBundleMetadata)
 		if err != nil {
 			return nil, errors.Wrap(err, "Cannot create cluster configuration")
 		}
+		client.updateClusterConfigFromRunningVM(host, crcBundleMetadata, clusterConfig)
+		resourceConflicts := client.checkRunningVMResources(host, startConfig)
+		client.warnPendingConfigChanges(true)
 
 		telemetry.SetStartType(ctx, telemetry.AlreadyRunningStartType)
 		return &types.StartResult{
-			Status:         state.FromMachine(vmState),
-			ClusterConfig:  *clusterConfig,
-			KubeletStarted: true,
+			Status:            state.FromMachine(vmState),
+			ClusterConfig:     *clusterConfig,
+			KubeletStarted:    true,
+			AlreadyRunning:    true,
+			ResourceConflicts: resourceConflicts,
+			Summary:           startSummary(client.name, clusterConfig),
 		}, nil
 	}
 
 	if _, err := bundle.Use(currentBundleName); err != nil {
 		return nil, err
 	}
-
-	logging.Infof("Starting CodeReady Containers VM for OpenShift %s...", crcBundleMetadata.GetOpenshiftVersion())
-
-	if client.useVSock() {
-		if err := exposePorts(); err != nil {
+	if client.useVSock() && !adopting {
+		if err := checkNoOtherInstanceRunning(libMachineAPIClient, client.name); err != nil {
 			return nil, err
 		}
 	}
-
-	if err := client.updateVMConfig(startConfig, libMachineAPIClient, host); err != nil {
-		return nil, errors.Wrap(err, "Could not update CRC VM configuration")
-	}
-
-	if err := startHost(ctx, libMachineAPIClient, host); err != nil {
-		return nil, errors.Wrap(err, "Error starting machine")
-	}
-
-	// Post-VM start
-	vmState, err = host.Driver.GetState()
-	if err != nil {
-		return nil, errors.Wrap(err, "Error getting the state")
-	}
-	if vmState != libmachinestate.Running {
-		return nil, errors.Wrap(err, "CodeReady Containers VM is not running")
-	}
-
-	instanceIP, err := getIP(host, client.useVSock())
-	if err != nil {
-		return nil, errors.Wrap(err, "Error getting the IP")
-	}
-	logging.Infof("CodeReady Containers instance is running with IP %s", instanceIP)
-	sshRunner, err := crcssh.CreateRunner(instanceIP, getSSHPort(client.useVSock()), crcBundleMetadata.GetSSHKeyPath(), constants.GetPrivateKeyPath(), constants.GetRsaPrivateKeyPath())
-	if err != nil {
-		return nil, errors.Wrap(err, "Error creating the ssh client")
-	}
-	defer sshRunner.Close()
-
-	logging.Debug("Waiting until ssh is available")
-	if err := sshRunner.WaitForConnectivity(ctx, 300*time.Second); err != nil {
-		return nil, errors.Wrap(err, "Failed to connect to the CRC VM with SSH -- host might be unreachable")
-	}
-	logging.Info("CodeReady Containers VM is running")
-
-	// Post VM start immediately update SSH key and copy kubeconfig to instance
-	// dir and VM
-	if err := updateSSHKeyPair(sshRunner); err != nil {
-		return nil, errors.Wrap(err, "Error updating public key")
-	}
-
-	// Trigger disk resize, this will be a no-op if no disk size change is needed
-	if err := growRootFileSystem(sshRunner); err != nil {
-		return nil, errors.Wrap(err, "Error updating filesystem size")
-	}
-
-	// Start network time synchronization if `CRC_DEBUG_ENABLE_STOP_NTP` is not set
-	if stopNtp, _ := strconv.ParseBool(os.Getenv("CRC_DEBUG_ENABLE_STOP_NTP")); stopNtp {
-		logging.Info("Stopping network time synchronization in CodeReady Containers VM")
-		if _, _, err := sshRunner.RunPrivileged("Turning off the ntp server", "timedatectl set-ntp off"); err != nil {
-			return nil, errors.Wrap(err, "Failed to stop network time synchronization")
-		}
-		logging.Info("Setting clock to host clock (UTC timezone)")
-		dateCmd := fmt.Sprintf("date -s '%s'", time.Now().Format(time.UnixDate))
-		if _, _, err := sshRunner.RunPrivileged("Setting clock same as host", dateCmd); err != nil {
-			return nil, errors.Wrap(err, "Failed to set clock to same as host")
-		}
-	}
-
-	// Add nameserver to VM if provided by User
-	if startConfig.NameServer != "" {
-		if err = addNameServerToInstance(sshRunner, startConfig.NameServer); err != nil {
-			return nil, errors.Wrap(err, "Failed to add nameserver to the VM")
-		}
-	}
-
-	if _, _, err := sshRunner.RunPrivileged("make root Podman socket accessible", "chmod 777 /run/podman/ /run/podman/podman.sock"); err != nil {
-		return nil, errors.Wrap(err, "Failed to change permissions to root podman socket")
-	}
-
-	proxyConfig, err := getProxyConfig(crcBundleMetadata.ClusterInfo.BaseDomain)
-	if err != nil {
-		return nil, errors.Wrap(err, "Error getting proxy configuration")
-	}
-	proxyConfig.ApplyToEnvironment()
-	proxyConfig.AddNoProxy(instanceIP)
-
-	// Create servicePostStartConfig for DNS checks and DNS start.
-	servicePostStartConfig := services.ServicePostStartConfig{
-		Name: client.name,
-		// TODO: would prefer passing in a more generic type
-		SSHRunner: sshRunner,
-		IP:        instanceIP,
-		// TODO: should be more finegrained
-		BundleMetadata: *crcBundleMetadata,
-		NetworkMode:    client.networkMode(),
-	}
-
-	// Run the DNS server inside the VM
-	if err := dns.RunPostStart(servicePostStartConfig); err != nil {
-		return nil, errors.Wrap(err, "Error running post start")
-	}
-
-	// Check DNS lookup before starting the kubelet
-	if queryOutput, err := dns.CheckCRCLocalDNSReachable(ctx, servicePostStartConfig); err != nil {
-		if !client.useVSock() {
-			return nil, errors.Wrapf(err, "Failed internal DNS query: %s", queryOutput)
-		}
-	}
-	logging.Info("Check internal and public DNS query...")
-
-	if queryOutput, err := dns.CheckCRCPublicDNSReachable(servicePostStartConfig); err != nil {
-		logging.Warnf("Failed public DNS query from the cluster: %v : %s", err, queryOutput)
-	}
-
-	// Check DNS lookup from host to VM
-	logging.Info("Check DNS query from host...")
-	if err := network.CheckCRCLocalDNSReachableFromHost(crcBundleMetadata, instanceIP); err != nil {
-		if !client.useVSock() {
-			return nil, errors.Wrap(err, "Failed to query DNS from host")
+	if exists && !adopting {
+		startConfig = client.applyPendingResources(startConfig)
+		startConfig, err = client.resolveResources(ctx, startConfig, crcBundleMetadata)
+		if err != nil {
+			return nil, err
 		}
-		logging.Warn(fmt.Sprintf("Failed to query DNS from host: %v", err))
 	}
 
-	// Remove this block after 2-3 release (after v1.32.0)
-	// This is just to support 4.7 bundle with current master
-	if strings.HasPrefix(crcBundleMetadata.GetOpenshiftVersion(), "4.7.") {
-		if err := cluster.EnsurePullSecretPresentOnInstanceDisk(sshRunner, startConfig.PullSecret); err != nil {
-			return nil, errors.Wrap(err, "Failed to update VM pull secret")
+	run := &startRun{
+		client:            client,
+		startConfig:       startConfig,
+		api:               libMachineAPIClient,
+		host:              host,
+		crcBundleMetadata: crcBundleMetadata,
+		adopting:          adopting,
+	}
+	defer run.close()
+	startPipeline := pipeline.New(run.steps()...)
+	startPipeline.OnStepDone = func(result pipeline.StepResult) {
+		if !result.Skipped {
+			timeline.FromContext(ctx).Phase(result.Name, result.Duration, result.Err)
+			telemetry.SetStepResult(ctx, result.Name, result.Duration, result.Err)
 		}
 	}
-
-	// Check the certs validity inside the vm
-	logging.Info("Verifying validity of the kubelet certificates...")
-	certsExpired, err := cluster.CheckCertsValidity(sshRunner)
+	results, err := startPipeline.Run(ctx)
+	logStepTimings(results)
 	if err != nil {
-		return nil, errors.Wrap(err, "Failed to check certificate validity")
-	}
-
-	logging.Info("Starting OpenShift kubelet service")
-	sd := systemd.NewInstanceSystemdCommander(sshRunner)
-	if err := sd.Start("kubelet"); err != nil {
-		return nil, errors.Wrap(err, "Error starting kubelet")
-	}
-
-	ocConfig := oc.UseOCWithSSH(sshRunner)
-
-	if err := cluster.ApproveCSRAndWaitForCertsRenewal(ctx, sshRunner, ocConfig, certsExpired[cluster.KubeletClientCert], certsExpired[cluster.KubeletServerCert]); err != nil {
-		logBundleDate(crcBundleMetadata)
-		return nil, errors.Wrap(err, "Failed to renew TLS certificates: please check if a newer CodeReady Containers release is available")
-	}
-
-	if err := cluster.WaitForAPIServer(ctx, ocConfig); err != nil {
-		return nil, errors.Wrap(err, "Error waiting for apiserver")
-	}
-
-	if err := cluster.DeleteMCOLeaderLease(ctx, ocConfig); err != nil {
 		return nil, err
 	}
 
-	if err := cluster.EnsurePullSecretPresentInTheCluster(ctx, ocConfig, startConfig.PullSecret); err != nil {
-		return nil, errors.Wrap(err, "Failed to update cluster pull secret")
-	}
-
-	if err := cluster.EnsureSSHKeyPresentInTheCluster(ctx, ocConfig, constants.GetPublicKeyPath()); err != nil {
-		return nil, errors.Wrap(err, "Failed to update ssh public key to machine config")
-	}
-
-	if err := cluster.WaitForPullSecretPresentOnInstanceDisk(ctx, sshRunner); err != nil {
-		return nil, errors.Wrap(err, "Failed to update pull secret on the disk")
+	if err := store.ForInstance(client.name).SetLastStart(time.Now()); err != nil {
+		logging.Debugf("Cannot record the start time: %v", err)
 	}
+	// the memory, CPUs and disk size of an adopted instance are not changed
+	client.warnPendingConfigChanges(adopting)
+	client.startHostServices()
 
-	if err := ensureProxyIsConfiguredInOpenShift(ctx, ocConfig, sshRunner, proxyConfig, instanceIP); err != nil {
-		return nil, errors.Wrap(err, "Failed to update cluster proxy configuration")
-	}
-
-	if err := cluster.UpdateKubeAdminUserPassword(ctx, ocConfig, startConfig.KubeAdminPassword); err != nil {
-		return nil, errors.Wrap(err, "Failed to update kubeadmin user password")
-	}
-
-	if err := cluster.EnsureClusterIDIsNotEmpty(ctx, ocConfig); err != nil {
-		return nil, errors.Wrap(err, "Failed to update cluster ID")
-	}
-
-	if client.useVSock() {
-		if err := ensureRoutesControllerIsRunning(sshRunner, ocConfig); err != nil {
-			return nil, err
-		}
-	}
-
-	if client.monitoringEnabled() {
-		logging.Info("Enabling cluster monitoring operator...")
-		if err := cluster.StartMonitoring(ocConfig); err != nil {
-			return nil, errors.Wrap(err, "Cannot start monitoring stack")
-		}
-	}
-
-	// In Openshift 4.3, when cluster comes up, the following happens
-	// 1. After the openshift-apiserver pod is started, its log contains multiple occurrences of `certificate has expired or is not yet valid`
-	// 2. Initially there is no request-header's client-ca crt available to `extension-apiserver-authentication` configmap
-	// 3. In the pod logs `missing content for CA bundle "client-ca::kube-system::extension-apiserver-authentication::requestheader-client-ca-file"`
-	// 4. After ~1 min /etc/kubernetes/static-pod-resources/kube-apiserver-certs/configmaps/aggregator-client-ca/ca-bundle.crt is regenerated
-	// 5. It is now also appear to `extension-apiserver-authentication` configmap as part of request-header's client-ca content
-	// 6. Openshift-apiserver is able to load the CA which was regenerated
-	// 7. Now apiserver pod log contains multiple occurrences of `error x509: certificate signed by unknown authority`
-	// When the openshift-apiserver is in this state, the cluster is non functional.
-	// A restart of the openshift-apiserver pod is enough to clear that error and get a working cluster.
-	// This is a work-around while the root cause is being identified.
-	// More info: https://bugzilla.redhat.com/show_bug.cgi?id=1795163
-	if certsExpired[cluster.AggregatorClientCert] {
-		logging.Debug("Waiting for the renewal of the request header client ca...")
-		if err := cluster.WaitForRequestHeaderClientCaFile(ctx, sshRunner); err != nil {
-			return nil, errors.Wrap(err, "Failed to wait for aggregator client ca renewal")
-		}
+	return &types.StartResult{
+		KubeletStarted:    true,
+		ClusterConfig:     *run.clusterConfig,
+		Status:            state.FromMachine(run.vmState),
+		ResourceConflicts: run.resourceConflicts,
+		ResourceChanges:   run.resourceChanges,
+		Summary:           startSummary(client.name, run.clusterConfig),
+	}, nil
+}
 
-		if err := cluster.DeleteOpenshiftAPIServerPods(ctx, ocConfig); err != nil {
-			return nil, errors.Wrap(err, "Cannot delete OpenShift API Server pods")
+func logStepTimings(results []pipeline.StepResult) {
+	var timings []string
+	for _, result := range results {
+		if !result.Skipped {
+			timings = append(timings, fmt.Sprintf("%s=%s", result.Name, result.Duration.Round(time.Millisecond)))
 		}
 	}
-
-	if err := updateKubeconfig(ctx, ocConfig, sshRunner, crcBundleMetadata.GetKubeConfigPath()); err != nil {
-		return nil, errors.Wrap(err, "Failed to update kubeconfig file")
-	}
-
-	logging.Info("Starting OpenShift cluster... [waiting for the cluster to stabilize]")
-	if err := cluster.WaitForClusterStable(ctx, instanceIP, constants.KubeconfigFilePath, proxyConfig); err != nil {
-		logging.Errorf("Cluster is not ready: %v", err)
-	}
-
-	waitForProxyPropagation(ctx, ocConfig, proxyConfig)
-
-	clusterConfig, err := getClusterConfig(crcBundleMetadata)
-	if err != nil {
-		return nil, errors.Wrap(err, "Cannot get cluster configuration")
-	}
-
-	logging.Info("Adding crc-admin and crc-developer contexts to kubeconfig...")
-	if err := writeKubeconfig(instanceIP, clusterConfig); err != nil {
-		logging.Errorf("Cannot update kubeconfig: %v", err)
-	}
-
-	return &types.StartResult{
-		KubeletStarted: true,
-		ClusterConfig:  *clusterConfig,
-		Status:         state.FromMachine(vmState),
-	}, nil
+	logging.Debugf("Start steps timings: %s", strings.Join(timings, " "))
 }
 
 func (client *client) IsRunning() (bool, error) {
@@ -496,50 +570,125 @@ func (client *client) IsRunning() (bool, error) {
 	return true, nil
 }
 
-func (client *client) validateStartConfig(startConfig types.StartConfig) error {
-	if client.monitoringEnabled() && startConfig.Memory < minimumMemoryForMonitoring {
-		return fmt.Errorf("Too little memory (%s) allocated to the virtual machine to start the monitoring stack, %s is the minimum",
-			units.BytesSize(float64(startConfig.Memory)*1024*1024),
-			units.BytesSize(minimumMemoryForMonitoring*1024*1024))
+func createHost(api libmachine.API, machineConfig config.MachineConfig) error {
+	if machineConfig.DiskEncryption {
+		if err := diskEncryptionSupported(); err != nil {
+			return err
+		}
+	}
+	vm, err := createVM(api, machineConfig)
+	if err != nil {
+		return err
+	}
+	if err := configureSharedDirs(machineConfig.Name, machineConfig.SharedDirs); err != nil {
+		return err
+	}
+	if machineConfig.DiskEncryption {
+		if err := encryptDisk(vm); err != nil {
+			return err
+		}
+	}
+
+	instanceProfile := profile.Profile{Name: machineConfig.Name}
+	logging.Info("Generating new SSH Key pair...")
+	if err := crcssh.GenerateSSHKey(instanceProfile.PrivateKeyPath()); err != nil {
+		return fmt.Errorf("Error generating ssh key pair: %v", err)
+	}
+	if err := cluster.GenerateKubeAdminUserPassword(kubeAdminPasswordStore(instanceProfile)); err != nil {
+		return errors.Wrap(err, "Error generating new kubeadmin password")
 	}
+	if err := api.SetExists(vm.Name); err != nil {
+		return fmt.Errorf("Failed to record VM existence: %s", err)
+	}
+
+	logging.Debug("Machine successfully created")
 	return nil
 }
 
-func createHost(api libmachine.API, machineConfig config.MachineConfig) error {
+// createVM creates the VM of machineConfig in the hypervisor
+func createVM(api libmachine.API, machineConfig config.MachineConfig) (*host.Host, error) {
 	vm, err := newHost(api, machineConfig)
 	if err != nil {
-		return fmt.Errorf("Error creating new host: %s", err)
+		return nil, fmt.Errorf("Error creating new host: %s", err)
 	}
 
 	logging.Debug("Running pre-create checks...")
 
 	if err := vm.Driver.PreCreateCheck(); err != nil {
-		return errors.Wrap(err, "error with pre-create check")
+		return nil, errors.Wrap(err, "error with pre-create check")
 	}
 
 	if err := api.Save(vm); err != nil {
-		return fmt.Errorf("Error saving host to store before attempting creation: %s", err)
+		return nil, fmt.Errorf("Error saving host to store before attempting creation: %s", err)
 	}
 
 	logging.Debug("Creating machine...")
 
 	if err := vm.Driver.Create(); err != nil {
-		return fmt.Errorf("Error in driver during machine creation: %s", err)
+		return nil, fmt.Errorf("Error in driver during machine creation: %s", err)
 	}
+	return vm, nil
+}
 
-	logging.Info("Generating new SSH Key pair...")
-	if err := crcssh.GenerateSSHKey(constants.GetPrivateKeyPath()); err != nil {
-		return fmt.Errorf("Error generating ssh key pair: %v", err)
-	}
-	if err := cluster.GenerateKubeAdminUserPassword(); err != nil {
-		return errors.Wrap(err, "Error generating new kubeadmin password")
+// waitForSSH waits for the VM to be reachable with ssh, and fails early if the
+// VM stops running in the meantime, for example when the hypervisor crashes
+func (client *client) waitForSSH(ctx context.Context, host *host.Host, sshRunner *crcssh.Runner) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	vmStopped := make(chan libmachinestate.State, 1)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(2 * time.Second):
+			}
+			vmState, err := host.Driver.GetState()
+			if err != nil {
+				logging.Debugf("Cannot get VM state: %v", err)
+				continue
+			}
+			if vmState != libmachinestate.Running {
+				vmStopped <- vmState
+				cancel()
+				return
+			}
+		}
+	}()
+
+	err := sshRunner.WaitForConnectivity(ctx, 300*time.Second)
+	if err == nil {
+		return nil
 	}
-	if err := api.SetExists(vm.Name); err != nil {
-		return fmt.Errorf("Failed to record VM existence: %s", err)
+	select {
+	case vmState := <-vmStopped:
+		msg := fmt.Sprintf("The CodeReady Containers VM unexpectedly stopped while waiting for it to start (state: %s)", vmState)
+		if excerpt := consoleLogExcerpt(consoleLogPath(client.name)); excerpt != "" {
+			msg = fmt.Sprintf("%s, last lines of the VM log:\n%s", msg, excerpt)
+		}
+		return errors.New(msg)
+	default:
+		return errors.Wrap(err, "Failed to connect to the CRC VM with SSH -- host might be unreachable")
 	}
+}
 
-	logging.Debug("Machine successfully created")
-	return nil
+// consoleLogExcerpt returns the last lines of the log file at path, or an empty string if it cannot be read
+func consoleLogExcerpt(path string) string {
+	const maxLines = 20
+	if path == "" {
+		return ""
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		logging.Debugf("Cannot read VM log: %v", err)
+		return ""
+	}
+	lines := strings.Split(strings.TrimRight(string(content), "\n"), "\n")
+	if len(lines) > maxLines {
+		lines = lines[len(lines)-maxLines:]
+	}
+	return strings.Join(lines, "\n")
 }
 
 func startHost(ctx context.Context, api libmachine.API, vm *host.Host) error {
@@ -561,44 +710,35 @@ func startHost(ctx context.Context, api libmachine.API, vm *host.Host) error {
 	return nil
 }
 
-func addNameServerToInstance(sshRunner *crcssh.Runner, ns string) error {
-	nameserver := network.NameServer{IPAddress: ns}
-	nameservers := []network.NameServer{nameserver}
-	exist, err := network.HasGivenNameserversConfigured(sshRunner, nameserver)
-	if err != nil {
-		return err
-	}
-	if !exist {
-		logging.Infof("Adding %s as nameserver to the instance...", nameserver.IPAddress)
-		return network.AddNameserversToInstance(sshRunner, nameservers)
-	}
-	return nil
-}
-
-func updateSSHKeyPair(sshRunner *crcssh.Runner) error {
+func updateSSHKeyPair(sshRunner *crcssh.Runner, homeDir string, publicKeyPath string) error {
 	// Read generated public key
-	publicKey, err := ioutil.ReadFile(constants.GetPublicKeyPath())
+	publicKey, err := ioutil.ReadFile(publicKeyPath)
 	if err != nil {
-		return err
+		return errors.Wrapf(err, "Cannot read the public SSH key %s", publicKeyPath)
+	}
+	if _, _, _, _, err := ssh.ParseAuthorizedKey(publicKey); err != nil {
+		return errors.Wrapf(err, "Invalid public SSH key %s", publicKeyPath)
 	}
 
-	authorizedKeys, _, err := sshRunner.Run("cat /home/core/.ssh/authorized_keys")
-	if err == nil && strings.TrimSpace(authorizedKeys) == strings.TrimSpace(string(publicKey)) {
+	sshDir := path.Join(homeDir, ".ssh")
+	authorizedKeysPath := path.Join(sshDir, "authorized_keys")
+	authorizedKeys, err := sshRunner.ReadRemoteFile(authorizedKeysPath)
+	if err == nil && strings.TrimSpace(string(authorizedKeys)) == strings.TrimSpace(string(publicKey)) {
 		return nil
 	}
 
 	logging.Info("Updating authorized keys...")
-	// CopyData uses sudo and we need to use it
-	// because of https://bugzilla.redhat.com/show_bug.cgi?id=1956739
-	err = sshRunner.CopyData(publicKey, "/home/core/.ssh/authorized_keys", 0644)
-	if err != nil {
-		return err
+	// sshd ignores the keys of a directory writable by other users
+	if _, _, err := sshRunner.RunPrivileged("Securing the ssh directory", "chmod", "0700", sshDir); err != nil {
+		return errors.Wrapf(err, "Cannot set the permissions of %s in the VM", sshDir)
 	}
-	return err
+	// InstallData uses sudo and we need to use it
+	// because of https://bugzilla.redhat.com/show_bug.cgi?id=1956739
+	return sshRunner.InstallData(publicKey, authorizedKeysPath, 0600)
 }
 
 func copyKubeconfigFileWithUpdatedUserClientCertAndKey(selfSignedCAKey *rsa.PrivateKey, selfSignedCACert *x509.Certificate, srcKubeConfigPath, dstKubeConfigPath string) error {
-	if _, err := os.Stat(constants.KubeconfigFilePath); err == nil {
+	if _, err := os.Stat(dstKubeConfigPath); err == nil {
 		return nil
 	}
 	clientKey, clientCert, err := crctls.GenerateClientCertificate(selfSignedCAKey, selfSignedCACert)
@@ -608,14 +748,6 @@ func copyKubeconfigFileWithUpdatedUserClientCertAndKey(selfSignedCAKey *rsa.Priv
 	return updateClientCrtAndKeyToKubeconfig(clientKey, clientCert, srcKubeConfigPath, dstKubeConfigPath)
 }
 
-func ensureProxyIsConfiguredInOpenShift(ctx context.Context, ocConfig oc.Config, sshRunner *crcssh.Runner, proxy *network.ProxyConfig, instanceIP string) (err error) {
-	if !proxy.IsEnabled() {
-		return nil
-	}
-	logging.Info("Adding proxy configuration to the cluster...")
-	return cluster.AddProxyConfigToCluster(ctx, sshRunner, ocConfig, proxy)
-}
-
 func waitForProxyPropagation(ctx context.Context, ocConfig oc.Config, proxyConfig *network.ProxyConfig) {
 	if !proxyConfig.IsEnabled() {
 		return
@@ -641,10 +773,8 @@ func waitForProxyPropagation(ctx context.Context, ocConfig oc.Config, proxyConfi
 
 func logBundleDate(crcBundleMetadata *bundle.CrcBundleInfo) {
 	if buildTime, err := crcBundleMetadata.GetBundleBuildTime(); err == nil {
-		bundleAgeDays := time.Since(buildTime).Hours() / 24
-		if bundleAgeDays >= 30 {
-			/* Initial bundle certificates are only valid for 30 days */
-			logging.Debugf("Bundle has been generated %d days ago", int(bundleAgeDays))
+		if bundleOutdated(buildTime, time.Now()) {
+			logging.Debugf("Bundle has been generated %d days ago", int(time.Since(buildTime).Hours()/24))
 		}
 	}
 }
@@ -683,19 +813,19 @@ func ensureRoutesControllerIsRunning(sshRunner *crcssh.Runner, ocConfig oc.Confi
 	return nil
 }
 
-func updateKubeconfig(ctx context.Context, ocConfig oc.Config, sshRunner *crcssh.Runner, kubeconfigFilePath string) error {
+func updateKubeconfig(ctx context.Context, ocConfig oc.Config, sshRunner *crcssh.Runner, kubeconfigFilePath string, instanceKubeconfigPath string) error {
 	selfSignedCAKey, selfSignedCACert, err := crctls.GetSelfSignedCA()
 	if err != nil {
 		return errors.Wrap(err, "Not able to generate root CA key and Cert")
 	}
-	if err := copyKubeconfigFileWithUpdatedUserClientCertAndKey(selfSignedCAKey, selfSignedCACert, kubeconfigFilePath, constants.KubeconfigFilePath); err != nil {
-		return errors.Wrapf(err, "Failed to copy kubeconfig file: %s", constants.KubeconfigFilePath)
+	if err := copyKubeconfigFileWithUpdatedUserClientCertAndKey(selfSignedCAKey, selfSignedCACert, kubeconfigFilePath, instanceKubeconfigPath); err != nil {
+		return errors.Wrapf(err, "Failed to copy kubeconfig file: %s", instanceKubeconfigPath)
 	}
-	adminClientCA, err := adminClientCertificate(constants.KubeconfigFilePath)
+	adminClientCA, err := adminClientCertificate(instanceKubeconfigPath)
 	if err != nil {
 		return errors.Wrap(err, "Not able to get user CA")
 	}
-	if err := cluster.EnsureGeneratedClientCAPresentInTheCluster(ctx, ocConfig, sshRunner, selfSignedCACert, adminClientCA); err != nil {
+	if err := cluster.EnsureGeneratedClientCAPresentInTheCluster(ctx, ocConfig, sshRunner, selfSignedCACert, adminClientCA, instanceKubeconfigPath); err != nil {
 		return errors.Wrap(err, "Failed to update user CA to cluster")
 	}
 	return nil
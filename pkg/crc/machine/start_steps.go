@@ -0,0 +1,691 @@
+package machine
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/crc/machine/store"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/oc"
+	"github.com/code-ready/crc/pkg/crc/pipeline"
+	"github.com/code-ready/crc/pkg/crc/services"
+	"github.com/code-ready/crc/pkg/crc/services/dns"
+	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/code-ready/crc/pkg/crc/systemd"
+	"github.com/code-ready/crc/pkg/libmachine"
+	"github.com/code-ready/crc/pkg/libmachine/host"
+	libmachinestate "github.com/code-ready/machine/libmachine/state"
+	"github.com/pkg/errors"
+)
+
+// startRun holds the state shared by the steps of Start, each step fills the
+// fields the following ones need
+type startRun struct {
+	client            *client
+	startConfig       types.StartConfig
+	api               libmachine.API
+	host              *host.Host
+	crcBundleMetadata *bundle.CrcBundleInfo
+	// the instance was found running and goes through the start sequence
+	// without being started again
+	adopting bool
+
+	vmState                libmachinestate.State
+	resourceConflicts      []types.ResourceConflict
+	resourceChanges        []types.ResourceChange
+	instanceIP             string
+	instanceIPv6           string
+	sshRunner              *crcssh.Runner
+	proxyConfig            *network.ProxyConfig
+	servicePostStartConfig services.ServicePostStartConfig
+	certsExpired           map[string]bool
+	ocConfig               oc.Config
+	clusterConfig          *types.ClusterConfig
+	// CA of the certificate the API server serves for the hostname of the
+	// named profile, nil until it is served
+	profileCACert *x509.Certificate
+}
+
+func (run *startRun) close() {
+	if run.sshRunner != nil {
+		run.sshRunner.Close()
+	}
+}
+
+// steps returns the start sequence of a created instance, from the start of
+// the VM to the availability of the cluster
+func (run *startRun) steps() []pipeline.Step {
+	client := run.client
+	return []pipeline.Step{
+		{Name: "expose-ports", Run: run.exposePorts, Skip: not(client.useVSock)},
+		{Name: "start-vm", Run: run.startVM},
+		// the driver may not know the address of the VM right after its start
+		{Name: "connect-vm", Run: run.connectVM, Retries: connectVMRetries, RetryInterval: 2 * time.Second},
+		{Name: "wait-for-ssh", Run: run.waitForSSH},
+		{Name: "rotate-ssh-key", Run: run.rotateSSHKey, Skip: not(run.sshKeyRotationEnabled)},
+		{Name: "update-ssh-key", Run: run.updateSSHKey},
+		{Name: "grow-filesystem", Run: run.growFilesystem},
+		{Name: "mount-shared-dirs", Run: run.mountSharedDirs, Skip: not(run.hasSharedDirs)},
+		{Name: "mount-registry-storage", Run: run.mountRegistryStorage, Skip: run.bundleRegistryStorage},
+		{Name: "stop-ntp", Run: run.stopNtp, Skip: not(run.stopNtpRequested)},
+		{Name: "configure-mtu", Run: run.configureMTU, Skip: client.useVSock},
+		{Name: "configure-nameservers", Run: run.configureNameServers},
+		{Name: "podman-socket", Run: run.podmanSocket},
+		{Name: "start-dns", Run: run.startDNS},
+		{Name: "check-dns", Run: run.checkDNS},
+		{Name: "pull-secret-on-disk", Run: run.pullSecretOnDisk, Skip: not(run.isOpenShift47)},
+		{Name: "check-certs", Run: run.checkCerts},
+		{Name: "node-overlays", Run: run.nodeOverlays},
+		{Name: "node-proxy", Run: run.nodeProxy},
+		{Name: "start-kubelet", Run: run.startKubelet},
+		{Name: "renew-certs", Run: run.renewCerts},
+		{Name: "wait-for-apiserver", Run: run.waitForAPIServer},
+		{Name: "delete-mco-lease", Run: run.deleteMCOLeaderLease},
+		// the API server can still reject requests right after it is up
+		{Name: "pull-secret", Run: run.pullSecret, Retries: clusterStepRetries, RetryInterval: clusterStepRetryInterval},
+		{Name: "ssh-key-in-cluster", Run: run.sshKeyInCluster, Retries: clusterStepRetries, RetryInterval: clusterStepRetryInterval},
+		{Name: "wait-for-pull-secret-on-disk", Run: run.waitForPullSecretOnDisk},
+		{Name: "proxy", Run: run.proxy},
+		{Name: "image-mirrors", Run: run.imageMirrors},
+		{Name: "registry-storage", Run: run.registryStorage},
+		{Name: "log-forwarding", Run: run.logForwarding},
+		{Name: "kubeadmin-password", Run: run.kubeadminPassword, Retries: clusterStepRetries, RetryInterval: clusterStepRetryInterval},
+		{Name: "cluster-id", Run: run.clusterID, Retries: clusterStepRetries, RetryInterval: clusterStepRetryInterval},
+		{Name: "profile-certs", Run: run.profileCerts, Skip: run.client.profile().IsDefault},
+		{Name: "routes-controller", Run: run.routesController, Skip: not(client.useVSock)},
+		{Name: "monitoring", Run: run.monitoring, Skip: not(client.monitoringEnabled)},
+		{Name: "aggregator-client-ca", Run: run.aggregatorClientCA, Skip: run.aggregatorClientCAValid},
+		{Name: "update-kubeconfig", Run: run.updateKubeconfig},
+		{Name: "wait-for-cluster-stable", Run: run.waitForClusterStable},
+		{Name: "wait-for-proxy", Run: run.waitForProxy},
+		{Name: "pre-pull-images", Run: run.prePullImages, Timeout: prePullImagesTimeout},
+		{Name: "cluster-config", Run: run.getClusterConfig},
+		{Name: "wait-for-login", Run: run.waitForLogin},
+		{Name: "write-kubeconfig", Run: run.writeKubeconfig},
+	}
+}
+
+func not(condition func() bool) func() bool {
+	return func() bool {
+		return !condition()
+	}
+}
+
+// stopNtpRequested tells if the network time synchronization of the VM is
+// stopped, it would undo the clock offset set by ShiftClock
+func (run *startRun) stopNtpRequested() bool {
+	stopNtp, _ := strconv.ParseBool(os.Getenv("CRC_DEBUG_ENABLE_STOP_NTP"))
+	return stopNtp || run.client.clockOffset() != 0
+}
+
+func (run *startRun) exposePorts(_ context.Context) error {
+	return exposePorts()
+}
+
+func (run *startRun) startVM(ctx context.Context) error {
+	if run.adopting {
+		run.resourceConflicts = run.client.checkRunningVMResources(run.host, run.startConfig)
+		return nil
+	}
+	logging.Infof("Starting CodeReady Containers VM for OpenShift %s...", run.crcBundleMetadata.GetOpenshiftVersion())
+
+	resourceChanges, resourceConflicts, err := run.client.updateVMConfig(run.startConfig, run.api, run.host)
+	if err != nil {
+		return errors.Wrap(err, "Could not update CRC VM configuration")
+	}
+	run.resourceChanges = resourceChanges
+	run.resourceConflicts = resourceConflicts
+
+	if err := configureSharedDirs(run.client.name, run.client.sharedDirs()); err != nil {
+		return errors.Wrap(err, "Could not share the directories with the VM")
+	}
+	if err := run.client.configureRegistryDisk(); err != nil {
+		return errors.Wrap(err, "Could not attach the registry disk to the VM")
+	}
+	if err := loadDiskPassphrase(run.host); err != nil {
+		return errors.Wrap(err, "Could not unlock the encrypted disk of the VM")
+	}
+
+	if err := startHost(ctx, run.api, run.host); err != nil {
+		return errors.Wrap(err, "Error starting machine")
+	}
+	return nil
+}
+
+func (run *startRun) connectVM(_ context.Context) error {
+	vmState, err := run.host.Driver.GetState()
+	if err != nil {
+		return errors.Wrap(err, "Error getting the state")
+	}
+	if vmState != libmachinestate.Running {
+		return errors.New("CodeReady Containers VM is not running")
+	}
+	run.vmState = vmState
+
+	run.instanceIP, err = getIP(run.host, run.client.useVSock())
+	if err != nil {
+		return errors.Wrap(err, "Error getting the IP")
+	}
+	logging.Infof("CodeReady Containers instance is running with IP %s", run.instanceIP)
+	run.sshRunner, err = run.client.createSSHRunner(run.instanceIP, getSSHPort(run.client.useVSock()), run.crcBundleMetadata, run.crcBundleMetadata.GetSSHKeyPath(), run.client.profile().PrivateKeyPath(), run.client.profile().RsaPrivateKeyPath())
+	if err != nil {
+		return errors.Wrap(err, "Error creating the ssh client")
+	}
+	return nil
+}
+
+func (run *startRun) waitForSSH(ctx context.Context) error {
+	logging.Debug("Waiting until ssh is available")
+	if err := run.client.waitForSSH(ctx, run.host, run.sshRunner); err != nil {
+		return err
+	}
+	logging.Info("CodeReady Containers VM is running")
+	return nil
+}
+
+func (run *startRun) sshKeyRotationEnabled() bool {
+	return crcConfig.GetSSHKeyRotationPolicy(run.client.config).Enabled()
+}
+
+// rotateSSHKey replaces the SSH key pair according to the rotation policy, a
+// failure keeps the current key pair and does not prevent the start unless
+// the local key pair is left inconsistent
+func (run *startRun) rotateSSHKey(_ context.Context) error {
+	connect := func(privateKey string) (*crcssh.Runner, error) {
+		return run.client.createSSHRunner(run.instanceIP, getSSHPort(run.client.useVSock()), run.crcBundleMetadata, privateKey)
+	}
+	policy := crcConfig.GetSSHKeyRotationPolicy(run.client.config)
+	err := rotateSSHKeyPair(run.sshRunner, run.crcBundleMetadata.GetSSHUserHomeDir(), run.client.profile(), policy, connect)
+	if errors.Is(err, errSSHKeyPairMismatch) {
+		return err
+	}
+	if err != nil {
+		logging.Warnf("Failed to rotate the SSH key pair, the current one is kept: %v", err)
+	}
+	return nil
+}
+
+// Post VM start immediately update SSH key and copy kubeconfig to instance
+// dir and VM
+func (run *startRun) updateSSHKey(_ context.Context) error {
+	if err := updateSSHKeyPair(run.sshRunner, run.crcBundleMetadata.GetSSHUserHomeDir(), run.client.profile().PublicKeyPath()); err != nil {
+		return errors.Wrap(err, "Error updating public key")
+	}
+	return nil
+}
+
+// Trigger disk resize, this will be a no-op if no disk size change is needed
+func (run *startRun) growFilesystem(_ context.Context) error {
+	if err := growRootFileSystem(run.sshRunner); err != nil {
+		return errors.Wrap(err, "Error updating filesystem size")
+	}
+	return nil
+}
+
+func (run *startRun) hasSharedDirs() bool {
+	return len(run.client.sharedDirs()) > 0
+}
+
+func (run *startRun) mountSharedDirs(_ context.Context) error {
+	return mountSharedDirs(run.sshRunner, run.client.sharedDirs())
+}
+
+func (run *startRun) bundleRegistryStorage() bool {
+	return run.client.registryStorage().Type == crcConfig.BundleRegistryStorage
+}
+
+// mountRegistryStorage mounts the registry disk, the host directory storing
+// the images is mounted with the other shared directories
+func (run *startRun) mountRegistryStorage(_ context.Context) error {
+	if run.client.registryStorage().Type == crcConfig.DiskRegistryStorage {
+		if err := mountRegistryDisk(run.sshRunner); err != nil {
+			return err
+		}
+	}
+	return prepareRegistryStorage(run.sshRunner)
+}
+
+// Stop network time synchronization when `CRC_DEBUG_ENABLE_STOP_NTP` is set
+// or the clock of the VM was shifted
+func (run *startRun) stopNtp(_ context.Context) error {
+	logging.Info("Stopping network time synchronization in CodeReady Containers VM")
+	if _, _, err := run.sshRunner.RunPrivileged("Turning off the ntp server", "timedatectl set-ntp off"); err != nil {
+		return errors.Wrap(err, "Failed to stop network time synchronization")
+	}
+	now := time.Now()
+	if offset := run.client.clockOffset(); offset != 0 {
+		logging.Infof("Setting clock to host clock shifted by %s", offset)
+		now = now.Add(offset)
+	} else {
+		logging.Info("Setting clock to host clock (UTC timezone)")
+	}
+	dateCmd := fmt.Sprintf("date -s '%s'", now.Format(time.UnixDate))
+	if _, _, err := run.sshRunner.RunPrivileged("Setting clock same as host", dateCmd); err != nil {
+		return errors.Wrap(err, "Failed to set clock to same as host")
+	}
+	return nil
+}
+
+// configureMTU lowers the MTU of the VM to the one of the host network, VPNs
+// with a small MTU otherwise cause TLS handshakes to hang. The user mode
+// network stack terminates the connections on the host, it does not need it.
+func (run *startRun) configureMTU(_ context.Context) error {
+	hostMTU, err := network.HostMTU()
+	if err != nil {
+		logging.Debugf("Cannot detect the MTU of the host network: %v", err)
+	}
+	mtu, clamped := network.InstanceMTU(run.client.networkMTU(), hostMTU)
+	if clamped {
+		logging.Warnf("The MTU of the host network is %d, the MTU of the VM is lowered from %d and the TCP MSS of the cluster traffic is clamped, set '%s' to override it",
+			hostMTU, network.DefaultMTU, crcConfig.NetworkMTU)
+	}
+	if err := network.ConfigureInstanceMTU(run.sshRunner, mtu); err != nil {
+		return errors.Wrap(err, "Failed to configure the MTU of the VM")
+	}
+	return nil
+}
+
+// Reconcile the nameservers of the VM with the ones configured by the user
+func (run *startRun) configureNameServers(_ context.Context) error {
+	if err := run.client.reconcileNameServers(run.sshRunner, run.startConfig.NameServer); err != nil {
+		return errors.Wrap(err, "Failed to configure the nameservers of the VM")
+	}
+	return nil
+}
+
+func (run *startRun) podmanSocket(_ context.Context) error {
+	if _, _, err := run.sshRunner.RunPrivileged("make root Podman socket accessible", "chmod 777 /run/podman/ /run/podman/podman.sock"); err != nil {
+		return errors.Wrap(err, "Failed to change permissions to root podman socket")
+	}
+	return nil
+}
+
+// startDNS runs the DNS server inside the VM
+func (run *startRun) startDNS(ctx context.Context) error {
+	var err error
+	run.instanceIPv6, err = run.client.instanceIPv6(ctx, run.sshRunner)
+	if err != nil {
+		return err
+	}
+
+	run.proxyConfig, err = run.client.clusterProxyConfig(run.crcBundleMetadata.ClusterInfo.BaseDomain, run.instanceIP, run.instanceIPv6)
+	if err != nil {
+		return errors.Wrap(err, "Error getting proxy configuration")
+	}
+
+	// Create servicePostStartConfig for DNS checks and DNS start.
+	run.servicePostStartConfig, err = run.client.servicePostStartConfig(run.sshRunner, run.crcBundleMetadata, run.instanceIP, run.instanceIPv6)
+	if err != nil {
+		return err
+	}
+
+	if err := dns.RunPostStart(run.servicePostStartConfig); err != nil {
+		return errors.Wrap(err, "Error running post start")
+	}
+	return nil
+}
+
+// servicePostStartConfig describes the VM at ip and ipv6 to the DNS services
+func (client *client) servicePostStartConfig(sshRunner *crcssh.Runner, bundleInfo *bundle.CrcBundleInfo, ip, ipv6 string) (services.ServicePostStartConfig, error) {
+	forwardZones, err := client.dnsForwardZones()
+	if err != nil {
+		return services.ServicePostStartConfig{}, errors.Wrap(err, "Invalid DNS forward zones configuration")
+	}
+	return services.ServicePostStartConfig{
+		Name: client.name,
+		// TODO: would prefer passing in a more generic type
+		SSHRunner: sshRunner,
+		IP:        ip,
+		IPv6:      ipv6,
+		Domains: services.ClusterDomains{
+			ClusterName: bundleInfo.ClusterInfo.ClusterName,
+			BaseDomain:  bundleInfo.ClusterInfo.BaseDomain,
+			AppsDomain:  bundleInfo.ClusterInfo.AppsDomain,
+
+			ProfileDomain: client.profile().HostDomain(),
+		},
+		Node: services.Node{
+			Hostname:   bundleInfo.Nodes[0].Hostname,
+			InternalIP: bundleInfo.Nodes[0].InternalIP,
+		},
+		NetworkMode:  client.networkMode(),
+		ForwardZones: forwardZones,
+		HostDNS:      client.hostDNS() && client.networkMode() == network.SystemNetworkingMode,
+	}, nil
+}
+
+// instanceIPs returns the addresses of the VM, the IPv6 one only on
+// dual-stack networks
+func (run *startRun) instanceIPs() []string {
+	if run.instanceIPv6 == "" {
+		return []string{run.instanceIP}
+	}
+	return []string{run.instanceIP, run.instanceIPv6}
+}
+
+// checkDNS checks DNS lookups before starting the kubelet, the severity of
+// each check is configurable
+func (run *startRun) checkDNS(ctx context.Context) error {
+	if severity := run.client.dnsCheckSeverity(crcConfig.DNSCheckInternal); severity != crcConfig.SkipCheck {
+		if queryOutput, err := dns.CheckCRCLocalDNSReachable(ctx, run.servicePostStartConfig); err != nil {
+			if err := checkFailed(severity, errors.Wrapf(err, "Failed internal DNS query: %s", queryOutput)); err != nil {
+				return err
+			}
+		}
+	}
+	logging.Info("Check internal and public DNS query...")
+
+	if severity := run.client.dnsCheckSeverity(crcConfig.DNSCheckPublic); severity != crcConfig.SkipCheck {
+		if queryOutput, err := dns.CheckCRCPublicDNSReachable(run.servicePostStartConfig); err != nil {
+			if err := checkFailed(severity, errors.Wrapf(err, "Failed public DNS query from the cluster: %s", queryOutput)); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Check DNS lookup from host to VM
+	if severity := run.client.dnsCheckSeverity(crcConfig.DNSCheckHost); severity != crcConfig.SkipCheck {
+		logging.Info("Check DNS query from host...")
+		if err := network.CheckCRCLocalDNSReachableFromHost(run.crcBundleMetadata, run.instanceIPs()...); err != nil {
+			if err := checkFailed(severity, errors.Wrap(err, "Failed to query DNS from host")); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkFailed returns err when the failed check must stop the start, it
+// only logs it otherwise
+func checkFailed(severity crcConfig.CheckSeverity, err error) error {
+	if severity == crcConfig.FailCheck {
+		return err
+	}
+	logging.Warn(err.Error())
+	return nil
+}
+
+// Remove this step after 2-3 release (after v1.32.0)
+// This is just to support 4.7 bundle with current master
+func (run *startRun) isOpenShift47() bool {
+	return strings.HasPrefix(run.crcBundleMetadata.GetOpenshiftVersion(), "4.7.")
+}
+
+func (run *startRun) pullSecretOnDisk(_ context.Context) error {
+	if err := cluster.EnsurePullSecretPresentOnInstanceDisk(run.sshRunner, run.startConfig.PullSecret); err != nil {
+		return errors.Wrap(err, "Failed to update VM pull secret")
+	}
+	return nil
+}
+
+// checkCerts checks the certs validity inside the vm
+func (run *startRun) checkCerts(_ context.Context) error {
+	logging.Info("Verifying validity of the kubelet certificates...")
+	certsExpiry, err := cluster.GetCertsExpiry(run.sshRunner)
+	if err != nil {
+		return errors.Wrap(err, "Failed to check certificate validity")
+	}
+	if err := store.ForInstance(run.client.name).SetCertsExpiry(certsExpiry); err != nil {
+		logging.Debugf("Cannot record certificates expiry: %v", err)
+	}
+	run.certsExpired = cluster.CheckCertsValidity(certsExpiry, run.client.now())
+	if err := cluster.CheckCertsNotBefore(run.sshRunner, run.client.now()); err != nil {
+		return errors.Wrap(err, "The certificates were renewed while the clock of the VM was shifted, shift it again with 'crc shift-clock' or delete the instance")
+	}
+	return nil
+}
+
+func (run *startRun) nodeOverlays(_ context.Context) error {
+	nodeOverlays, err := crcConfig.GetNodeOverlays(run.client.config)
+	if err != nil {
+		return err
+	}
+	if err := cluster.EnsureNodeOverlays(run.sshRunner, nodeOverlays); err != nil {
+		return errors.Wrap(err, "Failed to apply the kubelet and crio configuration overlays")
+	}
+	return nil
+}
+
+func (run *startRun) nodeProxy(_ context.Context) error {
+	if err := cluster.EnsureNodeProxyEnvironment(run.sshRunner, run.proxyConfig); err != nil {
+		return errors.Wrap(err, "Failed to update the proxy configuration of the kubelet and crio")
+	}
+	return nil
+}
+
+func (run *startRun) startKubelet(_ context.Context) error {
+	logging.Info("Starting OpenShift kubelet service")
+	sd := systemd.NewInstanceSystemdCommander(run.sshRunner)
+	if err := sd.Start("kubelet"); err != nil {
+		return errors.Wrap(err, "Error starting kubelet")
+	}
+	if err := store.ForInstance(run.client.name).SetHibernated(false); err != nil {
+		logging.Debugf("Cannot record hibernation state: %v", err)
+	}
+	run.ocConfig = oc.UseOCWithSSH(run.sshRunner)
+	return nil
+}
+
+func (run *startRun) renewCerts(ctx context.Context) error {
+	if err := cluster.ApproveCSRAndWaitForCertsRenewal(ctx, run.sshRunner, run.ocConfig, run.certsExpired[cluster.KubeletClientCert], run.certsExpired[cluster.KubeletServerCert]); err != nil {
+		logBundleDate(run.crcBundleMetadata)
+		return errors.Wrap(err, "Failed to renew TLS certificates: please check if a newer CodeReady Containers release is available")
+	}
+	return nil
+}
+
+func (run *startRun) waitForAPIServer(ctx context.Context) error {
+	if err := cluster.WaitForAPIServer(ctx, run.ocConfig); err != nil {
+		return errors.Wrap(err, "Error waiting for apiserver")
+	}
+	return nil
+}
+
+func (run *startRun) deleteMCOLeaderLease(ctx context.Context) error {
+	return cluster.DeleteMCOLeaderLease(ctx, run.ocConfig)
+}
+
+func (run *startRun) pullSecret(ctx context.Context) error {
+	if err := cluster.EnsurePullSecretPresentInTheCluster(ctx, run.ocConfig, run.startConfig.PullSecret); err != nil {
+		return errors.Wrap(err, "Failed to update cluster pull secret")
+	}
+	return nil
+}
+
+func (run *startRun) sshKeyInCluster(ctx context.Context) error {
+	if err := cluster.EnsureSSHKeyPresentInTheCluster(ctx, run.ocConfig, run.client.profile().PublicKeyPath()); err != nil {
+		return errors.Wrap(err, "Failed to update ssh public key to machine config")
+	}
+	return nil
+}
+
+func (run *startRun) waitForPullSecretOnDisk(ctx context.Context) error {
+	if err := cluster.WaitForPullSecretPresentOnInstanceDisk(ctx, run.sshRunner); err != nil {
+		return errors.Wrap(err, "Failed to update pull secret on the disk")
+	}
+	return nil
+}
+
+func (run *startRun) proxy(ctx context.Context) error {
+	if err := cluster.EnsureProxyConfigInCluster(ctx, run.sshRunner, run.ocConfig, run.proxyConfig); err != nil {
+		return errors.Wrap(err, "Failed to update cluster proxy configuration")
+	}
+	return nil
+}
+
+func (run *startRun) registryStorage(ctx context.Context) error {
+	path := ""
+	if !run.bundleRegistryStorage() {
+		path = registryStorageMountPoint
+	}
+	if err := cluster.EnsureRegistryStorage(ctx, run.sshRunner, run.ocConfig, path); err != nil {
+		return errors.Wrap(err, "Failed to configure the storage of the internal registry")
+	}
+	return nil
+}
+
+func (run *startRun) imageMirrors(ctx context.Context) error {
+	imageMirrors, err := run.client.imageMirrors()
+	if err != nil {
+		return err
+	}
+	if err := cluster.EnsureImageMirrorsInTheCluster(ctx, run.sshRunner, run.ocConfig, imageMirrors); err != nil {
+		return errors.Wrap(err, "Failed to update cluster image mirrors")
+	}
+	return nil
+}
+
+func (run *startRun) logForwarding(ctx context.Context) error {
+	if err := cluster.EnsureLogForwardingInTheCluster(ctx, run.sshRunner, run.ocConfig, run.client.logForwarding()); err != nil {
+		logging.Warnf("Failed to configure log forwarding: %v", err)
+	}
+	return nil
+}
+
+func (run *startRun) kubeadminPassword(ctx context.Context) error {
+	if err := cluster.UpdateKubeAdminUserPassword(ctx, run.ocConfig, kubeAdminPasswordStore(run.client.profile()), run.startConfig.KubeAdminPassword); err != nil {
+		return errors.Wrap(err, "Failed to update kubeadmin user password")
+	}
+	return nil
+}
+
+func (run *startRun) clusterID(ctx context.Context) error {
+	if err := run.client.ensureClusterID(ctx, run.ocConfig, run.startConfig.PullSecret); err != nil {
+		return errors.Wrap(err, "Failed to update cluster ID")
+	}
+	return nil
+}
+
+func (run *startRun) profileCerts(ctx context.Context) error {
+	instanceProfile := run.client.profile()
+	caKey, caCert, err := profileCA(instanceProfile)
+	if err != nil {
+		return errors.Wrap(err, "Cannot get the CA of the profile")
+	}
+	if err := cluster.EnsureProfileCertificates(ctx, run.sshRunner, run.ocConfig, cluster.ProfileCertificates{
+		APIHostname: instanceProfile.APIHostname(),
+		AppsDomain:  strings.TrimPrefix(instanceProfile.AppsDomain(), "."),
+		CACert:      caCert,
+		CAKey:       caKey,
+	}); err != nil {
+		return errors.Wrap(err, "Failed to configure the certificates of the profile")
+	}
+	logging.Infof("Waiting for the API server to serve the certificate of %s...", instanceProfile.APIHostname())
+	if err := cluster.WaitForProfileAPICertificate(ctx, run.instanceIP, instanceProfile.APIHostname(), caCert, profileCertTimeout); err != nil {
+		logging.Warnf("The API server does not serve the certificate of %s yet, the kubeconfig uses the one of %s: %v",
+			instanceProfile.APIHostname(), run.crcBundleMetadata.GetAPIHostname(), err)
+		return nil
+	}
+	run.profileCACert = caCert
+	return nil
+}
+
+func (run *startRun) routesController(_ context.Context) error {
+	return ensureRoutesControllerIsRunning(run.sshRunner, run.ocConfig)
+}
+
+func (run *startRun) monitoring(_ context.Context) error {
+	logging.Info("Enabling cluster monitoring operator...")
+	if err := cluster.StartMonitoring(run.ocConfig); err != nil {
+		return errors.Wrap(err, "Cannot start monitoring stack")
+	}
+	return nil
+}
+
+// In Openshift 4.3, when cluster comes up, the following happens
+// 1. After the openshift-apiserver pod is started, its log contains multiple occurrences of `certificate has expired or is not yet valid`
+// 2. Initially there is no request-header's client-ca crt available to `extension-apiserver-authentication` configmap
+// 3. In the pod logs `missing content for CA bundle "client-ca::kube-system::extension-apiserver-authentication::requestheader-client-ca-file"`
+// 4. After ~1 min /etc/kubernetes/static-pod-resources/kube-apiserver-certs/configmaps/aggregator-client-ca/ca-bundle.crt is regenerated
+// 5. It is now also appear to `extension-apiserver-authentication` configmap as part of request-header's client-ca content
+// 6. Openshift-apiserver is able to load the CA which was regenerated
+// 7. Now apiserver pod log contains multiple occurrences of `error x509: certificate signed by unknown authority`
+// When the openshift-apiserver is in this state, the cluster is non functional.
+// A restart of the openshift-apiserver pod is enough to clear that error and get a working cluster.
+// This is a work-around while the root cause is being identified.
+// More info: https://bugzilla.redhat.com/show_bug.cgi?id=1795163
+func (run *startRun) aggregatorClientCA(ctx context.Context) error {
+	logging.Debug("Waiting for the renewal of the request header client ca...")
+	if err := cluster.WaitForRequestHeaderClientCaFile(ctx, run.sshRunner); err != nil {
+		return errors.Wrap(err, "Failed to wait for aggregator client ca renewal")
+	}
+
+	if err := cluster.DeleteOpenshiftAPIServerPods(ctx, run.ocConfig); err != nil {
+		return errors.Wrap(err, "Cannot delete OpenShift API Server pods")
+	}
+	return nil
+}
+
+func (run *startRun) aggregatorClientCAValid() bool {
+	return !run.certsExpired[cluster.AggregatorClientCert]
+}
+
+func (run *startRun) updateKubeconfig(ctx context.Context) error {
+	if err := updateKubeconfig(ctx, run.ocConfig, run.sshRunner, run.crcBundleMetadata.GetKubeConfigPath(), run.client.profile().KubeconfigPath()); err != nil {
+		return errors.Wrap(err, "Failed to update kubeconfig file")
+	}
+	return nil
+}
+
+func (run *startRun) waitForClusterStable(ctx context.Context) error {
+	logging.Info("Starting OpenShift cluster... [waiting for the cluster to stabilize]")
+	monitorCtx, stopMonitoring := context.WithCancel(ctx)
+	defer stopMonitoring()
+	go cluster.MonitorResourceUsage(monitorCtx, run.sshRunner)
+	go cluster.MonitorEvents(monitorCtx, run.ocConfig)
+	if err := cluster.WaitForClusterStable(ctx, run.instanceIP, run.client.profile().KubeconfigPath(), run.proxyConfig, run.client.readinessOperators()); err != nil {
+		logging.Errorf("Cluster is not ready: %v", err)
+	}
+	return nil
+}
+
+func (run *startRun) waitForProxy(ctx context.Context) error {
+	waitForProxyPropagation(ctx, run.ocConfig, run.proxyConfig)
+	return nil
+}
+
+func (run *startRun) prePullImages(ctx context.Context) error {
+	if images := run.client.prePullImages(); len(images) > 0 {
+		logging.Infof("Pre-pulling %d container images...", len(images))
+		if err := cluster.PullImages(ctx, run.sshRunner, images); err != nil {
+			logging.Warnf("Failed to pre-pull container images: %v", err)
+		}
+	}
+	return nil
+}
+
+func (run *startRun) getClusterConfig(_ context.Context) error {
+	clusterConfig, err := getClusterConfig(run.client.profile(), run.crcBundleMetadata)
+	if err != nil {
+		return errors.Wrap(err, "Cannot get cluster configuration")
+	}
+	run.client.updateClusterConfigFromCluster(clusterConfig, run.ocConfig)
+	run.clusterConfig = clusterConfig
+	return nil
+}
+
+func (run *startRun) waitForLogin(ctx context.Context) error {
+	logging.Info("Waiting for user login to be available...")
+	if err := waitForLogin(ctx, run.instanceIP, run.clusterConfig, loginTimeout); err != nil {
+		logging.Errorf("Login is not available yet, 'oc login' may fail: %v", err)
+	}
+	return nil
+}
+
+func (run *startRun) writeKubeconfig(_ context.Context) error {
+	logging.Info("Adding crc-admin and crc-developer contexts to kubeconfig...")
+	if err := writeKubeconfig(run.client.name, run.instanceIP, run.clusterConfig, run.profileCACert); err != nil {
+		logging.Errorf("Cannot update kubeconfig: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartStepsHaveUniqueNames(t *testing.T) {
+	names := map[string]bool{}
+	for _, step := range (&startRun{client: &client{}}).steps() {
+		assert.NotEmpty(t, step.Name)
+		assert.NotNil(t, step.Run, step.Name)
+		assert.False(t, names[step.Name], "duplicate step %s", step.Name)
+		names[step.Name] = true
+	}
+}
+
+func TestStartStepsRetriesAndTimeouts(t *testing.T) {
+	steps := map[string]int{}
+	run := &startRun{client: &client{}}
+	all := run.steps()
+	for i, step := range all {
+		steps[step.Name] = i
+	}
+	assert.Equal(t, connectVMRetries, all[steps["connect-vm"]].Retries)
+	for _, name := range []string{"pull-secret", "ssh-key-in-cluster", "kubeadmin-password", "cluster-id"} {
+		assert.Equal(t, clusterStepRetries, all[steps[name]].Retries, name)
+		assert.Equal(t, clusterStepRetryInterval, all[steps[name]].RetryInterval, name)
+	}
+	assert.Equal(t, prePullImagesTimeout, all[steps["pre-pull-images"]].Timeout)
+}
@@ -0,0 +1,50 @@
+package machine
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadBundle(t *testing.T) {
+	content := []byte("bundle content")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest/crc.crcbundle":
+			_, _ = w.Write(content)
+		case "/latest/crc.crcbundle.sig":
+			_, _ = w.Write([]byte("signature"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	bundlePath := filepath.Join(dir, "crc_libvirt_4.6.1.crcbundle")
+	require.NoError(t, downloadBundle(bundlePath, bundleSource{
+		urls:      []string{server.URL + "/latest/crc.crcbundle"},
+		sha256:    fmt.Sprintf("%x", sha256.Sum256(content)),
+		signature: true,
+	}))
+	downloaded, err := ioutil.ReadFile(bundlePath)
+	require.NoError(t, err)
+	assert.Equal(t, content, downloaded)
+	assert.FileExists(t, bundlePath+".sig")
+	assert.NoFileExists(t, bundlePath+".part")
+
+	otherPath := filepath.Join(dir, "crc_libvirt_4.7.0.crcbundle")
+	assert.Error(t, downloadBundle(otherPath, bundleSource{
+		urls:   []string{server.URL + "/latest/crc.crcbundle"},
+		sha256: fmt.Sprintf("%x", sha256.Sum256([]byte("other content"))),
+	}))
+	assert.NoFileExists(t, otherPath)
+	assert.NoFileExists(t, otherPath+".part")
+}
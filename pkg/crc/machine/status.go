@@ -2,18 +2,25 @@ package machine
 
 import (
 	"context"
+	"time"
 
 	"github.com/code-ready/crc/pkg/crc/cluster"
-	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/bundle"
 	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/store"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
-	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
 	libmachinestate "github.com/code-ready/machine/libmachine/state"
 	"github.com/pkg/errors"
 )
 
+const (
+	// certificates expiring within this period are reported as needing renewal
+	certsRenewalWarningPeriod = 7 * 24 * time.Hour
+	// validity of the certificates shipped in the bundles
+	bundleCertsValidity = 30 * 24 * time.Hour
+)
+
 func (client *client) Status() (*types.ClusterStatusResult, error) {
 	libMachineAPIClient, cleanup := createLibMachineClient()
 	defer cleanup()
@@ -43,11 +50,30 @@ func (client *client) Status() (*types.ClusterStatusResult, error) {
 		return nil, errors.Wrap(err, "Error loading bundle metadata")
 	}
 
+	hypervisor := client.getHypervisorResources(host)
+	clusterID := client.getRecordedClusterID()
+	lastStart := client.getRecordedLastStart()
+	bundleBuildTime, err := crcBundleMetadata.GetBundleBuildTime()
+	if err != nil {
+		logging.Debugf("Cannot get the bundle build time: %v", err)
+	}
 	if vmStatus != libmachinestate.Running {
+		openshiftStatus := types.OpenshiftStopped
+		if vmStatus == libmachinestate.Paused {
+			openshiftStatus = types.OpenshiftPaused
+		}
+		certsExpiry := client.getRecordedCertsExpiry()
 		return &types.ClusterStatusResult{
-			CrcStatus:        state.FromMachine(vmStatus),
-			OpenshiftStatus:  types.OpenshiftStopped,
-			OpenshiftVersion: crcBundleMetadata.GetOpenshiftVersion(),
+			CrcStatus:          state.FromMachine(vmStatus),
+			OpenshiftStatus:    openshiftStatus,
+			OpenshiftVersion:   crcBundleMetadata.GetOpenshiftVersion(),
+			CertsExpiry:        certsExpiry,
+			CertsRenewalNeeded: certsRenewalNeeded(certsExpiry, client.now()),
+			ClusterID:          clusterID,
+			Hypervisor:         hypervisor,
+			LastStart:          lastStart,
+			BundleBuildTime:    bundleBuildTime,
+			BundleOutdated:     bundleOutdated(bundleBuildTime, time.Now()),
 		}, nil
 	}
 
@@ -57,18 +83,113 @@ func (client *client) Status() (*types.ClusterStatusResult, error) {
 	}
 
 	diskSize, diskUse := client.getDiskDetails(ip, crcBundleMetadata)
+	certsExpiry := client.getCertsExpiry(ip, crcBundleMetadata)
+	var bootTime time.Time
+	hypervisor.UsedMemory, hypervisor.CPUSteal, bootTime = client.getGuestUsage(ip, crcBundleMetadata)
+	openshiftStatus, operators := client.openshiftStatus(context.Background(), ip)
 	return &types.ClusterStatusResult{
-		CrcStatus:        state.Running,
-		OpenshiftStatus:  getOpenShiftStatus(context.Background(), ip),
-		OpenshiftVersion: crcBundleMetadata.GetOpenshiftVersion(),
-		DiskUse:          diskUse,
-		DiskSize:         diskSize,
+		CrcStatus:          state.Running,
+		OpenshiftStatus:    openshiftStatus,
+		OpenshiftVersion:   crcBundleMetadata.GetOpenshiftVersion(),
+		DiskUse:            diskUse,
+		DiskSize:           diskSize,
+		CertsExpiry:        certsExpiry,
+		CertsRenewalNeeded: certsRenewalNeeded(certsExpiry, client.now()),
+		ClusterID:          clusterID,
+		Hypervisor:         hypervisor,
+		Uptime:             uptime(bootTime, time.Now()),
+		LastStart:          lastStart,
+		BundleBuildTime:    bundleBuildTime,
+		BundleOutdated:     bundleOutdated(bundleBuildTime, time.Now()),
+		Operators:          operators,
 	}, nil
 }
 
+// getCertsExpiry returns the expiry date of the first cluster certificate to
+// expire, as checked in the VM, or as recorded when the VM cannot be reached
+func (client *client) getCertsExpiry(ip string, bundle *bundle.CrcBundleInfo) time.Time {
+	expiry, err, _ := client.certsExpiry.Memoize("certs", func() (interface{}, error) {
+		sshRunner, err := client.createSSHRunner(ip, getSSHPort(client.useVSock()), bundle, client.profile().PrivateKeyPath(), client.profile().RsaPrivateKeyPath(), bundle.GetSSHKeyPath())
+		if err != nil {
+			return nil, errors.Wrap(err, "Error creating the ssh client")
+		}
+		defer sshRunner.Close()
+		certsExpiry, err := cluster.GetCertsExpiry(sshRunner)
+		if err != nil {
+			return nil, err
+		}
+		if err := store.ForInstance(client.name).SetCertsExpiry(certsExpiry); err != nil {
+			logging.Debugf("Cannot record certificates expiry: %v", err)
+		}
+		return firstCertExpiry(certsExpiry), nil
+	})
+	if err != nil {
+		logging.Debugf("Cannot get certificates expiry: %v", err)
+		return client.getRecordedCertsExpiry()
+	}
+	return expiry.(time.Time)
+}
+
+func (client *client) getRecordedCertsExpiry() time.Time {
+	certsExpiry, err := store.ForInstance(client.name).CertsExpiry()
+	if err != nil {
+		logging.Debugf("Cannot read recorded certificates expiry: %v", err)
+		return time.Time{}
+	}
+	return firstCertExpiry(certsExpiry)
+}
+
+func (client *client) getRecordedClusterID() string {
+	clusterID, err := store.ForInstance(client.name).ClusterID()
+	if err != nil {
+		logging.Debugf("Cannot read recorded cluster ID: %v", err)
+	}
+	return clusterID
+}
+
+func (client *client) getRecordedLastStart() time.Time {
+	lastStart, err := store.ForInstance(client.name).LastStart()
+	if err != nil {
+		logging.Debugf("Cannot read recorded start time: %v", err)
+	}
+	return lastStart
+}
+
+// uptime returns the time since bootTime, rounded to the second, zero when
+// the boot time is unknown
+func uptime(bootTime time.Time, now time.Time) time.Duration {
+	if bootTime.IsZero() || bootTime.After(now) {
+		return 0
+	}
+	return now.Sub(bootTime).Round(time.Second)
+}
+
+// bundleOutdated tells if the certificates shipped in a bundle built at
+// buildTime have expired, a new instance then renews them during its first
+// start
+func bundleOutdated(buildTime time.Time, now time.Time) bool {
+	return !buildTime.IsZero() && buildTime.Add(bundleCertsValidity).Before(now)
+}
+
+func firstCertExpiry(certsExpiry map[string]time.Time) time.Time {
+	var first time.Time
+	for _, expiry := range certsExpiry {
+		if first.IsZero() || expiry.Before(first) {
+			first = expiry
+		}
+	}
+	return first
+}
+
+// certsRenewalNeeded tells if the certificates expire within
+// certsRenewalWarningPeriod, in which case their renewal slows down the start
+func certsRenewalNeeded(certsExpiry time.Time, now time.Time) bool {
+	return !certsExpiry.IsZero() && certsExpiry.Before(now.Add(certsRenewalWarningPeriod))
+}
+
 func (client *client) getDiskDetails(ip string, bundle *bundle.CrcBundleInfo) (int64, int64) {
 	disk, err, _ := client.diskDetails.Memoize("disks", func() (interface{}, error) {
-		sshRunner, err := crcssh.CreateRunner(ip, getSSHPort(client.useVSock()), constants.GetPrivateKeyPath(), constants.GetRsaPrivateKeyPath(), bundle.GetSSHKeyPath())
+		sshRunner, err := client.createSSHRunner(ip, getSSHPort(client.useVSock()), bundle, client.profile().PrivateKeyPath(), client.profile().RsaPrivateKeyPath(), bundle.GetSSHKeyPath())
 		if err != nil {
 			return nil, errors.Wrap(err, "Error creating the ssh client")
 		}
@@ -86,19 +207,19 @@ func (client *client) getDiskDetails(ip string, bundle *bundle.CrcBundleInfo) (i
 	return disk.([]int64)[0], disk.([]int64)[1]
 }
 
-func getOpenShiftStatus(ctx context.Context, ip string) types.OpenshiftStatus {
-	status, err := cluster.GetClusterOperatorsStatus(ctx, ip, constants.KubeconfigFilePath)
+func getOpenShiftStatus(ctx context.Context, ip string, kubeconfigPath string) (types.OpenshiftStatus, []cluster.OperatorStatus) {
+	status, err := cluster.GetClusterOperatorsStatus(ctx, ip, kubeconfigPath)
 	if err != nil {
 		logging.Debugf("cannot get OpenShift status: %v", err)
-		return types.OpenshiftUnreachable
+		return types.OpenshiftUnreachable, nil
 	}
 	switch {
 	case status.Progressing:
-		return types.OpenshiftStarting
+		return types.OpenshiftStarting, status.Operators
 	case status.Degraded:
-		return types.OpenshiftDegraded
+		return types.OpenshiftDegraded, status.Operators
 	case status.Available:
-		return types.OpenshiftRunning
+		return types.OpenshiftRunning, status.Operators
 	}
-	return types.OpenshiftStopped
+	return types.OpenshiftStopped, status.Operators
 }
@@ -0,0 +1,38 @@
+package machine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertsRenewalNeeded(t *testing.T) {
+	now := time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)
+	first := now.Add(3 * 24 * time.Hour)
+	certsExpiry := map[string]time.Time{
+		"kubelet-client": now.Add(30 * 24 * time.Hour),
+		"kubelet-server": first,
+	}
+
+	assert.Equal(t, first, firstCertExpiry(certsExpiry))
+	assert.True(t, certsRenewalNeeded(first, now))
+	assert.False(t, certsRenewalNeeded(now.Add(30*24*time.Hour), now))
+	assert.False(t, certsRenewalNeeded(time.Time{}, now))
+}
+
+func TestUptime(t *testing.T) {
+	now := time.Date(2021, time.June, 1, 10, 30, 0, 0, time.UTC)
+
+	assert.Equal(t, 2*time.Hour+30*time.Minute, uptime(now.Add(-2*time.Hour-30*time.Minute), now))
+	assert.Equal(t, time.Duration(0), uptime(time.Time{}, now))
+	assert.Equal(t, time.Duration(0), uptime(now.Add(time.Minute), now))
+}
+
+func TestBundleOutdated(t *testing.T) {
+	now := time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.False(t, bundleOutdated(now.Add(-10*24*time.Hour), now))
+	assert.True(t, bundleOutdated(now.Add(-40*24*time.Hour), now))
+	assert.False(t, bundleOutdated(time.Time{}, now))
+}
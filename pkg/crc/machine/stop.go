@@ -1,29 +1,45 @@
 package machine
 
 import (
-	"github.com/code-ready/crc/pkg/crc/constants"
+	"fmt"
+	"strings"
+
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/state"
-	crcssh "github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/services/dns"
 	"github.com/code-ready/crc/pkg/crc/systemd"
 	"github.com/code-ready/crc/pkg/libmachine/host"
+	libmachinestate "github.com/code-ready/machine/libmachine/state"
 	"github.com/pkg/errors"
 )
 
-func (client *client) Stop() (state.State, error) {
-	if running, _ := client.IsRunning(); !running {
-		return state.Error, errors.New("Cluster is already stopped")
-	}
-
+// Stop shuts the VM down, it does nothing when the VM is already stopped
+func (client *client) Stop() (*types.StopResult, error) {
 	libMachineAPIClient, cleanup := createLibMachineClient()
 	defer cleanup()
 	host, err := libMachineAPIClient.Load(client.name)
 
 	if err != nil {
-		return state.Error, errors.Wrap(err, "Cannot load machine")
+		return &types.StopResult{State: state.Error}, errors.Wrap(err, "Cannot load machine")
 	}
+	vmState, err := host.Driver.GetState()
+	if err != nil {
+		return &types.StopResult{State: state.Error}, errors.Wrap(err, "Cannot get VM status")
+	}
+	switch vmState {
+	case libmachinestate.Stopped:
+		logging.Debug("The VM is already stopped")
+		return &types.StopResult{State: state.Stopped, AlreadyStopped: true}, nil
+	case libmachinestate.Running:
+	default:
+		return &types.StopResult{State: state.FromMachine(vmState)}, fmt.Errorf("Cannot stop the VM, it is %s", strings.ToLower(vmState.String()))
+	}
+
+	client.stopHostServices()
+	client.removeHostRouting(host)
 	if err := stopAllContainers(host, client); err != nil {
-		return state.Error, err
+		return &types.StopResult{State: state.Running}, err
 	}
 	logging.Info("Stopping the OpenShift cluster, this may take a few minutes...")
 	if err := host.Stop(); err != nil {
@@ -31,13 +47,26 @@ func (client *client) Stop() (state.State, error) {
 		if stateErr != nil {
 			logging.Debugf("Cannot get VM status after stopping it: %v", stateErr)
 		}
-		return state.FromMachine(status), errors.Wrap(err, "Cannot stop machine")
+		return &types.StopResult{State: state.FromMachine(status)}, errors.Wrap(err, "Cannot stop machine")
 	}
 	status, err := host.Driver.GetState()
 	if err != nil {
-		return state.Error, errors.Wrap(err, "Cannot get VM status")
+		return &types.StopResult{State: state.Error}, errors.Wrap(err, "Cannot get VM status")
+	}
+	return &types.StopResult{State: state.FromMachine(status)}, nil
+}
+
+// removeHostRouting stops routing the cluster domains of the host to the VM,
+// it must be called before the VM is stopped or removed to know its IP
+func (client *client) removeHostRouting(host *host.Host) {
+	ip, err := getIP(host, client.useVSock())
+	if err != nil {
+		logging.Debugf("Cannot get the IP of the VM: %v", err)
+		ip = ""
+	}
+	if err := dns.RemoveHostRouting(client.name, ip); err != nil {
+		logging.Warnf("Failed to remove the DNS routing of the cluster domains: %v", err)
 	}
-	return state.FromMachine(status), nil
 }
 
 // This should be removed after https://bugzilla.redhat.com/show_bug.cgi?id=1965992
@@ -50,7 +79,11 @@ func stopAllContainers(host *host.Host, client *client) error {
 	if err != nil {
 		return errors.Wrapf(err, "Error getting the IP")
 	}
-	sshRunner, err := crcssh.CreateRunner(instanceIP, getSSHPort(client.useVSock()), constants.GetPrivateKeyPath(), constants.GetRsaPrivateKeyPath())
+	crcBundleMetadata, err := getBundleMetadataFromDriver(host.Driver)
+	if err != nil {
+		return errors.Wrap(err, "Error loading bundle metadata")
+	}
+	sshRunner, err := client.createSSHRunner(instanceIP, getSSHPort(client.useVSock()), crcBundleMetadata, client.profile().PrivateKeyPath(), client.profile().RsaPrivateKeyPath())
 	if err != nil {
 		return errors.Wrapf(err, "Error creating the ssh client")
 	}
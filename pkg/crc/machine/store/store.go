@@ -0,0 +1,449 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+const (
+	stateFilename = "crc-state.json"
+	// version of the state file format, to be increased on incompatible changes
+	currentVersion = 1
+
+	certsExpiryKey     = "certsExpiry"
+	protectionTokenKey = "protectionToken"
+	pendingChangesKey  = "pendingConfigChanges"
+	clusterIDKey       = "clusterID"
+	nameServersKey     = "upstreamNameServers"
+	hibernatedKey      = "hibernated"
+	lastStartKey       = "lastStart"
+	clockOffsetKey     = "clockOffset"
+	activeWarningsKey  = "activeWarnings"
+	clusterURLsKey     = "clusterURLs"
+
+	preservedClusterIDsKey = "preservedClusterIDs"
+	networkIndexesKey      = "networkIndexes"
+)
+
+// Store persists small pieces of data about an instance in a versioned JSON
+// file in the instance directory. The file is replaced atomically, and its
+// updates are serialized between processes with a lock file next to it, as
+// the CLI, the daemon and the tray can use it at the same time.
+type Store struct {
+	path string
+	lock sync.Mutex
+}
+
+type document struct {
+	Version int                        `json:"version"`
+	Entries map[string]json.RawMessage `json:"entries"`
+}
+
+var (
+	storesLock sync.Mutex
+	// stores has a single Store per path, so that the read-modify-write
+	// cycles of the callers using the same file are serialized
+	stores = map[string]*Store{}
+)
+
+// New returns the store persisted in the file at path, the same Store is
+// returned for a given path
+func New(path string) *Store {
+	path = filepath.Clean(path)
+
+	storesLock.Lock()
+	defer storesLock.Unlock()
+	if s, ok := stores[path]; ok {
+		return s
+	}
+	s := &Store{
+		path: path,
+	}
+	stores[path] = s
+	return s
+}
+
+// ForInstance returns the store of the machine with the given name
+func ForInstance(name string) *Store {
+	return New(filepath.Join(constants.MachineInstanceDir, name, stateFilename))
+}
+
+// Global returns the store of the data which outlives the instances
+func Global() *Store {
+	return New(filepath.Join(constants.CrcBaseDir, stateFilename))
+}
+
+// Get unmarshals the value stored for key into value and returns false when there is none
+func (s *Store) Get(key string, value interface{}) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	doc, err := s.load()
+	if err != nil {
+		return false, err
+	}
+	raw, ok := doc.Entries[key]
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, value); err != nil {
+		return false, fmt.Errorf("cannot read %s from %s: %w", key, s.path, err)
+	}
+	return true, nil
+}
+
+func (s *Store) Set(key string, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return s.update(func(doc *document) {
+		doc.Entries[key] = raw
+	})
+}
+
+func (s *Store) Delete(key string) error {
+	return s.update(func(doc *document) {
+		delete(doc.Entries, key)
+	})
+}
+
+// modify reads the value of key into value, calls fn and stores the value
+// back, or deletes the key when fn returns false. Other writers of the store
+// wait until it is done.
+func (s *Store) modify(key string, value interface{}, fn func() bool) error {
+	var modifyErr error
+	err := s.update(func(doc *document) {
+		if raw, ok := doc.Entries[key]; ok {
+			if modifyErr = json.Unmarshal(raw, value); modifyErr != nil {
+				modifyErr = fmt.Errorf("cannot read %s from %s: %w", key, s.path, modifyErr)
+				return
+			}
+		}
+		if !fn() {
+			delete(doc.Entries, key)
+			return
+		}
+		doc.Entries[key], modifyErr = json.Marshal(value)
+	})
+	if err != nil {
+		return err
+	}
+	return modifyErr
+}
+
+func (s *Store) update(fn func(doc *document)) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	unlock, err := crcos.LockFile(s.path + ".lock")
+	if err != nil {
+		return fmt.Errorf("cannot lock %s: %w", s.path, err)
+	}
+	defer func() {
+		_ = unlock()
+	}()
+
+	doc, err := s.load()
+	if err != nil {
+		return err
+	}
+	fn(doc)
+	return s.save(doc)
+}
+
+func (s *Store) load() (*document, error) {
+	doc := &document{
+		Version: currentVersion,
+		Entries: map[string]json.RawMessage{},
+	}
+	content, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return doc, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(content, doc); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", s.path, err)
+	}
+	if doc.Version > currentVersion {
+		return nil, fmt.Errorf("%s was written by a newer version of crc (format version %d)", s.path, doc.Version)
+	}
+	if doc.Entries == nil {
+		doc.Entries = map[string]json.RawMessage{}
+	}
+	// older formats are upgraded here, there is none so far
+	doc.Version = currentVersion
+	return doc, nil
+}
+
+func (s *Store) save(doc *document) error {
+	content, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmpFile, err := ioutil.TempFile(filepath.Dir(s.path), stateFilename)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmpFile.Name())
+	}()
+	if _, err := tmpFile.Write(content); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmpFile.Name(), s.path)
+}
+
+// CertsExpiry returns the expiry dates of the cluster certificates, as last
+// checked during start
+func (s *Store) CertsExpiry() (map[string]time.Time, error) {
+	certsExpiry := map[string]time.Time{}
+	if _, err := s.Get(certsExpiryKey, &certsExpiry); err != nil {
+		return nil, err
+	}
+	return certsExpiry, nil
+}
+
+func (s *Store) SetCertsExpiry(certsExpiry map[string]time.Time) error {
+	return s.Set(certsExpiryKey, certsExpiry)
+}
+
+// ProtectionToken returns the token needed to delete or power off the
+// instance, or an empty string when the instance is not protected
+func (s *Store) ProtectionToken() (string, error) {
+	var token string
+	if _, err := s.Get(protectionTokenKey, &token); err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+func (s *Store) SetProtectionToken(token string) error {
+	if token == "" {
+		return s.Delete(protectionTokenKey)
+	}
+	return s.Set(protectionTokenKey, token)
+}
+
+// PendingConfigChange is a configuration change which is not applied to the instance yet
+type PendingConfigChange struct {
+	Value interface{} `json:"value"`
+	// when the change will be applied, "start" or "delete"
+	Impact string `json:"impact"`
+}
+
+// PendingConfigChanges returns the configuration changes which are not applied
+// to the instance yet, indexed by setting name
+func (s *Store) PendingConfigChanges() (map[string]PendingConfigChange, error) {
+	changes := map[string]PendingConfigChange{}
+	if _, err := s.Get(pendingChangesKey, &changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (s *Store) SetPendingConfigChanges(changes map[string]PendingConfigChange) error {
+	if len(changes) == 0 {
+		return s.Delete(pendingChangesKey)
+	}
+	return s.Set(pendingChangesKey, changes)
+}
+
+// UpdatePendingConfigChanges calls fn with the pending configuration changes
+// and stores its modifications, without losing the ones done concurrently
+func (s *Store) UpdatePendingConfigChanges(fn func(changes map[string]PendingConfigChange)) error {
+	changes := map[string]PendingConfigChange{}
+	return s.modify(pendingChangesKey, &changes, func() bool {
+		fn(changes)
+		return len(changes) > 0
+	})
+}
+
+// UpdateActiveWarnings calls fn with the warnings sent to the users whose
+// cause did not go away yet, indexed by event type, and stores the ones it
+// leaves so that they are not sent again by the next commands
+func (s *Store) UpdateActiveWarnings(fn func(warnings map[string]bool)) error {
+	warnings := map[string]bool{}
+	return s.modify(activeWarningsKey, &warnings, func() bool {
+		fn(warnings)
+		return len(warnings) > 0
+	})
+}
+
+// ClusterID returns the ID the cluster reports to Insights and Telemetry, as
+// set during the last start
+func (s *Store) ClusterID() (string, error) {
+	var clusterID string
+	if _, err := s.Get(clusterIDKey, &clusterID); err != nil {
+		return "", err
+	}
+	return clusterID, nil
+}
+
+func (s *Store) SetClusterID(clusterID string) error {
+	return s.Set(clusterIDKey, clusterID)
+}
+
+// ClusterURLs are the URLs of the web console and of the API server, as
+// reported by the cluster
+type ClusterURLs struct {
+	WebConsole string `json:"webConsole"`
+	API        string `json:"api"`
+}
+
+// ClusterURLs returns the URLs read from the cluster during the last start,
+// nil when they were never read
+func (s *Store) ClusterURLs() (*ClusterURLs, error) {
+	var urls ClusterURLs
+	found, err := s.Get(clusterURLsKey, &urls)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &urls, nil
+}
+
+func (s *Store) SetClusterURLs(urls ClusterURLs) error {
+	return s.Set(clusterURLsKey, urls)
+}
+
+// UpstreamNameServers returns the nameservers added to the VM during the last
+// start, so that the ones removed from the configuration can be removed from
+// the VM
+func (s *Store) UpstreamNameServers() ([]string, error) {
+	var nameServers []string
+	if _, err := s.Get(nameServersKey, &nameServers); err != nil {
+		return nil, err
+	}
+	return nameServers, nil
+}
+
+func (s *Store) SetUpstreamNameServers(nameServers []string) error {
+	if len(nameServers) == 0 {
+		return s.Delete(nameServersKey)
+	}
+	return s.Set(nameServersKey, nameServers)
+}
+
+// Hibernated tells if the kubelet of the VM was stopped by Hibernate, the
+// cluster is then unreachable while the VM is running
+func (s *Store) Hibernated() (bool, error) {
+	var hibernated bool
+	if _, err := s.Get(hibernatedKey, &hibernated); err != nil {
+		return false, err
+	}
+	return hibernated, nil
+}
+
+func (s *Store) SetHibernated(hibernated bool) error {
+	if !hibernated {
+		return s.Delete(hibernatedKey)
+	}
+	return s.Set(hibernatedKey, true)
+}
+
+// LastStart returns the time of the last successful start of the instance,
+// zero when it never started successfully
+func (s *Store) LastStart() (time.Time, error) {
+	var lastStart time.Time
+	if _, err := s.Get(lastStartKey, &lastStart); err != nil {
+		return time.Time{}, err
+	}
+	return lastStart, nil
+}
+
+func (s *Store) SetLastStart(lastStart time.Time) error {
+	return s.Set(lastStartKey, lastStart)
+}
+
+// ClockOffset returns how far the clock of the VM was moved from the one of
+// the host with ShiftClock, to test the certificates expiry
+func (s *Store) ClockOffset() (time.Duration, error) {
+	var offset time.Duration
+	if _, err := s.Get(clockOffsetKey, &offset); err != nil {
+		return 0, err
+	}
+	return offset, nil
+}
+
+func (s *Store) SetClockOffset(offset time.Duration) error {
+	if offset == 0 {
+		return s.Delete(clockOffsetKey)
+	}
+	return s.Set(clockOffsetKey, offset)
+}
+
+// PreservedClusterID returns the cluster ID to reuse when the instance called
+// name is created again, this is only meaningful in the Global store
+func (s *Store) PreservedClusterID(name string) (string, error) {
+	clusterIDs := map[string]string{}
+	if _, err := s.Get(preservedClusterIDsKey, &clusterIDs); err != nil {
+		return "", err
+	}
+	return clusterIDs[name], nil
+}
+
+func (s *Store) SetPreservedClusterID(name, clusterID string) error {
+	clusterIDs := map[string]string{}
+	return s.modify(preservedClusterIDsKey, &clusterIDs, func() bool {
+		clusterIDs[name] = clusterID
+		return true
+	})
+}
+
+// NetworkIndex returns the index of the instance called name on the VM
+// network, a new instance gets the smallest index which is not used, the
+// index 0 is reserved for defaultName. This is only meaningful in the Global
+// store.
+func (s *Store) NetworkIndex(name, defaultName string) (int, error) {
+	if name == defaultName {
+		return 0, nil
+	}
+	indexes := map[string]int{}
+	var index int
+	err := s.modify(networkIndexesKey, &indexes, func() bool {
+		if existing, ok := indexes[name]; ok {
+			index = existing
+			return true
+		}
+		used := map[int]bool{}
+		for _, existing := range indexes {
+			used[existing] = true
+		}
+		index = 1
+		for used[index] {
+			index++
+		}
+		indexes[name] = index
+		return true
+	})
+	return index, err
+}
+
+// ReleaseNetworkIndex frees the index of the deleted instance called name
+func (s *Store) ReleaseNetworkIndex(name string) error {
+	indexes := map[string]int{}
+	return s.modify(networkIndexesKey, &indexes, func() bool {
+		delete(indexes, name)
+		return len(indexes) > 0
+	})
+}
@@ -0,0 +1,198 @@
+package store
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	store := New(filepath.Join(dir, "crc", stateFilename))
+
+	var value string
+	found, err := store.Get("key", &value)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, store.Set("key", "value"))
+	found, err = New(filepath.Join(dir, "crc", stateFilename)).Get("key", &value)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "value", value)
+
+	assert.NoError(t, store.Delete("key"))
+	found, err = store.Get("key", &value)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	expiry := map[string]time.Time{
+		"/var/lib/kubelet/pki/kubelet-client-current.pem": time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC),
+	}
+	assert.NoError(t, store.SetCertsExpiry(expiry))
+	certsExpiry, err := store.CertsExpiry()
+	assert.NoError(t, err)
+	assert.Equal(t, expiry, certsExpiry)
+
+	token, err := store.ProtectionToken()
+	assert.NoError(t, err)
+	assert.Empty(t, token)
+	assert.NoError(t, store.SetProtectionToken("d4f2a0"))
+	token, err = store.ProtectionToken()
+	assert.NoError(t, err)
+	assert.Equal(t, "d4f2a0", token)
+	assert.NoError(t, store.SetProtectionToken(""))
+	token, err = store.ProtectionToken()
+	assert.NoError(t, err)
+	assert.Empty(t, token)
+
+	changes := map[string]PendingConfigChange{
+		"bundle": {Value: "crc_libvirt_4.8.2.crcbundle", Impact: "delete"},
+	}
+	assert.NoError(t, store.SetPendingConfigChanges(changes))
+	pending, err := store.PendingConfigChanges()
+	assert.NoError(t, err)
+	assert.Equal(t, changes, pending)
+	assert.NoError(t, store.SetPendingConfigChanges(nil))
+	pending, err = store.PendingConfigChanges()
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+
+	assert.NoError(t, store.SetClusterID("6c4b2c56-0e6f-4c23-8b4f-3f2b6f1b1e27"))
+	clusterID, err := store.ClusterID()
+	assert.NoError(t, err)
+	assert.Equal(t, "6c4b2c56-0e6f-4c23-8b4f-3f2b6f1b1e27", clusterID)
+
+	urls, err := store.ClusterURLs()
+	assert.NoError(t, err)
+	assert.Nil(t, urls)
+	assert.NoError(t, store.SetClusterURLs(ClusterURLs{
+		WebConsole: "https://console-openshift-console.apps-crc.testing",
+		API:        "https://api.crc.testing:6443",
+	}))
+	urls, err = store.ClusterURLs()
+	assert.NoError(t, err)
+	assert.Equal(t, &ClusterURLs{
+		WebConsole: "https://console-openshift-console.apps-crc.testing",
+		API:        "https://api.crc.testing:6443",
+	}, urls)
+
+	clusterID, err = store.PreservedClusterID("crc")
+	assert.NoError(t, err)
+	assert.Empty(t, clusterID)
+	assert.NoError(t, store.SetPreservedClusterID("crc", "6c4b2c56-0e6f-4c23-8b4f-3f2b6f1b1e27"))
+	assert.NoError(t, store.SetPreservedClusterID("other", "0a7d1e2f-52a4-4d8e-9b6c-1f0e2d3c4b5a"))
+	clusterID, err = store.PreservedClusterID("crc")
+	assert.NoError(t, err)
+	assert.Equal(t, "6c4b2c56-0e6f-4c23-8b4f-3f2b6f1b1e27", clusterID)
+
+	index, err := store.NetworkIndex("crc", "crc")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, index)
+	for _, name := range []string{"dev", "test411", "dev"} {
+		_, err = store.NetworkIndex(name, "crc")
+		assert.NoError(t, err)
+	}
+	index, err = store.NetworkIndex("test411", "crc")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, index)
+	assert.NoError(t, store.ReleaseNetworkIndex("dev"))
+	index, err = store.NetworkIndex("qa", "crc")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, index)
+
+	assert.NoError(t, store.SetClockOffset(31*24*time.Hour))
+	offset, err := store.ClockOffset()
+	assert.NoError(t, err)
+	assert.Equal(t, 31*24*time.Hour, offset)
+	assert.NoError(t, store.SetClockOffset(0))
+	offset, err = store.ClockOffset()
+	assert.NoError(t, err)
+	assert.Zero(t, offset)
+}
+
+func TestStoreNewerVersion(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, stateFilename)
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"version": 2, "entries": {}}`), 0600))
+
+	var value string
+	_, err = New(path).Get("key", &value)
+	assert.EqualError(t, err, path+" was written by a newer version of crc (format version 2)")
+	assert.Error(t, New(path).Set("key", "value"))
+}
+
+func TestStoreConcurrentUpdates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "crc", stateFilename)
+	assert.Same(t, New(path), New(filepath.Join(dir, "crc", ".", stateFilename)))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// a new lookup for each update, as the callers do
+			assert.NoError(t, New(path).UpdatePendingConfigChanges(func(pending map[string]PendingConfigChange) {
+				pending[fmt.Sprintf("key%d", i)] = PendingConfigChange{Value: i, Impact: "start"}
+			}))
+		}(i)
+	}
+	wg.Wait()
+
+	pending, err := New(path).PendingConfigChanges()
+	assert.NoError(t, err)
+	assert.Len(t, pending, 20)
+
+	assert.NoError(t, New(path).UpdatePendingConfigChanges(func(pending map[string]PendingConfigChange) {
+		for key := range pending {
+			delete(pending, key)
+		}
+	}))
+	found, err := New(path).Get(pendingChangesKey, &pending)
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestStoreUpdatesFromSeveralProcesses(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, stateFilename)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// each process has its own Store, only the lock file serializes them
+			_, err := (&Store{path: path}).NetworkIndex(fmt.Sprintf("instance%d", i), "crc")
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	indexes := map[int]bool{}
+	for i := 0; i < 20; i++ {
+		index, err := New(path).NetworkIndex(fmt.Sprintf("instance%d", i), "crc")
+		assert.NoError(t, err)
+		indexes[index] = true
+	}
+	assert.Len(t, indexes, 20)
+}
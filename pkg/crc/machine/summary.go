@@ -0,0 +1,40 @@
+package machine
+
+import (
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+)
+
+const (
+	developerUsername = "developer"
+	developerPassword = "developer"
+)
+
+// startSummary describes how to access the cluster of the instance called name
+func startSummary(name string, clusterConfig *types.ClusterConfig) *types.StartSummary {
+	developerLogin := fmt.Sprintf("oc login -u %s %s", developerUsername, clusterConfig.ClusterAPI)
+	return &types.StartSummary{
+		ConsoleURL:     clusterConfig.WebConsoleURL,
+		APIURL:         clusterConfig.ClusterAPI,
+		KubeconfigPath: getGlobalKubeConfigPath(),
+		Logins: []types.Login{
+			{
+				Role:         types.AdminRole,
+				Username:     "kubeadmin",
+				Password:     clusterConfig.KubeAdminPass,
+				Context:      kubeconfigName("crc", name, "-admin"),
+				LoginCommand: fmt.Sprintf("oc login -u kubeadmin %s", clusterConfig.ClusterAPI),
+			},
+			{
+				Role:         types.DeveloperRole,
+				Username:     developerUsername,
+				Password:     developerPassword,
+				Context:      kubeconfigName("crc", name, "-developer"),
+				LoginCommand: developerLogin,
+			},
+		},
+		OcEnvCommand: "crc oc-env",
+		NextSteps:    []string{developerLogin},
+	}
+}
@@ -0,0 +1,38 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartSummary(t *testing.T) {
+	summary := startSummary("crc", &types.ClusterConfig{
+		KubeAdminPass: "secret",
+		ClusterAPI:    "https://api.crc.testing:6443",
+		WebConsoleURL: "https://console-openshift-console.apps-crc.testing",
+	})
+	assert.Equal(t, "https://console-openshift-console.apps-crc.testing", summary.ConsoleURL)
+	assert.Equal(t, "https://api.crc.testing:6443", summary.APIURL)
+	assert.Equal(t, []types.Login{
+		{
+			Role:         types.AdminRole,
+			Username:     "kubeadmin",
+			Password:     "secret",
+			Context:      "crc-admin",
+			LoginCommand: "oc login -u kubeadmin https://api.crc.testing:6443",
+		},
+		{
+			Role:         types.DeveloperRole,
+			Username:     "developer",
+			Password:     "developer",
+			Context:      "crc-developer",
+			LoginCommand: "oc login -u developer https://api.crc.testing:6443",
+		},
+	}, summary.Logins)
+	assert.Equal(t, "crc oc-env", summary.OcEnvCommand)
+	assert.Equal(t, []string{"oc login -u developer https://api.crc.testing:6443"}, summary.NextSteps)
+
+	assert.Equal(t, "crc-other-admin", startSummary("other", &types.ClusterConfig{}).Logins[0].Context)
+}
@@ -3,12 +3,14 @@ package machine
 import (
 	"context"
 	"errors"
+	"io"
 	"sync"
 	"time"
 
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/state"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/network/forward"
 )
 
 const startCancelTimeout = 15 * time.Second
@@ -20,6 +22,29 @@ const (
 	Deleting State = "Deleting"
 	Stopping State = "Stopping"
 	Starting State = "Starting"
+	// Snapshotting is the state during the scheduled snapshots, the
+	// filesystem of the VM is frozen meanwhile
+	Snapshotting State = "Snapshotting"
+	// Reverting is the state while the disk of the stopped VM is restored
+	// to a snapshot
+	Reverting State = "Reverting"
+	// Hibernating and Resuming are the states while the cluster is
+	// hibernated in the running VM and while it is resumed
+	Hibernating State = "Hibernating"
+	Resuming    State = "Resuming"
+	// Pausing is the state while the VM is suspended in memory
+	Pausing State = "Pausing"
+	// ShiftingClock is the state while the clock of the VM is moved
+	ShiftingClock State = "ShiftingClock"
+	// UpdatingProxy is the state while the proxy configuration is applied
+	// to the running cluster
+	UpdatingProxy State = "UpdatingProxy"
+	// Configuring is the state while the memory and the CPUs of the VM are
+	// changed
+	Configuring State = "Configuring"
+	// NetworkSelfTesting is the state while the network self-test pod runs
+	// in the cluster
+	NetworkSelfTesting State = "NetworkSelfTesting"
 )
 
 type Synchronized struct {
@@ -61,14 +86,14 @@ func (s *Synchronized) currentStateUnlocked() State {
 	return s.currentState
 }
 
-func (s *Synchronized) Delete() error {
+func (s *Synchronized) Delete(deleteConfig types.DeleteConfig) (*types.DeleteResult, error) {
 	if err := s.prepareStopDelete(Deleting); err != nil {
-		return err
+		return nil, err
 	}
 
-	err := s.underlying.Delete()
+	result, err := s.underlying.Delete(deleteConfig)
 	s.syncOperationDone <- Deleting
-	return err
+	return result, err
 }
 
 func (s *Synchronized) prepareStart(startCancel context.CancelFunc) error {
@@ -110,6 +135,18 @@ func (s *Synchronized) cancelUnlocked(timeout time.Duration) error {
 	}
 }
 
+// prepareOperation moves from Idle to state, the operation must send state to
+// syncOperationDone once it is done
+func (s *Synchronized) prepareOperation(state State) error {
+	s.stateLock.Lock()
+	defer s.stateLock.Unlock()
+	if s.currentStateUnlocked() != Idle {
+		return errors.New("cluster is busy")
+	}
+	s.currentState = state
+	return nil
+}
+
 func (s *Synchronized) prepareStopDelete(state State) error {
 	s.stateLock.Lock()
 	defer s.stateLock.Unlock()
@@ -123,6 +160,8 @@ func (s *Synchronized) prepareStopDelete(state State) error {
 		break
 	case Deleting, Stopping:
 		return errors.New("cluster is stopping or deleting")
+	case Snapshotting, Reverting, Configuring, Hibernating, Resuming, Pausing, ShiftingClock, UpdatingProxy, NetworkSelfTesting:
+		return errors.New("cluster is busy")
 	default:
 		return errors.New("invalid condition")
 	}
@@ -131,15 +170,15 @@ func (s *Synchronized) prepareStopDelete(state State) error {
 	return nil
 }
 
-func (s *Synchronized) Stop() (state.State, error) {
+func (s *Synchronized) Stop() (*types.StopResult, error) {
 	if err := s.prepareStopDelete(Stopping); err != nil {
-		return state.Error, err
+		return &types.StopResult{State: state.Error}, err
 	}
 
-	st, err := s.underlying.Stop()
+	result, err := s.underlying.Stop()
 	s.syncOperationDone <- Stopping
 
-	return st, err
+	return result, err
 }
 
 func (s *Synchronized) GetName() string {
@@ -158,8 +197,8 @@ func (s *Synchronized) ConnectionDetails() (*types.ConnectionDetails, error) {
 	return s.underlying.ConnectionDetails()
 }
 
-func (s *Synchronized) PowerOff() error {
-	return s.underlying.PowerOff()
+func (s *Synchronized) PowerOff(powerOffConfig types.PowerOffConfig) (*types.StopResult, error) {
+	return s.underlying.PowerOff(powerOffConfig)
 }
 
 func (s *Synchronized) Status() (*types.ClusterStatusResult, error) {
@@ -186,3 +225,148 @@ func (s *Synchronized) IsRunning() (bool, error) {
 func (s *Synchronized) GenerateBundle(forceStop bool) error {
 	return s.underlying.GenerateBundle(forceStop)
 }
+
+func (s *Synchronized) CompactDisk(forceStop bool) (*types.CompactDiskResult, error) {
+	return s.underlying.CompactDisk(forceStop)
+}
+
+func (s *Synchronized) ListImages() (*types.ImagesResult, error) {
+	return s.underlying.ListImages()
+}
+
+func (s *Synchronized) NetworkSelfTest() (*types.NetworkSelfTestResult, error) {
+	if err := s.prepareOperation(NetworkSelfTesting); err != nil {
+		return nil, err
+	}
+	result, err := s.underlying.NetworkSelfTest()
+	s.syncOperationDone <- NetworkSelfTesting
+	return result, err
+}
+
+func (s *Synchronized) TailUnitLogs(ctx context.Context, logsConfig types.UnitLogsConfig, writer io.Writer) error {
+	return s.underlying.TailUnitLogs(ctx, logsConfig, writer)
+}
+
+func (s *Synchronized) UpdateProxy() error {
+	if err := s.prepareOperation(UpdatingProxy); err != nil {
+		return err
+	}
+	err := s.underlying.UpdateProxy()
+	s.syncOperationDone <- UpdatingProxy
+	return err
+}
+
+func (s *Synchronized) ShiftClock(offset time.Duration) error {
+	if err := s.prepareOperation(ShiftingClock); err != nil {
+		return err
+	}
+	err := s.underlying.ShiftClock(offset)
+	s.syncOperationDone <- ShiftingClock
+	return err
+}
+
+func (s *Synchronized) ConfigChanged(key string, oldValue interface{}) (*types.ConfigChangeResult, error) {
+	return s.underlying.ConfigChanged(key, oldValue)
+}
+
+func (s *Synchronized) SetConfig(vmConfig types.VMConfig) (*types.SetConfigResult, error) {
+	if err := s.prepareOperation(Configuring); err != nil {
+		return nil, err
+	}
+	result, err := s.underlying.SetConfig(vmConfig)
+	s.syncOperationDone <- Configuring
+	return result, err
+}
+
+func (s *Synchronized) History() ([]types.HistoryEntry, error) {
+	return s.underlying.History()
+}
+
+func (s *Synchronized) AddPortForward(portForward forward.Forward) error {
+	return s.underlying.AddPortForward(portForward)
+}
+
+func (s *Synchronized) RemovePortForward(local string) error {
+	return s.underlying.RemovePortForward(local)
+}
+
+func (s *Synchronized) ListPortForwards() ([]forward.Forward, error) {
+	return s.underlying.ListPortForwards()
+}
+
+func (s *Synchronized) Protect() (string, error) {
+	return s.underlying.Protect()
+}
+
+func (s *Synchronized) Unprotect(token string) error {
+	return s.underlying.Unprotect(token)
+}
+
+func (s *Synchronized) Exec(execConfig types.ExecConfig) (*types.ExecResult, error) {
+	return s.underlying.Exec(execConfig)
+}
+
+func (s *Synchronized) SSH(sshConfig types.SSHConfig) error {
+	return s.underlying.SSH(sshConfig)
+}
+
+func (s *Synchronized) Hibernate() error {
+	if err := s.prepareOperation(Hibernating); err != nil {
+		return err
+	}
+	err := s.underlying.Hibernate()
+	s.syncOperationDone <- Hibernating
+	return err
+}
+
+func (s *Synchronized) Pause(pauseConfig types.PauseConfig) error {
+	if err := s.prepareOperation(Pausing); err != nil {
+		return err
+	}
+	err := s.underlying.Pause(pauseConfig)
+	s.syncOperationDone <- Pausing
+	return err
+}
+
+func (s *Synchronized) Resume(resumeConfig types.ResumeConfig) error {
+	if err := s.prepareOperation(Resuming); err != nil {
+		return err
+	}
+	err := s.underlying.Resume(resumeConfig)
+	s.syncOperationDone <- Resuming
+	return err
+}
+
+func (s *Synchronized) ScheduledSnapshot() error {
+	if err := s.prepareOperation(Snapshotting); err != nil {
+		return err
+	}
+	err := s.underlying.ScheduledSnapshot()
+	s.syncOperationDone <- Snapshotting
+	return err
+}
+
+func (s *Synchronized) Snapshot(snapshotName string) error {
+	if err := s.prepareOperation(Snapshotting); err != nil {
+		return err
+	}
+	err := s.underlying.Snapshot(snapshotName)
+	s.syncOperationDone <- Snapshotting
+	return err
+}
+
+func (s *Synchronized) Revert(snapshotName string) error {
+	if err := s.prepareOperation(Reverting); err != nil {
+		return err
+	}
+	err := s.underlying.Revert(snapshotName)
+	s.syncOperationDone <- Reverting
+	return err
+}
+
+func (s *Synchronized) Reconcile() error {
+	if s.CurrentState() != Idle {
+		return errors.New("cluster is busy")
+	}
+	return s.underlying.Reconcile()
+}
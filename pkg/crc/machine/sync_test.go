@@ -3,11 +3,14 @@ package machine
 import (
 	"context"
 	"errors"
+	"io"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/code-ready/crc/pkg/crc/machine/state"
 	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/network/forward"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -41,6 +44,118 @@ func TestOneStartAtTheSameTime(t *testing.T) {
 	assert.Equal(t, Idle, syncMachine.CurrentState())
 }
 
+func TestScheduledSnapshotIsExclusive(t *testing.T) {
+	isRunning := make(chan struct{}, 1)
+	snapshotCh := make(chan struct{}, 1)
+	waitingMachine := &waitingMachine{
+		isRunning:          isRunning,
+		snapshotCompleteCh: snapshotCh,
+	}
+	syncMachine := NewSynchronizedMachine(waitingMachine)
+
+	lock := &sync.WaitGroup{}
+	lock.Add(1)
+	go func() {
+		defer lock.Done()
+		assert.NoError(t, syncMachine.ScheduledSnapshot())
+	}()
+
+	<-isRunning
+	assert.Equal(t, Snapshotting, syncMachine.CurrentState())
+	_, err := syncMachine.Start(context.Background(), types.StartConfig{})
+	assert.EqualError(t, err, "cluster is busy")
+	_, err = syncMachine.Stop()
+	assert.EqualError(t, err, "cluster is busy")
+	assert.EqualError(t, syncMachine.ScheduledSnapshot(), "cluster is busy")
+
+	snapshotCh <- struct{}{}
+	lock.Wait()
+	assert.Equal(t, Idle, syncMachine.CurrentState())
+}
+
+func TestSetConfigIsExclusive(t *testing.T) {
+	isRunning := make(chan struct{}, 1)
+	setConfigCh := make(chan struct{}, 1)
+	waitingMachine := &waitingMachine{
+		isRunning:           isRunning,
+		setConfigCompleteCh: setConfigCh,
+	}
+	syncMachine := NewSynchronizedMachine(waitingMachine)
+
+	lock := &sync.WaitGroup{}
+	lock.Add(1)
+	go func() {
+		defer lock.Done()
+		_, err := syncMachine.SetConfig(types.VMConfig{Memory: 12288})
+		assert.NoError(t, err)
+	}()
+
+	<-isRunning
+	assert.Equal(t, Configuring, syncMachine.CurrentState())
+	_, err := syncMachine.Start(context.Background(), types.StartConfig{})
+	assert.EqualError(t, err, "cluster is busy")
+	_, err = syncMachine.Delete(types.DeleteConfig{})
+	assert.EqualError(t, err, "cluster is busy")
+	_, err = syncMachine.SetConfig(types.VMConfig{CPUs: 6})
+	assert.EqualError(t, err, "cluster is busy")
+
+	setConfigCh <- struct{}{}
+	lock.Wait()
+	assert.Equal(t, Idle, syncMachine.CurrentState())
+}
+
+func TestHibernateIsExclusive(t *testing.T) {
+	isRunning := make(chan struct{}, 1)
+	hibernateCh := make(chan struct{}, 1)
+	waitingMachine := &waitingMachine{
+		isRunning:           isRunning,
+		hibernateCompleteCh: hibernateCh,
+	}
+	syncMachine := NewSynchronizedMachine(waitingMachine)
+
+	lock := &sync.WaitGroup{}
+	lock.Add(1)
+	go func() {
+		defer lock.Done()
+		assert.NoError(t, syncMachine.Hibernate())
+	}()
+
+	<-isRunning
+	assert.Equal(t, Hibernating, syncMachine.CurrentState())
+	_, err := syncMachine.Stop()
+	assert.EqualError(t, err, "cluster is busy")
+	assert.EqualError(t, syncMachine.Resume(types.ResumeConfig{}), "cluster is busy")
+	assert.EqualError(t, syncMachine.Hibernate(), "cluster is busy")
+
+	hibernateCh <- struct{}{}
+	lock.Wait()
+	assert.Equal(t, Idle, syncMachine.CurrentState())
+}
+
+func TestFailedPauseReturnsToIdle(t *testing.T) {
+	syncMachine := NewSynchronizedMachine(&waitingMachine{})
+	assert.EqualError(t, syncMachine.Pause(types.PauseConfig{}), "not implemented")
+	assert.Equal(t, Idle, syncMachine.CurrentState())
+}
+
+func TestFailedUpdateProxyReturnsToIdle(t *testing.T) {
+	syncMachine := NewSynchronizedMachine(&waitingMachine{})
+	assert.EqualError(t, syncMachine.UpdateProxy(), "not implemented")
+	assert.Equal(t, Idle, syncMachine.CurrentState())
+}
+
+func TestNetworkSelfTestIsExclusive(t *testing.T) {
+	syncMachine := NewSynchronizedMachine(&waitingMachine{})
+	assert.NoError(t, syncMachine.prepareOperation(Hibernating))
+	_, err := syncMachine.NetworkSelfTest()
+	assert.EqualError(t, err, "cluster is busy")
+	syncMachine.syncOperationDone <- Hibernating
+
+	_, err = syncMachine.NetworkSelfTest()
+	assert.EqualError(t, err, "not implemented")
+	assert.Equal(t, Idle, syncMachine.CurrentState())
+}
+
 func TestDeleteStop(t *testing.T) {
 	isRunning := make(chan struct{}, 1)
 	deleteCh := make(chan struct{}, 1)
@@ -55,13 +170,15 @@ func TestDeleteStop(t *testing.T) {
 	lock.Add(1)
 	go func() {
 		defer lock.Done()
-		assert.NoError(t, syncMachine.Delete())
+		_, err := syncMachine.Delete(types.DeleteConfig{})
+		assert.NoError(t, err)
 	}()
 
 	<-isRunning
 	assert.Equal(t, Deleting, syncMachine.CurrentState())
-	assert.EqualError(t, syncMachine.Delete(), "cluster is stopping or deleting")
-	_, err := syncMachine.Stop()
+	_, err := syncMachine.Delete(types.DeleteConfig{})
+	assert.EqualError(t, err, "cluster is stopping or deleting")
+	_, err = syncMachine.Stop()
 	assert.EqualError(t, err, "cluster is stopping or deleting")
 	_, err = syncMachine.Start(context.Background(), types.StartConfig{})
 	assert.EqualError(t, err, "cluster is busy")
@@ -97,7 +214,8 @@ func TestCancelStart(t *testing.T) {
 	lock.Add(1)
 	go func() {
 		defer lock.Done()
-		assert.NoError(t, syncMachine.Delete())
+		_, err := syncMachine.Delete(types.DeleteConfig{})
+		assert.NoError(t, err)
 	}()
 
 	deleteCh <- struct{}{}
@@ -107,10 +225,13 @@ func TestCancelStart(t *testing.T) {
 }
 
 type waitingMachine struct {
-	isRunning        chan struct{}
-	startCompleteCh  chan struct{}
-	stopCompleteCh   chan struct{}
-	deleteCompleteCh chan struct{}
+	isRunning           chan struct{}
+	startCompleteCh     chan struct{}
+	stopCompleteCh      chan struct{}
+	deleteCompleteCh    chan struct{}
+	snapshotCompleteCh  chan struct{}
+	setConfigCompleteCh chan struct{}
+	hibernateCompleteCh chan struct{}
 }
 
 func (m *waitingMachine) IsRunning() (bool, error) {
@@ -121,10 +242,10 @@ func (m *waitingMachine) GetName() string {
 	return "waiting machine"
 }
 
-func (m *waitingMachine) Delete() error {
+func (m *waitingMachine) Delete(_ types.DeleteConfig) (*types.DeleteResult, error) {
 	m.isRunning <- struct{}{}
 	<-m.deleteCompleteCh
-	return nil
+	return &types.DeleteResult{}, nil
 }
 
 func (m *waitingMachine) Exists() (bool, error) {
@@ -139,8 +260,8 @@ func (m *waitingMachine) ConnectionDetails() (*types.ConnectionDetails, error) {
 	return nil, errors.New("not implemented")
 }
 
-func (m *waitingMachine) PowerOff() error {
-	return nil
+func (m *waitingMachine) PowerOff(_ types.PowerOffConfig) (*types.StopResult, error) {
+	return &types.StopResult{State: state.Stopped}, nil
 }
 
 func (m *waitingMachine) Start(context context.Context, _ types.StartConfig) (*types.StartResult, error) {
@@ -160,12 +281,112 @@ func (m *waitingMachine) Status() (*types.ClusterStatusResult, error) {
 	return nil, errors.New("not implemented")
 }
 
-func (m *waitingMachine) Stop() (state.State, error) {
+func (m *waitingMachine) Stop() (*types.StopResult, error) {
 	m.isRunning <- struct{}{}
 	<-m.stopCompleteCh
-	return state.Stopped, nil
+	return &types.StopResult{State: state.Stopped}, nil
 }
 
 func (m *waitingMachine) GenerateBundle(forceStop bool) error {
 	return errors.New("not implemented")
 }
+
+func (m *waitingMachine) CompactDisk(forceStop bool) (*types.CompactDiskResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *waitingMachine) SetConfig(vmConfig types.VMConfig) (*types.SetConfigResult, error) {
+	m.isRunning <- struct{}{}
+	<-m.setConfigCompleteCh
+	return &types.SetConfigResult{}, nil
+}
+
+func (m *waitingMachine) ListImages() (*types.ImagesResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *waitingMachine) NetworkSelfTest() (*types.NetworkSelfTestResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *waitingMachine) TailUnitLogs(ctx context.Context, logsConfig types.UnitLogsConfig, writer io.Writer) error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) ShiftClock(offset time.Duration) error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) UpdateProxy() error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) Hibernate() error {
+	m.isRunning <- struct{}{}
+	<-m.hibernateCompleteCh
+	return nil
+}
+
+func (m *waitingMachine) Pause(pauseConfig types.PauseConfig) error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) Resume(resumeConfig types.ResumeConfig) error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) Reconcile() error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) ScheduledSnapshot() error {
+	m.isRunning <- struct{}{}
+	<-m.snapshotCompleteCh
+	return nil
+}
+
+func (m *waitingMachine) Snapshot(snapshotName string) error {
+	m.isRunning <- struct{}{}
+	<-m.snapshotCompleteCh
+	return nil
+}
+
+func (m *waitingMachine) Revert(snapshotName string) error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) Exec(execConfig types.ExecConfig) (*types.ExecResult, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *waitingMachine) SSH(sshConfig types.SSHConfig) error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) Protect() (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func (m *waitingMachine) Unprotect(token string) error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) History() ([]types.HistoryEntry, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *waitingMachine) AddPortForward(portForward forward.Forward) error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) RemovePortForward(local string) error {
+	return errors.New("not implemented")
+}
+
+func (m *waitingMachine) ListPortForwards() ([]forward.Forward, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (m *waitingMachine) ConfigChanged(key string, oldValue interface{}) (*types.ConfigChangeResult, error) {
+	return nil, errors.New("not implemented")
+}
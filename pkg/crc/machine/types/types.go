@@ -1,6 +1,10 @@
 package types
 
 import (
+	"fmt"
+	"io"
+	"time"
+
 	"github.com/code-ready/crc/pkg/crc/cluster"
 	"github.com/code-ready/crc/pkg/crc/machine/state"
 	"github.com/code-ready/crc/pkg/crc/network"
@@ -10,7 +14,8 @@ type StartConfig struct {
 	// CRC system bundle
 	BundlePath string
 
-	// Hypervisor
+	// Hypervisor, a zero memory size or CPU count is chosen from the
+	// host capacity and the requirements of the bundle
 	Memory   int // Memory size in MiB
 	CPUs     int
 	DiskSize int // Disk size in GiB
@@ -38,13 +43,106 @@ type StartResult struct {
 	Status         state.State
 	ClusterConfig  ClusterConfig
 	KubeletStarted bool
+	// the VM was already running, it was not started again
+	AlreadyRunning bool
+	// resources which are requested but not applied to the existing VM
+	ResourceConflicts []ResourceConflict
+	// resources of the existing VM which the start changed
+	ResourceChanges []ResourceChange
+	// how to use the cluster, for the presentation layers
+	Summary *StartSummary
+}
+
+// StartSummary describes how to access the started cluster. It only holds
+// data, the texts shown to the users are written by the presentation layers.
+type StartSummary struct {
+	ConsoleURL string
+	APIURL     string
+	// kubeconfig file in which the contexts of the users are added
+	KubeconfigPath string
+	Logins         []Login
+	// command printing the environment needed to use oc from the host
+	OcEnvCommand string
+	// commands to run, in this order, once the OcEnvCommand environment is set
+	NextSteps []string
+}
+
+const (
+	AdminRole     = "admin"
+	DeveloperRole = "developer"
+)
+
+type Login struct {
+	// AdminRole or DeveloperRole
+	Role     string
+	Username string
+	Password string
+	// context of the user in the kubeconfig file
+	Context      string
+	LoginCommand string
+}
+
+// ResourceConflict is a resource of the VM, "memory" in MiB or "cpus", which
+// differs from the requested one
+type ResourceConflict struct {
+	Resource  string
+	Current   int
+	Requested int
+}
+
+func (conflict ResourceConflict) Format(value int) string {
+	return formatResource(conflict.Resource, value)
+}
+
+// ResourceChange is a resource of the VM, "memory" in MiB, "cpus" or
+// "disk-size" in GiB, which was changed from Previous to Current
+type ResourceChange struct {
+	Resource string
+	Previous int
+	Current  int
 }
 
+func (change ResourceChange) Format(value int) string {
+	return formatResource(change.Resource, value)
+}
+
+func formatResource(resource string, value int) string {
+	switch resource {
+	case "memory":
+		return fmt.Sprintf("%d MiB of memory", value)
+	case "disk-size":
+		return fmt.Sprintf("a %d GiB disk", value)
+	}
+	return fmt.Sprintf("%d %s", value, resource)
+}
+
+// VMConfig is the sizing of the VM, a zero value keeps the current one
+type VMConfig struct {
+	// in MiB
+	Memory int
+	CPUs   int
+	// in GiB, disks can grow but not shrink
+	DiskSize int
+}
+
+type SetConfigResult struct {
+	// resources changed in the stopped VM, and the disk of a running VM
+	Changes []ResourceChange
+	// resources which cannot be changed now, the VM is running or its
+	// driver does not support it. The next start applies the ones of a
+	// running VM.
+	Conflicts []ResourceConflict
+}
+
+// StopResult is the result of Stop and PowerOff, State is the state of the
+// VM once the operation is done, it is also set when the operation fails
 type StopResult struct {
 	Name    string
 	Success bool
 	State   state.State
 	Error   string
+	// the VM was not running, nothing was done
+	AlreadyStopped bool
 }
 
 type ClusterStatusResult struct {
@@ -53,6 +151,39 @@ type ClusterStatusResult struct {
 	OpenshiftVersion string
 	DiskUse          int64
 	DiskSize         int64
+	// expiry date of the first cluster certificate to expire, zero when unknown
+	CertsExpiry time.Time
+	// the certificates expire soon, their renewal then slows down the start
+	CertsRenewalNeeded bool
+	// ID the cluster reports to Insights and Telemetry, as set during the last start
+	ClusterID string
+	// what the VM costs the host, as seen by the hypervisor
+	Hypervisor HypervisorResources
+	// time since the VM booted, zero when it is not running
+	Uptime time.Duration
+	// time of the last successful start, zero when unknown
+	LastStart time.Time
+	// build date of the bundle of the instance, zero when unknown
+	BundleBuildTime time.Time
+	// the bundle is older than the validity of the certificates it ships,
+	// they are renewed during the start of a new instance created from it
+	BundleOutdated bool
+	// conditions of the cluster operators, empty when the cluster cannot
+	// be reached
+	Operators []cluster.OperatorStatus
+}
+
+type HypervisorResources struct {
+	// memory allocated to the VM, in MiB
+	AllocatedMemory int
+	AllocatedCPUs   int
+	// memory used by the guest, in bytes
+	UsedMemory int64
+	// percentage of the guest CPU time stolen by the host since boot
+	CPUSteal float64
+	// space used by the VM disk image on the host, in bytes
+	DiskImageSize int64
+	Snapshots     int
 }
 
 type OpenshiftStatus string
@@ -64,6 +195,8 @@ const (
 	OpenshiftDegraded    OpenshiftStatus = "Degraded"
 	OpenshiftStopped     OpenshiftStatus = "Stopped"
 	OpenshiftStopping    OpenshiftStatus = "Stopping"
+	OpenshiftHibernated  OpenshiftStatus = "Hibernated"
+	OpenshiftPaused      OpenshiftStatus = "Paused"
 )
 
 type ConsoleResult struct {
@@ -71,6 +204,120 @@ type ConsoleResult struct {
 	State         state.State
 }
 
+type ImagesResult struct {
+	Images []cluster.Image
+}
+
+type NetworkSelfTestResult struct {
+	// all the checks succeeded
+	Healthy bool
+	Checks  []cluster.SelfTestCheck
+}
+
+type ConfigChangeImpact string
+
+const (
+	// the change is used without further action
+	ConfigAppliedLive ConfigChangeImpact = "live"
+	// the change is applied when the instance is started
+	ConfigAppliedAtStart ConfigChangeImpact = "start"
+	// the change is only applied when the instance is created
+	ConfigRequiresDelete ConfigChangeImpact = "delete"
+)
+
+type ConfigChangeResult struct {
+	Impact          ConfigChangeImpact
+	InstanceRunning bool
+}
+
+type DeleteConfig struct {
+	// Remove the cached bundles and binaries once the VM is deleted
+	ClearCache bool
+	// Keep the extracted bundles when clearing the cache, so that the next
+	// start does not need to extract them again
+	KeepBundle bool
+	// Copy an etcd backup and the persistent volumes of the running cluster
+	// out of the VM before deleting it
+	KeepData bool
+	// Succeed without deleting anything when the instance does not exist
+	IgnoreMissing bool
+	// Token returned by Protect, needed to delete a protected instance
+	ProtectionToken string
+}
+
+// DeleteResult is the result of Delete
+type DeleteResult struct {
+	// the instance did not exist, nothing was deleted
+	AlreadyDeleted bool
+}
+
+type PowerOffConfig struct {
+	// Token returned by Protect, needed to power off a protected instance,
+	// the instance stays protected afterwards
+	ProtectionToken string
+}
+
+// PauseConfig is the configuration of Pause, it has no options yet
+type PauseConfig struct{}
+
+type ResumeConfig struct {
+	// Set the clock of a paused VM to the one of the host, its clock stood
+	// still while it was paused
+	SyncClock bool
+}
+
+type ExecConfig struct {
+	// Command and its arguments, the command must be allowed by the exec-allowed-commands setting
+	Command []string
+	// Run the command as root, the command must be allowed by the
+	// exec-privileged-commands setting instead
+	Privileged bool
+}
+
+// UnitLogsConfig tells which messages of the journal of a systemd unit of the
+// VM are sent
+type UnitLogsConfig struct {
+	// Unit is the systemd unit, such as kubelet or crio
+	Unit string
+	// Lines is the number of the last messages sent first, all of them if 0
+	Lines int
+	// Follow sends the new messages until the request is cancelled
+	Follow bool
+}
+
+// SSHConfig is the configuration of an interactive ssh session in the VM
+type SSHConfig struct {
+	// Command and its arguments, a login shell is started when it is empty
+	Command []string
+	Stdin   io.Reader
+	Stdout  io.Writer
+	Stderr  io.Writer
+}
+
+// HistoryEntry is a lifecycle operation of an instance recorded in its history
+type HistoryEntry struct {
+	Time time.Time `json:"time"`
+	// user who requested the operation
+	User       string                 `json:"user"`
+	Operation  string                 `json:"operation"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Success    bool                   `json:"success"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+type CompactDiskResult struct {
+	// space used by the disk image on the host, in bytes
+	SizeBefore int64
+	SizeAfter  int64
+	// the running instance was stopped to compact its disk
+	Stopped bool
+}
+
+type ExecResult struct {
+	Stdout string
+	Stderr string
+}
+
 type ConnectionDetails struct {
 	IP          string
 	SSHPort     int
@@ -0,0 +1,63 @@
+package machine
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/pkg/errors"
+)
+
+// unitNameRegex matches the systemd unit names, which are passed unquoted to
+// the shell of the VM
+var unitNameRegex = regexp.MustCompile(`^[a-zA-Z0-9:_.@\-]+$`)
+
+// TailUnitLogs writes the journal of a systemd unit of the VM to writer. With
+// Follow the new messages are written until ctx is cancelled.
+func (client *client) TailUnitLogs(ctx context.Context, logsConfig types.UnitLogsConfig, writer io.Writer) error {
+	args, err := journalctlArgs(logsConfig)
+	if err != nil {
+		return err
+	}
+	running, err := client.IsRunning()
+	if err != nil {
+		return err
+	}
+	if !running {
+		return errors.New("machine is not running")
+	}
+	_, sshRunner, err := loadVM(client)
+	if err != nil {
+		return err
+	}
+	defer sshRunner.Close()
+
+	stderr, err := sshRunner.StreamPrivileged(ctx, fmt.Sprintf("reading the logs of %s", logsConfig.Unit), writer, args...)
+	if ctx.Err() != nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("Failed to read the logs of %s %v: %s", logsConfig.Unit, err, stderr)
+	}
+	return nil
+}
+
+func journalctlArgs(logsConfig types.UnitLogsConfig) ([]string, error) {
+	if !unitNameRegex.MatchString(logsConfig.Unit) {
+		return nil, fmt.Errorf("Invalid unit name '%s'", logsConfig.Unit)
+	}
+	if logsConfig.Lines < 0 {
+		return nil, fmt.Errorf("Invalid number of lines %d", logsConfig.Lines)
+	}
+	args := []string{"journalctl", "--no-pager", "--output", "short-iso", "--unit", logsConfig.Unit}
+	if logsConfig.Lines > 0 {
+		args = append(args, "--lines", strconv.Itoa(logsConfig.Lines))
+	}
+	if logsConfig.Follow {
+		args = append(args, "--follow")
+	}
+	return args, nil
+}
@@ -0,0 +1,25 @@
+package machine
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalctlArgs(t *testing.T) {
+	args, err := journalctlArgs(types.UnitLogsConfig{Unit: "kubelet", Lines: 100, Follow: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"journalctl", "--no-pager", "--output", "short-iso", "--unit", "kubelet", "--lines", "100", "--follow"}, args)
+
+	args, err = journalctlArgs(types.UnitLogsConfig{Unit: "crc-dnsmasq.service"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"journalctl", "--no-pager", "--output", "short-iso", "--unit", "crc-dnsmasq.service"}, args)
+
+	_, err = journalctlArgs(types.UnitLogsConfig{Unit: "kubelet; reboot"})
+	assert.EqualError(t, err, "Invalid unit name 'kubelet; reboot'")
+	_, err = journalctlArgs(types.UnitLogsConfig{Unit: ""})
+	assert.Error(t, err)
+	_, err = journalctlArgs(types.UnitLogsConfig{Unit: "crio", Lines: -1})
+	assert.EqualError(t, err, "Invalid number of lines -1")
+}
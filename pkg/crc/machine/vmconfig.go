@@ -0,0 +1,120 @@
+package machine
+
+import (
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/store"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/validation"
+	libmachinestate "github.com/code-ready/machine/libmachine/state"
+	"github.com/pkg/errors"
+)
+
+// SetConfig changes the memory, the CPUs and the disk size of the existing VM
+// and stores them in the configuration. They are applied right away to a
+// stopped VM. The disk of a running VM is grown right away too, its memory and
+// CPUs are recorded for its next start.
+func (client *client) SetConfig(vmConfig types.VMConfig) (*types.SetConfigResult, error) {
+	if vmConfig.Memory != 0 {
+		if err := validation.ValidateMemory(vmConfig.Memory); err != nil {
+			return nil, err
+		}
+	}
+	if vmConfig.CPUs != 0 {
+		if err := validation.ValidateCPUs(vmConfig.CPUs); err != nil {
+			return nil, err
+		}
+	}
+	if vmConfig.DiskSize != 0 {
+		if err := validation.ValidateDiskSize(vmConfig.DiskSize); err != nil {
+			return nil, err
+		}
+	}
+
+	libMachineAPIClient, cleanup := createLibMachineClient()
+	defer cleanup()
+	host, err := libMachineAPIClient.Load(client.name)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot load machine")
+	}
+	vmState, err := host.Driver.GetState()
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot get machine state")
+	}
+	result := &types.SetConfigResult{}
+	if vmConfig.DiskSize != 0 {
+		diskChange, err := client.resizeDisk(host, vmState, vmConfig.DiskSize)
+		if err != nil {
+			return nil, err
+		}
+		if diskChange != nil {
+			if err := libMachineAPIClient.Save(host); err != nil {
+				return nil, err
+			}
+			result.Changes = append(result.Changes, *diskChange)
+			if vmState == libmachinestate.Running {
+				if err := client.growRunningDisk(); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if err := client.storeVMConfig(vmConfig); err != nil {
+		return nil, err
+	}
+	startConfig := types.StartConfig{Memory: vmConfig.Memory, CPUs: vmConfig.CPUs}
+	if vmState != libmachinestate.Stopped {
+		result.Conflicts = client.checkRunningVMResources(host, startConfig)
+		return result, nil
+	}
+
+	changes, conflicts, err := resizeVM(host, vmConfig.Memory, vmConfig.CPUs)
+	if err != nil {
+		return nil, errors.Wrap(err, "Could not update CRC VM configuration")
+	}
+	if err := libMachineAPIClient.Save(host); err != nil {
+		return nil, err
+	}
+	client.forgetPendingResources(changes)
+	result.Changes = append(result.Changes, changes...)
+	result.Conflicts = conflicts
+	return result, nil
+}
+
+// storeVMConfig sets the sizing of vmConfig in the configuration,
+// the next starts and the new instances use them
+func (client *client) storeVMConfig(vmConfig types.VMConfig) error {
+	if vmConfig.Memory != 0 {
+		if _, err := client.config.Set(crcConfig.Memory, vmConfig.Memory); err != nil {
+			return errors.Wrap(err, "Cannot store the memory in the configuration")
+		}
+	}
+	if vmConfig.CPUs != 0 {
+		if _, err := client.config.Set(crcConfig.CPUs, vmConfig.CPUs); err != nil {
+			return errors.Wrap(err, "Cannot store the CPUs in the configuration")
+		}
+	}
+	if vmConfig.DiskSize != 0 {
+		if _, err := client.config.Set(crcConfig.DiskSize, vmConfig.DiskSize); err != nil {
+			return errors.Wrap(err, "Cannot store the disk size in the configuration")
+		}
+	}
+	return nil
+}
+
+// forgetPendingResources removes the resources requested while the VM was
+// running once they are changed, the next start must not apply them again
+func (client *client) forgetPendingResources(changes []types.ResourceChange) {
+	if len(changes) == 0 {
+		return
+	}
+	if err := store.ForInstance(client.name).UpdatePendingConfigChanges(func(pending map[string]store.PendingConfigChange) {
+		for _, change := range changes {
+			if change.Resource == crcConfig.Memory || change.Resource == crcConfig.CPUs || change.Resource == crcConfig.DiskSize {
+				delete(pending, change.Resource)
+			}
+		}
+	}); err != nil {
+		logging.Debugf("Cannot update pending configuration changes: %v", err)
+	}
+}
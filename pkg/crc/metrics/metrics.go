@@ -0,0 +1,226 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/code-ready/crc/pkg/crc/version"
+	"github.com/kofalt/go-memoize"
+)
+
+// ContentType is the content type of the text exposition format of
+// Prometheus
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// MissingVM is the state of crc_vm_state when the instance has no VM
+const MissingVM state.State = "DoesNotExist"
+
+// statusCacheDuration is how long the status is reused by the scrapes, getting
+// it runs commands in the VM and queries the cluster operators
+const statusCacheDuration = 15 * time.Second
+
+var (
+	vmStates = []state.State{state.Running, state.Stopped, state.Stopping, state.Starting, state.Paused, state.Error, MissingVM}
+
+	openshiftStatuses = []types.OpenshiftStatus{types.OpenshiftUnreachable, types.OpenshiftStarting, types.OpenshiftRunning,
+		types.OpenshiftDegraded, types.OpenshiftStopped, types.OpenshiftStopping, types.OpenshiftHibernated, types.OpenshiftPaused}
+)
+
+// StatusFunc returns the status of the instance, such as the Status method
+// of machine.Client
+type StatusFunc func() (*types.ClusterStatusResult, error)
+
+// Source is the part of machine.Client the metrics are read from
+type Source interface {
+	Exists() (bool, error)
+	Status() (*types.ClusterStatusResult, error)
+}
+
+// CachedStatus returns the status of source, it is only read again once it
+// is older than statusCacheDuration. The status of an instance without VM has
+// the MissingVM state.
+func CachedStatus(source Source) StatusFunc {
+	cache := memoize.NewMemoizer(statusCacheDuration, time.Minute)
+	return func() (*types.ClusterStatusResult, error) {
+		status, err, _ := cache.Memoize("status", func() (interface{}, error) {
+			exists, err := source.Exists()
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				return &types.ClusterStatusResult{CrcStatus: MissingVM}, nil
+			}
+			return source.Status()
+		})
+		if err != nil {
+			return nil, err
+		}
+		return status.(*types.ClusterStatusResult), nil
+	}
+}
+
+// NewHandler returns the handler serving the metrics of the instance whose
+// status is returned by status
+func NewHandler(status StatusFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Only GET is allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		result, err := status()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", ContentType)
+		if err := Write(w, result, time.Now()); err != nil {
+			logging.Debugf("Cannot write the metrics: %v", err)
+		}
+	})
+}
+
+type sample struct {
+	labels []string
+	value  float64
+}
+
+type metric struct {
+	name    string
+	help    string
+	samples []sample
+}
+
+// Write writes the metrics of status in the text exposition format of
+// Prometheus, all of them are gauges
+func Write(w io.Writer, status *types.ClusterStatusResult, now time.Time) error {
+	for _, metric := range collect(status, now) {
+		if len(metric.samples) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s gauge\n", metric.name, metric.help, metric.name); err != nil {
+			return err
+		}
+		for _, sample := range metric.samples {
+			if _, err := fmt.Fprintf(w, "%s%s %s\n", metric.name, formatLabels(sample.labels), formatValue(sample.value)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func collect(status *types.ClusterStatusResult, now time.Time) []metric {
+	var vmState []sample
+	for _, s := range vmStates {
+		vmState = append(vmState, sample{labels: []string{"state", string(s)}, value: boolValue(status.CrcStatus == s)})
+	}
+	var openshiftStatus []sample
+	for _, s := range openshiftStatuses {
+		openshiftStatus = append(openshiftStatus, sample{labels: []string{"status", string(s)}, value: boolValue(status.OpenshiftStatus == s)})
+	}
+
+	metrics := []metric{
+		{
+			name: "crc_info",
+			help: "Versions of crc and of the OpenShift cluster of the instance.",
+			samples: []sample{{
+				labels: []string{"crc_version", version.GetCRCVersion(), "openshift_version", status.OpenshiftVersion},
+				value:  1,
+			}},
+		},
+		{name: "crc_vm_state", help: "State of the VM, 1 for the current one.", samples: vmState},
+	}
+	if status.CrcStatus == MissingVM {
+		return metrics
+	}
+	metrics = append(metrics, []metric{
+		{name: "crc_openshift_status", help: "Status of the OpenShift cluster, 1 for the current one.", samples: openshiftStatus},
+		{name: "crc_uptime_seconds", help: "Time since the VM booted.", samples: single(status.Uptime.Seconds())},
+		{
+			name:    "crc_memory_allocated_bytes",
+			help:    "Memory allocated to the VM.",
+			samples: single(float64(status.Hypervisor.AllocatedMemory) * 1024 * 1024),
+		},
+		{name: "crc_cpus_allocated", help: "Virtual CPUs allocated to the VM.", samples: single(float64(status.Hypervisor.AllocatedCPUs))},
+		{name: "crc_disk_image_bytes", help: "Space used by the disk image of the VM on the host.", samples: single(float64(status.Hypervisor.DiskImageSize))},
+		{name: "crc_certs_renewal_needed", help: "1 when the certificates of the cluster expire soon.", samples: single(boolValue(status.CertsRenewalNeeded))},
+	}...)
+	if !status.CertsExpiry.IsZero() {
+		metrics = append(metrics, metric{
+			name:    "crc_certs_expiry_days",
+			help:    "Days until the first certificate of the cluster expires.",
+			samples: single(status.CertsExpiry.Sub(now).Hours() / 24),
+		})
+	}
+	// only known while the VM is running
+	if status.CrcStatus == state.Running {
+		metrics = append(metrics,
+			metric{name: "crc_disk_used_bytes", help: "Space used on the root partition of the VM.", samples: single(float64(status.DiskUse))},
+			metric{name: "crc_disk_size_bytes", help: "Size of the root partition of the VM.", samples: single(float64(status.DiskSize))},
+			metric{name: "crc_memory_used_bytes", help: "Memory used by the guest.", samples: single(float64(status.Hypervisor.UsedMemory))},
+			metric{name: "crc_cpu_steal_percent", help: "Percentage of the guest CPU time stolen by the host since boot.", samples: single(status.Hypervisor.CPUSteal)},
+		)
+		if status.Hypervisor.AllocatedMemory > 0 && status.Hypervisor.UsedMemory > 0 {
+			metrics = append(metrics, metric{
+				name:    "crc_memory_pressure_ratio",
+				help:    "Ratio of the memory of the VM used by the guest.",
+				samples: single(float64(status.Hypervisor.UsedMemory) / (float64(status.Hypervisor.AllocatedMemory) * 1024 * 1024)),
+			})
+		}
+	}
+	return append(metrics, operatorMetrics(status.Operators)...)
+}
+
+func operatorMetrics(operators []cluster.OperatorStatus) []metric {
+	sorted := append([]cluster.OperatorStatus{}, operators...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	available := metric{name: "crc_cluster_operator_available", help: "1 when the cluster operator is available."}
+	degraded := metric{name: "crc_cluster_operator_degraded", help: "1 when the cluster operator is degraded."}
+	progressing := metric{name: "crc_cluster_operator_progressing", help: "1 when the cluster operator is progressing."}
+	for _, operator := range sorted {
+		labels := []string{"name", operator.Name}
+		available.samples = append(available.samples, sample{labels: labels, value: boolValue(operator.Available)})
+		degraded.samples = append(degraded.samples, sample{labels: labels, value: boolValue(operator.Degraded)})
+		progressing.samples = append(progressing.samples, sample{labels: labels, value: boolValue(operator.Progressing)})
+	}
+	return []metric{available, degraded, progressing}
+}
+
+func single(value float64) []sample {
+	return []sample{{value: value}}
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// formatLabels formats labels, a list of names and values
+func formatLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	var pairs []string
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, labels[i], labelValueEscaper.Replace(labels[i+1])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
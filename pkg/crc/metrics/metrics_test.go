@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/cluster"
+	"github.com/code-ready/crc/pkg/crc/machine/state"
+	"github.com/code-ready/crc/pkg/crc/machine/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var runningStatus = &types.ClusterStatusResult{
+	CrcStatus:        state.Running,
+	OpenshiftStatus:  types.OpenshiftDegraded,
+	OpenshiftVersion: "4.9.0",
+	DiskUse:          10 * 1024 * 1024 * 1024,
+	DiskSize:         32 * 1024 * 1024 * 1024,
+	CertsExpiry:      time.Date(2021, time.November, 10, 12, 0, 0, 0, time.UTC),
+	Hypervisor: types.HypervisorResources{
+		AllocatedMemory: 8192,
+		AllocatedCPUs:   4,
+		UsedMemory:      6 * 1024 * 1024 * 1024,
+	},
+	Uptime: 90 * time.Second,
+	Operators: []cluster.OperatorStatus{
+		{Name: "kube-apiserver", Available: true},
+		{Name: "authentication", Degraded: true},
+	},
+}
+
+func TestWrite(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, runningStatus, time.Date(2021, time.October, 17, 0, 0, 0, 0, time.UTC)))
+	metrics := out.String()
+
+	assert.Contains(t, metrics, "# HELP crc_vm_state State of the VM, 1 for the current one.\n# TYPE crc_vm_state gauge\n")
+	assert.Contains(t, metrics, "crc_vm_state{state=\"Running\"} 1\ncrc_vm_state{state=\"Stopped\"} 0\n")
+	assert.Contains(t, metrics, "crc_openshift_status{status=\"Degraded\"} 1\n")
+	assert.Contains(t, metrics, "crc_openshift_status{status=\"Running\"} 0\n")
+	assert.Contains(t, metrics, "crc_disk_used_bytes 1.073741824e+10\n")
+	assert.Contains(t, metrics, "crc_memory_allocated_bytes 8.589934592e+09\n")
+	assert.Contains(t, metrics, "crc_memory_pressure_ratio 0.75\n")
+	assert.Contains(t, metrics, "crc_certs_expiry_days 24.5\n")
+	assert.Contains(t, metrics, "crc_uptime_seconds 90\n")
+	assert.Contains(t, metrics, "crc_cluster_operator_available{name=\"authentication\"} 0\ncrc_cluster_operator_available{name=\"kube-apiserver\"} 1\n")
+	assert.Contains(t, metrics, "crc_cluster_operator_degraded{name=\"authentication\"} 1\n")
+}
+
+func TestWriteStopped(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, &types.ClusterStatusResult{
+		CrcStatus:       state.Stopped,
+		OpenshiftStatus: types.OpenshiftStopped,
+	}, time.Now()))
+	metrics := out.String()
+
+	assert.Contains(t, metrics, "crc_vm_state{state=\"Stopped\"} 1\n")
+	assert.NotContains(t, metrics, "crc_disk_used_bytes")
+	assert.NotContains(t, metrics, "crc_certs_expiry_days")
+	assert.NotContains(t, metrics, "crc_cluster_operator_available")
+}
+
+func TestFormatLabels(t *testing.T) {
+	assert.Equal(t, "", formatLabels(nil))
+	assert.Equal(t, `{name="a\"b\\c\nd",version="1"}`, formatLabels([]string{"name", "a\"b\\c\nd", "version", "1"}))
+}
+
+func TestHandler(t *testing.T) {
+	handler := NewHandler(func() (*types.ClusterStatusResult, error) {
+		return runningStatus, nil
+	})
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, ContentType, recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), "crc_vm_state{state=\"Running\"} 1\n")
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+
+	failingHandler := NewHandler(func() (*types.ClusterStatusResult, error) {
+		return nil, errors.New("status failed")
+	})
+	recorder = httptest.NewRecorder()
+	failingHandler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	assert.Equal(t, "status failed\n", recorder.Body.String())
+}
+
+type countingSource struct {
+	missing  bool
+	statuses int
+}
+
+func (s *countingSource) Exists() (bool, error) {
+	return !s.missing, nil
+}
+
+func (s *countingSource) Status() (*types.ClusterStatusResult, error) {
+	s.statuses++
+	return runningStatus, nil
+}
+
+func TestCachedStatus(t *testing.T) {
+	source := &countingSource{}
+	status := CachedStatus(source)
+	for i := 0; i < 3; i++ {
+		result, err := status()
+		require.NoError(t, err)
+		assert.Equal(t, runningStatus, result)
+	}
+	assert.Equal(t, 1, source.statuses)
+}
+
+func TestMissingVM(t *testing.T) {
+	source := &countingSource{missing: true}
+	status, err := CachedStatus(source)()
+	require.NoError(t, err)
+	assert.Equal(t, 0, source.statuses)
+
+	var out bytes.Buffer
+	require.NoError(t, Write(&out, status, time.Now()))
+	metrics := out.String()
+	assert.Contains(t, metrics, "crc_vm_state{state=\"DoesNotExist\"} 1\n")
+	assert.Contains(t, metrics, "crc_vm_state{state=\"Stopped\"} 0\n")
+	assert.NotContains(t, metrics, "crc_openshift_status")
+	assert.NotContains(t, metrics, "crc_uptime_seconds")
+}
@@ -0,0 +1,173 @@
+package network
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/spf13/cast"
+)
+
+const (
+	// DefaultNetworkCIDR is the subnet of the VM network with the system
+	// network mode on Linux
+	DefaultNetworkCIDR = "192.168.130.0/24"
+	// BridgeInterface is the host interface of the VM network, its route is
+	// the one of the subnet and does not conflict with it
+	BridgeInterface = "crc"
+
+	// the VM gets the 11th address of the subnet, the host the first one
+	instanceHostNumber = 11
+	hostHostNumber     = 1
+	// the smallest subnet holding the address of the VM
+	maxPrefixLength = 28
+	// libvirt only sends router advertisements, which give the VM its
+	// default route, for IPv6 subnets of this length
+	ipv6PrefixLength = 64
+)
+
+// Route is a route of the host
+type Route struct {
+	Interface   string
+	Destination *net.IPNet
+}
+
+// ParseNetworkCIDR parses the subnet of the VM network, an empty value is the
+// default subnet
+func ParseNetworkCIDR(cidr string) (*net.IPNet, error) {
+	if cidr == "" {
+		cidr = DefaultNetworkCIDR
+	}
+	ip, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid CIDR", cidr)
+	}
+	if ip.To4() == nil {
+		return nil, fmt.Errorf("%s is not an IPv4 subnet", cidr)
+	}
+	if !ip.Equal(subnet.IP) {
+		return nil, fmt.Errorf("%s is not the address of a subnet, use %s", cidr, subnet)
+	}
+	if ones, _ := subnet.Mask.Size(); ones > maxPrefixLength {
+		return nil, fmt.Errorf("%s is too small, the prefix length must be %d or less", cidr, maxPrefixLength)
+	}
+	return subnet, nil
+}
+
+// ParseNetworkIPv6CIDR parses the optional IPv6 subnet of the VM network, an
+// empty value returns a nil subnet and the VM network is IPv4-only
+func ParseNetworkIPv6CIDR(cidr string) (*net.IPNet, error) {
+	if cidr == "" {
+		return nil, nil
+	}
+	ip, subnet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not a valid CIDR", cidr)
+	}
+	if ip.To4() != nil {
+		return nil, fmt.Errorf("%s is not an IPv6 subnet", cidr)
+	}
+	if !ip.Equal(subnet.IP) {
+		return nil, fmt.Errorf("%s is not the address of a subnet, use %s", cidr, subnet)
+	}
+	if ones, _ := subnet.Mask.Size(); ones != ipv6PrefixLength {
+		return nil, fmt.Errorf("the prefix length of %s must be %d", cidr, ipv6PrefixLength)
+	}
+	return subnet, nil
+}
+
+// ValidateNetworkCIDR accepts an IPv4 subnet which does not overlap the routes
+// of the host
+func ValidateNetworkCIDR(value interface{}) (bool, string) {
+	subnet, err := ParseNetworkCIDR(cast.ToString(value))
+	if err != nil {
+		return false, err.Error()
+	}
+	if err := CheckNetworkOverlap(subnet); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// ValidateNetworkIPv6CIDR accepts an empty value or an IPv6 subnet, the
+// routes of the host are IPv4 ones and are not checked
+func ValidateNetworkIPv6CIDR(value interface{}) (bool, string) {
+	if _, err := ParseNetworkIPv6CIDR(cast.ToString(value)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// DetermineHostIP returns the address of the host in the IPv4 or IPv6 subnet
+// of the VM network, the VM reaches the host and the internet through it
+func DetermineHostIP(subnet *net.IPNet) net.IP {
+	return hostAddress(subnet, hostHostNumber)
+}
+
+// InstanceIP returns the address of the VM in the IPv4 or IPv6 subnet of the
+// VM network
+func InstanceIP(subnet *net.IPNet) net.IP {
+	return hostAddress(subnet, instanceHostNumber)
+}
+
+// NthInstanceIP returns the address of the VM with the given network index in
+// the IPv4 subnet of the VM network, the VM with the index 0 gets InstanceIP.
+// It fails when the subnet is too small for index.
+func NthInstanceIP(subnet *net.IPNet, index int) (net.IP, error) {
+	ones, bits := subnet.Mask.Size()
+	// the last address of the subnet is the broadcast one
+	if instanceHostNumber+index >= 1<<uint(bits-ones)-1 {
+		return nil, fmt.Errorf("%s has no address left for instance number %d, use a larger network-cidr", subnet, index+1)
+	}
+	return hostAddress(subnet, uint32(instanceHostNumber+index)), nil
+}
+
+// PrefixLength returns the length of the prefix of subnet
+func PrefixLength(subnet *net.IPNet) int {
+	ones, _ := subnet.Mask.Size()
+	return ones
+}
+
+// hostAddress adds number to the address of subnet, for IPv4 and IPv6
+// subnets. The subnets are large enough for the sum to fit in the last 4 bytes.
+func hostAddress(subnet *net.IPNet, number uint32) net.IP {
+	base := subnet.IP.To4()
+	if base == nil {
+		base = subnet.IP.To16()
+	}
+	ip := make(net.IP, len(base))
+	copy(ip, base)
+	last := len(ip) - net.IPv4len
+	binary.BigEndian.PutUint32(ip[last:], binary.BigEndian.Uint32(ip[last:])+number)
+	return ip
+}
+
+// CheckNetworkOverlap fails when subnet overlaps a route of the host, such as
+// the ones of a VPN, other than the default route and the one of the VM network
+func CheckNetworkOverlap(subnet *net.IPNet) error {
+	routes, err := hostRoutes()
+	if err != nil {
+		logging.Debugf("Cannot get the routes of the host: %v", err)
+		return nil
+	}
+	if route := overlappingRoute(subnet, routes); route != nil {
+		return fmt.Errorf("%s overlaps the route to %s of %s, choose another subnet", subnet, route.Destination, route.Interface)
+	}
+	return nil
+}
+
+func overlappingRoute(subnet *net.IPNet, routes []Route) *Route {
+	for i, route := range routes {
+		if route.Interface == BridgeInterface {
+			continue
+		}
+		if ones, _ := route.Destination.Mask.Size(); ones == 0 {
+			continue
+		}
+		if route.Destination.Contains(subnet.IP) || subnet.Contains(route.Destination.IP) {
+			return &routes[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNetworkCIDR(t *testing.T) {
+	subnet, err := ParseNetworkCIDR("")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultNetworkCIDR, subnet.String())
+
+	subnet, err = ParseNetworkCIDR("10.200.0.0/16")
+	require.NoError(t, err)
+	assert.Equal(t, "10.200.0.1", DetermineHostIP(subnet).String())
+	assert.Equal(t, "10.200.0.11", InstanceIP(subnet).String())
+	assert.Equal(t, 16, PrefixLength(subnet))
+
+	_, err = ParseNetworkCIDR("10.200.0.1/16")
+	assert.EqualError(t, err, "10.200.0.1/16 is not the address of a subnet, use 10.200.0.0/16")
+	_, err = ParseNetworkCIDR("10.200.0.0/29")
+	assert.EqualError(t, err, "10.200.0.0/29 is too small, the prefix length must be 28 or less")
+	_, err = ParseNetworkCIDR("fd00::/64")
+	assert.EqualError(t, err, "fd00::/64 is not an IPv4 subnet")
+	_, err = ParseNetworkCIDR("10.200.0.0")
+	assert.EqualError(t, err, "10.200.0.0 is not a valid CIDR")
+}
+
+func TestNthInstanceIP(t *testing.T) {
+	subnet, err := ParseNetworkCIDR("")
+	require.NoError(t, err)
+	ip, err := NthInstanceIP(subnet, 0)
+	require.NoError(t, err)
+	assert.Equal(t, InstanceIP(subnet), ip)
+	ip, err = NthInstanceIP(subnet, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.130.13", ip.String())
+
+	subnet, err = ParseNetworkCIDR("10.200.0.0/28")
+	require.NoError(t, err)
+	ip, err = NthInstanceIP(subnet, 3)
+	require.NoError(t, err)
+	assert.Equal(t, "10.200.0.14", ip.String())
+	_, err = NthInstanceIP(subnet, 4)
+	assert.EqualError(t, err, "10.200.0.0/28 has no address left for instance number 5, use a larger network-cidr")
+}
+
+func TestParseNetworkIPv6CIDR(t *testing.T) {
+	subnet, err := ParseNetworkIPv6CIDR("")
+	require.NoError(t, err)
+	assert.Nil(t, subnet)
+
+	subnet, err = ParseNetworkIPv6CIDR("fd00:130::/64")
+	require.NoError(t, err)
+	assert.Equal(t, "fd00:130::1", DetermineHostIP(subnet).String())
+	assert.Equal(t, "fd00:130::b", InstanceIP(subnet).String())
+	assert.Equal(t, 64, PrefixLength(subnet))
+
+	_, err = ParseNetworkIPv6CIDR("fd00:130::1/64")
+	assert.EqualError(t, err, "fd00:130::1/64 is not the address of a subnet, use fd00:130::/64")
+	_, err = ParseNetworkIPv6CIDR("fd00:130::/48")
+	assert.EqualError(t, err, "the prefix length of fd00:130::/48 must be 64")
+	_, err = ParseNetworkIPv6CIDR("10.200.0.0/16")
+	assert.EqualError(t, err, "10.200.0.0/16 is not an IPv6 subnet")
+}
+
+func TestOverlappingRoute(t *testing.T) {
+	route := func(iface, cidr string) Route {
+		_, destination, err := net.ParseCIDR(cidr)
+		require.NoError(t, err)
+		return Route{Interface: iface, Destination: destination}
+	}
+	routes := []Route{
+		route("eth0", "0.0.0.0/0"),
+		route("eth0", "192.168.1.0/24"),
+		route("tun0", "10.0.0.0/8"),
+		route(BridgeInterface, "192.168.130.0/24"),
+	}
+	subnet := func(cidr string) *net.IPNet {
+		subnet, err := ParseNetworkCIDR(cidr)
+		require.NoError(t, err)
+		return subnet
+	}
+
+	assert.Nil(t, overlappingRoute(subnet("192.168.130.0/24"), routes))
+	assert.Nil(t, overlappingRoute(subnet("172.30.0.0/24"), routes))
+	assert.Equal(t, "tun0", overlappingRoute(subnet("10.1.0.0/24"), routes).Interface)
+	assert.Equal(t, "eth0", overlappingRoute(subnet("192.168.0.0/16"), routes).Interface)
+}
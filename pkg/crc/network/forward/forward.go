@@ -0,0 +1,92 @@
+// Package forward forwards ports of the host to ports of the VM, so that
+// services of the cluster such as the node ports or the internal registry
+// can be reached on localhost.
+package forward
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// defaultHost is the host address of the forwards declared without one,
+// they are not reachable from the network
+const defaultHost = "127.0.0.1"
+
+// Forward forwards the connections to a host address to a port of the VM
+type Forward struct {
+	// Local is the host address the connections are accepted on, such as
+	// 127.0.0.1:8080, or :8080 for all the interfaces
+	Local string `json:"local"`
+	// Remote is the port of the VM the connections are forwarded to
+	Remote int `json:"remote"`
+}
+
+// Parse parses a forward written as [host:]port:vm-port
+func Parse(spec string) (Forward, error) {
+	i := strings.LastIndex(spec, ":")
+	if i == -1 {
+		return Forward{}, fmt.Errorf("'%s' is not a valid port forward, expected [host:]port:vm-port", spec)
+	}
+	remote, err := strconv.Atoi(spec[i+1:])
+	if err != nil {
+		return Forward{}, fmt.Errorf("'%s' is not a valid port forward, invalid VM port '%s'", spec, spec[i+1:])
+	}
+	return New(spec[:i], remote)
+}
+
+// New returns the forward of the host address or the host port local to
+// the VM port remote
+func New(local string, remote int) (Forward, error) {
+	if !strings.Contains(local, ":") {
+		local = net.JoinHostPort(defaultHost, local)
+	}
+	forward := Forward{Local: local, Remote: remote}
+	if err := forward.Validate(); err != nil {
+		return Forward{}, err
+	}
+	return forward, nil
+}
+
+// Validate checks that the addresses of forward are valid
+func (forward Forward) Validate() error {
+	host, port, err := net.SplitHostPort(forward.Local)
+	if err != nil {
+		return fmt.Errorf("'%s' is not a valid host address: %v", forward.Local, err)
+	}
+	if host != "" && net.ParseIP(host) == nil {
+		return fmt.Errorf("'%s' is not a valid host address, the host must be an IP address", forward.Local)
+	}
+	if !isPort(port) {
+		return fmt.Errorf("'%s' is not a valid host address, invalid port '%s'", forward.Local, port)
+	}
+	if forward.Remote < 1 || forward.Remote > 65535 {
+		return fmt.Errorf("%d is not a valid VM port", forward.Remote)
+	}
+	return nil
+}
+
+// LocalPort returns the host port of forward
+func (forward Forward) LocalPort() string {
+	_, port, err := net.SplitHostPort(forward.Local)
+	if err != nil {
+		return ""
+	}
+	return port
+}
+
+// matches returns true when local designates forward, as its host address
+// or its host port
+func (forward Forward) matches(local string) bool {
+	return local == forward.Local || local == forward.LocalPort()
+}
+
+func (forward Forward) String() string {
+	return fmt.Sprintf("%s -> %d", forward.Local, forward.Remote)
+}
+
+func isPort(port string) bool {
+	value, err := strconv.Atoi(port)
+	return err == nil && value >= 1 && value <= 65535
+}
@@ -0,0 +1,108 @@
+package forward
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for spec, expected := range map[string]Forward{
+		"8080:30080":         {Local: "127.0.0.1:8080", Remote: 30080},
+		"0.0.0.0:8080:30080": {Local: "0.0.0.0:8080", Remote: 30080},
+		":8080:30080":        {Local: ":8080", Remote: 30080},
+		"[::1]:5000:5000":    {Local: "[::1]:5000", Remote: 5000},
+	} {
+		forward, err := Parse(spec)
+		require.NoError(t, err, spec)
+		assert.Equal(t, expected, forward)
+	}
+	for _, spec := range []string{"8080", "8080:", "localhost:8080:30080", "0:30080", "8080:70000", "a:b"} {
+		_, err := Parse(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "port-forwards.json")
+	forwards, err := Load(path)
+	require.NoError(t, err)
+	assert.Empty(t, forwards)
+
+	require.NoError(t, Add(path, Forward{Local: "127.0.0.1:8080", Remote: 30080}))
+	require.NoError(t, Add(path, Forward{Local: ":5000", Remote: 5000}))
+	assert.EqualError(t, Add(path, Forward{Local: "0.0.0.0:8080", Remote: 30081}), "port 8080 is already forwarded (127.0.0.1:8080 -> 30080)")
+	forwards, err = Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Forward{{Local: "127.0.0.1:8080", Remote: 30080}, {Local: ":5000", Remote: 5000}}, forwards)
+
+	require.NoError(t, Remove(path, "8080"))
+	assert.EqualError(t, Remove(path, "8080"), "8080 is not forwarded")
+	require.NoError(t, Remove(path, ":5000"))
+	assert.NoFileExists(t, path)
+}
+
+func echoServer(t *testing.T) (string, int) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					fmt.Fprintf(conn, "echo %s\n", scanner.Text())
+				}
+			}()
+		}
+	}()
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return host, portNumber
+}
+
+func freeAddress(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+func TestProxies(t *testing.T) {
+	host, port := echoServer(t)
+	local := freeAddress(t)
+	proxies := NewProxies()
+	defer proxies.Close()
+
+	assert.Empty(t, proxies.Sync(map[Forward]string{{Local: local, Remote: port}: host}))
+	conn, err := net.Dial("tcp", local)
+	require.NoError(t, err)
+	defer conn.Close()
+	fmt.Fprintln(conn, "hello")
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "echo hello\n", reply)
+
+	// the forwards are stopped with their connections
+	assert.Empty(t, proxies.Sync(nil))
+	_, err = bufio.NewReader(conn).ReadString('\n')
+	assert.Error(t, err)
+	_, err = net.Dial("tcp", local)
+	assert.Error(t, err)
+
+	errs := proxies.Sync(map[Forward]string{{Local: "192.0.2.1:8080", Remote: port}: host})
+	assert.Error(t, errs["192.0.2.1:8080"])
+}
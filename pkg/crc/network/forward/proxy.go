@@ -0,0 +1,162 @@
+package forward
+
+import (
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+const dialTimeout = 10 * time.Second
+
+// Proxies forwards the connections of the forwards to the VM with TCP
+// proxies, for the networking modes in which the VM has its own IP address
+type Proxies struct {
+	mu sync.Mutex
+	// running proxies, by host address
+	proxies map[string]*proxy
+}
+
+func NewProxies() *Proxies {
+	return &Proxies{
+		proxies: map[string]*proxy{},
+	}
+}
+
+// Sync starts and stops the proxies so that the connections to the host
+// addresses of the forwards are forwarded to their remote host, the IP of
+// their VM. It returns the errors of the forwards which cannot be started,
+// by host address.
+func (p *Proxies) Sync(forwards map[Forward]string) map[string]error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	wanted := map[string]string{}
+	for forward, remoteHost := range forwards {
+		wanted[forward.Local] = net.JoinHostPort(remoteHost, strconv.Itoa(forward.Remote))
+	}
+	for local, proxy := range p.proxies {
+		if wanted[local] != proxy.target {
+			logging.Debugf("Stopping the forward of %s to %s", local, proxy.target)
+			proxy.close()
+			delete(p.proxies, local)
+		}
+	}
+
+	errs := map[string]error{}
+	for local, target := range wanted {
+		if _, ok := p.proxies[local]; ok {
+			continue
+		}
+		proxy, err := listen(local, target)
+		if err != nil {
+			errs[local] = err
+			continue
+		}
+		logging.Debugf("Forwarding %s to %s", local, target)
+		p.proxies[local] = proxy
+	}
+	return errs
+}
+
+// Close stops all the proxies
+func (p *Proxies) Close() {
+	p.Sync(nil)
+}
+
+type proxy struct {
+	target   string
+	listener net.Listener
+
+	mu     sync.Mutex
+	conns  map[net.Conn]struct{}
+	closed bool
+}
+
+func listen(local, target string) (*proxy, error) {
+	listener, err := net.Listen("tcp", local)
+	if err != nil {
+		return nil, err
+	}
+	proxy := &proxy{
+		target:   target,
+		listener: listener,
+		conns:    map[net.Conn]struct{}{},
+	}
+	go proxy.serve()
+	return proxy, nil
+}
+
+func (p *proxy) serve() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		go p.handle(conn)
+	}
+}
+
+func (p *proxy) handle(conn net.Conn) {
+	remote, err := net.DialTimeout("tcp", p.target, dialTimeout)
+	if err != nil {
+		logging.Debugf("Cannot forward the connection from %s to %s: %v", conn.RemoteAddr(), p.target, err)
+		conn.Close()
+		return
+	}
+	if !p.track(conn, remote) {
+		return
+	}
+	defer p.untrack(conn, remote)
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(remote, conn)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(conn, remote)
+		done <- struct{}{}
+	}()
+	// the connection ends as soon as one of its sides is closed
+	<-done
+}
+
+// track registers the connections so that they are closed with the proxy,
+// they are closed right away when the proxy is already closed
+func (p *proxy) track(conns ...net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		for _, conn := range conns {
+			conn.Close()
+		}
+		return false
+	}
+	for _, conn := range conns {
+		p.conns[conn] = struct{}{}
+	}
+	return true
+}
+
+func (p *proxy) untrack(conns ...net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, conn := range conns {
+		conn.Close()
+		delete(p.conns, conn)
+	}
+}
+
+func (p *proxy) close() {
+	p.listener.Close()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for conn := range p.conns {
+		conn.Close()
+	}
+}
@@ -0,0 +1,75 @@
+package forward
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// Load returns the forwards stored in path, there are none when it does not
+// exist
+func Load(path string) ([]Forward, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var forwards []Forward
+	if err := json.Unmarshal(content, &forwards); err != nil {
+		return nil, fmt.Errorf("cannot parse the port forwards in %s: %v", path, err)
+	}
+	return forwards, nil
+}
+
+func save(path string, forwards []Forward) error {
+	if len(forwards) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	content, err := json.MarshalIndent(forwards, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0600)
+}
+
+// Add stores forward in path, the host port must not be forwarded already
+func Add(path string, forward Forward) error {
+	if err := forward.Validate(); err != nil {
+		return err
+	}
+	forwards, err := Load(path)
+	if err != nil {
+		return err
+	}
+	for _, existing := range forwards {
+		if existing.LocalPort() == forward.LocalPort() {
+			return fmt.Errorf("port %s is already forwarded (%s)", forward.LocalPort(), existing)
+		}
+	}
+	return save(path, append(forwards, forward))
+}
+
+// Remove removes the forward of the host address or the host port local
+// from path
+func Remove(path string, local string) error {
+	forwards, err := Load(path)
+	if err != nil {
+		return err
+	}
+	var kept []Forward
+	for _, forward := range forwards {
+		if !forward.matches(local) {
+			kept = append(kept, forward)
+		}
+	}
+	if len(kept) == len(forwards) {
+		return fmt.Errorf("%s is not forwarded", local)
+	}
+	return save(path, kept)
+}
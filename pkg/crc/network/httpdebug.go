@@ -0,0 +1,39 @@
+package network
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+// debugTransport logs the requests sent through transport, without their
+// headers and bodies which may contain credentials
+type debugTransport struct {
+	transport http.RoundTripper
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.transport.RoundTrip(req)
+	if err != nil {
+		logging.DebugfFor(logging.HTTPDebug, "HTTP %s %s failed after %v: %v", req.Method, redactedURL(req), time.Since(start), err)
+		return resp, err
+	}
+	logging.DebugfFor(logging.HTTPDebug, "HTTP %s %s: %s in %v (%d bytes)", req.Method, redactedURL(req), resp.Status, time.Since(start), resp.ContentLength)
+	return resp, err
+}
+
+func redactedURL(req *http.Request) string {
+	u := *req.URL
+	u.User = nil
+	u.RawQuery = ""
+	return u.String()
+}
+
+func withDebugLogs(transport http.RoundTripper) http.RoundTripper {
+	if !logging.IsDebugEnabled(logging.HTTPDebug) {
+		return transport
+	}
+	return &debugTransport{transport: transport}
+}
@@ -0,0 +1,32 @@
+package network
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	crcerrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+)
+
+// WaitForInstanceIPv6 waits for the VM to hold its address in the IPv6 subnet
+// of the VM network, the DHCPv6 server of libvirt gives it after the IPv4 one
+func WaitForInstanceIPv6(ctx context.Context, sshRunner *ssh.Runner, subnet *net.IPNet) (string, error) {
+	ip := InstanceIP(subnet).String()
+	hasAddress := func() error {
+		out, _, err := sshRunner.Run("ip", "-o", "-6", "addr", "show", "to", ip+"/128")
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(out) == "" {
+			return &crcerrors.RetriableError{Err: fmt.Errorf("the VM does not have the address %s", ip)}
+		}
+		return nil
+	}
+	if err := crcerrors.Retry(ctx, time.Minute, hasAddress, 2*time.Second); err != nil {
+		return "", err
+	}
+	return ip, nil
+}
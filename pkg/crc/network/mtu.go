@@ -0,0 +1,146 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/ssh"
+	"github.com/spf13/cast"
+)
+
+const (
+	// DefaultMTU is the MTU of the network interface of the VM in the bundle
+	DefaultMTU = 1500
+	// MinMTU is the smallest MTU which can carry IPv6 traffic
+	MinMTU = 1280
+	// MaxMTU is the MTU of jumbo frames
+	MaxMTU = 9000
+
+	// address of a public DNS server, only used to find the route to the
+	// internet, no packet is sent to it
+	routeProbeAddress = "8.8.8.8:53"
+)
+
+type interfaceAddrs struct {
+	name  string
+	mtu   int
+	addrs []net.Addr
+}
+
+// HostMTU returns the MTU of the host network interface used to reach the
+// internet, which is the one of the VPN interface when a VPN is up
+func HostMTU() (int, error) {
+	conn, err := net.Dial("udp", routeProbeAddress)
+	if err != nil {
+		return 0, fmt.Errorf("Cannot find the route to the internet: %w", err)
+	}
+	localAddr := conn.LocalAddr().(*net.UDPAddr)
+	conn.Close()
+
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return 0, err
+	}
+	var candidates []interfaceAddrs
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			logging.Debugf("Cannot get the addresses of %s: %v", iface.Name, err)
+			continue
+		}
+		candidates = append(candidates, interfaceAddrs{
+			name:  iface.Name,
+			mtu:   iface.MTU,
+			addrs: addrs,
+		})
+	}
+	iface, err := interfaceWithIP(candidates, localAddr.IP)
+	if err != nil {
+		return 0, err
+	}
+	logging.Debugf("The route to the internet goes through %s, its MTU is %d", iface.name, iface.mtu)
+	return iface.mtu, nil
+}
+
+func interfaceWithIP(ifaces []interfaceAddrs, ip net.IP) (interfaceAddrs, error) {
+	for _, iface := range ifaces {
+		for _, addr := range iface.addrs {
+			if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+				return iface, nil
+			}
+		}
+	}
+	return interfaceAddrs{}, fmt.Errorf("Cannot find the network interface with the address %s", ip)
+}
+
+// ValidateMTU accepts 0, which detects the MTU from the host network, or an
+// MTU between MinMTU and MaxMTU
+func ValidateMTU(value interface{}) (bool, string) {
+	mtu, err := cast.ToIntE(value)
+	if err != nil || (mtu != 0 && (mtu < MinMTU || mtu > MaxMTU)) {
+		return false, fmt.Sprintf("MTU must be 0 or an integer between %d and %d", MinMTU, MaxMTU)
+	}
+	return true, ""
+}
+
+// InstanceMTU returns the MTU of the VM network interface. configuredMTU is
+// used when it is not zero, otherwise the default MTU is lowered to hostMTU
+// when the host network cannot carry it. clamped is true in the latter case.
+func InstanceMTU(configuredMTU, hostMTU int) (mtu int, clamped bool) {
+	if configuredMTU != 0 {
+		return configuredMTU, false
+	}
+	if hostMTU >= MinMTU && hostMTU < DefaultMTU {
+		return hostMTU, true
+	}
+	return DefaultMTU, false
+}
+
+// ConfigureInstanceMTU sets the MTU of the interface of the default route of
+// the VM. When it is lower than the default one, the MSS of the TCP
+// connections forwarded by the VM, such as the ones of the pods, is clamped
+// to the MTU of the route since the overlay network of the cluster keeps its
+// own MTU.
+func ConfigureInstanceMTU(sshRunner *ssh.Runner, mtu int) error {
+	route, _, err := sshRunner.Run("ip -o route show default")
+	if err != nil {
+		return fmt.Errorf("Cannot get the default route of the VM: %w", err)
+	}
+	iface, err := defaultRouteInterface(route)
+	if err != nil {
+		return err
+	}
+	if _, stderr, err := sshRunner.RunPrivileged(fmt.Sprintf("Setting the MTU of %s", iface), "ip", "link", "set", "dev", iface, "mtu", fmt.Sprint(mtu)); err != nil {
+		return fmt.Errorf("Failed to set the MTU of %s %v: %s", iface, err, stderr)
+	}
+
+	clampRule := fmt.Sprintf("FORWARD -o %s -p tcp --tcp-flags SYN,RST SYN -j TCPMSS --clamp-mss-to-pmtu", iface)
+	_, _, err = sshRunner.RunPrivileged("Checking the TCP MSS clamping rule", "sh", "-c", fmt.Sprintf("'iptables -t mangle -C %s'", clampRule))
+	ruleExists := err == nil
+	switch {
+	case mtu < DefaultMTU && !ruleExists:
+		if _, stderr, err := sshRunner.RunPrivileged("Enabling TCP MSS clamping", "sh", "-c", fmt.Sprintf("'iptables -t mangle -A %s'", clampRule)); err != nil {
+			return fmt.Errorf("Failed to enable TCP MSS clamping %v: %s", err, stderr)
+		}
+	case mtu >= DefaultMTU && ruleExists:
+		if _, stderr, err := sshRunner.RunPrivileged("Disabling TCP MSS clamping", "sh", "-c", fmt.Sprintf("'iptables -t mangle -D %s'", clampRule)); err != nil {
+			return fmt.Errorf("Failed to disable TCP MSS clamping %v: %s", err, stderr)
+		}
+	}
+	return nil
+}
+
+// defaultRouteInterface parses the output of `ip -o route show default`
+func defaultRouteInterface(routes string) (string, error) {
+	for _, line := range strings.Split(routes, "\n") {
+		fields := strings.Fields(line)
+		for i := 0; i+1 < len(fields); i++ {
+			if fields[i] == "dev" {
+				return fields[i+1], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("The VM has no default route")
+}
@@ -0,0 +1,77 @@
+package network
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterfaceWithIP(t *testing.T) {
+	ifaces := []interfaceAddrs{
+		{
+			name:  "eth0",
+			mtu:   1500,
+			addrs: []net.Addr{&net.IPNet{IP: net.ParseIP("192.168.1.10"), Mask: net.CIDRMask(24, 32)}},
+		},
+		{
+			name: "tun0",
+			mtu:  1360,
+			addrs: []net.Addr{
+				&net.IPNet{IP: net.ParseIP("fe80::1"), Mask: net.CIDRMask(64, 128)},
+				&net.IPNet{IP: net.ParseIP("10.8.0.2"), Mask: net.CIDRMask(24, 32)},
+			},
+		},
+	}
+	iface, err := interfaceWithIP(ifaces, net.ParseIP("10.8.0.2"))
+	require.NoError(t, err)
+	assert.Equal(t, "tun0", iface.name)
+	assert.Equal(t, 1360, iface.mtu)
+
+	_, err = interfaceWithIP(ifaces, net.ParseIP("172.16.0.1"))
+	assert.Error(t, err)
+}
+
+func TestInstanceMTU(t *testing.T) {
+	mtu, clamped := InstanceMTU(0, 1500)
+	assert.Equal(t, DefaultMTU, mtu)
+	assert.False(t, clamped)
+
+	mtu, clamped = InstanceMTU(0, 1360)
+	assert.Equal(t, 1360, mtu)
+	assert.True(t, clamped)
+
+	mtu, clamped = InstanceMTU(0, 9000)
+	assert.Equal(t, DefaultMTU, mtu)
+	assert.False(t, clamped)
+
+	// the host MTU is unknown
+	mtu, clamped = InstanceMTU(0, 0)
+	assert.Equal(t, DefaultMTU, mtu)
+	assert.False(t, clamped)
+
+	mtu, clamped = InstanceMTU(1400, 1360)
+	assert.Equal(t, 1400, mtu)
+	assert.False(t, clamped)
+}
+
+func TestValidateMTU(t *testing.T) {
+	ok, _ := ValidateMTU(0)
+	assert.True(t, ok)
+	ok, _ = ValidateMTU("1400")
+	assert.True(t, ok)
+	ok, _ = ValidateMTU(576)
+	assert.False(t, ok)
+	ok, _ = ValidateMTU("auto")
+	assert.False(t, ok)
+}
+
+func TestDefaultRouteInterface(t *testing.T) {
+	iface, err := defaultRouteInterface("default via 192.168.130.1 dev ens3 proto dhcp metric 100 \n")
+	require.NoError(t, err)
+	assert.Equal(t, "ens3", iface)
+
+	_, err = defaultRouteInterface("")
+	assert.Error(t, err)
+}
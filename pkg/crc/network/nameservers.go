@@ -54,13 +54,25 @@ func AddNameserversToInstance(sshRunner *ssh.Runner, nameservers []NameServer) e
 
 // writes nameserver to the /etc/resolv.conf inside the instance
 func addNameserverToInstance(sshRunner *ssh.Runner, nameserver NameServer) error {
-	_, _, err := sshRunner.Run(fmt.Sprintf("NS=%s; cat /etc/resolv.conf |grep -i \"^nameserver $NS\" || echo \"nameserver $NS\" | sudo tee -a /etc/resolv.conf", nameserver.IPAddress))
+	_, _, err := sshRunner.Run(fmt.Sprintf("NS=%s; cat /etc/resolv.conf |grep -i \"^nameserver $NS\" || echo \"nameserver $NS\" | %s", nameserver.IPAddress, sshRunner.PrivilegedCommand("tee -a /etc/resolv.conf")))
 	if err != nil {
 		return fmt.Errorf("%s: %s", "Error adding nameserver", err.Error())
 	}
 	return nil
 }
 
+// RemoveNameserversFromInstance removes nameservers from the
+// /etc/resolv.conf file inside the instance.
+func RemoveNameserversFromInstance(sshRunner *ssh.Runner, nameservers []NameServer) error {
+	for _, ns := range nameservers {
+		pattern := fmt.Sprintf("/^nameserver %s$/d", strings.ReplaceAll(ns.IPAddress, ".", "\\."))
+		if _, _, err := sshRunner.RunPrivileged(fmt.Sprintf("Removing nameserver %s", ns.IPAddress), "sed", "-i", fmt.Sprintf("'%s'", pattern), "/etc/resolv.conf"); err != nil {
+			return fmt.Errorf("%s: %s", "Error removing nameserver", err.Error())
+		}
+	}
+	return nil
+}
+
 func GetResolvValuesFromHost() (*ResolvFileValues, error) {
 	// TODO: we need to add runtime OS in case of windows.
 	out, err := ioutil.ReadFile("/etc/resolv.conf")
@@ -0,0 +1,33 @@
+package network
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+)
+
+// PACTargetURL is the URL the PAC file is evaluated for, the images of the
+// cluster are pulled from this registry
+const PACTargetURL = "https://quay.io/"
+
+// ValidatePACFile checks that pacLocation is an HTTP URL or the path of an
+// existing file
+func ValidatePACFile(pacLocation string) error {
+	if pacLocation == "" {
+		return nil
+	}
+	pacURL, err := url.Parse(pacLocation)
+	if err == nil && (pacURL.Scheme == "http" || pacURL.Scheme == "https") {
+		if pacURL.Host == "" {
+			return fmt.Errorf("PAC file URL '%s' is not valid", pacLocation)
+		}
+		return nil
+	}
+	if err == nil && pacURL.Scheme == "file" {
+		pacLocation = pacURL.Path
+	}
+	if _, err := os.Stat(pacLocation); err != nil {
+		return fmt.Errorf("PAC file '%s' is neither an HTTP URL nor an existing file", pacLocation)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+// Package pac evaluates the proxy auto-config (PAC) files selecting the proxy
+// of crc and of the cluster
+package pac
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/download"
+	crcos "github.com/code-ready/crc/pkg/os"
+	"github.com/pkg/errors"
+)
+
+// cacheDuration is how long the result of the evaluation of a PAC file is
+// reused, the PAC file is fetched and evaluated again afterwards
+const cacheDuration = 5 * time.Minute
+
+type cacheEntry struct {
+	proxyURL string
+	expiry   time.Time
+}
+
+var (
+	cacheLock sync.Mutex
+	cache     = map[string]cacheEntry{}
+)
+
+// ProxyFor returns the proxy URL selected by the PAC file at pacLocation, a
+// URL or a path, for the connections to target. It is empty when the
+// connections are direct. crc has no JavaScript interpreter, the PAC file is
+// evaluated by pactester from pacparser. The result is cached for a few
+// minutes.
+func ProxyFor(pacLocation, target string) (string, error) {
+	key := pacLocation + " " + target
+	cacheLock.Lock()
+	entry, ok := cache[key]
+	cacheLock.Unlock()
+	if ok && time.Now().Before(entry.expiry) {
+		return entry.proxyURL, nil
+	}
+
+	proxyURL, err := evaluate(pacLocation, target)
+	if err != nil {
+		return "", err
+	}
+	cacheLock.Lock()
+	cache[key] = cacheEntry{proxyURL: proxyURL, expiry: time.Now().Add(cacheDuration)}
+	cacheLock.Unlock()
+	return proxyURL, nil
+}
+
+func evaluate(pacLocation, target string) (string, error) {
+	if _, err := exec.LookPath("pactester"); err != nil {
+		return "", errors.New("pactester, from pacparser, is needed to evaluate PAC files")
+	}
+	pacFile, cleanup, err := fetchPACFile(pacLocation)
+	if err != nil {
+		return "", err
+	}
+	defer cleanup()
+
+	stdout, stderr, err := crcos.RunWithDefaultLocale("pactester", "-p", pacFile, "-u", target)
+	if err != nil {
+		return "", fmt.Errorf("Failed to evaluate %s: %v: %s", pacLocation, err, stderr)
+	}
+	return parsePACResult(stdout)
+}
+
+// fetchPACFile returns the path of the PAC file, PAC files served over HTTP
+// are downloaded to a temporary file removed by the returned function
+func fetchPACFile(pacLocation string) (string, func(), error) {
+	pacURL, err := url.Parse(pacLocation)
+	if err != nil || (pacURL.Scheme != "http" && pacURL.Scheme != "https") {
+		if err == nil && pacURL.Scheme == "file" {
+			pacLocation = pacURL.Path
+		}
+		return pacLocation, func() {}, nil
+	}
+
+	// the PAC file tells which proxy to use, it is not downloaded through one
+	downloader := download.New()
+	downloader.Transport = network.DirectHTTPTransport()
+	downloader.Timeout = 30 * time.Second
+	content, err := downloader.InMemory(pacLocation)
+	if err != nil {
+		return "", nil, errors.Wrapf(err, "Cannot download %s", pacLocation)
+	}
+	file, err := ioutil.TempFile("", "crc-proxy-*.pac")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() {
+		_ = os.Remove(file.Name())
+	}
+	_, err = file.Write(content)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return file.Name(), cleanup, nil
+}
+
+// parsePACResult returns the URL of the first proxy of the result of
+// FindProxyForURL, such as 'PROXY proxy.example.com:3128; DIRECT', crio and
+// the kubelet do not support the SOCKS proxies
+func parsePACResult(result string) (string, error) {
+	for _, entry := range strings.Split(strings.TrimSpace(result), ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		switch strings.ToUpper(fields[0]) {
+		case "DIRECT":
+			return "", nil
+		case "PROXY", "HTTP":
+			if len(fields) == 2 {
+				return "http://" + fields[1], nil
+			}
+		case "HTTPS":
+			if len(fields) == 2 {
+				return "https://" + fields[1], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("No supported proxy in the PAC result '%s'", strings.TrimSpace(result))
+}
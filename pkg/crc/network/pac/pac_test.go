@@ -0,0 +1,63 @@
+package pac
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePACResult(t *testing.T) {
+	proxyURL, err := parsePACResult("PROXY proxy.example.com:3128; DIRECT\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://proxy.example.com:3128", proxyURL)
+
+	proxyURL, err = parsePACResult("SOCKS socks.example.com:1080; HTTPS proxy.example.com:3129")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://proxy.example.com:3129", proxyURL)
+
+	proxyURL, err = parsePACResult("DIRECT")
+	assert.NoError(t, err)
+	assert.Equal(t, "", proxyURL)
+
+	_, err = parsePACResult("SOCKS5 socks.example.com:1080")
+	assert.EqualError(t, err, "No supported proxy in the PAC result 'SOCKS5 socks.example.com:1080'")
+}
+
+func TestFetchPACFile(t *testing.T) {
+	const pac = `function FindProxyForURL(url, host) { return "PROXY proxy.example.com:3128"; }`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(pac))
+	}))
+	defer server.Close()
+
+	path, cleanup, err := fetchPACFile(server.URL + "/proxy.pac")
+	require.NoError(t, err)
+	content, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, pac, string(content))
+	cleanup()
+	assert.NoFileExists(t, path)
+
+	path, _, err = fetchPACFile("file:///etc/proxy.pac")
+	assert.NoError(t, err)
+	assert.Equal(t, "/etc/proxy.pac", path)
+}
+
+func TestProxyForCache(t *testing.T) {
+	cacheLock.Lock()
+	cache["http://wpad.example.com/wpad.dat https://quay.io/"] = cacheEntry{
+		proxyURL: "http://proxy.example.com:3128",
+		expiry:   time.Now().Add(time.Minute),
+	}
+	cacheLock.Unlock()
+
+	// served from the cache, without pactester nor download
+	proxyURL, err := ProxyFor("http://wpad.example.com/wpad.dat", "https://quay.io/")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://proxy.example.com:3128", proxyURL)
+}
@@ -0,0 +1,21 @@
+package network
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePACFile(t *testing.T) {
+	pacFile := filepath.Join(t.TempDir(), "proxy.pac")
+	require.NoError(t, ioutil.WriteFile(pacFile, []byte{}, 0600))
+
+	assert.NoError(t, ValidatePACFile(""))
+	assert.NoError(t, ValidatePACFile("http://wpad.example.com/wpad.dat"))
+	assert.NoError(t, ValidatePACFile(pacFile))
+	assert.Error(t, ValidatePACFile("http:///wpad.dat"))
+	assert.Error(t, ValidatePACFile(filepath.Join(t.TempDir(), "missing.pac")))
+}
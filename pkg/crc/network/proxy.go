@@ -7,7 +7,6 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
 
 	"github.com/code-ready/crc/pkg/crc/logging"
@@ -19,7 +18,7 @@ import (
 
 var (
 	DefaultProxy     ProxyConfig
-	defaultNoProxies = []string{"127.0.0.1", "localhost"}
+	defaultNoProxies = []string{"127.0.0.1", "::1", "localhost"}
 )
 
 // ProxyConfig keeps the proxy configuration for the current environment
@@ -55,34 +54,105 @@ func trimTrailingEOL(s string) string {
 	return strings.TrimRight(s, "\n")
 }
 
+// ProxySettings are the proxy settings of the user, the proxy environment
+// variables are used when they are empty
+type ProxySettings struct {
+	HTTPProxy   string
+	HTTPSProxy  string
+	NoProxy     string
+	ProxyCAFile string
+	// credentials added to the proxy URLs which have none, so that they are
+	// not part of the URLs shown in the configuration and the logs
+	Username string
+	Password string
+	// PACFile is the URL or the path of the proxy auto-config file selecting
+	// the proxy when HTTPProxy and HTTPSProxy are empty
+	PACFile string
+	// EvaluatePAC returns the proxy URL selected by PACFile for target. The
+	// PAC file is ignored when it is nil, evaluating it needs a download and
+	// an external program, only the commands changing the proxy of the
+	// cluster do it.
+	EvaluatePAC func(pacFile, target string) (string, error)
+}
+
 func NewProxyDefaults(httpProxy, httpsProxy, noProxy, proxyCAFile string) (*ProxyConfig, error) {
-	proxyCAData, err := readProxyCAData(proxyCAFile)
+	return NewProxyDefaultsFromSettings(ProxySettings{
+		HTTPProxy:   httpProxy,
+		HTTPSProxy:  httpsProxy,
+		NoProxy:     noProxy,
+		ProxyCAFile: proxyCAFile,
+	})
+}
+
+func NewProxyDefaultsFromSettings(settings ProxySettings) (*ProxyConfig, error) {
+	proxyCAData, err := readProxyCAData(settings.ProxyCAFile)
 	if err != nil {
-		return nil, errors.Wrapf(err, "not able to read proxy CA data from %s", proxyCAFile)
+		return nil, errors.Wrapf(err, "not able to read proxy CA data from %s", settings.ProxyCAFile)
 	}
 
 	DefaultProxy = ProxyConfig{
-		HTTPProxy:   httpProxy,
-		HTTPSProxy:  httpsProxy,
+		HTTPProxy:   settings.HTTPProxy,
+		HTTPSProxy:  settings.HTTPSProxy,
 		ProxyCACert: proxyCAData,
-		ProxyCAFile: proxyCAFile,
+		ProxyCAFile: settings.ProxyCAFile,
+	}
+	fromPAC := false
+	if DefaultProxy.HTTPProxy == "" && DefaultProxy.HTTPSProxy == "" && settings.PACFile != "" && settings.EvaluatePAC != nil {
+		proxyURL, err := settings.EvaluatePAC(settings.PACFile, PACTargetURL)
+		if err != nil {
+			logging.Warnf("Cannot get the proxy from %s, using the proxy environment variables: %v", settings.PACFile, err)
+		} else {
+			fromPAC = true
+			DefaultProxy.HTTPSProxy = proxyURL
+			// an https:// URL is not valid for the HTTP proxy
+			if strings.HasPrefix(proxyURL, "http://") {
+				DefaultProxy.HTTPProxy = proxyURL
+			}
+		}
 	}
 	envProxy := httpproxy.FromEnvironment()
 
-	if DefaultProxy.HTTPProxy == "" {
+	if DefaultProxy.HTTPProxy == "" && !fromPAC {
 		DefaultProxy.HTTPProxy = envProxy.HTTPProxy
 	}
-	if DefaultProxy.HTTPSProxy == "" {
+	if DefaultProxy.HTTPSProxy == "" && !fromPAC {
 		DefaultProxy.HTTPSProxy = envProxy.HTTPSProxy
 	}
+	noProxy := settings.NoProxy
 	if noProxy == "" {
 		noProxy = envProxy.NoProxy
 	}
 	DefaultProxy.setNoProxyString(noProxy)
 
+	if settings.Username != "" {
+		if DefaultProxy.HTTPProxy, err = withCredentials(DefaultProxy.HTTPProxy, settings.Username, settings.Password); err != nil {
+			return nil, err
+		}
+		if DefaultProxy.HTTPSProxy, err = withCredentials(DefaultProxy.HTTPSProxy, settings.Username, settings.Password); err != nil {
+			return nil, err
+		}
+	}
+
 	return NewProxyConfig()
 }
 
+// withCredentials adds the credentials to proxyURL, the credentials of the URL
+// take precedence
+func withCredentials(proxyURL, username, password string) (string, error) {
+	if proxyURL == "" {
+		return "", nil
+	}
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return "", fmt.Errorf("Proxy URL '%s' is not valid", proxyURL)
+	}
+	if u.User != nil {
+		return proxyURL, nil
+	}
+	u.User = url.UserPassword(username, password)
+	return u.String(), nil
+}
+
 // NewProxyConfig creates a proxy configuration with the specified parameters. If an empty string is passed
 // the corresponding environment variable is checked.
 func NewProxyConfig() (*ProxyConfig, error) {
@@ -139,26 +209,6 @@ func (p *ProxyConfig) GetNoProxyString() string {
 	return strings.Join(p.noProxy, ",")
 }
 
-// Sets the current config as environment variables in the current process.
-func (p *ProxyConfig) ApplyToEnvironment() {
-	if !p.IsEnabled() {
-		return
-	}
-
-	if p.HTTPProxy != "" {
-		os.Setenv("HTTP_PROXY", p.HTTPProxy)
-		os.Setenv("http_proxy", p.HTTPProxy)
-	}
-	if p.HTTPSProxy != "" {
-		os.Setenv("HTTPS_PROXY", p.HTTPSProxy)
-		os.Setenv("https_proxy", p.HTTPSProxy)
-	}
-	if len(p.noProxy) != 0 {
-		os.Setenv("NO_PROXY", p.GetNoProxyString())
-		os.Setenv("no_proxy", p.GetNoProxyString())
-	}
-}
-
 // This wraps https://pkg.go.dev/golang.org/x/net/http/httpproxy#Config.ProxyFunc
 // This can be called on a nil *ProxyConfig
 func (p *ProxyConfig) ProxyFunc() func(req *http.Request) (*url.URL, error) {
@@ -204,7 +254,9 @@ func ValidateProxyURL(proxyURL string, isHTTPSProxy bool) error {
 	return nil
 }
 
-func (p *ProxyConfig) tlsConfig() (*tls.Config, error) {
+// TLSConfig returns the TLS configuration trusting the proxy CA, nil when
+// there is no proxy CA
+func (p *ProxyConfig) TLSConfig() (*tls.Config, error) {
 	if p.ProxyCACert == "" {
 		return nil, nil
 	}
@@ -223,6 +275,9 @@ func (p *ProxyConfig) tlsConfig() (*tls.Config, error) {
 	}, nil
 }
 
+// HTTPTransport returns a transport using the proxy configuration, the
+// environment of the process is not modified so that other http clients are
+// not affected
 func (p *ProxyConfig) HTTPTransport() http.RoundTripper {
 	if !p.IsEnabled() {
 		return http.DefaultTransport
@@ -237,7 +292,7 @@ func (p *ProxyConfig) HTTPTransport() http.RoundTripper {
 	transport := defaultTransport.Clone()
 
 	transport.Proxy = p.ProxyFunc()
-	tlsConfig, err := p.tlsConfig()
+	tlsConfig, err := p.TLSConfig()
 	if err != nil {
 		logging.Warnf("Failed to add proxy CA to crc http transport")
 		return transport
@@ -247,11 +302,22 @@ func (p *ProxyConfig) HTTPTransport() http.RoundTripper {
 	return transport
 }
 
+// HTTPTransport returns the transport of the http clients of crc, using the
+// proxy configuration and logging the requests when the HTTP debug logs are
+// enabled
+// DirectHTTPTransport returns a transport which does not use the proxy, for
+// the documents telling which proxy to use
+func DirectHTTPTransport() http.RoundTripper {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	return withDebugLogs(transport)
+}
+
 func HTTPTransport() http.RoundTripper {
 	proxyConfig, err := NewProxyConfig()
 	if err != nil {
-		return http.DefaultTransport
+		return withDebugLogs(http.DefaultTransport)
 	}
 
-	return proxyConfig.HTTPTransport()
+	return withDebugLogs(proxyConfig.HTTPTransport())
 }
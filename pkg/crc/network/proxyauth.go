@@ -0,0 +1,70 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/spf13/cast"
+)
+
+// ProxyAuth is the authentication scheme of the upstream proxy. crio and the
+// kubelet only support basic authentication, so with the other schemes the
+// daemon relays the proxy connections of the VM and authenticates them.
+type ProxyAuth string
+
+const (
+	NoProxyAuth        ProxyAuth = ""
+	NTLMProxyAuth      ProxyAuth = "ntlm"
+	NegotiateProxyAuth ProxyAuth = "negotiate"
+)
+
+func parseProxyAuth(input string) (ProxyAuth, error) {
+	switch input {
+	case string(NoProxyAuth), "none", "basic":
+		return NoProxyAuth, nil
+	case string(NTLMProxyAuth):
+		return NTLMProxyAuth, nil
+	case string(NegotiateProxyAuth), "kerberos":
+		return NegotiateProxyAuth, nil
+	default:
+		return NoProxyAuth, fmt.Errorf("Cannot parse proxy authentication '%s'", input)
+	}
+}
+
+func ParseProxyAuth(input string) ProxyAuth {
+	auth, err := parseProxyAuth(input)
+	if err != nil {
+		logging.Errorf("unexpected proxy authentication %s, the proxy connections are not relayed", input)
+		return NoProxyAuth
+	}
+	return auth
+}
+
+func ValidateProxyAuth(val interface{}) (bool, string) {
+	_, err := parseProxyAuth(cast.ToString(val))
+	if err != nil {
+		return false, fmt.Sprintf("proxy authentication should be either none, %s or %s", NTLMProxyAuth, NegotiateProxyAuth)
+	}
+	return true, ""
+}
+
+// Relayed returns the proxy configuration of the VM when its proxy
+// connections go through the relay of the daemon listening on relayHost
+func (p *ProxyConfig) Relayed(relayHost string, relayPort int) *ProxyConfig {
+	relayURL := fmt.Sprintf("http://%s", net.JoinHostPort(relayHost, strconv.Itoa(relayPort)))
+	relayed := &ProxyConfig{
+		ProxyCACert: p.ProxyCACert,
+		ProxyCAFile: p.ProxyCAFile,
+	}
+	if p.HTTPProxy != "" {
+		relayed.HTTPProxy = relayURL
+	}
+	if p.HTTPSProxy != "" {
+		relayed.HTTPSProxy = relayURL
+	}
+	relayed.AddNoProxy(p.noProxy...)
+	relayed.AddNoProxy(relayHost)
+	return relayed
+}
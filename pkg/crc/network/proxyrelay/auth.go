@@ -0,0 +1,119 @@
+package proxyrelay
+
+import (
+	"fmt"
+	"net/url"
+	"runtime"
+
+	"github.com/Azure/go-ntlmssp"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/openshift/oc/pkg/helpers/tokencmd"
+)
+
+// authenticator computes the tokens of the Proxy-Authorization headers of
+// an authentication handshake with the upstream proxy, it is used for a
+// single connection
+type authenticator interface {
+	scheme() string
+	// token returns the token answering the challenge of the proxy, the
+	// challenge is nil for the first request of the handshake
+	token(challenge []byte) ([]byte, error)
+	release()
+}
+
+func newAuthenticator(auth network.ProxyAuth, proxyURL *url.URL) (authenticator, error) {
+	switch auth {
+	case network.NTLMProxyAuth:
+		return newNTLMAuthenticator(proxyURL)
+	case network.NegotiateProxyAuth:
+		return newNegotiateAuthenticator(proxyURL)
+	default:
+		return nil, nil
+	}
+}
+
+// ntlmAuthenticator does NTLMv2 with the credentials of the proxy URL, the
+// user name is optionally prefixed by the domain such as DOMAIN\user
+type ntlmAuthenticator struct {
+	user     string
+	domain   string
+	password string
+}
+
+func newNTLMAuthenticator(proxyURL *url.URL) (authenticator, error) {
+	if proxyURL.User == nil {
+		return nil, fmt.Errorf("NTLM authentication requires the credentials in the proxy URL, such as 'http://DOMAIN%%5Cuser:password@%s'", proxyURL.Host)
+	}
+	password, _ := proxyURL.User.Password()
+	user, domain := ntlmssp.GetDomain(proxyURL.User.Username())
+	return &ntlmAuthenticator{
+		user:     user,
+		domain:   domain,
+		password: password,
+	}, nil
+}
+
+func (a *ntlmAuthenticator) scheme() string {
+	return "NTLM"
+}
+
+func (a *ntlmAuthenticator) token(challenge []byte) ([]byte, error) {
+	if challenge == nil {
+		return ntlmssp.NewNegotiateMessage(a.domain, "")
+	}
+	return ntlmssp.ProcessChallenge(challenge, a.user, a.password)
+}
+
+func (a *ntlmAuthenticator) release() {
+}
+
+// negotiateAuthenticator does SPNEGO with SSPI on Windows, falling back to
+// NTLM, and with GSSAPI when crc is built with the gssapi tag. The
+// credentials of the logged in user are used unless the proxy URL has some.
+type negotiateAuthenticator struct {
+	negotiator tokencmd.Negotiator
+	serviceURL string
+}
+
+func newNegotiateAuthenticator(proxyURL *url.URL) (authenticator, error) {
+	var principal, password string
+	if proxyURL.User != nil {
+		principal = proxyURL.User.Username()
+		password, _ = proxyURL.User.Password()
+	}
+
+	var negotiator tokencmd.Negotiator
+	switch {
+	case tokencmd.SSPIEnabled() && (principal == "" || password != ""):
+		negotiator = tokencmd.NewSSPINegotiator(principal, password, proxyURL.Host, nil)
+	case tokencmd.GSSAPIEnabled():
+		negotiator = tokencmd.NewGSSAPINegotiator(principal)
+	default:
+		return nil, fmt.Errorf("Negotiate authentication is not supported on %s, use NTLM authentication instead", runtime.GOOS)
+	}
+	if err := negotiator.Load(); err != nil {
+		if err := negotiator.Release(); err != nil {
+			logging.Debugf("Failed to release the negotiator: %v", err)
+		}
+		return nil, err
+	}
+	return &negotiateAuthenticator{
+		negotiator: negotiator,
+		serviceURL: (&url.URL{Scheme: proxyURL.Scheme, Host: proxyURL.Host}).String(),
+	}, nil
+}
+
+func (a *negotiateAuthenticator) scheme() string {
+	return "Negotiate"
+}
+
+func (a *negotiateAuthenticator) token(challenge []byte) ([]byte, error) {
+	return a.negotiator.InitSecContext(a.serviceURL, challenge)
+}
+
+func (a *negotiateAuthenticator) release() {
+	if err := a.negotiator.Release(); err != nil {
+		logging.Debugf("Failed to release the negotiator: %v", err)
+	}
+}
@@ -0,0 +1,335 @@
+package proxyrelay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/pkg/errors"
+)
+
+const (
+	dialTimeout = 10 * time.Second
+	// NTLM needs 2 requests, SPNEGO may need a few more
+	maxHandshakeRequests = 5
+)
+
+// hop-by-hop headers, they are not forwarded
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+// Relay is a plain HTTP proxy for the VM, it forwards the requests and the
+// CONNECT tunnels to the upstream proxy of the host and does the NTLM or
+// Negotiate authentication which crio and the kubelet cannot do
+type Relay struct {
+	proxyConfig func() (*network.ProxyConfig, error)
+	auth        func() network.ProxyAuth
+	dialer      *net.Dialer
+	direct      http.RoundTripper
+}
+
+// New returns a relay using the proxy configuration and the authentication
+// scheme returned by the functions, they are called for each request so that
+// configuration changes apply without restarting the daemon
+func New(proxyConfig func() (*network.ProxyConfig, error), auth func() network.ProxyAuth) *Relay {
+	direct := http.DefaultTransport.(*http.Transport).Clone()
+	direct.Proxy = nil
+	return &Relay{
+		proxyConfig: proxyConfig,
+		auth:        auth,
+		dialer:      &net.Dialer{Timeout: dialTimeout},
+		direct:      direct,
+	}
+}
+
+func (relay *Relay) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	proxyConfig, err := relay.proxyConfig()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	switch {
+	case req.Method == http.MethodConnect:
+		relay.tunnel(w, req, proxyConfig)
+	case req.URL.IsAbs():
+		relay.forward(w, req, proxyConfig)
+	default:
+		http.Error(w, "only proxy requests are supported", http.StatusBadRequest)
+	}
+}
+
+// tunnel opens a CONNECT tunnel to the target through the upstream proxy and
+// splices it with the connection of the client
+func (relay *Relay) tunnel(w http.ResponseWriter, req *http.Request, proxyConfig *network.ProxyConfig) {
+	target := req.Host
+	proxyURL, err := proxyConfig.ProxyFunc()(&http.Request{URL: &url.URL{Scheme: "https", Host: target}})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	var (
+		upstream net.Conn
+		reader   io.Reader
+	)
+	if proxyURL == nil {
+		upstream, err = relay.dialer.DialContext(req.Context(), "tcp", target)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		reader = upstream
+	} else {
+		upstream, err = relay.dialProxy(req.Context(), proxyURL, proxyConfig)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		connectReq := &http.Request{
+			Method: http.MethodConnect,
+			URL:    &url.URL{Opaque: target},
+			Host:   target,
+			Header: http.Header{},
+		}
+		bufferedReader := bufio.NewReader(upstream)
+		resp, err := relay.roundTrip(upstream, bufferedReader, proxyURL, connectReq, nil)
+		if err != nil {
+			upstream.Close()
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		if resp.StatusCode != http.StatusOK {
+			defer upstream.Close()
+			logging.Debugf("The proxy %s refused the tunnel to %s: %s", proxyURL.Host, target, resp.Status)
+			copyResponse(w, resp)
+			return
+		}
+		reader = bufferedReader
+	}
+	defer upstream.Close()
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "cannot hijack the connection", http.StatusInternalServerError)
+		return
+	}
+	conn, clientReadWriter, err := hijacker.Hijack()
+	if err != nil {
+		logging.Debugf("Cannot hijack the connection of %s: %v", req.RemoteAddr, err)
+		return
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+		return
+	}
+
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(upstream, clientReadWriter.Reader)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(conn, reader)
+		done <- struct{}{}
+	}()
+	// the tunnel ends as soon as one of its sides is closed
+	<-done
+}
+
+// forward sends a plain HTTP request to the upstream proxy
+func (relay *Relay) forward(w http.ResponseWriter, req *http.Request, proxyConfig *network.ProxyConfig) {
+	proxyURL, err := proxyConfig.ProxyFunc()(req)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	outReq := req.Clone(req.Context())
+	outReq.RequestURI = ""
+	outReq.Close = false
+	for _, header := range hopHeaders {
+		outReq.Header.Del(header)
+	}
+
+	if proxyURL == nil {
+		resp, err := relay.direct.RoundTrip(outReq)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		copyResponse(w, resp)
+		return
+	}
+
+	// the body is sent again for each request of the handshake
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	upstream, err := relay.dialProxy(req.Context(), proxyURL, proxyConfig)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer upstream.Close()
+	resp, err := relay.roundTrip(upstream, bufio.NewReader(upstream), proxyURL, outReq, body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	copyResponse(w, resp)
+}
+
+func (relay *Relay) dialProxy(ctx context.Context, proxyURL *url.URL, proxyConfig *network.ProxyConfig) (net.Conn, error) {
+	address := proxyURL.Host
+	if proxyURL.Port() == "" {
+		port := "80"
+		if proxyURL.Scheme == "https" {
+			port = "443"
+		}
+		address = net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+	conn, err := relay.dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot connect to the proxy %s", proxyURL.Host)
+	}
+	if proxyURL.Scheme != "https" {
+		return conn, nil
+	}
+
+	tlsConfig, err := proxyConfig.TLSConfig()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	tlsConfig = tlsConfig.Clone()
+	tlsConfig.ServerName = proxyURL.Hostname()
+	tlsConn := tls.Client(conn, tlsConfig)
+	if err := tlsConn.Handshake(); err != nil {
+		conn.Close()
+		return nil, errors.Wrapf(err, "TLS handshake with the proxy %s failed", proxyURL.Host)
+	}
+	return tlsConn, nil
+}
+
+// roundTrip sends the request to the upstream proxy and answers its
+// authentication challenges, all on the same connection since NTLM
+// authenticates connections and not requests
+func (relay *Relay) roundTrip(conn net.Conn, reader *bufio.Reader, proxyURL *url.URL, req *http.Request, body []byte) (*http.Response, error) {
+	auth, err := newAuthenticator(relay.auth(), proxyURL)
+	if err != nil {
+		return nil, err
+	}
+	if auth == nil {
+		if proxyURL.User != nil {
+			password, _ := proxyURL.User.Password()
+			credentials := fmt.Sprintf("%s:%s", proxyURL.User.Username(), password)
+			req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+		}
+		return send(conn, reader, req, body)
+	}
+	defer auth.release()
+
+	var challenge []byte
+	for i := 1; ; i++ {
+		token, err := auth.token(challenge)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s authentication with the proxy %s failed", auth.scheme(), proxyURL.Host)
+		}
+		req.Header.Set("Proxy-Authorization", fmt.Sprintf("%s %s", auth.scheme(), base64.StdEncoding.EncodeToString(token)))
+		resp, err := send(conn, reader, req, body)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusProxyAuthRequired || resp.Close || i == maxHandshakeRequests {
+			return resp, nil
+		}
+		challenge = proxyChallenge(resp.Header, auth.scheme())
+		if len(challenge) == 0 {
+			// the proxy rejected the credentials
+			return resp, nil
+		}
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
+
+func send(conn net.Conn, reader *bufio.Reader, req *http.Request, body []byte) (*http.Response, error) {
+	req.Body = nil
+	req.ContentLength = int64(len(body))
+	if len(body) > 0 {
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	var err error
+	if req.Method == http.MethodConnect {
+		err = req.Write(conn)
+	} else {
+		err = req.WriteProxy(conn)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot send the request to the proxy")
+	}
+	resp, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read the response of the proxy")
+	}
+	return resp, nil
+}
+
+// proxyChallenge returns the token of the Proxy-Authenticate header of the
+// scheme, nil when there is none
+func proxyChallenge(header http.Header, scheme string) []byte {
+	for _, value := range header.Values("Proxy-Authenticate") {
+		fields := strings.Fields(value)
+		if len(fields) != 2 || !strings.EqualFold(fields[0], scheme) {
+			continue
+		}
+		token, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil {
+			logging.Debugf("Invalid %s challenge: %v", scheme, err)
+			return nil
+		}
+		return token
+	}
+	return nil
+}
+
+func copyResponse(w http.ResponseWriter, resp *http.Response) {
+	defer resp.Body.Close()
+	for _, header := range hopHeaders {
+		resp.Header.Del(header)
+	}
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	_, _ = io.Copy(w, resp.Body)
+}
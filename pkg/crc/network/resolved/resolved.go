@@ -0,0 +1,277 @@
+// Package resolved routes DNS domains to the DNS servers of a network link
+// with the D-Bus API of systemd-resolved
+package resolved
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	resolvedDest          = "org.freedesktop.resolve1"
+	resolvedPath          = dbus.ObjectPath("/org/freedesktop/resolve1")
+	resolvedInterface     = "org.freedesktop.resolve1.Manager"
+	resolvedLinkInterface = "org.freedesktop.resolve1.Link"
+
+	systemdDest = "org.freedesktop.systemd1"
+	systemdPath = dbus.ObjectPath("/org/freedesktop/systemd1")
+
+	// minPortVersion is the first systemd release supporting DNS servers
+	// which do not listen on the default DNS port
+	minPortVersion = 246
+
+	// address families of the Linux kernel, the ones of the syscall package
+	// depend on the OS crc is built for
+	afInet  = 2
+	afInet6 = 10
+)
+
+// Server is a DNS server of a link, a zero Port is the default DNS port
+type Server struct {
+	IP   net.IP
+	Port uint16
+}
+
+func (server Server) String() string {
+	if server.Port == 0 {
+		return server.IP.String()
+	}
+	return net.JoinHostPort(server.IP.String(), fmt.Sprint(server.Port))
+}
+
+// Equal returns true if both servers have the same IP and port
+func (server Server) Equal(other Server) bool {
+	return server.IP.Equal(other.IP) && server.Port == other.Port
+}
+
+// dnsAddress is the (iay) D-Bus structure of SetLinkDNS
+type dnsAddress struct {
+	Family  int32
+	Address []byte
+}
+
+// dnsAddressEx is the (iayqs) D-Bus structure of SetLinkDNSEx, Name is the
+// server name used for DNS-over-TLS
+type dnsAddressEx struct {
+	Family  int32
+	Address []byte
+	Port    uint16
+	Name    string
+}
+
+// linkDomain is the (sb) D-Bus structure of SetLinkDomains
+type linkDomain struct {
+	Domain      string
+	RoutingOnly bool
+}
+
+func family(ip net.IP) (int32, []byte) {
+	if ip4 := ip.To4(); ip4 != nil {
+		return afInet, ip4
+	}
+	return afInet6, ip.To16()
+}
+
+func toDNSAddresses(servers []Server) []dnsAddress {
+	var addresses []dnsAddress
+	for _, server := range servers {
+		af, address := family(server.IP)
+		addresses = append(addresses, dnsAddress{Family: af, Address: address})
+	}
+	return addresses
+}
+
+func toDNSAddressesEx(servers []Server) []dnsAddressEx {
+	var addresses []dnsAddressEx
+	for _, server := range servers {
+		af, address := family(server.IP)
+		addresses = append(addresses, dnsAddressEx{Family: af, Address: address, Port: server.Port})
+	}
+	return addresses
+}
+
+func fromDNSAddressesEx(addresses []dnsAddressEx) []Server {
+	var servers []Server
+	for _, address := range addresses {
+		servers = append(servers, Server{IP: net.IP(address.Address), Port: address.Port})
+	}
+	return servers
+}
+
+// needsEx returns true when a server does not use the default DNS port, only
+// SetLinkDNSEx, added in systemd 246, supports the port
+func needsEx(servers []Server) bool {
+	for _, server := range servers {
+		if server.Port != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// toRoutingDomains returns the routing-only domains of the names, the
+// queries of these domains and of their subdomains go to the servers of the
+// link, the link is not used for the other queries
+func toRoutingDomains(domains []string) []linkDomain {
+	var routing []linkDomain
+	for _, domain := range domains {
+		routing = append(routing, linkDomain{Domain: strings.TrimPrefix(domain, "~"), RoutingOnly: true})
+	}
+	return routing
+}
+
+func manager() (dbus.BusObject, error) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return nil, err
+	}
+	return conn.Object(resolvedDest, resolvedPath), nil
+}
+
+func call(obj dbus.BusObject, method string, args ...interface{}) *dbus.Call {
+	// polkit may ask the user to authenticate for the link methods
+	return obj.Call(resolvedInterface+"."+method, dbus.FlagAllowInteractiveAuthorization, args...)
+}
+
+// Available returns true when systemd-resolved is running
+func Available() bool {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		logging.Debugf("Cannot connect to the system bus: %v", err)
+		return false
+	}
+	var hasOwner bool
+	if err := conn.BusObject().Call("org.freedesktop.DBus.NameHasOwner", 0, resolvedDest).Store(&hasOwner); err != nil {
+		logging.Debugf("Cannot check if systemd-resolved is running: %v", err)
+		return false
+	}
+	return hasOwner
+}
+
+// SupportsPorts returns true when systemd-resolved can send the queries to a
+// DNS server which does not listen on the default DNS port
+func SupportsPorts() bool {
+	version, err := systemdVersion()
+	if err != nil {
+		logging.Debugf("Cannot get the version of systemd: %v", err)
+		return false
+	}
+	return version >= minPortVersion
+}
+
+func systemdVersion() (int, error) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return 0, err
+	}
+	var version string
+	if err := conn.Object(systemdDest, systemdPath).StoreProperty("org.freedesktop.systemd1.Manager.Version", &version); err != nil {
+		return 0, err
+	}
+	return parseVersion(version)
+}
+
+// parseVersion returns the release of versions such as '246.6-1.fc33',
+// '245.4-4ubuntu3' or 'v249'
+func parseVersion(version string) (int, error) {
+	release := strings.TrimPrefix(version, "v")
+	if i := strings.IndexFunc(release, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+		release = release[:i]
+	}
+	number, err := strconv.Atoi(release)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid systemd version '%s'", version)
+	}
+	return number, nil
+}
+
+// SetLinkRouting sends the queries of the domains and of their subdomains to
+// the servers through the link, it replaces the previous DNS configuration of
+// the link
+func SetLinkRouting(link string, servers []Server, domains []string) error {
+	iface, err := net.InterfaceByName(link)
+	if err != nil {
+		return err
+	}
+	obj, err := manager()
+	if err != nil {
+		return err
+	}
+	ifindex := int32(iface.Index)
+
+	if needsEx(servers) {
+		err = call(obj, "SetLinkDNSEx", ifindex, toDNSAddressesEx(servers)).Err
+	} else {
+		err = call(obj, "SetLinkDNS", ifindex, toDNSAddresses(servers)).Err
+	}
+	if err != nil {
+		return fmt.Errorf("Cannot set the DNS servers of %s: %w", link, err)
+	}
+	if err := call(obj, "SetLinkDomains", ifindex, toRoutingDomains(domains)).Err; err != nil {
+		return fmt.Errorf("Cannot set the DNS domains of %s: %w", link, err)
+	}
+	// the link is not used for the other domains, systemd does not use the
+	// links with only routing domains as default route
+	logging.Debugf("Routing the DNS queries of %s to %v through %s", strings.Join(domains, ", "), servers, link)
+	return nil
+}
+
+// LinkServers returns the DNS servers of the link, they are empty when the
+// link does not exist
+func LinkServers(link string) ([]Server, error) {
+	iface, err := net.InterfaceByName(link)
+	if err != nil {
+		return nil, nil
+	}
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		return nil, err
+	}
+	var linkPath dbus.ObjectPath
+	if err := call(conn.Object(resolvedDest, resolvedPath), "GetLink", int32(iface.Index)).Store(&linkPath); err != nil {
+		return nil, err
+	}
+	linkObj := conn.Object(resolvedDest, linkPath)
+
+	var addresses []dnsAddressEx
+	if err := linkObj.StoreProperty(resolvedLinkInterface+".DNSEx", &addresses); err == nil {
+		return fromDNSAddressesEx(addresses), nil
+	}
+	// systemd releases older than 246 do not have the DNSEx property
+	var legacy []dnsAddress
+	if err := linkObj.StoreProperty(resolvedLinkInterface+".DNS", &legacy); err != nil {
+		return nil, err
+	}
+	for _, address := range legacy {
+		addresses = append(addresses, dnsAddressEx{Family: address.Family, Address: address.Address})
+	}
+	return fromDNSAddressesEx(addresses), nil
+}
+
+// ClearLinkRouting removes the DNS servers and domains of the link, it does
+// nothing when the link does not exist
+func ClearLinkRouting(link string) error {
+	iface, err := net.InterfaceByName(link)
+	if err != nil {
+		logging.Debugf("Not clearing the DNS configuration of %s: %v", link, err)
+		return nil
+	}
+	obj, err := manager()
+	if err != nil {
+		return err
+	}
+	ifindex := int32(iface.Index)
+	if err := call(obj, "SetLinkDNS", ifindex, []dnsAddress{}).Err; err != nil {
+		return fmt.Errorf("Cannot clear the DNS servers of %s: %w", link, err)
+	}
+	if err := call(obj, "SetLinkDomains", ifindex, []linkDomain{}).Err; err != nil {
+		return fmt.Errorf("Cannot clear the DNS domains of %s: %w", link, err)
+	}
+	logging.Debugf("Cleared the DNS configuration of %s", link)
+	return nil
+}
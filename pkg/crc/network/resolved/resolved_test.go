@@ -0,0 +1,50 @@
+package resolved
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDNSAddresses(t *testing.T) {
+	servers := []Server{
+		{IP: net.ParseIP("192.168.130.11")},
+		{IP: net.ParseIP("fd00::11")},
+	}
+	assert.False(t, needsEx(servers))
+	assert.Equal(t, []dnsAddress{
+		{Family: afInet, Address: []byte{192, 168, 130, 11}},
+		{Family: afInet6, Address: net.ParseIP("fd00::11").To16()},
+	}, toDNSAddresses(servers))
+
+	hostServer := []Server{{IP: net.ParseIP("127.0.0.1"), Port: 10053}}
+	assert.True(t, needsEx(hostServer))
+	addresses := toDNSAddressesEx(hostServer)
+	assert.Equal(t, []dnsAddressEx{{Family: afInet, Address: []byte{127, 0, 0, 1}, Port: 10053}}, addresses)
+	assert.True(t, fromDNSAddressesEx(addresses)[0].Equal(hostServer[0]))
+	assert.Equal(t, "127.0.0.1:10053", hostServer[0].String())
+	assert.Equal(t, "192.168.130.11", servers[0].String())
+}
+
+func TestRoutingDomains(t *testing.T) {
+	assert.Equal(t, []linkDomain{
+		{Domain: "testing", RoutingOnly: true},
+		{Domain: "apps.example.com", RoutingOnly: true},
+	}, toRoutingDomains([]string{"~testing", "apps.example.com"}))
+}
+
+func TestParseVersion(t *testing.T) {
+	for version, expected := range map[string]int{
+		"246":            246,
+		"246.6-1.fc33":   246,
+		"245.4-4ubuntu3": 245,
+		"v249":           249,
+	} {
+		release, err := parseVersion(version)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, release, version)
+	}
+	_, err := parseVersion("unknown")
+	assert.EqualError(t, err, "Invalid systemd version 'unknown'")
+}
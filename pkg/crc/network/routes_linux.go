@@ -0,0 +1,62 @@
+package network
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+const procRoutePath = "/proc/net/route"
+
+// hostRoutes returns the IPv4 routes of the main routing table
+func hostRoutes() ([]Route, error) {
+	file, err := os.Open(procRoutePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseProcRoutes(file)
+}
+
+// parseProcRoutes parses the format of /proc/net/route, its addresses are in
+// host byte order
+func parseProcRoutes(reader io.Reader) ([]Route, error) {
+	var routes []Route
+	scanner := bufio.NewScanner(reader)
+	// skip the header
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 8 {
+			continue
+		}
+		destination, err := parseProcAddress(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		mask, err := parseProcAddress(fields[7])
+		if err != nil {
+			return nil, err
+		}
+		routes = append(routes, Route{
+			Interface:   fields[0],
+			Destination: &net.IPNet{IP: destination, Mask: net.IPMask(mask)},
+		})
+	}
+	return routes, scanner.Err()
+}
+
+func parseProcAddress(value string) (net.IP, error) {
+	bin, err := hex.DecodeString(value)
+	if err != nil || len(bin) != net.IPv4len {
+		return nil, fmt.Errorf("invalid address in %s: %s", procRoutePath, value)
+	}
+	ip := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(ip, binary.LittleEndian.Uint32(bin))
+	return ip, nil
+}
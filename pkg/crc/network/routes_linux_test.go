@@ -0,0 +1,23 @@
+package network
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProcRoutes(t *testing.T) {
+	routes, err := parseProcRoutes(strings.NewReader(`Iface	Destination	Gateway 	Flags	RefCnt	Use	Metric	Mask		MTU	Window	IRTT
+eth0	00000000	0101A8C0	0003	0	0	100	00000000	0	0	0
+eth0	0001A8C0	00000000	0001	0	0	100	00FFFFFF	0	0	0
+tun0	0000000A	00000000	0001	0	0	50	000000FF	0	0	0
+`))
+	require.NoError(t, err)
+	require.Len(t, routes, 3)
+	assert.Equal(t, "0.0.0.0/0", routes[0].Destination.String())
+	assert.Equal(t, "192.168.1.0/24", routes[1].Destination.String())
+	assert.Equal(t, "tun0", routes[2].Interface)
+	assert.Equal(t, "10.0.0.0/8", routes[2].Destination.String())
+}
@@ -0,0 +1,37 @@
+// +build !linux
+
+package network
+
+import (
+	"net"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+// hostRoutes returns the subnets of the network interfaces, which are the
+// routes to the networks the host is directly connected to
+func hostRoutes() ([]Route, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	var routes []Route
+	for _, iface := range ifaces {
+		addrs, err := iface.Addrs()
+		if err != nil {
+			logging.Debugf("Cannot get the addresses of %s: %v", iface.Name, err)
+			continue
+		}
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || ipNet.IP.To4() == nil || ipNet.IP.IsLoopback() {
+				continue
+			}
+			routes = append(routes, Route{
+				Interface:   iface.Name,
+				Destination: &net.IPNet{IP: ipNet.IP.Mask(ipNet.Mask), Mask: ipNet.Mask},
+			})
+		}
+	}
+	return routes, nil
+}
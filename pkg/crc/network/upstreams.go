@@ -0,0 +1,78 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/spf13/cast"
+)
+
+// UpstreamServer is a nameserver used by the CRC VM to resolve the names which
+// are not part of the cluster. When Mode is set, it is only used with this
+// network mode.
+type UpstreamServer struct {
+	Mode       Mode
+	NameServer NameServer
+}
+
+func (s UpstreamServer) String() string {
+	if s.Mode == "" {
+		return s.NameServer.IPAddress
+	}
+	return fmt.Sprintf("%s:%s", s.Mode, s.NameServer.IPAddress)
+}
+
+// ParseUpstreamServers parses a comma-separated list of IPv4 addresses,
+// optionally prefixed by the network mode they apply to, such as
+// '1.1.1.1,system:10.0.0.53,user:8.8.8.8'
+func ParseUpstreamServers(input string) ([]UpstreamServer, error) {
+	var servers []UpstreamServer
+	if strings.TrimSpace(input) == "" {
+		return servers, nil
+	}
+	for _, item := range strings.Split(input, ",") {
+		item = strings.TrimSpace(item)
+		server := UpstreamServer{}
+		address := item
+		if i := strings.Index(item, ":"); i != -1 {
+			mode, err := parseMode(item[:i])
+			if err != nil {
+				return nil, fmt.Errorf("'%s' is not a valid upstream server, expected 'ip' or 'mode:ip'", item)
+			}
+			server.Mode = mode
+			address = item[i+1:]
+		}
+		if net.ParseIP(address).To4() == nil {
+			return nil, fmt.Errorf("'%s' is not a valid IPv4 address", address)
+		}
+		server.NameServer = NameServer{IPAddress: address}
+		servers = append(servers, server)
+	}
+	return servers, nil
+}
+
+func ValidateUpstreamServers(value interface{}) (bool, string) {
+	if _, err := ParseUpstreamServers(cast.ToString(value)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// UpstreamNameServersForMode returns the nameservers of servers which apply
+// to the network mode, without duplicates and in the configured order
+func UpstreamNameServersForMode(servers []UpstreamServer, mode Mode) []NameServer {
+	var nameservers []NameServer
+	seen := map[string]bool{}
+	for _, server := range servers {
+		if server.Mode != "" && server.Mode != mode {
+			continue
+		}
+		if seen[server.NameServer.IPAddress] {
+			continue
+		}
+		seen[server.NameServer.IPAddress] = true
+		nameservers = append(nameservers, server.NameServer)
+	}
+	return nameservers
+}
@@ -0,0 +1,33 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseUpstreamServers(t *testing.T) {
+	servers, err := ParseUpstreamServers("1.1.1.1, system:10.0.0.53,user:8.8.8.8")
+	assert.NoError(t, err)
+	assert.Equal(t, []UpstreamServer{
+		{NameServer: NameServer{IPAddress: "1.1.1.1"}},
+		{Mode: SystemNetworkingMode, NameServer: NameServer{IPAddress: "10.0.0.53"}},
+		{Mode: UserNetworkingMode, NameServer: NameServer{IPAddress: "8.8.8.8"}},
+	}, servers)
+
+	servers, err = ParseUpstreamServers("")
+	assert.NoError(t, err)
+	assert.Empty(t, servers)
+
+	_, err = ParseUpstreamServers("office:10.0.0.53")
+	assert.EqualError(t, err, "'office:10.0.0.53' is not a valid upstream server, expected 'ip' or 'mode:ip'")
+	_, err = ParseUpstreamServers("user:10.0.0")
+	assert.EqualError(t, err, "'10.0.0' is not a valid IPv4 address")
+}
+
+func TestUpstreamNameServersForMode(t *testing.T) {
+	servers, err := ParseUpstreamServers("1.1.1.1,system:10.0.0.53,user:8.8.8.8,user:1.1.1.1")
+	assert.NoError(t, err)
+	assert.Equal(t, []NameServer{{IPAddress: "1.1.1.1"}, {IPAddress: "10.0.0.53"}}, UpstreamNameServersForMode(servers, SystemNetworkingMode))
+	assert.Equal(t, []NameServer{{IPAddress: "1.1.1.1"}, {IPAddress: "8.8.8.8"}}, UpstreamNameServersForMode(servers, UserNetworkingMode))
+}
@@ -5,6 +5,7 @@ import (
 	"net"
 	"net/url"
 	"runtime"
+	"strings"
 
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/bundle"
@@ -21,24 +22,32 @@ func URIStringForDisplay(uri string) (string, error) {
 	return uri, nil
 }
 
-func matchIP(ips []net.IP, expectedIP string) bool {
+// matchIP returns true when one of the resolved addresses is one of the
+// expected ones, the empty expected addresses are ignored
+func matchIP(ips []net.IP, expectedIPs []string) bool {
 	for _, ip := range ips {
-		if ip.String() == expectedIP {
-			return true
+		for _, expectedIP := range expectedIPs {
+			if ip.Equal(net.ParseIP(expectedIP)) {
+				return true
+			}
 		}
 	}
 
 	return false
 }
-func CheckCRCLocalDNSReachableFromHost(bundle *bundle.CrcBundleInfo, expectedIP string) error {
+
+// CheckCRCLocalDNSReachableFromHost checks that the cluster hostnames resolve
+// to one of the addresses of the VM, its IPv4 address and its optional IPv6
+// address on dual-stack hosts
+func CheckCRCLocalDNSReachableFromHost(bundle *bundle.CrcBundleInfo, expectedIPs ...string) error {
 	apiHostname := bundle.GetAPIHostname()
 	ip, err := net.LookupIP(apiHostname)
 	if err != nil {
 		return err
 	}
 	logging.Debugf("%s resolved to %s", apiHostname, ip)
-	if !matchIP(ip, expectedIP) {
-		logging.Warnf("%s resolved to %s but %s was expected", apiHostname, ip, expectedIP)
+	if !matchIP(ip, expectedIPs) {
+		logging.Warnf("%s resolved to %s but %s was expected", apiHostname, ip, strings.Join(expectedIPs, " or "))
 		return fmt.Errorf("Invalid IP for %s", apiHostname)
 	}
 
@@ -59,8 +68,8 @@ func CheckCRCLocalDNSReachableFromHost(bundle *bundle.CrcBundleInfo, expectedIP
 			return nil
 		}
 		logging.Debugf("%s resolved to %s", appsHostname, ip)
-		if !matchIP(ip, expectedIP) {
-			logging.Warnf("%s resolved to %s but %s was expected", appsHostname, ip, expectedIP)
+		if !matchIP(ip, expectedIPs) {
+			logging.Warnf("%s resolved to %s but %s was expected", appsHostname, ip, strings.Join(expectedIPs, " or "))
 			return fmt.Errorf("Invalid IP for %s", appsHostname)
 		}
 	}
@@ -0,0 +1,55 @@
+package network
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/spf13/cast"
+)
+
+// ForwardZone is a DNS zone which is resolved by a user-specified nameserver
+// instead of the default resolvers of the CRC VM
+type ForwardZone struct {
+	Domain     string
+	NameServer NameServer
+}
+
+func (z ForwardZone) String() string {
+	return fmt.Sprintf("%s=%s", z.Domain, z.NameServer.IPAddress)
+}
+
+// ParseForwardZones parses a comma-separated list of 'domain=ip' pairs,
+// such as 'internal.company.com=10.0.0.53,corp.example=10.1.1.1'
+func ParseForwardZones(input string) ([]ForwardZone, error) {
+	var zones []ForwardZone
+	if strings.TrimSpace(input) == "" {
+		return zones, nil
+	}
+	for _, item := range strings.Split(input, ",") {
+		parts := strings.Split(strings.TrimSpace(item), "=")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("'%s' is not a valid forward zone, expected 'domain=ip'", item)
+		}
+		domain := strings.TrimSuffix(strings.TrimPrefix(parts[0], "."), ".")
+		if !govalidator.IsDNSName(domain) {
+			return nil, fmt.Errorf("'%s' is not a valid domain name", parts[0])
+		}
+		if net.ParseIP(parts[1]).To4() == nil {
+			return nil, fmt.Errorf("'%s' is not a valid IPv4 address", parts[1])
+		}
+		zones = append(zones, ForwardZone{
+			Domain:     domain,
+			NameServer: NameServer{IPAddress: parts[1]},
+		})
+	}
+	return zones, nil
+}
+
+func ValidateForwardZones(value interface{}) (bool, string) {
+	if _, err := ParseForwardZones(cast.ToString(value)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
@@ -0,0 +1,27 @@
+package network
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseForwardZones(t *testing.T) {
+	zones, err := ParseForwardZones("internal.company.com=10.0.0.53, .corp.example.=10.1.1.1")
+	assert.NoError(t, err)
+	assert.Equal(t, []ForwardZone{
+		{Domain: "internal.company.com", NameServer: NameServer{IPAddress: "10.0.0.53"}},
+		{Domain: "corp.example", NameServer: NameServer{IPAddress: "10.1.1.1"}},
+	}, zones)
+
+	zones, err = ParseForwardZones("")
+	assert.NoError(t, err)
+	assert.Empty(t, zones)
+
+	_, err = ParseForwardZones("internal.company.com")
+	assert.EqualError(t, err, "'internal.company.com' is not a valid forward zone, expected 'domain=ip'")
+	_, err = ParseForwardZones("internal.company.com=10.0.0")
+	assert.EqualError(t, err, "'10.0.0' is not a valid IPv4 address")
+	_, err = ParseForwardZones("internal company=10.0.0.1")
+	assert.EqualError(t, err, "'internal company' is not a valid domain name")
+}
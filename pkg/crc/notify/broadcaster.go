@@ -0,0 +1,61 @@
+package notify
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/code-ready/crc/pkg/broadcast"
+)
+
+// subscriberBuffer is the number of events kept for a subscriber which
+// does not receive them fast enough, it is told how many of the next ones
+// it missed with an EventsDropped event
+const subscriberBuffer = 16
+
+// EventsDropped is sent to a subscriber in place of the events it missed
+const EventsDropped EventType = "events-dropped"
+
+// Events receives the events of all the instances of this process, the
+// streaming clients of the daemon subscribe to it
+var Events = NewBroadcaster()
+
+// Broadcaster sends the published events to all its subscribers
+type Broadcaster struct {
+	subscribers *broadcast.Broadcaster
+}
+
+func NewBroadcaster() *Broadcaster {
+	return &Broadcaster{
+		subscribers: broadcast.New(),
+	}
+}
+
+// Subscribe returns the channel receiving the next events, and the function
+// to call once they are not needed anymore
+func (b *Broadcaster) Subscribe() (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+	unsubscribe := b.subscribers.Subscribe(func(event interface{}) bool {
+		select {
+		case ch <- event.(Event):
+			return true
+		default:
+			return false
+		}
+	}, func(dropped int) interface{} {
+		return Event{
+			Type:    EventsDropped,
+			Message: fmt.Sprintf("%d events were dropped, the client did not read them fast enough", dropped),
+			Time:    time.Now(),
+		}
+	})
+	return ch, func() {
+		if unsubscribe() {
+			close(ch)
+		}
+	}
+}
+
+// Publish sends event to the subscribers without blocking
+func (b *Broadcaster) Publish(event Event) {
+	b.subscribers.Publish(event)
+}
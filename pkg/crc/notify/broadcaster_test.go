@@ -0,0 +1,46 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBroadcaster(t *testing.T) {
+	broadcaster := NewBroadcaster()
+	first, cancelFirst := broadcaster.Subscribe()
+	second, cancelSecond := broadcaster.Subscribe()
+	defer cancelSecond()
+
+	broadcaster.Publish(Event{Type: StartSucceeded})
+	assert.Equal(t, StartSucceeded, (<-first).Type)
+	assert.Equal(t, StartSucceeded, (<-second).Type)
+
+	cancelFirst()
+	cancelFirst()
+	_, open := <-first
+	assert.False(t, open)
+
+	broadcaster.Publish(Event{Type: DiskPressure})
+	assert.Equal(t, DiskPressure, (<-second).Type)
+}
+
+func TestBroadcasterSlowSubscriber(t *testing.T) {
+	broadcaster := NewBroadcaster()
+	events, cancel := broadcaster.Subscribe()
+	defer cancel()
+
+	for i := 0; i < subscriberBuffer+5; i++ {
+		broadcaster.Publish(Event{Type: CertsExpiring})
+	}
+	assert.Len(t, events, subscriberBuffer)
+
+	for i := 0; i < subscriberBuffer; i++ {
+		<-events
+	}
+	broadcaster.Publish(Event{Type: DiskPressure})
+	dropped := <-events
+	assert.Equal(t, EventsDropped, dropped.Type)
+	assert.Equal(t, "5 events were dropped, the client did not read them fast enough", dropped.Message)
+	assert.Equal(t, DiskPressure, (<-events).Type)
+}
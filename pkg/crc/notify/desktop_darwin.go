@@ -0,0 +1,16 @@
+package notify
+
+import (
+	"fmt"
+	"strconv"
+
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+func sendDesktopNotification(title, message string) error {
+	script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(message), strconv.Quote(title))
+	if _, stderr, err := crcos.RunWithDefaultLocale("osascript", "-e", script); err != nil {
+		return fmt.Errorf("Failed to send desktop notification: %v: %s", err, stderr)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package notify
+
+import (
+	"fmt"
+
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+func sendDesktopNotification(title, message string) error {
+	if _, stderr, err := crcos.RunWithDefaultLocale("notify-send", "--app-name=crc", title, message); err != nil {
+		return fmt.Errorf("Failed to send desktop notification: %v: %s", err, stderr)
+	}
+	return nil
+}
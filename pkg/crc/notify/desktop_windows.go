@@ -0,0 +1,30 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/os/windows/powershell"
+)
+
+func quote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+func sendDesktopNotification(title, message string) error {
+	script := []string{
+		"Add-Type -AssemblyName System.Windows.Forms;",
+		"$notification = New-Object System.Windows.Forms.NotifyIcon;",
+		"$notification.Icon = [System.Drawing.SystemIcons]::Information;",
+		fmt.Sprintf("$notification.BalloonTipTitle = %s;", quote(title)),
+		fmt.Sprintf("$notification.BalloonTipText = %s;", quote(message)),
+		"$notification.Visible = $true;",
+		"$notification.ShowBalloonTip(5000);",
+		"Start-Sleep -Seconds 5;",
+		"$notification.Dispose()",
+	}
+	if _, stderr, err := powershell.Execute(script...); err != nil {
+		return fmt.Errorf("Failed to send desktop notification: %v: %s", err, stderr)
+	}
+	return nil
+}
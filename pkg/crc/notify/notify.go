@@ -0,0 +1,124 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	crcerrors "github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/network"
+)
+
+type EventType string
+
+const (
+	StartSucceeded EventType = "start-succeeded"
+	StartFailed    EventType = "start-failed"
+	CertsExpiring  EventType = "certs-expiring"
+	DiskPressure   EventType = "disk-pressure"
+)
+
+const webhookTimeout = 10 * time.Second
+
+// Event is a lifecycle event of an instance which users are notified of
+type Event struct {
+	Type     EventType `json:"event"`
+	Instance string    `json:"instance"`
+	Message  string    `json:"message"`
+	Time     time.Time `json:"time"`
+}
+
+func (event Event) title() string {
+	return fmt.Sprintf("CodeReady Containers (%s)", event.Instance)
+}
+
+type Notifier interface {
+	Notify(event Event) error
+}
+
+// New returns the notifier sending the events as desktop notifications
+// and/or to webhookURL, it does nothing when neither are enabled
+func New(desktop bool, webhookURL string) Notifier {
+	var notifiers multiNotifier
+	if desktop {
+		notifiers = append(notifiers, desktopNotifier{})
+	}
+	if webhookURL != "" {
+		notifiers = append(notifiers, &WebhookNotifier{
+			URL:    webhookURL,
+			Client: &http.Client{Transport: network.HTTPTransport(), Timeout: webhookTimeout},
+		})
+	}
+	return notifiers
+}
+
+type multiNotifier []Notifier
+
+func (notifiers multiNotifier) Notify(event Event) error {
+	var errs crcerrors.MultiError
+	for _, notifier := range notifiers {
+		if err := notifier.Notify(event); err != nil {
+			errs.Collect(err)
+		}
+	}
+	if len(errs.Errors) > 0 {
+		return errs
+	}
+	return nil
+}
+
+type desktopNotifier struct{}
+
+func (desktopNotifier) Notify(event Event) error {
+	logging.Debugf("Sending desktop notification for %s", event.Type)
+	return sendDesktopNotification(event.title(), event.Message)
+}
+
+// webhookPayload is compatible with Slack incoming webhooks, which only
+// use the text field
+type webhookPayload struct {
+	Text string `json:"text"`
+	Event
+}
+
+// WebhookNotifier POSTs the events as JSON to URL
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+func (notifier *WebhookNotifier) Notify(event Event) error {
+	body, err := json.Marshal(webhookPayload{
+		Text:  fmt.Sprintf("%s: %s", event.title(), event.Message),
+		Event: event,
+	})
+	if err != nil {
+		return err
+	}
+	logging.Debugf("Sending %s to the notification webhook", event.Type)
+	resp, err := notifier.Client.Post(notifier.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("Failed to send the notification to the webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("Notification webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// ValidateWebhookURL checks that webhookURL is empty or an http(s) URL
+func ValidateWebhookURL(webhookURL string) error {
+	if webhookURL == "" {
+		return nil
+	}
+	u, err := url.Parse(webhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("'%s' is not a valid webhook URL, expected an http or https URL", webhookURL)
+	}
+	return nil
+}
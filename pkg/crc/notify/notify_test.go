@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	notifier := &WebhookNotifier{URL: server.URL, Client: server.Client()}
+	require.NoError(t, notifier.Notify(Event{
+		Type:     StartFailed,
+		Instance: "crc",
+		Message:  "The OpenShift cluster failed to start",
+		Time:     time.Date(2021, time.June, 1, 12, 0, 0, 0, time.UTC),
+	}))
+	assert.Equal(t, map[string]interface{}{
+		"text":     "CodeReady Containers (crc): The OpenShift cluster failed to start",
+		"event":    "start-failed",
+		"instance": "crc",
+		"message":  "The OpenShift cluster failed to start",
+		"time":     "2021-06-01T12:00:00Z",
+	}, received)
+}
+
+func TestWebhookNotifierError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	notifier := &WebhookNotifier{URL: server.URL, Client: server.Client()}
+	assert.EqualError(t, notifier.Notify(Event{Type: DiskPressure}), "Notification webhook returned 404 Not Found")
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	assert.NoError(t, ValidateWebhookURL(""))
+	assert.NoError(t, ValidateWebhookURL("https://hooks.slack.com/services/T000/B000/XXXX"))
+	assert.Error(t, ValidateWebhookURL("hooks.slack.com/services"))
+	assert.Error(t, ValidateWebhookURL("ftp://example.com"))
+}
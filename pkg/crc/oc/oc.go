@@ -2,8 +2,10 @@ package oc
 
 import (
 	"path/filepath"
+	"strings"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/ssh"
 	crcos "github.com/code-ready/crc/pkg/os"
 )
@@ -57,10 +59,14 @@ func (oc Config) runCommand(isPrivate bool, args ...string) (string, string, err
 	}
 
 	if isPrivate {
+		logging.DebugfFor(logging.OcDebug, "Running oc command: <hidden>")
 		return oc.Runner.RunPrivate("timeout", append([]string{oc.Timeout, oc.OcExecutablePath}, args...)...)
 	}
 
-	return oc.Runner.Run("timeout", append([]string{oc.Timeout, oc.OcExecutablePath}, args...)...)
+	logging.DebugfFor(logging.OcDebug, "Running oc command: %s", strings.Join(args, " "))
+	stdout, stderr, err := oc.Runner.Run("timeout", append([]string{oc.Timeout, oc.OcExecutablePath}, args...)...)
+	logging.DebugfFor(logging.OcDebug, "oc command results: err: %v, output: %s, error output: %s", err, stdout, stderr)
+	return stdout, stderr, err
 }
 
 func (oc Config) RunOcCommand(args ...string) (string, string, error) {
@@ -0,0 +1,78 @@
+// Package output writes the results of the commands for the automation
+// consuming them.
+//
+// The JSON documents have a schemaVersion field next to the fields of the
+// result. Within a schema version, fields may be added but are never removed,
+// renamed or given another meaning, so that consumers only need to check the
+// version they were written for.
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// SchemaVersion is the version of the JSON documents written by the commands
+const SchemaVersion = 1
+
+// JSON is the format of the JSON documents, the results are printed for
+// humans when no format is given
+const JSON = "json"
+
+// ValidateFormat checks that format is an output format which can be written
+func ValidateFormat(format string) error {
+	switch format {
+	case "", JSON:
+		return nil
+	default:
+		return fmt.Errorf("invalid format: %s", format)
+	}
+}
+
+// Write writes result in format, prettyPrint writes it for humans when
+// format is empty
+func Write(writer io.Writer, format string, result interface{}, prettyPrint func(io.Writer) error) error {
+	if err := ValidateFormat(format); err != nil {
+		return err
+	}
+	if format == "" {
+		return prettyPrint(writer)
+	}
+	document, err := Document(result)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(document)
+	return err
+}
+
+// Document returns the indented JSON document of result with its schema
+// version, the fields of result keep their order
+func Document(result interface{}) ([]byte, error) {
+	fields, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	fields = bytes.TrimSpace(fields)
+	if len(fields) < 2 || fields[0] != '{' {
+		return nil, fmt.Errorf("cannot write %T as a JSON document, it is not an object", result)
+	}
+
+	var document bytes.Buffer
+	fmt.Fprintf(&document, `{"schemaVersion":%d`, SchemaVersion)
+	if rest := fields[1:]; !bytes.Equal(rest, []byte("}")) {
+		document.WriteByte(',')
+		document.Write(rest)
+	} else {
+		document.WriteByte('}')
+	}
+
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, document.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	indented.WriteByte('\n')
+	return indented.Bytes(), nil
+}
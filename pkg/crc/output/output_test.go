@@ -0,0 +1,45 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type result struct {
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+func TestDocument(t *testing.T) {
+	document, err := Document(&result{Success: true, Message: "done"})
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"schemaVersion\": 1,\n  \"success\": true,\n  \"message\": \"done\"\n}\n", string(document))
+
+	document, err = Document(struct{}{})
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"schemaVersion\": 1\n}\n", string(document))
+
+	_, err = Document([]string{"not", "an", "object"})
+	assert.Error(t, err)
+}
+
+func TestWrite(t *testing.T) {
+	prettyPrint := func(writer io.Writer) error {
+		_, err := fmt.Fprintln(writer, "done")
+		return err
+	}
+	out := new(bytes.Buffer)
+	assert.NoError(t, Write(out, "", &result{Success: true}, prettyPrint))
+	assert.Equal(t, "done\n", out.String())
+
+	out.Reset()
+	assert.NoError(t, Write(out, JSON, &result{Success: true}, prettyPrint))
+	assert.JSONEq(t, `{"schemaVersion": 1, "success": true}`, out.String())
+
+	assert.EqualError(t, Write(out, "yaml", &result{}, prettyPrint), "invalid format: yaml")
+}
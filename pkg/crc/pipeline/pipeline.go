@@ -0,0 +1,145 @@
+// Package pipeline runs a sequence of named steps. Each step has its own
+// timeout and retry policy, and a panic in a step fails the pipeline with an
+// error instead of crashing the process.
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+type Step struct {
+	Name string
+	// bounds the duration of each attempt, the step must honour the
+	// cancellation of its context. There is no timeout when it is zero.
+	Timeout time.Duration
+	// number of attempts made after the first one fails
+	Retries int
+	// delay between two attempts
+	RetryInterval time.Duration
+	// the step is not run when Skip returns true
+	Skip func() bool
+	Run  func(ctx context.Context) error
+}
+
+// StepResult describes the outcome of a step once it is done
+type StepResult struct {
+	Name     string
+	Duration time.Duration
+	Attempts int
+	Skipped  bool
+	Err      error
+}
+
+// StepError is returned by Run when a step fails, its message is the one of
+// the step error
+type StepError struct {
+	Step string
+	Err  error
+}
+
+func (err *StepError) Error() string {
+	return err.Err.Error()
+}
+
+func (err *StepError) Unwrap() error {
+	return err.Err
+}
+
+// PanicError is the error of a step which panicked
+type PanicError struct {
+	Step  string
+	Value interface{}
+}
+
+func (err *PanicError) Error() string {
+	return fmt.Sprintf("step %s panicked: %v", err.Step, err.Value)
+}
+
+type Pipeline struct {
+	Steps []Step
+	// called after each step, for progress reporting or timing purposes
+	OnStepDone func(result StepResult)
+}
+
+func New(steps ...Step) *Pipeline {
+	return &Pipeline{
+		Steps: steps,
+	}
+}
+
+// Run runs the steps in order and stops at the first one which fails. It
+// returns the results of the steps which were run or skipped.
+func (p *Pipeline) Run(ctx context.Context) ([]StepResult, error) {
+	var results []StepResult
+	for _, step := range p.Steps {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		result := runStep(ctx, step)
+		results = append(results, result)
+		if p.OnStepDone != nil {
+			p.OnStepDone(result)
+		}
+		if result.Err != nil {
+			return results, &StepError{
+				Step: step.Name,
+				Err:  result.Err,
+			}
+		}
+	}
+	return results, nil
+}
+
+func runStep(ctx context.Context, step Step) StepResult {
+	result := StepResult{
+		Name: step.Name,
+	}
+	if step.Skip != nil && step.Skip() {
+		logging.Debugf("Skipping step %s", step.Name)
+		result.Skipped = true
+		return result
+	}
+
+	start := time.Now()
+	for {
+		result.Attempts++
+		result.Err = runAttempt(ctx, step)
+		if result.Err == nil || result.Attempts > step.Retries || ctx.Err() != nil {
+			break
+		}
+		if _, isPanic := result.Err.(*PanicError); isPanic {
+			break
+		}
+		logging.Debugf("Step %s failed, retrying in %s: %v", step.Name, step.RetryInterval, result.Err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(step.RetryInterval):
+		}
+	}
+	result.Duration = time.Since(start)
+	logging.Debugf("Step %s took %s", step.Name, result.Duration)
+	return result
+}
+
+func runAttempt(ctx context.Context, step Step) (err error) {
+	if step.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, step.Timeout)
+		defer cancel()
+	}
+	defer func() {
+		if value := recover(); value != nil {
+			logging.Debugf("Step %s panicked: %v\n%s", step.Name, value, debug.Stack())
+			err = &PanicError{
+				Step:  step.Name,
+				Value: value,
+			}
+		}
+	}()
+	return step.Run(ctx)
+}
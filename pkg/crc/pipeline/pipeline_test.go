@@ -0,0 +1,136 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunInOrder(t *testing.T) {
+	var order []string
+	step := func(name string) Step {
+		return Step{
+			Name: name,
+			Run: func(ctx context.Context) error {
+				order = append(order, name)
+				return nil
+			},
+		}
+	}
+	skipped := step("skipped")
+	skipped.Skip = func() bool { return true }
+
+	var done []string
+	p := New(step("first"), skipped, step("second"))
+	p.OnStepDone = func(result StepResult) {
+		done = append(done, result.Name)
+	}
+	results, err := p.Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, order)
+	assert.Equal(t, []string{"first", "skipped", "second"}, done)
+	require.Len(t, results, 3)
+	assert.True(t, results[1].Skipped)
+	assert.Equal(t, 1, results[2].Attempts)
+}
+
+func TestRunStopsAtFailure(t *testing.T) {
+	ran := false
+	results, err := New(
+		Step{
+			Name: "failing",
+			Run: func(ctx context.Context) error {
+				return errors.New("failed")
+			},
+		},
+		Step{
+			Name: "never",
+			Run: func(ctx context.Context) error {
+				ran = true
+				return nil
+			},
+		},
+	).Run(context.Background())
+	assert.EqualError(t, err, "failed")
+	var stepErr *StepError
+	require.True(t, errors.As(err, &stepErr))
+	assert.Equal(t, "failing", stepErr.Step)
+	assert.Len(t, results, 1)
+	assert.False(t, ran)
+}
+
+func TestRunRetries(t *testing.T) {
+	attempts := 0
+	results, err := New(Step{
+		Name:    "flaky",
+		Retries: 2,
+		Run: func(ctx context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("not yet")
+			}
+			return nil
+		},
+	}).Run(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, results[0].Attempts)
+
+	attempts = 0
+	_, err = New(Step{
+		Name:    "broken",
+		Retries: 1,
+		Run: func(ctx context.Context) error {
+			attempts++
+			return errors.New("broken")
+		},
+	}).Run(context.Background())
+	assert.EqualError(t, err, "broken")
+	assert.Equal(t, 2, attempts)
+}
+
+func TestRunTimeout(t *testing.T) {
+	_, err := New(Step{
+		Name:    "slow",
+		Timeout: 10 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+	}).Run(context.Background())
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestRunRecoversPanics(t *testing.T) {
+	attempts := 0
+	_, err := New(Step{
+		Name:    "panicking",
+		Retries: 3,
+		Run: func(ctx context.Context) error {
+			attempts++
+			var m map[string]string
+			m["key"] = "value"
+			return nil
+		},
+	}).Run(context.Background())
+	assert.EqualError(t, err, "step panicking panicked: assignment to entry in nil map")
+	var panicErr *PanicError
+	assert.True(t, errors.As(err, &panicErr))
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRunCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := New(Step{
+		Name: "cancelled",
+		Run: func(ctx context.Context) error {
+			return nil
+		},
+	}).Run(ctx)
+	assert.Equal(t, context.Canceled, err)
+	assert.Empty(t, results)
+}
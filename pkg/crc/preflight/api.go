@@ -0,0 +1,146 @@
+package preflight
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+)
+
+// CheckInfo describes a preflight check to the clients of the daemon API
+type CheckInfo struct {
+	Name           string
+	Description    string
+	FixDescription string
+	// the check can be fixed with FixCheck
+	Fixable bool
+	// the check is only run by 'crc setup', it is not needed to start the cluster
+	SetupOnly bool
+	// the check is skipped because of the skip-<name> setting
+	Skipped bool
+}
+
+// CheckResult is the outcome of a preflight check or fix
+type CheckResult struct {
+	Name    string
+	Success bool
+	Skipped bool
+	Error   string
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+
+// name identifies the check in the daemon API. The checks without a skip
+// setting are named after their description.
+func (check *Check) name() string {
+	if check.configKeySuffix != "" {
+		return check.configKeySuffix
+	}
+	description := strings.TrimPrefix(strings.ToLower(check.checkDescription), "checking ")
+	return strings.Trim(nonAlphanumeric.ReplaceAllString(description, "-"), "-")
+}
+
+func (check *Check) fixable() bool {
+	return check.fix != nil && check.flags&NoFix == 0 && check.flags&StartUpOnly == 0
+}
+
+func apiChecks(config crcConfig.Storage) []Check {
+	experimentalFeatures := config.Get(crcConfig.ExperimentalFeatures).AsBool()
+	mode := crcConfig.GetNetworkMode(config)
+	trayAutostart := config.Get(crcConfig.AutostartTray).AsBool()
+	var checks []Check
+	for _, check := range getPreflightChecks(experimentalFeatures, trayAutostart, mode, newNetworkConfig(config)) {
+		if check.flags&CleanUpOnly == CleanUpOnly {
+			continue
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// ListChecks returns the preflight checks of the host, in the order used by
+// 'crc setup'
+func ListChecks(config crcConfig.Storage) []CheckInfo {
+	return listChecks(config, apiChecks(config))
+}
+
+func listChecks(config crcConfig.Storage, checks []Check) []CheckInfo {
+	var infos []CheckInfo
+	for _, check := range checks {
+		infos = append(infos, CheckInfo{
+			Name:           check.name(),
+			Description:    check.checkDescription,
+			FixDescription: check.fixDescription,
+			Fixable:        check.fixable(),
+			SetupOnly:      check.flags&SetupOnly == SetupOnly,
+			Skipped:        check.shouldSkip(config),
+		})
+	}
+	return infos
+}
+
+func findCheck(checks []Check, name string) (*Check, error) {
+	for _, check := range checks {
+		if check.name() == name {
+			check := check
+			return &check, nil
+		}
+	}
+	return nil, fmt.Errorf("Unknown preflight check '%s'", name)
+}
+
+// RunCheck runs the preflight check called name. The error is only set when
+// the check cannot be run, a failed check is reported in the result.
+func RunCheck(config crcConfig.Storage, name string) (*CheckResult, error) {
+	return runCheck(config, apiChecks(config), name)
+}
+
+func runCheck(config crcConfig.Storage, checks []Check, name string) (*CheckResult, error) {
+	check, err := findCheck(checks, name)
+	if err != nil {
+		return nil, err
+	}
+	return checkResult(check, config, check.doCheck(config)), nil
+}
+
+// FixCheck runs the fix of the preflight check called name when the check
+// fails, and checks it again. The fixes needing administrator privileges may
+// fail when the daemon cannot ask for a password.
+func FixCheck(config crcConfig.Storage, name string) (*CheckResult, error) {
+	return fixCheck(config, apiChecks(config), name)
+}
+
+func fixCheck(config crcConfig.Storage, checks []Check, name string) (*CheckResult, error) {
+	check, err := findCheck(checks, name)
+	if err != nil {
+		return nil, err
+	}
+	if !check.fixable() {
+		return nil, fmt.Errorf("Preflight check '%s' cannot be fixed automatically", name)
+	}
+	return check.fixResult(config), nil
+}
+
+// fixResult runs the fix of check when it fails, and checks it again
+func (check *Check) fixResult(config crcConfig.Storage) *CheckResult {
+	if err := check.doCheck(config); err == nil {
+		return checkResult(check, config, nil)
+	}
+	if err := check.doFix(); err != nil {
+		return checkResult(check, config, err)
+	}
+	return checkResult(check, config, check.doCheck(config))
+}
+
+func checkResult(check *Check, config crcConfig.Storage, err error) *CheckResult {
+	result := &CheckResult{
+		Name:    check.name(),
+		Success: err == nil,
+		Skipped: check.shouldSkip(config),
+	}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package preflight
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckName(t *testing.T) {
+	check, _ := sampleCheck(nil, nil)
+	assert.Equal(t, "sample", check.name())
+	check.configKeySuffix = ""
+	check.checkDescription = "Checking if CRC bundle is extracted in '$HOME/.crc'"
+	assert.Equal(t, "if-crc-bundle-is-extracted-in-home-crc", check.name())
+}
+
+func TestListChecks(t *testing.T) {
+	check, calls := sampleCheck(nil, nil)
+	noFix, _ := sampleCheck(nil, nil)
+	noFix.configKeySuffix = "no-fix"
+	noFix.flags = NoFix
+	cfg := config.New(config.NewEmptyInMemoryStorage())
+	doRegisterSettings(cfg, []Check{*check, *noFix})
+	_, err := cfg.Set("skip-no-fix", true)
+	require.NoError(t, err)
+
+	assert.Equal(t, []CheckInfo{
+		{Name: "sample", Description: "Sample check", FixDescription: "sample fix", Fixable: true},
+		{Name: "no-fix", Description: "Sample check", FixDescription: "sample fix", Skipped: true},
+	}, listChecks(cfg, []Check{*check, *noFix}))
+	assert.False(t, calls.checked)
+}
+
+func TestRunCheck(t *testing.T) {
+	check, calls := sampleCheck(errors.New("check failed"), nil)
+	cfg := config.New(config.NewEmptyInMemoryStorage())
+	doRegisterSettings(cfg, []Check{*check})
+
+	result, err := runCheck(cfg, []Check{*check}, "sample")
+	assert.NoError(t, err)
+	assert.Equal(t, &CheckResult{Name: "sample", Error: "check failed"}, result)
+	assert.True(t, calls.checked)
+	assert.False(t, calls.fixed)
+
+	_, err = runCheck(cfg, []Check{*check}, "unknown")
+	assert.EqualError(t, err, "Unknown preflight check 'unknown'")
+}
+
+func TestFixCheck(t *testing.T) {
+	fixed := false
+	check, calls := sampleCheck(nil, nil)
+	check.check = func() error {
+		if !fixed {
+			return errors.New("check failed")
+		}
+		return nil
+	}
+	check.fix = func() error {
+		fixed = true
+		calls.fixed = true
+		return nil
+	}
+	cfg := config.New(config.NewEmptyInMemoryStorage())
+	doRegisterSettings(cfg, []Check{*check})
+
+	result, err := fixCheck(cfg, []Check{*check}, "sample")
+	assert.NoError(t, err)
+	assert.Equal(t, &CheckResult{Name: "sample", Success: true}, result)
+	assert.True(t, calls.fixed)
+
+	check.flags = NoFix
+	_, err = fixCheck(cfg, []Check{*check}, "sample")
+	assert.EqualError(t, err, "Preflight check 'sample' cannot be fixed automatically")
+}
+
+func TestFixCheckFailure(t *testing.T) {
+	check, _ := sampleCheck(errors.New("check failed"), errors.New("fix failed"))
+	cfg := config.New(config.NewEmptyInMemoryStorage())
+	doRegisterSettings(cfg, []Check{*check})
+
+	result, err := fixCheck(cfg, []Check{*check}, "sample")
+	assert.NoError(t, err)
+	assert.Equal(t, &CheckResult{Name: "sample", Error: "fix failed"}, result)
+}
@@ -0,0 +1,64 @@
+package preflight
+
+import (
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
+)
+
+// driverChecks returns the checks of the virtualization stack used by the
+// machine driver, in the order of 'crc setup'
+func driverChecks(checks []Check) []Check {
+	var driver []Check
+	for _, check := range checks {
+		if check.flags&Driver == Driver {
+			driver = append(driver, check)
+		}
+	}
+	return driver
+}
+
+// DriverHealth checks the virtualization stack used by the machine driver,
+// such as the state of the hypervisor service, the permissions of the user
+// and the definition of the VM network
+func DriverHealth(config crcConfig.Storage) []CheckResult {
+	return driverHealth(config, apiChecks(config))
+}
+
+func driverHealth(config crcConfig.Storage, checks []Check) []CheckResult {
+	var results []CheckResult
+	for _, check := range driverChecks(checks) {
+		check := check
+		results = append(results, *checkResult(&check, config, check.doCheck(config)))
+	}
+	return results
+}
+
+// RepairDriver fixes the failing checks of the virtualization stack, for
+// instance by restarting libvirt or recreating the crc network, and returns
+// their results after the fixes. The checks which cannot be fixed
+// automatically are only run.
+func RepairDriver(config crcConfig.Storage) []CheckResult {
+	return repairDriver(config, apiChecks(config))
+}
+
+func repairDriver(config crcConfig.Storage, checks []Check) []CheckResult {
+	var results []CheckResult
+	for _, check := range driverChecks(checks) {
+		check := check
+		if check.fix == nil || check.flags&NoFix == NoFix {
+			results = append(results, *checkResult(&check, config, check.doCheck(config)))
+			continue
+		}
+		results = append(results, *check.fixResult(config))
+	}
+	return results
+}
+
+// Healthy reports whether all the checks of results pass
+func Healthy(results []CheckResult) bool {
+	for _, result := range results {
+		if !result.Success {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package preflight
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDriverHealth(t *testing.T) {
+	broken, brokenCalls := sampleCheck(errors.New("libvirt is not running"), nil)
+	broken.configKeySuffix = "broken"
+	broken.flags = Driver
+	other, otherCalls := sampleCheck(errors.New("not enough memory"), nil)
+	other.configKeySuffix = "other"
+	cfg := config.New(config.NewEmptyInMemoryStorage())
+	doRegisterSettings(cfg, []Check{*broken, *other})
+
+	results := driverHealth(cfg, []Check{*broken, *other})
+	assert.Equal(t, []CheckResult{{Name: "broken", Error: "libvirt is not running"}}, results)
+	assert.False(t, Healthy(results))
+	assert.True(t, brokenCalls.checked)
+	assert.False(t, brokenCalls.fixed)
+	assert.False(t, otherCalls.checked)
+}
+
+func TestRepairDriver(t *testing.T) {
+	repaired := false
+	broken, calls := sampleCheck(nil, nil)
+	broken.flags = Driver
+	broken.check = func() error {
+		if !repaired {
+			return errors.New("libvirt is not running")
+		}
+		return nil
+	}
+	broken.fix = func() error {
+		repaired = true
+		calls.fixed = true
+		return nil
+	}
+	manual, manualCalls := sampleCheck(errors.New("log out and log in again"), nil)
+	manual.configKeySuffix = "manual"
+	manual.flags = NoFix | Driver
+	cfg := config.New(config.NewEmptyInMemoryStorage())
+	doRegisterSettings(cfg, []Check{*broken, *manual})
+
+	results := repairDriver(cfg, []Check{*broken, *manual})
+	assert.Equal(t, []CheckResult{
+		{Name: "sample", Success: true},
+		{Name: "manual", Error: "log out and log in again"},
+	}, results)
+	assert.False(t, Healthy(results))
+	assert.True(t, calls.fixed)
+	assert.False(t, manualCalls.fixed)
+
+	assert.True(t, Healthy(repairDriver(cfg, []Check{*broken})))
+}
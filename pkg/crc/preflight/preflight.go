@@ -2,6 +2,7 @@ package preflight
 
 import (
 	"fmt"
+	"net"
 
 	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	"github.com/code-ready/crc/pkg/crc/errors"
@@ -16,6 +17,9 @@ const (
 	NoFix
 	CleanUpOnly
 	StartUpOnly
+	// Indicates a PreflightCheck verifies the virtualization stack used by
+	// the machine driver, it is part of the driver health
+	Driver
 )
 
 type CheckFunc func() error
@@ -156,7 +160,7 @@ func StartPreflightChecks(config crcConfig.Storage) error {
 	experimentalFeatures := config.Get(crcConfig.ExperimentalFeatures).AsBool()
 	mode := crcConfig.GetNetworkMode(config)
 	trayAutostart := config.Get(crcConfig.AutostartTray).AsBool()
-	if err := doPreflightChecks(config, getPreflightChecks(experimentalFeatures, trayAutostart, mode)); err != nil {
+	if err := doPreflightChecks(config, getPreflightChecks(experimentalFeatures, trayAutostart, mode, newNetworkConfig(config))); err != nil {
 		return &errors.PreflightError{Err: err}
 	}
 	return nil
@@ -167,7 +171,27 @@ func SetupHost(config crcConfig.Storage, checkOnly bool) error {
 	experimentalFeatures := config.Get(crcConfig.ExperimentalFeatures).AsBool()
 	mode := crcConfig.GetNetworkMode(config)
 	trayAutostart := config.Get(crcConfig.AutostartTray).AsBool()
-	return doFixPreflightChecks(config, getPreflightChecks(experimentalFeatures, trayAutostart, mode), checkOnly)
+	return doFixPreflightChecks(config, getPreflightChecks(experimentalFeatures, trayAutostart, mode, newNetworkConfig(config)), checkOnly)
+}
+
+// networkConfig is the configuration of the VM network the checks set up
+// the host for
+type networkConfig struct {
+	subnet *net.IPNet
+	// ipv6Subnet is the optional IPv6 subnet of the VM network, nil on
+	// IPv4-only networks
+	ipv6Subnet *net.IPNet
+	// hostDNS is true when the cluster hostnames are resolved by the DNS
+	// server of 'crc daemon' instead of the one of the VM
+	hostDNS bool
+}
+
+func newNetworkConfig(config crcConfig.Storage) networkConfig {
+	return networkConfig{
+		subnet:     crcConfig.GetNetworkCIDR(config),
+		ipv6Subnet: crcConfig.GetNetworkIPv6CIDR(config),
+		hostDNS:    crcConfig.GetDNSService(config) == crcConfig.HostDNSService,
+	}
 }
 
 func RegisterSettings(config crcConfig.Schema) {
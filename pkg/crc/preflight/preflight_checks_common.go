@@ -2,19 +2,58 @@ package preflight
 
 import (
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/code-ready/crc/pkg/crc/adminhelper"
 	"github.com/code-ready/crc/pkg/crc/cluster"
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"github.com/code-ready/crc/pkg/crc/validation"
 	"github.com/code-ready/crc/pkg/embed"
 	crcos "github.com/code-ready/crc/pkg/os"
 	"github.com/pkg/errors"
 )
 
+// storageCheck runs before bundleCheck so that the bundle is not extracted to a
+// filesystem which cannot hold it
+var storageCheck = Check{
+	configKeySuffix:  "check-storage-directories",
+	checkDescription: "Checking if the filesystem of the bundle and instance directories is suitable",
+	check:            checkStorageDirectories,
+	fixDescription:   "Set " + constants.StorageDirEnv + " to a directory of a writable local filesystem, the bundles and the instances are then kept there instead of '$HOME/.crc'",
+	flags:            NoFix,
+
+	labels: None,
+}
+
+// diskLatencyCheck runs after storageCheck, which checks that the instance
+// directory is writable
+var diskLatencyCheck = Check{
+	configKeySuffix:  "check-disk-latency",
+	checkDescription: "Checking if the storage of the instance directory is fast enough for etcd",
+	check:            checkDiskLatency,
+	fixDescription:   "Set " + constants.StorageDirEnv + " to a directory of a local SSD, the bundles and the instances are then kept there instead of '$HOME/.crc'",
+	flags:            NoFix,
+
+	labels: None,
+}
+
+// homeDirectoryCheck runs before storageCheck, the paths of '$HOME/.crc' are
+// derived from the home directory
+var homeDirectoryCheck = Check{
+	configKeySuffix:  "check-home-directory",
+	checkDescription: "Checking if the home directory is supported",
+	check:            checkHomeDirectory,
+	fixDescription:   "Use a local home directory with a shorter path",
+	flags:            NoFix,
+
+	labels: None,
+}
+
 var bundleCheck = Check{
 	configKeySuffix:  "check-bundle-extracted",
 	checkDescription: "Checking if CRC bundle is extracted in '$HOME/.crc'",
@@ -26,6 +65,18 @@ var bundleCheck = Check{
 	labels: None,
 }
 
+// cpuFeaturesCheck runs after bundleCheck, which extracts the bundle declaring
+// the requirements
+var cpuFeaturesCheck = Check{
+	configKeySuffix:  "check-cpu-features",
+	checkDescription: "Checking if the CPU has the features required by the bundle",
+	check:            checkCPUFeatures,
+	fixDescription:   "The virtual machine of the bundle cannot run on this CPU, a bundle for older CPUs or a newer host is needed",
+	flags:            NoFix,
+
+	labels: None,
+}
+
 var genericCleanupChecks = []Check{
 	{
 		cleanupDescription: "Removing CRC Machine Instance directory",
@@ -71,6 +122,148 @@ func checkBundleExtracted() error {
 	return nil
 }
 
+func checkCPUFeatures() error {
+	bundleInfo, err := bundle.Get(constants.GetDefaultBundle())
+	if err != nil {
+		logging.Debugf("Cannot get the CPU requirements of %s, skipping check: %v", constants.GetDefaultBundle(), err)
+		return nil
+	}
+	return validation.ValidateCPUFeatures(bundleInfo.ClusterInfo.RequiredCPUFeatures)
+}
+
+// minFreeInodes is the number of free inodes needed to extract a bundle and
+// to create the instance files, with a large margin
+const minFreeInodes = 1000
+
+func checkHomeDirectory() error {
+	warnings, err := crcos.CheckHomeDirectory(constants.GetHomeDir())
+	if err != nil {
+		return err
+	}
+	for _, warning := range warnings {
+		logging.Warn(warning)
+	}
+	// the longest paths crc gives to the hypervisor and its tools
+	paths := []string{
+		filepath.Join(constants.MachineCacheDir, bundle.GetBundleNameWithoutExtension(constants.GetDefaultBundle()), "crc-bundle-info.json"),
+		constants.GetKubeAdminPasswordPath(),
+	}
+	for _, path := range paths {
+		if err := crcos.CheckPathLength(path); err != nil {
+			return err
+		}
+	}
+	for _, path := range daemonSocketPaths() {
+		if err := crcos.CheckSocketPath(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkStorageDirectories() error {
+	for _, dir := range []string{constants.MachineCacheDir, constants.MachineInstanceDir} {
+		existingDir := crcos.ExistingParent(dir)
+		info, err := crcos.GetFilesystemInfo(existingDir)
+		if err != nil {
+			logging.Debugf("Cannot check the filesystem of %s: %v", dir, err)
+			continue
+		}
+		logging.Debugf("%s is on a %s filesystem", dir, info.Type)
+		if err := validateFilesystem(dir, info); err != nil {
+			return err
+		}
+		if err := checkWritable(existingDir); err != nil {
+			return fmt.Errorf("%s is not writable: %v", existingDir, err)
+		}
+	}
+	return nil
+}
+
+func validateFilesystem(dir string, info crcos.FilesystemInfo) error {
+	switch {
+	case info.ReadOnly:
+		return fmt.Errorf("%s is on a read-only filesystem", dir)
+	case info.Remote:
+		return fmt.Errorf("%s is on a %s network filesystem, it does not support the memory mapped files and the locks used by the bundle extraction and the virtual machine", dir, info.Type)
+	case info.InodesKnown && info.FreeInodes < minFreeInodes:
+		return fmt.Errorf("The filesystem of %s has only %d free inodes, at least %d are needed", dir, info.FreeInodes, minFreeInodes)
+	}
+	return nil
+}
+
+// checkWritable creates a file in dir, some filesystems are mounted
+// read-write but reject writes, for instance when they are full or because of
+// their permissions
+func checkWritable(dir string) error {
+	file, err := ioutil.TempFile(dir, ".crc-write-check")
+	if err != nil {
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	return os.Remove(file.Name())
+}
+
+const (
+	// etcd recommends a 99th percentile of the syncs of its write-ahead
+	// log below 10ms
+	diskLatencyWarningThreshold = 10 * time.Millisecond
+	// above the heartbeat interval of etcd, the cluster never becomes
+	// healthy
+	diskLatencyErrorThreshold = 100 * time.Millisecond
+
+	// the size of the entries of the write-ahead log of etcd, as in the fio
+	// benchmark recommended by the etcd documentation
+	diskLatencyBlockSize = 2300
+	diskLatencyWrites    = 200
+	diskLatencyMaxTime   = 10 * time.Second
+	// a slow storage is measured again, the lowest latency is kept so that
+	// the writes of another process during a measurement do not count
+	diskLatencyMeasurements = 3
+)
+
+// checkDiskLatency measures the sync latency of the filesystem holding the
+// disk image of the instance, the cluster stays degraded when etcd cannot
+// persist its writes fast enough, for instance on NFS homes or SD cards. The
+// measurement depends on the load of the host, a slow storage only gets a
+// warning.
+func checkDiskLatency() error {
+	dir := crcos.ExistingParent(constants.MachineInstanceDir)
+	var latency time.Duration
+	for i := 0; i < diskLatencyMeasurements; i++ {
+		measured, err := crcos.SyncLatency(dir, diskLatencyWrites, diskLatencyBlockSize, diskLatencyMaxTime)
+		if err != nil {
+			logging.Debugf("Cannot measure the sync latency of %s: %v", dir, err)
+			return nil
+		}
+		logging.Debugf("99th percentile of the sync latency of %s: %v", dir, measured)
+		if i == 0 || measured < latency {
+			latency = measured
+		}
+		if latency <= diskLatencyWarningThreshold {
+			break
+		}
+	}
+	if warning := diskLatencyWarning(dir, latency); warning != "" {
+		logging.Warn(warning)
+	}
+	return nil
+}
+
+// diskLatencyWarning returns the warning about the storage of dir, it is
+// empty when the storage is fast enough
+func diskLatencyWarning(dir string, latency time.Duration) string {
+	switch {
+	case latency > diskLatencyErrorThreshold:
+		return fmt.Sprintf("The storage of %s takes %v to persist the writes of etcd, above %v the cluster cannot become healthy", dir, latency.Round(time.Millisecond), diskLatencyErrorThreshold)
+	case latency > diskLatencyWarningThreshold:
+		return fmt.Sprintf("The storage of %s takes %v to persist the writes of etcd, above %v the cluster may be degraded", dir, latency.Round(time.Millisecond), diskLatencyWarningThreshold)
+	}
+	return ""
+}
+
 func fixBundleExtracted() error {
 	// Should be removed after 1.19 release
 	// This check will ensure correct mode for `~/.crc/cache` directory
@@ -99,7 +292,8 @@ func fixBundleExtracted() error {
 	_, err := bundle.Get(constants.GetDefaultBundle())
 	if err != nil {
 		logging.Infof("Uncompressing %s", constants.GetDefaultBundle())
-		_, err := bundle.Extract(constants.DefaultBundlePath)
+		// the default bundle is shipped with the crc executable, its signature is not checked
+		_, err := bundle.Extract(constants.DefaultBundlePath, bundle.SignatureCheck{})
 		return err
 	}
 
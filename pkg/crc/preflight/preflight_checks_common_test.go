@@ -0,0 +1,31 @@
+package preflight
+
+import (
+	"testing"
+	"time"
+
+	crcos "github.com/code-ready/crc/pkg/os"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateFilesystem(t *testing.T) {
+	assert.NoError(t, validateFilesystem("/home/user/.crc", crcos.FilesystemInfo{Type: "ext4", InodesKnown: true, FreeInodes: 100000}))
+	// inodes allocated on demand
+	assert.NoError(t, validateFilesystem("/home/user/.crc", crcos.FilesystemInfo{Type: "btrfs"}))
+
+	assert.Error(t, validateFilesystem("/home/user/.crc", crcos.FilesystemInfo{Type: "ext4", ReadOnly: true}))
+	assert.Error(t, validateFilesystem("/home/user/.crc", crcos.FilesystemInfo{Type: "nfs", Remote: true}))
+	assert.Error(t, validateFilesystem("/home/user/.crc", crcos.FilesystemInfo{Type: "ext4", InodesKnown: true, FreeInodes: 10}))
+}
+
+func TestCheckWritable(t *testing.T) {
+	assert.NoError(t, checkWritable(t.TempDir()))
+}
+
+func TestDiskLatencyWarning(t *testing.T) {
+	assert.Empty(t, diskLatencyWarning("/home/user/.crc/machines", 2*time.Millisecond))
+	assert.Equal(t, "The storage of /home/user/.crc/machines takes 30ms to persist the writes of etcd, above 10ms the cluster may be degraded",
+		diskLatencyWarning("/home/user/.crc/machines", 30*time.Millisecond))
+	assert.Equal(t, "The storage of /home/user/.crc/machines takes 350ms to persist the writes of etcd, above 100ms the cluster cannot become healthy",
+		diskLatencyWarning("/home/user/.crc/machines", 350*time.Millisecond))
+}
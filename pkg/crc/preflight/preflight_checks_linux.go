@@ -17,6 +17,7 @@ import (
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/libvirt"
+	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/systemd"
 	"github.com/code-ready/crc/pkg/crc/systemd/states"
 	crcos "github.com/code-ready/crc/pkg/os"
@@ -339,7 +340,13 @@ func checkSystemdUnit(unitName string, unitContent string, shouldBeRunning bool)
 }
 
 func daemonUnitContent() string {
-	return fmt.Sprintf(daemonUnitTemplate, constants.CrcSymlinkPath)
+	content := fmt.Sprintf(daemonUnitTemplate, constants.CrcSymlinkPath)
+	// the daemon does not inherit the environment of 'crc setup', it must use
+	// the same storage directory as the other commands
+	if constants.MachineBaseDir != constants.CrcBaseDir {
+		content += fmt.Sprintf("Environment=\"%s=%s\"\n", constants.StorageDirEnv, constants.MachineBaseDir)
+	}
+	return content
 }
 
 func checkDaemonSystemdSockets() error {
@@ -466,6 +473,28 @@ func fixLibvirtServiceRunning() error {
 	return nil
 }
 
+func checkLibvirtConnection() error {
+	logging.Debug("Checking if libvirt accepts connections")
+	_, stdErr, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "uri")
+	if err != nil {
+		return fmt.Errorf("Cannot connect to libvirt %v: %s", err, strings.TrimSpace(stdErr))
+	}
+	logging.Debug("libvirt accepts connections")
+	return nil
+}
+
+func fixLibvirtConnection() error {
+	logging.Debug("Restarting libvirtd.service")
+	sd := systemd.NewHostSystemdCommander()
+	// a stale daemon, for instance after an update of libvirt, is running
+	// but does not accept connections
+	if err := sd.Restart("libvirtd"); err != nil {
+		return fmt.Errorf("Failed to restart libvirt service: %v", err)
+	}
+	logging.Debug("libvirtd.service is restarted")
+	return nil
+}
+
 func checkMachineDriverLibvirtInstalled() error {
 	machineDriverLibvirt := cache.NewMachineDriverLibvirtCache()
 
@@ -493,21 +522,28 @@ func fixMachineDriverLibvirtInstalled() error {
 	return nil
 }
 
-func checkLibvirtCrcNetworkAvailable() error {
+func checkLibvirtCrcNetworkAvailable(netConfig networkConfig) error {
 	logging.Debug("Checking if libvirt 'crc' network exists")
 	_, _, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "net-info", "crc")
 	if err != nil {
 		return fmt.Errorf("Libvirt network crc not found")
 	}
 
-	return checkLibvirtCrcNetworkDefinition()
+	return checkLibvirtCrcNetworkDefinition(netConfig)
 }
 
-func getLibvirtNetworkXML() (string, error) {
+func getLibvirtNetworkXML(netConfig networkConfig) (string, error) {
 	config := libvirt.NetworkConfig{
 		NetworkName: libvirt.DefaultNetwork,
 		MAC:         libvirt.MACAddress,
-		IP:          libvirt.IPAddress,
+		IP:          network.InstanceIP(netConfig.subnet).String(),
+		HostIP:      network.DetermineHostIP(netConfig.subnet).String(),
+		Prefix:      network.PrefixLength(netConfig.subnet),
+	}
+	if netConfig.ipv6Subnet != nil {
+		config.IPv6 = network.InstanceIP(netConfig.ipv6Subnet).String()
+		config.HostIPv6 = network.DetermineHostIP(netConfig.ipv6Subnet).String()
+		config.IPv6Prefix = network.PrefixLength(netConfig.ipv6Subnet)
 	}
 	t, err := template.New("netxml").Parse(libvirt.NetworkTemplate)
 	if err != nil {
@@ -522,10 +558,10 @@ func getLibvirtNetworkXML() (string, error) {
 	return netXMLDef.String(), nil
 }
 
-func fixLibvirtCrcNetworkAvailable() error {
+func fixLibvirtCrcNetworkAvailable(netConfig networkConfig) error {
 	logging.Debug("Creating libvirt 'crc' network")
 
-	netXMLDef, err := getLibvirtNetworkXML()
+	netXMLDef, err := getLibvirtNetworkXML(netConfig)
 	if err != nil {
 		logging.Debugf("getLibvirtNetworkXML() failed: %v", err)
 		return fmt.Errorf("Failed to read libvirt 'crc' network definition")
@@ -628,7 +664,7 @@ func trimSpacesFromXML(str string) string {
 	return builder.String()
 }
 
-func checkLibvirtCrcNetworkDefinition() error {
+func checkLibvirtCrcNetworkDefinition(netConfig networkConfig) error {
 	logging.Debug("Checking if libvirt 'crc' definition is up to date")
 	stdOut, _, err := crcos.RunWithDefaultLocale("virsh", "--connect", "qemu:///system", "net-dumpxml", "--inactive", "crc")
 	if err != nil {
@@ -636,7 +672,7 @@ func checkLibvirtCrcNetworkDefinition() error {
 	}
 	stdOut = trimSpacesFromXML(stdOut)
 
-	netXMLDef, err := getLibvirtNetworkXML()
+	netXMLDef, err := getLibvirtNetworkXML(netConfig)
 	if err != nil {
 		return fmt.Errorf("Failed to generate 'crc' network XML from template: %s", err)
 	}
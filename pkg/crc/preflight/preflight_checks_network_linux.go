@@ -8,7 +8,10 @@ import (
 	"os/exec"
 	"path/filepath"
 
+	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/network/resolved"
 	"github.com/code-ready/crc/pkg/crc/systemd"
 	"github.com/code-ready/crc/pkg/crc/systemd/states"
 	crcos "github.com/code-ready/crc/pkg/os"
@@ -44,37 +47,43 @@ var nmPreflightChecks = []Check{
 	},
 }
 
-var dnsmasqPreflightChecks = []Check{
-	{
-		configKeySuffix:    "check-network-manager-config",
-		checkDescription:   "Checking if /etc/NetworkManager/conf.d/crc-nm-dnsmasq.conf exists",
-		check:              checkCrcNetworkManagerConfig,
-		fixDescription:     "Writing Network Manager config for crc",
-		fix:                fixCrcNetworkManagerConfig,
-		cleanupDescription: "Removing /etc/NetworkManager/conf.d/crc-nm-dnsmasq.conf file",
-		cleanup:            removeCrcNetworkManagerConfig,
-
-		labels: labels{Os: Linux, NetworkMode: System, DNS: Dnsmasq},
-	},
-	{
-		configKeySuffix:    "check-crc-dnsmasq-file",
-		checkDescription:   "Checking if /etc/NetworkManager/dnsmasq.d/crc.conf exists",
-		check:              checkCrcDnsmasqConfigFile,
-		fixDescription:     "Writing dnsmasq config for crc",
-		fix:                fixCrcDnsmasqConfigFile,
-		cleanupDescription: "Removing /etc/NetworkManager/dnsmasq.d/crc.conf file",
-		cleanup:            removeCrcDnsmasqConfigFile,
-
-		labels: labels{Os: Linux, NetworkMode: System, DNS: Dnsmasq},
-	},
+func dnsmasqPreflightChecks(netConfig networkConfig) []Check {
+	return []Check{
+		{
+			configKeySuffix:    "check-network-manager-config",
+			checkDescription:   "Checking if /etc/NetworkManager/conf.d/crc-nm-dnsmasq.conf exists",
+			check:              checkCrcNetworkManagerConfig,
+			fixDescription:     "Writing Network Manager config for crc",
+			fix:                fixCrcNetworkManagerConfig,
+			cleanupDescription: "Removing /etc/NetworkManager/conf.d/crc-nm-dnsmasq.conf file",
+			cleanup:            removeCrcNetworkManagerConfig,
+
+			labels: labels{Os: Linux, NetworkMode: System, DNS: Dnsmasq},
+		},
+		{
+			configKeySuffix:  "check-crc-dnsmasq-file",
+			checkDescription: "Checking if /etc/NetworkManager/dnsmasq.d/crc.conf exists",
+			check: func() error {
+				return checkCrcDnsmasqConfigFile(netConfig)
+			},
+			fixDescription: "Writing dnsmasq config for crc",
+			fix: func() error {
+				return fixCrcDnsmasqConfigFile(netConfig)
+			},
+			cleanupDescription: "Removing /etc/NetworkManager/dnsmasq.d/crc.conf file",
+			cleanup:            removeCrcDnsmasqConfigFile,
+
+			labels: labels{Os: Linux, NetworkMode: System, DNS: Dnsmasq},
+		},
+	}
 }
 
 var (
 	crcNetworkManagerRootPath = filepath.Join(string(filepath.Separator), "etc", "NetworkManager")
 
-	crcDnsmasqConfigPath = filepath.Join(crcNetworkManagerRootPath, "dnsmasq.d", "crc.conf")
-	crcDnsmasqConfig     = `server=/apps-crc.testing/192.168.130.11
-server=/crc.testing/192.168.130.11
+	crcDnsmasqConfigPath     = filepath.Join(crcNetworkManagerRootPath, "dnsmasq.d", "crc.conf")
+	crcDnsmasqConfigTemplate = `server=/apps-crc.testing/%[1]s
+server=/crc.testing/%[1]s
 `
 
 	crcNetworkManagerConfigPath = filepath.Join(crcNetworkManagerRootPath, "conf.d", "crc-nm-dnsmasq.conf")
@@ -82,64 +91,117 @@ server=/crc.testing/192.168.130.11
 dns=dnsmasq
 `
 
-	crcNetworkManagerOldDispatcherPath = filepath.Join(crcNetworkManagerRootPath, "dispatcher.d", "pre-up.d", "99-crc.sh")
-	crcNetworkManagerDispatcherPath    = filepath.Join(crcNetworkManagerRootPath, "dispatcher.d", "99-crc.sh")
-	crcNetworkManagerDispatcherConfig  = `#!/bin/sh
+	crcNetworkManagerOldDispatcherPath  = filepath.Join(crcNetworkManagerRootPath, "dispatcher.d", "pre-up.d", "99-crc.sh")
+	crcNetworkManagerDispatcherPath     = filepath.Join(crcNetworkManagerRootPath, "dispatcher.d", "99-crc.sh")
+	crcNetworkManagerDispatcherTemplate = `#!/bin/sh
 # This is a NetworkManager dispatcher script to configure split DNS for
 # the 'crc' libvirt network.
 #
-# The corresponding crc bridge is not created through NetworkManager, so
-# it cannot be configured permanently through NetworkManager. We make the
-# change directly using systemd-resolve instead.
+# crc configures the DNS of the crc bridge with the D-Bus API of
+# systemd-resolved when the cluster starts. NetworkManager will overwrite
+# this configuration every time a network connection goes up/down, so we run
+# this script on each of these events to restore it. This is a NetworkManager
+# bug which is fixed in version 1.26.6 by this commit:
+# https://cgit.freedesktop.org/NetworkManager/NetworkManager/commit/?id=ee4e679bc7479de42780ebd8e3a4d74afa2b2ebe
 #
 # systemd-resolve is used instead of resolvectl due to distributions shipping
 # systemd releases older than 239 not having the newer renamed tool. resolvectl
 # supports being called as systemd-resolve, correctly handling the old CLI.
-#
-# NetworkManager will overwrite this systemd-resolve configuration every time a
-# network connection goes up/down, so we run this script on each of these events
-# to restore our settings. This is a NetworkManager bug which is fixed in
-# version 1.26.6 by this commit:
-# https://cgit.freedesktop.org/NetworkManager/NetworkManager/commit/?id=ee4e679bc7479de42780ebd8e3a4d74afa2b2ebe
 
 export LC_ALL=C
 
-systemd-resolve --interface crc --set-dns 192.168.130.11 --set-domain ~testing
+[ -d /sys/class/net/crc ] || exit 0
+systemd-resolve --interface crc --set-dns %s --set-domain ~testing
 
 exit 0
+`
+
+	crcResolvedPolkitRulePath = filepath.Join(string(filepath.Separator), "etc", "polkit-1", "rules.d", "50-crc-resolved.rules")
+	// systemd-resolved asks for the password of an administrator to change
+	// the DNS configuration of a link, the user is in the libvirt group. The
+	// rule is limited to the DNS servers and domains of the crc bridge,
+	// systemd releases which do not tell polkit the link get no rule and
+	// the dispatcher script configures the bridge.
+	crcResolvedPolkitRule = `polkit.addRule(function(action, subject) {
+    if ((action.id == "org.freedesktop.resolve1.set-dns-servers" ||
+         action.id == "org.freedesktop.resolve1.set-domains") &&
+        action.lookup("ifname") == "crc" &&
+        subject.isInGroup("libvirt")) {
+        return polkit.Result.YES;
+    }
+});
 `
 )
 
-var systemdResolvedPreflightChecks = []Check{
-	{
-		configKeySuffix:  "check-dnsmasq-network-manager-config",
-		checkDescription: "Checking if dnsmasq configurations file exist for NetworkManager",
-		check:            checkCrcDnsmasqAndNetworkManagerConfigFile,
-		fixDescription:   "Removing dnsmasq configuration file for NetworkManager",
-		fix:              fixCrcDnsmasqAndNetworkManagerConfigFile,
+// crcDnsmasqConfig forwards the queries of the crc domains to the VM, or to
+// 'crc daemon' with the host DNS service
+func crcDnsmasqConfig(netConfig networkConfig) string {
+	if netConfig.hostDNS {
+		return fmt.Sprintf(crcDnsmasqConfigTemplate, fmt.Sprintf("127.0.0.1#%d", constants.HostDNSPort))
+	}
+	return fmt.Sprintf(crcDnsmasqConfigTemplate, network.InstanceIP(netConfig.subnet))
+}
 
-		labels: labels{Os: Linux, NetworkMode: System, DNS: SystemdResolved},
-	},
-	{
-		configKeySuffix:  "check-systemd-resolved-running",
-		checkDescription: "Checking if the systemd-resolved service is running",
-		check:            checkSystemdResolvedIsRunning,
-		fixDescription:   "systemd-resolved is required on this distribution. Please make sure it is installed and running manually",
-		flags:            NoFix,
+// crcNetworkManagerDispatcherConfig forwards the queries of the crc domains
+// to the VM, or to 'crc daemon' with the host DNS service when
+// systemd-resolved supports its port
+func crcNetworkManagerDispatcherConfig(netConfig networkConfig) string {
+	if netConfig.hostDNS && resolved.SupportsPorts() {
+		return fmt.Sprintf(crcNetworkManagerDispatcherTemplate, fmt.Sprintf("127.0.0.1:%d", constants.HostDNSPort))
+	}
+	return fmt.Sprintf(crcNetworkManagerDispatcherTemplate, network.InstanceIP(netConfig.subnet))
+}
 
-		labels: labels{Os: Linux, NetworkMode: System, DNS: SystemdResolved},
-	},
-	{
-		configKeySuffix:    "check-network-manager-dispatcher-file",
-		checkDescription:   fmt.Sprintf("Checking if %s exists", crcNetworkManagerDispatcherPath),
-		check:              checkCrcNetworkManagerDispatcherFile,
-		fixDescription:     "Writing NetworkManager dispatcher file for crc",
-		fix:                fixCrcNetworkManagerDispatcherFile,
-		cleanupDescription: fmt.Sprintf("Removing %s file", crcNetworkManagerDispatcherPath),
-		cleanup:            removeCrcNetworkManagerDispatcherFile,
-
-		labels: labels{Os: Linux, NetworkMode: System, DNS: SystemdResolved},
-	},
+// With systemd-resolved, the cluster domains are routed to the VM through the
+// 'crc' bridge with its D-Bus API during the start, and by the NetworkManager
+// dispatcher script when NetworkManager resets the bridge
+func systemdResolvedPreflightChecks(netConfig networkConfig) []Check {
+	return []Check{
+		{
+			configKeySuffix:  "check-dnsmasq-network-manager-config",
+			checkDescription: "Checking if dnsmasq configurations file exist for NetworkManager",
+			check:            checkCrcDnsmasqAndNetworkManagerConfigFile,
+			fixDescription:   "Removing dnsmasq configuration file for NetworkManager",
+			fix:              fixCrcDnsmasqAndNetworkManagerConfigFile,
+
+			labels: labels{Os: Linux, NetworkMode: System, DNS: SystemdResolved},
+		},
+		{
+			configKeySuffix:  "check-systemd-resolved-running",
+			checkDescription: "Checking if the systemd-resolved service is running",
+			check:            checkSystemdResolvedIsRunning,
+			fixDescription:   "systemd-resolved is required on this distribution. Please make sure it is installed and running manually",
+			flags:            NoFix,
+
+			labels: labels{Os: Linux, NetworkMode: System, DNS: SystemdResolved},
+		},
+		{
+			configKeySuffix:  "check-network-manager-dispatcher-file",
+			checkDescription: fmt.Sprintf("Checking if %s exists", crcNetworkManagerDispatcherPath),
+			check: func() error {
+				return checkCrcNetworkManagerDispatcherFile(netConfig)
+			},
+			fixDescription: "Writing NetworkManager dispatcher file for crc",
+			fix: func() error {
+				return fixCrcNetworkManagerDispatcherFile(netConfig)
+			},
+			cleanupDescription: fmt.Sprintf("Removing %s file", crcNetworkManagerDispatcherPath),
+			cleanup:            removeCrcNetworkManagerDispatcherFile,
+
+			labels: labels{Os: Linux, NetworkMode: System, DNS: SystemdResolved},
+		},
+		{
+			configKeySuffix:    "check-systemd-resolved-polkit-rule",
+			checkDescription:   fmt.Sprintf("Checking if %s exists", crcResolvedPolkitRulePath),
+			check:              checkCrcResolvedPolkitRule,
+			fixDescription:     "Allowing the libvirt group to configure the DNS of the crc network",
+			fix:                fixCrcResolvedPolkitRule,
+			cleanupDescription: fmt.Sprintf("Removing %s file", crcResolvedPolkitRulePath),
+			cleanup:            removeCrcResolvedPolkitRule,
+
+			labels: labels{Os: Linux, NetworkMode: System, DNS: SystemdResolved},
+		},
+	}
 }
 
 func fixNetworkManagerConfigFile(path string, content string, perms os.FileMode) error {
@@ -186,9 +248,9 @@ func removeNetworkManagerConfigFile(path string) error {
 	return nil
 }
 
-func checkCrcDnsmasqConfigFile() error {
+func checkCrcDnsmasqConfigFile(netConfig networkConfig) error {
 	logging.Debug("Checking dnsmasq configuration")
-	err := crcos.FileContentMatches(crcDnsmasqConfigPath, []byte(crcDnsmasqConfig))
+	err := crcos.FileContentMatches(crcDnsmasqConfigPath, []byte(crcDnsmasqConfig(netConfig)))
 	if err != nil {
 		return err
 	}
@@ -196,9 +258,9 @@ func checkCrcDnsmasqConfigFile() error {
 	return nil
 }
 
-func fixCrcDnsmasqConfigFile() error {
+func fixCrcDnsmasqConfigFile(netConfig networkConfig) error {
 	logging.Debug("Fixing dnsmasq configuration")
-	err := fixNetworkManagerConfigFile(crcDnsmasqConfigPath, crcDnsmasqConfig, 0644)
+	err := fixNetworkManagerConfigFile(crcDnsmasqConfigPath, crcDnsmasqConfig(netConfig), 0644)
 	if err != nil {
 		return err
 	}
@@ -277,9 +339,9 @@ func checkSystemdResolvedIsRunning() error {
 	return checkSystemdServiceRunning("systemd-resolved.service")
 }
 
-func checkCrcNetworkManagerDispatcherFile() error {
+func checkCrcNetworkManagerDispatcherFile(netConfig networkConfig) error {
 	logging.Debug("Checking NetworkManager dispatcher file for crc network")
-	err := crcos.FileContentMatches(crcNetworkManagerDispatcherPath, []byte(crcNetworkManagerDispatcherConfig))
+	err := crcos.FileContentMatches(crcNetworkManagerDispatcherPath, []byte(crcNetworkManagerDispatcherConfig(netConfig)))
 	if err != nil {
 		return err
 	}
@@ -287,13 +349,13 @@ func checkCrcNetworkManagerDispatcherFile() error {
 	return nil
 }
 
-func fixCrcNetworkManagerDispatcherFile() error {
+func fixCrcNetworkManagerDispatcherFile(netConfig networkConfig) error {
 	logging.Debug("Fixing NetworkManager dispatcher configuration")
 
 	// Remove dispatcher script which was used in crc 1.20 - it's been moved to a new location
 	_ = removeNetworkManagerConfigFile(crcNetworkManagerOldDispatcherPath)
 
-	err := fixNetworkManagerConfigFile(crcNetworkManagerDispatcherPath, crcNetworkManagerDispatcherConfig, 0755)
+	err := fixNetworkManagerConfigFile(crcNetworkManagerDispatcherPath, crcNetworkManagerDispatcherConfig(netConfig), 0755)
 	if err != nil {
 		return err
 	}
@@ -331,3 +393,24 @@ func fixCrcDnsmasqAndNetworkManagerConfigFile() error {
 	}
 	return nil
 }
+
+func checkCrcResolvedPolkitRule() error {
+	return crcos.FileContentMatches(crcResolvedPolkitRulePath, []byte(crcResolvedPolkitRule))
+}
+
+func fixCrcResolvedPolkitRule() error {
+	// polkit reloads its rules when they change
+	return crcos.WriteToFileAsRoot(
+		fmt.Sprintf("Writing polkit rule to %s", crcResolvedPolkitRulePath),
+		crcResolvedPolkitRule,
+		crcResolvedPolkitRulePath,
+		0644,
+	)
+}
+
+func removeCrcResolvedPolkitRule() error {
+	if _, err := os.Stat(crcResolvedPolkitRulePath); os.IsNotExist(err) {
+		return nil
+	}
+	return crcos.RemoveFileAsRoot(fmt.Sprintf("Removing polkit rule in %s", crcResolvedPolkitRulePath), crcResolvedPolkitRulePath)
+}
@@ -71,6 +71,10 @@ var genericPreflightChecks = []Check{
 	},
 }
 
+func daemonSocketPaths() []string {
+	return []string{constants.DaemonSocketPath, constants.DaemonHTTPSocketPath}
+}
+
 func checkIfRunningAsNormalUser() error {
 	if os.Geteuid() != 0 {
 		return nil
@@ -19,6 +19,12 @@ const (
 	minimumWindowsReleaseID = 1709
 )
 
+// the daemon listens on a unix socket and a named pipe, only the socket
+// path length is limited
+func daemonSocketPaths() []string {
+	return []string{constants.DaemonSocketPath}
+}
+
 func checkVersionOfWindowsUpdate() error {
 	windowsReleaseID := `(Get-ItemProperty -Path "HKLM:\SOFTWARE\Microsoft\Windows NT\CurrentVersion" -Name ReleaseId).ReleaseId`
 
@@ -99,6 +105,13 @@ func checkHyperVServiceRunning() error {
 	return nil
 }
 
+func fixHyperVServiceRunning() error {
+	if _, stdErr, err := powershell.ExecuteAsAdmin("starting Hyper-V Virtual Machine Management service", "Start-Service vmms"); err != nil {
+		return fmt.Errorf("Failed to start Hyper-V Virtual Machine Management service %v: %s", err, stdErr)
+	}
+	return nil
+}
+
 func checkIfUserPartOfHyperVAdmins() error {
 	// https://support.microsoft.com/en-us/help/243330/well-known-security-identifiers-in-windows-operating-systems
 	// BUILTIN\Hyper-V Administrators => S-1-5-32-578
@@ -25,6 +25,7 @@ func hyperkitPreflightChecks(networkMode network.Mode) []Check {
 			check:            checkHyperKitInstalled(networkMode),
 			fixDescription:   "Setting up virtualization with HyperKit",
 			fix:              fixHyperKitInstallation(networkMode),
+			flags:            Driver,
 
 			labels: labels{Os: Darwin},
 		},
@@ -43,6 +44,7 @@ func hyperkitPreflightChecks(networkMode network.Mode) []Check {
 			check:            checkMachineDriverHyperKitInstalled(networkMode),
 			fixDescription:   "Installing crc-machine-hyperkit",
 			fix:              fixMachineDriverHyperKitInstalled(networkMode),
+			flags:            Driver,
 
 			labels: labels{Os: Darwin},
 		},
@@ -135,7 +137,7 @@ func (filter preflightFilter) SetTray(enable bool) {
 // Passing 'SystemNetworkingMode' to getPreflightChecks currently achieves this
 // as there are no user networking specific checks
 func getAllPreflightChecks() []Check {
-	return getPreflightChecks(true, true, network.SystemNetworkingMode)
+	return getPreflightChecks(true, true, network.SystemNetworkingMode, networkConfig{})
 }
 
 func getChecks(mode network.Mode) []Check {
@@ -148,12 +150,16 @@ func getChecks(mode network.Mode) []Check {
 	checks = append(checks, daemonSetupChecks...)
 	checks = append(checks, resolverPreflightChecks...)
 	checks = append(checks, traySetupChecks...)
+	checks = append(checks, homeDirectoryCheck)
+	checks = append(checks, storageCheck)
+	checks = append(checks, diskLatencyCheck)
 	checks = append(checks, bundleCheck)
+	checks = append(checks, cpuFeaturesCheck)
 
 	return checks
 }
 
-func getPreflightChecks(_ bool, trayAutostart bool, mode network.Mode) []Check {
+func getPreflightChecks(_ bool, trayAutostart bool, mode network.Mode, _ networkConfig) []Check {
 	filter := newFilter()
 	filter.SetNetworkMode(mode)
 	filter.SetTray(trayAutostart)
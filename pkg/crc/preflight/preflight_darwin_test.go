@@ -12,13 +12,13 @@ import (
 func TestCountConfigurationOptions(t *testing.T) {
 	cfg := config.New(config.NewEmptyInMemoryStorage())
 	RegisterSettings(cfg)
-	assert.Len(t, cfg.AllConfigs(), 11)
+	assert.Len(t, cfg.AllConfigs(), 15)
 }
 
 func TestCountPreflights(t *testing.T) {
-	assert.Len(t, getPreflightChecks(true, false, network.SystemNetworkingMode), 17)
-	assert.Len(t, getPreflightChecks(true, true, network.SystemNetworkingMode), 17)
+	assert.Len(t, getPreflightChecks(true, false, network.SystemNetworkingMode, networkConfig{}), 21)
+	assert.Len(t, getPreflightChecks(true, true, network.SystemNetworkingMode, networkConfig{}), 21)
 
-	assert.Len(t, getPreflightChecks(true, false, network.UserNetworkingMode), 16)
-	assert.Len(t, getPreflightChecks(true, true, network.UserNetworkingMode), 16)
+	assert.Len(t, getPreflightChecks(true, false, network.UserNetworkingMode, networkConfig{}), 20)
+	assert.Len(t, getPreflightChecks(true, true, network.UserNetworkingMode, networkConfig{}), 20)
 }
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strings"
 
+	crcConfig "github.com/code-ready/crc/pkg/crc/config"
 	crcErrors "github.com/code-ready/crc/pkg/crc/errors"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/network"
@@ -43,6 +44,7 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 			check:            checkKvmEnabled,
 			fixDescription:   "Setting up KVM",
 			fix:              fixKvmEnabled,
+			flags:            Driver,
 
 			labels: labels{Os: Linux},
 		},
@@ -61,6 +63,7 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 			check:            checkUserPartOfLibvirtGroup,
 			fixDescription:   "Adding user to libvirt group",
 			fix:              fixUserPartOfLibvirtGroup,
+			flags:            Driver,
 
 			labels: labels{Os: Linux},
 		},
@@ -69,7 +72,7 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 			checkDescription: "Checking if active user/process is currently part of the libvirt group",
 			check:            checkCurrentGroups(distro),
 			fixDescription:   "You need to logout, re-login, and run crc setup again before the user is effectively a member of the 'libvirt' group.",
-			flags:            NoFix,
+			flags:            NoFix | Driver,
 
 			labels: labels{Os: Linux},
 		},
@@ -79,6 +82,17 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 			check:            checkLibvirtServiceRunning,
 			fixDescription:   "Starting libvirt service",
 			fix:              fixLibvirtServiceRunning,
+			flags:            Driver,
+
+			labels: labels{Os: Linux},
+		},
+		{
+			configKeySuffix:  "check-libvirt-connection",
+			checkDescription: "Checking if libvirt accepts connections",
+			check:            checkLibvirtConnection,
+			fixDescription:   "Restarting libvirt service",
+			fix:              fixLibvirtConnection,
+			flags:            Driver,
 
 			labels: labels{Os: Linux},
 		},
@@ -97,6 +111,7 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 			check:            checkMachineDriverLibvirtInstalled,
 			fixDescription:   "Installing crc-driver-libvirt",
 			fix:              fixMachineDriverLibvirtInstalled,
+			flags:            Driver,
 
 			labels: labels{Os: Linux},
 		},
@@ -147,27 +162,46 @@ func libvirtPreflightChecks(distro *linux.OsRelease) []Check {
 	return checks
 }
 
-var libvirtNetworkPreflightChecks = []Check{
-	{
-		configKeySuffix:    "check-crc-network",
-		checkDescription:   "Checking if libvirt 'crc' network is available",
-		check:              checkLibvirtCrcNetworkAvailable,
-		fixDescription:     "Setting up libvirt 'crc' network",
-		fix:                fixLibvirtCrcNetworkAvailable,
-		cleanupDescription: "Removing 'crc' network from libvirt",
-		cleanup:            removeLibvirtCrcNetwork,
-
-		labels: labels{Os: Linux, NetworkMode: System},
-	},
-	{
-		configKeySuffix:  "check-crc-network-active",
-		checkDescription: "Checking if libvirt 'crc' network is active",
-		check:            checkLibvirtCrcNetworkActive,
-		fixDescription:   "Starting libvirt 'crc' network",
-		fix:              fixLibvirtCrcNetworkActive,
-
-		labels: labels{Os: Linux, NetworkMode: System},
-	},
+func libvirtNetworkPreflightChecks(netConfig networkConfig) []Check {
+	return []Check{
+		{
+			configKeySuffix:  "check-network-cidr",
+			checkDescription: "Checking if the VM network overlaps the routes of the host",
+			check: func() error {
+				return network.CheckNetworkOverlap(netConfig.subnet)
+			},
+			fixDescription: fmt.Sprintf("Choose a subnet which does not overlap the routes of the host with 'crc config set %s'", crcConfig.NetworkCIDR),
+			flags:          NoFix,
+
+			labels: labels{Os: Linux, NetworkMode: System},
+		},
+		{
+			configKeySuffix:  "check-crc-network",
+			checkDescription: "Checking if libvirt 'crc' network is available",
+			check: func() error {
+				return checkLibvirtCrcNetworkAvailable(netConfig)
+			},
+			fixDescription: "Setting up libvirt 'crc' network",
+			fix: func() error {
+				return fixLibvirtCrcNetworkAvailable(netConfig)
+			},
+			cleanupDescription: "Removing 'crc' network from libvirt",
+			cleanup:            removeLibvirtCrcNetwork,
+			flags:              Driver,
+
+			labels: labels{Os: Linux, NetworkMode: System},
+		},
+		{
+			configKeySuffix:  "check-crc-network-active",
+			checkDescription: "Checking if libvirt 'crc' network is active",
+			check:            checkLibvirtCrcNetworkActive,
+			fixDescription:   "Starting libvirt 'crc' network",
+			fix:              fixLibvirtCrcNetworkActive,
+			flags:            Driver,
+
+			labels: labels{Os: Linux, NetworkMode: System},
+		},
+	}
 }
 
 var vsockPreflightCheck = Check{
@@ -351,26 +385,27 @@ func getAllPreflightChecks() []Check {
 	filter.SetDistro(distro())
 	filter.SetSystemdUser(distro())
 
-	return filter.Apply(getChecks(distro()))
+	subnet, _ := network.ParseNetworkCIDR("")
+	return filter.Apply(getChecks(distro(), networkConfig{subnet: subnet}))
 }
 
-func getPreflightChecks(_ bool, _ bool, networkMode network.Mode) []Check {
+func getPreflightChecks(_ bool, _ bool, networkMode network.Mode, netConfig networkConfig) []Check {
 	usingSystemdResolved := checkSystemdResolvedIsRunning()
 
-	return getPreflightChecksForDistro(distro(), networkMode, usingSystemdResolved == nil)
+	return getPreflightChecksForDistro(distro(), networkMode, usingSystemdResolved == nil, netConfig)
 }
 
-func getPreflightChecksForDistro(distro *linux.OsRelease, networkMode network.Mode, usingSystemdResolved bool) []Check {
+func getPreflightChecksForDistro(distro *linux.OsRelease, networkMode network.Mode, usingSystemdResolved bool, netConfig networkConfig) []Check {
 	filter := newFilter()
 	filter.SetDistro(distro)
 	filter.SetSystemdUser(distro)
 	filter.SetNetworkMode(networkMode)
 	filter.SetSystemdResolved(usingSystemdResolved)
 
-	return filter.Apply(getChecks(distro))
+	return filter.Apply(getChecks(distro, netConfig))
 }
 
-func getChecks(distro *linux.OsRelease) []Check {
+func getChecks(distro *linux.OsRelease, netConfig networkConfig) []Check {
 	var checks []Check
 	checks = append(checks, nonWinPreflightChecks...)
 	checks = append(checks, wsl2PreflightCheck)
@@ -379,11 +414,15 @@ func getChecks(distro *linux.OsRelease) []Check {
 	checks = append(checks, libvirtPreflightChecks(distro)...)
 	checks = append(checks, ubuntuPreflightChecks...)
 	checks = append(checks, nmPreflightChecks...)
-	checks = append(checks, systemdResolvedPreflightChecks...)
-	checks = append(checks, dnsmasqPreflightChecks...)
-	checks = append(checks, libvirtNetworkPreflightChecks...)
+	checks = append(checks, systemdResolvedPreflightChecks(netConfig)...)
+	checks = append(checks, dnsmasqPreflightChecks(netConfig)...)
+	checks = append(checks, libvirtNetworkPreflightChecks(netConfig)...)
 	checks = append(checks, vsockPreflightCheck)
+	checks = append(checks, homeDirectoryCheck)
+	checks = append(checks, storageCheck)
+	checks = append(checks, diskLatencyCheck)
 	checks = append(checks, bundleCheck)
+	checks = append(checks, cpuFeaturesCheck)
 
 	return checks
 }
@@ -1,15 +1,18 @@
 package preflight
 
 import (
+	"net"
 	"reflect"
 	"runtime"
 	"testing"
 
 	"github.com/code-ready/crc/pkg/crc/cluster"
 	"github.com/code-ready/crc/pkg/crc/config"
+	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/network"
 	crcos "github.com/code-ready/crc/pkg/os/linux"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCountConfigurationOptions(t *testing.T) {
@@ -79,6 +82,7 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkUserPartOfLibvirtGroup},
 			{configKeySuffix: "check-libvirt-group-active"},
 			{check: checkLibvirtServiceRunning},
+			{check: checkLibvirtConnection},
 			{check: checkLibvirtVersion},
 			{check: checkMachineDriverLibvirtInstalled},
 			{cleanup: removeLibvirtStoragePool},
@@ -90,10 +94,16 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkNetworkManagerIsRunning},
 			{check: checkCrcDnsmasqAndNetworkManagerConfigFile},
 			{check: checkSystemdResolvedIsRunning},
-			{check: checkCrcNetworkManagerDispatcherFile},
-			{check: checkLibvirtCrcNetworkAvailable},
+			{configKeySuffix: "check-network-manager-dispatcher-file"},
+			{check: checkCrcResolvedPolkitRule},
+			{configKeySuffix: "check-network-cidr"},
+			{configKeySuffix: "check-crc-network"},
 			{check: checkLibvirtCrcNetworkActive},
+			{check: checkHomeDirectory},
+			{check: checkStorageDirectories},
+			{check: checkDiskLatency},
 			{check: checkBundleExtracted},
+			{check: checkCPUFeatures},
 		},
 	},
 	{
@@ -118,6 +128,7 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkUserPartOfLibvirtGroup},
 			{configKeySuffix: "check-libvirt-group-active"},
 			{check: checkLibvirtServiceRunning},
+			{check: checkLibvirtConnection},
 			{check: checkLibvirtVersion},
 			{check: checkMachineDriverLibvirtInstalled},
 			{cleanup: removeLibvirtStoragePool},
@@ -128,10 +139,15 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkNetworkManagerInstalled},
 			{check: checkNetworkManagerIsRunning},
 			{check: checkCrcNetworkManagerConfig},
-			{check: checkCrcDnsmasqConfigFile},
-			{check: checkLibvirtCrcNetworkAvailable},
+			{configKeySuffix: "check-crc-dnsmasq-file"},
+			{configKeySuffix: "check-network-cidr"},
+			{configKeySuffix: "check-crc-network"},
 			{check: checkLibvirtCrcNetworkActive},
+			{check: checkHomeDirectory},
+			{check: checkStorageDirectories},
+			{check: checkDiskLatency},
 			{check: checkBundleExtracted},
+			{check: checkCPUFeatures},
 		},
 	},
 	{
@@ -156,6 +172,7 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkUserPartOfLibvirtGroup},
 			{configKeySuffix: "check-libvirt-group-active"},
 			{check: checkLibvirtServiceRunning},
+			{check: checkLibvirtConnection},
 			{check: checkLibvirtVersion},
 			{check: checkMachineDriverLibvirtInstalled},
 			{cleanup: removeLibvirtStoragePool},
@@ -163,7 +180,11 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkDaemonSystemdService},
 			{check: checkDaemonSystemdSockets},
 			{check: checkVsock},
+			{check: checkHomeDirectory},
+			{check: checkStorageDirectories},
+			{check: checkDiskLatency},
 			{check: checkBundleExtracted},
+			{check: checkCPUFeatures},
 		},
 	},
 	{
@@ -188,6 +209,7 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkUserPartOfLibvirtGroup},
 			{configKeySuffix: "check-libvirt-group-active"},
 			{check: checkLibvirtServiceRunning},
+			{check: checkLibvirtConnection},
 			{check: checkLibvirtVersion},
 			{check: checkMachineDriverLibvirtInstalled},
 			{cleanup: removeLibvirtStoragePool},
@@ -199,10 +221,16 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkNetworkManagerIsRunning},
 			{check: checkCrcDnsmasqAndNetworkManagerConfigFile},
 			{check: checkSystemdResolvedIsRunning},
-			{check: checkCrcNetworkManagerDispatcherFile},
-			{check: checkLibvirtCrcNetworkAvailable},
+			{configKeySuffix: "check-network-manager-dispatcher-file"},
+			{check: checkCrcResolvedPolkitRule},
+			{configKeySuffix: "check-network-cidr"},
+			{configKeySuffix: "check-crc-network"},
 			{check: checkLibvirtCrcNetworkActive},
+			{check: checkHomeDirectory},
+			{check: checkStorageDirectories},
+			{check: checkDiskLatency},
 			{check: checkBundleExtracted},
+			{check: checkCPUFeatures},
 		},
 	},
 	{
@@ -227,6 +255,7 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkUserPartOfLibvirtGroup},
 			{configKeySuffix: "check-libvirt-group-active"},
 			{check: checkLibvirtServiceRunning},
+			{check: checkLibvirtConnection},
 			{check: checkLibvirtVersion},
 			{check: checkMachineDriverLibvirtInstalled},
 			{cleanup: removeLibvirtStoragePool},
@@ -237,10 +266,15 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkNetworkManagerInstalled},
 			{check: checkNetworkManagerIsRunning},
 			{check: checkCrcNetworkManagerConfig},
-			{check: checkCrcDnsmasqConfigFile},
-			{check: checkLibvirtCrcNetworkAvailable},
+			{configKeySuffix: "check-crc-dnsmasq-file"},
+			{configKeySuffix: "check-network-cidr"},
+			{configKeySuffix: "check-crc-network"},
 			{check: checkLibvirtCrcNetworkActive},
+			{check: checkHomeDirectory},
+			{check: checkStorageDirectories},
+			{check: checkDiskLatency},
 			{check: checkBundleExtracted},
+			{check: checkCPUFeatures},
 		},
 	},
 	{
@@ -265,6 +299,7 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkUserPartOfLibvirtGroup},
 			{configKeySuffix: "check-libvirt-group-active"},
 			{check: checkLibvirtServiceRunning},
+			{check: checkLibvirtConnection},
 			{check: checkLibvirtVersion},
 			{check: checkMachineDriverLibvirtInstalled},
 			{cleanup: removeLibvirtStoragePool},
@@ -272,7 +307,11 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkDaemonSystemdService},
 			{check: checkDaemonSystemdSockets},
 			{check: checkVsock},
+			{check: checkHomeDirectory},
+			{check: checkStorageDirectories},
+			{check: checkDiskLatency},
 			{check: checkBundleExtracted},
+			{check: checkCPUFeatures},
 		},
 	},
 	{
@@ -297,6 +336,7 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkUserPartOfLibvirtGroup},
 			{configKeySuffix: "check-libvirt-group-active"},
 			{check: checkLibvirtServiceRunning},
+			{check: checkLibvirtConnection},
 			{check: checkLibvirtVersion},
 			{check: checkMachineDriverLibvirtInstalled},
 			{cleanup: removeLibvirtStoragePool},
@@ -308,10 +348,16 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkNetworkManagerIsRunning},
 			{check: checkCrcDnsmasqAndNetworkManagerConfigFile},
 			{check: checkSystemdResolvedIsRunning},
-			{check: checkCrcNetworkManagerDispatcherFile},
-			{check: checkLibvirtCrcNetworkAvailable},
+			{configKeySuffix: "check-network-manager-dispatcher-file"},
+			{check: checkCrcResolvedPolkitRule},
+			{configKeySuffix: "check-network-cidr"},
+			{configKeySuffix: "check-crc-network"},
 			{check: checkLibvirtCrcNetworkActive},
+			{check: checkHomeDirectory},
+			{check: checkStorageDirectories},
+			{check: checkDiskLatency},
 			{check: checkBundleExtracted},
+			{check: checkCPUFeatures},
 		},
 	},
 	{
@@ -336,6 +382,7 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkUserPartOfLibvirtGroup},
 			{configKeySuffix: "check-libvirt-group-active"},
 			{check: checkLibvirtServiceRunning},
+			{check: checkLibvirtConnection},
 			{check: checkLibvirtVersion},
 			{check: checkMachineDriverLibvirtInstalled},
 			{cleanup: removeLibvirtStoragePool},
@@ -346,10 +393,15 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkNetworkManagerInstalled},
 			{check: checkNetworkManagerIsRunning},
 			{check: checkCrcNetworkManagerConfig},
-			{check: checkCrcDnsmasqConfigFile},
-			{check: checkLibvirtCrcNetworkAvailable},
+			{configKeySuffix: "check-crc-dnsmasq-file"},
+			{configKeySuffix: "check-network-cidr"},
+			{configKeySuffix: "check-crc-network"},
 			{check: checkLibvirtCrcNetworkActive},
+			{check: checkHomeDirectory},
+			{check: checkStorageDirectories},
+			{check: checkDiskLatency},
 			{check: checkBundleExtracted},
+			{check: checkCPUFeatures},
 		},
 	},
 	{
@@ -374,6 +426,7 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkUserPartOfLibvirtGroup},
 			{configKeySuffix: "check-libvirt-group-active"},
 			{check: checkLibvirtServiceRunning},
+			{check: checkLibvirtConnection},
 			{check: checkLibvirtVersion},
 			{check: checkMachineDriverLibvirtInstalled},
 			{cleanup: removeLibvirtStoragePool},
@@ -381,7 +434,11 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkDaemonSystemdService},
 			{check: checkDaemonSystemdSockets},
 			{check: checkVsock},
+			{check: checkHomeDirectory},
+			{check: checkStorageDirectories},
+			{check: checkDiskLatency},
 			{check: checkBundleExtracted},
+			{check: checkCPUFeatures},
 		},
 	},
 	{
@@ -406,6 +463,7 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkUserPartOfLibvirtGroup},
 			{configKeySuffix: "check-libvirt-group-active"},
 			{check: checkLibvirtServiceRunning},
+			{check: checkLibvirtConnection},
 			{check: checkLibvirtVersion},
 			{check: checkMachineDriverLibvirtInstalled},
 			{cleanup: removeLibvirtStoragePool},
@@ -418,10 +476,16 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkNetworkManagerIsRunning},
 			{check: checkCrcDnsmasqAndNetworkManagerConfigFile},
 			{check: checkSystemdResolvedIsRunning},
-			{check: checkCrcNetworkManagerDispatcherFile},
-			{check: checkLibvirtCrcNetworkAvailable},
+			{configKeySuffix: "check-network-manager-dispatcher-file"},
+			{check: checkCrcResolvedPolkitRule},
+			{configKeySuffix: "check-network-cidr"},
+			{configKeySuffix: "check-crc-network"},
 			{check: checkLibvirtCrcNetworkActive},
+			{check: checkHomeDirectory},
+			{check: checkStorageDirectories},
+			{check: checkDiskLatency},
 			{check: checkBundleExtracted},
+			{check: checkCPUFeatures},
 		},
 	},
 	{
@@ -446,6 +510,7 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkUserPartOfLibvirtGroup},
 			{configKeySuffix: "check-libvirt-group-active"},
 			{check: checkLibvirtServiceRunning},
+			{check: checkLibvirtConnection},
 			{check: checkLibvirtVersion},
 			{check: checkMachineDriverLibvirtInstalled},
 			{cleanup: removeLibvirtStoragePool},
@@ -457,10 +522,15 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkNetworkManagerInstalled},
 			{check: checkNetworkManagerIsRunning},
 			{check: checkCrcNetworkManagerConfig},
-			{check: checkCrcDnsmasqConfigFile},
-			{check: checkLibvirtCrcNetworkAvailable},
+			{configKeySuffix: "check-crc-dnsmasq-file"},
+			{configKeySuffix: "check-network-cidr"},
+			{configKeySuffix: "check-crc-network"},
 			{check: checkLibvirtCrcNetworkActive},
+			{check: checkHomeDirectory},
+			{check: checkStorageDirectories},
+			{check: checkDiskLatency},
 			{check: checkBundleExtracted},
+			{check: checkCPUFeatures},
 		},
 	},
 	{
@@ -485,6 +555,7 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkUserPartOfLibvirtGroup},
 			{configKeySuffix: "check-libvirt-group-active"},
 			{check: checkLibvirtServiceRunning},
+			{check: checkLibvirtConnection},
 			{check: checkLibvirtVersion},
 			{check: checkMachineDriverLibvirtInstalled},
 			{cleanup: removeLibvirtStoragePool},
@@ -493,7 +564,11 @@ var checkListForDistros = []checkListForDistro{
 			{check: checkDaemonSystemdSockets},
 			{configKeySuffix: "check-apparmor-profile-setup"},
 			{check: checkVsock},
+			{check: checkHomeDirectory},
+			{check: checkStorageDirectories},
+			{check: checkDiskLatency},
 			{check: checkBundleExtracted},
+			{check: checkCPUFeatures},
 		},
 	},
 }
@@ -507,7 +582,7 @@ func assertFuncEqual(t *testing.T, func1 interface{}, func2 interface{}) {
 }
 
 func assertExpectedPreflights(t *testing.T, distro *crcos.OsRelease, networkMode network.Mode, systemdResolved bool) {
-	preflights := getPreflightChecksForDistro(distro, networkMode, systemdResolved)
+	preflights := getPreflightChecksForDistro(distro, networkMode, systemdResolved, networkConfig{subnet: defaultNetworkCIDR(t)})
 	var expected checkListForDistro
 	for _, expected = range checkListForDistros {
 		if expected.distro == distro && expected.networkMode == networkMode && expected.systemdResolved == systemdResolved {
@@ -551,3 +626,32 @@ func TestCountPreflights(t *testing.T) {
 	assertExpectedPreflights(t, &ubuntu, network.SystemNetworkingMode, false)
 	assertExpectedPreflights(t, &ubuntu, network.UserNetworkingMode, false)
 }
+
+func defaultNetworkCIDR(t *testing.T) *net.IPNet {
+	subnet, err := network.ParseNetworkCIDR("")
+	require.NoError(t, err)
+	return subnet
+}
+
+func TestLibvirtNetworkXMLIPv6(t *testing.T) {
+	netXML, err := getLibvirtNetworkXML(networkConfig{subnet: defaultNetworkCIDR(t)})
+	require.NoError(t, err)
+	assert.NotContains(t, netXML, "ipv6")
+
+	ipv6Subnet, err := network.ParseNetworkIPv6CIDR("fd00:130::/64")
+	require.NoError(t, err)
+	netXML, err = getLibvirtNetworkXML(networkConfig{subnet: defaultNetworkCIDR(t), ipv6Subnet: ipv6Subnet})
+	require.NoError(t, err)
+	assert.Contains(t, netXML, "<nat ipv6='yes'>")
+	assert.Contains(t, trimSpacesFromXML(netXML), "<ip family='ipv6' address='fd00:130::1' prefix='64'><dhcp><range start='fd00:130::b' end='fd00:130::b'/></dhcp></ip></network>")
+}
+
+func TestDaemonUnitStorageDir(t *testing.T) {
+	assert.NotContains(t, daemonUnitContent(), constants.StorageDirEnv)
+
+	defer func(dir string) {
+		constants.MachineBaseDir = dir
+	}(constants.MachineBaseDir)
+	constants.MachineBaseDir = "/var/lib/crc-storage"
+	assert.Contains(t, daemonUnitContent(), `Environment="CRC_STORAGE_DIR=/var/lib/crc-storage"`)
+}
@@ -40,7 +40,7 @@ var hypervPreflightChecks = []Check{
 		configKeySuffix:  "check-hyperv-installed",
 		checkDescription: "Checking if Hyper-V is installed and operational",
 		check:            checkHyperVInstalled,
-		flags:            StartUpOnly,
+		flags:            StartUpOnly | Driver,
 
 		labels: labels{Os: Windows},
 	},
@@ -63,6 +63,7 @@ var hypervPreflightChecks = []Check{
 		check:            checkIfUserPartOfHyperVAdmins,
 		fixDescription:   "Adding current user to Hyper-V Admins group",
 		fix:              fixUserPartOfHyperVAdmins,
+		flags:            Driver,
 
 		labels: labels{Os: Windows},
 	},
@@ -70,7 +71,9 @@ var hypervPreflightChecks = []Check{
 		configKeySuffix:  "check-hyperv-service-running",
 		checkDescription: "Checking if Hyper-V service is enabled",
 		check:            checkHyperVServiceRunning,
-		flags:            StartUpOnly,
+		fixDescription:   "Starting Hyper-V Virtual Machine Management service",
+		fix:              fixHyperVServiceRunning,
+		flags:            StartUpOnly | Driver,
 
 		labels: labels{Os: Windows},
 	},
@@ -78,7 +81,7 @@ var hypervPreflightChecks = []Check{
 		configKeySuffix:  "check-hyperv-switch",
 		checkDescription: "Checking if the Hyper-V virtual switch exists",
 		check:            checkIfHyperVVirtualSwitchExists,
-		flags:            StartUpOnly,
+		flags:            StartUpOnly | Driver,
 
 		labels: labels{Os: Windows},
 	},
@@ -145,19 +148,23 @@ func checkVsock() error {
 // Passing 'UserNetworkingMode' to getPreflightChecks currently achieves this
 // as there are no system networking specific checks
 func getAllPreflightChecks() []Check {
-	return getPreflightChecks(true, true, network.UserNetworkingMode)
+	return getPreflightChecks(true, true, network.UserNetworkingMode, networkConfig{})
 }
 
 func getChecks() []Check {
 	checks := []Check{}
 	checks = append(checks, hypervPreflightChecks...)
 	checks = append(checks, vsockChecks...)
+	checks = append(checks, homeDirectoryCheck)
+	checks = append(checks, storageCheck)
+	checks = append(checks, diskLatencyCheck)
 	checks = append(checks, bundleCheck)
+	checks = append(checks, cpuFeaturesCheck)
 	checks = append(checks, genericCleanupChecks...)
 	return checks
 }
 
-func getPreflightChecks(_ bool, trayAutoStart bool, networkMode network.Mode) []Check {
+func getPreflightChecks(_ bool, trayAutoStart bool, networkMode network.Mode, _ networkConfig) []Check {
 	filter := newFilter()
 	filter.SetNetworkMode(networkMode)
 
@@ -11,13 +11,13 @@ import (
 func TestCountConfigurationOptions(t *testing.T) {
 	cfg := config.New(config.NewEmptyInMemoryStorage())
 	RegisterSettings(cfg)
-	assert.Len(t, cfg.AllConfigs(), 10)
+	assert.Len(t, cfg.AllConfigs(), 14)
 }
 
 func TestCountPreflights(t *testing.T) {
-	assert.Len(t, getPreflightChecks(false, false, network.SystemNetworkingMode), 15)
-	assert.Len(t, getPreflightChecks(true, true, network.SystemNetworkingMode), 15)
+	assert.Len(t, getPreflightChecks(false, false, network.SystemNetworkingMode, networkConfig{}), 19)
+	assert.Len(t, getPreflightChecks(true, true, network.SystemNetworkingMode, networkConfig{}), 19)
 
-	assert.Len(t, getPreflightChecks(false, false, network.UserNetworkingMode), 16)
-	assert.Len(t, getPreflightChecks(true, true, network.UserNetworkingMode), 16)
+	assert.Len(t, getPreflightChecks(false, false, network.UserNetworkingMode, networkConfig{}), 20)
+	assert.Len(t, getPreflightChecks(true, true, network.UserNetworkingMode, networkConfig{}), 20)
 }
@@ -88,7 +88,13 @@ func (c *Client) UploadAction(action, source, status string) error {
 }
 
 func (c *Client) UploadCmd(ctx context.Context, action string, duration time.Duration, err error) error {
-	return c.upload(action, properties(ctx, err, duration))
+	return c.UploadOperation(ctx, action, "cli", duration, err)
+}
+
+// UploadOperation sends the outcome of an operation run by source, such as
+// the daemon on behalf of the tray, with the properties recorded in ctx
+func (c *Client) UploadOperation(ctx context.Context, action, source string, duration time.Duration, err error) error {
+	return c.upload(action, properties(ctx, source, err, duration))
 }
 
 func identifyHash(identify *analytics.Identify) (uint64, error) {
@@ -189,8 +195,8 @@ func baseProperties(source string) analytics.Properties {
 		Set("source", source)
 }
 
-func properties(ctx context.Context, err error, duration time.Duration) analytics.Properties {
-	properties := baseProperties("cli")
+func properties(ctx context.Context, source string, err error, duration time.Duration) analytics.Properties {
+	properties := baseProperties(source)
 	for k, v := range telemetry.GetContextProperties(ctx) {
 		properties = properties.Set(k, v)
 	}
@@ -201,8 +207,8 @@ func properties(ctx context.Context, err error, duration time.Duration) analytic
 		Set("tty", crcos.RunningInTerminal()).
 		Set("remote", crcos.RunningUsingSSH())
 	if err != nil {
-		properties = properties.Set("error", telemetry.SetError(err)).
-			Set("error-type", errorType(err))
+		properties = properties.Set("error-type", errorType(err)).
+			Set("error-category", telemetry.GetErrorCategory(err))
 	}
 	return properties
 }
@@ -30,11 +30,15 @@ type segmentResponse struct {
 			ExperimentalFeatures bool   `json:"enable-experimental-features"`
 		} `json:"traits"`
 		Properties struct {
-			Error     string `json:"error"`
-			ErrorType string `json:"error-type"`
-			Version   string `json:"version"`
-			CPUs      int    `json:"cpus"`
-			Remote    bool   `json:"remote"`
+			Error         string           `json:"error"`
+			ErrorType     string           `json:"error-type"`
+			ErrorCategory string           `json:"error-category"`
+			Version       string           `json:"version"`
+			Source        string           `json:"source"`
+			CPUs          int              `json:"cpus"`
+			Remote        bool             `json:"remote"`
+			FailedStep    string           `json:"failed-step"`
+			StepDurations map[string]int64 `json:"step-durations"`
 		} `json:"properties"`
 		Type string `json:"type"`
 	} `json:"batch"`
@@ -107,7 +111,7 @@ func TestClientUploadWithConsentAndWithSerializableError(t *testing.T) {
 		require.Equal(t, s.Batch[0].Traits.ExperimentalFeatures, true)
 		require.Equal(t, s.Batch[1].Type, "track")
 		require.Equal(t, s.Batch[1].UserID, string(uuid))
-		require.Equal(t, s.Batch[1].Properties.Error, crcErr.VMNotExist.Error())
+		require.Empty(t, s.Batch[1].Properties.Error)
 		require.Equal(t, s.Batch[1].Properties.ErrorType, "errors.vmNotExist")
 		require.Equal(t, s.Batch[1].Properties.Version, version.GetCRCVersion())
 		require.Equal(t, s.Batch[1].Properties.Remote, true)
@@ -142,8 +146,9 @@ func TestClientUploadWithConsentAndWithoutSerializableError(t *testing.T) {
 		require.Equal(t, s.Batch[0].Traits.OS, runtime.GOOS)
 		require.Equal(t, s.Batch[0].Traits.ExperimentalFeatures, true)
 		require.Equal(t, s.Batch[1].Type, "track")
-		require.Equal(t, s.Batch[1].Properties.Error, "an error occurred")
+		require.Empty(t, s.Batch[1].Properties.Error)
 		require.Equal(t, s.Batch[1].Properties.ErrorType, "*errors.errorString")
+		require.Equal(t, s.Batch[1].Properties.ErrorCategory, "other")
 		require.Equal(t, s.Batch[1].Properties.Version, version.GetCRCVersion())
 		require.Equal(t, s.Batch[1].Properties.Remote, false)
 	default:
@@ -168,7 +173,9 @@ func TestClientUploadWithContext(t *testing.T) {
 
 	ctx := telemetry.NewContext(context.Background())
 	telemetry.SetCPUs(ctx, 6)
-	require.NoError(t, c.UploadCmd(ctx, "start", time.Minute, nil))
+	telemetry.SetStepResult(ctx, "start-vm", 12*time.Second, nil)
+	telemetry.SetStepResult(ctx, "wait-for-api-server", 3*time.Second, context.DeadlineExceeded)
+	require.NoError(t, c.UploadOperation(ctx, "start", "daemon", time.Minute, context.DeadlineExceeded))
 	require.NoError(t, c.Close())
 
 	select {
@@ -176,6 +183,10 @@ func TestClientUploadWithContext(t *testing.T) {
 		s := segmentResponse{}
 		require.NoError(t, json.Unmarshal(x, &s))
 		require.Equal(t, s.Batch[1].Properties.CPUs, 6)
+		require.Equal(t, s.Batch[1].Properties.Source, "daemon")
+		require.Equal(t, s.Batch[1].Properties.ErrorCategory, "timeout")
+		require.Equal(t, s.Batch[1].Properties.FailedStep, "wait-for-api-server")
+		require.Equal(t, s.Batch[1].Properties.StepDurations, map[string]int64{"start-vm": 12000, "wait-for-api-server": 3000})
 	default:
 		require.Fail(t, "server should receive data")
 	}
@@ -3,15 +3,19 @@ package dns
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/code-ready/crc/pkg/crc/adminhelper"
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/errors"
+	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/services"
 	"github.com/code-ready/crc/pkg/crc/systemd"
 	"github.com/code-ready/crc/pkg/crc/systemd/states"
+	crcos "github.com/code-ready/crc/pkg/os"
 )
 
 const (
@@ -28,10 +32,26 @@ func RunPostStart(serviceConfig services.ServicePostStartConfig) error {
 	if err := setupDnsmasq(serviceConfig); err != nil {
 		return err
 	}
+	if serviceConfig.HostDNS {
+		if err := writeHostRecords(serviceConfig); err != nil {
+			return fmt.Errorf("Cannot write the DNS records of the host: %w", err)
+		}
+	}
 
 	if err := runPostStartForOS(serviceConfig); err != nil {
 		return err
 	}
+	// stale negative entries would otherwise keep the cluster hostnames
+	// unresolvable on the host for minutes
+	changed, err := hostResolutionChanged(hostResolutionPath(serviceConfig.Name), serviceConfig)
+	if err != nil {
+		logging.Debugf("Cannot record the host resolution of the cluster: %v", err)
+	}
+	if changed || err != nil {
+		if err := flushHostDNSCache(crcos.RunWithDefaultLocale); err != nil {
+			logging.Warn(err)
+		}
+	}
 
 	resolvFileValues, err := getResolvFileValues(serviceConfig)
 	if err != nil {
@@ -41,12 +61,48 @@ func RunPostStart(serviceConfig services.ServicePostStartConfig) error {
 	return network.CreateResolvFileOnInstance(serviceConfig.SSHRunner, resolvFileValues)
 }
 
+// RunPostDelete removes the host DNS configuration of the deleted instance
+func RunPostDelete(name string) error {
+	if err := RemoveHostRecords(name); err != nil {
+		return err
+	}
+	if err := os.Remove(hostResolutionPath(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return runPostDeleteForOS(name)
+}
+
+// commandRunner runs a command on the host, like crcos.RunWithDefaultLocale
+type commandRunner func(command string, args ...string) (string, string, error)
+
+// hostResolutionPath records how the host resolves the cluster hostnames of
+// the instance name since its last start
+func hostResolutionPath(name string) string {
+	return filepath.Join(constants.HostDNSRecordsDir, fmt.Sprintf("%s.resolution", name))
+}
+
+// hostResolutionChanged records the address and the domains the host
+// resolves for the cluster, and reports whether they changed since the last
+// start. The host DNS cache only needs to be flushed then.
+func hostResolutionChanged(path string, serviceConfig services.ServicePostStartConfig) (bool, error) {
+	resolution := fmt.Sprintf("%s %s %s %s %s %s %t\n", serviceConfig.IP, serviceConfig.IPv6, serviceConfig.NetworkMode,
+		serviceConfig.Domains.BaseDomain, serviceConfig.Domains.AppsDomain, serviceConfig.Domains.ProfileDomain, serviceConfig.HostDNS)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return false, err
+	}
+	return crcos.WriteFileIfContentChanged(path, []byte(resolution), 0600)
+}
+
 func setupDnsmasq(serviceConfig services.ServicePostStartConfig) error {
 	if serviceConfig.NetworkMode == network.UserNetworkingMode {
+		if len(serviceConfig.ForwardZones) != 0 {
+			logging.Warnf("DNS zone forwarding is not supported with the %s network mode, ignoring it", network.UserNetworkingMode)
+		}
 		return nil
 	}
 
-	if err := createDnsmasqDNSConfig(serviceConfig); err != nil {
+	configChanged, err := createDnsmasqDNSConfig(serviceConfig)
+	if err != nil {
 		return err
 	}
 	sd := systemd.NewInstanceSystemdCommander(serviceConfig.SSHRunner)
@@ -54,8 +110,26 @@ func setupDnsmasq(serviceConfig services.ServicePostStartConfig) error {
 		if err := sd.Enable(crcDnsmasqService); err != nil {
 			return err
 		}
+		return sd.Start(crcDnsmasqService)
+	}
+	if configChanged {
+		logging.Debugf("dnsmasq configuration changed, restarting %s", crcDnsmasqService)
+		return sd.Restart(crcDnsmasqService)
+	}
+	return nil
+}
+
+// RefreshDnsmasq renders the dnsmasq configuration of the running VM again
+// and restarts dnsmasq, so that it uses the current forward zones and the
+// current upstream servers of the VM
+func RefreshDnsmasq(serviceConfig services.ServicePostStartConfig) error {
+	if serviceConfig.NetworkMode == network.UserNetworkingMode {
+		return nil
 	}
-	return sd.Start(crcDnsmasqService)
+	if _, err := createDnsmasqDNSConfig(serviceConfig); err != nil {
+		return err
+	}
+	return systemd.NewInstanceSystemdCommander(serviceConfig.SSHRunner).Restart(crcDnsmasqService)
 }
 
 func getResolvFileValues(serviceConfig services.ServicePostStartConfig) (network.ResolvFileValues, error) {
@@ -66,7 +140,7 @@ func getResolvFileValues(serviceConfig services.ServicePostStartConfig) (network
 	return network.ResolvFileValues{
 		SearchDomains: []network.SearchDomain{
 			{
-				Domain: fmt.Sprintf("%s.%s", serviceConfig.Name, serviceConfig.BundleMetadata.ClusterInfo.BaseDomain),
+				Domain: fmt.Sprintf("%s.%s", serviceConfig.Name, serviceConfig.Domains.BaseDomain),
 			},
 		},
 		NameServers: dnsServers,
@@ -89,7 +163,7 @@ func dnsServers(serviceConfig services.ServicePostStartConfig) ([]network.NameSe
 }
 
 func CheckCRCLocalDNSReachable(ctx context.Context, serviceConfig services.ServicePostStartConfig) (string, error) {
-	appsURI := fmt.Sprintf("foo.%s", serviceConfig.BundleMetadata.ClusterInfo.AppsDomain)
+	appsURI := fmt.Sprintf("foo.%s", serviceConfig.Domains.AppsDomain)
 	// Try 30 times for 1 second interval, In nested environment most of time crc failed to get
 	// Internal dns query resolved for some time.
 	var queryOutput string
@@ -134,10 +208,16 @@ func CheckCRCPublicDNSReachable(serviceConfig services.ServicePostStartConfig) (
 }
 
 func addOpenShiftHosts(serviceConfig services.ServicePostStartConfig) error {
-	return adminhelper.UpdateHostsFile(serviceConfig.IP, serviceConfig.BundleMetadata.GetAPIHostname(),
-		serviceConfig.BundleMetadata.GetAppHostname("oauth-openshift"),
-		serviceConfig.BundleMetadata.GetAppHostname("console-openshift-console"),
-		serviceConfig.BundleMetadata.GetAppHostname("downloads-openshift-console"),
-		serviceConfig.BundleMetadata.GetAppHostname("canary-openshift-ingress-canary"),
-		serviceConfig.BundleMetadata.GetAppHostname("default-route-openshift-image-registry"))
+	hostnames := []string{serviceConfig.Domains.APIHostname(),
+		serviceConfig.Domains.AppHostname("oauth-openshift"),
+		serviceConfig.Domains.AppHostname("console-openshift-console"),
+		serviceConfig.Domains.AppHostname("downloads-openshift-console"),
+		serviceConfig.Domains.AppHostname("canary-openshift-ingress-canary"),
+		serviceConfig.Domains.AppHostname("default-route-openshift-image-registry")}
+	if profileAPIHostname := serviceConfig.Domains.ProfileAPIHostname(); profileAPIHostname != "" {
+		hostnames = append(hostnames, profileAPIHostname,
+			serviceConfig.Domains.ProfileAppHostname("console-openshift-console"),
+			serviceConfig.Domains.ProfileAppHostname("downloads-openshift-console"))
+	}
+	return adminhelper.UpdateHostsFile(serviceConfig.IP, hostnames...)
 }
@@ -9,6 +9,7 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/code-ready/crc/pkg/crc/constants"
 	crcerrors "github.com/code-ready/crc/pkg/crc/errors"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/network"
@@ -42,8 +43,12 @@ func runPostStartForOS(serviceConfig services.ServicePostStartConfig) error {
 	}
 
 	// Write resolver config to host
-	needRestart, err := createResolverFile(serviceConfig.IP, serviceConfig.BundleMetadata.ClusterInfo.BaseDomain,
-		serviceConfig.BundleMetadata.ClusterInfo.BaseDomain)
+	nameServer, port := serviceConfig.IP, dnsServicePort
+	if serviceConfig.HostDNS {
+		nameServer, port = "127.0.0.1", constants.HostDNSPort
+	}
+	needRestart, err := createResolverFile(nameServer, port, serviceConfig.Domains.BaseDomain,
+		serviceConfig.Domains.BaseDomain)
 	if err != nil {
 		return err
 	}
@@ -66,13 +71,13 @@ func runPostStartForOS(serviceConfig services.ServicePostStartConfig) error {
 	return nil
 }
 
-func createResolverFile(instanceIP string, domain string, filename string) (bool, error) {
+func createResolverFile(nameServer string, port int, domain string, filename string) (bool, error) {
 	var resolverFile bytes.Buffer
 
 	values := resolverFileValues{
-		Port:        dnsServicePort,
+		Port:        port,
 		Domain:      domain,
-		IP:          instanceIP,
+		IP:          nameServer,
 		SearchOrder: 1,
 	}
 
@@ -89,6 +94,20 @@ func createResolverFile(instanceIP string, domain string, filename string) (bool
 	return crcos.WriteFileIfContentChanged(path, resolverFile.Bytes(), 0644)
 }
 
+// flushHostDNSCache flushes the Directory Service cache and the cache of
+// mDNSResponder. The latter needs root, it fails when sudo would prompt for
+// a password and the user is told how to flush it.
+func flushHostDNSCache(run commandRunner) error {
+	if _, stderr, err := run("dscacheutil", "-flushcache"); err != nil {
+		logging.Debugf("Failed to flush the host DNS cache: %v: %s", err, stderr)
+	}
+	if _, stderr, err := run("sudo", "-n", "killall", "-HUP", "mDNSResponder"); err != nil {
+		logging.Debugf("Failed to flush the mDNSResponder cache: %v: %s", err, stderr)
+		return errors.New("Cannot flush the DNS cache of the host without a password, run 'sudo killall -HUP mDNSResponder' if the cluster hostnames do not resolve")
+	}
+	return nil
+}
+
 // restartNetwork is required to update the resolver file on OSx.
 func restartNetwork() error {
 	// https://medium.com/@kumar_pravin/network-restart-on-mac-os-using-shell-script-ab19ba6e6e99
@@ -144,3 +163,15 @@ func waitForNetwork() error {
 
 	return nil
 }
+
+// runPostDeleteForOS does nothing, the host configuration does not depend on
+// the instance
+func runPostDeleteForOS(_ string) error {
+	return nil
+}
+
+// RemoveHostRouting does nothing, the cluster domains are routed by a
+// configuration shared by all the instances
+func RemoveHostRouting(_ string, _ string) error {
+	return nil
+}
@@ -0,0 +1,28 @@
+package dns
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlushHostDNSCache(t *testing.T) {
+	var commands []string
+	run := func(command string, args ...string) (string, string, error) {
+		commands = append(commands, strings.Join(append([]string{command}, args...), " "))
+		return "", "", nil
+	}
+	assert.NoError(t, flushHostDNSCache(run))
+	assert.Equal(t, []string{"dscacheutil -flushcache", "sudo -n killall -HUP mDNSResponder"}, commands)
+
+	// sudo needs a password
+	failing := func(command string, args ...string) (string, string, error) {
+		if command == "sudo" {
+			return "", "sudo: a password is required", errors.New("exit status 1")
+		}
+		return "", "", nil
+	}
+	assert.EqualError(t, flushHostDNSCache(failing), "Cannot flush the DNS cache of the host without a password, run 'sudo killall -HUP mDNSResponder' if the cluster hostnames do not resolve")
+}
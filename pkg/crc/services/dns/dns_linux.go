@@ -1,11 +1,126 @@
 package dns
 
 import (
+	"fmt"
+	"net"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/network/resolved"
 	"github.com/code-ready/crc/pkg/crc/services"
+	"github.com/miekg/dns"
 )
 
+// crcLink is the bridge of the 'crc' libvirt network
+const crcLink = "crc"
+
 func runPostStartForOS(serviceConfig services.ServicePostStartConfig) error {
+	if err := routeClusterDomains(serviceConfig); err != nil {
+		logging.Warnf("Cannot route the cluster domains with systemd-resolved: %v", err)
+	}
 	// We might need to set the firewall here to forward
 	// Update /etc/hosts file for host
 	return addOpenShiftHosts(serviceConfig)
 }
+
+// daemonDNSServer is the DNS server of 'crc daemon' with the host DNS service
+func daemonDNSServer() resolved.Server {
+	return resolved.Server{IP: net.IPv4(127, 0, 0, 1), Port: constants.HostDNSPort}
+}
+
+// hostRoutingServer is the DNS server of the cluster domains on the host, the
+// VM or 'crc daemon' with the host DNS service. systemd-resolved releases
+// which cannot use the port of 'crc daemon' use the VM.
+func hostRoutingServer(serviceConfig services.ServicePostStartConfig, supportsPorts bool) resolved.Server {
+	if serviceConfig.HostDNS {
+		if supportsPorts {
+			return daemonDNSServer()
+		}
+		logging.Warnf("systemd-resolved older than release 246 cannot use the host DNS service, the cluster domains are resolved by the VM")
+	}
+	return resolved.Server{IP: net.ParseIP(serviceConfig.IP)}
+}
+
+// routingDomains are the base domain of the cluster, and the apps and
+// instance domains when they are not in the base domain
+func routingDomains(domains services.ClusterDomains) []string {
+	routing := []string{domains.BaseDomain}
+	for _, domain := range []string{domains.AppsDomain, domains.ProfileDomain} {
+		if domain != "" && !inDomain(dns.Fqdn(domain), domains.BaseDomain) {
+			routing = append(routing, domain)
+		}
+	}
+	return routing
+}
+
+// routeClusterDomains sends the queries of the cluster domains to the DNS
+// server of the cluster through the 'crc' bridge, when systemd-resolved is
+// running. Without it, the dnsmasq plugin of NetworkManager is configured by
+// 'crc setup'.
+func routeClusterDomains(serviceConfig services.ServicePostStartConfig) error {
+	if serviceConfig.NetworkMode == network.UserNetworkingMode || !resolved.Available() {
+		return nil
+	}
+	return resolved.SetLinkRouting(crcLink, []resolved.Server{hostRoutingServer(serviceConfig, resolved.SupportsPorts())}, routingDomains(serviceConfig.Domains))
+}
+
+// hostRecordsExist returns true when an instance still has DNS records for
+// 'crc daemon'
+func hostRecordsExist(dir string) bool {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	return err == nil && len(files) != 0
+}
+
+// runPostDeleteForOS does nothing, the host configuration does not depend on
+// the instance
+func runPostDeleteForOS(_ string) error {
+	return nil
+}
+
+// RemoveHostRouting stops routing the cluster domains through the 'crc'
+// bridge when it is done for the instance with this IP, or for 'crc daemon'
+// when it has no DNS records left. The other instances keep their routing.
+func RemoveHostRouting(name string, ip string) error {
+	if !resolved.Available() {
+		return nil
+	}
+	servers, err := resolved.LinkServers(crcLink)
+	if err != nil {
+		return err
+	}
+	for _, server := range servers {
+		instanceServer := ip != "" && server.Equal(resolved.Server{IP: net.ParseIP(ip)})
+		unusedDaemonServer := server.Equal(daemonDNSServer()) && !hostRecordsExist(constants.HostDNSRecordsDir)
+		if !instanceServer && !unusedDaemonServer {
+			continue
+		}
+		logging.Debugf("Removing the DNS routing of the %s instance from %s", name, crcLink)
+		if err := resolved.ClearLinkRouting(crcLink); err != nil {
+			return fmt.Errorf("Cannot remove the DNS routing of the cluster domains: %w", err)
+		}
+		return nil
+	}
+	return nil
+}
+
+// flushHostDNSCache flushes the cache of systemd-resolved when it is installed
+func flushHostDNSCache(run commandRunner) error {
+	// systemd releases older than 239 only ship systemd-resolve
+	for _, command := range []string{"resolvectl", "systemd-resolve"} {
+		if _, err := exec.LookPath(command); err != nil {
+			continue
+		}
+		args := []string{"flush-caches"}
+		if command == "systemd-resolve" {
+			args = []string{"--flush-caches"}
+		}
+		if _, stderr, err := run(command, args...); err != nil {
+			return fmt.Errorf("Failed to flush the host DNS cache: %v: %s", err, stderr)
+		}
+		return nil
+	}
+	return nil
+}
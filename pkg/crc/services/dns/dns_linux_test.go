@@ -0,0 +1,32 @@
+package dns
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoutingDomains(t *testing.T) {
+	assert.Equal(t, []string{"testing"}, routingDomains(services.ClusterDomains{
+		ClusterName:   "crc",
+		BaseDomain:    "testing",
+		AppsDomain:    "apps-crc.testing",
+		ProfileDomain: "test411.crc.testing",
+	}))
+	assert.Equal(t, []string{"testing", "apps.example.com"}, routingDomains(services.ClusterDomains{
+		ClusterName: "crc",
+		BaseDomain:  "testing",
+		AppsDomain:  "apps.example.com",
+	}))
+}
+
+func TestHostRoutingServer(t *testing.T) {
+	serviceConfig := services.ServicePostStartConfig{IP: "192.168.130.11", HostDNS: true}
+	assert.Equal(t, "127.0.0.1:10053", hostRoutingServer(serviceConfig, true).String())
+	// systemd-resolved older than 246
+	assert.Equal(t, "192.168.130.11", hostRoutingServer(serviceConfig, false).String())
+
+	serviceConfig.HostDNS = false
+	assert.Equal(t, "192.168.130.11", hostRoutingServer(serviceConfig, true).String())
+}
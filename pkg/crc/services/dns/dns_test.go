@@ -0,0 +1,27 @@
+package dns
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostResolutionChanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dns", "crc.resolution")
+	serviceConfig := testServiceConfig()
+
+	changed, err := hostResolutionChanged(path, serviceConfig)
+	require.NoError(t, err)
+	assert.True(t, changed)
+
+	changed, err = hostResolutionChanged(path, serviceConfig)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	serviceConfig.IP = "192.168.130.12"
+	changed, err = hostResolutionChanged(path, serviceConfig)
+	require.NoError(t, err)
+	assert.True(t, changed)
+}
@@ -1,72 +1,105 @@
 package dns
 
 import (
-	"bufio"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/services"
-	winnet "github.com/code-ready/crc/pkg/os/windows/network"
 	"github.com/code-ready/crc/pkg/os/windows/powershell"
-	"github.com/code-ready/crc/pkg/os/windows/win32"
 )
 
-const (
-	// Alternative
-	AlternativeNetwork = "crc"
-)
+// nrptRuleComment marks the Name Resolution Policy Table rule of crc, it
+// sends the queries of the cluster domains to the VM
+const nrptRuleComment = "crc"
 
 func runPostStartForOS(serviceConfig services.ServicePostStartConfig) error {
 	if serviceConfig.NetworkMode == network.UserNetworkingMode {
 		return addOpenShiftHosts(serviceConfig)
 	}
+	return addNRPTRule(nrptNamespaces(serviceConfig.Domains), serviceConfig.IP)
+}
 
-	_, switchName := winnet.SelectSwitchByNameOrDefault(AlternativeNetwork)
-	networkInterface := fmt.Sprintf("vEthernet (%s)", switchName)
-
-	setInterfaceNameserverValue(networkInterface, serviceConfig.IP)
-
-	time.Sleep(2 * time.Second)
-
-	if !contains(getInterfaceNameserverValues(networkInterface), serviceConfig.IP) {
-		return fmt.Errorf("Nameserver %s not successfully set on interface %s. Perhaps you can try this new network mode: https://github.com/code-ready/crc/wiki/VPN-support--with-an--userland-network-stack", serviceConfig.IP, networkInterface)
+// runPostDeleteForOS removes the NRPT rule, the instances share it and the
+// next start of another instance adds it again
+func runPostDeleteForOS(_ string) error {
+	current, err := currentNRPTRule()
+	if err != nil || current == "" {
+		return err
+	}
+	if _, stderr, err := powershell.ExecuteAsAdmin("removing the DNS rule of the cluster domains", removeNRPTRuleCommand()); err != nil {
+		return fmt.Errorf("Failed to remove the NRPT rule %v: %s", err, stderr)
 	}
 	return nil
 }
 
-func getInterfaceNameserverValues(iface string) []string {
-	getDNSServerCommand := fmt.Sprintf(`(Get-DnsClientServerAddress "%s")[0].ServerAddresses`, iface)
-	stdOut, _, _ := powershell.Execute(getDNSServerCommand)
+// nrptNamespaces are the cluster domains and their subdomains, the instance
+// domain is in the cluster domain
+func nrptNamespaces(domains services.ClusterDomains) []string {
+	clusterDomain := fmt.Sprintf("%s.%s", domains.ClusterName, domains.BaseDomain)
+	namespaces := []string{"." + clusterDomain, "." + domains.AppsDomain}
+	if domains.ProfileDomain != "" && !strings.HasSuffix(domains.ProfileDomain, "."+clusterDomain) {
+		namespaces = append(namespaces, "."+domains.ProfileDomain)
+	}
+	return namespaces
+}
 
-	return parseLines(stdOut)
+// nrptRule is how currentNRPTRule prints a rule
+func nrptRule(namespaces []string, nameServer string) string {
+	return fmt.Sprintf("%s=%s", strings.Join(namespaces, ","), nameServer)
 }
 
-func contains(s []string, e string) bool {
-	for _, v := range s {
-		if v == e {
-			return true
-		}
+func currentNRPTRule() (string, error) {
+	stdout, stderr, err := powershell.Execute(fmt.Sprintf(`Get-DnsClientNrptRule | Where-Object { $_.Comment -eq '%s' } | ForEach-Object { "$($_.Namespace -join ',')=$($_.NameServers -join ',')" }`, nrptRuleComment))
+	if err != nil {
+		return "", fmt.Errorf("Failed to get the NRPT rules %v: %s", err, stderr)
 	}
-	return false
+	return strings.TrimSpace(stdout), nil
 }
 
-func setInterfaceNameserverValue(iface string, address string) {
-	exe := "netsh"
-	args := fmt.Sprintf(`interface ip set dns "%s" static %s primary`, iface, address)
+func removeNRPTRuleCommand() string {
+	return fmt.Sprintf(`Get-DnsClientNrptRule | Where-Object { $_.Comment -eq '%s' } | Remove-DnsClientNrptRule -Force`, nrptRuleComment)
+}
 
-	// ignore the error as this is useless (prefer not to use nolint here)
-	_ = win32.ShellExecuteAsAdmin(fmt.Sprintf("add dns server address to interface %s", iface), win32.HwndDesktop, exe, args, "", 0)
+func addNRPTRuleCommand(namespaces []string, nameServer string) string {
+	return fmt.Sprintf(`%s; Add-DnsClientNrptRule -Namespace '%s' -NameServers '%s' -Comment '%s'`,
+		removeNRPTRuleCommand(), strings.Join(namespaces, "','"), nameServer, nrptRuleComment)
 }
 
-func parseLines(input string) []string {
-	output := []string{}
+// addNRPTRule sends the queries of the namespaces to the DNS server of the
+// VM, the rule is only replaced when it changed since administrator rights
+// are needed
+func addNRPTRule(namespaces []string, nameServer string) error {
+	current, err := currentNRPTRule()
+	if err != nil {
+		return err
+	}
+	if current == nrptRule(namespaces, nameServer) {
+		return nil
+	}
+	if _, stderr, err := powershell.ExecuteAsAdmin("adding a DNS rule for the cluster domains", addNRPTRuleCommand(namespaces, nameServer)); err != nil {
+		return fmt.Errorf("Failed to add the NRPT rule %v: %s", err, stderr)
+	}
+	current, err = currentNRPTRule()
+	if err != nil {
+		return err
+	}
+	if current != nrptRule(namespaces, nameServer) {
+		return fmt.Errorf("The NRPT rule sending the cluster domains to %s was not added. Perhaps you can try this new network mode: https://github.com/code-ready/crc/wiki/VPN-support--with-an--userland-network-stack", nameServer)
+	}
+	return nil
+}
 
-	s := bufio.NewScanner(strings.NewReader(input))
-	for s.Scan() {
-		output = append(output, s.Text())
+// flushHostDNSCache empties the cache of the DNS client service
+func flushHostDNSCache(run commandRunner) error {
+	if _, stderr, err := run("ipconfig", "/flushdns"); err != nil {
+		return fmt.Errorf("Failed to flush the host DNS cache: %v: %s", err, stderr)
 	}
+	return nil
+}
 
-	return output
+// RemoveHostRouting does nothing, the NRPT rule is kept while the instance is
+// stopped and removed by RunPostDelete
+func RemoveHostRouting(_ string, _ string) error {
+	return nil
 }
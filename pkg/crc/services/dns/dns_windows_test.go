@@ -0,0 +1,32 @@
+package dns
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNRPTRule(t *testing.T) {
+	namespaces := nrptNamespaces(services.ClusterDomains{
+		ClusterName:   "crc",
+		BaseDomain:    "testing",
+		AppsDomain:    "apps-crc.testing",
+		ProfileDomain: "test411.crc.testing",
+	})
+	assert.Equal(t, []string{".crc.testing", ".apps-crc.testing"}, namespaces)
+	assert.Equal(t, ".crc.testing,.apps-crc.testing=172.17.0.2", nrptRule(namespaces, "172.17.0.2"))
+	assert.Equal(t, `Get-DnsClientNrptRule | Where-Object { $_.Comment -eq 'crc' } | Remove-DnsClientNrptRule -Force; Add-DnsClientNrptRule -Namespace '.crc.testing','.apps-crc.testing' -NameServers '172.17.0.2' -Comment 'crc'`,
+		addNRPTRuleCommand(namespaces, "172.17.0.2"))
+}
+
+func TestFlushHostDNSCache(t *testing.T) {
+	var commands []string
+	run := func(command string, args ...string) (string, string, error) {
+		commands = append(commands, strings.Join(append([]string{command}, args...), " "))
+		return "", "", nil
+	}
+	assert.NoError(t, flushHostDNSCache(run))
+	assert.Equal(t, []string{"ipconfig /flushdns"}, commands)
+}
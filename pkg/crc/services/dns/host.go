@@ -0,0 +1,210 @@
+package dns
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/constants"
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/crc/services"
+	crcos "github.com/code-ready/crc/pkg/os"
+	"github.com/miekg/dns"
+)
+
+const hostRecordsTTL = 60
+
+// hostRecords are the cluster hostnames of an instance, they are served on
+// the host by 'crc daemon' when the host DNS service is used
+type hostRecords struct {
+	// Zones are the domains the server is authoritative for, the names of
+	// these domains without records do not exist
+	Zones   []string     `json:"zones"`
+	Records []hostRecord `json:"records"`
+}
+
+// hostRecord resolves the domain and all its subdomains to the IP, like the
+// address option of dnsmasq
+type hostRecord struct {
+	Domain string `json:"domain"`
+	IP     string `json:"ip"`
+}
+
+func hostRecordsPath(name string) string {
+	return filepath.Join(constants.HostDNSRecordsDir, fmt.Sprintf("%s.json", name))
+}
+
+// hostRecordsFor mirrors the addresses of the dnsmasq configuration of the VM
+func hostRecordsFor(serviceConfig services.ServicePostStartConfig) hostRecords {
+	clusterDomain := fmt.Sprintf("%s.%s", serviceConfig.Domains.ClusterName, serviceConfig.Domains.BaseDomain)
+	records := hostRecords{
+		Zones: []string{clusterDomain, serviceConfig.Domains.AppsDomain},
+	}
+	domains := []string{
+		serviceConfig.Domains.AppsDomain,
+		fmt.Sprintf("api.%s", clusterDomain),
+		fmt.Sprintf("api-int.%s", clusterDomain),
+	}
+	if serviceConfig.Domains.ProfileDomain != "" {
+		records.Zones = append(records.Zones, serviceConfig.Domains.ProfileDomain)
+		domains = append(domains, serviceConfig.Domains.ProfileDomain)
+	}
+	for _, ip := range []string{serviceConfig.IP, serviceConfig.IPv6} {
+		if ip == "" {
+			continue
+		}
+		for _, domain := range domains {
+			records.Records = append(records.Records, hostRecord{Domain: domain, IP: ip})
+		}
+	}
+	records.Records = append(records.Records, hostRecord{
+		Domain: fmt.Sprintf("%s.%s", serviceConfig.Node.Hostname, clusterDomain),
+		IP:     serviceConfig.Node.InternalIP,
+	})
+	return records
+}
+
+// writeHostRecords stores the cluster hostnames of the instance for the DNS
+// server of 'crc daemon', it reads them for each query
+func writeHostRecords(serviceConfig services.ServicePostStartConfig) error {
+	data, err := json.Marshal(hostRecordsFor(serviceConfig))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(constants.HostDNSRecordsDir, 0700); err != nil {
+		return err
+	}
+	_, err = crcos.WriteFileIfContentChanged(hostRecordsPath(serviceConfig.Name), data, 0600)
+	return err
+}
+
+// RemoveHostRecords removes the cluster hostnames of the instance from the
+// DNS server of 'crc daemon'
+func RemoveHostRecords(name string) error {
+	if err := os.Remove(hostRecordsPath(name)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func loadHostRecords(dir string) []hostRecords {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil
+	}
+	var all []hostRecords
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			logging.Debugf("Cannot read the DNS records %s: %v", file, err)
+			continue
+		}
+		var records hostRecords
+		if err := json.Unmarshal(data, &records); err != nil {
+			logging.Debugf("Invalid DNS records %s: %v", file, err)
+			continue
+		}
+		all = append(all, records)
+	}
+	return all
+}
+
+// inDomain returns true if name is the domain or one of its subdomains, name
+// is fully qualified
+func inDomain(name, domain string) bool {
+	domain = dns.Fqdn(strings.ToLower(domain))
+	return name == domain || strings.HasSuffix(name, "."+domain)
+}
+
+// lookup returns the IPs of the longest record domain matching the name, and
+// whether the name is in one of the zones
+func lookup(all []hostRecords, name string) ([]net.IP, bool) {
+	name = strings.ToLower(dns.Fqdn(name))
+	var (
+		ips       []net.IP
+		longest   int
+		authority bool
+	)
+	for _, records := range all {
+		for _, zone := range records.Zones {
+			if inDomain(name, zone) {
+				authority = true
+			}
+		}
+		for _, record := range records.Records {
+			if !inDomain(name, record.Domain) {
+				continue
+			}
+			ip := net.ParseIP(record.IP)
+			if ip == nil {
+				continue
+			}
+			switch {
+			case len(record.Domain) > longest:
+				longest = len(record.Domain)
+				ips = []net.IP{ip}
+			case len(record.Domain) == longest:
+				ips = append(ips, ip)
+			}
+		}
+	}
+	return ips, authority || len(ips) != 0
+}
+
+// HostServer answers the queries of the cluster hostnames on the host, with
+// the records written by the start of the instances. It refuses the other
+// queries, the resolver of the host only forwards the cluster domains to it.
+type HostServer struct {
+	recordsDir string
+}
+
+func NewHostServer() *HostServer {
+	return &HostServer{recordsDir: constants.HostDNSRecordsDir}
+}
+
+func (server *HostServer) ServeDNS(w dns.ResponseWriter, req *dns.Msg) {
+	msg := new(dns.Msg)
+	msg.SetReply(req)
+	msg.Authoritative = true
+	if len(req.Question) != 1 {
+		msg.SetRcode(req, dns.RcodeFormatError)
+		_ = w.WriteMsg(msg)
+		return
+	}
+
+	question := req.Question[0]
+	ips, found := lookup(loadHostRecords(server.recordsDir), question.Name)
+	if !found {
+		msg.Authoritative = false
+		msg.SetRcode(req, dns.RcodeRefused)
+		_ = w.WriteMsg(msg)
+		return
+	}
+	if len(ips) == 0 {
+		msg.SetRcode(req, dns.RcodeNameError)
+		_ = w.WriteMsg(msg)
+		return
+	}
+	for _, ip := range ips {
+		header := dns.RR_Header{Name: question.Name, Class: dns.ClassINET, Ttl: hostRecordsTTL}
+		switch {
+		case question.Qtype == dns.TypeA && ip.To4() != nil:
+			header.Rrtype = dns.TypeA
+			msg.Answer = append(msg.Answer, &dns.A{Hdr: header, A: ip.To4()})
+		case question.Qtype == dns.TypeAAAA && ip.To4() == nil:
+			header.Rrtype = dns.TypeAAAA
+			msg.Answer = append(msg.Answer, &dns.AAAA{Hdr: header, AAAA: ip})
+		}
+	}
+	_ = w.WriteMsg(msg)
+}
+
+// Serve answers the queries received on the connection until it is closed
+func (server *HostServer) Serve(conn net.PacketConn) error {
+	dnsServer := &dns.Server{PacketConn: conn, Handler: server}
+	return dnsServer.ActivateAndServe()
+}
@@ -0,0 +1,94 @@
+package dns
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/services"
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testServiceConfig() services.ServicePostStartConfig {
+	return services.ServicePostStartConfig{
+		Name: "crc",
+		IP:   "192.168.130.11",
+		IPv6: "fd00::11",
+		Domains: services.ClusterDomains{
+			ClusterName: "crc",
+			BaseDomain:  "testing",
+			AppsDomain:  "apps-crc.testing",
+		},
+		Node: services.Node{
+			Hostname:   "crc-m89r2-master-0",
+			InternalIP: "192.168.126.11",
+		},
+	}
+}
+
+func TestLookupHostRecords(t *testing.T) {
+	all := []hostRecords{hostRecordsFor(testServiceConfig())}
+
+	ips, found := lookup(all, "console-openshift-console.apps-crc.testing")
+	assert.True(t, found)
+	assert.Equal(t, []net.IP{net.ParseIP("192.168.130.11"), net.ParseIP("fd00::11")}, ips)
+
+	ips, found = lookup(all, "API.crc.testing.")
+	assert.True(t, found)
+	assert.Len(t, ips, 2)
+
+	ips, found = lookup(all, "crc-m89r2-master-0.crc.testing")
+	assert.True(t, found)
+	assert.Equal(t, []net.IP{net.ParseIP("192.168.126.11")}, ips)
+
+	ips, found = lookup(all, "missing.crc.testing")
+	assert.True(t, found)
+	assert.Empty(t, ips)
+
+	_, found = lookup(all, "quay.io")
+	assert.False(t, found)
+}
+
+func TestHostServer(t *testing.T) {
+	dir := t.TempDir()
+	data, err := json.Marshal(hostRecordsFor(testServiceConfig()))
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "crc.json"), data, 0600))
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := &HostServer{recordsDir: dir}
+	go func() {
+		_ = server.Serve(conn)
+	}()
+	defer conn.Close()
+
+	query := func(name string, qtype uint16) *dns.Msg {
+		msg := new(dns.Msg)
+		msg.SetQuestion(dns.Fqdn(name), qtype)
+		// the socket is bound, the query waits for the server to start
+		reply, err := dns.Exchange(msg, conn.LocalAddr().String())
+		require.NoError(t, err)
+		return reply
+	}
+
+	reply := query("api.crc.testing", dns.TypeA)
+	assert.Equal(t, dns.RcodeSuccess, reply.Rcode)
+	require.Len(t, reply.Answer, 1)
+	assert.Equal(t, "192.168.130.11", reply.Answer[0].(*dns.A).A.String())
+
+	reply = query("foo.apps-crc.testing", dns.TypeAAAA)
+	assert.Equal(t, dns.RcodeSuccess, reply.Rcode)
+	require.Len(t, reply.Answer, 1)
+	assert.Equal(t, "fd00::11", reply.Answer[0].(*dns.AAAA).AAAA.String())
+
+	reply = query("missing.crc.testing", dns.TypeA)
+	assert.Equal(t, dns.RcodeNameError, reply.Rcode)
+
+	reply = query("quay.io", dns.TypeA)
+	assert.Equal(t, dns.RcodeRefused, reply.Rcode)
+}
@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"text/template"
 
+	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/services"
 )
 
 const (
+	dnsmasqConfPath     = "/var/srv/dnsmasq.conf"
 	dnsmasqConfTemplate = `user=root
 port= {{ .Port }}
 bind-interfaces
@@ -19,6 +21,20 @@ address=/{{ .AppsDomain }}/{{ .IP }}
 address=/api.{{ .ClusterName}}.{{ .BaseDomain }}/{{ .IP }}
 address=/api-int.{{ .ClusterName}}.{{ .BaseDomain }}/{{ .IP }}
 address=/{{ .Hostname }}.{{ .ClusterName}}.{{ .BaseDomain }}/{{ .InternalIP }}
+{{- if .ProfileDomain }}
+address=/{{ .ProfileDomain }}/{{ .IP }}
+{{- end }}
+{{- if .IPv6 }}
+address=/{{ .AppsDomain }}/{{ .IPv6 }}
+address=/api.{{ .ClusterName}}.{{ .BaseDomain }}/{{ .IPv6 }}
+address=/api-int.{{ .ClusterName}}.{{ .BaseDomain }}/{{ .IPv6 }}
+{{- if .ProfileDomain }}
+address=/{{ .ProfileDomain }}/{{ .IPv6 }}
+{{- end }}
+{{- end }}
+{{- range .ForwardZones }}
+server=/{{ .Domain }}/{{ .NameServer.IPAddress }}
+{{- end }}
 `
 )
 
@@ -28,29 +44,45 @@ type dnsmasqConfFileValues struct {
 	ClusterName string
 	Hostname    string
 	IP          string
+	IPv6        string
 	AppsDomain  string
 	InternalIP  string
-}
+	// domain of the named instance, resolved to the instance IP
+	ProfileDomain string
 
-func createDnsmasqDNSConfig(serviceConfig services.ServicePostStartConfig) error {
-	domain := serviceConfig.BundleMetadata.ClusterInfo.BaseDomain
+	ForwardZones []network.ForwardZone
+}
 
-	dnsmasqConfFileValues := dnsmasqConfFileValues{
-		BaseDomain:  domain,
-		Hostname:    serviceConfig.BundleMetadata.Nodes[0].Hostname,
+func dnsmasqConfFileValuesFor(serviceConfig services.ServicePostStartConfig) dnsmasqConfFileValues {
+	return dnsmasqConfFileValues{
+		BaseDomain:  serviceConfig.Domains.BaseDomain,
+		Hostname:    serviceConfig.Node.Hostname,
 		Port:        dnsServicePort,
-		AppsDomain:  serviceConfig.BundleMetadata.ClusterInfo.AppsDomain,
-		ClusterName: serviceConfig.BundleMetadata.ClusterInfo.ClusterName,
+		AppsDomain:  serviceConfig.Domains.AppsDomain,
+		ClusterName: serviceConfig.Domains.ClusterName,
 		IP:          serviceConfig.IP,
-		InternalIP:  serviceConfig.BundleMetadata.Nodes[0].InternalIP,
+		IPv6:        serviceConfig.IPv6,
+		InternalIP:  serviceConfig.Node.InternalIP,
+
+		ProfileDomain: serviceConfig.Domains.ProfileDomain,
+
+		ForwardZones: serviceConfig.ForwardZones,
 	}
+}
 
-	dnsConfig, err := createDNSConfigFile(dnsmasqConfFileValues, dnsmasqConfTemplate)
+// createDnsmasqDNSConfig writes the dnsmasq configuration file in the VM and
+// returns true if its content changed
+func createDnsmasqDNSConfig(serviceConfig services.ServicePostStartConfig) (bool, error) {
+	dnsConfig, err := createDNSConfigFile(dnsmasqConfFileValuesFor(serviceConfig), dnsmasqConfTemplate)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	return serviceConfig.SSHRunner.CopyData([]byte(dnsConfig), "/var/srv/dnsmasq.conf", 0644)
+	currentConfig, err := serviceConfig.SSHRunner.ReadRemoteFile(dnsmasqConfPath)
+	if err == nil && string(currentConfig) == dnsConfig {
+		return false, nil
+	}
+	return true, serviceConfig.SSHRunner.CopyData([]byte(dnsConfig), dnsmasqConfPath, 0644)
 }
 
 func createDNSConfigFile(values dnsmasqConfFileValues, tmpl string) (string, error) {
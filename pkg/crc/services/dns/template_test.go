@@ -0,0 +1,96 @@
+package dns
+
+import (
+	"testing"
+
+	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDnsmasqConfig(t *testing.T) {
+	serviceConfig := services.ServicePostStartConfig{
+		Name: "crc",
+		IP:   "192.168.130.11",
+		Domains: services.ClusterDomains{
+			ClusterName: "crc",
+			BaseDomain:  "testing",
+			AppsDomain:  "apps-crc.testing",
+		},
+		Node: services.Node{
+			Hostname:   "crc-m89r2-master-0",
+			InternalIP: "192.168.126.11",
+		},
+		ForwardZones: []network.ForwardZone{
+			{Domain: "internal.company.com", NameServer: network.NameServer{IPAddress: "10.0.0.53"}},
+		},
+	}
+
+	config, err := createDNSConfigFile(dnsmasqConfFileValuesFor(serviceConfig), dnsmasqConfTemplate)
+	require.NoError(t, err)
+	assert.Equal(t, `user=root
+port= 53
+bind-interfaces
+expand-hosts
+log-queries
+local=/crc.testing/
+domain=crc.testing
+address=/apps-crc.testing/192.168.130.11
+address=/api.crc.testing/192.168.130.11
+address=/api-int.crc.testing/192.168.130.11
+address=/crc-m89r2-master-0.crc.testing/192.168.126.11
+server=/internal.company.com/10.0.0.53
+`, config)
+
+	assert.Equal(t, "api.crc.testing", serviceConfig.Domains.APIHostname())
+	assert.Equal(t, "console-openshift-console.apps-crc.testing", serviceConfig.Domains.AppHostname("console-openshift-console"))
+}
+
+func TestDnsmasqConfigProfileDomain(t *testing.T) {
+	serviceConfig := services.ServicePostStartConfig{
+		Name: "test411",
+		IP:   "192.168.130.11",
+		Domains: services.ClusterDomains{
+			ClusterName:   "crc",
+			BaseDomain:    "testing",
+			AppsDomain:    "apps-crc.testing",
+			ProfileDomain: "test411.crc.testing",
+		},
+		Node: services.Node{
+			Hostname:   "crc-m89r2-master-0",
+			InternalIP: "192.168.126.11",
+		},
+	}
+
+	config, err := createDNSConfigFile(dnsmasqConfFileValuesFor(serviceConfig), dnsmasqConfTemplate)
+	require.NoError(t, err)
+	assert.Contains(t, config, "address=/crc-m89r2-master-0.crc.testing/192.168.126.11\naddress=/test411.crc.testing/192.168.130.11\n")
+	assert.Equal(t, "api.test411.crc.testing", serviceConfig.Domains.ProfileAPIHostname())
+	assert.Equal(t, "console-openshift-console.apps.test411.crc.testing", serviceConfig.Domains.ProfileAppHostname("console-openshift-console"))
+}
+
+func TestDnsmasqConfigIPv6(t *testing.T) {
+	serviceConfig := services.ServicePostStartConfig{
+		Name: "crc",
+		IP:   "192.168.130.11",
+		IPv6: "2001:db8::11",
+		Domains: services.ClusterDomains{
+			ClusterName: "crc",
+			BaseDomain:  "testing",
+			AppsDomain:  "apps-crc.testing",
+		},
+		Node: services.Node{
+			Hostname:   "crc-m89r2-master-0",
+			InternalIP: "192.168.126.11",
+		},
+	}
+
+	config, err := createDNSConfigFile(dnsmasqConfFileValuesFor(serviceConfig), dnsmasqConfTemplate)
+	require.NoError(t, err)
+	assert.Contains(t, config, `address=/crc-m89r2-master-0.crc.testing/192.168.126.11
+address=/apps-crc.testing/2001:db8::11
+address=/api.crc.testing/2001:db8::11
+address=/api-int.crc.testing/2001:db8::11
+`)
+}
@@ -1,15 +1,66 @@
 package services
 
 import (
-	"github.com/code-ready/crc/pkg/crc/machine/bundle"
+	"fmt"
+
 	"github.com/code-ready/crc/pkg/crc/network"
 	"github.com/code-ready/crc/pkg/crc/ssh"
 )
 
+// ClusterDomains are the DNS domains used by the cluster
+type ClusterDomains struct {
+	ClusterName string
+	BaseDomain  string
+	AppsDomain  string
+	// domain of the instance when it is not the default one, such as
+	// test411.crc.testing, the cluster API is also reachable through it
+	ProfileDomain string
+}
+
+func (domains ClusterDomains) APIHostname() string {
+	return fmt.Sprintf("api.%s.%s", domains.ClusterName, domains.BaseDomain)
+}
+
+// ProfileAPIHostname is the hostname of the cluster API in the domain of the
+// instance, it is empty for the default instance
+func (domains ClusterDomains) ProfileAPIHostname() string {
+	if domains.ProfileDomain == "" {
+		return ""
+	}
+	return fmt.Sprintf("api.%s", domains.ProfileDomain)
+}
+
+// ProfileAppHostname is the hostname of a console route in the domain of the
+// instance, it is empty for the default instance
+func (domains ClusterDomains) ProfileAppHostname(appName string) string {
+	if domains.ProfileDomain == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s.apps.%s", appName, domains.ProfileDomain)
+}
+
+func (domains ClusterDomains) AppHostname(appName string) string {
+	return fmt.Sprintf("%s.%s", appName, domains.AppsDomain)
+}
+
+// Node is the cluster node running in the VM
+type Node struct {
+	Hostname string
+	// IP of the node inside the VM, IP of ServicePostStartConfig is the one
+	// reachable from the host
+	InternalIP string
+}
+
 type ServicePostStartConfig struct {
-	Name           string
-	SSHRunner      *ssh.Runner
-	BundleMetadata bundle.CrcBundleInfo
-	IP             string
-	NetworkMode    network.Mode
+	Name         string
+	SSHRunner    *ssh.Runner
+	IP           string
+	IPv6         string
+	Domains      ClusterDomains
+	Node         Node
+	NetworkMode  network.Mode
+	ForwardZones []network.ForwardZone
+	// HostDNS is true when the host resolves the cluster hostnames with the
+	// DNS server of 'crc daemon' instead of the one of the VM
+	HostDNS bool
 }
@@ -0,0 +1,66 @@
+package ssh
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/spf13/cast"
+)
+
+// Backend is the ssh client implementation used to connect to the VM
+type Backend string
+
+const (
+	// NativeBackend uses the Go ssh client, it works on all platforms
+	// without any dependency
+	NativeBackend Backend = "native"
+	// ExternalBackend runs the ssh executable of the host, which honours the
+	// ssh configuration of the user (GSSAPI, hardware tokens, ssh agent, ...)
+	ExternalBackend Backend = "external"
+)
+
+func (b Backend) String() string {
+	return string(b)
+}
+
+func parseBackend(input string) (Backend, error) {
+	switch input {
+	case string(NativeBackend), "":
+		return NativeBackend, nil
+	case string(ExternalBackend):
+		return ExternalBackend, nil
+	default:
+		return NativeBackend, fmt.Errorf("Cannot parse ssh backend '%s'", input)
+	}
+}
+
+func ParseBackend(input string) Backend {
+	backend, err := parseBackend(input)
+	if err != nil {
+		logging.Errorf("unexpected ssh backend %s, using default", input)
+		return NativeBackend
+	}
+	return backend
+}
+
+func ValidateBackend(val interface{}) (bool, string) {
+	backend, err := parseBackend(cast.ToString(val))
+	if err != nil {
+		return false, fmt.Sprintf("ssh backend should be either %s or %s", NativeBackend, ExternalBackend)
+	}
+	if err := CheckBackend(backend); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// CheckBackend checks that backend can be used on this host
+func CheckBackend(backend Backend) error {
+	if backend == ExternalBackend {
+		if _, err := exec.LookPath(sshExecutable); err != nil {
+			return fmt.Errorf("the %s ssh backend needs the %s executable: %v", ExternalBackend, sshExecutable, err)
+		}
+	}
+	return nil
+}
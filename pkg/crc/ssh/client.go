@@ -2,11 +2,14 @@ package ssh
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net"
 	"strconv"
+	"sync"
 	"time"
 
 	log "github.com/code-ready/crc/pkg/crc/logging"
@@ -15,6 +18,13 @@ import (
 
 type Client interface {
 	Run(command string) ([]byte, []byte, error)
+	// Stream runs command with its standard output written to stdout, for
+	// outputs too large to be kept in memory. The command is ended when ctx
+	// is cancelled.
+	Stream(ctx context.Context, command string, stdout io.Writer) ([]byte, error)
+	// Shell runs command, or a login shell when command is empty, with the
+	// given standard streams, for interactive sessions
+	Shell(command string, stdin io.Reader, stdout, stderr io.Writer) error
 	Close()
 }
 
@@ -24,16 +34,39 @@ type NativeClient struct {
 	Port     int
 	Keys     []string
 
+	// protects conn, sessions can be opened concurrently
+	lock sync.Mutex
 	conn *ssh.Client
 }
 
 func NewClient(user string, host string, port int, keys ...string) (Client, error) {
-	return &NativeClient{
-		User:     user,
-		Hostname: host,
-		Port:     port,
-		Keys:     keys,
-	}, nil
+	return NewClientWithBackend(NativeBackend, user, host, port, keys...)
+}
+
+// NewClientWithBackend is the same as NewClient, with the ssh client
+// implementation given by backend
+func NewClientWithBackend(backend Backend, user string, host string, port int, keys ...string) (Client, error) {
+	switch backend {
+	case NativeBackend:
+		return &NativeClient{
+			User:     user,
+			Hostname: host,
+			Port:     port,
+			Keys:     keys,
+		}, nil
+	case ExternalBackend:
+		if err := CheckBackend(backend); err != nil {
+			return nil, err
+		}
+		return &ExternalClient{
+			User:     user,
+			Hostname: host,
+			Port:     port,
+			Keys:     keys,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported ssh backend '%s'", backend)
+	}
 }
 
 func clientConfig(user string, keys []string) (*ssh.ClientConfig, error) {
@@ -72,6 +105,8 @@ func clientConfig(user string, keys []string) (*ssh.ClientConfig, error) {
 }
 
 func (client *NativeClient) session() (*ssh.Session, error) {
+	client.lock.Lock()
+	defer client.lock.Unlock()
 	if client.conn == nil {
 		var err error
 		config, err := clientConfig(client.User, client.Keys)
@@ -85,6 +120,9 @@ func (client *NativeClient) session() (*ssh.Session, error) {
 	}
 	session, err := client.conn.NewSession()
 	if err != nil {
+		log.Debugf("Failed to create new ssh session: %s", err)
+		client.conn.Close()
+		client.conn = nil
 		return nil, err
 	}
 	return session, err
@@ -93,11 +131,6 @@ func (client *NativeClient) session() (*ssh.Session, error) {
 func (client *NativeClient) Run(command string) ([]byte, []byte, error) {
 	session, err := client.session()
 	if err != nil {
-		if client.conn != nil {
-			log.Debugf("Failed to create new ssh session: %s", err)
-			client.conn.Close()
-			client.conn = nil
-		}
 		return nil, nil, err
 	}
 	defer session.Close()
@@ -114,7 +147,40 @@ func (client *NativeClient) Run(command string) ([]byte, []byte, error) {
 	return stdout.Bytes(), stderr.Bytes(), err
 }
 
+func (client *NativeClient) Stream(ctx context.Context, command string, stdout io.Writer) ([]byte, error) {
+	session, err := client.session()
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	var stderr bytes.Buffer
+	session.Stdout = stdout
+	session.Stderr = &stderr
+
+	if err := session.Start(command); err != nil {
+		return nil, err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- session.Wait()
+	}()
+	select {
+	case err := <-done:
+		return stderr.Bytes(), err
+	case <-ctx.Done():
+		// the ssh servers ignoring the signal end the command once it
+		// writes to the closed session
+		_ = session.Signal(ssh.SIGTERM)
+		_ = session.Close()
+		<-done
+		return stderr.Bytes(), ctx.Err()
+	}
+}
+
 func (client *NativeClient) Close() {
+	client.lock.Lock()
+	defer client.lock.Unlock()
 	if client.conn == nil {
 		return
 	}
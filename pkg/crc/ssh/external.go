@@ -0,0 +1,91 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	log "github.com/code-ready/crc/pkg/crc/logging"
+)
+
+const sshExecutable = "ssh"
+
+// ExternalClient runs commands with the ssh executable of the host
+type ExternalClient struct {
+	User     string
+	Hostname string
+	Port     int
+	Keys     []string
+}
+
+// readCommand is the remote command reading the command to run from the
+// standard input, the commands are not on the command line of the ssh
+// process since they can hold secrets such as the pull secret, which would be
+// visible to the other users of the host in ps or /proc. The whole input is
+// read before running it, the command gets an empty standard input.
+const readCommand = `sh -c 'eval "$(cat)"'`
+
+func (client *ExternalClient) args() []string {
+	return append(client.options(), client.User+"@"+client.Hostname, "--", readCommand)
+}
+
+// options returns the arguments of the ssh executable needed to connect to
+// the VM
+func (client *ExternalClient) options() []string {
+	args := []string{
+		// the VM host key changes with each bundle
+		"-o", "StrictHostKeyChecking=no",
+		"-o", "UserKnownHostsFile=" + os.DevNull,
+		"-o", "LogLevel=ERROR",
+		"-o", "ConnectTimeout=10",
+		"-o", "BatchMode=yes",
+		"-p", strconv.Itoa(client.Port),
+	}
+	for _, key := range client.Keys {
+		if _, err := os.Stat(key); err == nil {
+			args = append(args, "-i", key)
+		}
+	}
+	return args
+}
+
+func (client *ExternalClient) Run(command string) ([]byte, []byte, error) {
+	var (
+		stdout bytes.Buffer
+		stderr bytes.Buffer
+	)
+	// #nosec G204
+	cmd := exec.Command(sshExecutable, client.args()...)
+	cmd.Stdin = strings.NewReader(command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		log.Debugf("ssh executable failed: %s", stderr.String())
+	}
+	return stdout.Bytes(), stderr.Bytes(), err
+}
+
+func (client *ExternalClient) Stream(ctx context.Context, command string, stdout io.Writer) ([]byte, error) {
+	var stderr bytes.Buffer
+	// the ssh process is killed when ctx is cancelled, which closes the
+	// session of the command
+	// #nosec G204
+	cmd := exec.CommandContext(ctx, sshExecutable, client.args()...)
+	cmd.Stdin = strings.NewReader(command)
+	cmd.Stdout = stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		log.Debugf("ssh executable failed: %s", stderr.String())
+	}
+	return stderr.Bytes(), err
+}
+
+// Close does nothing, a new ssh process is used for each command
+func (client *ExternalClient) Close() {
+}
@@ -0,0 +1,45 @@
+package ssh
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalClientArgs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ssh")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	key := filepath.Join(dir, "id_ecdsa")
+	require.NoError(t, ioutil.WriteFile(key, []byte("key"), 0600))
+
+	client := &ExternalClient{
+		User:     "core",
+		Hostname: "192.168.130.11",
+		Port:     22,
+		Keys:     []string{filepath.Join(dir, "missing"), key},
+	}
+	assert.Equal(t, []string{
+		"-o", "StrictHostKeyChecking=no",
+		"-o", "UserKnownHostsFile=" + os.DevNull,
+		"-o", "LogLevel=ERROR",
+		"-o", "ConnectTimeout=10",
+		"-o", "BatchMode=yes",
+		"-p", "22",
+		"-i", key,
+		"core@192.168.130.11", "--", `sh -c 'eval "$(cat)"'`,
+	}, client.args())
+}
+
+func TestValidateBackend(t *testing.T) {
+	valid, _ := ValidateBackend("native")
+	assert.True(t, valid)
+	valid, message := ValidateBackend("putty")
+	assert.False(t, valid)
+	assert.Equal(t, "ssh backend should be either native or external", message)
+}
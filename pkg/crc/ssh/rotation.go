@@ -0,0 +1,79 @@
+package ssh
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/spf13/cast"
+)
+
+const (
+	// NeverRotate keeps the SSH key pair generated with the instance
+	NeverRotate = "never"
+	// RotateEveryStart generates a new SSH key pair on every start
+	RotateEveryStart = "every-start"
+)
+
+// RotationPolicy tells when the SSH key pair of the instance is replaced
+type RotationPolicy struct {
+	EveryStart bool
+	// maximum age of the key pair, the key pair is never replaced when it
+	// is zero and EveryStart is false
+	MaxAge time.Duration
+}
+
+func (p RotationPolicy) String() string {
+	switch {
+	case p.EveryStart:
+		return RotateEveryStart
+	case p.MaxAge == 0:
+		return NeverRotate
+	default:
+		return strconv.Itoa(int(p.MaxAge / (24 * time.Hour)))
+	}
+}
+
+// Enabled is false when the key pair is never replaced
+func (p RotationPolicy) Enabled() bool {
+	return p.EveryStart || p.MaxAge > 0
+}
+
+// Due tells if a key pair created at keyCreated must be replaced at now
+func (p RotationPolicy) Due(keyCreated, now time.Time) bool {
+	if p.EveryStart {
+		return true
+	}
+	return p.MaxAge > 0 && now.Sub(keyCreated) >= p.MaxAge
+}
+
+func parseRotationPolicy(input string) (RotationPolicy, error) {
+	switch input {
+	case NeverRotate, "":
+		return RotationPolicy{}, nil
+	case RotateEveryStart:
+		return RotationPolicy{EveryStart: true}, nil
+	}
+	days, err := strconv.Atoi(input)
+	if err != nil || days <= 0 {
+		return RotationPolicy{}, fmt.Errorf("SSH key rotation should be %s, %s or a number of days greater than 0", NeverRotate, RotateEveryStart)
+	}
+	return RotationPolicy{MaxAge: time.Duration(days) * 24 * time.Hour}, nil
+}
+
+func ParseRotationPolicy(input string) RotationPolicy {
+	policy, err := parseRotationPolicy(input)
+	if err != nil {
+		logging.Errorf("unexpected SSH key rotation policy %s, the key is not rotated", input)
+		return RotationPolicy{}
+	}
+	return policy
+}
+
+func ValidateRotationPolicy(val interface{}) (bool, string) {
+	if _, err := parseRotationPolicy(cast.ToString(val)); err != nil {
+		return false, err.Error()
+	}
+	return true, ""
+}
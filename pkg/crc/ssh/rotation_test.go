@@ -0,0 +1,41 @@
+package ssh
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRotationPolicy(t *testing.T) {
+	policy, err := parseRotationPolicy("")
+	require.NoError(t, err)
+	assert.False(t, policy.Enabled())
+	assert.Equal(t, NeverRotate, policy.String())
+
+	policy, err = parseRotationPolicy(RotateEveryStart)
+	require.NoError(t, err)
+	assert.True(t, policy.EveryStart)
+	assert.Equal(t, RotateEveryStart, policy.String())
+
+	policy, err = parseRotationPolicy("30")
+	require.NoError(t, err)
+	assert.Equal(t, 30*24*time.Hour, policy.MaxAge)
+	assert.Equal(t, "30", policy.String())
+
+	_, err = parseRotationPolicy("0")
+	assert.Error(t, err)
+	_, err = parseRotationPolicy("weekly")
+	assert.Error(t, err)
+}
+
+func TestRotationPolicyDue(t *testing.T) {
+	now := time.Now()
+	created := now.Add(-10 * 24 * time.Hour)
+
+	assert.False(t, RotationPolicy{}.Due(created, now))
+	assert.True(t, RotationPolicy{EveryStart: true}.Due(now, now))
+	assert.True(t, RotationPolicy{MaxAge: 7 * 24 * time.Hour}.Due(created, now))
+	assert.False(t, RotationPolicy{MaxAge: 30 * 24 * time.Hour}.Due(created, now))
+}
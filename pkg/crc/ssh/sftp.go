@@ -0,0 +1,233 @@
+package ssh
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/pkg/sftp"
+)
+
+// sftpServer is the path of the sftp server of openssh in the VM, it is run
+// over an ssh session rather than as the sftp subsystem so that it can be
+// run as root
+const sftpServer = "/usr/libexec/openssh/sftp-server"
+
+// sftpSession is an sftp client connected to an sftp server in the VM
+type sftpSession struct {
+	*sftp.Client
+	stderr bytes.Buffer
+	done   chan error
+}
+
+// openSFTP starts an sftp server in the VM, running as root when privileged
+// is true, and returns a client connected to it
+func (runner *Runner) openSFTP(privileged bool) (*sftpSession, error) {
+	command := sftpServer
+	if privileged {
+		command = runner.PrivilegedCommand(command)
+	}
+	logging.DebugfFor(logging.SSHDebug, "Running SSH command: %s", command)
+	stdinReader, stdinWriter := io.Pipe()
+	stdoutReader, stdoutWriter := io.Pipe()
+	session := &sftpSession{
+		done: make(chan error, 1),
+	}
+	go func() {
+		err := runner.client.Shell(command, stdinReader, stdoutWriter, &session.stderr)
+		_ = stdinReader.Close()
+		_ = stdoutWriter.Close()
+		session.done <- err
+	}()
+	client, err := sftp.NewClientPipe(stdoutReader, stdinWriter)
+	if err != nil {
+		_ = stdinWriter.Close()
+		if sessionErr := <-session.done; sessionErr != nil {
+			err = sessionErr
+		}
+		return nil, fmt.Errorf("cannot start the sftp server in the VM: %v: %s", err, session.stderr.String())
+	}
+	session.Client = client
+	return session, nil
+}
+
+// Close stops the sftp server
+func (session *sftpSession) Close() error {
+	_ = session.Client.Close()
+	return <-session.done
+}
+
+// writeFile creates or replaces filename in the VM with the content of
+// reader, its permissions are set to mode once it is written, also when the
+// file already existed
+func (runner *Runner) writeFile(reader io.Reader, filename string, mode os.FileMode, privileged bool) error {
+	session, err := runner.openSFTP(privileged)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	file, err := session.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return fmt.Errorf("cannot create %s in the VM: %w", filename, err)
+	}
+	if _, err := file.ReadFrom(reader); err != nil {
+		file.Close()
+		return fmt.Errorf("cannot write %s in the VM: %w", filename, err)
+	}
+	if err := file.Chmod(mode); err != nil {
+		file.Close()
+		return fmt.Errorf("cannot change the permissions of %s in the VM: %w", filename, err)
+	}
+	return file.Close()
+}
+
+// CopyFile copies the local file srcFilename to destFilename in the VM, as
+// the ssh user
+func (runner *Runner) CopyFile(srcFilename string, destFilename string, mode os.FileMode) error {
+	return runner.copyFile(srcFilename, destFilename, mode, false)
+}
+
+// CopyFileAsRoot copies the local file srcFilename to destFilename in the
+// VM, as root
+func (runner *Runner) CopyFileAsRoot(srcFilename string, destFilename string, mode os.FileMode) error {
+	return runner.copyFile(srcFilename, destFilename, mode, true)
+}
+
+func (runner *Runner) copyFile(srcFilename string, destFilename string, mode os.FileMode, privileged bool) error {
+	file, err := os.Open(srcFilename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	logging.Debugf("Copying %s to %s with permissions 0%o in the CRC VM", srcFilename, destFilename, mode)
+	return runner.writeFile(file, destFilename, mode, privileged)
+}
+
+// ReadRemoteFile returns the content of filename in the VM, it is read as
+// root
+func (runner *Runner) ReadRemoteFile(filename string) ([]byte, error) {
+	session, err := runner.openSFTP(true)
+	if err != nil {
+		return nil, err
+	}
+	defer session.Close()
+
+	file, err := session.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open %s in the VM: %w", filename, err)
+	}
+	defer file.Close()
+	content, err := ioutil.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read %s in the VM: %w", filename, err)
+	}
+	return content, nil
+}
+
+// InstallData creates destFilename in the VM with data, owned by the ssh user
+// and with the given permissions. The file is written over sftp next to
+// destFilename and only renamed in place once its content is read back and
+// verified, so that an incomplete copy never replaces a working file.
+func (runner *Runner) InstallData(data []byte, destFilename string, mode os.FileMode) error {
+	logging.Debugf("Installing %s with permissions 0%o in the CRC VM", destFilename, mode)
+	uid, err := runner.userID("-u")
+	if err != nil {
+		return err
+	}
+	gid, err := runner.userID("-g")
+	if err != nil {
+		return err
+	}
+	// the destination may be in a directory the ssh user cannot write to,
+	// see https://bugzilla.redhat.com/show_bug.cgi?id=1956739
+	session, err := runner.openSFTP(true)
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	tmpFilename := destFilename + ".crc-new"
+	if err := session.writeOwnedFile(data, tmpFilename, mode, uid, gid); err != nil {
+		_ = session.Remove(tmpFilename)
+		return fmt.Errorf("cannot write %s in the VM: %w", destFilename, err)
+	}
+	if err := session.verifyContent(data, tmpFilename); err != nil {
+		_ = session.Remove(tmpFilename)
+		return fmt.Errorf("cannot verify %s in the VM: %w", destFilename, err)
+	}
+	if err := session.PosixRename(tmpFilename, destFilename); err != nil {
+		_ = session.Remove(tmpFilename)
+		return fmt.Errorf("cannot move %s in place in the VM: %w", destFilename, err)
+	}
+
+	info, err := session.Stat(destFilename)
+	if err != nil {
+		return fmt.Errorf("cannot get the permissions of %s in the VM: %w", destFilename, err)
+	}
+	stat, ok := info.Sys().(*sftp.FileStat)
+	if !ok || info.Mode().Perm() != mode || int(stat.UID) != uid {
+		return fmt.Errorf("unexpected permissions and owner for %s in the VM: expected 0%o owned by %d, got 0%o", destFilename, mode, uid, info.Mode().Perm())
+	}
+	return nil
+}
+
+// userID returns the user or the group ID of the ssh user, as given by the
+// flag of id
+func (runner *Runner) userID(flag string) (int, error) {
+	stdout, stderr, err := runner.Run("id", flag)
+	if err != nil {
+		return 0, fmt.Errorf("cannot get the ID of %s in the VM: %w: %s", runner.user, err, stderr)
+	}
+	id, err := strconv.Atoi(strings.TrimSpace(stdout))
+	if err != nil {
+		return 0, fmt.Errorf("invalid ID of %s in the VM '%s'", runner.user, strings.TrimSpace(stdout))
+	}
+	return id, nil
+}
+
+// writeOwnedFile creates or replaces filename with data, owned by uid and gid
+// and with the permissions mode
+func (session *sftpSession) writeOwnedFile(data []byte, filename string, mode os.FileMode, uid, gid int) error {
+	file, err := session.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Chmod(mode); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Chown(uid, gid); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// verifyContent reads filename back and checks that it has the checksum of
+// data
+func (session *sftpSession) verifyContent(data []byte, filename string) error {
+	file, err := session.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return err
+	}
+	if expected, actual := fmt.Sprintf("%x", sha256.Sum256(data)), fmt.Sprintf("%x", hash.Sum(nil)); actual != expected {
+		return fmt.Errorf("checksum mismatch, expected %s, got %s", expected, actual)
+	}
+	return nil
+}
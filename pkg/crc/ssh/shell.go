@@ -0,0 +1,104 @@
+package ssh
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+
+	"golang.org/x/crypto/ssh"
+	terminal "golang.org/x/term"
+)
+
+const defaultTerm = "xterm-256color"
+
+// Shell runs command, or a login shell when command is empty, with the given
+// standard streams. A pseudo-terminal is allocated when stdin is a terminal.
+func (client *NativeClient) Shell(command string, stdin io.Reader, stdout, stderr io.Writer) error {
+	session, err := client.session()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	session.Stdin = stdin
+	session.Stdout = stdout
+	session.Stderr = stderr
+	if fd, ok := terminalFd(stdin); ok {
+		width, height, err := terminal.GetSize(fd)
+		if err != nil {
+			width, height = 80, 24
+		}
+		state, err := terminal.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = terminal.Restore(fd, state)
+		}()
+		term := os.Getenv("TERM")
+		if term == "" {
+			term = defaultTerm
+		}
+		modes := ssh.TerminalModes{
+			ssh.ECHO:          1,
+			ssh.TTY_OP_ISPEED: 14400,
+			ssh.TTY_OP_OSPEED: 14400,
+		}
+		if err := session.RequestPty(term, height, width, modes); err != nil {
+			return err
+		}
+	}
+
+	if command != "" {
+		return session.Run(command)
+	}
+	if err := session.Shell(); err != nil {
+		return err
+	}
+	return session.Wait()
+}
+
+// Shell runs command, or a login shell when command is empty, with the ssh
+// executable attached to the given standard streams
+func (client *ExternalClient) Shell(command string, stdin io.Reader, stdout, stderr io.Writer) error {
+	args := client.options()
+	if _, ok := terminalFd(stdin); ok {
+		args = append(args, "-t")
+	}
+	args = append(args, client.User+"@"+client.Hostname)
+	if command != "" {
+		args = append(args, "--", command)
+	}
+	// #nosec G204
+	cmd := exec.Command(sshExecutable, args...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	return cmd.Run()
+}
+
+// terminalFd returns the file descriptor of stream when it is a terminal
+func terminalFd(stream io.Reader) (int, bool) {
+	file, ok := stream.(*os.File)
+	if !ok || !terminal.IsTerminal(int(file.Fd())) {
+		return 0, false
+	}
+	return int(file.Fd()), true
+}
+
+// ExitStatus returns the exit status of the remote command which failed
+// with err, it is false when err is not an exit status, for instance when
+// the connection failed
+func ExitStatus(err error) (int, bool) {
+	var sshErr *ssh.ExitError
+	if errors.As(err, &sshErr) {
+		return sshErr.ExitStatus(), true
+	}
+	var execErr *exec.ExitError
+	// the ssh executable exits with 255 when it cannot connect
+	if errors.As(err, &execErr) && execErr.ExitCode() != 255 {
+		return execErr.ExitCode(), true
+	}
+	return 0, false
+}
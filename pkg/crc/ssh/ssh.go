@@ -1,10 +1,10 @@
 package ssh
 
 import (
+	"bytes"
 	"context"
-	"encoding/base64"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"os"
 	"strings"
 	"time"
@@ -14,17 +14,42 @@ import (
 	"github.com/code-ready/crc/pkg/crc/logging"
 )
 
+// PrivilegeEscalation is the way commands needing root access are run in the VM
+type PrivilegeEscalation string
+
+const (
+	// Sudo runs privileged commands with passwordless sudo
+	Sudo PrivilegeEscalation = "sudo"
+	// NoPrivilegeEscalation runs privileged commands as is, the ssh user must be root
+	NoPrivilegeEscalation PrivilegeEscalation = "none"
+)
+
 type Runner struct {
-	client Client
+	client              Client
+	user                string
+	privilegeEscalation PrivilegeEscalation
 }
 
 func CreateRunner(ip string, port int, privateKeys ...string) (*Runner, error) {
-	client, err := NewClient(constants.DefaultSSHUser, ip, port, privateKeys...)
+	return CreateRunnerForUser(NativeBackend, constants.DefaultSSHUser, Sudo, ip, port, privateKeys...)
+}
+
+// CreateRunnerForUser is the same as CreateRunner, for images not using the
+// default core user with passwordless sudo, and with the given ssh backend
+func CreateRunnerForUser(backend Backend, user string, privilegeEscalation PrivilegeEscalation, ip string, port int, privateKeys ...string) (*Runner, error) {
+	switch privilegeEscalation {
+	case Sudo, NoPrivilegeEscalation:
+	default:
+		return nil, fmt.Errorf("unsupported privilege escalation method '%s'", privilegeEscalation)
+	}
+	client, err := NewClientWithBackend(backend, user, ip, port, privateKeys...)
 	if err != nil {
 		return nil, err
 	}
 	return &Runner{
-		client: client,
+		client:              client,
+		user:                user,
+		privilegeEscalation: privilegeEscalation,
 	}, nil
 }
 
@@ -48,43 +73,63 @@ func (runner *Runner) RunPrivate(cmd string, args ...string) (string, string, er
 
 func (runner *Runner) RunPrivileged(reason string, cmdAndArgs ...string) (string, string, error) {
 	logging.Debugf("Using root access: %s", reason)
-	commandline := fmt.Sprintf("sudo %s", strings.Join(cmdAndArgs, " "))
-	return runner.runSSHCommand(commandline, false)
+	return runner.runSSHCommand(runner.PrivilegedCommand(strings.Join(cmdAndArgs, " ")), false)
+}
+
+// Shell runs command, or a login shell when command is empty, as the ssh
+// user with the given standard streams. A pseudo-terminal is allocated when
+// stdin is a terminal.
+func (runner *Runner) Shell(command string, stdin io.Reader, stdout, stderr io.Writer) error {
+	return runner.client.Shell(command, stdin, stdout, stderr)
 }
 
+// PrivilegedCommand returns command prefixed as needed to run it as root, for
+// use in shell pipelines which cannot go through RunPrivileged
+func (runner *Runner) PrivilegedCommand(command string) string {
+	if runner.privilegeEscalation == NoPrivilegeEscalation {
+		return command
+	}
+	return fmt.Sprintf("sudo %s", command)
+}
+
+// CopyData creates or replaces destFilename in the VM with data, as root
 func (runner *Runner) CopyData(data []byte, destFilename string, mode os.FileMode) error {
 	logging.Debugf("Creating %s with permissions 0%o in the CRC VM", destFilename, mode)
-	base64Data := base64.StdEncoding.EncodeToString(data)
-	command := fmt.Sprintf("sudo install -m 0%o /dev/null %s && cat <<EOF | base64 --decode | sudo tee %s\n%s\nEOF", mode, destFilename, destFilename, base64Data)
-	_, _, err := runner.RunPrivate(command)
-
-	return err
+	return runner.writeFile(bytes.NewReader(data), destFilename, mode, true)
 }
 
-func (runner *Runner) CopyFile(srcFilename string, destFilename string, mode os.FileMode) error {
-	data, err := ioutil.ReadFile(srcFilename)
+// StreamPrivileged runs the command as root with its standard output written to
+// stdout, and returns its standard error. The command is ended when ctx is
+// cancelled.
+func (runner *Runner) StreamPrivileged(ctx context.Context, reason string, stdout io.Writer, cmdAndArgs ...string) (string, error) {
+	logging.Debugf("Using root access: %s", reason)
+	command := runner.PrivilegedCommand(strings.Join(cmdAndArgs, " "))
+	logging.DebugfFor(logging.SSHDebug, "Running SSH command: %s", command)
+	stderr, err := runner.client.Stream(ctx, command, stdout)
 	if err != nil {
-		return err
+		return string(stderr), fmt.Errorf(`ssh command error:
+command : %s
+err     : %w`+"\n", command, err)
 	}
-	return runner.CopyData(data, destFilename, mode)
+	return string(stderr), nil
 }
 
 func (runner *Runner) runSSHCommand(command string, runPrivate bool) (string, string, error) {
 	if runPrivate {
-		logging.Debugf("Running SSH command: <hidden>")
+		logging.DebugfFor(logging.SSHDebug, "Running SSH command: <hidden>")
 	} else {
-		logging.Debugf("Running SSH command: %s", command)
+		logging.DebugfFor(logging.SSHDebug, "Running SSH command: %s", command)
 	}
 
 	stdout, stderr, err := runner.client.Run(command)
 	if runPrivate {
 		if err != nil {
-			logging.Debugf("SSH command failed")
+			logging.DebugfFor(logging.SSHDebug, "SSH command failed")
 		} else {
-			logging.Debugf("SSH command succeeded")
+			logging.DebugfFor(logging.SSHDebug, "SSH command succeeded")
 		}
 	} else {
-		logging.Debugf("SSH command results: err: %v, output: %s", err, string(stdout))
+		logging.DebugfFor(logging.SSHDebug, "SSH command results: err: %v, output: %s", err, string(stdout))
 	}
 
 	if err != nil {
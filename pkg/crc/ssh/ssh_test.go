@@ -16,7 +16,9 @@ import (
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/pkg/sftp"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -44,13 +46,177 @@ func TestRunner(t *testing.T) {
 	assert.Equal(t, "hello", bin)
 	cancel()
 	// Expect error when sending data over close ssh server channel
-	assert.Error(t, runner.CopyData([]byte(`hello world`), "/hello", 0644))
+	hello := filepath.Join(dir, "hello")
+	assert.Error(t, runner.CopyData([]byte(`hello world`), hello, 0644))
 
 	_, runner, totalConn := createListnerAndSSHServer(t, clientKey, clientKeyFile)
-	assert.NoError(t, runner.CopyData([]byte(`hello world`), "/hello", 0644))
-	assert.NoError(t, runner.CopyData([]byte(`hello world`), "/hello", 0644))
-	assert.NoError(t, runner.CopyData([]byte(`hello world`), "/hello", 0644))
+	assert.NoError(t, runner.CopyData([]byte(`hello world`), hello, 0644))
+	assert.NoError(t, runner.CopyData([]byte(`hello world`), hello, 0644))
+	assert.NoError(t, runner.CopyData([]byte(`hello world`), hello, 0644))
 	assert.Equal(t, 1, *totalConn)
+
+	content, err := runner.ReadRemoteFile(hello)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello world", string(content))
+	info, err := os.Stat(hello)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+}
+
+func TestCopyFile(t *testing.T) {
+	dir := t.TempDir()
+	clientKey, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+	clientKeyFile := filepath.Join(dir, "private.key")
+	writePrivateKey(t, clientKeyFile, clientKey)
+
+	cancel, runner, _ := createListnerAndSSHServer(t, clientKey, clientKeyFile)
+	defer cancel()
+	defer runner.Close()
+
+	// binary content goes through unchanged
+	data := []byte{0, 1, 2, 0xff, '\n', 'E', 'O', 'F', '\n'}
+	src := filepath.Join(dir, "src")
+	require.NoError(t, ioutil.WriteFile(src, data, 0600))
+
+	dest := filepath.Join(dir, "dest")
+	assert.NoError(t, runner.CopyFile(src, dest, 0640))
+	content, err := runner.ReadRemoteFile(dest)
+	assert.NoError(t, err)
+	assert.Equal(t, data, content)
+	info, err := os.Stat(dest)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+
+	// the permissions of an existing file are replaced too
+	assert.NoError(t, runner.CopyFile(src, dest, 0600))
+	info, err = os.Stat(dest)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	destAsRoot := filepath.Join(dir, "dest-as-root")
+	assert.NoError(t, runner.CopyFileAsRoot(src, destAsRoot, 0600))
+	assert.FileExists(t, destAsRoot)
+
+	_, err = runner.ReadRemoteFile(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestShell(t *testing.T) {
+	dir := t.TempDir()
+	clientKey, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+	clientKeyFile := filepath.Join(dir, "private.key")
+	writePrivateKey(t, clientKeyFile, clientKey)
+
+	cancel, runner, _ := createListnerAndSSHServer(t, clientKey, clientKeyFile)
+	defer cancel()
+	defer runner.Close()
+
+	var stdout, stderr bytes.Buffer
+	assert.NoError(t, runner.Shell("echo hello", strings.NewReader(""), &stdout, &stderr))
+	assert.Equal(t, "hello", stdout.String())
+
+	err = runner.Shell("false", strings.NewReader(""), &stdout, &stderr)
+	status, ok := ExitStatus(err)
+	assert.True(t, ok)
+	assert.Equal(t, 1, status)
+
+	_, ok = ExitStatus(fmt.Errorf("connection refused"))
+	assert.False(t, ok)
+}
+
+func TestStreamPrivilegedCancel(t *testing.T) {
+	dir := t.TempDir()
+	clientKey, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+	clientKeyFile := filepath.Join(dir, "private.key")
+	writePrivateKey(t, clientKeyFile, clientKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	serverCtx, serverCancel := context.WithCancel(context.Background())
+	defer serverCancel()
+	started := make(chan struct{})
+	createSSHServer(serverCtx, t, listener, clientKey, func(input string) (byte, string) {
+		if input == "sudo journalctl --follow" {
+			close(started)
+			// the session ends once the client closes it
+			<-serverCtx.Done()
+		}
+		return 0, ""
+	})
+	runner, err := CreateRunner(ipFor(addr), portFor(addr), clientKeyFile)
+	require.NoError(t, err)
+	defer runner.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := runner.StreamPrivileged(ctx, "following the journal", ioutil.Discard, "journalctl", "--follow")
+		errCh <- err
+	}()
+	<-started
+	cancel()
+	serverCancel()
+	select {
+	case err := <-errCh:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(10 * time.Second):
+		t.Fatal("the command was not ended")
+	}
+}
+
+func TestInstallData(t *testing.T) {
+	dir := t.TempDir()
+	clientKey, err := ecdsa.GenerateKey(elliptic.P521(), rand.Reader)
+	require.NoError(t, err)
+	clientKeyFile := filepath.Join(dir, "private.key")
+	writePrivateKey(t, clientKeyFile, clientKey)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	createSSHServer(ctx, t, listener, clientKey, func(input string) (byte, string) {
+		switch input {
+		case "id -u":
+			return 0, fmt.Sprintf("%d\n", os.Getuid())
+		case "id -g":
+			return 0, fmt.Sprintf("%d\n", os.Getgid())
+		}
+		return 1, ""
+	})
+	runner, err := CreateRunner(ipFor(addr), portFor(addr), clientKeyFile)
+	require.NoError(t, err)
+	defer runner.Close()
+
+	// an existing file is replaced with its permissions
+	hello := filepath.Join(dir, "hello")
+	require.NoError(t, ioutil.WriteFile(hello, []byte("hello"), 0644))
+	assert.NoError(t, runner.InstallData([]byte("hello world"), hello, 0600))
+	content, err := ioutil.ReadFile(hello)
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", string(content))
+	info, err := os.Stat(hello)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.NoFileExists(t, hello+".crc-new")
+
+	missing := filepath.Join(dir, "missing", "hello")
+	err = runner.InstallData([]byte("hello world"), missing, 0600)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot write "+missing+" in the VM")
+}
+
+func TestPrivilegedCommand(t *testing.T) {
+	assert.Equal(t, "sudo cat /etc/shadow", (&Runner{privilegeEscalation: Sudo}).PrivilegedCommand("cat /etc/shadow"))
+	assert.Equal(t, "cat /etc/shadow", (&Runner{privilegeEscalation: NoPrivilegeEscalation}).PrivilegedCommand("cat /etc/shadow"))
+
+	_, err := CreateRunnerForUser(NativeBackend, "root", "doas", "127.0.0.1", 22)
+	assert.EqualError(t, err, "unsupported privilege escalation method 'doas'")
 }
 
 func createListnerAndSSHServer(t *testing.T, clientKey *ecdsa.PrivateKey, clientKeyFile string) (context.CancelFunc, *Runner, *int) {
@@ -66,9 +232,6 @@ func createListnerAndSSHServer(t *testing.T, clientKey *ecdsa.PrivateKey, client
 		if escaped == `"echo hello"` {
 			return 0, "hello"
 		}
-		if escaped == `"sudo install -m 0644 /dev/null /hello && cat <<EOF | base64 --decode | sudo tee /hello\naGVsbG8gd29ybGQ=\nEOF"` {
-			return 0, ""
-		}
 		return 1, fmt.Sprintf("unexpected command: %q", input)
 	})
 	return cancel, runner, totalConn
@@ -135,6 +298,16 @@ func createSSHServer(ctx context.Context, t *testing.T, listener net.Listener, c
 							logrus.Debugf("received command: %s", command)
 							_ = req.Reply(req.Type == "exec", nil)
 
+							if command == sftpServer || command == "sudo "+sftpServer {
+								// files are read and written on the host
+								server, err := sftp.NewServer(channel)
+								require.NoError(t, err)
+								_ = server.Serve()
+								_, _ = channel.SendRequest("exit-status", false, []byte{0, 0, 0, 0})
+								_ = channel.Close()
+								continue
+							}
+
 							ret, out := fun(command)
 							_, _ = channel.Write([]byte(out))
 							_, _ = channel.SendRequest("exit-status", false, []byte{0, 0, 0, ret})
@@ -2,9 +2,13 @@ package telemetry
 
 import (
 	"context"
+	"errors"
+	"net"
+	"os"
 	"os/user"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
 )
@@ -85,6 +89,7 @@ type StartType string
 
 const (
 	AlreadyRunningStartType StartType = "already-running"
+	AdoptedStartType        StartType = "adopted"
 	CreationStartType       StartType = "creation"
 	StartStartType          StartType = "start"
 )
@@ -98,3 +103,61 @@ func SetError(err error) string {
 	withoutHomeDir := strings.ReplaceAll(err.Error(), constants.GetHomeDir(), "$HOME")
 	return strings.ReplaceAll(withoutHomeDir, user.Username, "$USERNAME")
 }
+
+// SetStepResult records the duration of the start step called name, and the
+// step when it failed, so that the steps which fail or take long can be told
+// apart
+func SetStepResult(ctx context.Context, name string, duration time.Duration, err error) {
+	properties := propertiesFromContext(ctx)
+	if properties == nil {
+		return
+	}
+	properties.lock.Lock()
+	defer properties.lock.Unlock()
+	// the map is copied, the values returned earlier may still be in use
+	durations := make(map[string]int64)
+	if previous, ok := properties.storage["step-durations"].(map[string]int64); ok {
+		for step, milliseconds := range previous {
+			durations[step] = milliseconds
+		}
+	}
+	durations[name] = duration.Milliseconds()
+	properties.storage["step-durations"] = durations
+	if err != nil {
+		properties.storage["failed-step"] = name
+	}
+}
+
+type ErrorCategory string
+
+const (
+	CancelledErrorCategory  ErrorCategory = "cancelled"
+	TimeoutErrorCategory    ErrorCategory = "timeout"
+	NetworkErrorCategory    ErrorCategory = "network"
+	PermissionErrorCategory ErrorCategory = "permission"
+	NotFoundErrorCategory   ErrorCategory = "not-found"
+	OtherErrorCategory      ErrorCategory = "other"
+)
+
+// GetErrorCategory returns the kind of failure of err, unlike its message it
+// never contains data about the host
+func GetErrorCategory(err error) ErrorCategory {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return CancelledErrorCategory
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded):
+		return TimeoutErrorCategory
+	case errors.Is(err, os.ErrPermission):
+		return PermissionErrorCategory
+	case errors.Is(err, os.ErrNotExist):
+		return NotFoundErrorCategory
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return TimeoutErrorCategory
+		}
+		return NetworkErrorCategory
+	default:
+		return OtherErrorCategory
+	}
+}
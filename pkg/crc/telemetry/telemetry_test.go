@@ -1,10 +1,14 @@
 package telemetry
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
+	"os"
 	"os/user"
 	"testing"
+	"time"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/stretchr/testify/assert"
@@ -25,3 +29,25 @@ func TestSetError(t *testing.T) {
 	assert.NotEqual(t, err.Error(), SetError(err))
 	assert.NotContains(t, SetError(err), user.Username)
 }
+
+func TestSetStepResult(t *testing.T) {
+	ctx := NewContext(context.Background())
+	SetStepResult(ctx, "start-vm", 12*time.Second, nil)
+	SetStepResult(ctx, "wait-for-api-server", 3*time.Second, errors.New("timed out"))
+	assert.Equal(t, map[string]interface{}{
+		"step-durations": map[string]int64{"start-vm": 12000, "wait-for-api-server": 3000},
+		"failed-step":    "wait-for-api-server",
+	}, GetContextProperties(ctx))
+
+	// nothing is recorded without a telemetry context
+	SetStepResult(context.Background(), "start-vm", time.Second, nil)
+}
+
+func TestGetErrorCategory(t *testing.T) {
+	assert.Equal(t, CancelledErrorCategory, GetErrorCategory(fmt.Errorf("start: %w", context.Canceled)))
+	assert.Equal(t, TimeoutErrorCategory, GetErrorCategory(context.DeadlineExceeded))
+	assert.Equal(t, PermissionErrorCategory, GetErrorCategory(&os.PathError{Op: "open", Path: "/root/.crc", Err: os.ErrPermission}))
+	assert.Equal(t, NotFoundErrorCategory, GetErrorCategory(&os.PathError{Op: "open", Path: "crc.qcow2", Err: os.ErrNotExist}))
+	assert.Equal(t, NetworkErrorCategory, GetErrorCategory(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	assert.Equal(t, OtherErrorCategory, GetErrorCategory(errors.New("this is an error string")))
+}
@@ -0,0 +1,154 @@
+package timeline
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	JSONFileName = "timeline.json"
+	HTMLFileName = "timeline.html"
+
+	schemaVersion = 1
+)
+
+type Timeline struct {
+	SchemaVersion int     `json:"schemaVersion"`
+	Events        []Event `json:"events"`
+}
+
+// Load reads the timeline exported to dir, it is empty when there is none
+func Load(dir string) (*Timeline, error) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, JSONFileName))
+	if os.IsNotExist(err) {
+		return &Timeline{SchemaVersion: schemaVersion}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var timeline Timeline
+	if err := json.Unmarshal(content, &timeline); err != nil {
+		return nil, fmt.Errorf("invalid timeline %s: %w", filepath.Join(dir, JSONFileName), err)
+	}
+	if timeline.SchemaVersion != schemaVersion {
+		return nil, fmt.Errorf("the timeline %s has the unsupported schema version %d", filepath.Join(dir, JSONFileName), timeline.SchemaVersion)
+	}
+	return &timeline, nil
+}
+
+// Save appends events to the timeline exported to dir, so that the commands
+// of a whole session can be recorded in the same directory, and writes its
+// JSON and HTML exports
+func Save(dir string, events []Event) error {
+	timeline, err := Load(dir)
+	if err != nil {
+		return err
+	}
+	timeline.Events = append(timeline.Events, events...)
+	sortEvents(timeline.Events)
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(timeline, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, JSONFileName), content, 0600); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(filepath.Join(dir, HTMLFileName), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	if err := reportTemplate.Execute(file, newReport(timeline)); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+type report struct {
+	Start time.Time
+	Rows  []reportRow
+}
+
+type reportRow struct {
+	Event
+	Offset string
+	// BarPercent is the width of the duration bar, relative to the longest
+	// command or phase
+	BarPercent float64
+}
+
+func newReport(timeline *Timeline) report {
+	var r report
+	if len(timeline.Events) == 0 {
+		return r
+	}
+	r.Start = timeline.Events[0].Time
+	var longest float64
+	for _, event := range timeline.Events {
+		if event.DurationSeconds > longest {
+			longest = event.DurationSeconds
+		}
+	}
+	for _, event := range timeline.Events {
+		row := reportRow{
+			Event:  event,
+			Offset: formatOffset(event.Time.Sub(r.Start)),
+		}
+		if longest > 0 {
+			row.BarPercent = 100 * event.DurationSeconds / longest
+		}
+		r.Rows = append(r.Rows, row)
+	}
+	return r
+}
+
+func formatOffset(offset time.Duration) string {
+	offset = offset.Round(time.Millisecond)
+	return fmt.Sprintf("+%02d:%02d:%06.3f", int(offset.Hours()), int(offset.Minutes())%60, (offset % time.Minute).Seconds())
+}
+
+var reportTemplate = template.Must(template.New("timeline").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>CodeReady Containers timeline</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { text-align: left; vertical-align: top; padding: 2px 8px; border-bottom: 1px solid #eee; }
+td.message { font-family: monospace; white-space: pre-wrap; word-break: break-all; }
+tr.command { background: #dde8f6; font-weight: bold; }
+tr.phase { background: #eef4fb; }
+tr.cluster-event { background: #fff4e0; }
+tr.output td.message { color: #135; }
+tr.debug { color: #888; }
+tr.warning, tr.error { color: #a30; }
+.failed { color: #c00; font-weight: bold; }
+.bar { background: #6a9fd8; height: 10px; min-width: 1px; }
+</style>
+</head>
+<body>
+<h1>CodeReady Containers timeline</h1>
+{{if .Rows}}<p>Recorded from {{.Start.Format "2006-01-02 15:04:05 MST"}}</p>{{end}}
+<table>
+<tr><th>Time</th><th>Kind</th><th>Name</th><th>Message</th><th>Duration</th></tr>
+{{range .Rows}}<tr class="{{.Kind}} {{.Level}}">
+<td title="{{.Time.Format "2006-01-02T15:04:05.000Z07:00"}}">{{.Offset}}</td>
+<td>{{.Kind}}</td>
+<td>{{if .Name}}{{.Name}}{{else}}{{.Level}}{{end}}</td>
+<td class="message">{{.Message}}{{if .Error}}<span class="failed">{{.Error}}</span>{{end}}</td>
+<td>{{if .DurationSeconds}}{{printf "%.1fs" .DurationSeconds}}<div class="bar" style="width: {{printf "%.1f" .BarPercent}}%"></div>{{end}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
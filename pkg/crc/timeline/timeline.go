@@ -0,0 +1,201 @@
+// Package timeline records what happens during a command, the lifecycle
+// phases, the cluster events, the logs and the output, with their time. The
+// timeline is exported as JSON and as an HTML report which can be attached
+// to issues or replayed during workshops.
+package timeline
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+type Kind string
+
+const (
+	CommandKind      Kind = "command"
+	PhaseKind        Kind = "phase"
+	ClusterEventKind Kind = "cluster-event"
+	LogKind          Kind = "log"
+	OutputKind       Kind = "output"
+)
+
+type Event struct {
+	Time    time.Time `json:"time"`
+	Kind    Kind      `json:"kind"`
+	Name    string    `json:"name,omitempty"`
+	Level   string    `json:"level,omitempty"`
+	Message string    `json:"message,omitempty"`
+	// DurationSeconds is set for the commands and the phases, their Time
+	// is the one at which they started
+	DurationSeconds float64 `json:"durationSeconds,omitempty"`
+	Error           string  `json:"error,omitempty"`
+}
+
+type contextKey struct{}
+
+var key = contextKey{}
+
+// Recorder collects the events of a command, it records nothing until it is
+// started. The methods of a nil Recorder do nothing.
+type Recorder struct {
+	lock    sync.Mutex
+	active  bool
+	events  []Event
+	secrets []string
+}
+
+// NewContext returns a context carrying a Recorder which is not started
+func NewContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, key, &Recorder{})
+}
+
+// FromContext returns the Recorder of ctx, nil if it has none
+func FromContext(ctx context.Context) *Recorder {
+	if recorder, ok := ctx.Value(key).(*Recorder); ok {
+		return recorder
+	}
+	return nil
+}
+
+func (r *Recorder) Start() {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.active = true
+}
+
+func (r *Recorder) Active() bool {
+	if r == nil {
+		return false
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.active
+}
+
+// Add records event, at the current time if it has none
+func (r *Recorder) Add(event Event) {
+	if r == nil {
+		return
+	}
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.active {
+		r.events = append(r.events, event)
+	}
+}
+
+// Span records kind called name which just ended after duration
+func (r *Recorder) Span(kind Kind, name string, duration time.Duration, err error) {
+	event := Event{
+		Time:            time.Now().Add(-duration),
+		Kind:            kind,
+		Name:            name,
+		DurationSeconds: duration.Seconds(),
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	r.Add(event)
+}
+
+// Phase records the lifecycle phase called name which just ended
+func (r *Recorder) Phase(name string, duration time.Duration, err error) {
+	r.Span(PhaseKind, name, duration, err)
+}
+
+// Output records a line written by the command
+func (r *Recorder) Output(line string) {
+	r.Add(Event{Kind: OutputKind, Message: line})
+}
+
+// Redact hides secrets in the events returned by Events, including the events
+// recorded before, since a secret can be known only once the command ran
+func (r *Recorder) Redact(secrets ...string) {
+	if r == nil {
+		return
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, secret := range secrets {
+		if secret != "" {
+			r.secrets = append(r.secrets, secret)
+		}
+	}
+}
+
+// Events returns the recorded events, ordered by time
+func (r *Recorder) Events() []Event {
+	if r == nil {
+		return nil
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	events := append([]Event(nil), r.events...)
+	if len(r.secrets) > 0 {
+		redact := redactor(r.secrets)
+		for i := range events {
+			events[i].Name = redact.Replace(events[i].Name)
+			events[i].Message = redact.Replace(events[i].Message)
+			events[i].Error = redact.Replace(events[i].Error)
+		}
+	}
+	sortEvents(events)
+	return events
+}
+
+const redacted = "<redacted>"
+
+// redactor replaces the secrets, the longest ones first so that a secret
+// containing another one is fully hidden
+func redactor(secrets []string) *strings.Replacer {
+	secrets = append([]string(nil), secrets...)
+	sort.SliceStable(secrets, func(i, j int) bool {
+		return len(secrets[i]) > len(secrets[j])
+	})
+	var oldnew []string
+	for _, secret := range secrets {
+		oldnew = append(oldnew, secret, redacted)
+	}
+	return strings.NewReplacer(oldnew...)
+}
+
+func sortEvents(events []Event) {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+}
+
+// LogHook returns a logrus hook recording the log messages of the debug
+// level and above
+func (r *Recorder) LogHook() logrus.Hook {
+	return &logHook{recorder: r}
+}
+
+type logHook struct {
+	recorder *Recorder
+}
+
+func (h *logHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.PanicLevel, logrus.FatalLevel, logrus.ErrorLevel, logrus.WarnLevel, logrus.InfoLevel, logrus.DebugLevel}
+}
+
+func (h *logHook) Fire(entry *logrus.Entry) error {
+	h.recorder.Add(Event{
+		Time:    entry.Time,
+		Kind:    LogKind,
+		Level:   entry.Level.String(),
+		Message: strings.TrimRight(entry.Message, "\n"),
+	})
+	return nil
+}
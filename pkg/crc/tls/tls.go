@@ -277,3 +277,21 @@ func VerifyCertificateAgainstRootCA(ca, certificate string) (bool, error) {
 	}
 	return true, nil
 }
+
+// PemToCert parses the first certificate of a pem string
+func PemToCert(certPem []byte) (*x509.Certificate, error) {
+	block, _ := pem.Decode(certPem)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New("failed to decode certificate PEM")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// PemToPrivateKey parses an RSA private key encoded by PrivateKeyToPem
+func PemToPrivateKey(keyPem []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(keyPem)
+	if block == nil || block.Type != "RSA PRIVATE KEY" {
+		return nil, errors.New("failed to decode private key PEM")
+	}
+	return x509.ParsePKCS1PrivateKey(block.Bytes)
+}
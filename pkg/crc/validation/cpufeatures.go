@@ -0,0 +1,80 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// x86-64 microarchitecture levels of the x86-64 psABI, with the flag names
+// of /proc/cpuinfo
+var microarchitectureLevels = map[string][]string{
+	"x86-64-v2": levelV2Flags,
+	"x86-64-v3": append(append([]string{}, levelV2Flags...), levelV3Flags...),
+	"x86-64-v4": append(append(append([]string{}, levelV2Flags...), levelV3Flags...), levelV4Flags...),
+}
+
+var (
+	levelV2Flags = []string{"cx16", "lahf_lm", "popcnt", "pni", "sse4_1", "sse4_2", "ssse3"}
+	levelV3Flags = []string{"avx", "avx2", "bmi1", "bmi2", "f16c", "fma", "abm", "movbe", "xsave"}
+	levelV4Flags = []string{"avx512f", "avx512bw", "avx512cd", "avx512dq", "avx512vl"}
+)
+
+// cpuFeatures are the features of the host CPU
+type cpuFeatures struct {
+	present map[string]bool
+	// the features which can be detected on this host, nil when all of them can
+	detectable map[string]bool
+}
+
+// lacks returns true when the CPU is known not to have flag
+func (features cpuFeatures) lacks(flag string) bool {
+	if features.detectable != nil && !features.detectable[flag] {
+		return false
+	}
+	return !features.present[flag]
+}
+
+// ValidateCPUFeatures checks that the host CPU has the features a bundle
+// requires, the features which cannot be detected on the host are assumed to
+// be present
+func ValidateCPUFeatures(required []string) error {
+	if len(required) == 0 {
+		return nil
+	}
+	features, err := hostCPUFeatures()
+	if err != nil {
+		return err
+	}
+	if missing := missingCPUFeatures(features, required); len(missing) > 0 {
+		return fmt.Errorf("The CPU of this host lacks features required by the bundle: %s. The virtual machine would crash while booting", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func missingCPUFeatures(features cpuFeatures, required []string) []string {
+	var missing []string
+	for _, requirement := range required {
+		if flags, isLevel := microarchitectureLevels[requirement]; isLevel {
+			var lacking []string
+			for _, flag := range flags {
+				if features.lacks(flag) {
+					lacking = append(lacking, flag)
+				}
+			}
+			if len(lacking) > 0 {
+				missing = append(missing, fmt.Sprintf("%s (%s)", requirement, strings.Join(lacking, " ")))
+			}
+			continue
+		}
+		lacksAll := true
+		for _, flag := range strings.Split(requirement, "|") {
+			if !features.lacks(flag) {
+				lacksAll = false
+			}
+		}
+		if lacksAll {
+			missing = append(missing, requirement)
+		}
+	}
+	return missing
+}
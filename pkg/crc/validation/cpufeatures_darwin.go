@@ -0,0 +1,36 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	crcos "github.com/code-ready/crc/pkg/os"
+)
+
+// sysctl names of the CPU features which differ from the /proc/cpuinfo ones
+var sysctlCPUFeatureAliases = map[string]string{
+	"avx1_0": "avx",
+	"lahf":   "lahf_lm",
+	"lzcnt":  "abm",
+	"sse3":   "pni",
+}
+
+func hostCPUFeatures() (cpuFeatures, error) {
+	stdout, stderr, err := crcos.RunWithDefaultLocale("sysctl", "-n", "machdep.cpu.features", "machdep.cpu.leaf7_features", "machdep.cpu.extfeatures")
+	if err != nil {
+		return cpuFeatures{}, fmt.Errorf("Failed to get the CPU features %v: %s", err, stderr)
+	}
+	return parseSysctlCPUFeatures(stdout), nil
+}
+
+func parseSysctlCPUFeatures(output string) cpuFeatures {
+	features := cpuFeatures{present: map[string]bool{}}
+	for _, feature := range strings.Fields(output) {
+		flag := strings.ReplaceAll(strings.ToLower(feature), ".", "_")
+		if alias, ok := sysctlCPUFeatureAliases[flag]; ok {
+			flag = alias
+		}
+		features.present[flag] = true
+	}
+	return features
+}
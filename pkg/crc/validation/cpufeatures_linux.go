@@ -0,0 +1,31 @@
+package validation
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+func hostCPUFeatures() (cpuFeatures, error) {
+	cpuinfo, err := ioutil.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return cpuFeatures{}, fmt.Errorf("Failed to read /proc/cpuinfo: %w", err)
+	}
+	return parseCPUInfoFlags(string(cpuinfo))
+}
+
+// parseCPUInfoFlags returns the flags of the first CPU of /proc/cpuinfo
+func parseCPUInfoFlags(cpuinfo string) (cpuFeatures, error) {
+	for _, line := range strings.Split(cpuinfo, "\n") {
+		fields := strings.SplitN(line, ":", 2)
+		if len(fields) != 2 || strings.TrimSpace(fields[0]) != "flags" {
+			continue
+		}
+		features := cpuFeatures{present: map[string]bool{}}
+		for _, flag := range strings.Fields(fields[1]) {
+			features.present[flag] = true
+		}
+		return features, nil
+	}
+	return cpuFeatures{}, fmt.Errorf("Could not find cpu flags from /proc/cpuinfo")
+}
@@ -0,0 +1,20 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCPUInfoFlags(t *testing.T) {
+	cpuinfo := "processor\t: 0\nmodel name\t: Intel(R) Core(TM) i7\nflags\t\t: fpu vme sse4_2 vmx\nbugs\t\t: spectre_v1\n"
+	features, err := parseCPUInfoFlags(cpuinfo)
+	require.NoError(t, err)
+	assert.False(t, features.lacks("sse4_2"))
+	assert.False(t, features.lacks("vmx"))
+	assert.True(t, features.lacks("avx"))
+
+	_, err = parseCPUInfoFlags("processor\t: 0\n")
+	assert.Error(t, err)
+}
@@ -0,0 +1,30 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func features(flags ...string) cpuFeatures {
+	present := map[string]bool{}
+	for _, flag := range flags {
+		present[flag] = true
+	}
+	return cpuFeatures{present: present}
+}
+
+func TestMissingCPUFeatures(t *testing.T) {
+	v2 := features("cx16", "lahf_lm", "popcnt", "pni", "sse4_1", "sse4_2", "ssse3", "vmx")
+	assert.Empty(t, missingCPUFeatures(v2, []string{"x86-64-v2", "vmx|svm", "sse4_2"}))
+
+	old := features("cx16", "lahf_lm", "pni", "ssse3", "svm")
+	assert.Equal(t, []string{"x86-64-v2 (popcnt sse4_1 sse4_2)", "avx"},
+		missingCPUFeatures(old, []string{"x86-64-v2", "vmx|svm", "avx"}))
+}
+
+func TestMissingCPUFeaturesUndetectable(t *testing.T) {
+	partial := features("avx")
+	partial.detectable = map[string]bool{"avx": true, "avx2": true}
+	assert.Equal(t, []string{"avx2"}, missingCPUFeatures(partial, []string{"avx", "avx2", "movbe", "vmx|svm"}))
+}
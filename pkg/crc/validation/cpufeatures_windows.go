@@ -0,0 +1,36 @@
+package validation
+
+import (
+	"golang.org/x/sys/cpu"
+)
+
+// hostCPUFeatures only knows the features golang.org/x/sys/cpu detects,
+// Windows does not list the CPU flags
+func hostCPUFeatures() (cpuFeatures, error) {
+	present := map[string]bool{
+		"pni":      cpu.X86.HasSSE3,
+		"ssse3":    cpu.X86.HasSSSE3,
+		"sse4_1":   cpu.X86.HasSSE41,
+		"sse4_2":   cpu.X86.HasSSE42,
+		"popcnt":   cpu.X86.HasPOPCNT,
+		"cx16":     cpu.X86.HasCX16,
+		"avx":      cpu.X86.HasAVX,
+		"avx2":     cpu.X86.HasAVX2,
+		"bmi1":     cpu.X86.HasBMI1,
+		"bmi2":     cpu.X86.HasBMI2,
+		"fma":      cpu.X86.HasFMA,
+		"avx512f":  cpu.X86.HasAVX512F,
+		"avx512bw": cpu.X86.HasAVX512BW,
+		"avx512cd": cpu.X86.HasAVX512CD,
+		"avx512dq": cpu.X86.HasAVX512DQ,
+		"avx512vl": cpu.X86.HasAVX512VL,
+	}
+	detectable := map[string]bool{}
+	for flag := range present {
+		detectable[flag] = true
+	}
+	return cpuFeatures{
+		present:    present,
+		detectable: detectable,
+	}, nil
+}
@@ -0,0 +1,54 @@
+// +build linux
+
+package validation
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/os/linux"
+	"github.com/pbnjay/memory"
+)
+
+var (
+	hostResourcesOnce sync.Once
+	hostMemory        uint64
+	hostCPUs          int
+)
+
+// readHostResources reads the resources of the host and the cgroup limits
+// once, the validators run each time a setting is read
+func readHostResources() {
+	hostResourcesOnce.Do(func() {
+		hostMemory = memory.TotalMemory()
+		if limit, err := linux.CgroupMemoryLimit(); err != nil {
+			logging.Debugf("Cannot get cgroup memory limit: %v", err)
+		} else if limit != 0 && limit < hostMemory {
+			logging.Debugf("Memory is limited to %d bytes by cgroup", limit)
+			hostMemory = limit
+		}
+
+		hostCPUs = runtime.NumCPU()
+		if limit, err := linux.CgroupCPULimit(); err != nil {
+			logging.Debugf("Cannot get cgroup CPU limit: %v", err)
+		} else if limit != 0 && limit < hostCPUs {
+			logging.Debugf("CPUs are limited to %d by cgroup", limit)
+			hostCPUs = limit
+		}
+	})
+}
+
+// HostTotalMemory returns the memory available to crc in bytes, taking into
+// account the cgroup limits when crc runs in a container or a CI job
+func HostTotalMemory() uint64 {
+	readHostResources()
+	return hostMemory
+}
+
+// HostCPUs returns the number of CPUs available to crc, taking into account
+// the cgroup CPU quota when crc runs in a container or a CI job
+func HostCPUs() int {
+	readHostResources()
+	return hostCPUs
+}
@@ -0,0 +1,19 @@
+// +build !linux
+
+package validation
+
+import (
+	"runtime"
+
+	"github.com/pbnjay/memory"
+)
+
+// HostTotalMemory returns the memory available to crc in bytes
+func HostTotalMemory() uint64 {
+	return memory.TotalMemory()
+}
+
+// HostCPUs returns the number of CPUs available to crc
+func HostCPUs() int {
+	return runtime.NumCPU()
+}
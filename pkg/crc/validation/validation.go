@@ -8,12 +8,12 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/code-ready/crc/pkg/crc/constants"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/machine/bundle"
 	"github.com/docker/go-units"
-	"github.com/pbnjay/memory"
 )
 
 // ValidateCPUs checks if provided cpus count is valid
@@ -21,6 +21,9 @@ func ValidateCPUs(value int) error {
 	if value < constants.DefaultCPUs {
 		return fmt.Errorf("requires CPUs >= %d", constants.DefaultCPUs)
 	}
+	if hostCPUs := HostCPUs(); value > hostCPUs {
+		logging.Warnf("%d CPUs requested but only %d are available, the VM will be overcommitted", value, hostCPUs)
+	}
 	return nil
 }
 
@@ -42,7 +45,7 @@ func ValidateDiskSize(value int) error {
 
 // ValidateEnoughMemory checks if enough memory is installed on the host
 func ValidateEnoughMemory(value int) error {
-	totalMemory := memory.TotalMemory()
+	totalMemory := HostTotalMemory()
 	logging.Debugf("Total memory of system is %d bytes", totalMemory)
 	valueBytes := value * 1024 * 1024
 	if totalMemory < uint64(valueBytes) {
@@ -85,6 +88,14 @@ func ValidateBundle(bundlePath string) error {
 	bundleName := filepath.Base(bundlePath)
 	_, err := bundle.Get(bundleName)
 	if err != nil {
+		if bundlePath == constants.DefaultBundlePath && constants.BundleEmbedded() {
+			logging.Warnf("Cannot use the extracted bundle %s (%v), it will be extracted again from the crc executable", bundleName, err)
+			return nil
+		}
+		if sourceInfo, _ := bundle.GetSourceInfo(bundleName); sourceInfo != nil && ValidatePath(bundlePath) != nil {
+			return fmt.Errorf("The extracted bundle %s is invalid (%v) and %s was deleted on %s as per the '%s' bundle cleanup policy, please download it again",
+				bundleName, err, sourceInfo.Path, sourceInfo.RemovedAt.Format(time.RFC1123), sourceInfo.Policy)
+		}
 		return ValidateBundlePath(bundlePath)
 	}
 	/* 'bundle' is already unpacked in ~/.crc/cache */
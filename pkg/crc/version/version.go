@@ -3,11 +3,8 @@ package version
 import (
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
-	"net/http"
 	"os"
 	"path/filepath"
-	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/code-ready/crc/pkg/crc/logging"
@@ -33,7 +30,7 @@ var (
 )
 
 const (
-	releaseInfoLink = "https://developers.redhat.com/content-gateway/rest/mirror/pub/openshift-v4/clients/crc/latest/release-info.json"
+	ReleaseInfoLink = "https://developers.redhat.com/content-gateway/rest/mirror/pub/openshift-v4/clients/crc/latest/release-info.json"
 	// Tray version to be embedded in executable
 	crcMacTrayVersion = "1.0.12"
 	// Windows forms application version type major.minor.buildnumber.revesion
@@ -99,31 +96,8 @@ func InstallPath() string {
 	return filepath.Dir(src)
 }
 
-func GetCRCLatestVersionFromMirror(transport http.RoundTripper) (*CrcReleaseInfo, error) {
-	client := &http.Client{
-		Timeout:   5 * time.Second,
-		Transport: transport,
-	}
-	req, err := http.NewRequest(http.MethodGet, releaseInfoLink, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("User-Agent", fmt.Sprintf("crc/%s", crcVersion))
-	response, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer response.Body.Close()
-
-	if response.StatusCode != 200 {
-		return nil, fmt.Errorf("HTTP error: %s: %d", response.Status, response.StatusCode)
-	}
-
-	releaseMetaData, err := ioutil.ReadAll(response.Body)
-	if err != nil {
-		return nil, err
-	}
-
+// ParseReleaseInfo parses the release metadata published at ReleaseInfoLink
+func ParseReleaseInfo(releaseMetaData []byte) (*CrcReleaseInfo, error) {
 	var releaseInfo CrcReleaseInfo
 	if err := json.Unmarshal(releaseMetaData, &releaseInfo); err != nil {
 		return nil, fmt.Errorf("Error unmarshaling JSON metadata: %v", err)
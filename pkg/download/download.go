@@ -1,35 +1,388 @@
 package download
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"strings"
+	"sync/atomic"
+	"time"
 
+	crcerrors "github.com/code-ready/crc/pkg/crc/errors"
 	"github.com/code-ready/crc/pkg/crc/logging"
 	"github.com/code-ready/crc/pkg/crc/network"
+	"github.com/code-ready/crc/pkg/crc/version"
 
 	"github.com/cavaliercoder/grab"
 	"github.com/pkg/errors"
 )
 
+// Downloader fetches files over HTTP using the proxy configuration and the
+// proxy CA of crc. Failed transfers are retried and resumed, and mirrors are
+// tried in order until one of them succeeds.
+type Downloader struct {
+	Transport http.RoundTripper
+	UserAgent string
+	// number of attempts made with each mirror
+	Attempts   int
+	RetryDelay time.Duration
+	// limit of the duration of the InMemory requests, 0 means no limit.
+	// Downloads to files are not limited, bundles take a long time to fetch.
+	Timeout time.Duration
+	// Progress is called every ProgressInterval during the downloads to
+	// files and once they end, total is 0 when the size is unknown
+	Progress         func(complete, total int64)
+	ProgressInterval time.Duration
+}
+
+func New() *Downloader {
+	return &Downloader{
+		Transport:        network.HTTPTransport(),
+		UserAgent:        fmt.Sprintf("crc/%s", version.GetCRCVersion()),
+		Attempts:         3,
+		RetryDelay:       2 * time.Second,
+		ProgressInterval: 5 * time.Second,
+	}
+}
+
 func Download(uri, destination string, mode os.FileMode) (string, error) {
-	logging.Debugf("Downloading %s to %s", uri, destination)
+	return New().Download(destination, mode, uri)
+}
+
+// InMemory returns the content of uri, it is meant for small documents such
+// as the release metadata
+func InMemory(uri string) ([]byte, error) {
+	return New().InMemory(uri)
+}
+
+// Download fetches the file from the first mirror which succeeds and saves it
+// to destination, a file or a directory, with mode. A partial file left by a
+// previous attempt is resumed when the server supports it.
+func (d *Downloader) Download(destination string, mode os.FileMode, mirrors ...string) (string, error) {
+	return d.DownloadWithChecksum(destination, mode, "", mirrors...)
+}
 
+// DownloadWithChecksum downloads the file like Download and verifies that its
+// content has the hex-encoded sha256sum, the file is removed when it does not
+func (d *Downloader) DownloadWithChecksum(destination string, mode os.FileMode, sha256sum string, mirrors ...string) (string, error) {
+	var checksum []byte
+	if sha256sum != "" {
+		var err error
+		if checksum, err = hex.DecodeString(sha256sum); err != nil {
+			return "", errors.Wrapf(err, "invalid sha256 checksum %s", sha256sum)
+		}
+	}
 	client := grab.NewClient()
-	client.HTTPClient.Transport = network.HTTPTransport()
-	req, err := grab.NewRequest(destination, uri)
+	client.HTTPClient.Transport = d.Transport
+	client.UserAgent = d.UserAgent
+
+	var filename string
+	err := d.fromMirrors(mirrors, func(uri string) error {
+		logging.Debugf("Downloading %s to %s", uri, destination)
+		req, err := grab.NewRequest(destination, uri)
+		if err != nil {
+			return errors.Wrapf(err, "unable to get response from %s", uri)
+		}
+		if checksum != nil {
+			req.SetChecksum(sha256.New(), checksum, true)
+		}
+		resp := client.Do(req)
+		d.reportProgress(resp)
+		if err := resp.Err(); err != nil {
+			return errors.Wrapf(err, "download of %s failed", uri)
+		}
+		filename = resp.Filename
+		return nil
+	})
 	if err != nil {
-		return "", errors.Wrapf(err, "unable to get response from %s", uri)
+		return "", err
 	}
 
-	resp := client.Do(req)
-	if err := resp.Err(); err != nil {
-		return "", errors.Wrapf(err, "download of %s failed", uri)
+	if err := os.Chmod(filename, mode); err != nil {
+		_ = os.Remove(filename)
+		return "", err
 	}
+	logging.Debugf("Download saved to %v", filename)
+	return filename, nil
+}
 
-	if err := os.Chmod(resp.Filename, mode); err != nil {
-		_ = os.Remove(resp.Filename)
-		return "", err
+// reportProgress calls Progress until the transfer of resp ends
+func (d *Downloader) reportProgress(resp *grab.Response) {
+	d.progressLoop(resp.BytesComplete, resp.Size, resp.Done)
+}
+
+// progressLoop calls Progress with the value of complete until done is closed
+func (d *Downloader) progressLoop(complete func() int64, total int64, done <-chan struct{}) {
+	if d.Progress == nil {
+		return
+	}
+	ticker := time.NewTicker(d.ProgressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			d.Progress(complete(), total)
+		case <-done:
+			d.Progress(complete(), total)
+			return
+		}
+	}
+}
+
+// Stream passes the content of the file from the first mirror which succeeds
+// to consume while it is downloaded, without saving it. consume is called
+// again from the start when the transfer fails and is retried, its other
+// errors are returned without retrying.
+func (d *Downloader) Stream(consume func(reader io.Reader) error, mirrors ...string) error {
+	client := &http.Client{
+		Transport: d.Transport,
+	}
+	return d.fromMirrors(mirrors, func(uri string) error {
+		logging.Debugf("Streaming %s", uri)
+		req, err := http.NewRequest(http.MethodGet, uri, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("User-Agent", d.UserAgent)
+		response, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+		if response.StatusCode < 200 || response.StatusCode > 299 {
+			return grab.StatusCodeError(response.StatusCode)
+		}
+
+		body := &bodyReader{reader: response.Body}
+		done := make(chan struct{})
+		progressDone := make(chan struct{})
+		go func() {
+			defer close(progressDone)
+			d.progressLoop(body.complete, response.ContentLength, done)
+		}()
+		err = consume(body)
+		close(done)
+		<-progressDone
+
+		var transferErr *transferError
+		if err != nil && !errors.As(err, &transferErr) {
+			return &permanentError{err: err}
+		}
+		return err
+	})
+}
+
+// bodyReader counts the bytes read from a response body, and marks its
+// errors as transfer errors
+type bodyReader struct {
+	reader io.Reader
+	read   int64
+}
+
+func (r *bodyReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	atomic.AddInt64(&r.read, int64(n))
+	if err != nil && err != io.EOF {
+		return n, &transferError{err: err}
+	}
+	return n, err
+}
+
+func (r *bodyReader) complete() int64 {
+	return atomic.LoadInt64(&r.read)
+}
+
+// transferError is a failure to read a response body, another attempt may succeed
+type transferError struct {
+	err error
+}
+
+func (e *transferError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transferError) Unwrap() error {
+	return e.err
+}
+
+// permanentError is an error which another attempt cannot fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Open sends req, a request without body, and retries it like the downloads
+// when it fails or the server is unavailable. The response is returned
+// whatever its status code is. The transfer of the body of a successful
+// response is resumed with a range request when it is interrupted, and its
+// progress is reported like the downloads to files.
+func (d *Downloader) Open(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", d.UserAgent)
+	}
+	client := &http.Client{
+		Transport: d.Transport,
 	}
+	for attempt := 1; ; attempt++ {
+		resp, err := client.Do(req)
+		if attempt >= d.Attempts || (err == nil && !retriableStatusCode(resp.StatusCode)) {
+			if err == nil && resp.StatusCode == http.StatusOK {
+				resp.Body = &resumingReader{
+					downloader: d,
+					client:     client,
+					req:        req,
+					body:       resp.Body,
+					total:      resp.ContentLength,
+					reported:   time.Now(),
+				}
+			}
+			return resp, err
+		}
+		if err == nil {
+			resp.Body.Close()
+			err = grab.StatusCodeError(resp.StatusCode)
+		}
+		logging.Debugf("Cannot fetch %s, retrying in %s: %v", req.URL, d.RetryDelay, err)
+		time.Sleep(d.RetryDelay)
+	}
+}
+
+// resumingReader reads a response body, the transfer is resumed from where
+// it stopped when it fails, up to Attempts times in a row
+type resumingReader struct {
+	downloader *Downloader
+	client     *http.Client
+	req        *http.Request
+	body       io.ReadCloser
+	read       int64
+	total      int64
+	failures   int
+	reported   time.Time
+}
+
+func (r *resumingReader) Read(p []byte) (int, error) {
+	n, err := r.body.Read(p)
+	r.read += int64(n)
+	if n > 0 {
+		r.failures = 0
+	}
+	if err != nil && err != io.EOF && r.failures+1 < r.downloader.Attempts {
+		r.failures++
+		logging.Debugf("Transfer of %s interrupted after %d bytes, resuming in %s: %v", r.req.URL, r.read, r.downloader.RetryDelay, err)
+		time.Sleep(r.downloader.RetryDelay)
+		resumeErr := r.resume()
+		if resumeErr == nil {
+			return n, nil
+		}
+		logging.Debugf("Cannot resume the transfer of %s: %v", r.req.URL, resumeErr)
+	}
+	if d := r.downloader; d.Progress != nil && (err == io.EOF || time.Since(r.reported) >= d.ProgressInterval) {
+		d.Progress(r.read, r.total)
+		r.reported = time.Now()
+	}
+	return n, err
+}
+
+// resume requests the rest of the body, the original request is sent again
+// so that its redirections are followed again
+func (r *resumingReader) resume() error {
+	req := r.req.Clone(r.req.Context())
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", r.read))
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusPartialContent || !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", r.read)) {
+		resp.Body.Close()
+		return fmt.Errorf("the server does not support resuming the transfer: %s", resp.Status)
+	}
+	r.body.Close()
+	r.body = resp.Body
+	return nil
+}
+
+func (r *resumingReader) Close() error {
+	return r.body.Close()
+}
+
+// InMemory returns the content of the document from the first mirror which
+// succeeds
+func (d *Downloader) InMemory(mirrors ...string) ([]byte, error) {
+	client := &http.Client{
+		Timeout:   d.Timeout,
+		Transport: d.Transport,
+	}
+	var content []byte
+	err := d.fromMirrors(mirrors, func(uri string) error {
+		req, err := http.NewRequest(http.MethodGet, uri, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("User-Agent", d.UserAgent)
+		response, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer response.Body.Close()
+		if response.StatusCode < 200 || response.StatusCode > 299 {
+			return grab.StatusCodeError(response.StatusCode)
+		}
+		content, err = ioutil.ReadAll(response.Body)
+		return err
+	})
+	return content, err
+}
+
+// fromMirrors calls fetch with each mirror until it succeeds, the mirrors
+// are retried Attempts times unless their error is permanent
+func (d *Downloader) fromMirrors(mirrors []string, fetch func(uri string) error) error {
+	if len(mirrors) == 0 {
+		return errors.New("no URL to download from")
+	}
+	var errs crcerrors.MultiError
+	for _, uri := range mirrors {
+		for attempt := 1; ; attempt++ {
+			err := fetch(uri)
+			if err == nil {
+				return nil
+			}
+			if attempt >= d.Attempts || !isRetriable(err) {
+				logging.Debugf("Cannot fetch %s: %v", uri, err)
+				errs.Collect(err)
+				break
+			}
+			logging.Debugf("Cannot fetch %s, retrying in %s: %v", uri, d.RetryDelay, err)
+			time.Sleep(d.RetryDelay)
+		}
+	}
+	return errs
+}
+
+// isRetriable is false for the errors which another attempt cannot fix, the
+// next mirror may still succeed
+func isRetriable(err error) bool {
+	var permanentErr *permanentError
+	if errors.As(err, &permanentErr) {
+		return false
+	}
+	var statusCode grab.StatusCodeError
+	if errors.As(err, &statusCode) {
+		return retriableStatusCode(int(statusCode))
+	}
+	return !errors.Is(err, grab.ErrBadChecksum) && !errors.Is(err, grab.ErrNoFilename)
+}
 
-	logging.Debugf("Download saved to %v", resp.Filename)
-	return resp.Filename, nil
+func retriableStatusCode(statusCode int) bool {
+	return statusCode >= 500 || statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests
 }
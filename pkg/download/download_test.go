@@ -0,0 +1,197 @@
+package download
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDownloader() *Downloader {
+	return &Downloader{
+		Transport: http.DefaultTransport,
+		UserAgent: "crc/test",
+		Attempts:  3,
+	}
+}
+
+func TestDownloadRetries(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "bundle")
+	}))
+	defer server.Close()
+
+	filename, err := testDownloader().Download(filepath.Join(t.TempDir(), "crc.crcbundle"), 0600, server.URL+"/crc.crcbundle")
+	require.NoError(t, err)
+	assert.Equal(t, 3, requests)
+	content, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	assert.Equal(t, "bundle", string(content))
+}
+
+func TestDownloadMirrorFailover(t *testing.T) {
+	missingRequests := 0
+	missing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		missingRequests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer missing.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "crc/test", r.Header.Get("User-Agent"))
+		fmt.Fprint(w, `{"version": {}}`)
+	}))
+	defer mirror.Close()
+
+	content, err := testDownloader().InMemory(missing.URL, mirror.URL)
+	require.NoError(t, err)
+	assert.Equal(t, `{"version": {}}`, string(content))
+	// a missing file is not retried
+	assert.Equal(t, 1, missingRequests)
+}
+
+func TestDownloadAllMirrorsFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	_, err := testDownloader().InMemory(server.URL+"/a", server.URL+"/b")
+	assert.EqualError(t, err, "server returned 403 Forbidden (x2)")
+	_, err = testDownloader().InMemory()
+	assert.Error(t, err)
+}
+
+func TestDownloadWithChecksum(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "bundle")
+	}))
+	defer server.Close()
+
+	downloader := testDownloader()
+	var complete, total int64
+	downloader.Progress = func(c, t int64) {
+		complete, total = c, t
+	}
+	downloader.ProgressInterval = time.Second
+	destination := filepath.Join(t.TempDir(), "crc.crcbundle")
+	// sha256 of 'bundle'
+	_, err := downloader.DownloadWithChecksum(destination, 0600, "6ecea7b1c4a8e7ea0a8ee0d8ad3a17a0bdee1c4eb4aad3ae47e0e43b3d0ed8a0", server.URL+"/crc.crcbundle")
+	assert.Error(t, err)
+	assert.NoFileExists(t, destination)
+
+	sum := sha256.Sum256([]byte("bundle"))
+	filename, err := downloader.DownloadWithChecksum(destination, 0600, hex.EncodeToString(sum[:]), server.URL+"/crc.crcbundle")
+	require.NoError(t, err)
+	assert.Equal(t, destination, filename)
+	assert.Equal(t, int64(6), complete)
+	assert.Equal(t, int64(6), total)
+}
+
+func TestStream(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, "bundle")
+	}))
+	defer server.Close()
+
+	var consumed []string
+	err := testDownloader().Stream(func(reader io.Reader) error {
+		content, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return err
+		}
+		consumed = append(consumed, string(content))
+		if len(consumed) == 1 {
+			return &transferError{err: errors.New("connection reset")}
+		}
+		return nil
+	}, server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, []string{"bundle", "bundle"}, consumed)
+
+	requests = 0
+	err = testDownloader().Stream(func(reader io.Reader) error {
+		return errors.New("invalid checksum")
+	}, server.URL)
+	assert.EqualError(t, err, "invalid checksum")
+	assert.Equal(t, 1, requests)
+}
+
+func TestOpenResumesTransfer(t *testing.T) {
+	var ranges []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		ranges = append(ranges, r.Header.Get("Range"))
+		switch r.Header.Get("Range") {
+		case "":
+			if len(ranges) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			// the connection is closed before the end of the body
+			w.Header().Set("Content-Length", "12")
+			fmt.Fprint(w, "crc.qc")
+		case "bytes=6-":
+			w.Header().Set("Content-Range", "bytes 6-11/12")
+			w.WriteHeader(http.StatusPartialContent)
+			fmt.Fprint(w, "ow2...")
+		default:
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+		}
+	}))
+	defer server.Close()
+
+	downloader := testDownloader()
+	var progress []int64
+	downloader.Progress = func(complete, total int64) {
+		assert.Equal(t, int64(12), total)
+		progress = append(progress, complete)
+	}
+	downloader.ProgressInterval = time.Hour
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer token")
+	resp, err := downloader.Open(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	content, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "crc.qcow2...", string(content))
+	assert.Equal(t, []string{"", "", "bytes=6-"}, ranges)
+	assert.Equal(t, []int64{12}, progress)
+}
+
+func TestOpenReturnsErrorResponses(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	resp, err := testDownloader().Open(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Equal(t, 1, requests)
+}
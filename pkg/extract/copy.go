@@ -0,0 +1,78 @@
+package extract
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+const (
+	copyChunkSize = 4 * 1024 * 1024
+	// number of chunks read ahead of the disk writes
+	copyQueueLength = 8
+)
+
+var zeroChunk = make([]byte, copyChunkSize)
+
+type chunk struct {
+	data []byte
+	err  error
+}
+
+// parallelCopy copies src to dst with the reads, which decompress the
+// archive, done in their own goroutine so that they overlap with the disk
+// writes. The chunks of zeros are skipped to create a sparse file, disk
+// images are mostly empty.
+func parallelCopy(dst *os.File, src io.Reader) (int64, error) {
+	chunks := make(chan chunk, copyQueueLength)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		defer close(chunks)
+		for {
+			buf := make([]byte, copyChunkSize)
+			n, err := io.ReadFull(src, buf)
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				err = nil
+			}
+			if n > 0 || err != nil {
+				select {
+				case chunks <- chunk{data: buf[:n], err: err}:
+				case <-done:
+					return
+				}
+			}
+			if n < copyChunkSize || err != nil {
+				return
+			}
+		}
+	}()
+
+	var written int64
+	sparse := false
+	for c := range chunks {
+		if c.err != nil {
+			return written, c.err
+		}
+		if bytes.Equal(c.data, zeroChunk[:len(c.data)]) {
+			if _, err := dst.Seek(int64(len(c.data)), io.SeekCurrent); err != nil {
+				return written, err
+			}
+			sparse = true
+		} else {
+			if _, err := dst.Write(c.data); err != nil {
+				return written, err
+			}
+			sparse = false
+		}
+		written += int64(len(c.data))
+	}
+	// a file ending with zeros is extended up to its size
+	if sparse {
+		if err := dst.Truncate(written); err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,31 @@
+package extract
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelCopy(t *testing.T) {
+	data := make([]byte, 3*copyChunkSize+100)
+	copy(data[copyChunkSize:], bytes.Repeat([]byte("crc"), 1000))
+
+	for _, content := range [][]byte{data, data[:2*copyChunkSize], data[copyChunkSize : 2*copyChunkSize+10], {}} {
+		path := filepath.Join(t.TempDir(), "disk.img")
+		file, err := os.Create(path)
+		require.NoError(t, err)
+		written, err := parallelCopy(file, bytes.NewReader(content))
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+		assert.Equal(t, int64(len(content)), written)
+
+		copied, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(content, copied))
+	}
+}
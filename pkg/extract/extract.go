@@ -3,11 +3,13 @@ package extract
 import (
 	"archive/tar"
 	"archive/zip"
+	"bufio"
 	"compress/gzip"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/cheggaaa/pb/v3"
@@ -15,7 +17,6 @@ import (
 	"github.com/h2non/filetype"
 	"github.com/klauspost/compress/zstd"
 	"github.com/pkg/errors"
-	"github.com/xi2/xz"
 	terminal "golang.org/x/term"
 )
 
@@ -54,32 +55,61 @@ func uncompress(tarball, targetDir string, fileFilter func(string) bool, showPro
 		return nil, errors.Wrap(err, "cannot seek file")
 	}
 
+	if filetype.Is(header, "zip") {
+		return unzip(tarball, targetDir, fileFilter, showProgress)
+	}
+	extractedFiles, err := uncompressReader(file, header, targetDir, fileFilter, showProgress)
+	if errors.Is(err, errUnknownFormat) {
+		return nil, fmt.Errorf("Unknown file format when trying to uncompress %s", tarball)
+	}
+	return extractedFiles, err
+}
+
+// UncompressStream extracts the compressed tarball read from reader, for
+// instance while it is downloaded
+func UncompressStream(reader io.Reader, targetDir string) ([]string, error) {
+	logging.Debugf("Uncompressing stream to %s", targetDir)
+	bufferedReader := bufio.NewReader(reader)
+	header, err := bufferedReader.Peek(262)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "cannot determine type by reading stream header")
+	}
+	extractedFiles, err := uncompressReader(bufferedReader, header, targetDir, nil, false)
+	if errors.Is(err, errUnknownFormat) {
+		return nil, errors.New("Unknown file format when trying to uncompress stream")
+	}
+	return extractedFiles, err
+}
+
+var errUnknownFormat = errors.New("unknown file format")
+
+func uncompressReader(reader io.Reader, header []byte, targetDir string, fileFilter func(string) bool, showProgress bool) ([]string, error) {
 	switch {
 	case filetype.Is(header, "xz"):
-		reader, err := xz.NewReader(file, 0)
+		xzReader, err := newXZReader(reader)
 		if err != nil {
 			return nil, err
 		}
-		return untar(reader, targetDir, fileFilter, showProgress)
+		defer xzReader.Close()
+		return untar(xzReader, targetDir, fileFilter, showProgress)
 	case filetype.Is(header, "zst"):
-		reader, err := zstd.NewReader(file)
+		zstdReader, err := zstd.NewReader(reader, zstd.WithDecoderConcurrency(runtime.NumCPU()), zstd.WithDecoderLowmem(false))
 		if err != nil {
 			return nil, err
 		}
-		return untar(reader, targetDir, fileFilter, showProgress)
+		defer zstdReader.Close()
+		return untar(zstdReader, targetDir, fileFilter, showProgress)
 	case filetype.Is(header, "gz"):
-		reader, err := gzip.NewReader(file)
+		gzipReader, err := gzip.NewReader(reader)
 		if err != nil {
 			return nil, err
 		}
-		defer reader.Close()
-		return untar(io.Reader(reader), targetDir, fileFilter, showProgress)
-	case filetype.Is(header, "zip"):
-		return unzip(tarball, targetDir, fileFilter, showProgress)
+		defer gzipReader.Close()
+		return untar(io.Reader(gzipReader), targetDir, fileFilter, showProgress)
 	case filetype.Is(header, "tar"):
-		return untar(file, targetDir, fileFilter, showProgress)
+		return untar(reader, targetDir, fileFilter, showProgress)
 	default:
-		return nil, fmt.Errorf("Unknown file format when trying to uncompress %s", tarball)
+		return nil, errUnknownFormat
 	}
 }
 
@@ -158,7 +188,7 @@ func uncompressFile(tarReader io.Reader, fileInfo os.FileInfo, path string, show
 
 	// copy over contents
 	// #nosec G110
-	_, err = io.Copy(file, reader)
+	_, err = parallelCopy(file, reader)
 	if err != nil {
 		return err
 	}
@@ -28,6 +28,7 @@ var (
 		"test.tar.gz",
 		"test.zip",
 		"test.tar.xz",
+		"test-blocks.tar.xz",
 		"test.tar.zst",
 	}
 )
@@ -157,3 +158,19 @@ func testUncompress(archiveName string, fileFilter func(string) bool, files file
 func fileFilter(filename string) bool {
 	return filepath.Base(filename) == "c.txt"
 }
+
+func TestUncompressStream(t *testing.T) {
+	for _, archive := range archives {
+		if archive == "test.zip" {
+			continue
+		}
+		destDir := t.TempDir()
+		file, err := os.Open(filepath.Join("testdata", archive))
+		require.NoError(t, err)
+		fileList, err := UncompressStream(file, destDir)
+		file.Close()
+		require.NoError(t, err, archive)
+		assert.NoError(t, checkFileList(destDir, fileList, files))
+		assert.NoError(t, checkFiles(destDir, files))
+	}
+}
@@ -0,0 +1,276 @@
+package extract
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"runtime"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/pkg/errors"
+	"github.com/xi2/xz"
+)
+
+// xz files compressed with several threads are made of independent blocks,
+// their sizes are listed in the index at the end of the file. Each block is
+// decompressed by a worker as a standalone xz stream, and the blocks are read
+// back in order.
+
+const (
+	xzHeaderSize = 12
+	xzFooterSize = 12
+	// blocks larger than this are decompressed serially, each worker holds a
+	// whole uncompressed block in memory
+	maxParallelXZBlockSize = 256 * 1024 * 1024
+	maxParallelXZMemory    = 1024 * 1024 * 1024
+)
+
+var (
+	xzHeaderMagic = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+	xzFooterMagic = []byte{'Y', 'Z'}
+	// errSerialXZ is returned when the file cannot be decompressed in
+	// parallel, because it has a single block, several streams or too large
+	// blocks
+	errSerialXZ = errors.New("xz file cannot be decompressed in parallel")
+)
+
+type xzBlock struct {
+	offset           int64
+	unpaddedSize     uint64
+	uncompressedSize uint64
+}
+
+type xzBlockResult struct {
+	data []byte
+	err  error
+}
+
+type parallelXZReader struct {
+	results []chan xzBlockResult
+	slots   chan struct{}
+	done    chan struct{}
+	next    int
+	current []byte
+}
+
+// newXZReader decompresses the blocks of xz files in parallel, streams and
+// files with a single block are decompressed serially
+func newXZReader(reader io.Reader) (io.ReadCloser, error) {
+	if file, ok := reader.(*os.File); ok {
+		stat, err := file.Stat()
+		if err != nil {
+			return nil, err
+		}
+		parallelReader, err := newParallelXZReader(file, stat.Size())
+		if err == nil {
+			return parallelReader, nil
+		}
+		if !errors.Is(err, errSerialXZ) {
+			return nil, err
+		}
+		logging.Debugf("Decompressing %s serially: %v", file.Name(), err)
+	}
+	xzReader, err := xz.NewReader(reader, 0)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(xzReader), nil
+}
+
+// newParallelXZReader returns a reader of the decompressed content of the xz
+// file read from file, or errSerialXZ when it must be decompressed serially
+func newParallelXZReader(file io.ReaderAt, size int64) (io.ReadCloser, error) {
+	header := make([]byte, xzHeaderSize)
+	if _, err := file.ReadAt(header, 0); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:len(xzHeaderMagic)], xzHeaderMagic) {
+		return nil, errSerialXZ
+	}
+	blocks, err := readXZIndex(file, size, header[6:8])
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) < 2 {
+		return nil, errSerialXZ
+	}
+	var largestBlock uint64
+	for _, block := range blocks {
+		if block.uncompressedSize > largestBlock {
+			largestBlock = block.uncompressedSize
+		}
+	}
+	if largestBlock > maxParallelXZBlockSize {
+		return nil, errSerialXZ
+	}
+
+	workers := runtime.NumCPU()
+	if limit := int(maxParallelXZMemory / (largestBlock + 1)); limit < workers {
+		workers = limit
+	}
+	if workers < 2 {
+		workers = 2
+	}
+	reader := &parallelXZReader{
+		results: make([]chan xzBlockResult, len(blocks)),
+		slots:   make(chan struct{}, workers),
+		done:    make(chan struct{}),
+	}
+	for i := range reader.results {
+		reader.results[i] = make(chan xzBlockResult, 1)
+	}
+	go func() {
+		for i, block := range blocks {
+			// a slot is freed when the block is read, so that at most
+			// workers blocks are held in memory
+			select {
+			case reader.slots <- struct{}{}:
+			case <-reader.done:
+				return
+			}
+			go func(result chan xzBlockResult, block xzBlock) {
+				data, err := decodeXZBlock(file, header, block)
+				result <- xzBlockResult{data: data, err: err}
+			}(reader.results[i], block)
+		}
+	}()
+	return reader, nil
+}
+
+func (r *parallelXZReader) Read(p []byte) (int, error) {
+	for len(r.current) == 0 {
+		if r.next == len(r.results) {
+			return 0, io.EOF
+		}
+		result := <-r.results[r.next]
+		r.next++
+		<-r.slots
+		if result.err != nil {
+			return 0, result.err
+		}
+		r.current = result.data
+	}
+	n := copy(p, r.current)
+	r.current = r.current[n:]
+	return n, nil
+}
+
+// Close stops the decompression of the blocks which were not started yet
+func (r *parallelXZReader) Close() error {
+	close(r.done)
+	return nil
+}
+
+// readXZIndex returns the blocks listed in the index of the xz file, it
+// fails with errSerialXZ when the file is not made of a single stream
+func readXZIndex(file io.ReaderAt, size int64, streamFlags []byte) ([]xzBlock, error) {
+	if size < xzHeaderSize+xzFooterSize {
+		return nil, errSerialXZ
+	}
+	footer := make([]byte, xzFooterSize)
+	if _, err := file.ReadAt(footer, size-xzFooterSize); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(footer[10:], xzFooterMagic) || !bytes.Equal(footer[8:10], streamFlags) {
+		return nil, errSerialXZ
+	}
+	indexSize := (int64(binary.LittleEndian.Uint32(footer[4:8])) + 1) * 4
+	indexOffset := size - xzFooterSize - indexSize
+	if indexOffset < xzHeaderSize {
+		return nil, errSerialXZ
+	}
+	index := make([]byte, indexSize)
+	if _, err := file.ReadAt(index, indexOffset); err != nil {
+		return nil, err
+	}
+	if crc32.ChecksumIEEE(index[:indexSize-4]) != binary.LittleEndian.Uint32(index[indexSize-4:]) {
+		return nil, errors.New("corrupted xz index")
+	}
+	if index[0] != 0x00 {
+		return nil, errSerialXZ
+	}
+
+	records := bytes.NewReader(index[1 : indexSize-4])
+	count, err := binary.ReadUvarint(records)
+	if err != nil {
+		return nil, errSerialXZ
+	}
+	blocks := make([]xzBlock, 0, count)
+	offset := int64(xzHeaderSize)
+	for i := uint64(0); i < count; i++ {
+		unpaddedSize, err := binary.ReadUvarint(records)
+		if err != nil {
+			return nil, errSerialXZ
+		}
+		uncompressedSize, err := binary.ReadUvarint(records)
+		if err != nil {
+			return nil, errSerialXZ
+		}
+		blocks = append(blocks, xzBlock{
+			offset:           offset,
+			unpaddedSize:     unpaddedSize,
+			uncompressedSize: uncompressedSize,
+		})
+		offset += int64(padTo4(unpaddedSize))
+	}
+	// the blocks must fill the stream up to the index, otherwise the file
+	// holds several streams
+	if offset != indexOffset {
+		return nil, errSerialXZ
+	}
+	return blocks, nil
+}
+
+// decodeXZBlock decompresses block as a standalone xz stream made of the
+// stream header of the file, the block, and an index listing only this block
+func decodeXZBlock(file io.ReaderAt, header []byte, block xzBlock) ([]byte, error) {
+	stream := bytes.NewBuffer(make([]byte, 0, xzHeaderSize+padTo4(block.unpaddedSize)+64))
+	stream.Write(header)
+	data := make([]byte, padTo4(block.unpaddedSize))
+	if _, err := file.ReadAt(data, block.offset); err != nil {
+		return nil, err
+	}
+	stream.Write(data)
+
+	index := []byte{0x00}
+	index = appendUvarint(index, 1)
+	index = appendUvarint(index, block.unpaddedSize)
+	index = appendUvarint(index, block.uncompressedSize)
+	for len(index)%4 != 0 {
+		index = append(index, 0x00)
+	}
+	index = appendUint32(index, crc32.ChecksumIEEE(index))
+	stream.Write(index)
+
+	footer := appendUint32(nil, uint32(len(index)/4-1))
+	footer = append(footer, header[6:8]...)
+	stream.Write(appendUint32(nil, crc32.ChecksumIEEE(footer)))
+	stream.Write(footer)
+	stream.Write(xzFooterMagic)
+
+	reader, err := xz.NewReader(stream, 0)
+	if err != nil {
+		return nil, err
+	}
+	uncompressed := bytes.NewBuffer(make([]byte, 0, block.uncompressedSize))
+	if _, err := io.Copy(uncompressed, reader); err != nil {
+		return nil, err
+	}
+	return uncompressed.Bytes(), nil
+}
+
+func padTo4(size uint64) uint64 {
+	return (size + 3) &^ 3
+}
+
+func appendUvarint(buf []byte, value uint64) []byte {
+	varint := make([]byte, binary.MaxVarintLen64)
+	return append(buf, varint[:binary.PutUvarint(varint, value)]...)
+}
+
+func appendUint32(buf []byte, value uint32) []byte {
+	return append(buf, byte(value), byte(value>>8), byte(value>>16), byte(value>>24))
+}
@@ -0,0 +1,40 @@
+package extract
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelXZReader(t *testing.T) {
+	expected, err := ioutil.ReadFile(filepath.Join("testdata", "test.tar"))
+	require.NoError(t, err)
+
+	// compressed with xz --block-size=1024
+	file, err := os.Open(filepath.Join("testdata", "test-blocks.tar.xz"))
+	require.NoError(t, err)
+	defer file.Close()
+	stat, err := file.Stat()
+	require.NoError(t, err)
+	reader, err := newParallelXZReader(file, stat.Size())
+	require.NoError(t, err)
+	defer reader.Close()
+	assert.Len(t, reader.(*parallelXZReader).results, 10)
+	uncompressed, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, expected, uncompressed)
+}
+
+func TestParallelXZReaderSingleBlock(t *testing.T) {
+	file, err := os.Open(filepath.Join("testdata", "test.tar.xz"))
+	require.NoError(t, err)
+	defer file.Close()
+	stat, err := file.Stat()
+	require.NoError(t, err)
+	_, err = newParallelXZReader(file, stat.Size())
+	assert.ErrorIs(t, err, errSerialXZ)
+}
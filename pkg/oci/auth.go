@@ -0,0 +1,220 @@
+package oci
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/pkg/errors"
+)
+
+// authorize returns the Authorization header answering the
+// WWW-Authenticate challenge of the registry
+func (c *Client) authorize(ref Reference, challenge string) (string, error) {
+	scheme, params := parseChallenge(challenge)
+	username, password := c.credentials(ref.Registry)
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if username == "" {
+			return "", fmt.Errorf("no credentials for %s in the registry auth files", ref.Registry)
+		}
+		return "Basic " + basicAuth(username, password), nil
+	case "bearer":
+		token, err := c.token(ref, params, username, password)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	default:
+		return "", fmt.Errorf("unsupported authentication challenge '%s'", challenge)
+	}
+}
+
+// token fetches a pull token from the token server of the registry
+func (c *Client) token(ref Reference, params map[string]string, username, password string) (string, error) {
+	realm, err := url.Parse(params["realm"])
+	if err != nil || (realm.Scheme != "https" && realm.Scheme != "http") {
+		return "", fmt.Errorf("invalid token realm '%s'", params["realm"])
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", ref.Repository)
+	}
+	query.Set("scope", scope)
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := c.Downloader.Open(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token server returned %s", resp.Status)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	content, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(content, &body); err != nil {
+		return "", errors.Wrap(err, "cannot parse the token server response")
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	if body.AccessToken != "" {
+		return body.AccessToken, nil
+	}
+	return "", errors.New("the token server returned no token")
+}
+
+// parseChallenge parses a WWW-Authenticate header such as
+// Bearer realm="https://quay.io/v2/auth",service="quay.io"
+func parseChallenge(challenge string) (string, map[string]string) {
+	params := map[string]string{}
+	challenge = strings.TrimSpace(challenge)
+	i := strings.Index(challenge, " ")
+	if i == -1 {
+		return challenge, params
+	}
+	scheme, rest := challenge[:i], challenge[i+1:]
+	for rest != "" {
+		rest = strings.TrimLeft(rest, " ,")
+		eq := strings.Index(rest, "=")
+		if eq == -1 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(rest[:eq]))
+		rest = rest[eq+1:]
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end == -1 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.Index(rest, ",")
+			if end == -1 {
+				value, rest = rest, ""
+			} else {
+				value, rest = rest[:end], rest[end:]
+			}
+		}
+		params[key] = value
+	}
+	return scheme, params
+}
+
+func basicAuth(username, password string) string {
+	return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+}
+
+func (c *Client) credentials(registry string) (string, string) {
+	if c.Credentials == nil {
+		return "", ""
+	}
+	return c.Credentials(registry)
+}
+
+// authFiles returns the auth files of podman/skopeo and docker, in the
+// order these tools use them
+func authFiles() []string {
+	var files []string
+	if file := os.Getenv("REGISTRY_AUTH_FILE"); file != "" {
+		files = append(files, file)
+	}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		files = append(files, filepath.Join(dir, "containers", "auth.json"))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		files = append(files,
+			filepath.Join(home, ".config", "containers", "auth.json"),
+			filepath.Join(home, ".docker", "config.json"))
+	}
+	return files
+}
+
+type authFile struct {
+	Auths map[string]struct {
+		Auth string `json:"auth"`
+	} `json:"auths"`
+}
+
+// LoadCredentials returns the credentials stored by 'podman login' or
+// 'docker login' for a registry. Credential helpers are not supported.
+func LoadCredentials() func(registry string) (string, string) {
+	return func(registry string) (string, string) {
+		for _, file := range authFiles() {
+			username, password, err := credentialsFromFile(file, registry)
+			if err != nil {
+				logging.Debugf("Cannot read the credentials of %s from %s: %v", registry, file, err)
+				continue
+			}
+			if username != "" {
+				logging.Debugf("Using the credentials of %s from %s", registry, file)
+				return username, password
+			}
+		}
+		return "", ""
+	}
+}
+
+func credentialsFromFile(file, registry string) (string, string, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", "", nil
+		}
+		return "", "", err
+	}
+	var auths authFile
+	if err := json.Unmarshal(content, &auths); err != nil {
+		return "", "", err
+	}
+	for key, entry := range auths.Auths {
+		if authFileRegistry(key) != registry || entry.Auth == "" {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+		if err != nil {
+			return "", "", err
+		}
+		credentials := strings.SplitN(string(decoded), ":", 2)
+		if len(credentials) != 2 {
+			return "", "", fmt.Errorf("invalid credentials for %s", key)
+		}
+		return credentials[0], credentials[1], nil
+	}
+	return "", "", nil
+}
+
+// authFileRegistry returns the registry host of a key of an auth file,
+// docker uses URLs such as https://index.docker.io/v1/
+func authFileRegistry(key string) string {
+	key = strings.TrimPrefix(strings.TrimPrefix(key, "https://"), "http://")
+	return strings.SplitN(key, "/", 2)[0]
+}
@@ -0,0 +1,94 @@
+package oci
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	transportPrefix = "docker://"
+	defaultTag      = "latest"
+)
+
+var (
+	repositoryRegexp = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*$`)
+	tagRegexp        = regexp.MustCompile(`^\w[\w.-]{0,127}$`)
+	digestRegexp     = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+)
+
+// Reference is the location of an image or an artifact in a registry, such
+// as quay.io/crcont/bundle:4.7.0 or quay.io/crcont/bundle@sha256:...
+type Reference struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseReference parses an image reference, which must include the registry
+// host. The docker:// prefix used by skopeo and podman is accepted.
+func ParseReference(input string) (Reference, error) {
+	name := strings.TrimPrefix(input, transportPrefix)
+	var ref Reference
+	if i := strings.Index(name, "@"); i != -1 {
+		ref.Digest = name[i+1:]
+		name = name[:i]
+		if !digestRegexp.MatchString(ref.Digest) {
+			return Reference{}, fmt.Errorf("'%s' is not a valid image reference, only sha256 digests are supported", input)
+		}
+	}
+	i := strings.Index(name, "/")
+	if i == -1 || !isRegistry(name[:i]) {
+		return Reference{}, fmt.Errorf("'%s' is not a valid image reference, it must start with the registry host, for instance quay.io/", input)
+	}
+	ref.Registry = name[:i]
+	ref.Repository = name[i+1:]
+	if j := strings.LastIndex(ref.Repository, ":"); j != -1 {
+		ref.Tag = ref.Repository[j+1:]
+		ref.Repository = ref.Repository[:j]
+		if !tagRegexp.MatchString(ref.Tag) {
+			return Reference{}, fmt.Errorf("'%s' is not a valid image reference, invalid tag '%s'", input, ref.Tag)
+		}
+	}
+	if !repositoryRegexp.MatchString(ref.Repository) {
+		return Reference{}, fmt.Errorf("'%s' is not a valid image reference, invalid repository '%s'", input, ref.Repository)
+	}
+	if ref.Tag == "" && ref.Digest == "" {
+		ref.Tag = defaultTag
+	}
+	return ref, nil
+}
+
+func isRegistry(host string) bool {
+	return strings.ContainsAny(host, ".:") || host == "localhost"
+}
+
+// ValidateReference checks that input is empty or a valid image reference
+func ValidateReference(input string) error {
+	if input == "" {
+		return nil
+	}
+	_, err := ParseReference(input)
+	return err
+}
+
+// reference returns the tag or the digest identifying the manifest, the
+// digest is used when both are set
+func (ref Reference) reference() string {
+	if ref.Digest != "" {
+		return ref.Digest
+	}
+	return ref.Tag
+}
+
+func (ref Reference) String() string {
+	s := ref.Registry + "/" + ref.Repository
+	if ref.Tag != "" {
+		s += ":" + ref.Tag
+	}
+	if ref.Digest != "" {
+		s += "@" + ref.Digest
+	}
+	return s
+}
@@ -0,0 +1,37 @@
+package oci
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseReference(t *testing.T) {
+	digest := "sha256:" + "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	for input, expected := range map[string]Reference{
+		"quay.io/crcont/bundle:4.7.0":          {Registry: "quay.io", Repository: "crcont/bundle", Tag: "4.7.0"},
+		"docker://quay.io/crcont/bundle":       {Registry: "quay.io", Repository: "crcont/bundle", Tag: "latest"},
+		"registry.local:5000/bundle@" + digest: {Registry: "registry.local:5000", Repository: "bundle", Digest: digest},
+		"localhost/crc/bundle:4.7.0@" + digest: {Registry: "localhost", Repository: "crc/bundle", Tag: "4.7.0", Digest: digest},
+	} {
+		ref, err := ParseReference(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, ref)
+	}
+	assert.Equal(t, "quay.io/crcont/bundle:latest", Reference{Registry: "quay.io", Repository: "crcont/bundle", Tag: "latest"}.String())
+}
+
+func TestParseInvalidReference(t *testing.T) {
+	for _, input := range []string{
+		"crcont/bundle:4.7.0",
+		"bundle",
+		"quay.io/crcont/Bundle",
+		"quay.io/crcont/bundle:",
+		"quay.io/crcont/bundle@sha512:1234",
+	} {
+		_, err := ParseReference(input)
+		assert.Error(t, err, input)
+	}
+	assert.NoError(t, ValidateReference(""))
+}
@@ -0,0 +1,290 @@
+package oci
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/code-ready/crc/pkg/download"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+const (
+	MediaTypeImageManifest  = "application/vnd.oci.image.manifest.v1+json"
+	MediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+	MediaTypeImageIndex     = "application/vnd.oci.image.index.v1+json"
+
+	// AnnotationTitle is the file name of a layer, as set by 'oras push'
+	AnnotationTitle = "org.opencontainers.image.title"
+
+	// manifests are small documents, this limits what is read from a
+	// misbehaving registry
+	maxManifestSize = 4 * 1024 * 1024
+)
+
+// Descriptor describes a blob stored in a registry
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Title returns the file name of the blob, it is empty when it has none
+func (descriptor Descriptor) Title() string {
+	return descriptor.Annotations[AnnotationTitle]
+}
+
+// Manifest is an OCI image manifest, artifacts such as the bundles store
+// their files as layers
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType,omitempty"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+	// Digest is the digest of the manifest, it identifies the artifact
+	Digest string `json:"-"`
+}
+
+// Title returns the name of the artifact, it is empty when it has none
+func (manifest *Manifest) Title() string {
+	return manifest.Annotations[AnnotationTitle]
+}
+
+// Client pulls manifests and blobs from registries implementing the OCI
+// distribution API. The requests are sent by Downloader, they are retried,
+// the interrupted blob transfers are resumed and their progress is reported.
+type Client struct {
+	Downloader *download.Downloader
+	// Credentials returns the username and password to use with registry,
+	// the requests are anonymous when it returns empty strings
+	Credentials func(registry string) (string, string)
+
+	mu sync.Mutex
+	// Authorization headers by registry and repository
+	authorizations map[string]string
+}
+
+func New() *Client {
+	return &Client{
+		Downloader:  download.New(),
+		Credentials: LoadCredentials(),
+	}
+}
+
+// Manifest fetches the manifest of ref. It is verified against the digest
+// of ref when it has one.
+func (c *Client) Manifest(ref Reference) (*Manifest, error) {
+	logging.Debugf("Fetching the manifest of %s", ref)
+	resp, err := c.get(ref, "manifests/"+ref.reference(), MediaTypeImageManifest, MediaTypeDockerManifest, MediaTypeImageIndex)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	content, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxManifestSize))
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read the manifest of %s", ref)
+	}
+	sum := sha256.Sum256(content)
+	digest := "sha256:" + hex.EncodeToString(sum[:])
+	if ref.Digest != "" && digest != ref.Digest {
+		return nil, fmt.Errorf("the manifest of %s has the digest %s", ref, digest)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, errors.Wrapf(err, "cannot parse the manifest of %s", ref)
+	}
+	if manifest.MediaType == "" {
+		manifest.MediaType = mediaType(resp.Header.Get("Content-Type"))
+	}
+	switch manifest.MediaType {
+	case MediaTypeImageManifest, MediaTypeDockerManifest:
+	case MediaTypeImageIndex:
+		return nil, fmt.Errorf("%s is a multi-platform image index, it must reference a single artifact", ref)
+	default:
+		return nil, fmt.Errorf("%s has an unsupported manifest type '%s'", ref, manifest.MediaType)
+	}
+	manifest.Digest = digest
+	return &manifest, nil
+}
+
+// Blob returns the content of the blob of repository ref described by
+// descriptor. Reading it fails when the content does not match the digest.
+func (c *Client) Blob(ref Reference, descriptor Descriptor) (io.ReadCloser, error) {
+	if !digestRegexp.MatchString(descriptor.Digest) {
+		return nil, fmt.Errorf("unsupported digest '%s', only sha256 digests are supported", descriptor.Digest)
+	}
+	logging.Debugf("Fetching blob %s from %s", descriptor.Digest, ref)
+	resp, err := c.get(ref, "blobs/"+descriptor.Digest)
+	if err != nil {
+		return nil, err
+	}
+	return &verifyingReader{
+		ReadCloser: resp.Body,
+		hash:       sha256.New(),
+		descriptor: descriptor,
+	}, nil
+}
+
+// Layer returns the content of a layer like Blob, decompressed when its
+// media type ends with +gzip or +zstd
+func (c *Client) Layer(ref Reference, descriptor Descriptor) (io.ReadCloser, error) {
+	blob, err := c.Blob(ref, descriptor)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case strings.HasSuffix(descriptor.MediaType, "+gzip") || strings.HasSuffix(descriptor.MediaType, ".gzip"):
+		reader, err := gzip.NewReader(blob)
+		if err != nil {
+			blob.Close()
+			return nil, err
+		}
+		return &layerReader{Reader: reader, blob: blob}, nil
+	case strings.HasSuffix(descriptor.MediaType, "+zstd"):
+		decoder, err := zstd.NewReader(blob)
+		if err != nil {
+			blob.Close()
+			return nil, err
+		}
+		return &layerReader{Reader: decoder, blob: blob, close: decoder.Close}, nil
+	default:
+		return blob, nil
+	}
+}
+
+// layerReader reads a decompressed layer, the end of the blob is read when
+// the layer ends so that its digest is verified
+type layerReader struct {
+	io.Reader
+	blob  io.ReadCloser
+	close func()
+}
+
+func (r *layerReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err == io.EOF {
+		if _, err := io.Copy(ioutil.Discard, r.blob); err != nil {
+			return n, err
+		}
+	}
+	return n, err
+}
+
+func (r *layerReader) Close() error {
+	if r.close != nil {
+		r.close()
+	}
+	return r.blob.Close()
+}
+
+type verifyingReader struct {
+	io.ReadCloser
+	hash       hash.Hash
+	descriptor Descriptor
+	read       int64
+}
+
+func (r *verifyingReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.hash.Write(p[:n])
+	r.read += int64(n)
+	if err == io.EOF {
+		if r.descriptor.Size > 0 && r.read != r.descriptor.Size {
+			return n, fmt.Errorf("blob %s has %d bytes, expected %d", r.descriptor.Digest, r.read, r.descriptor.Size)
+		}
+		if digest := "sha256:" + hex.EncodeToString(r.hash.Sum(nil)); digest != r.descriptor.Digest {
+			return n, fmt.Errorf("blob %s has the digest %s, it may have been tampered with", r.descriptor.Digest, digest)
+		}
+	}
+	return n, err
+}
+
+// get sends a GET request to the API of the repository of ref, it
+// authenticates when the registry asks for it
+func (c *Client) get(ref Reference, path string, accept ...string) (*http.Response, error) {
+	url := fmt.Sprintf("https://%s/v2/%s/%s", ref.Registry, ref.Repository, path)
+	key := ref.Registry + "/" + ref.Repository
+
+	c.mu.Lock()
+	authorization := c.authorizations[key]
+	c.mu.Unlock()
+	resp, err := c.send(url, authorization, accept)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		authorization, err := c.authorize(ref, challenge)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot authenticate to %s", ref.Registry)
+		}
+		c.mu.Lock()
+		if c.authorizations == nil {
+			c.authorizations = map[string]string{}
+		}
+		c.authorizations[key] = authorization
+		c.mu.Unlock()
+		if resp, err = c.send(url, authorization, accept); err != nil {
+			return nil, err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("cannot fetch %s from %s: %s", path, ref, registryError(resp))
+	}
+	return resp, nil
+}
+
+func (c *Client) send(url, authorization string, accept []string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	if len(accept) > 0 {
+		req.Header.Set("Accept", strings.Join(accept, ", "))
+	}
+	// the Authorization header is not sent again when the blobs are
+	// redirected to another host, such as a storage bucket
+	return c.Downloader.Open(req)
+}
+
+// registryError returns the message of an error response of the
+// distribution API
+func registryError(resp *http.Response) string {
+	var body struct {
+		Errors []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	content, err := ioutil.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil || json.Unmarshal(content, &body) != nil || len(body.Errors) == 0 {
+		return resp.Status
+	}
+	var messages []string
+	for _, e := range body.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", e.Code, e.Message))
+	}
+	return fmt.Sprintf("%s (%s)", resp.Status, strings.Join(messages, ", "))
+}
+
+func mediaType(contentType string) string {
+	return strings.TrimSpace(strings.Split(contentType, ";")[0])
+}
@@ -0,0 +1,190 @@
+package oci
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/code-ready/crc/pkg/download"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "pull-token"
+
+func digestOf(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// newTestRegistry serves the manifest and the blobs of crcont/bundle:4.7.0,
+// the pulls need a token from its token server
+func newTestRegistry(t *testing.T, manifest []byte, blobs map[string][]byte) (*httptest.Server, *Client) {
+	mux := http.NewServeMux()
+	var server *httptest.Server
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "repository:crcont/bundle:pull", r.URL.Query().Get("scope"))
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "user" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprintf(w, `{"token": "%s"}`, testToken)
+	})
+	mux.HandleFunc("/v2/crcont/bundle/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+testToken {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.URL.Path == "/v2/crcont/bundle/manifests/4.7.0":
+			w.Header().Set("Content-Type", MediaTypeImageManifest)
+			_, _ = w.Write(manifest)
+		case strings.HasPrefix(r.URL.Path, "/v2/crcont/bundle/blobs/"):
+			blob, ok := blobs[strings.TrimPrefix(r.URL.Path, "/v2/crcont/bundle/blobs/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"errors": [{"code": "BLOB_UNKNOWN", "message": "blob unknown to registry"}]}`))
+				return
+			}
+			_, _ = w.Write(blob)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	server = httptest.NewTLSServer(mux)
+	client := &Client{
+		Downloader: &download.Downloader{
+			Transport: server.Client().Transport,
+			Attempts:  1,
+		},
+		Credentials: func(registry string) (string, string) {
+			return "user", "secret"
+		},
+	}
+	return server, client
+}
+
+func testReference(t *testing.T, server *httptest.Server) Reference {
+	ref, err := ParseReference(strings.TrimPrefix(server.URL, "https://") + "/crcont/bundle:4.7.0")
+	require.NoError(t, err)
+	return ref
+}
+
+func TestPull(t *testing.T) {
+	metadata := []byte(`{"version": "1.0"}`)
+	var disk bytes.Buffer
+	gz := gzip.NewWriter(&disk)
+	_, err := gz.Write([]byte("disk image"))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	manifest, err := json.Marshal(Manifest{
+		SchemaVersion: 2,
+		MediaType:     MediaTypeImageManifest,
+		Layers: []Descriptor{
+			{MediaType: "application/json", Digest: digestOf(metadata), Size: int64(len(metadata)), Annotations: map[string]string{AnnotationTitle: "crc-bundle-info.json"}},
+			{MediaType: "application/octet-stream+gzip", Digest: digestOf(disk.Bytes()), Size: int64(disk.Len()), Annotations: map[string]string{AnnotationTitle: "crc.qcow2"}},
+		},
+		Annotations: map[string]string{AnnotationTitle: "crc_libvirt_4.7.0.crcbundle"},
+	})
+	require.NoError(t, err)
+	server, client := newTestRegistry(t, manifest, map[string][]byte{
+		digestOf(metadata):     metadata,
+		digestOf(disk.Bytes()): disk.Bytes(),
+	})
+	defer server.Close()
+	ref := testReference(t, server)
+
+	m, err := client.Manifest(ref)
+	require.NoError(t, err)
+	assert.Equal(t, "crc_libvirt_4.7.0.crcbundle", m.Title())
+	assert.Equal(t, digestOf(manifest), m.Digest)
+	require.Len(t, m.Layers, 2)
+	assert.Equal(t, "crc.qcow2", m.Layers[1].Title())
+
+	blob, err := client.Layer(ref, m.Layers[0])
+	require.NoError(t, err)
+	content, err := ioutil.ReadAll(blob)
+	require.NoError(t, err)
+	require.NoError(t, blob.Close())
+	assert.Equal(t, metadata, content)
+
+	layer, err := client.Layer(ref, m.Layers[1])
+	require.NoError(t, err)
+	content, err = ioutil.ReadAll(layer)
+	require.NoError(t, err)
+	require.NoError(t, layer.Close())
+	assert.Equal(t, "disk image", string(content))
+}
+
+func TestPullTamperedBlob(t *testing.T) {
+	server, client := newTestRegistry(t, nil, map[string][]byte{
+		digestOf([]byte("original")): []byte("tampered"),
+	})
+	defer server.Close()
+
+	blob, err := client.Blob(testReference(t, server), Descriptor{Digest: digestOf([]byte("original"))})
+	require.NoError(t, err)
+	defer blob.Close()
+	_, err = ioutil.ReadAll(blob)
+	assert.EqualError(t, err, fmt.Sprintf("blob %s has the digest %s, it may have been tampered with", digestOf([]byte("original")), digestOf([]byte("tampered"))))
+}
+
+func TestPullErrors(t *testing.T) {
+	server, client := newTestRegistry(t, nil, nil)
+	defer server.Close()
+	ref := testReference(t, server)
+
+	_, err := client.Blob(ref, Descriptor{Digest: digestOf([]byte("missing"))})
+	assert.EqualError(t, err, fmt.Sprintf("cannot fetch blobs/%s from %s: 404 Not Found (BLOB_UNKNOWN: blob unknown to registry)", digestOf([]byte("missing")), ref))
+
+	anonymous := &Client{Downloader: client.Downloader}
+	_, err = anonymous.Manifest(ref)
+	assert.EqualError(t, err, fmt.Sprintf("cannot authenticate to %s: token server returned 401 Unauthorized", ref.Registry))
+}
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:samalba/my-app:pull,push"`)
+	assert.Equal(t, "Bearer", scheme)
+	assert.Equal(t, map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:samalba/my-app:pull,push",
+	}, params)
+}
+
+func TestCredentialsFromFile(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "auth.json")
+	content := fmt.Sprintf(`{"auths": {"quay.io": {"auth": "%s"}, "https://index.docker.io/v1/": {"auth": "%s"}}}`,
+		base64.StdEncoding.EncodeToString([]byte("user:secret")), base64.StdEncoding.EncodeToString([]byte("other:password")))
+	require.NoError(t, ioutil.WriteFile(file, []byte(content), 0600))
+
+	username, password, err := credentialsFromFile(file, "quay.io")
+	require.NoError(t, err)
+	assert.Equal(t, "user", username)
+	assert.Equal(t, "secret", password)
+
+	username, _, err = credentialsFromFile(file, "index.docker.io")
+	require.NoError(t, err)
+	assert.Equal(t, "other", username)
+
+	username, _, err = credentialsFromFile(file, "registry.local")
+	require.NoError(t, err)
+	assert.Empty(t, username)
+
+	_, _, err = credentialsFromFile(filepath.Join(dir, "missing.json"), "quay.io")
+	assert.NoError(t, err)
+}
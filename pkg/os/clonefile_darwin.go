@@ -0,0 +1,25 @@
+package os
+
+import (
+	"fmt"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+)
+
+// CloneFile copies src to dst, sharing their blocks on APFS
+func CloneFile(src string, dst string) error {
+	if _, stderr, err := RunWithDefaultLocale("cp", "-c", src, dst); err != nil {
+		logging.Debugf("Cannot clone %s, copying it instead: %v: %s", src, err, stderr)
+		return CopyFileContents(src, dst, 0600)
+	}
+	return nil
+}
+
+// ReflinkFile clones src to dst without copying its blocks, it fails on the
+// filesystems other than APFS instead of copying the file
+func ReflinkFile(src string, dst string) error {
+	if _, stderr, err := RunWithDefaultLocale("cp", "-c", src, dst); err != nil {
+		return fmt.Errorf("Failed to clone %s to %s, the filesystem must be APFS %v: %s", src, dst, err, stderr)
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package os
+
+import (
+	"fmt"
+)
+
+// CloneFile copies src to dst, sharing their blocks on filesystems with
+// reflink support such as btrfs and xfs, and keeping the holes of sparse files
+func CloneFile(src string, dst string) error {
+	if _, stderr, err := RunWithDefaultLocale("cp", "--reflink=auto", "--sparse=always", src, dst); err != nil {
+		return fmt.Errorf("Failed to copy %s to %s %v: %s", src, dst, err, stderr)
+	}
+	return nil
+}
+
+// ReflinkFile clones src to dst without copying its blocks, it fails on the
+// filesystems without reflink support instead of copying the file
+func ReflinkFile(src string, dst string) error {
+	if _, stderr, err := RunWithDefaultLocale("cp", "--reflink=always", src, dst); err != nil {
+		return fmt.Errorf("Failed to clone %s to %s, the filesystem must support reflinks, such as btrfs and xfs %v: %s", src, dst, err, stderr)
+	}
+	return nil
+}
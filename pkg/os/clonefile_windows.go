@@ -0,0 +1,15 @@
+package os
+
+import (
+	"errors"
+)
+
+// CloneFile copies src to dst, NTFS does not support sharing blocks between files
+func CloneFile(src string, dst string) error {
+	return CopyFileContents(src, dst, 0600)
+}
+
+// ReflinkFile fails, NTFS does not support sharing blocks between files
+func ReflinkFile(_ string, _ string) error {
+	return errors.New("Files cannot be cloned on NTFS")
+}
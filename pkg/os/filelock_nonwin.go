@@ -0,0 +1,27 @@
+// +build !windows
+
+package os
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// LockFile takes an exclusive lock on the file at path, creating it if
+// needed, and blocks until the lock is acquired. The lock is shared with the
+// other processes, it is released by calling the returned function.
+func LockFile(path string) (func() error, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Flock(int(file.Fd()), unix.LOCK_EX); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return func() error {
+		defer file.Close()
+		return unix.Flock(int(file.Fd()), unix.LOCK_UN)
+	}, nil
+}
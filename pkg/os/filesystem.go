@@ -0,0 +1,82 @@
+package os
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// FilesystemInfo describes the filesystem holding a path
+type FilesystemInfo struct {
+	// name of the filesystem type, such as ext4, nfs or NTFS
+	Type     string
+	ReadOnly bool
+	// network filesystems have a poor mmap and locking support
+	Remote bool
+	// free inodes, only meaningful when InodesKnown is true, filesystems
+	// such as btrfs or NTFS allocate them dynamically
+	FreeInodes  uint64
+	InodesKnown bool
+}
+
+// ExistingParent returns path or its closest parent which exists, so that
+// the filesystem of a directory can be checked before it is created
+func ExistingParent(path string) string {
+	for {
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			return path
+		}
+		path = parent
+	}
+}
+
+// SyncLatency measures how long the filesystem of dir takes to persist small
+// writes, the way the write-ahead log of etcd syncs each of its entries. It
+// writes count blocks of blockSize bytes to a temporary file, syncing the file
+// after each of them, and returns the 99th percentile of the durations of the
+// writes. It stops early once the writes took maxDuration, a filesystem this
+// slow is not worth measuring further.
+func SyncLatency(dir string, count, blockSize int, maxDuration time.Duration) (time.Duration, error) {
+	file, err := ioutil.TempFile(dir, ".crc-latency-check")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(file.Name())
+	defer file.Close()
+
+	block := make([]byte, blockSize)
+	var durations []time.Duration
+	start := time.Now()
+	for i := 0; i < count && time.Since(start) < maxDuration; i++ {
+		writeStart := time.Now()
+		if _, err := file.Write(block); err != nil {
+			return 0, err
+		}
+		if err := syncData(file); err != nil {
+			return 0, err
+		}
+		durations = append(durations, time.Since(writeStart))
+	}
+	return percentile(durations, 99), nil
+}
+
+// percentile returns the duration which is greater than or equal to p
+// percent of durations
+func percentile(durations []time.Duration, p int) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	index := (len(sorted)*p+99)/100 - 1
+	if index < 0 {
+		index = 0
+	}
+	return sorted[index]
+}
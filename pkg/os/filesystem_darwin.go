@@ -0,0 +1,41 @@
+package os
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+var remoteFilesystems = map[string]bool{
+	"afpfs":   true,
+	"nfs":     true,
+	"smbfs":   true,
+	"webdav":  true,
+	"osxfuse": true,
+	"macfuse": true,
+}
+
+// GetFilesystemInfo returns the filesystem information of the existing path
+func GetFilesystemInfo(path string) (FilesystemInfo, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return FilesystemInfo{}, fmt.Errorf("Cannot get the filesystem information of %s: %w", path, err)
+	}
+	fsType := unix.ByteSliceToString(stat.Fstypename[:])
+	return FilesystemInfo{
+		Type:     fsType,
+		ReadOnly: stat.Flags&unix.MNT_RDONLY != 0,
+		Remote:   remoteFilesystems[fsType],
+		// APFS allocates inodes on demand
+		InodesKnown: stat.Files != 0 && fsType != "apfs",
+		FreeInodes:  stat.Ffree,
+	}, nil
+}
+
+// syncData persists the data written to file with fsync, File.Sync uses
+// F_FULLFSYNC which also flushes the cache of the disk and is much slower
+// than the syncs of etcd in the VM
+func syncData(file *os.File) error {
+	return unix.Fsync(int(file.Fd()))
+}
@@ -0,0 +1,64 @@
+package os
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// magic numbers of the statfs filesystem types which are not all defined by
+// x/sys/unix
+const (
+	btrfsSuperMagic = 0x9123683e
+	cifsMagicNumber = 0xff534d42
+	fuseSuperMagic  = 0x65735546
+	nfsSuperMagic   = 0x6969
+	smb2MagicNumber = 0xfe534d42
+	smbSuperMagic   = 0x517b
+	v9fsMagic       = 0x01021997
+)
+
+var remoteFilesystems = map[uint32]string{
+	cifsMagicNumber: "cifs",
+	nfsSuperMagic:   "nfs",
+	smb2MagicNumber: "smb2",
+	smbSuperMagic:   "smb",
+	v9fsMagic:       "9p",
+}
+
+// GetFilesystemInfo returns the filesystem information of the existing path
+func GetFilesystemInfo(path string) (FilesystemInfo, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(path, &stat); err != nil {
+		return FilesystemInfo{}, fmt.Errorf("Cannot get the filesystem information of %s: %w", path, err)
+	}
+	return filesystemInfo(uint32(stat.Type), uint64(stat.Flags), stat.Files, stat.Ffree), nil
+}
+
+func filesystemInfo(magic uint32, flags uint64, files, freeFiles uint64) FilesystemInfo {
+	info := FilesystemInfo{
+		Type:     fmt.Sprintf("0x%x", magic),
+		ReadOnly: flags&unix.ST_RDONLY != 0,
+		// btrfs reports 0 inodes, they are allocated on demand
+		InodesKnown: files != 0 && magic != btrfsSuperMagic,
+		FreeInodes:  freeFiles,
+	}
+	if name, ok := remoteFilesystems[magic]; ok {
+		info.Type = name
+		info.Remote = true
+	}
+	// sshfs and the other FUSE filesystems are often remote, and their mmap
+	// support depends on the implementation
+	if magic == fuseSuperMagic {
+		info.Type = "fuse"
+		info.Remote = true
+	}
+	return info
+}
+
+// syncData persists the data written to file without its metadata, as the
+// fdatasync of etcd
+func syncData(file *os.File) error {
+	return unix.Fdatasync(int(file.Fd()))
+}
@@ -0,0 +1,54 @@
+package os
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/unix"
+)
+
+func TestFilesystemInfo(t *testing.T) {
+	info := filesystemInfo(unix.EXT4_SUPER_MAGIC, 0, 1000, 500)
+	assert.False(t, info.ReadOnly)
+	assert.False(t, info.Remote)
+	assert.True(t, info.InodesKnown)
+	assert.Equal(t, uint64(500), info.FreeInodes)
+
+	info = filesystemInfo(nfsSuperMagic, unix.ST_RDONLY, 0, 0)
+	assert.True(t, info.ReadOnly)
+	assert.True(t, info.Remote)
+	assert.Equal(t, "nfs", info.Type)
+	assert.False(t, info.InodesKnown)
+
+	info = filesystemInfo(btrfsSuperMagic, 0, 0, 0)
+	assert.False(t, info.InodesKnown)
+}
+
+func TestExistingParent(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, dir, ExistingParent(filepath.Join(dir, "cache", "bundle")))
+	assert.Equal(t, dir, ExistingParent(dir))
+}
+
+func TestSyncLatency(t *testing.T) {
+	dir := t.TempDir()
+	latency, err := SyncLatency(dir, 10, 2300, time.Minute)
+	assert.NoError(t, err)
+	assert.Greater(t, int64(latency), int64(0))
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestPercentile(t *testing.T) {
+	var durations []time.Duration
+	for i := 100; i > 0; i-- {
+		durations = append(durations, time.Duration(i)*time.Millisecond)
+	}
+	assert.Equal(t, 99*time.Millisecond, percentile(durations, 99))
+	assert.Equal(t, 50*time.Millisecond, percentile(durations, 50))
+	assert.Equal(t, 3*time.Millisecond, percentile([]time.Duration{3 * time.Millisecond}, 99))
+	assert.Equal(t, time.Duration(0), percentile(nil, 99))
+}
@@ -0,0 +1,36 @@
+package os
+
+import (
+	"fmt"
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// GetFilesystemInfo returns the filesystem information of the existing path,
+// NTFS allocates its file records on demand so inodes are never reported
+func GetFilesystemInfo(path string) (FilesystemInfo, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return FilesystemInfo{}, err
+	}
+	volumePath := make([]uint16, windows.MAX_PATH+1)
+	if err := windows.GetVolumePathName(pathPtr, &volumePath[0], uint32(len(volumePath))); err != nil {
+		return FilesystemInfo{}, fmt.Errorf("Cannot get the volume of %s: %w", path, err)
+	}
+	var flags uint32
+	fsName := make([]uint16, windows.MAX_PATH+1)
+	if err := windows.GetVolumeInformation(&volumePath[0], nil, 0, nil, nil, &flags, &fsName[0], uint32(len(fsName))); err != nil {
+		return FilesystemInfo{}, fmt.Errorf("Cannot get the filesystem information of %s: %w", path, err)
+	}
+	return FilesystemInfo{
+		Type:     windows.UTF16ToString(fsName),
+		ReadOnly: flags&windows.FILE_READ_ONLY_VOLUME != 0,
+		Remote:   windows.GetDriveType(&volumePath[0]) == windows.DRIVE_REMOTE,
+	}, nil
+}
+
+// syncData persists the data written to file with FlushFileBuffers
+func syncData(file *os.File) error {
+	return file.Sync()
+}
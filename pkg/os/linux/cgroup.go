@@ -0,0 +1,155 @@
+// +build linux
+
+package linux
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	procSelfCgroup = "/proc/self/cgroup"
+	cgroupRoot     = "/sys/fs/cgroup"
+)
+
+// CgroupMemoryLimit returns the memory limit in bytes applying to the current
+// process, or 0 if it is not limited by a cgroup
+func CgroupMemoryLimit() (uint64, error) {
+	return cgroupMemoryLimit(procSelfCgroup, cgroupRoot)
+}
+
+// CgroupCPULimit returns the number of CPUs the current process can use
+// according to its cgroup CPU quota, or 0 if there is no quota
+func CgroupCPULimit() (int, error) {
+	return cgroupCPULimit(procSelfCgroup, cgroupRoot)
+}
+
+func isCgroupV2(root string) bool {
+	_, err := os.Stat(filepath.Join(root, "cgroup.controllers"))
+	return err == nil
+}
+
+// cgroupPath returns the path of the cgroup of the current process for the
+// given v1 controller, or of the unified hierarchy if controller is empty
+func cgroupPath(procCgroupFile, controller string) (string, error) {
+	content, err := ioutil.ReadFile(procCgroupFile)
+	if err != nil {
+		return "", err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		// hierarchy-ID:controller-list:cgroup-path
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if controller == "" && fields[0] == "0" && fields[1] == "" {
+			return fields[2], nil
+		}
+		for _, c := range strings.Split(fields[1], ",") {
+			if controller != "" && c == controller {
+				return fields[2], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("cannot find cgroup for controller '%s'", controller)
+}
+
+// readCgroupFile reads filename in the cgroup directory of the process, falling back
+// to the root of the hierarchy as the cgroup path is not always visible in containers
+func readCgroupFile(dir, path, filename string) (string, error) {
+	content, err := ioutil.ReadFile(filepath.Join(dir, path, filename))
+	if err != nil {
+		content, err = ioutil.ReadFile(filepath.Join(dir, filename))
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+func cgroupMemoryLimit(procCgroupFile, root string) (uint64, error) {
+	dir, controller, filename := root, "", "memory.max"
+	if !isCgroupV2(root) {
+		dir, controller, filename = filepath.Join(root, "memory"), "memory", "memory.limit_in_bytes"
+	}
+	path, err := cgroupPath(procCgroupFile, controller)
+	if err != nil {
+		return 0, err
+	}
+	value, err := readCgroupFile(dir, path, filename)
+	if err != nil {
+		return 0, err
+	}
+	if value == "max" {
+		return 0, nil
+	}
+	limit, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	// cgroup v1 reports a page-aligned math.MaxInt64 when there is no limit
+	if limit >= math.MaxInt64/4096*4096 {
+		return 0, nil
+	}
+	return limit, nil
+}
+
+func cgroupCPULimit(procCgroupFile, root string) (int, error) {
+	var quota, period int64
+	if isCgroupV2(root) {
+		path, err := cgroupPath(procCgroupFile, "")
+		if err != nil {
+			return 0, err
+		}
+		value, err := readCgroupFile(root, path, "cpu.max")
+		if err != nil {
+			return 0, err
+		}
+		// "$MAX $PERIOD", $MAX being "max" when there is no quota
+		fields := strings.Fields(value)
+		if len(fields) != 2 {
+			return 0, fmt.Errorf("unexpected cpu.max content: %s", value)
+		}
+		if fields[0] == "max" {
+			return 0, nil
+		}
+		if quota, err = strconv.ParseInt(fields[0], 10, 64); err != nil {
+			return 0, err
+		}
+		if period, err = strconv.ParseInt(fields[1], 10, 64); err != nil {
+			return 0, err
+		}
+	} else {
+		path, err := cgroupPath(procCgroupFile, "cpu")
+		if err != nil {
+			return 0, err
+		}
+		dir := filepath.Join(root, "cpu")
+		value, err := readCgroupFile(dir, path, "cpu.cfs_quota_us")
+		if err != nil {
+			return 0, err
+		}
+		if quota, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return 0, err
+		}
+		value, err = readCgroupFile(dir, path, "cpu.cfs_period_us")
+		if err != nil {
+			return 0, err
+		}
+		if period, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return 0, err
+		}
+	}
+	if quota <= 0 || period <= 0 {
+		return 0, nil
+	}
+	return int(math.Ceil(float64(quota) / float64(period))), nil
+}
@@ -0,0 +1,77 @@
+// +build linux
+
+package linux
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+}
+
+func TestCgroupV2Limits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	procFile := filepath.Join(dir, "cgroup")
+	root := filepath.Join(dir, "sys")
+	writeFile(t, procFile, "0::/ci/job\n")
+	writeFile(t, filepath.Join(root, "cgroup.controllers"), "cpu memory")
+	writeFile(t, filepath.Join(root, "ci", "job", "memory.max"), "8589934592\n")
+	writeFile(t, filepath.Join(root, "ci", "job", "cpu.max"), "250000 100000\n")
+
+	memory, err := cgroupMemoryLimit(procFile, root)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(8589934592), memory)
+	cpus, err := cgroupCPULimit(procFile, root)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, cpus)
+
+	writeFile(t, filepath.Join(root, "ci", "job", "memory.max"), "max\n")
+	writeFile(t, filepath.Join(root, "ci", "job", "cpu.max"), "max 100000\n")
+	memory, err = cgroupMemoryLimit(procFile, root)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), memory)
+	cpus, err = cgroupCPULimit(procFile, root)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cpus)
+}
+
+func TestCgroupV1Limits(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cgroup")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	procFile := filepath.Join(dir, "cgroup")
+	root := filepath.Join(dir, "sys")
+	writeFile(t, procFile, "4:memory:/docker/abc\n2:cpu,cpuacct:/docker/abc\n1:name=systemd:/\n")
+	// the cgroup path is not visible, files are read from the root of the hierarchy
+	writeFile(t, filepath.Join(root, "memory", "memory.limit_in_bytes"), "4294967296\n")
+	writeFile(t, filepath.Join(root, "cpu", "cpu.cfs_quota_us"), "200000\n")
+	writeFile(t, filepath.Join(root, "cpu", "cpu.cfs_period_us"), "100000\n")
+
+	memory, err := cgroupMemoryLimit(procFile, root)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(4294967296), memory)
+	cpus, err := cgroupCPULimit(procFile, root)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, cpus)
+
+	writeFile(t, filepath.Join(root, "memory", "memory.limit_in_bytes"), "9223372036854771712\n")
+	writeFile(t, filepath.Join(root, "cpu", "cpu.cfs_quota_us"), "-1\n")
+	memory, err = cgroupMemoryLimit(procFile, root)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), memory)
+	cpus, err = cgroupCPULimit(procFile, root)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, cpus)
+}
@@ -0,0 +1,51 @@
+package os
+
+import (
+	"fmt"
+	"path/filepath"
+	"unicode"
+)
+
+// NormalizePath returns the absolute and cleaned form of path. On Windows,
+// the 8.3 short names it contains are expanded so that crc, the hypervisor
+// and the tools it runs all see the same path for a file.
+func NormalizePath(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	return longPathName(filepath.Clean(abs)), nil
+}
+
+// CheckPathLength returns an error when path is too long for the hypervisor
+// and the tools run by crc
+func CheckPathLength(path string) error {
+	if maxPathLength > 0 && len(path) >= maxPathLength {
+		return fmt.Errorf("%s is %d characters long, the hypervisor and the tools used by crc cannot use paths of %d characters or more", path, len(path), maxPathLength)
+	}
+	return nil
+}
+
+// CheckSocketPath returns an error when path cannot be used as the path of
+// a unix socket, their length is limited by the size of sockaddr_un
+func CheckSocketPath(path string) error {
+	if len(path) >= maxSocketPathLength() {
+		return fmt.Errorf("%s is %d characters long, unix socket paths must be shorter than %d characters", path, len(path), maxSocketPathLength())
+	}
+	return nil
+}
+
+// CheckHomeDirectory returns an error when crc cannot work with the home
+// directory home, and warnings for the setups known to cause problems
+func CheckHomeDirectory(home string) ([]string, error) {
+	return checkHomeDirectory(home)
+}
+
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,31 @@
+// +build !windows
+
+package os
+
+import (
+	"runtime"
+	"strings"
+)
+
+// PATH_MAX is large enough for all the files of crc
+const maxPathLength = 0
+
+// maxSocketPathLength is the size of sun_path, including its terminating NUL
+func maxSocketPathLength() int {
+	if runtime.GOOS == "darwin" {
+		return 104
+	}
+	return 108
+}
+
+func longPathName(path string) string {
+	return path
+}
+
+func checkHomeDirectory(home string) ([]string, error) {
+	var warnings []string
+	if runtime.GOOS == "darwin" && strings.ContainsRune(home, ' ') {
+		warnings = append(warnings, "the home directory contains spaces, which hyperkit does not support in all its options")
+	}
+	return warnings, nil
+}
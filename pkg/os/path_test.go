@@ -0,0 +1,31 @@
+package os
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizePath(t *testing.T) {
+	dir := t.TempDir()
+	normalized, err := NormalizePath(filepath.Join(dir, "a", "..", "b"))
+	require.NoError(t, err)
+	assert.True(t, filepath.IsAbs(normalized))
+	assert.Equal(t, "b", filepath.Base(normalized))
+	assert.NotContains(t, normalized, "..")
+}
+
+func TestCheckSocketPath(t *testing.T) {
+	assert.NoError(t, CheckSocketPath(filepath.Join(t.TempDir(), "crc.sock")))
+	assert.Error(t, CheckSocketPath(filepath.Join(t.TempDir(), strings.Repeat("a", 120), "crc.sock")))
+}
+
+func TestCheckPathLength(t *testing.T) {
+	assert.NoError(t, CheckPathLength(filepath.Join(t.TempDir(), "crc-bundle-info.json")))
+	if maxPathLength > 0 {
+		assert.Error(t, CheckPathLength(filepath.Join(t.TempDir(), strings.Repeat("a", maxPathLength))))
+	}
+}
@@ -0,0 +1,47 @@
+package os
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// MAX_PATH, Hyper-V and the tools run by crc do not use the long path prefix
+	maxPathLength  = 260
+	longPathPrefix = `\\?\`
+	uncPrefix      = `\\`
+)
+
+// maxSocketPathLength is the size of sun_path in afunix.h, including its
+// terminating NUL
+func maxSocketPathLength() int {
+	return 108
+}
+
+// longPathName expands the 8.3 short names of path, which must exist
+func longPathName(path string) string {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return path
+	}
+	buf := make([]uint16, windows.MAX_LONG_PATH)
+	n, err := windows.GetLongPathName(pathPtr, &buf[0], uint32(len(buf)))
+	if err != nil || n == 0 || n > uint32(len(buf)) {
+		return path
+	}
+	return windows.UTF16ToString(buf[:n])
+}
+
+func checkHomeDirectory(home string) ([]string, error) {
+	home = strings.TrimPrefix(home, longPathPrefix)
+	if strings.HasPrefix(home, uncPrefix) || strings.HasPrefix(strings.ToUpper(home), `UNC\`) {
+		return nil, fmt.Errorf("the home directory %s is on a network share, Hyper-V cannot use disk images stored on network shares", home)
+	}
+	var warnings []string
+	if !isASCII(home) {
+		warnings = append(warnings, fmt.Sprintf("the home directory %s contains non-ASCII characters, which some versions of PowerShell and Hyper-V do not handle", home))
+	}
+	return warnings, nil
+}
@@ -0,0 +1,15 @@
+package power
+
+import (
+	"time"
+)
+
+// clock jumps larger than this while waiting for the next tick are reported as a resume
+const resumeThreshold = 30 * time.Second
+
+// wallClockJumped returns true when the wall clock time elapsed since last is
+// much larger than the expected interval, which happens when the host was
+// suspended in between
+func wallClockJumped(last, now time.Time, interval time.Duration) bool {
+	return now.Round(0).Sub(last.Round(0)) > interval+resumeThreshold
+}
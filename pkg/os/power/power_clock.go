@@ -0,0 +1,27 @@
+package power
+
+import (
+	"context"
+	"time"
+)
+
+const clockInterval = 10 * time.Second
+
+// watchClock calls onResume when the wall clock jumps forward, which happens
+// when the host resumed from sleep, until ctx is cancelled
+func watchClock(ctx context.Context, onResume func()) {
+	ticker := time.NewTicker(clockInterval)
+	defer ticker.Stop()
+	last := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if wallClockJumped(last, now, clockInterval) {
+				onResume()
+			}
+			last = time.Now()
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package power
+
+import (
+	"context"
+	"os"
+
+	"github.com/code-ready/crc/pkg/crc/logging"
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	logindDest      = "org.freedesktop.login1"
+	logindPath      = dbus.ObjectPath("/org/freedesktop/login1")
+	logindInterface = "org.freedesktop.login1.Manager"
+)
+
+// Watch calls onSuspend before the host goes to sleep and onResume after it
+// resumed, until ctx is cancelled.
+// A logind delay inhibitor is held so that onSuspend can complete before the
+// host sleeps. When logind is not reachable, resumes are detected from clock jumps.
+func Watch(ctx context.Context, onSuspend, onResume func()) {
+	conn, err := dbus.SystemBus()
+	if err != nil {
+		logging.Debugf("Cannot connect to the system bus, falling back to clock monitoring: %v", err)
+		watchClock(ctx, onResume)
+		return
+	}
+
+	if err := conn.AddMatchSignal(dbus.WithMatchInterface(logindInterface), dbus.WithMatchMember("PrepareForSleep")); err != nil {
+		logging.Debugf("Cannot subscribe to logind sleep signals, falling back to clock monitoring: %v", err)
+		watchClock(ctx, onResume)
+		return
+	}
+	defer func() {
+		_ = conn.RemoveMatchSignal(dbus.WithMatchInterface(logindInterface), dbus.WithMatchMember("PrepareForSleep"))
+	}()
+	signals := make(chan *dbus.Signal, 10)
+	conn.Signal(signals)
+	defer conn.RemoveSignal(signals)
+
+	lock := inhibit(conn)
+	defer func() {
+		if lock != nil {
+			lock.Close()
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case signal := <-signals:
+			if signal.Name != logindInterface+".PrepareForSleep" || len(signal.Body) != 1 {
+				continue
+			}
+			sleeping, ok := signal.Body[0].(bool)
+			if !ok {
+				continue
+			}
+			if sleeping {
+				onSuspend()
+				// let the host go to sleep
+				if lock != nil {
+					lock.Close()
+					lock = nil
+				}
+			} else {
+				lock = inhibit(conn)
+				onResume()
+			}
+		}
+	}
+}
+
+// inhibit takes a logind delay lock on sleep, the host sleeps when the
+// returned file is closed
+func inhibit(conn *dbus.Conn) *os.File {
+	var fd dbus.UnixFD
+	err := conn.Object(logindDest, logindPath).Call(logindInterface+".Inhibit", 0,
+		"sleep", "crc", "Suspending the CodeReady Containers VM", "delay").Store(&fd)
+	if err != nil {
+		logging.Debugf("Cannot take logind sleep inhibitor: %v", err)
+		return nil
+	}
+	return os.NewFile(uintptr(fd), "crc-sleep-inhibitor")
+}
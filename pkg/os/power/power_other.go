@@ -0,0 +1,13 @@
+// +build !linux
+
+package power
+
+import (
+	"context"
+)
+
+// Watch calls onResume after the host resumed from sleep, until ctx is cancelled.
+// Resumes are detected from wall clock jumps, onSuspend is never called.
+func Watch(ctx context.Context, onSuspend, onResume func()) {
+	watchClock(ctx, onResume)
+}
@@ -0,0 +1,15 @@
+package power
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWallClockJumped(t *testing.T) {
+	last := time.Date(2021, 7, 1, 10, 0, 0, 0, time.UTC)
+	assert.False(t, wallClockJumped(last, last.Add(10*time.Second), 10*time.Second))
+	assert.False(t, wallClockJumped(last, last.Add(15*time.Second), 10*time.Second))
+	assert.True(t, wallClockJumped(last, last.Add(time.Hour), 10*time.Second))
+}
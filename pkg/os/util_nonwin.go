@@ -9,6 +9,7 @@ import (
 	"os/exec"
 	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/code-ready/crc/pkg/crc/logging"
 )
@@ -36,3 +37,17 @@ func RemoveFileAsRoot(reason, filepath string) error {
 	_, _, err := RunPrivileged(reason, "rm", "-fr", filepath)
 	return err
 }
+
+// AllocatedFileSize returns the disk space used by a file, which is smaller
+// than its size for sparse files such as VM disk images
+func AllocatedFileSize(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		// st_blocks is always counted in 512 bytes units
+		return stat.Blocks * 512, nil
+	}
+	return info.Size(), nil
+}
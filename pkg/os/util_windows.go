@@ -3,6 +3,7 @@ package os
 import (
 	"bytes"
 	"io/ioutil"
+	"os"
 
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
@@ -28,3 +29,13 @@ func ReadFileUTF16LE(filename string) ([]byte, error) {
 	decoded, err := ioutil.ReadAll(unicodeReader)
 	return decoded, err
 }
+
+// AllocatedFileSize returns the disk space used by a file. Hyper-V dynamic
+// disks are not sparse files, their size is the space they use
+func AllocatedFileSize(path string) (int64, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}